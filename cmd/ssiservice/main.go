@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"expvar"
+	"flag"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,6 +18,9 @@ import (
 
 	"github.com/tbd54566975/ssi-service/config"
 	"github.com/tbd54566975/ssi-service/pkg/server"
+	"github.com/tbd54566975/ssi-service/pkg/service"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 
 	"go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -24,6 +28,13 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 )
 
+const (
+	migrateCommand        = "migrate"
+	exportCommand         = "export"
+	importCommand         = "import"
+	rotateDataKeysCommand = "rotate-data-keys"
+)
+
 func init() {
 	// Log as JSON instead of the default ASCII formatter.
 	logrus.SetFormatter(&logrus.JSONFormatter{})
@@ -43,6 +54,26 @@ func init() {
 // @license.url    http://www.apache.org/licenses/LICENSE-2.0.html
 // @host           localhost:8080
 func main() {
+	if len(os.Args) > 1 {
+		var command func([]string) error
+		switch os.Args[1] {
+		case migrateCommand:
+			command = migrate
+		case exportCommand:
+			command = export
+		case importCommand:
+			command = importArchive
+		case rotateDataKeysCommand:
+			command = rotateDataKeys
+		}
+		if command != nil {
+			if err := command(os.Args[2:]); err != nil {
+				logrus.Fatalf("%s: error: %s", os.Args[1], err.Error())
+			}
+			return
+		}
+	}
+
 	logrus.Info("Starting up...")
 
 	if err := run(); err != nil {
@@ -50,6 +81,141 @@ func main() {
 	}
 }
 
+// migrate runs the storage migrations without starting the server, e.g. `ssiservice migrate -dry-run`. Migrations
+// also run on startup, so this is only needed to upgrade the storage ahead of a deployment, or to preview an upgrade.
+// Once a namespace is added to the encrypted ones, `ssiservice migrate -encrypt-existing` encrypts the values it already
+// had, which are not read until then.
+func migrate(args []string) error {
+	flags := flag.NewFlagSet(migrateCommand, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be migrated without writing anything")
+	encryptExisting := flags.Bool("encrypt-existing", false, "encrypt the values written before their namespace was encrypted")
+	if err := flags.Parse(args); err != nil {
+		return errors.Wrap(err, "parsing flags")
+	}
+
+	cfg, err := config.LoadConfig(config.DefaultConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "loading config")
+	}
+	if *encryptExisting {
+		encrypted, err := service.EncryptExistingValues(context.Background(), cfg.Services, *dryRun)
+		if err != nil {
+			return err
+		}
+		logrus.WithField("dryRun", *dryRun).Infof("migrate: %d existing values encrypted", encrypted)
+	}
+	results, err := service.MigrateStorage(context.Background(), cfg.Services, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		logrus.WithFields(logrus.Fields{
+			"namespace":   result.Namespace,
+			"fromVersion": result.FromVersion,
+			"toVersion":   result.ToVersion,
+			"migrated":    result.Migrated,
+			"dryRun":      *dryRun,
+		}).Info("migrate: namespace migrated")
+	}
+	logrus.Infof("migrate: %d namespaces migrated", len(results))
+	return nil
+}
+
+// rotateDataKeys creates new data keys for the given encrypted namespaces, or for all of them, e.g.
+// `ssiservice rotate-data-keys credential`. Instances of the service which are running keep writing with the previous
+// data keys until they are restarted.
+func rotateDataKeys(args []string) error {
+	cfg, err := config.LoadConfig(config.DefaultConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "loading config")
+	}
+	if err = service.RotateDataKeys(context.Background(), cfg.Services, args...); err != nil {
+		return err
+	}
+	logrus.Infof("%s: data keys rotated", rotateDataKeysCommand)
+	return nil
+}
+
+// export writes the state of the service to an archive file, e.g. `ssiservice export backup.tar.gz`. Namespaces are
+// read one at a time, so the service should be stopped for the archive to be consistent. When a service has a storage
+// of its own, it is exported separately, e.g. `ssiservice export -service keystore keystore.tar.gz`.
+func export(args []string) error {
+	path, serviceType, err := parseArchiveArgs(exportCommand, args)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig(config.DefaultConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "loading config")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "creating archive file")
+	}
+	manifest, err := service.ExportStorage(context.Background(), cfg.Services, serviceType, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = errors.Wrap(closeErr, "closing archive file")
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	logArchiveManifest(exportCommand, manifest)
+	return nil
+}
+
+// importArchive loads an archive file written by export into the configured storage, e.g.
+// `ssiservice import backup.tar.gz`, or into that of a single service with `-service`. The archive is verified against
+// its manifest before anything is written.
+func importArchive(args []string) error {
+	path, serviceType, err := parseArchiveArgs(importCommand, args)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig(config.DefaultConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "loading config")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening archive file")
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	manifest, err := service.ImportStorage(context.Background(), cfg.Services, serviceType, file)
+	if err != nil {
+		return err
+	}
+	logArchiveManifest(importCommand, manifest)
+	return nil
+}
+
+func parseArchiveArgs(command string, args []string) (string, svcframework.Type, error) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	serviceType := flags.String("service", "", "service whose own storage to use, instead of the storage of the services block")
+	if err := flags.Parse(args); err != nil {
+		return "", "", errors.Wrap(err, "parsing flags")
+	}
+	if flags.NArg() != 1 {
+		return "", "", errors.Errorf("usage: ssiservice %s [-service <name>] <archive file>", command)
+	}
+	return flags.Arg(0), svcframework.Type(*serviceType), nil
+}
+
+func logArchiveManifest(command string, manifest *storage.ArchiveManifest) {
+	for _, namespace := range manifest.Namespaces {
+		logrus.WithFields(logrus.Fields{
+			"namespace": namespace.Name,
+			"count":     namespace.Count,
+			"sha256":    namespace.SHA256,
+		}).Infof("%s: namespace done", command)
+	}
+	logrus.Infof("%s: %d namespaces from %s storage, archived at %s", command, len(manifest.Namespaces), manifest.Source, manifest.CreatedAt)
+}
+
 // startup and shutdown logic
 func run() error {
 	cfg, err := config.LoadConfig(config.DefaultConfigPath)
@@ -115,6 +281,11 @@ func run() error {
 	if err != nil {
 		logrus.Fatalf("could not start http services: %s", err.Error())
 	}
+	defer func() {
+		if err := ssiServer.SSIService.Close(); err != nil {
+			logrus.WithError(err).Error("main: failed to close storage")
+		}
+	}()
 	api := http.Server{
 		Addr:         cfg.Server.APIHost,
 		Handler:      ssiServer,
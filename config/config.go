@@ -23,8 +23,10 @@ const (
 
 	DefaultServiceEndpoint = "http://localhost:8080"
 
-	KeystorePassword EnvironmentVariable = "KEYSTORE_PASSWORD"
-	DBPassword       EnvironmentVariable = "DB_PASSWORD"
+	KeystorePassword   EnvironmentVariable = "KEYSTORE_PASSWORD"
+	DBPassword         EnvironmentVariable = "DB_PASSWORD"
+	EncryptionPassword EnvironmentVariable = "ENCRYPTION_PASSWORD"
+	AdminAPIKey        EnvironmentVariable = "ADMIN_API_KEY"
 )
 
 type EnvironmentVariable string
@@ -48,6 +50,9 @@ type ServerConfig struct {
 	LogLevel            string        `toml:"log_level" conf:"default:debug"`
 	EnableSchemaCaching bool          `toml:"enable_schema_caching" conf:"default:true"`
 	EnableAllowAllCORS  bool          `toml:"enable_allow_all_cors" conf:"default:false"`
+	// API key authenticating the requests made to the admin routes, such as those managing tenants, which are rejected
+	// when it is not set
+	AdminAPIKey string `toml:"admin_api_key"`
 }
 
 type IssuingServiceConfig struct {
@@ -63,13 +68,20 @@ func (s *IssuingServiceConfig) IsEmpty() bool {
 
 // ServicesConfig represents configurable properties for the components of the SSI Service
 type ServicesConfig struct {
-	// at present, it is assumed that a single storage provider works for all services
-	// in the future it may make sense to have per-service storage providers (e.g. mysql for one service,
-	// mongo for another)
+	// Storage provider of every service whose own block does not configure one
 	StorageProvider string      `toml:"storage"`
 	StorageOption   interface{} `toml:"storage_option"`
 	ServiceEndpoint string      `toml:"service_endpoint"`
 
+	// Encryption at rest of the values stored in the configured namespaces
+	EncryptionConfig EncryptionConfig `toml:"encryption,omitempty"`
+
+	// Outbox of the changes made to stored values, which are delivered to subscribers such as webhooks
+	OutboxConfig OutboxConfig `toml:"outbox,omitempty"`
+
+	// Scheduler of the background jobs, such as expiring credentials, which run periodically
+	SchedulerConfig SchedulerConfig `toml:"scheduler,omitempty"`
+
 	// Embed all service-specific configs here. The order matters: from which should be instantiated first, to last
 	KeyStoreConfig       KeyStoreServiceConfig     `toml:"keystore,omitempty"`
 	DIDConfig            DIDServiceConfig          `toml:"did,omitempty"`
@@ -86,6 +98,71 @@ type ServicesConfig struct {
 type BaseServiceConfig struct {
 	Name            string `toml:"name"`
 	ServiceEndpoint string `toml:"service_endpoint"`
+
+	// Storage provider of the service, and its options. The storage configured in the services block is used when
+	// empty. Services configured with the same provider and options share a single instance of it.
+	StorageProvider string      `toml:"storage"`
+	StorageOption   interface{} `toml:"storage_option"`
+}
+
+// Storage returns the storage provider configured for the service, and its options, falling back to the given
+// defaults.
+func (b *BaseServiceConfig) Storage(defaultProvider string, defaultOption interface{}) (string, interface{}) {
+	if b == nil || b.StorageProvider == "" {
+		return defaultProvider, defaultOption
+	}
+	return b.StorageProvider, b.StorageOption
+}
+
+// EncryptionConfig represents configurable properties for encrypting stored values. The values of each namespace are
+// encrypted with its own data keys, which are in turn encrypted with a key derived from the current password.
+type EncryptionConfig struct {
+	// Namespaces whose values are encrypted, e.g. "credential". Nothing is encrypted when empty. The values a namespace
+	// already has when it is added are not read until encrypted with `ssiservice migrate -encrypt-existing`.
+	Namespaces []string `toml:"namespaces"`
+	// ID of the password used to encrypt data keys. When it changes, data keys encrypted with any other password are
+	// re-encrypted on startup; values themselves are not re-encrypted.
+	CurrentPasswordID string `toml:"current_password_id"`
+	// Passwords by ID. Previous passwords must be kept until every instance of the service has been restarted with the
+	// new current password.
+	Passwords map[string]string `toml:"passwords"`
+}
+
+func (e *EncryptionConfig) IsEmpty() bool {
+	if e == nil {
+		return true
+	}
+	return len(e.Namespaces) == 0
+}
+
+// OutboxConfig represents configurable properties for the outbox of changes to stored values.
+type OutboxConfig struct {
+	// How long changes, and the records of their delivery, are kept when they could not be delivered to every
+	// subscriber. Defaults to a week when zero.
+	Retention time.Duration `toml:"retention"`
+}
+
+// SchedulerConfig represents configurable properties for the scheduler of background jobs. Each job runs on a single
+// instance of the service at a time, which holds a lease on it in storage.
+type SchedulerConfig struct {
+	// Whether no jobs are run by this instance.
+	Disabled bool `toml:"disabled"`
+	// How long the lease an instance holds on the job it runs lasts, which the instance renews while the job runs.
+	// Another instance takes the job over when the lease expires, e.g. because the instance running it stopped.
+	// Defaults to 10 minutes when zero.
+	LeaseDuration time.Duration `toml:"lease_duration"`
+
+	// How often credentials past their expiration date are marked expired. Defaults to an hour when zero.
+	CredentialExpirationInterval time.Duration `toml:"credential_expiration_interval"`
+	// How often temporary suspensions which are over are lifted. Defaults to a minute when zero.
+	SuspensionInterval time.Duration `toml:"suspension_interval"`
+	// How often operations which are done are purged, and how long they are kept once done. Default to an hour and
+	// 30 days respectively when zero.
+	OperationPurgeInterval time.Duration `toml:"operation_purge_interval"`
+	OperationRetention     time.Duration `toml:"operation_retention"`
+	// How often the status lists and DID documents cached by each instance are fetched again. Defaults to a minute when
+	// zero.
+	CacheRefreshInterval time.Duration `toml:"cache_refresh_interval"`
 }
 
 type KeyStoreServiceConfig struct {
@@ -132,7 +209,43 @@ func (s *SchemaServiceConfig) IsEmpty() bool {
 type CredentialServiceConfig struct {
 	*BaseServiceConfig
 
-	// TODO(gabe) supported key and signature types
+	// Batches of more credentials than this are created in the background, and tracked by an operation. Defaults to
+	// 100 when zero.
+	BatchOperationThreshold int `toml:"batch_operation_threshold"`
+
+	// The number of indexes of each status list. Once a status list is full, a new one is created for the credentials
+	// of the same issuer, schema and purpose. Defaults to 131072 when zero.
+	StatusListSize int `toml:"status_list_size"`
+
+	// The issuers whose credentials are VC data model 2.0 credentials, unless their create request asks for another
+	// version. Credentials of other issuers are 1.1 credentials by default.
+	DataModelV2Issuers []string `toml:"data_model_v2_issuers"`
+
+	// Named policies credentials can be verified with, along with those created through the API, which cannot share
+	// their names.
+	VerificationPolicies []VerificationPolicyConfig `toml:"verification_policies"`
+
+	// Whether the status lists of credentials are fetched from any URL, rather than only from https URLs of public
+	// addresses. Only meant for development, as the status list URLs of credentials are picked by their issuers, and
+	// would otherwise let anyone have the service make requests to itself or to its private network.
+	AllowPrivateStatusListURLs bool `toml:"allow_private_status_list_urls"`
+}
+
+// VerificationPolicyConfig is a named policy which sets the checks run when verifying a credential, and what they
+// accept. It is picked by name when verifying a credential, or set on a presentation definition or credential manifest.
+type VerificationPolicyConfig struct {
+	Name string `toml:"name"`
+	// The checks run on credentials, e.g. "expiry". Every check runs when empty.
+	RequiredChecks []string `toml:"required_checks"`
+	// The JWS algorithms credentials may be signed with, e.g. "EdDSA". Any algorithm is accepted when empty.
+	AllowedAlgorithms []string `toml:"allowed_algorithms"`
+	// The methods of the DIDs credentials may be issued by, e.g. "key". Any method is accepted when empty.
+	AllowedDIDMethods []string `toml:"allowed_did_methods"`
+	// How long after their issuance credentials are accepted. Credentials of any age are accepted when zero.
+	MaxCredentialAge time.Duration `toml:"max_credential_age"`
+	// Whether credentials whose schema or status list cannot be resolved are verified without checking them.
+	AllowUnresolvableSchema bool `toml:"allow_unresolvable_schema"`
+	AllowUnresolvableStatus bool `toml:"allow_unresolvable_status"`
 }
 
 func (c *CredentialServiceConfig) IsEmpty() bool {
@@ -144,6 +257,8 @@ func (c *CredentialServiceConfig) IsEmpty() bool {
 
 type ManifestServiceConfig struct {
 	*BaseServiceConfig
+	// How long credential applications, and the operations tracking them, are kept. They are kept forever when zero.
+	ApplicationTTL time.Duration `toml:"application_ttl"`
 }
 
 func (m *ManifestServiceConfig) IsEmpty() bool {
@@ -155,6 +270,8 @@ func (m *ManifestServiceConfig) IsEmpty() bool {
 
 type PresentationServiceConfig struct {
 	*BaseServiceConfig
+	// How long presentation submissions, and the operations tracking them, are kept. They are kept forever when zero.
+	SubmissionTTL time.Duration `toml:"submission_ttl"`
 }
 
 func (p *PresentationServiceConfig) IsEmpty() bool {
@@ -315,6 +432,21 @@ func applyEnvVariables(config *SSIServiceConfig) error {
 		config.Services.KeyStoreConfig.ServiceKeyPassword = keystorePassword
 	}
 
+	encryptionPassword, present := os.LookupEnv(string(EncryptionPassword))
+
+	if present {
+		if config.Services.EncryptionConfig.Passwords == nil {
+			config.Services.EncryptionConfig.Passwords = make(map[string]string)
+		}
+		config.Services.EncryptionConfig.Passwords[config.Services.EncryptionConfig.CurrentPasswordID] = encryptionPassword
+	}
+
+	adminAPIKey, present := os.LookupEnv(string(AdminAPIKey))
+
+	if present {
+		config.Server.AdminAPIKey = adminAPIKey
+	}
+
 	dbPassword, present := os.LookupEnv(string(DBPassword))
 
 	if present {
@@ -0,0 +1,166 @@
+package credential
+
+import (
+	"github.com/TBD54566975/ssi-sdk/credential"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/jws"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// DataModelVersion is the version of https://www.w3.org/TR/vc-data-model/ a credential conforms to.
+type DataModelVersion string
+
+const (
+	DataModelV1 DataModelVersion = "1.1"
+	DataModelV2 DataModelVersion = "2.0"
+
+	// V2Context is the base context of VC data model 2.0 credentials, in place of the 1.1 base context.
+	V2Context = "https://www.w3.org/ns/credentials/v2"
+	// JSONSchemaType is the type of the credentialSchema of VC data model 2.0 credentials.
+	JSONSchemaType = "JsonSchema"
+
+	issuanceDateProperty   = "issuanceDate"
+	expirationDateProperty = "expirationDate"
+	validFromProperty      = "validFrom"
+	validUntilProperty     = "validUntil"
+)
+
+// IsValid returns whether the version is a known version of the data model, or empty.
+func (v DataModelVersion) IsValid() bool {
+	return v == "" || v == DataModelV1 || v == DataModelV2
+}
+
+// Version returns the version of the data model the credential conforms to, which is told by its base context.
+//
+// The credentials of both versions are held in the same model, in which the issuanceDate and expirationDate of a 2.0
+// credential hold its validFrom and validUntil. Only the JSON a 2.0 credential is secured as, returned by V2JSON, has
+// the properties of its version.
+func Version(cred credential.VerifiableCredential) DataModelVersion {
+	contexts, err := sdkutil.InterfaceToStrings(cred.Context)
+	if err == nil && len(contexts) > 0 && contexts[0] == V2Context {
+		return DataModelV2
+	}
+	return DataModelV1
+}
+
+// ToV2 makes a credential built as a 1.1 credential a 2.0 one: its base context is the 2.0 context, and its
+// credentialSchema is of type JsonSchema.
+func ToV2(cred *credential.VerifiableCredential) error {
+	contexts, err := sdkutil.InterfaceToStrings(cred.Context)
+	if err != nil {
+		return errors.Wrap(err, "malformed context")
+	}
+	v2Contexts := []string{V2Context}
+	for _, c := range contexts {
+		if c != credential.VerifiableCredentialsLinkedDataContext && c != V2Context {
+			v2Contexts = append(v2Contexts, c)
+		}
+	}
+	cred.Context = v2Contexts
+	if cred.CredentialSchema != nil {
+		cred.CredentialSchema.Type = JSONSchemaType
+	}
+	return nil
+}
+
+// V2JSON returns the JSON of a 2.0 credential, with validFrom and validUntil in place of issuanceDate and
+// expirationDate.
+func V2JSON(cred credential.VerifiableCredential) (map[string]any, error) {
+	credJSON, err := sdkutil.ToJSONMap(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+	renameProperty(credJSON, issuanceDateProperty, validFromProperty)
+	renameProperty(credJSON, expirationDateProperty, validUntilProperty)
+	return credJSON, nil
+}
+
+// FromV2JSON returns the credential of the JSON of a 2.0 credential.
+func FromV2JSON(credJSON map[string]any) (*credential.VerifiableCredential, error) {
+	renamed := make(map[string]any, len(credJSON))
+	for k, v := range credJSON {
+		renamed[k] = v
+	}
+	renameProperty(renamed, validFromProperty, issuanceDateProperty)
+	renameProperty(renamed, validUntilProperty, expirationDateProperty)
+	credBytes, err := json.Marshal(renamed)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+	var cred credential.VerifiableCredential
+	if err = json.Unmarshal(credBytes, &cred); err != nil {
+		return nil, errors.Wrap(err, "reconstructing Verifiable Credential")
+	}
+	return &cred, nil
+}
+
+func renameProperty(m map[string]any, from, to string) {
+	if v, ok := m[from]; ok {
+		delete(m, from)
+		m[to] = v
+	}
+}
+
+// ParseJWTCredential parses the credential of a JWT, which is either a 1.1 VC-JWT, holding the credential in its vc
+// claim, or a 2.0 credential secured as a JWT with the application/vc+ld+json+jwt media type, whose claims set is the
+// credential.
+func ParseJWTCredential(token string) (jws.Headers, *credential.VerifiableCredential, error) {
+	headers, err := keyaccess.GetJWTHeaders([]byte(token))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not get JWT headers")
+	}
+	if headers.Type() != keyaccess.VCV2JWTType {
+		_, _, cred, err := credential.ParseVerifiableCredentialFromJWT(token)
+		if err != nil {
+			return nil, nil, err
+		}
+		return headers, cred, nil
+	}
+
+	msg, err := jws.Parse([]byte(token))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing credential token")
+	}
+	var claims map[string]any
+	if err = json.Unmarshal(msg.Payload(), &claims); err != nil {
+		return nil, nil, errors.Wrap(err, "reading credential claims")
+	}
+	cred, err := FromV2JSON(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, cred, nil
+}
+
+// IsV2JWT returns whether the token is a VC data model 2.0 credential secured as a JWT.
+func IsV2JWT(token string) bool {
+	headers, err := keyaccess.GetJWTHeaders([]byte(token))
+	return err == nil && headers.Type() == keyaccess.VCV2JWTType
+}
+
+// V2JWTsAsJSON returns a copy of the credentials in which each VC data model 2.0 credential secured as a JWT is
+// replaced with its JSON, which is what presentation definition and credential manifest constraints are evaluated
+// against, since the claims set of such a JWT has no vc claim. It must only be used once the credentials are verified.
+func V2JWTsAsJSON(credentials []any) ([]any, error) {
+	res := make([]any, 0, len(credentials))
+	for _, cred := range credentials {
+		token, ok := cred.(string)
+		if !ok || !IsV2JWT(token) {
+			res = append(res, cred)
+			continue
+		}
+		_, parsed, err := ParseJWTCredential(token)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing data model 2.0 credential")
+		}
+		credJSON, err := V2JSON(*parsed)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, credJSON)
+	}
+	return res, nil
+}
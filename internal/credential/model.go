@@ -3,24 +3,35 @@ package credential
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 )
 
-// Container acts as an abstraction over both possible credential representations
-// JWT representations are parsed upon container creation, while the original JWT is maintained
+// SDJWTVC is the format of credentials issued as SD-JWTs, whose credential subject claims may be selectively disclosed.
+const SDJWTVC exchange.CredentialFormat = "vc+sd-jwt"
+
+// Container acts as an abstraction over the possible credential representations
+// JWT representations are parsed upon container creation, while the original JWT is maintained. The credential of an
+// SD-JWT holds the claims it discloses, except when issued, in which case it holds every claim.
 type Container struct {
 	// Credential ID
-	ID            string
-	IssuerKID     string
-	Credential    *credential.VerifiableCredential
-	CredentialJWT *keyaccess.JWT
-	Revoked       bool
-	Suspended     bool
+	ID              string
+	IssuerKID       string
+	Credential      *credential.VerifiableCredential
+	CredentialJWT   *keyaccess.JWT
+	CredentialSDJWT *keyaccess.SDJWT
+	Revoked         bool
+	Suspended       bool
+	// When the suspension of the credential is lifted, if it is temporary
+	SuspendedUntil *time.Time
+	// Whether the expiration date of the credential has passed, once the service noticed it
+	Expired bool
 }
 
 func (c Container) JWTString() string {
@@ -28,11 +39,11 @@ func (c Container) JWTString() string {
 }
 
 func (c Container) IsValid() bool {
-	return c.ID != "" && (c.HasDataIntegrityCredential() || c.HasJWTCredential())
+	return c.ID != "" && c.HasSignedCredential()
 }
 
 func (c Container) HasSignedCredential() bool {
-	return c.HasDataIntegrityCredential() || c.HasJWTCredential()
+	return c.HasDataIntegrityCredential() || c.HasJWTCredential() || c.HasSDJWTCredential()
 }
 
 func (c Container) HasDataIntegrityCredential() bool {
@@ -43,9 +54,28 @@ func (c Container) HasJWTCredential() bool {
 	return c.CredentialJWT != nil
 }
 
-// NewCredentialContainerFromJWT attempts to parse a VC-JWT credential from a string into a Container
+func (c Container) HasSDJWTCredential() bool {
+	return c.CredentialSDJWT != nil
+}
+
+// Format returns the format the credential is signed in, or an empty format when it is not signed.
+func (c Container) Format() exchange.CredentialFormat {
+	if c.HasSDJWTCredential() {
+		return SDJWTVC
+	}
+	if c.HasJWTCredential() {
+		return exchange.JWTVC.CredentialFormat()
+	}
+	if c.HasDataIntegrityCredential() {
+		return exchange.LDPVC.CredentialFormat()
+	}
+	return ""
+}
+
+// NewCredentialContainerFromJWT attempts to parse a VC-JWT credential, or a VC data model 2.0 credential secured as a
+// JWT, from a string into a Container
 func NewCredentialContainerFromJWT(credentialJWT string) (*Container, error) {
-	_, _, cred, err := credential.ToCredential(credentialJWT)
+	_, cred, err := ParseJWTCredential(credentialJWT)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not parse credential from JWT")
 	}
@@ -56,6 +86,22 @@ func NewCredentialContainerFromJWT(credentialJWT string) (*Container, error) {
 	}, nil
 }
 
+// NewCredentialContainerFromSDJWT attempts to parse an SD-JWT credential from a string into a Container, whose
+// credential holds the claims it discloses. The disclosures are checked against the digests of the credential, but no
+// signature is verified.
+func NewCredentialContainerFromSDJWT(credentialSDJWT string) (*Container, error) {
+	sdJWT := keyaccess.SDJWT(credentialSDJWT)
+	cred, err := DisclosedCredential(sdJWT)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse credential from SD-JWT")
+	}
+	return &Container{
+		ID:              cred.ID,
+		Credential:      cred,
+		CredentialSDJWT: &sdJWT,
+	}, nil
+}
+
 // NewCredentialContainerFromMap attempts to parse a data integrity credential from a piece of JSON,
 // which is represented as a map in go, into a Container
 func NewCredentialContainerFromMap(credMap map[string]any) (*Container, error) {
@@ -76,7 +122,9 @@ func NewCredentialContainerFromMap(credMap map[string]any) (*Container, error) {
 func ContainersToInterface(cs []Container) []any {
 	var credentials []any
 	for _, container := range cs {
-		if container.HasDataIntegrityCredential() {
+		if container.HasSDJWTCredential() {
+			credentials = append(credentials, *container.CredentialSDJWT)
+		} else if container.HasDataIntegrityCredential() {
 			credentials = append(credentials, *container.Credential)
 		} else if container.HasJWTCredential() {
 			credentials = append(credentials, *container.CredentialJWT)
@@ -85,13 +133,21 @@ func ContainersToInterface(cs []Container) []any {
 	return credentials
 }
 
-// NewCredentialContainerFromArray attempts to parse arrays of credentials of any type (either data integrity or JWT)
-// into an array of CredentialContainers. The method will return an error if any of the credentials are invalid.
+// NewCredentialContainerFromArray attempts to parse arrays of credentials of any type (either data integrity, JWT or
+// SD-JWT) into an array of CredentialContainers. The method will return an error if any of the credentials are invalid.
 func NewCredentialContainerFromArray(creds []any) ([]Container, error) {
 	var containers []Container
 	for _, c := range creds {
 		switch v := c.(type) {
 		case string:
+			if keyaccess.IsSDJWT(v) {
+				container, err := NewCredentialContainerFromSDJWT(v)
+				if err != nil {
+					return nil, errors.Wrap(err, "could not parse credential from SD-JWT")
+				}
+				containers = append(containers, *container)
+				continue
+			}
 			// JWT
 			container, err := NewCredentialContainerFromJWT(v)
 			if err != nil {
@@ -0,0 +1,129 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/pkg/errors"
+)
+
+// policyNamePattern restricts the names of policies, which are part of the path of their endpoints.
+var policyNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ErrPolicyNotFound is returned by PolicyResolution when there is no policy with the requested name.
+var ErrPolicyNotFound = errors.New("verification policy not found")
+
+// PolicyResolution resolves verification policies by their name.
+type PolicyResolution interface {
+	// ResolvePolicy returns the policy with the given name, or ErrPolicyNotFound.
+	ResolvePolicy(ctx context.Context, name string) (*Policy, error)
+}
+
+// Policy sets the checks run when verifying a credential, and what they accept. The zero value runs every check, and
+// accepts any algorithm, DID method and credential age, which is how credentials are verified when no policy is named.
+type Policy struct {
+	Name string `json:"name" validate:"required"`
+
+	// The checks run on credentials. The DID resolution and signature checks always run. Every check runs when empty.
+	// A check which does not apply to a credential, such as the schema check of a credential without a schema, is
+	// skipped either way.
+	RequiredChecks []Check `json:"requiredChecks,omitempty"`
+	// The JWS algorithms credentials may be signed with, e.g. "EdDSA". Any algorithm is accepted when empty.
+	AllowedAlgorithms []string `json:"allowedAlgorithms,omitempty"`
+	// The methods of the DIDs credentials may be issued by, e.g. "key". Any method is accepted when empty.
+	AllowedDIDMethods []string `json:"allowedDidMethods,omitempty"`
+	// How long after their issuance credentials are accepted, as part of the expiry check. Credentials of any age are
+	// accepted when zero.
+	MaxCredentialAge time.Duration `json:"maxCredentialAge,omitempty"`
+	// Whether the schema check is skipped, rather than failed, when the schema of a credential cannot be resolved.
+	AllowUnresolvableSchema bool `json:"allowUnresolvableSchema,omitempty"`
+	// Whether the status check is skipped, rather than failed, when the status list of a credential cannot be resolved.
+	AllowUnresolvableStatus bool `json:"allowUnresolvableStatus,omitempty"`
+}
+
+// IsValid checks the name of the policy, and that its checks and algorithms are known.
+func (p Policy) IsValid() error {
+	if !policyNamePattern.MatchString(p.Name) {
+		return errors.Errorf("policy name<%s> must be 1 to 64 letters, digits, dashes or underscores", p.Name)
+	}
+	for _, check := range p.RequiredChecks {
+		if !isKnownCheck(check) {
+			return errors.Errorf("unknown check<%s>", check)
+		}
+	}
+	for _, alg := range p.AllowedAlgorithms {
+		var sigAlg jwa.SignatureAlgorithm
+		if err := sigAlg.Accept(alg); err != nil || sigAlg == jwa.NoSignature {
+			return errors.Errorf("unknown algorithm<%s>", alg)
+		}
+	}
+	for _, method := range p.AllowedDIDMethods {
+		if method == "" || strings.Contains(method, ":") {
+			return errors.Errorf("invalid DID method<%s>", method)
+		}
+	}
+	if p.MaxCredentialAge < 0 {
+		return errors.Errorf("max credential age<%s> cannot be negative", p.MaxCredentialAge)
+	}
+	return nil
+}
+
+func isKnownCheck(check Check) bool {
+	switch check {
+	case CheckDIDResolution, CheckKeyPurpose, CheckSignature, CheckKeyBinding, CheckDataModel, CheckExpiry,
+		CheckNotBefore, CheckSchema, CheckStatus:
+		return true
+	}
+	return false
+}
+
+// requires returns whether the check runs as per the policy.
+func (p Policy) requires(check Check) bool {
+	if len(p.RequiredChecks) == 0 || check == CheckDIDResolution || check == CheckSignature {
+		return true
+	}
+	for _, required := range p.RequiredChecks {
+		if required == check {
+			return true
+		}
+	}
+	return false
+}
+
+// notRequired is the detail of the checks skipped as per the policy.
+func (p Policy) notRequired() string {
+	return fmt.Sprintf("not required by policy<%s>", p.Name)
+}
+
+// allowsAlgorithm returns an error when the policy does not allow credentials signed with the algorithm.
+func (p Policy) allowsAlgorithm(alg string) error {
+	if len(p.AllowedAlgorithms) == 0 {
+		return nil
+	}
+	for _, allowed := range p.AllowedAlgorithms {
+		if allowed == alg {
+			return nil
+		}
+	}
+	return errors.Errorf("algorithm<%s> is not allowed by policy<%s>", alg, p.Name)
+}
+
+// allowsDID returns an error when the policy does not allow credentials issued by the method of the DID.
+func (p Policy) allowsDID(did string) error {
+	if len(p.AllowedDIDMethods) == 0 {
+		return nil
+	}
+	parts := strings.SplitN(did, ":", 3)
+	if len(parts) == 3 && parts[0] == "did" {
+		for _, allowed := range p.AllowedDIDMethods {
+			if allowed == parts[1] {
+				return nil
+			}
+		}
+	}
+	return errors.Errorf("the method of DID<%s> is not allowed by policy<%s>", did, p.Name)
+}
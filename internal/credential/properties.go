@@ -0,0 +1,86 @@
+package credential
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+// IsURI returns whether the value is an absolute URI, as the ids and contexts of credentials must be.
+func IsURI(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != ""
+}
+
+// Contexts returns the contexts of a credential, which are either a single context URI or a list of them.
+func Contexts(context any) ([]string, error) {
+	if context == nil {
+		return nil, nil
+	}
+	contexts, err := sdkutil.InterfaceToStrings(context)
+	if err != nil {
+		return nil, errors.Wrap(err, "context must be a URI or a list of URIs")
+	}
+	for _, c := range contexts {
+		if !IsURI(c) {
+			return nil, errors.Errorf("context<%s> is not a URI", c)
+		}
+	}
+	return contexts, nil
+}
+
+// Types returns the types of a credential, which are either a single type or a list of them.
+func Types(types any) ([]string, error) {
+	if types == nil {
+		return nil, nil
+	}
+	res, err := sdkutil.InterfaceToStrings(types)
+	if err != nil {
+		return nil, errors.Wrap(err, "type must be a string or a list of strings")
+	}
+	for _, t := range res {
+		if t == "" || strings.ContainsAny(t, " \t\n") {
+			return nil, errors.Errorf("type<%s> must be a non-empty term or URI", t)
+		}
+	}
+	return res, nil
+}
+
+// ValidateProperties validates the optional properties a credential is created with against
+// https://www.w3.org/TR/vc-data-model/: contexts are URIs, types are terms or URIs, and each evidence and terms of use
+// has a type, and an id which is a URI when present.
+func ValidateProperties(context, types any, evidence []any, termsOfUse []credential.TermsOfUse) error {
+	if _, err := Contexts(context); err != nil {
+		return err
+	}
+	if _, err := Types(types); err != nil {
+		return err
+	}
+	for i, e := range evidence {
+		evidenceMap, ok := e.(map[string]any)
+		if !ok {
+			return errors.Errorf("evidence<%d> must be an object", i)
+		}
+		evidenceTypes, err := Types(evidenceMap["type"])
+		if err != nil || len(evidenceTypes) == 0 {
+			return errors.Errorf("evidence<%d> must have a type", i)
+		}
+		if id, ok := evidenceMap["id"]; ok {
+			if idStr, isString := id.(string); !isString || !IsURI(idStr) {
+				return errors.Errorf("id of evidence<%d> must be a URI", i)
+			}
+		}
+	}
+	for i, t := range termsOfUse {
+		if t.Type == "" {
+			return errors.Errorf("terms of use<%d> must have a type", i)
+		}
+		if t.ID != "" && !IsURI(t.ID) {
+			return errors.Errorf("id of terms of use<%d> must be a URI", i)
+		}
+	}
+	return nil
+}
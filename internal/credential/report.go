@@ -0,0 +1,135 @@
+package credential
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check is a check run when verifying a credential.
+type Check string
+
+const (
+	// CheckDIDResolution resolves the DID of the signer, and the key the credential is signed with from its document.
+	CheckDIDResolution Check = "didResolution"
+	// CheckKeyPurpose checks the key the credential is signed with is an assertion method of the signer, meant for
+	// issuing credentials.
+	CheckKeyPurpose Check = "keyPurpose"
+	// CheckSignature checks the signature of the credential.
+	CheckSignature Check = "signature"
+	// CheckKeyBinding checks the key binding JWT of an SD-JWT credential is signed by its subject.
+	CheckKeyBinding Check = "keyBinding"
+	// CheckDataModel checks the credential is well-formed, as per https://www.w3.org/TR/vc-data-model/.
+	CheckDataModel Check = "dataModel"
+	// CheckExpiry checks the credential has not expired.
+	CheckExpiry Check = "expiry"
+	// CheckNotBefore checks the credential is already valid, as of its issuance date, or validFrom.
+	CheckNotBefore Check = "notBefore"
+	// CheckSchema checks the claims of the credential comply with its schema.
+	CheckSchema Check = "schema"
+	// CheckStatus checks the credential is neither revoked nor suspended, as held by its status list.
+	CheckStatus Check = "status"
+)
+
+// CheckOutcome is the outcome of a check.
+type CheckOutcome string
+
+const (
+	CheckPassed  CheckOutcome = "passed"
+	CheckFailed  CheckOutcome = "failed"
+	CheckSkipped CheckOutcome = "skipped"
+)
+
+// CheckResult is the outcome of a check of a credential, with the detail of why it failed or was skipped.
+type CheckResult struct {
+	Check   Check        `json:"check"`
+	Outcome CheckOutcome `json:"outcome"`
+	Detail  string       `json:"detail,omitempty"`
+}
+
+// VerificationReport lists the checks run when verifying a credential, in the order they ran, with their outcome. A
+// credential is verified when none of its checks failed.
+type VerificationReport struct {
+	// The ID of the credential, once parsed.
+	CredentialID string        `json:"credentialId,omitempty"`
+	Verified     bool          `json:"verified"`
+	Checks       []CheckResult `json:"checks"`
+
+	// the error of the first check which failed
+	err error
+}
+
+func newVerificationReport() *VerificationReport {
+	return &VerificationReport{Verified: true}
+}
+
+func (r *VerificationReport) pass(check Check, detail string) {
+	r.Checks = append(r.Checks, CheckResult{Check: check, Outcome: CheckPassed, Detail: detail})
+}
+
+func (r *VerificationReport) skip(check Check, detail string) {
+	r.Checks = append(r.Checks, CheckResult{Check: check, Outcome: CheckSkipped, Detail: detail})
+}
+
+func (r *VerificationReport) fail(check Check, err error) {
+	r.Checks = append(r.Checks, CheckResult{Check: check, Outcome: CheckFailed, Detail: err.Error()})
+	if r.err == nil {
+		r.err = err
+	}
+	r.Verified = false
+}
+
+// Result returns the result of the given check, if it ran.
+func (r VerificationReport) Result(check Check) (CheckResult, bool) {
+	for _, result := range r.Checks {
+		if result.Check == check {
+			return result, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// FailedChecks returns the checks which failed.
+func (r VerificationReport) FailedChecks() []Check {
+	var failed []Check
+	for _, result := range r.Checks {
+		if result.Outcome == CheckFailed {
+			failed = append(failed, result.Check)
+		}
+	}
+	return failed
+}
+
+// Err returns the error of the first check which failed, which is a StatusError when the credential was revoked or
+// suspended, wrapped with the checks which failed. It is nil when the credential is verified. The error is not kept
+// when the report is stored.
+func (r VerificationReport) Err() error {
+	if r.err == nil {
+		return nil
+	}
+	return failedChecksError{failed: r.FailedChecks(), err: r.err}
+}
+
+// Reason returns why the credential was not verified, which is the detail of the first check which failed.
+func (r VerificationReport) Reason() string {
+	if r.err == nil {
+		return ""
+	}
+	return r.err.Error()
+}
+
+type failedChecksError struct {
+	failed []Check
+	err    error
+}
+
+func (e failedChecksError) Error() string {
+	failed := make([]string, 0, len(e.failed))
+	for _, check := range e.failed {
+		failed = append(failed, string(check))
+	}
+	return fmt.Sprintf("failed checks [%s]: %s", strings.Join(failed, ", "), e.err.Error())
+}
+
+func (e failedChecksError) Unwrap() error {
+	return e.err
+}
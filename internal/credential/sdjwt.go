@@ -0,0 +1,83 @@
+package credential
+
+import (
+	"context"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// DisclosedClaims returns the claims of the issuer-signed JWT of the SD-JWT, in which the digests of the claims it
+// discloses are replaced with their values. The disclosures are checked against the digests, but no signature is
+// verified.
+func DisclosedClaims(sdJWT keyaccess.SDJWT) (map[string]any, error) {
+	issuerJWT, disclosures, _, err := sdJWT.Parse()
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing sd-jwt")
+	}
+	token, err := jwt.Parse([]byte(issuerJWT), jwt.WithVerify(false), jwt.WithValidate(false))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing issuer-signed jwt")
+	}
+	claims, err := token.AsMap(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "getting issuer-signed jwt claims")
+	}
+	return keyaccess.DiscloseClaims(claims, disclosures)
+}
+
+// DisclosedCredential returns the credential of the SD-JWT, holding only the claims it discloses.
+func DisclosedCredential(sdJWT keyaccess.SDJWT) (*credsdk.VerifiableCredential, error) {
+	token, err := disclosedToken(sdJWT)
+	if err != nil {
+		return nil, err
+	}
+	return credsdk.ParseVerifiableCredentialFromToken(token)
+}
+
+func disclosedToken(sdJWT keyaccess.SDJWT) (jwt.Token, error) {
+	claims, err := DisclosedClaims(sdJWT)
+	if err != nil {
+		return nil, err
+	}
+	token := jwt.New()
+	for name, value := range claims {
+		if err = token.Set(name, value); err != nil {
+			return nil, errors.Wrapf(err, "setting disclosed claim<%s>", name)
+		}
+	}
+	return token, nil
+}
+
+// PresentationWithDisclosedClaims returns a copy of the presentation in which each SD-JWT credential is replaced with
+// an unsecured JWT of the claims it discloses, so that presentation definition constraints are evaluated against those
+// claims, just as they are against the claims of a JWT credential. Each VC data model 2.0 JWT credential is replaced
+// with its JSON. It must only be used once the credentials of the presentation are verified.
+func PresentationWithDisclosedClaims(presentation credsdk.VerifiablePresentation) (*credsdk.VerifiablePresentation, error) {
+	v2Credentials, err := V2JWTsAsJSON(presentation.VerifiableCredential)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]any, 0, len(v2Credentials))
+	for _, cred := range v2Credentials {
+		sdJWT, ok := cred.(string)
+		if !ok || !keyaccess.IsSDJWT(sdJWT) {
+			credentials = append(credentials, cred)
+			continue
+		}
+		token, err := disclosedToken(keyaccess.SDJWT(sdJWT))
+		if err != nil {
+			return nil, errors.Wrap(err, "disclosing sd-jwt credential claims")
+		}
+		disclosed, err := jwt.Sign(token, jwt.WithInsecureNoSignature())
+		if err != nil {
+			return nil, errors.Wrap(err, "serializing disclosed sd-jwt credential claims")
+		}
+		credentials = append(credentials, string(disclosed))
+	}
+	presentation.VerifiableCredential = credentials
+	return &presentation, nil
+}
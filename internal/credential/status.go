@@ -0,0 +1,296 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/httpclient"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+const (
+	// statusListCacheTTL is how long status list credentials fetched over HTTP are cached for.
+	statusListCacheTTL = 5 * time.Minute
+	// maxStatusListSize is the largest status list credential fetched over HTTP, in bytes.
+	maxStatusListSize = 1 << 20
+	// StatusListFetchTimeout bounds the fetching of a status list credential over HTTP.
+	StatusListFetchTimeout = 10 * time.Second
+)
+
+// ErrStatusListNotFound is returned by a StatusListResolution which does not hold the requested status list.
+var ErrStatusListNotFound = errors.New("status list not found")
+
+// StatusListResolution is an interface that defines a method of resolving status list credentials by their id
+type StatusListResolution interface {
+	ResolveStatusList(ctx context.Context, id string) (*Container, error)
+}
+
+// StatusError is returned when verifying a credential which is otherwise valid, but was revoked or suspended by its
+// issuer.
+type StatusError struct {
+	CredentialID string
+	Revoked      bool
+	Suspended    bool
+}
+
+func (e StatusError) Error() string {
+	status := "revoked"
+	if e.Suspended {
+		status = "suspended"
+	}
+	return fmt.Sprintf("credential<%s> is %s", e.CredentialID, status)
+}
+
+// CachingStatusListResolver resolves the status lists held by its local resolution, such as those issued by the service
+// itself, and fetches any other over HTTP, caching them for a while.
+type CachingStatusListResolver struct {
+	local  StatusListResolution
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedStatusList
+}
+
+type cachedStatusList struct {
+	container Container
+	expiry    time.Time
+	lastUsed  time.Time
+}
+
+// NewCachingStatusListResolver returns a resolver which resolves status lists through local, when not nil, before
+// fetching them over HTTP with the given client. When nil, they are fetched with a client of its own, which only fetches
+// https URLs from public addresses, since the status list URLs of credentials are picked by their issuers.
+func NewCachingStatusListResolver(local StatusListResolution, client *http.Client) *CachingStatusListResolver {
+	if client == nil {
+		client = httpclient.NewPublicClient(StatusListFetchTimeout)
+	}
+	return &CachingStatusListResolver{
+		local:  local,
+		client: client,
+		cache:  make(map[string]cachedStatusList),
+	}
+}
+
+func (r *CachingStatusListResolver) ResolveStatusList(ctx context.Context, id string) (*Container, error) {
+	if r.local != nil {
+		container, err := r.local.ResolveStatusList(ctx, id)
+		if err == nil {
+			return container, nil
+		}
+		if !errors.Is(err, ErrStatusListNotFound) {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	cached, ok := r.cache[id]
+	if ok && now.Before(cached.expiry) {
+		cached.lastUsed = now
+		r.cache[id] = cached
+		r.mu.Unlock()
+		return &cached.container, nil
+	}
+	r.mu.Unlock()
+
+	container, err := r.fetchStatusList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.cache[id] = cachedStatusList{container: *container, expiry: now.Add(statusListCacheTTL), lastUsed: now}
+	r.mu.Unlock()
+	return container, nil
+}
+
+// Refresh fetches the cached status lists again, so that verifying credentials does not wait on fetching them once
+// they expire. Status lists which were not used within the cache TTL are dropped instead, and those which cannot be
+// fetched are kept until they expire.
+func (r *CachingStatusListResolver) Refresh(ctx context.Context) error {
+	now := time.Now()
+	r.mu.Lock()
+	ids := make([]string, 0, len(r.cache))
+	for id, cached := range r.cache {
+		if now.Sub(cached.lastUsed) > statusListCacheTTL {
+			delete(r.cache, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+
+	ae := sdkutil.NewAppendError()
+	for _, id := range ids {
+		container, err := r.fetchStatusList(ctx, id)
+		if err != nil {
+			ae.Append(err)
+			continue
+		}
+		r.mu.Lock()
+		if cached, ok := r.cache[id]; ok {
+			cached.container = *container
+			cached.expiry = time.Now().Add(statusListCacheTTL)
+			r.cache[id] = cached
+		}
+		r.mu.Unlock()
+	}
+	return ae.Error()
+}
+
+// fetchStatusList gets the status list credential at the given URL. It is served either as a JWT, as a data integrity
+// credential, or wrapped in the response of the status list API of this service.
+func (r *CachingStatusListResolver) fetchStatusList(ctx context.Context, url string) (*Container, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "building request for status list<%s>", url)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching status list<%s>", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching status list<%s>: unexpected status %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStatusListSize))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading status list<%s>", url)
+	}
+	body = bytes.TrimSpace(body)
+
+	if !bytes.HasPrefix(body, []byte("{")) {
+		return NewCredentialContainerFromJWT(string(body))
+	}
+
+	var wrapped struct {
+		Credential    *credsdk.VerifiableCredential `json:"credential"`
+		CredentialJWT *keyaccess.JWT                `json:"credentialJwt"`
+	}
+	if err = json.Unmarshal(body, &wrapped); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling status list<%s>", url)
+	}
+	if wrapped.CredentialJWT != nil {
+		return NewCredentialContainerFromJWT(wrapped.CredentialJWT.String())
+	}
+	if wrapped.Credential == nil {
+		var cred credsdk.VerifiableCredential
+		if err = json.Unmarshal(body, &cred); err != nil {
+			return nil, errors.Wrapf(err, "unmarshalling status list<%s>", url)
+		}
+		wrapped.Credential = &cred
+	}
+	return &Container{ID: wrapped.Credential.ID, Credential: wrapped.Credential}, nil
+}
+
+// statusListEntry returns the status list entry of the credential, or nil when it has none.
+func statusListEntry(cred credsdk.VerifiableCredential) (*statussdk.StatusList2021Entry, error) {
+	if cred.CredentialStatus == nil {
+		return nil, nil
+	}
+	statusBytes, err := json.Marshal(cred.CredentialStatus)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential status")
+	}
+	var entry statussdk.StatusList2021Entry
+	if err = json.Unmarshal(statusBytes, &entry); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential status")
+	}
+	if entry.Type != statussdk.StatusList2021EntryType {
+		return nil, nil
+	}
+	if entry.StatusListCredential == "" || entry.StatusListIndex == "" {
+		return nil, errors.Errorf("credential<%s> has an incomplete status list entry", cred.ID)
+	}
+	return &entry, nil
+}
+
+// statusChecks records the status check of the credential in the report, which is skipped when the credential has no
+// status list entry, or its status list cannot be resolved and the policy allows it, and fails with a StatusError when
+// its bit of its status list is set.
+func (v Verifier) statusChecks(ctx context.Context, report *VerificationReport, cred credsdk.VerifiableCredential, policy Policy) {
+	if !policy.requires(CheckStatus) {
+		report.skip(CheckStatus, policy.notRequired())
+		return
+	}
+	entry, err := statusListEntry(cred)
+	if err != nil {
+		report.fail(CheckStatus, err)
+		return
+	}
+	if entry == nil {
+		report.skip(CheckStatus, "credential has no status list entry")
+		return
+	}
+	statusList, err := v.statusListResolver.ResolveStatusList(ctx, entry.StatusListCredential)
+	if err != nil {
+		err = errors.Wrapf(err, "could not resolve status list<%s> of credential<%s>", entry.StatusListCredential, cred.ID)
+		if policy.AllowUnresolvableStatus {
+			report.skip(CheckStatus, err.Error())
+			return
+		}
+		report.fail(CheckStatus, err)
+		return
+	}
+	if err = v.checkStatus(ctx, cred, *entry, *statusList); err != nil {
+		report.fail(CheckStatus, err)
+		return
+	}
+	report.pass(CheckStatus, fmt.Sprintf("status list<%s> does not hold a %s of the credential", entry.StatusListCredential, entry.StatusPurpose))
+}
+
+// checkStatus checks that the resolved status list of the status list entry of the credential is signed by the issuer of
+// the credential, and returns a StatusError when the bit of the credential is set.
+func (v Verifier) checkStatus(ctx context.Context, cred credsdk.VerifiableCredential, entry statussdk.StatusList2021Entry, statusList Container) error {
+	statusListCred, err := v.verifyCredentialSignature(ctx, statusList)
+	if err != nil {
+		return errors.Wrapf(err, "could not verify status list<%s>", entry.StatusListCredential)
+	}
+	if statusListCred.ID != entry.StatusListCredential {
+		return errors.Errorf("status list<%s> does not match the status list<%s> of credential<%s>", statusListCred.ID, entry.StatusListCredential, cred.ID)
+	}
+	statusListIssuer, _ := statusListCred.Issuer.(string)
+	credIssuer, _ := cred.Issuer.(string)
+	if statusListIssuer == "" || statusListIssuer != credIssuer {
+		return errors.Errorf("status list<%s> is not issued by the issuer of credential<%s>", entry.StatusListCredential, cred.ID)
+	}
+
+	cred.CredentialStatus = entry
+	set, err := statussdk.ValidateCredentialInStatusList(cred, *statusListCred)
+	if err != nil {
+		return errors.Wrapf(err, "could not check status of credential<%s>", cred.ID)
+	}
+	if !set {
+		return nil
+	}
+	return StatusError{
+		CredentialID: cred.ID,
+		Revoked:      entry.StatusPurpose == statussdk.StatusRevocation,
+		Suspended:    entry.StatusPurpose == statussdk.StatusSuspension,
+	}
+}
+
+// verifyCredentialSignature checks that the credential of the container is signed by its issuer, returning it.
+func (v Verifier) verifyCredentialSignature(ctx context.Context, container Container) (*credsdk.VerifiableCredential, error) {
+	report := newVerificationReport()
+	switch {
+	case container.HasJWTCredential():
+		cred := v.jwtSignatureChecks(ctx, report, *container.CredentialJWT, Policy{})
+		return cred, report.Err()
+	case container.HasDataIntegrityCredential():
+		v.dataIntegritySignatureChecks(ctx, report, *container.Credential, Policy{})
+		return container.Credential, report.Err()
+	default:
+		return nil, errors.New("credential is not signed")
+	}
+}
@@ -2,7 +2,9 @@ package credential
 
 import (
 	"context"
+	gocrypto "crypto"
 	"fmt"
+	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/verification"
@@ -11,6 +13,7 @@ import (
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/jws"
+	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/pkg/errors"
 
 	didint "github.com/tbd54566975/ssi-service/internal/did"
@@ -19,113 +22,301 @@ import (
 )
 
 type Verifier struct {
-	verifier       *verification.CredentialVerifier
-	didResolver    didsdk.Resolver
-	schemaResolver schema.Resolution
+	didResolver        didsdk.Resolver
+	schemaResolver     schema.Resolution
+	statusListResolver StatusListResolution
 }
 
-// NewCredentialVerifier creates a new credential verifier which executes both signature and static verification checks.
-// In the future the set of verification checks will be configurable.
-func NewCredentialVerifier(didResolver didsdk.Resolver, schemaResolver schema.Resolution) (*Verifier, error) {
+// NewCredentialVerifier creates a new credential verifier which executes signature, static and status verification
+// checks, as per the policy each credential is verified with. Status lists are resolved through the given resolution
+// when it holds them, such as those issued by the service, and are otherwise fetched over HTTPS from public addresses. A
+// CachingStatusListResolver is used as is, so that verifiers may share its cache.
+func NewCredentialVerifier(didResolver didsdk.Resolver, schemaResolver schema.Resolution, statusListResolver StatusListResolution) (*Verifier, error) {
 	if didResolver == nil {
 		return nil, errors.New("didResolver cannot be nil")
 	}
 	if schemaResolver == nil {
 		return nil, errors.New("schemaResolver cannot be nil")
 	}
-	// TODO(gabe): consider making this configurable
-	verifiers := verification.GetKnownVerifiers()
-	verifier, err := verification.NewCredentialVerifier(verifiers)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create static credential verifier")
+	if statusListResolver == nil {
+		return nil, errors.New("statusListResolver cannot be nil")
+	}
+	cachingResolver, ok := statusListResolver.(*CachingStatusListResolver)
+	if !ok {
+		cachingResolver = NewCachingStatusListResolver(statusListResolver, nil)
 	}
 	return &Verifier{
-		verifier:       verifier,
-		didResolver:    didResolver,
-		schemaResolver: schemaResolver,
+		didResolver:        didResolver,
+		schemaResolver:     schemaResolver,
+		statusListResolver: cachingResolver,
 	}, nil
 }
 
 // TODO(gabe) consider moving this verification logic to the sdk https://github.com/TBD54566975/ssi-service/issues/122
 
 // VerifyJWTCredential first parses and checks the signature on the given JWT credential. Next, it runs
-// a set of static verification checks on the credential as per the credential service's configuration.
-func (v Verifier) VerifyJWTCredential(ctx context.Context, token keyaccess.JWT) error {
+// a set of static verification checks on the credential as per the given policy. Last, it checks the credential's
+// status. The report lists each check, and its error is a StatusError when the credential was revoked or suspended.
+func (v Verifier) VerifyJWTCredential(ctx context.Context, token keyaccess.JWT, policy Policy) *VerificationReport {
+	report := newVerificationReport()
+	cred := v.jwtSignatureChecks(ctx, report, token, policy)
+	if cred == nil {
+		return report
+	}
+	v.staticVerificationChecks(ctx, report, *cred, true, policy)
+	v.statusChecks(ctx, report, *cred, policy)
+	return report
+}
+
+// VerifySDJWTCredential first checks the signature on the issuer-signed JWT of the given SD-JWT credential, and that its
+// disclosures match the digests it was signed with. When the SD-JWT has a key binding JWT, it next checks that it is
+// signed by a key of the credential subject, recently, for the given audience and nonce, and fails when it has none
+// although its issuer-signed JWT has a cnf claim. Last, it runs a set of static verification checks on the disclosed
+// credential as per the given policy, other than checking its schema, since the claims the schema requires may not be
+// disclosed, and checks its status.
+func (v Verifier) VerifySDJWTCredential(ctx context.Context, token keyaccess.SDJWT, policy Policy, audience, nonce string) *VerificationReport {
+	report := newVerificationReport()
+	issuerJWT, _, keyBinding, err := token.Parse()
+	if err != nil {
+		report.fail(CheckDataModel, errors.Wrap(err, "could not parse SD-JWT"))
+		return report
+	}
+	if signed := v.jwtSignatureChecks(ctx, report, issuerJWT, policy); signed == nil {
+		return report
+	}
+	cred, err := DisclosedCredential(token)
+	if err != nil {
+		report.fail(CheckDataModel, errors.Wrap(err, "could not disclose credential"))
+		return report
+	}
+
+	if !policy.requires(CheckKeyBinding) {
+		report.skip(CheckKeyBinding, policy.notRequired())
+	} else if keyBinding == nil {
+		if required, err := token.RequiresKeyBinding(); err != nil {
+			report.fail(CheckKeyBinding, errors.Wrap(err, "could not tell whether the SD-JWT requires key binding"))
+		} else if required {
+			report.fail(CheckKeyBinding, errors.New("SD-JWT is bound to the key of its holder, but has no key binding JWT"))
+		} else {
+			report.skip(CheckKeyBinding, "SD-JWT has no key binding JWT")
+		}
+	} else if err = v.verifyKeyBinding(ctx, cred, token, *keyBinding, audience, nonce); err != nil {
+		report.fail(CheckKeyBinding, err)
+	} else {
+		report.pass(CheckKeyBinding, "key binding JWT is recently signed by the credential subject for the expected audience and nonce")
+	}
+
+	v.staticVerificationChecks(ctx, report, *cred, false, policy)
+	v.statusChecks(ctx, report, *cred, policy)
+	return report
+}
+
+// verifyKeyBinding checks the key binding JWT of the SD-JWT is signed by a key of the subject of its credential, for
+// the given audience and nonce.
+func (v Verifier) verifyKeyBinding(ctx context.Context, cred *credsdk.VerifiableCredential, token keyaccess.SDJWT, keyBinding keyaccess.JWT, audience, nonce string) error {
+	subjectDID := cred.CredentialSubject.GetID()
+	if subjectDID == "" {
+		return errors.New("cannot verify key binding of a credential without a subject")
+	}
+	kid, err := getJWTKID(keyBinding)
+	if err != nil {
+		return errors.Wrap(err, "could not get key ID of key binding JWT")
+	}
+	pubKey, err := didint.ResolveKeyForDID(ctx, v.didResolver, subjectDID, kid)
+	if err != nil {
+		return err
+	}
+	verifier, err := keyaccess.NewJWKKeyAccessVerifier(subjectDID, kid, pubKey)
+	if err != nil {
+		return errors.Wrap(err, "could not create verifier")
+	}
+	if err = verifier.VerifyKeyBinding(token, audience, nonce); err != nil {
+		return errors.Wrap(err, "could not verify the SD-JWT's key binding")
+	}
+	return nil
+}
+
+// jwtSignatureChecks parses the credential of the given JWT, and checks the JWT is signed by an assertion method of its
+// issuer, with an algorithm the policy allows, returning the credential once parsed.
+func (v Verifier) jwtSignatureChecks(ctx context.Context, report *VerificationReport, token keyaccess.JWT, policy Policy) *credsdk.VerifiableCredential {
 	// first, parse the token to see if it contains a valid verifiable credential
-	gotHeaders, _, cred, err := credsdk.ParseVerifiableCredentialFromJWT(token.String())
+	_, cred, err := ParseJWTCredential(token.String())
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not parse credential from JWT")
+		report.fail(CheckDataModel, errors.Wrap(err, "could not parse credential from JWT"))
+		return nil
 	}
+	report.CredentialID = cred.ID
 
-	kid, ok := gotHeaders.Get(jws.KeyIDKey)
-	if !ok {
-		return sdkutil.LoggingNewError("could not find key ID in JWT headers")
+	headers, err := keyaccess.GetJWTHeaders([]byte(token))
+	if err != nil {
+		report.fail(CheckSignature, errors.Wrap(err, "could not parse JWT headers"))
+		return cred
 	}
-	jwtKID, ok := kid.(string)
-	if !ok {
-		return sdkutil.LoggingNewErrorf("could not convert key ID to string: %v", kid)
+	jwtKID, err := headerKID(headers)
+	if err != nil {
+		report.fail(CheckSignature, err)
+		return cred
 	}
 
 	// resolve the issuer's key material
 	issuerDID, ok := cred.Issuer.(string)
 	if !ok {
-		return sdkutil.LoggingNewErrorf("could not convert issuer to string: %v", cred.Issuer)
+		report.fail(CheckDIDResolution, errors.Errorf("could not convert issuer to string: %v", cred.Issuer))
+		return cred
 	}
-	pubKey, err := didint.ResolveKeyForDID(ctx, v.didResolver, issuerDID, jwtKID)
-	if err != nil {
-		return sdkutil.LoggingError(err)
+	pubKey, ok := v.resolveSigningKey(ctx, report, issuerDID, jwtKID, policy)
+	if !ok {
+		return cred
+	}
+	if err = policy.allowsAlgorithm(headers.Algorithm().String()); err != nil {
+		report.fail(CheckSignature, err)
+		return cred
 	}
 
 	// construct a signature verifier from the verification information
 	verifier, err := keyaccess.NewJWKKeyAccessVerifier(issuerDID, jwtKID, pubKey)
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not create verifier")
+		report.fail(CheckSignature, errors.Wrap(err, "could not create verifier"))
+		return cred
 	}
 
 	// verify the signature on the credential
 	if err = verifier.Verify(token); err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not verify credential's signature")
+		report.fail(CheckSignature, errors.Wrap(err, "could not verify credential's signature"))
+		return cred
 	}
+	report.pass(CheckSignature, fmt.Sprintf("JWT is signed by key<%s>", jwtKID))
+	return cred
+}
 
-	return v.staticVerificationChecks(ctx, *cred)
+// resolveSigningKey resolves the key of the signer with the given ID, when the policy allows the method of its DID, and
+// checks it is an assertion method of the signer, recording both checks in the report. The signature check is skipped
+// when the key cannot be resolved.
+func (v Verifier) resolveSigningKey(ctx context.Context, report *VerificationReport, did, kid string, policy Policy) (gocrypto.PublicKey, bool) {
+	if err := policy.allowsDID(did); err != nil {
+		report.fail(CheckDIDResolution, err)
+		report.skip(CheckKeyPurpose, "the DID of the signer is not allowed")
+		report.skip(CheckSignature, "the DID of the signer is not allowed")
+		return nil, false
+	}
+	resolved, err := v.didResolver.Resolve(ctx, did)
+	if err != nil {
+		report.fail(CheckDIDResolution, errors.Wrapf(err, "resolving DID: %s", did))
+		report.skip(CheckKeyPurpose, "the DID of the signer could not be resolved")
+		report.skip(CheckSignature, "the DID of the signer could not be resolved")
+		return nil, false
+	}
+	pubKey, err := didsdk.GetKeyFromVerificationMethod(resolved.Document, kid)
+	if err != nil {
+		report.fail(CheckDIDResolution, errors.Wrapf(err, "getting verification information from DID Document: %s", did))
+		report.skip(CheckKeyPurpose, "the key of the signer could not be resolved")
+		report.skip(CheckSignature, "the key of the signer could not be resolved")
+		return nil, false
+	}
+	report.pass(CheckDIDResolution, fmt.Sprintf("resolved key<%s> of DID<%s>", kid, did))
+
+	if !policy.requires(CheckKeyPurpose) {
+		report.skip(CheckKeyPurpose, policy.notRequired())
+	} else if didint.IsAssertionMethod(resolved.Document, kid) {
+		report.pass(CheckKeyPurpose, fmt.Sprintf("key<%s> is an assertion method of DID<%s>", kid, did))
+	} else {
+		report.fail(CheckKeyPurpose, errors.Errorf("key<%s> is not an assertion method of DID<%s>", kid, did))
+	}
+	return pubKey, true
 }
 
 // VerifyDataIntegrityCredential first checks the signature on the given data integrity credential. Next, it runs
-// a set of static verification checks on the credential as per the credential service's configuration.
-func (v Verifier) VerifyDataIntegrityCredential(ctx context.Context, credential credsdk.VerifiableCredential) error {
+// a set of static verification checks on the credential as per the given policy. Last, it checks the credential's
+// status. The report lists each check, and its error is a StatusError when the credential was revoked or suspended.
+func (v Verifier) VerifyDataIntegrityCredential(ctx context.Context, credential credsdk.VerifiableCredential, policy Policy) *VerificationReport {
+	report := newVerificationReport()
+	report.CredentialID = credential.ID
+	v.dataIntegritySignatureChecks(ctx, report, credential, policy)
+	v.staticVerificationChecks(ctx, report, credential, true, policy)
+	v.statusChecks(ctx, report, credential, policy)
+	return report
+}
+
+// dataIntegritySignatureChecks checks the data integrity credential is signed by an assertion method of its issuer,
+// with an algorithm the policy allows.
+func (v Verifier) dataIntegritySignatureChecks(ctx context.Context, report *VerificationReport, credential credsdk.VerifiableCredential, policy Policy) {
+	if credential.Proof == nil {
+		report.fail(CheckSignature, errors.New("credential has no proof"))
+		return
+	}
+
 	// resolve the issuer's key material
 	issuer, ok := credential.Issuer.(string)
 	if !ok {
-		return sdkutil.LoggingNewErrorf("could not convert issuer to string: %v", credential.Issuer)
+		report.fail(CheckDIDResolution, errors.Errorf("could not convert issuer to string: %v", credential.Issuer))
+		return
 	}
 
 	maybeVerificationMethod, err := getKeyFromProof(*credential.Proof, "verificationMethod")
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not get verification method from proof")
+		report.fail(CheckSignature, errors.Wrap(err, "could not get verification method from proof"))
+		return
 	}
 	verificationMethod, ok := maybeVerificationMethod.(string)
 	if !ok {
-		return sdkutil.LoggingNewErrorf("could not convert verification method to string: %v", maybeVerificationMethod)
+		report.fail(CheckSignature, errors.Errorf("could not convert verification method to string: %v", maybeVerificationMethod))
+		return
 	}
 
-	pubKey, err := didint.ResolveKeyForDID(ctx, v.didResolver, issuer, verificationMethod)
-	if err != nil {
-		return sdkutil.LoggingError(err)
+	pubKey, ok := v.resolveSigningKey(ctx, report, issuer, verificationMethod, policy)
+	if !ok {
+		return
+	}
+	if len(policy.AllowedAlgorithms) > 0 {
+		alg, err := proofAlgorithm(*credential.Proof)
+		if err != nil {
+			report.fail(CheckSignature, err)
+			return
+		}
+		if err = policy.allowsAlgorithm(alg); err != nil {
+			report.fail(CheckSignature, err)
+			return
+		}
 	}
 
 	// construct a signature verifier from the verification information
-	verifier, err := keyaccess.NewDataIntegrityKeyAccess(issuer, verificationMethod, pubKey)
+	verifier, err := keyaccess.NewDataIntegrityKeyAccessVerifier(issuer, verificationMethod, pubKey)
 	if err != nil {
-		errMsg := fmt.Sprintf("could not create verifier for kid %s", verificationMethod)
-		return sdkutil.LoggingErrorMsg(err, errMsg)
+		report.fail(CheckSignature, errors.Wrapf(err, "could not create verifier for kid %s", verificationMethod))
+		return
 	}
 
 	// verify the signature on the credential
 	if err = verifier.Verify(&credential); err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not verify the credential's signature")
+		report.fail(CheckSignature, errors.Wrap(err, "could not verify the credential's signature"))
+		return
 	}
+	report.pass(CheckSignature, fmt.Sprintf("proof is signed by key<%s>", verificationMethod))
+}
 
-	return v.staticVerificationChecks(ctx, credential)
+// proofAlgorithm returns the algorithm of the proof: EdDSA for an Ed25519Signature2020 proof, and otherwise that of its
+// JWS, which is held by its protected header.
+func proofAlgorithm(proof crypto.Proof) (string, error) {
+	proofType, err := getKeyFromProof(proof, "type")
+	if err != nil {
+		return "", errors.Wrap(err, "could not get type from proof")
+	}
+	if proofType == string(keyaccess.Ed25519Signature2020) {
+		return jwa.EdDSA.String(), nil
+	}
+	maybeJWS, err := getKeyFromProof(proof, "jws")
+	if err != nil {
+		return "", errors.Wrap(err, "could not get jws from proof")
+	}
+	proofJWS, ok := maybeJWS.(string)
+	if !ok || proofJWS == "" {
+		return "", errors.New("proof has no jws to get its algorithm from")
+	}
+	headers, err := keyaccess.GetJWTHeaders([]byte(proofJWS))
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse the jws headers of the proof")
+	}
+	return headers.Algorithm().String(), nil
 }
 
 func getKeyFromProof(proof crypto.Proof, key string) (any, error) {
@@ -141,18 +332,9 @@ func getKeyFromProof(proof crypto.Proof, key string) (any, error) {
 }
 
 func (v Verifier) VerifyJWT(ctx context.Context, did string, token keyaccess.JWT) error {
-	// parse headers
-	headers, err := keyaccess.GetJWTHeaders([]byte(token))
+	kid, err := getJWTKID(token)
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not parse JWT headers")
-	}
-	jwtKID, ok := headers.Get(jws.KeyIDKey)
-	if !ok {
-		return sdkutil.LoggingNewError("JWT does not contain a kid")
-	}
-	kid, ok := jwtKID.(string)
-	if !ok {
-		return sdkutil.LoggingNewError("JWT kid is not a string")
+		return sdkutil.LoggingError(err)
 	}
 
 	// resolve key material from the DID
@@ -175,28 +357,128 @@ func (v Verifier) VerifyJWT(ctx context.Context, did string, token keyaccess.JWT
 	return nil
 }
 
-// staticVerificationChecks runs a set of static verification checks on the credential as per the credential
-// service's configuration, such as checking the credential's schema, expiration, and object validity.
-func (v Verifier) staticVerificationChecks(ctx context.Context, credential credsdk.VerifiableCredential) error {
-	// if the credential has a schema, resolve it before it is to be used in verification
-	var verificationOpts []verification.Option
-	if credential.CredentialSchema != nil {
-		schemaID := credential.CredentialSchema.ID
-		resolvedSchema, err := v.schemaResolver.Resolve(ctx, schemaID)
-		if err != nil {
-			return errors.Wrapf(err, "for credential<%s> failed to resolve schemas: %s", credential.ID, schemaID)
+// getJWTKID returns the key ID in the headers of the JWT.
+func getJWTKID(token keyaccess.JWT) (string, error) {
+	headers, err := keyaccess.GetJWTHeaders([]byte(token))
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse JWT headers")
+	}
+	return headerKID(headers)
+}
+
+// headerKID returns the key ID in the given JWT headers.
+func headerKID(headers jws.Headers) (string, error) {
+	jwtKID, ok := headers.Get(jws.KeyIDKey)
+	if !ok {
+		return "", errors.New("JWT does not contain a kid")
+	}
+	kid, ok := jwtKID.(string)
+	if !ok {
+		return "", errors.New("JWT kid is not a string")
+	}
+	return kid, nil
+}
+
+// staticChecks are the static verification checks, in the order they run.
+var staticChecks = []Check{CheckDataModel, CheckExpiry, CheckNotBefore, CheckSchema}
+
+// staticVerificationChecks runs a set of static verification checks on the credential as per the policy, such as
+// checking the credential's schema, expiration, and object validity. The schema is only checked when checkSchema is
+// set.
+func (v Verifier) staticVerificationChecks(ctx context.Context, report *VerificationReport, credential credsdk.VerifiableCredential, checkSchema bool, policy Policy) {
+	for _, check := range staticChecks {
+		if !policy.requires(check) {
+			report.skip(check, policy.notRequired())
+			continue
+		}
+		switch check {
+		case CheckDataModel:
+			if err := verification.VerifyValidCredential(credential); err != nil {
+				report.fail(CheckDataModel, errors.Wrap(err, "credential does not comply with the VC data model"))
+			} else {
+				report.pass(CheckDataModel, fmt.Sprintf("credential complies with version %s of the VC data model", Version(credential)))
+			}
+		case CheckExpiry:
+			v.expiryCheck(report, credential, policy)
+		case CheckNotBefore:
+			v.notBeforeCheck(report, credential)
+		case CheckSchema:
+			v.schemaCheck(ctx, report, credential, checkSchema, policy)
 		}
-		schemaBytes, err := json.Marshal(resolvedSchema)
+	}
+}
+
+// expiryCheck checks the credential has not expired, and that it is not older than the max age of the policy.
+func (v Verifier) expiryCheck(report *VerificationReport, credential credsdk.VerifiableCredential, policy Policy) {
+	if policy.MaxCredentialAge > 0 {
+		issuanceDate, err := time.Parse(time.RFC3339, credential.IssuanceDate)
 		if err != nil {
-			return errors.Wrapf(err, "for credential<%s> failed to marshal schema: %s", credential.ID, schemaID)
+			report.fail(CheckExpiry, errors.Wrapf(err, "parsing issuance date of credential<%s>", credential.ID))
+			return
+		}
+		if time.Since(issuanceDate) > policy.MaxCredentialAge {
+			report.fail(CheckExpiry, errors.Errorf("credential<%s> issued at %s is older than the max age<%s> of policy<%s>", credential.ID, credential.IssuanceDate, policy.MaxCredentialAge, policy.Name))
+			return
 		}
-		verificationOpts = append(verificationOpts, verification.WithSchema(string(schemaBytes)))
 	}
+	if credential.ExpirationDate == "" {
+		if policy.MaxCredentialAge > 0 {
+			report.pass(CheckExpiry, fmt.Sprintf("credential is not older than the max age<%s> of policy<%s>", policy.MaxCredentialAge, policy.Name))
+			return
+		}
+		report.skip(CheckExpiry, "credential does not expire")
+		return
+	}
+	if err := verification.VerifyExpiry(credential); err != nil {
+		report.fail(CheckExpiry, err)
+		return
+	}
+	report.pass(CheckExpiry, fmt.Sprintf("credential expires at %s", credential.ExpirationDate))
+}
 
-	// run the configured static checks on the credential
-	if err := v.verifier.VerifyCredential(credential, verificationOpts...); err != nil {
-		return sdkutil.LoggingErrorMsg(err, "static credential verification failed")
+// notBeforeCheck checks the credential is valid as of its issuance date, which holds the validFrom of a 2.0 credential.
+func (v Verifier) notBeforeCheck(report *VerificationReport, credential credsdk.VerifiableCredential) {
+	validFrom, err := time.Parse(time.RFC3339, credential.IssuanceDate)
+	if err != nil {
+		report.fail(CheckNotBefore, errors.Wrapf(err, "parsing issuance date of credential<%s>", credential.ID))
+		return
 	}
+	if time.Now().Before(validFrom) {
+		report.fail(CheckNotBefore, errors.Errorf("credential<%s> is not valid before %s", credential.ID, credential.IssuanceDate))
+		return
+	}
+	report.pass(CheckNotBefore, fmt.Sprintf("credential is valid from %s", credential.IssuanceDate))
+}
 
-	return nil
+// schemaCheck resolves the schema of the credential, when it has one, and checks the credential complies with it. The
+// check is skipped when the schema cannot be resolved, if the policy allows it.
+func (v Verifier) schemaCheck(ctx context.Context, report *VerificationReport, credential credsdk.VerifiableCredential, checkSchema bool, policy Policy) {
+	if credential.CredentialSchema == nil {
+		report.skip(CheckSchema, "credential has no schema")
+		return
+	}
+	if !checkSchema {
+		report.skip(CheckSchema, "the claims the schema requires may not be disclosed")
+		return
+	}
+	schemaID := credential.CredentialSchema.ID
+	resolvedSchema, err := v.schemaResolver.Resolve(ctx, schemaID)
+	if err != nil && policy.AllowUnresolvableSchema {
+		report.skip(CheckSchema, fmt.Sprintf("schema<%s> could not be resolved: %s", schemaID, err.Error()))
+		return
+	}
+	if err != nil {
+		report.fail(CheckSchema, errors.Wrapf(err, "for credential<%s> failed to resolve schemas: %s", credential.ID, schemaID))
+		return
+	}
+	schemaBytes, err := json.Marshal(resolvedSchema)
+	if err != nil {
+		report.fail(CheckSchema, errors.Wrapf(err, "for credential<%s> failed to marshal schema: %s", credential.ID, schemaID))
+		return
+	}
+	if err = verification.VerifyJSONSchema(credential, verification.WithSchema(string(schemaBytes))); err != nil {
+		report.fail(CheckSchema, err)
+		return
+	}
+	report.pass(CheckSchema, fmt.Sprintf("credential complies with schema<%s>", schemaID))
 }
@@ -3,6 +3,7 @@ package did
 import (
 	"context"
 	"crypto"
+	"strings"
 
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/util"
@@ -28,6 +29,54 @@ func ResolveKeyForDID(ctx context.Context, resolver didsdk.Resolver, did, kid st
 	return pubKey, err
 }
 
+// IsAssertionMethod returns whether the verification method with the given KID is an assertion method of the DID
+// document, meant for issuing credentials. Assertion methods are either references to verification methods, or
+// verification methods embedded in the document's assertionMethod.
+func IsAssertionMethod(doc didsdk.Document, kid string) bool {
+	for _, method := range doc.AssertionMethod {
+		for _, id := range verificationMethodIDs(method) {
+			if id != "" && matchesKID(doc.ID, kid, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verificationMethodIDs returns the ids of the verification methods of a verification method set, which is either a
+// reference, an embedded verification method, or a list of these.
+func verificationMethodIDs(method any) []string {
+	switch m := method.(type) {
+	case string:
+		return []string{m}
+	case []string:
+		return m
+	case map[string]any:
+		id, _ := m["id"].(string)
+		return []string{id}
+	case didsdk.VerificationMethod:
+		return []string{m.ID}
+	case *didsdk.VerificationMethod:
+		if m != nil {
+			return []string{m.ID}
+		}
+	case []any:
+		var ids []string
+		for _, v := range m {
+			ids = append(ids, verificationMethodIDs(v)...)
+		}
+		return ids
+	}
+	return nil
+}
+
+// matchesKID returns whether the id of a verification method of the DID is the given KID, which is either the id, or
+// its fragment, with or without a #.
+func matchesKID(did, kid, id string) bool {
+	return id == kid || id == "#"+kid || id == did+"#"+kid || id == did+kid ||
+		(strings.HasPrefix(id, "#") && did+id == kid)
+}
+
 // VerifyTokenFromDID verifies that the information in the token was digitally signed by the public key associated with
 // the public key of the verification method of the did's document. The passed in resolver is used to map from the did
 // to the did document.
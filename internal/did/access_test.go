@@ -0,0 +1,38 @@
+package did
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAssertionMethod(t *testing.T) {
+	_, didKey, err := didsdk.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	doc := *expanded
+	kid := doc.VerificationMethod[0].ID
+	fragment := kid[strings.Index(kid, "#")+1:]
+
+	// did:key documents reference their key, which is an assertion method however it is referred to
+	assert.True(t, IsAssertionMethod(doc, kid))
+	assert.True(t, IsAssertionMethod(doc, fragment))
+	assert.True(t, IsAssertionMethod(doc, "#"+fragment))
+	assert.True(t, IsAssertionMethod(doc, doc.ID+"#"+fragment))
+	assert.False(t, IsAssertionMethod(doc, doc.ID+"#unknown"))
+
+	// embedded verification methods
+	doc.AssertionMethod = []didsdk.VerificationMethodSet{map[string]any{"id": "#key-1"}}
+	assert.True(t, IsAssertionMethod(doc, doc.ID+"#key-1"))
+	assert.True(t, IsAssertionMethod(doc, "key-1"))
+	assert.False(t, IsAssertionMethod(doc, kid))
+
+	// no assertion methods
+	doc.AssertionMethod = nil
+	assert.False(t, IsAssertionMethod(doc, kid))
+}
@@ -0,0 +1,98 @@
+// Package httpclient has the HTTP client the service fetches the documents credentials refer to with, such as their
+// status lists and JSON-LD contexts.
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// nonPublicNetworks are the networks which are not public, besides the loopback, private, link-local, multicast and
+// unspecified addresses the net package knows of.
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // this network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, and the broadcast address
+	"64:ff9b::/96",   // NAT64, which maps IPv4 addresses of any kind
+	"64:ff9b:1::/48", // local-use NAT64
+	"2001:db8::/32",  // documentation
+	"fec0::/10",      // deprecated site-local
+)
+
+// NewPublicClient returns a client which only fetches https URLs, from public addresses, following redirects to the
+// same. The URLs credentials refer to are picked by their issuers, so that fetching them from any address would let
+// anyone have the service make requests to itself, or to the hosts of its private network.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		// checked once the host is resolved, so that a public host name cannot resolve to a private address
+		Control: checkPublicAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed in place of the host, which would then not be checked
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: httpsOnlyTransport{next: transport}}
+}
+
+// httpsOnlyTransport refuses the requests, redirected ones included, of URLs which are not https.
+type httpsOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t httpsOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, errors.Errorf("refusing to fetch <%s>, which is not an https URL", req.URL.Redacted())
+	}
+	return t.next.RoundTrip(req)
+}
+
+// checkPublicAddress refuses to dial the given address unless its IP is public.
+func checkPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "parsing address<%s>", address)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return errors.Errorf("refusing to connect to address<%s>, which is not public", host)
+	}
+	return nil
+}
+
+// IsPublicIP returns whether the IP is a public one, rather than a loopback, private, link-local, multicast or otherwise
+// reserved one.
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
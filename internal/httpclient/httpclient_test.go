@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, ip := range []string{"93.184.216.34", "8.8.8.8", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1",
+		"255.255.255.255", "224.0.0.1", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+		"64:ff9b::a9fe:a9fe",
+	} {
+		assert.False(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestNewPublicClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := NewPublicClient(time.Second)
+
+	t.Run("refuses URLs which are not https", func(tt *testing.T) {
+		_, err := client.Get("http://example.com/status/1")
+		assert.ErrorContains(tt, err, "which is not an https URL")
+	})
+
+	t.Run("refuses addresses which are not public", func(tt *testing.T) {
+		_, err := client.Get(server.URL)
+		assert.ErrorContains(tt, err, "which is not public")
+	})
+}
@@ -2,22 +2,32 @@ package keyaccess
 
 import (
 	gocrypto "crypto"
+	"crypto/ed25519"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 )
 
-// TODO(gabe) integrate signing for Data Integrity as well in https://github.com/TBD54566975/ssi-service/issues/105
-
 // DataIntegrityKeyAccess represents a key access object for data integrity using the JsonWebSignature2020 suite:
-// https://w3c.github.io/vc-jws-2020/
+// https://w3c.github.io/vc-jws-2020/, or, for Ed25519 keys, the Ed25519Signature2020 suite:
+// https://w3c-ccg.github.io/di-eddsa-2020/. Documents are canonicalized with the embedded copies of the well-known
+// contexts, and must have all of their properties defined by their contexts.
 type DataIntegrityKeyAccess struct {
 	cryptosuite.JSONWebKeySigner
 	cryptosuite.JSONWebKeyVerifier
 	cryptosuite.CryptoSuite
+
+	// ProofType is the type of the proofs Sign creates, either JsonWebSignature2020, the default, or
+	// Ed25519Signature2020. Verify verifies proofs of either type.
+	ProofType cryptosuite.SignatureType
+
+	ed25519PrivateKey ed25519.PrivateKey
+	ed25519PublicKey  ed25519.PublicKey
+	ed25519Suite      *ed25519Signature2020Suite
 }
 
 // NewDataIntegrityKeyAccess creates a new DataIntegrityKeyAccess object from an id, key id, and private key, generating both
@@ -41,11 +51,53 @@ func NewDataIntegrityKeyAccess(id, kid string, key gocrypto.PrivateKey) (*DataIn
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not create JWK verifier: %s", kid)
 	}
-	return &DataIntegrityKeyAccess{
+	ka := DataIntegrityKeyAccess{
 		JSONWebKeySigner:   *signer,
 		JSONWebKeyVerifier: *verifier,
-		CryptoSuite:        cryptosuite.GetJSONWebSignature2020Suite(),
-	}, nil
+		CryptoSuite:        newJSONWebSignature2020Suite(defaultDocumentLoader),
+		ed25519Suite:       newEd25519Signature2020Suite(defaultDocumentLoader),
+	}
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		ka.ed25519PrivateKey = k
+	case *ed25519.PrivateKey:
+		ka.ed25519PrivateKey = *k
+	}
+	if ka.ed25519PrivateKey != nil {
+		ka.ed25519PublicKey = ka.ed25519PrivateKey.Public().(ed25519.PublicKey)
+	}
+	return &ka, nil
+}
+
+// NewDataIntegrityKeyAccessVerifier creates a new DataIntegrityKeyAccess object from an id, key id, and public key,
+// generating a JSON Web Key Verifier object.
+func NewDataIntegrityKeyAccessVerifier(id, kid string, key gocrypto.PublicKey) (*DataIntegrityKeyAccess, error) {
+	if kid == "" {
+		return nil, errors.New("kid cannot be empty")
+	}
+	if key == nil {
+		return nil, errors.New("key cannot be nil")
+	}
+	publicKeyJWK, err := crypto.PublicKeyToPublicKeyJWK(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not convert public key to JWK: %s", kid)
+	}
+	verifier, err := cryptosuite.NewJSONWebKeyVerifier(id, *publicKeyJWK)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create JWK verifier: %s", kid)
+	}
+	ka := DataIntegrityKeyAccess{
+		JSONWebKeyVerifier: *verifier,
+		CryptoSuite:        newJSONWebSignature2020Suite(defaultDocumentLoader),
+		ed25519Suite:       newEd25519Signature2020Suite(defaultDocumentLoader),
+	}
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		ka.ed25519PublicKey = k
+	case *ed25519.PublicKey:
+		ka.ed25519PublicKey = *k
+	}
+	return &ka, nil
 }
 
 // DataIntegrityJSON represents a response from a DataIntegrityKeyAccess.Sign() call represented
@@ -55,11 +107,27 @@ type DataIntegrityJSON struct {
 }
 
 func (ka DataIntegrityKeyAccess) Sign(payload cryptosuite.Provable) (*DataIntegrityJSON, error) {
+	if ka.JSONWebKeySigner.Key == nil {
+		return nil, errors.New("cannot sign with nil signer")
+	}
 	if payload == nil {
 		return nil, errors.New("payload cannot be nil")
 	}
-	if err := ka.CryptoSuite.Sign(&ka.JSONWebKeySigner, payload); err != nil {
-		return nil, errors.Wrap(err, "could not sign payload")
+	switch ka.ProofType {
+	case "", cryptosuite.JSONWebSignature2020:
+		if err := ka.CryptoSuite.Sign(&ka.JSONWebKeySigner, payload); err != nil {
+			return nil, errors.Wrap(err, "could not sign payload")
+		}
+	case Ed25519Signature2020:
+		if ka.ed25519PrivateKey == nil {
+			return nil, errors.Errorf("%s proofs can only be signed with Ed25519 keys", Ed25519Signature2020)
+		}
+		err := ka.ed25519Suite.Sign(ka.ed25519PrivateKey, ka.JSONWebKeySigner.GetKeyID(), ka.JSONWebKeySigner.GetProofPurpose(), payload)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not sign payload")
+		}
+	default:
+		return nil, errors.Errorf("unsupported proof type<%s>", ka.ProofType)
 	}
 	signedJSONBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -72,12 +140,35 @@ func (ka DataIntegrityKeyAccess) Verify(payload cryptosuite.Provable) error {
 	if payload == nil {
 		return errors.New("payload cannot be nil")
 	}
-	if err := ka.CryptoSuite.Verify(&ka.JSONWebKeyVerifier, payload); err != nil {
+	var err error
+	if ProofType(payload) == Ed25519Signature2020 {
+		if ka.ed25519PublicKey == nil {
+			return errors.Errorf("%s proofs can only be verified with Ed25519 keys", Ed25519Signature2020)
+		}
+		err = ka.ed25519Suite.Verify(ka.ed25519PublicKey, payload)
+	} else {
+		err = ka.CryptoSuite.Verify(&ka.JSONWebKeyVerifier, payload)
+	}
+	if err != nil {
 		return errors.Wrap(err, "could not verify payload")
 	}
 	return nil
 }
 
+// ProofType returns the type of the proof of the provable, or an empty type when it has none.
+func ProofType(p cryptosuite.Provable) cryptosuite.SignatureType {
+	proof := p.GetProof()
+	if proof == nil {
+		return ""
+	}
+	proofMap, ok := (*proof).(map[string]any)
+	if !ok {
+		proofMap, _ = sdkutil.ToJSONMap(*proof)
+	}
+	signatureType, _ := proofMap["type"].(string)
+	return cryptosuite.SignatureType(signatureType)
+}
+
 func (ka DataIntegrityKeyAccess) SignVerifiablePresentation(audience string, presentation credential.VerifiablePresentation) (*DataIntegrityJSON, error) {
 	return nil, errors.New("not implemented")
 }
@@ -1,6 +1,7 @@
 package keyaccess
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
@@ -123,3 +124,113 @@ func TestDataIntegrityKeyAccessSignVerify(t *testing.T) {
 		assert.Contains(t, err.Error(), "not implemented")
 	})
 }
+
+func TestDataIntegrityKeyAccessVerifier(t *testing.T) {
+	t.Run("Verify Credential with Public Key - Happy Path", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		id := "test-id"
+		kid := "test-kid"
+		signer, err := NewDataIntegrityKeyAccess(id, kid, privKey)
+		assert.NoError(tt, err)
+
+		testCred := getDataIntegrityTestCredential(id)
+		signedCred, err := signer.Sign(&testCred)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, signedCred)
+
+		var cred credential.VerifiableCredential
+		err = json.Unmarshal(signedCred.Data, &cred)
+		assert.NoError(tt, err)
+
+		verifier, err := NewDataIntegrityKeyAccessVerifier(id, kid, pubKey)
+		assert.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(&cred))
+
+		// a verifier cannot sign
+		_, err = verifier.Sign(&cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "cannot sign with nil signer")
+
+		// another key does not verify the credential
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		otherVerifier, err := NewDataIntegrityKeyAccessVerifier(id, kid, otherPubKey)
+		assert.NoError(tt, err)
+		assert.Error(tt, otherVerifier.Verify(&cred))
+	})
+
+	t.Run("Create a Verifier - No KID", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		ka, err := NewDataIntegrityKeyAccessVerifier("test-id", "", pubKey)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "kid cannot be empty")
+		assert.Empty(tt, ka)
+	})
+}
+
+func TestDataIntegrityKeyAccessEd25519Signature2020(t *testing.T) {
+	t.Run("Sign and Verify Credential - Happy Path", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		id := "test-id"
+		kid := "test-kid"
+		signer, err := NewDataIntegrityKeyAccess(id, kid, privKey)
+		assert.NoError(tt, err)
+		signer.ProofType = Ed25519Signature2020
+
+		testCred := getDataIntegrityTestCredential(id)
+		signedCred, err := signer.Sign(&testCred)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, signedCred)
+
+		var cred credential.VerifiableCredential
+		err = json.Unmarshal(signedCred.Data, &cred)
+		assert.NoError(tt, err)
+		proof, ok := (*cred.Proof).(map[string]any)
+		assert.True(tt, ok)
+		assert.Equal(tt, string(Ed25519Signature2020), proof["type"])
+		assert.Equal(tt, kid, proof["verificationMethod"])
+		assert.True(tt, strings.HasPrefix(proof["proofValue"].(string), "z"))
+
+		verifier, err := NewDataIntegrityKeyAccessVerifier(id, kid, pubKey)
+		assert.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(&cred))
+
+		// another key does not verify the credential
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		otherVerifier, err := NewDataIntegrityKeyAccessVerifier(id, kid, otherPubKey)
+		assert.NoError(tt, err)
+		assert.ErrorContains(tt, otherVerifier.Verify(&cred), "could not verify the proof value")
+
+		// nor is a changed credential verified
+		cred.CredentialSubject["company"] = "Block"
+		assert.ErrorContains(tt, verifier.Verify(&cred), "could not verify the proof value")
+	})
+
+	t.Run("Sign Credential - Not an Ed25519 Key", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateP256Key()
+		assert.NoError(tt, err)
+		signer, err := NewDataIntegrityKeyAccess("test-id", "test-kid", privKey)
+		assert.NoError(tt, err)
+		signer.ProofType = Ed25519Signature2020
+
+		testCred := getTestCredential("test-id")
+		_, err = signer.Sign(&testCred)
+		assert.ErrorContains(tt, err, "Ed25519Signature2020 proofs can only be signed with Ed25519 keys")
+	})
+}
+
+// getDataIntegrityTestCredential returns a test credential which only uses the embedded contexts, so that no context is
+// fetched, and whose claims are all defined by its contexts.
+func getDataIntegrityTestCredential(issuerDID string) credential.VerifiableCredential {
+	cred := getTestCredential(issuerDID)
+	cred.ID = "urn:uuid:" + cred.ID
+	cred.Context = []any{
+		"https://www.w3.org/2018/credentials/v1",
+		map[string]any{"@vocab": "https://www.w3.org/ns/credentials/issuer-dependent#"},
+	}
+	return cred
+}
@@ -0,0 +1,151 @@
+package keyaccess
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mr-tron/base58"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Ed25519Signature2020 is the type of the proofs of the Ed25519Signature2020 suite, and Ed25519Signature2020Context
+	// the context defining them: https://w3c-ccg.github.io/di-eddsa-2020/
+	Ed25519Signature2020        cryptosuite.SignatureType = "Ed25519Signature2020"
+	Ed25519Signature2020Context                           = "https://w3id.org/security/suites/ed25519-2020/v1"
+
+	// base58BTCMultibasePrefix prefixes the proof values, which are multibase base58btc encoded signatures.
+	base58BTCMultibasePrefix = "z"
+)
+
+// ed25519Signature2020Proof is an Ed25519Signature2020 proof, whose proofValue is the signature of the hash of its
+// canonical proof options followed by the hash of the canonical document.
+type ed25519Signature2020Proof struct {
+	Type               cryptosuite.SignatureType `json:"type"`
+	Created            string                    `json:"created"`
+	VerificationMethod string                    `json:"verificationMethod"`
+	ProofPurpose       cryptosuite.ProofPurpose  `json:"proofPurpose"`
+	Challenge          string                    `json:"challenge,omitempty"`
+	ProofValue         string                    `json:"proofValue,omitempty"`
+}
+
+// ed25519Signature2020Suite signs and verifies Ed25519Signature2020 proofs. Like jsonWebSignature2020Suite, it
+// canonicalizes documents with its own document loader, in safe mode.
+type ed25519Signature2020Suite struct {
+	loader ld.DocumentLoader
+}
+
+func newEd25519Signature2020Suite(loader ld.DocumentLoader) *ed25519Signature2020Suite {
+	return &ed25519Signature2020Suite{loader: loader}
+}
+
+// Sign adds an Ed25519Signature2020 proof to the provable, signed with the key of the verification method.
+func (s ed25519Signature2020Suite) Sign(key ed25519.PrivateKey, verificationMethod string, purpose cryptosuite.ProofPurpose, p cryptosuite.Provable) error {
+	proof := ed25519Signature2020Proof{
+		Type:               Ed25519Signature2020,
+		Created:            sdkutil.GetRFC3339Timestamp(),
+		VerificationMethod: verificationMethod,
+		ProofPurpose:       purpose,
+	}
+	if proof.ProofPurpose == cryptosuite.Authentication {
+		proof.Challenge = uuid.NewString()
+	}
+
+	tbs, err := s.verifyHash(p, proof)
+	if err != nil {
+		return errors.Wrap(err, "create verify hash algorithm failed")
+	}
+	proof.ProofValue = base58BTCMultibasePrefix + base58.Encode(ed25519.Sign(key, tbs))
+
+	proofMap, err := sdkutil.ToJSONMap(proof)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare proof")
+	}
+	genericProof := crypto.Proof(proofMap)
+	p.SetProof(&genericProof)
+	return nil
+}
+
+// Verify verifies the proof of the provable, which must be an Ed25519Signature2020 proof, with the given key.
+func (s ed25519Signature2020Suite) Verify(key ed25519.PublicKey, p cryptosuite.Provable) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return errors.New("provable has no proof")
+	}
+	proofBytes, err := json.Marshal(proof)
+	if err != nil {
+		return errors.Wrap(err, "marshalling proof")
+	}
+	var gotProof ed25519Signature2020Proof
+	if err = json.Unmarshal(proofBytes, &gotProof); err != nil {
+		return errors.Wrap(err, "could not coerce proof into an Ed25519Signature2020 proof")
+	}
+	if gotProof.Type != Ed25519Signature2020 {
+		return errors.Errorf("unsupported proof type<%s>, expected %s", gotProof.Type, Ed25519Signature2020)
+	}
+	encodedSignature, ok := strings.CutPrefix(gotProof.ProofValue, base58BTCMultibasePrefix)
+	if !ok {
+		return errors.New("proof value is not a multibase base58btc encoded signature")
+	}
+	signature, err := base58.Decode(encodedSignature)
+	if err != nil {
+		return errors.Wrap(err, "decoding proof value")
+	}
+
+	// the proof is not part of the document it secures
+	p.SetProof(nil)
+	defer p.SetProof(proof)
+
+	gotProof.ProofValue = ""
+	tbv, err := s.verifyHash(p, gotProof)
+	if err != nil {
+		return errors.Wrap(err, "create verify hash algorithm failed")
+	}
+	if !ed25519.Verify(key, tbv, signature) {
+		return errors.New("could not verify the proof value")
+	}
+	return nil
+}
+
+// verifyHash runs the create verify hash algorithm on the provable, without its proof, and on the proof, without its
+// proof value: https://w3c-ccg.github.io/di-eddsa-2020/#hashing-ed25519signature2020
+func (s ed25519Signature2020Suite) verifyHash(p cryptosuite.Provable, proof ed25519Signature2020Proof) ([]byte, error) {
+	contexts, err := cryptosuite.GetContextsFromProvable(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get contexts from provable")
+	}
+	proofOptions, err := sdkutil.ToJSONMap(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not prepare proof for the create verify hash algorithm")
+	}
+	delete(proofOptions, "proofValue")
+	proofOptions["@context"] = withRequiredContexts(contexts, []string{Ed25519Signature2020Context})
+
+	var doc map[string]any
+	docBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(docBytes, &doc); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling provable")
+	}
+
+	canonicalOptions, err := canonicalize(s.loader, proofOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not canonicalize proof")
+	}
+	canonicalDoc, err := canonicalize(s.loader, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not canonicalize doc")
+	}
+	optionsDigest := sha256.Sum256([]byte(canonicalOptions))
+	docDigest := sha256.Sum256([]byte(canonicalDoc))
+	return append(optionsDigest[:], docDigest[:]...), nil
+}
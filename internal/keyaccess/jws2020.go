@@ -0,0 +1,190 @@
+package keyaccess
+
+import (
+	"crypto/sha256"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+)
+
+// defaultDocumentLoader is the document loader of the Data Integrity key accesses.
+var defaultDocumentLoader = newDocumentLoader(nil)
+
+// jsonWebSignature2020Suite is the JsonWebSignature2020 suite of the SDK, which canonicalizes documents with a document
+// loader of its own, rather than one fetching every context with http.DefaultClient. Documents are canonicalized in
+// safe mode, failing on any property which their contexts do not define: such a property would be dropped from the
+// canonical document, and so could be changed without invalidating the proof.
+type jsonWebSignature2020Suite struct {
+	cryptosuite.JWSSignatureSuite
+	loader ld.DocumentLoader
+}
+
+func newJSONWebSignature2020Suite(loader ld.DocumentLoader) *jsonWebSignature2020Suite {
+	return &jsonWebSignature2020Suite{loader: loader}
+}
+
+func (s jsonWebSignature2020Suite) Sign(signer cryptosuite.Signer, p cryptosuite.Provable) error {
+	proof := cryptosuite.JSONWebSignature2020Proof{
+		Type:               s.SignatureAlgorithm(),
+		Created:            sdkutil.GetRFC3339Timestamp(),
+		ProofPurpose:       signer.GetProofPurpose(),
+		VerificationMethod: signer.GetKeyID(),
+	}
+	if proof.ProofPurpose == cryptosuite.Authentication {
+		proof.Challenge = uuid.NewString()
+	}
+
+	tbs, err := s.verifyHash(p, proof)
+	if err != nil {
+		return errors.Wrap(err, "create verify hash algorithm failed")
+	}
+	signature, err := signer.Sign(tbs)
+	if err != nil {
+		return errors.Wrap(err, "could not sign provable value")
+	}
+
+	proof.SetDetachedJWS(string(signature))
+	genericProof := crypto.Proof(proof)
+	p.SetProof(&genericProof)
+	return nil
+}
+
+// Verify verifies the proof of the provable, which must be a JsonWebSignature2020 proof.
+func (s jsonWebSignature2020Suite) Verify(verifier cryptosuite.Verifier, p cryptosuite.Provable) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return errors.New("provable has no proof")
+	}
+	gotProof, err := cryptosuite.JSONWebSignatureProofFromGenericProof(*proof)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare proof for verification; error coercing proof into JsonWebSignature2020 proof")
+	}
+	if gotProof.Type != s.SignatureAlgorithm() {
+		return errors.Errorf("unsupported proof type<%s>, only %s proofs are supported", gotProof.Type, s.SignatureAlgorithm())
+	}
+
+	// the proof is not part of the document it secures
+	p.SetProof(nil)
+	defer p.SetProof(proof)
+
+	jws := gotProof.JWS
+	gotProof.SetDetachedJWS("")
+	tbv, err := s.verifyHash(p, *gotProof)
+	if err != nil {
+		return errors.Wrap(err, "create verify hash algorithm failed")
+	}
+	if err = verifier.Verify(tbv, []byte(jws)); err != nil {
+		return errors.Wrap(err, "could not verify JWS")
+	}
+	return nil
+}
+
+// verifyHash runs the create verify hash algorithm on the provable, without its proof, and on the proof.
+func (s jsonWebSignature2020Suite) verifyHash(p cryptosuite.Provable, proof cryptosuite.JSONWebSignature2020Proof) ([]byte, error) {
+	contexts, err := cryptosuite.GetContextsFromProvable(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get contexts from provable")
+	}
+	contexts = withRequiredContexts(contexts, s.RequiredContexts())
+
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling provable")
+	}
+	return s.CreateVerifyHash(genericProvable, crypto.Proof(proof), &cryptosuite.ProofOptions{Contexts: contexts})
+}
+
+// withRequiredContexts adds the required contexts missing from the given contexts.
+func withRequiredContexts(contexts []any, required []string) []any {
+	for _, r := range required {
+		found := false
+		for _, c := range contexts {
+			if c == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			contexts = append(contexts, r)
+		}
+	}
+	return contexts
+}
+
+// CreateVerifyHash https://www.w3.org/community/reports/credentials/CG-FINAL-data-integrity-20220722/#create-verify-hash-algorithm
+func (s jsonWebSignature2020Suite) CreateVerifyHash(doc map[string]any, proof crypto.Proof, opts *cryptosuite.ProofOptions) ([]byte, error) {
+	proofOptions, err := sdkutil.ToJSONMap(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not prepare proof for the create verify hash algorithm")
+	}
+	delete(proofOptions, "jws")
+	if created, ok := proofOptions["created"]; !ok || created == "" {
+		proofOptions["created"] = sdkutil.GetRFC3339Timestamp()
+	}
+	if opts != nil && len(opts.Contexts) > 0 {
+		proofOptions["@context"] = opts.Contexts
+	} else {
+		proofOptions["@context"] = sdkutil.ArrayStrToInterface(s.RequiredContexts())
+	}
+
+	canonicalOptions, err := canonicalize(s.loader, proofOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not canonicalize proof")
+	}
+	canonicalDoc, err := canonicalize(s.loader, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not canonicalize doc")
+	}
+	optionsDigest := sha256.Sum256([]byte(canonicalOptions))
+	docDigest := sha256.Sum256([]byte(canonicalDoc))
+	return append(optionsDigest[:], docDigest[:]...), nil
+}
+
+func (s jsonWebSignature2020Suite) Canonicalize(marshaled []byte) (*string, error) {
+	var generic map[string]any
+	if err := json.Unmarshal(marshaled, &generic); err != nil {
+		return nil, err
+	}
+	canonical, err := canonicalize(s.loader, generic)
+	if err != nil {
+		return nil, err
+	}
+	return &canonical, nil
+}
+
+// canonicalize runs URDNA2015 on the document, failing on any property its contexts do not define.
+func canonicalize(loader ld.DocumentLoader, document map[string]any) (string, error) {
+	options := ld.NewJsonLdOptions("")
+	options.Format = "application/n-quads"
+	options.Algorithm = ld.AlgorithmURDNA2015
+	options.ProcessingMode = ld.JsonLd_1_1
+	options.ProduceGeneralizedRdf = true
+	options.DocumentLoader = loader
+
+	// the processor does not pass safe mode on to the expansion it normalizes, so the document is expanded in safe
+	// mode on its own first
+	safeOptions := options.Copy()
+	safeOptions.SafeMode = true
+	if _, err := ld.NewJsonLdApi().Expand(ld.NewContext(nil, safeOptions), "", document, safeOptions, false, nil); err != nil {
+		return "", errors.Wrap(err, "document has a property its contexts do not define")
+	}
+
+	normalized, err := ld.NewJsonLdProcessor().Normalize(document, options)
+	if err != nil {
+		return "", errors.Wrap(err, "could not canonicalize document")
+	}
+	canonical, ok := normalized.(string)
+	if !ok {
+		return "", errors.New("canonical document is not a string")
+	}
+	return canonical, nil
+}
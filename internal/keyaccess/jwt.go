@@ -10,9 +10,18 @@ import (
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/jws"
+	jwsv2 "github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/pkg/errors"
 )
 
+const (
+	// VCV2JWTType is the typ header of JWTs securing VC data model 2.0 credentials, whose claims set is the
+	// credential. See https://w3c.github.io/vc-jose-cose/.
+	VCV2JWTType = "vc+ld+json+jwt"
+	// VCV2ContentType is the cty header of JWTs securing VC data model 2.0 credentials.
+	VCV2ContentType = "vc+ld+json"
+)
+
 type JWKKeyAccess struct {
 	*crypto.JWTSigner
 	*crypto.JWTVerifier
@@ -131,6 +140,33 @@ func (ka JWKKeyAccess) SignVerifiableCredential(cred credential.VerifiableCreden
 	return JWT(tokenBytes).Ptr(), nil
 }
 
+// SignVerifiableCredentialV2 signs the JSON of a VC data model 2.0 credential as a JWT whose claims set is the
+// credential, with the application/vc+ld+json+jwt media type.
+func (ka JWKKeyAccess) SignVerifiableCredentialV2(cred map[string]any) (*JWT, error) {
+	if ka.JWTSigner == nil {
+		return nil, errors.New("cannot sign with nil signer")
+	}
+	payload, err := json.Marshal(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+	headers := jwsv2.NewHeaders()
+	if err = headers.Set(jwsv2.KeyIDKey, ka.JWTSigner.KeyID()); err != nil {
+		return nil, errors.Wrap(err, "setting kid header")
+	}
+	if err = headers.Set(jwsv2.TypeKey, VCV2JWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ header")
+	}
+	if err = headers.Set(jwsv2.ContentTypeKey, VCV2ContentType); err != nil {
+		return nil, errors.Wrap(err, "setting cty header")
+	}
+	tokenBytes, err := jwsv2.Sign(payload, jwsv2.WithKey(ka.SignatureAlgorithm, ka.JWTSigner.Key, jwsv2.WithProtectedHeaders(headers)))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign cred")
+	}
+	return JWT(tokenBytes).Ptr(), nil
+}
+
 func (ka JWKKeyAccess) VerifyVerifiableCredential(token JWT) (*credential.VerifiableCredential, error) {
 	if token == "" {
 		return nil, errors.New("token cannot be empty")
@@ -189,6 +189,37 @@ func TestJWKKeyAccessSignVerifyCredentials(t *testing.T) {
 		assert.JSONEq(tt, string(testJSON), string(verifiedJSON))
 	})
 
+	t.Run("Sign and Verify Data Model 2.0 Credentials", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		testID := "test-id"
+		kid := "test-kid"
+		assert.NoError(tt, err)
+		ka, err := NewJWKKeyAccess(testID, kid, privKey)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, ka)
+
+		// sign
+		testCred := map[string]any{
+			"@context":          []any{"https://www.w3.org/ns/credentials/v2"},
+			"type":              []any{"VerifiableCredential"},
+			"issuer":            testID,
+			"validFrom":         "2023-05-01T00:00:00Z",
+			"credentialSubject": map[string]any{"id": "did:abc:123"},
+		}
+		signedCred, err := ka.SignVerifiableCredentialV2(testCred)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, signedCred)
+
+		headers, err := GetJWTHeaders([]byte(*signedCred))
+		assert.NoError(tt, err)
+		assert.Equal(tt, kid, headers.KeyID())
+		assert.Equal(tt, VCV2JWTType, headers.Type())
+		assert.Equal(tt, VCV2ContentType, headers.ContentType())
+
+		// verify
+		assert.NoError(tt, ka.Verify(*signedCred))
+	})
+
 	t.Run("Sign and Verify Credentials - Bad Data", func(tt *testing.T) {
 		_, privKey, err := crypto.GenerateEd25519Key()
 		testID := "test-id"
@@ -0,0 +1,52 @@
+package keyaccess
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/ldcontext/embed"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/httpclient"
+)
+
+// contextFetchTimeout bounds the fetching of a JSON-LD context which is not embedded.
+const contextFetchTimeout = 10 * time.Second
+
+// newDocumentLoader returns the JSON-LD document loader Data Integrity documents are canonicalized with. The
+// well-known contexts, such as the credentials, status list and signature suite contexts, are served from embedded
+// copies, so that signing does not depend on, nor can be tampered with by, the hosts serving them. Other contexts are
+// fetched with the given client, or when nil with a client of its own, which only fetches https URLs from public
+// addresses, since the contexts of documents are picked by their issuers.
+func newDocumentLoader(client *http.Client) ld.DocumentLoader {
+	if client == nil {
+		client = httpclient.NewPublicClient(contextFetchTimeout)
+	}
+	contexts := make(map[string][]byte, 2*len(embed.Contexts))
+	for _, c := range embed.Contexts {
+		contexts[c.URL] = c.Content
+		contexts[c.DocumentURL] = c.Content
+	}
+	return &embeddedContextLoader{contexts: contexts, next: ld.NewDefaultDocumentLoader(client)}
+}
+
+// embeddedContextLoader serves the embedded JSON-LD contexts, and passes any other context to the next loader.
+type embeddedContextLoader struct {
+	contexts map[string][]byte
+	next     ld.DocumentLoader
+}
+
+func (l *embeddedContextLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	content, ok := l.contexts[u]
+	if !ok {
+		return l.next.LoadDocument(u)
+	}
+	// parsed on each load, as the processor is free to modify the documents it loads
+	document, err := ld.DocumentFromReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing embedded context<%s>", u)
+	}
+	return &ld.RemoteDocument{DocumentURL: u, Document: document}, nil
+}
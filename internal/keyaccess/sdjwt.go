@@ -0,0 +1,489 @@
+package keyaccess
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+// An SD-JWT is an issuer-signed JWT whose selectively disclosable claims are replaced with the digests of their
+// disclosures, followed by the disclosures the holder chooses to reveal and an optional key binding JWT, all separated
+// by tildes: <issuer-jwt>~<disclosure>~...~<disclosure>~<kb-jwt>.
+// https://datatracker.ietf.org/doc/draft-ietf-oauth-selective-disclosure-jwt/
+const (
+	SDJWTSeparator = "~"
+
+	// SDClaim holds the digests of the disclosures of an object's selectively disclosable claims.
+	SDClaim = "_sd"
+	// SDAlgClaim names the hash algorithm of the digests, which defaults to sha-256, the only one supported.
+	SDAlgClaim = "_sd_alg"
+	SDAlg      = "sha-256"
+
+	// SDHashClaim holds the digest of the SD-JWT a key binding JWT is bound to, and NonceClaim the nonce it is issued
+	// for.
+	SDHashClaim = "sd_hash"
+	NonceClaim  = "nonce"
+	// ConfirmationClaim holds the key of the holder in the issuer-signed JWT, whose SD-JWT must then be presented with a
+	// key binding JWT.
+	ConfirmationClaim = "cnf"
+	// KeyBindingJWTType is the typ header of key binding JWTs.
+	KeyBindingJWTType = "kb+jwt"
+	// KeyBindingMaxAge is how long after it is issued a key binding JWT is accepted, and keyBindingClockSkew how far in
+	// the future it may be issued, to allow for the clocks of the holder and verifier to differ.
+	KeyBindingMaxAge    = 5 * time.Minute
+	keyBindingClockSkew = time.Minute
+
+	// saltSize is the number of random bytes salting each disclosure, as recommended by the specification.
+	saltSize = 16
+)
+
+type SDJWT string
+
+func (s SDJWT) String() string {
+	return string(s)
+}
+
+func (s SDJWT) Ptr() *SDJWT {
+	return &s
+}
+
+func SDJWTPtr(s string) *SDJWT {
+	sdJWT := SDJWT(s)
+	return &sdJWT
+}
+
+// IsSDJWT returns whether the token is in the SD-JWT format, rather than a plain JWT.
+func IsSDJWT(token string) bool {
+	return strings.Contains(token, SDJWTSeparator)
+}
+
+// Disclosure reveals the value of a selectively disclosable claim.
+type Disclosure struct {
+	Salt  string
+	Name  string
+	Value any
+
+	// encoded is the form the disclosure is presented in, over which its digest is computed
+	encoded string
+}
+
+// NewDisclosure creates a disclosure of the named claim with a random salt.
+func NewDisclosure(name string, value any) (*Disclosure, error) {
+	saltBytes := make([]byte, saltSize)
+	if _, err := rand.Read(saltBytes); err != nil {
+		return nil, errors.Wrap(err, "generating salt")
+	}
+	salt := base64.RawURLEncoding.EncodeToString(saltBytes)
+	disclosureBytes, err := json.Marshal([]any{salt, name, value})
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling disclosure of claim<%s>", name)
+	}
+	return &Disclosure{
+		Salt:    salt,
+		Name:    name,
+		Value:   value,
+		encoded: base64.RawURLEncoding.EncodeToString(disclosureBytes),
+	}, nil
+}
+
+// ParseDisclosure parses a disclosure from its encoded form.
+func ParseDisclosure(encoded string) (*Disclosure, error) {
+	disclosureBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding disclosure")
+	}
+	var disclosure []any
+	if err = json.Unmarshal(disclosureBytes, &disclosure); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling disclosure")
+	}
+	if len(disclosure) != 3 {
+		return nil, fmt.Errorf("disclosure has %d elements, expected 3", len(disclosure))
+	}
+	salt, ok := disclosure[0].(string)
+	if !ok {
+		return nil, errors.New("disclosure salt is not a string")
+	}
+	name, ok := disclosure[1].(string)
+	if !ok {
+		return nil, errors.New("disclosure claim name is not a string")
+	}
+	if name == SDClaim || name == "..." {
+		return nil, fmt.Errorf("disclosure cannot disclose claim<%s>", name)
+	}
+	return &Disclosure{Salt: salt, Name: name, Value: disclosure[2], encoded: encoded}, nil
+}
+
+// Encoded returns the form the disclosure is presented in.
+func (d Disclosure) Encoded() string {
+	return d.encoded
+}
+
+// Digest returns the digest which replaces the disclosed claim in the issuer-signed JWT.
+func (d Disclosure) Digest() string {
+	return digest(d.encoded)
+}
+
+func digest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewSDJWT combines an issuer-signed JWT with disclosures and, unless nil, a key binding JWT.
+func NewSDJWT(issuerJWT JWT, disclosures []Disclosure, keyBinding *JWT) SDJWT {
+	var sb strings.Builder
+	sb.WriteString(issuerJWT.String())
+	sb.WriteString(SDJWTSeparator)
+	for _, d := range disclosures {
+		sb.WriteString(d.Encoded())
+		sb.WriteString(SDJWTSeparator)
+	}
+	if keyBinding != nil {
+		sb.WriteString(keyBinding.String())
+	}
+	return SDJWT(sb.String())
+}
+
+// Parse splits the SD-JWT into its issuer-signed JWT, its disclosures and its key binding JWT, which is nil when
+// absent.
+func (s SDJWT) Parse() (JWT, []Disclosure, *JWT, error) {
+	parts := strings.Split(s.String(), SDJWTSeparator)
+	if len(parts) < 2 || parts[0] == "" {
+		return "", nil, nil, errors.New("sd-jwt must start with an issuer-signed jwt followed by a separator")
+	}
+	disclosures := make([]Disclosure, 0, len(parts)-2)
+	for _, part := range parts[1 : len(parts)-1] {
+		disclosure, err := ParseDisclosure(part)
+		if err != nil {
+			return "", nil, nil, errors.Wrapf(err, "parsing disclosure<%s>", part)
+		}
+		disclosures = append(disclosures, *disclosure)
+	}
+	var keyBinding *JWT
+	if last := parts[len(parts)-1]; last != "" {
+		keyBinding = JWTPtr(last)
+	}
+	return JWT(parts[0]), disclosures, keyBinding, nil
+}
+
+// WithoutKeyBinding returns the SD-JWT without its key binding JWT, which is the form a key binding JWT is bound to.
+func (s SDJWT) WithoutKeyBinding() SDJWT {
+	return s[:strings.LastIndex(s.String(), SDJWTSeparator)+1]
+}
+
+// RequiresKeyBinding returns whether the issuer-signed JWT of the SD-JWT has a cnf claim, binding it to the key of its
+// holder, so that it is only valid when presented with a key binding JWT.
+func (s SDJWT) RequiresKeyBinding() (bool, error) {
+	issuerJWT, _, _, err := s.Parse()
+	if err != nil {
+		return false, err
+	}
+	token, err := jwt.Parse([]byte(issuerJWT), jwt.WithVerify(false), jwt.WithValidate(false))
+	if err != nil {
+		return false, errors.Wrap(err, "parsing issuer-signed jwt")
+	}
+	_, ok := token.Get(ConfirmationClaim)
+	return ok, nil
+}
+
+// KeyBindingNonce returns the nonce of the key binding JWT of the SD-JWT, without verifying it, so that the nonce it is
+// expected to have can be looked up before it is.
+func (s SDJWT) KeyBindingNonce() (string, error) {
+	_, _, keyBinding, err := s.Parse()
+	if err != nil {
+		return "", err
+	}
+	if keyBinding == nil {
+		return "", errors.New("sd-jwt does not have a key binding jwt")
+	}
+	token, err := jwt.Parse([]byte(*keyBinding), jwt.WithVerify(false), jwt.WithValidate(false))
+	if err != nil {
+		return "", errors.Wrap(err, "parsing key binding jwt")
+	}
+	nonce, _ := token.Get(NonceClaim)
+	nonceString, ok := nonce.(string)
+	if !ok || nonceString == "" {
+		return "", errors.New("key binding jwt does not have a nonce")
+	}
+	return nonceString, nil
+}
+
+// RedactClaims replaces the claims at the given paths of the object with the digests of their disclosures, which it
+// returns. Paths are dot-separated names of nested objects, e.g. address.street. A path nested in another redacted
+// path is disclosed as part of its parent's disclosure, and is then selectively disclosable on its own.
+func RedactClaims(claims map[string]any, paths []string) ([]Disclosure, error) {
+	// redact the deepest claims first, so that their digests are carried by the disclosures of their parents
+	sorted := make([]string, len(paths))
+	copy(sorted, paths)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.Count(sorted[i], ".") > strings.Count(sorted[j], ".")
+	})
+
+	var disclosures []Disclosure
+	for _, path := range sorted {
+		names := strings.Split(path, ".")
+		object := claims
+		for _, name := range names[:len(names)-1] {
+			nested, ok := object[name].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("claim<%s> of path<%s> is not an object", name, path)
+			}
+			object = nested
+		}
+		name := names[len(names)-1]
+		value, ok := object[name]
+		if !ok || name == SDClaim {
+			return nil, fmt.Errorf("claim<%s> not found", path)
+		}
+		disclosure, err := NewDisclosure(name, value)
+		if err != nil {
+			return nil, err
+		}
+		delete(object, name)
+		digests, _ := object[SDClaim].([]any)
+		object[SDClaim] = sortedDigests(append(digests, disclosure.Digest()))
+		disclosures = append(disclosures, *disclosure)
+	}
+	return disclosures, nil
+}
+
+// sortedDigests sorts digests so that their order does not reveal the order of the claims they replace.
+func sortedDigests(digests []any) []any {
+	sort.Slice(digests, func(i, j int) bool {
+		return fmt.Sprint(digests[i]) < fmt.Sprint(digests[j])
+	})
+	return digests
+}
+
+// DiscloseClaims returns a copy of the claims in which the digests of the given disclosures are replaced with the
+// claims they disclose, and from which the digests of undisclosed claims are removed. Every disclosure must be
+// referenced by exactly one digest.
+func DiscloseClaims(claims map[string]any, disclosures []Disclosure) (map[string]any, error) {
+	if alg, ok := claims[SDAlgClaim]; ok && alg != SDAlg {
+		return nil, fmt.Errorf("unsupported %s<%v>", SDAlgClaim, alg)
+	}
+	d := discloser{
+		disclosures: make(map[string]Disclosure, len(disclosures)),
+		seen:        make(map[string]bool),
+	}
+	for _, disclosure := range disclosures {
+		if _, ok := d.disclosures[disclosure.Digest()]; ok {
+			return nil, fmt.Errorf("disclosure<%s> is presented more than once", disclosure.Encoded())
+		}
+		d.disclosures[disclosure.Digest()] = disclosure
+	}
+	disclosed, err := d.disclose(claims)
+	if err != nil {
+		return nil, err
+	}
+	for dgst, disclosure := range d.disclosures {
+		if !d.seen[dgst] {
+			return nil, fmt.Errorf("disclosure of claim<%s> is not referenced by the sd-jwt", disclosure.Name)
+		}
+	}
+	disclosedClaims := disclosed.(map[string]any)
+	delete(disclosedClaims, SDAlgClaim)
+	return disclosedClaims, nil
+}
+
+type discloser struct {
+	disclosures map[string]Disclosure
+	// seen holds every digest encountered, each of which must be unique
+	seen map[string]bool
+}
+
+func (d discloser) disclose(value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for name, claim := range v {
+			if name == SDClaim {
+				continue
+			}
+			disclosed, err := d.disclose(claim)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = disclosed
+		}
+		sd, ok := v[SDClaim]
+		if !ok {
+			return object, nil
+		}
+		digests, ok := sd.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s claim is not an array", SDClaim)
+		}
+		for _, dgst := range digests {
+			dgstString, ok := dgst.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s claim holds a digest which is not a string: %v", SDClaim, dgst)
+			}
+			if d.seen[dgstString] {
+				return nil, fmt.Errorf("digest<%s> appears more than once", dgstString)
+			}
+			d.seen[dgstString] = true
+			disclosure, ok := d.disclosures[dgstString]
+			if !ok {
+				continue
+			}
+			if _, ok = object[disclosure.Name]; ok {
+				return nil, fmt.Errorf("disclosed claim<%s> is already present", disclosure.Name)
+			}
+			disclosed, err := d.disclose(disclosure.Value)
+			if err != nil {
+				return nil, err
+			}
+			object[disclosure.Name] = disclosed
+		}
+		return object, nil
+	case []any:
+		array := make([]any, 0, len(v))
+		for _, element := range v {
+			disclosed, err := d.disclose(element)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, disclosed)
+		}
+		return array, nil
+	default:
+		return value, nil
+	}
+}
+
+// SignVerifiableCredentialSDJWT signs the credential as a vc-jwt whose credential subject claims at the given paths are
+// selectively disclosable, returning it as an SD-JWT along with the disclosure of every such claim. The subject's ID is
+// always disclosed, as the sub claim.
+func (ka JWKKeyAccess) SignVerifiableCredentialSDJWT(cred credential.VerifiableCredential, paths []string) (*SDJWT, error) {
+	if ka.JWTSigner == nil {
+		return nil, errors.New("cannot sign with nil signer")
+	}
+	if err := cred.IsValid(); err != nil {
+		return nil, errors.New("cannot sign invalid credential")
+	}
+	for _, path := range paths {
+		if path == credential.VerifiableCredentialIDProperty {
+			return nil, errors.New("credential subject id cannot be selectively disclosable")
+		}
+	}
+
+	// redact a copy of the subject, leaving the credential untouched
+	subjectBytes, err := json.Marshal(cred.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential subject")
+	}
+	var subject map[string]any
+	if err = json.Unmarshal(subjectBytes, &subject); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential subject")
+	}
+	disclosures, err := RedactClaims(subject, paths)
+	if err != nil {
+		return nil, errors.Wrap(err, "redacting credential subject")
+	}
+	cred.CredentialSubject = subject
+
+	tokenBytes, err := credential.SignVerifiableCredentialJWT(*ka.JWTSigner, cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign cred")
+	}
+	sdJWT := NewSDJWT(JWT(tokenBytes), disclosures, nil)
+	return &sdJWT, nil
+}
+
+// SignKeyBinding binds the SD-JWT to the holder's key for the given audience and nonce, returning it along with its
+// key binding JWT, which replaces any existing one.
+func (ka JWKKeyAccess) SignKeyBinding(sdJWT SDJWT, audience, nonce string) (*SDJWT, error) {
+	if ka.JWTSigner == nil {
+		return nil, errors.New("cannot sign with nil signer")
+	}
+	presented := sdJWT.WithoutKeyBinding()
+
+	t := jwt.New()
+	if err := t.Set(jwt.IssuedAtKey, time.Now().Unix()); err != nil {
+		return nil, errors.Wrap(err, "setting iat value")
+	}
+	if err := t.Set(jwt.AudienceKey, audience); err != nil {
+		return nil, errors.Wrap(err, "setting aud value")
+	}
+	if err := t.Set(NonceClaim, nonce); err != nil {
+		return nil, errors.Wrap(err, "setting nonce value")
+	}
+	if err := t.Set(SDHashClaim, digest(presented.String())); err != nil {
+		return nil, errors.Wrap(err, "setting sd_hash value")
+	}
+	headers := jws.NewHeaders()
+	if err := headers.Set(jws.TypeKey, KeyBindingJWTType); err != nil {
+		return nil, errors.Wrap(err, "setting typ header")
+	}
+	tokenBytes, err := jwt.Sign(t, jwt.WithKey(ka.SignatureAlgorithm, ka.JWTSigner.Key, jws.WithProtectedHeaders(headers)))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing key binding jwt")
+	}
+	bound := SDJWT(presented.String() + string(tokenBytes))
+	return &bound, nil
+}
+
+// VerifyKeyBinding verifies the signature on the key binding JWT of the SD-JWT, that it is bound to the SD-JWT it is
+// presented with, that it was issued for the given audience and nonce, which are required, and that it was issued at
+// most KeyBindingMaxAge ago.
+func (ka JWKKeyAccess) VerifyKeyBinding(sdJWT SDJWT, audience, nonce string) error {
+	if audience == "" || nonce == "" {
+		return errors.New("the audience and nonce the key binding jwt is expected to have are required")
+	}
+	_, _, keyBinding, err := sdJWT.Parse()
+	if err != nil {
+		return err
+	}
+	if keyBinding == nil {
+		return errors.New("sd-jwt does not have a key binding jwt")
+	}
+	headers, err := GetJWTHeaders([]byte(*keyBinding))
+	if err != nil {
+		return errors.Wrap(err, "parsing key binding jwt headers")
+	}
+	if headers.Type() != KeyBindingJWTType {
+		return fmt.Errorf("key binding jwt has typ<%s>, expected %s", headers.Type(), KeyBindingJWTType)
+	}
+	if err = ka.Verify(*keyBinding); err != nil {
+		return errors.Wrap(err, "verifying key binding jwt")
+	}
+	token, err := jwt.Parse([]byte(*keyBinding), jwt.WithVerify(false), jwt.WithValidate(false))
+	if err != nil {
+		return errors.Wrap(err, "parsing key binding jwt")
+	}
+	if token.IssuedAt().IsZero() {
+		return errors.New("key binding jwt does not have an iat claim")
+	}
+	now := time.Now()
+	if token.IssuedAt().Before(now.Add(-KeyBindingMaxAge)) {
+		return fmt.Errorf("key binding jwt was issued more than %s ago", KeyBindingMaxAge)
+	}
+	if token.IssuedAt().After(now.Add(keyBindingClockSkew)) {
+		return errors.New("key binding jwt is issued in the future")
+	}
+	issuedForAudience := false
+	for _, aud := range token.Audience() {
+		issuedForAudience = issuedForAudience || aud == audience
+	}
+	if !issuedForAudience {
+		return fmt.Errorf("key binding jwt is not issued for audience<%s>", audience)
+	}
+	if gotNonce, _ := token.Get(NonceClaim); gotNonce != nonce {
+		return errors.New("key binding jwt does not have the expected nonce")
+	}
+	sdHash, _ := token.Get(SDHashClaim)
+	if sdHash != digest(sdJWT.WithoutKeyBinding().String()) {
+		return fmt.Errorf("key binding jwt %s does not match the sd-jwt", SDHashClaim)
+	}
+	return nil
+}
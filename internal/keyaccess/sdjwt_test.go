@@ -0,0 +1,217 @@
+package keyaccess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKKeyAccessSignVerifiableCredentialSDJWT(t *testing.T) {
+	t.Run("Sign and Disclose - Happy Path", func(tt *testing.T) {
+		ka := getTestSDJWTKeyAccess(tt)
+		testCred := getTestCredential(ka.JWTSigner.ID)
+		testCred.CredentialSubject["name"] = "Satoshi"
+
+		sdJWT, err := ka.SignVerifiableCredentialSDJWT(testCred, []string{"name", "happiness", "happiness.howHappy"})
+		assert.NoError(tt, err)
+		assert.True(tt, IsSDJWT(sdJWT.String()))
+
+		// the credential is left untouched
+		assert.Equal(tt, "Satoshi", testCred.CredentialSubject["name"])
+
+		issuerJWT, disclosures, keyBinding, err := sdJWT.Parse()
+		assert.NoError(tt, err)
+		assert.Len(tt, disclosures, 3)
+		assert.Nil(tt, keyBinding)
+		assert.NoError(tt, ka.Verify(issuerJWT))
+
+		// the redacted claims are replaced with digests
+		claims := getTestSDJWTClaims(tt, issuerJWT)
+		subject := claims["vc"].(map[string]any)["credentialSubject"].(map[string]any)
+		assert.NotContains(tt, subject, "name")
+		assert.NotContains(tt, subject, "happiness")
+		assert.Len(tt, subject[SDClaim], 2)
+
+		// disclosing every claim restores the subject
+		disclosed, err := DiscloseClaims(claims, disclosures)
+		assert.NoError(tt, err)
+		disclosedSubject := disclosed["vc"].(map[string]any)["credentialSubject"].(map[string]any)
+		assert.Equal(tt, map[string]any{"name": "Satoshi", "happiness": map[string]any{"howHappy": "really happy"}}, disclosedSubject)
+
+		// disclosing only the name leaves out the other claims
+		var nameDisclosure []Disclosure
+		for _, d := range disclosures {
+			if d.Name == "name" {
+				nameDisclosure = append(nameDisclosure, d)
+			}
+		}
+		disclosed, err = DiscloseClaims(claims, nameDisclosure)
+		assert.NoError(tt, err)
+		disclosedSubject = disclosed["vc"].(map[string]any)["credentialSubject"].(map[string]any)
+		assert.Equal(tt, map[string]any{"name": "Satoshi"}, disclosedSubject)
+
+		// the presented disclosures round trip
+		_, presented, _, err := NewSDJWT(issuerJWT, nameDisclosure, nil).Parse()
+		assert.NoError(tt, err)
+		assert.Equal(tt, nameDisclosure, presented)
+	})
+
+	t.Run("Sign - Bad Paths", func(tt *testing.T) {
+		ka := getTestSDJWTKeyAccess(tt)
+		testCred := getTestCredential(ka.JWTSigner.ID)
+
+		_, err := ka.SignVerifiableCredentialSDJWT(testCred, []string{"id"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential subject id cannot be selectively disclosable")
+
+		_, err = ka.SignVerifiableCredentialSDJWT(testCred, []string{"missing"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "claim<missing> not found")
+
+		_, err = ka.SignVerifiableCredentialSDJWT(testCred, []string{"happiness.howHappy.very"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is not an object")
+	})
+
+	t.Run("Disclose - Bad Disclosures", func(tt *testing.T) {
+		ka := getTestSDJWTKeyAccess(tt)
+		testCred := getTestCredential(ka.JWTSigner.ID)
+
+		sdJWT, err := ka.SignVerifiableCredentialSDJWT(testCred, []string{"happiness"})
+		assert.NoError(tt, err)
+		issuerJWT, disclosures, _, err := sdJWT.Parse()
+		assert.NoError(tt, err)
+		claims := getTestSDJWTClaims(tt, issuerJWT)
+
+		// a disclosure presented twice
+		_, err = DiscloseClaims(claims, append(disclosures, disclosures...))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is presented more than once")
+
+		// a disclosure the issuer did not sign
+		forged, err := NewDisclosure("happiness", "very happy")
+		assert.NoError(tt, err)
+		_, err = DiscloseClaims(claims, []Disclosure{*forged})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is not referenced by the sd-jwt")
+
+		// a malformed disclosure
+		_, _, _, err = SDJWT(issuerJWT.String() + SDJWTSeparator + "bad" + SDJWTSeparator).Parse()
+		assert.Error(tt, err)
+	})
+}
+
+func TestJWKKeyAccessKeyBinding(t *testing.T) {
+	issuer := getTestSDJWTKeyAccess(t)
+	holder := getTestSDJWTKeyAccess(t)
+
+	testCred := getTestCredential(issuer.JWTSigner.ID)
+	sdJWT, err := issuer.SignVerifiableCredentialSDJWT(testCred, []string{"happiness"})
+	require.NoError(t, err)
+
+	t.Run("Sign and Verify Key Binding - Happy Path", func(tt *testing.T) {
+		bound, err := holder.SignKeyBinding(*sdJWT, "did:example:verifier", "nonce")
+		assert.NoError(tt, err)
+		_, disclosures, keyBinding, err := bound.Parse()
+		assert.NoError(tt, err)
+		assert.Len(tt, disclosures, 1)
+		assert.NotNil(tt, keyBinding)
+
+		assert.NoError(tt, holder.VerifyKeyBinding(*bound, "did:example:verifier", "nonce"))
+		assert.Equal(tt, *sdJWT, bound.WithoutKeyBinding())
+	})
+
+	t.Run("Verify Key Binding - Bad Binding", func(tt *testing.T) {
+		// no key binding
+		err := holder.VerifyKeyBinding(*sdJWT, "did:example:verifier", "nonce")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not have a key binding jwt")
+
+		// signed by another key
+		bound, err := issuer.SignKeyBinding(*sdJWT, "did:example:verifier", "nonce")
+		assert.NoError(tt, err)
+		assert.Error(tt, holder.VerifyKeyBinding(*bound, "did:example:verifier", "nonce"))
+
+		// bound to other disclosures
+		bound, err = holder.SignKeyBinding(*sdJWT, "did:example:verifier", "nonce")
+		assert.NoError(tt, err)
+		issuerJWT, _, keyBinding, err := bound.Parse()
+		assert.NoError(tt, err)
+		err = holder.VerifyKeyBinding(NewSDJWT(issuerJWT, nil, keyBinding), "did:example:verifier", "nonce")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not match the sd-jwt")
+
+		// issued for another audience or nonce
+		err = holder.VerifyKeyBinding(*bound, "did:example:other", "nonce")
+		assert.ErrorContains(tt, err, "not issued for audience<did:example:other>")
+		err = holder.VerifyKeyBinding(*bound, "did:example:verifier", "other")
+		assert.ErrorContains(tt, err, "does not have the expected nonce")
+		err = holder.VerifyKeyBinding(*bound, "", "")
+		assert.ErrorContains(tt, err, "are required")
+	})
+
+	t.Run("Verify Key Binding - Not Recently Issued", func(tt *testing.T) {
+		for _, iat := range []time.Time{time.Now().Add(-KeyBindingMaxAge - time.Minute), time.Now().Add(time.Hour)} {
+			token := jwt.New()
+			require.NoError(tt, token.Set(jwt.IssuedAtKey, iat.Unix()))
+			require.NoError(tt, token.Set(jwt.AudienceKey, "did:example:verifier"))
+			require.NoError(tt, token.Set(NonceClaim, "nonce"))
+			require.NoError(tt, token.Set(SDHashClaim, digest(sdJWT.WithoutKeyBinding().String())))
+			headers := jws.NewHeaders()
+			require.NoError(tt, headers.Set(jws.TypeKey, KeyBindingJWTType))
+			keyBinding, err := jwt.Sign(token, jwt.WithKey(holder.SignatureAlgorithm, holder.JWTSigner.Key, jws.WithProtectedHeaders(headers)))
+			require.NoError(tt, err)
+
+			err = holder.VerifyKeyBinding(SDJWT(sdJWT.WithoutKeyBinding().String()+string(keyBinding)), "did:example:verifier", "nonce")
+			assert.Error(tt, err)
+		}
+	})
+
+	t.Run("Key Binding Nonce", func(tt *testing.T) {
+		bound, err := holder.SignKeyBinding(*sdJWT, "did:example:verifier", "nonce")
+		require.NoError(tt, err)
+		nonce, err := bound.KeyBindingNonce()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "nonce", nonce)
+
+		_, err = sdJWT.KeyBindingNonce()
+		assert.ErrorContains(tt, err, "does not have a key binding jwt")
+	})
+
+	t.Run("Requires Key Binding", func(tt *testing.T) {
+		required, err := sdJWT.RequiresKeyBinding()
+		assert.NoError(tt, err)
+		assert.False(tt, required)
+
+		// an issuer-signed jwt with a cnf claim is bound to the key of its holder
+		token := jwt.New()
+		require.NoError(tt, token.Set(ConfirmationClaim, map[string]any{"kid": "did:example:holder#key-1"}))
+		issuerJWT, err := jwt.Sign(token, jwt.WithKey(issuer.SignatureAlgorithm, issuer.JWTSigner.Key))
+		require.NoError(tt, err)
+		required, err = NewSDJWT(JWT(issuerJWT), nil, nil).RequiresKeyBinding()
+		assert.NoError(tt, err)
+		assert.True(tt, required)
+	})
+}
+
+func getTestSDJWTKeyAccess(t *testing.T) *JWKKeyAccess {
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	ka, err := NewJWKKeyAccess("test-id", "test-kid", privKey)
+	require.NoError(t, err)
+	return ka
+}
+
+func getTestSDJWTClaims(t *testing.T, token JWT) map[string]any {
+	parsed, err := jwt.Parse([]byte(token), jwt.WithVerify(false), jwt.WithValidate(false))
+	require.NoError(t, err)
+	claims, err := parsed.AsMap(context.Background())
+	require.NoError(t, err)
+	return claims
+}
@@ -27,6 +27,12 @@ const (
 
 // XChaCha20Poly1305Encrypt takes a 32 byte key and uses XChaCha20-Poly1305 to encrypt a piece of data
 func XChaCha20Poly1305Encrypt(key, data []byte) ([]byte, error) {
+	return XChaCha20Poly1305EncryptWithAAD(key, data, nil)
+}
+
+// XChaCha20Poly1305EncryptWithAAD is XChaCha20Poly1305Encrypt, authenticating the additional data along with the data,
+// so that the ciphertext only decrypts with the same additional data.
+func XChaCha20Poly1305EncryptWithAAD(key, data, additionalData []byte) ([]byte, error) {
 	aead, err := chacha20poly1305.NewX(key)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create aead with provided key")
@@ -38,12 +44,18 @@ func XChaCha20Poly1305Encrypt(key, data []byte) ([]byte, error) {
 		return nil, errors.Wrap(err, "could not generate nonce for encryption")
 	}
 
-	encrypted := aead.Seal(nonce, nonce, data, nil)
+	encrypted := aead.Seal(nonce, nonce, data, additionalData)
 	return encrypted, nil
 }
 
 // XChaCha20Poly1305Decrypt takes a 32 byte key and uses XChaCha20-Poly1305 to decrypt a piece of data
 func XChaCha20Poly1305Decrypt(key, data []byte) ([]byte, error) {
+	return XChaCha20Poly1305DecryptWithAAD(key, data, nil)
+}
+
+// XChaCha20Poly1305DecryptWithAAD decrypts data encrypted by XChaCha20Poly1305EncryptWithAAD with the same additional
+// data.
+func XChaCha20Poly1305DecryptWithAAD(key, data, additionalData []byte) ([]byte, error) {
 	aead, err := chacha20poly1305.NewX(key)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create aead with provided key")
@@ -57,7 +69,7 @@ func XChaCha20Poly1305Decrypt(key, data []byte) ([]byte, error) {
 	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
 
 	// Decrypt the message and check it wasn't tampered with.
-	decrypted, err := aead.Open(nil, nonce, ciphertext, nil)
+	decrypted, err := aead.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not decrypt data")
 	}
@@ -46,3 +46,20 @@ func TestXChaCha20Poly1305(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, message, decrypted)
 }
+
+func TestXChaCha20Poly1305WithAAD(t *testing.T) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	message := []byte("open sesame")
+	encrypted, err := XChaCha20Poly1305EncryptWithAAD(key, message, []byte("credential/1"))
+	assert.NoError(t, err)
+
+	decrypted, err := XChaCha20Poly1305DecryptWithAAD(key, encrypted, []byte("credential/1"))
+	assert.NoError(t, err)
+	assert.Equal(t, message, decrypted)
+
+	// the ciphertext only decrypts with the same additional data
+	_, err = XChaCha20Poly1305DecryptWithAAD(key, encrypted, []byte("credential/2"))
+	assert.Error(t, err)
+	_, err = XChaCha20Poly1305Decrypt(key, encrypted)
+	assert.Error(t, err)
+}
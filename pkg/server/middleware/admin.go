@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+)
+
+// AdminAPIKeyHeader is the header holding the admin API key, which authenticates the requests made to the admin
+// routes, such as those managing tenants.
+const AdminAPIKeyHeader = "X-Admin-API-Key"
+
+// Admin rejects the requests whose AdminAPIKeyHeader does not hold the given admin API key. When no admin API key is
+// configured, every request is rejected, so that the admin routes cannot be reached.
+func Admin(adminAPIKey string) framework.Middleware {
+	mw := func(handler framework.Handler) framework.Handler {
+		wrapped := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if adminAPIKey == "" {
+				errMsg := "could not authenticate admin request, since no admin api key is configured"
+				logrus.Warn(errMsg)
+				return framework.NewRequestErrorMsg(errMsg, http.StatusUnauthorized)
+			}
+			apiKey := r.Header.Get(AdminAPIKeyHeader)
+			if subtle.ConstantTimeCompare([]byte(apiKey), []byte(adminAPIKey)) != 1 {
+				errMsg := "could not authenticate admin request"
+				logrus.Warn(errMsg)
+				return framework.NewRequestErrorMsg(errMsg, http.StatusUnauthorized)
+			}
+			return handler(ctx, w, r)
+		}
+
+		return wrapped
+	}
+
+	return mw
+}
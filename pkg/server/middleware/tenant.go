@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	// TenantHeader is the header naming the tenant a request is made on behalf of. Requests without it are made on
+	// behalf of the default tenant.
+	TenantHeader = "X-Tenant-ID"
+	// TenantAPIKeyHeader is the header holding the API key of the tenant a request is made on behalf of.
+	TenantAPIKeyHeader = "X-Tenant-API-Key"
+)
+
+// Tenant scopes each request to the tenant named by its TenantHeader, so that every service only sees the keys, data
+// and webhooks of that tenant. Requests naming a tenant are rejected unless their TenantAPIKeyHeader holds the API key
+// of that tenant, which is told apart from a tenant which does not exist only once authenticated.
+func Tenant(tenantService *tenant.Service) framework.Middleware {
+	mw := func(handler framework.Handler) framework.Handler {
+		wrapped := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			tenantID := r.Header.Get(TenantHeader)
+			if tenantID == "" {
+				return handler(ctx, w, r)
+			}
+
+			if err := storage.ValidateTenantID(tenantID); err != nil {
+				return framework.NewRequestError(err, http.StatusBadRequest)
+			}
+			t, err := tenantService.ResolveTenant(ctx, tenantID)
+			if err != nil {
+				errMsg := fmt.Sprintf("could not get tenant<%s>", tenantID)
+				logrus.WithError(err).Error(errMsg)
+				return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+			}
+			if t == nil || !t.HasAPIKey(r.Header.Get(TenantAPIKeyHeader)) {
+				errMsg := fmt.Sprintf("could not authenticate request on behalf of tenant<%s>", tenantID)
+				logrus.Warn(errMsg)
+				return framework.NewRequestErrorMsg(errMsg, http.StatusUnauthorized)
+			}
+
+			ctx = tenant.NewContext(storage.WithTenant(ctx, t.ID), *t)
+			return handler(ctx, w, r.WithContext(ctx))
+		}
+
+		return wrapped
+	}
+
+	return mw
+}
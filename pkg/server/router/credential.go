@@ -4,10 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.einride.tech/aip/filtering"
+	"go.einride.tech/aip/ordering"
 
 	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
@@ -15,12 +21,16 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 const (
 	IssuerParam  string = "issuer"
 	SubjectParam string = "subject"
 	SchemaParam  string = "schema"
+	FilterParam  string = "filter"
+	OrderByParam string = "orderBy"
 )
 
 type CredentialRouter struct {
@@ -50,8 +60,25 @@ type CreateCredentialRequest struct {
 	// The subject id.
 	Subject string `json:"subject" validate:"required" example:"did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"`
 
-	// A context is optional. If not present, we'll apply default, required context values.
-	Context string `json:"@context,omitempty"`
+	// Optional. A URI, unique among the credentials of the service, to use as the id of the credential. Generated when
+	// not present.
+	ID string `json:"id,omitempty" example:"urn:uuid:6fa2ad3b-4f15-4f4a-a1a5-2d3f5c9d3a47"`
+
+	// A context is optional. It is either a context URI or a list of them. If not present, we'll apply default,
+	// required context values.
+	Context any `json:"@context,omitempty" swaggertype:"array,string" example:"https://www.w3.org/2018/credentials/examples/v1"`
+
+	// Optional. Either a type or a list of them, which are added to `VerifiableCredential`. Corresponds to `type` in
+	// https://www.w3.org/TR/vc-data-model/#types.
+	Type any `json:"type,omitempty" swaggertype:"array,string" example:"EmployeeCredential"`
+
+	// Optional. Corresponds to `evidence` in https://www.w3.org/TR/vc-data-model/#evidence. Each evidence must have a
+	// `type`.
+	Evidence []any `json:"evidence,omitempty" swaggertype:"array,object"`
+
+	// Optional. Corresponds to `termsOfUse` in https://www.w3.org/TR/vc-data-model/#terms-of-use. Each terms of use
+	// must have a `type`.
+	TermsOfUse []credsdk.TermsOfUse `json:"termsOfUse,omitempty"`
 
 	// A schema ID is optional. If present, we'll attempt to look it up and validate the data against it.
 	SchemaID string `json:"schemaId,omitempty"`
@@ -69,7 +96,34 @@ type CreateCredentialRequest struct {
 	// Whether this credential can be suspended. When true, the created VC will have the "credentialStatus"
 	// property set.
 	Suspendable bool `json:"suspendable"`
-	// TODO(gabe) support more capabilities like signature type, format, and more.
+
+	// Optional. The format the credential is signed in: `jwt_vc` returns the credential as a VC-JWT in
+	// `credentialJwt`, while `ldp_vc` returns it in `credential` with an embedded proof of type `proofType`. The claims
+	// the credential's contexts do not define are defined in the issuer dependent vocabulary of a context the service
+	// adds, so that the proof covers them. `vc+sd-jwt` returns it as an SD-JWT in `credentialSdJwt`, along with the
+	// disclosures of its `disclosableClaims`. Defaults to `vc+sd-jwt` when `disclosableClaims` is set, and to `jwt_vc`
+	// otherwise.
+	Format string `json:"format,omitempty" validate:"omitempty,oneof=jwt_vc ldp_vc vc+sd-jwt" example:"jwt_vc"`
+
+	// Optional. The type of the embedded proof of an `ldp_vc` credential, either `JsonWebSignature2020`, see
+	// https://w3c.github.io/vc-jws-2020/, or `Ed25519Signature2020`, see https://w3c-ccg.github.io/di-eddsa-2020/,
+	// which requires an Ed25519 `issuerKid`. Only valid for the `ldp_vc` format. Defaults to `JsonWebSignature2020`.
+	ProofType string `json:"proofType,omitempty" validate:"omitempty,oneof=JsonWebSignature2020 Ed25519Signature2020" example:"Ed25519Signature2020"`
+
+	// Optional. The version of the VC data model the credential conforms to, either `1.1` or `2.0`. A `2.0` credential
+	// has the `https://www.w3.org/ns/credentials/v2` base context, `validFrom` and `validUntil` in place of
+	// `issuanceDate` and `expirationDate`, and a `credentialSchema` of type `JsonSchema`, and is signed as a JWT with
+	// the `application/vc+ld+json+jwt` media type, whose claims are the credential. Only valid for the `jwt_vc` format.
+	// Defaults to `2.0` for the issuers the service is configured to issue 2.0 credentials for, and to `1.1` otherwise.
+	DataModelVersion string `json:"dataModelVersion,omitempty" validate:"omitempty,oneof=1.1 2.0" example:"2.0"`
+
+	// Optional. Paths of the claims in `data` which the holder may selectively disclose, with the names of nested
+	// claims separated by dots. Only valid for the `vc+sd-jwt` format.
+	DisclosableClaims []string `json:"disclosableClaims,omitempty" example:"alumniOf,address.street"`
+
+	// Whether this credential can be refreshed. When true, the created VC will have the "refreshService" property set,
+	// through which its subject may have it issued again with a new expiry and the same claims.
+	Refreshable bool `json:"refreshable,omitempty"`
 }
 
 func (c CreateCredentialRequest) ToServiceRequest() credential.CreateCredentialRequest {
@@ -77,12 +131,22 @@ func (c CreateCredentialRequest) ToServiceRequest() credential.CreateCredentialR
 		Issuer:      c.Issuer,
 		IssuerKID:   c.IssuerKID,
 		Subject:     c.Subject,
+		ID:          c.ID,
 		Context:     c.Context,
+		Type:        c.Type,
+		Evidence:    c.Evidence,
+		TermsOfUse:  c.TermsOfUse,
 		SchemaID:    c.SchemaID,
 		Data:        c.Data,
 		Expiry:      c.Expiry,
 		Revocable:   c.Revocable,
 		Suspendable: c.Suspendable,
+		Format:      exchange.CredentialFormat(c.Format),
+		ProofType:   cryptosuite.SignatureType(c.ProofType),
+
+		DataModelVersion:  credmodel.DataModelVersion(c.DataModelVersion),
+		DisclosableClaims: c.DisclosableClaims,
+		Refreshable:       c.Refreshable,
 	}
 }
 
@@ -93,6 +157,10 @@ type CreateCredentialResponse struct {
 	// The same verifiable credential, but using the syntax defined for the media type `application/vc+jwt`. See
 	// https://w3c.github.io/vc-jwt/ for more details.
 	CredentialJWT *keyaccess.JWT `json:"credentialJwt,omitempty"`
+
+	// The same verifiable credential as an SD-JWT, followed by the disclosures of its selectively disclosable claims.
+	// See https://datatracker.ietf.org/doc/draft-ietf-oauth-selective-disclosure-jwt/ for more details.
+	CredentialSDJWT *keyaccess.SDJWT `json:"credentialSdJwt,omitempty"`
 }
 
 // CreateCredential godoc
@@ -130,15 +198,180 @@ func (cr CredentialRouter) CreateCredential(ctx context.Context, w http.Response
 		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
 	}
 
-	resp := CreateCredentialResponse{Credential: createCredentialResponse.Credential, CredentialJWT: createCredentialResponse.CredentialJWT}
+	resp := CreateCredentialResponse{
+		Credential:      createCredentialResponse.Credential,
+		CredentialJWT:   createCredentialResponse.CredentialJWT,
+		CredentialSDJWT: createCredentialResponse.CredentialSDJWT,
+	}
 
 	return framework.Respond(ctx, w, resp, http.StatusCreated)
 }
 
+type BatchCreateCredentialsRequest struct {
+	// The credentials to create, each as it would be created by `PUT /v1/credentials`. At most 10000 may be created at
+	// once.
+	Requests []CreateCredentialRequest `json:"requests" validate:"required,min=1,max=10000,dive"`
+
+	// Whether either every credential is created or none is. When false, the credentials which cannot be created are
+	// reported in their results, and the others are created.
+	Atomic bool `json:"atomic"`
+}
+
+func (c BatchCreateCredentialsRequest) ToServiceRequest() credential.BatchCreateCredentialsRequest {
+	requests := make([]credential.CreateCredentialRequest, 0, len(c.Requests))
+	for _, r := range c.Requests {
+		requests = append(requests, r.ToServiceRequest())
+	}
+	return credential.BatchCreateCredentialsRequest{
+		Requests: requests,
+		Atomic:   c.Atomic,
+	}
+}
+
+type BatchCreateCredentialResult struct {
+	CreateCredentialResponse
+
+	// Why the credential could not be created, in which case it holds no credential.
+	Error string `json:"error,omitempty"`
+}
+
+type BatchCreateCredentialsResponse struct {
+	// The result of each request, in the order they were made. Populated unless the batch is created in the
+	// background.
+	Results []BatchCreateCredentialResult `json:"results,omitempty"`
+
+	// Tracks the batch when it is created in the background, which is done for batches of more credentials than the
+	// configured threshold. The operation's response is a BatchCreateCredentialsResponse once it is done.
+	Operation *Operation `json:"operation,omitempty"`
+}
+
+func batchCreateCredentialsResponse(results []batch.Result) BatchCreateCredentialsResponse {
+	resp := BatchCreateCredentialsResponse{Results: make([]BatchCreateCredentialResult, 0, len(results))}
+	for _, result := range results {
+		routerResult := BatchCreateCredentialResult{Error: result.Error}
+		if result.Container != nil {
+			routerResult.CreateCredentialResponse = CreateCredentialResponse{
+				Credential:      result.Container.Credential,
+				CredentialJWT:   result.Container.CredentialJWT,
+				CredentialSDJWT: result.Container.CredentialSDJWT,
+			}
+		}
+		resp.Results = append(resp.Results, routerResult)
+	}
+	return resp
+}
+
+// BatchCreateCredentials godoc
+//
+// @Summary     Batch Create Credentials
+// @Description Create many verifiable credentials at once. Batches of more credentials than the configured threshold
+// @Description are created in the background, and an operation which tracks them is returned instead of their results.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       request body     BatchCreateCredentialsRequest true "request body"
+// @Success     201     {object} BatchCreateCredentialsResponse
+// @Success     202     {object} BatchCreateCredentialsResponse "The batch is created in the background."
+// @Failure     400     {string} string "Bad request"
+// @Failure     500     {string} string "Internal server error"
+// @Router      /v1/credentials/batch [put]
+func (cr CredentialRouter) BatchCreateCredentials(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request BatchCreateCredentialsRequest
+	invalidBatchCreateCredentialsRequest := "invalid batch create credentials request"
+	if err := framework.Decode(r, &request); err != nil {
+		errMsg := invalidBatchCreateCredentialsRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		errMsg := invalidBatchCreateCredentialsRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	batchResponse, err := cr.service.BatchCreateCredentials(ctx, request.ToServiceRequest())
+	if err != nil {
+		errMsg := "could not create credentials"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+	}
+
+	if batchResponse.Operation != nil {
+		resp := BatchCreateCredentialsResponse{Operation: &Operation{ID: batchResponse.Operation.ID}}
+		return framework.Respond(ctx, w, resp, http.StatusAccepted)
+	}
+	return framework.Respond(ctx, w, batchCreateCredentialsResponse(batchResponse.Results), http.StatusCreated)
+}
+
+type RefreshCredentialRequest struct {
+	// A JWT signed by the subject of the credential to refresh, whose DID is its `iss`, with the `kid` of the signing
+	// key in its header. The ID of the credential to refresh is its `credentialId` claim. Its `aud` must be the id of
+	// the refresh service of the credential, and its `iat` must be at most 5 minutes ago. When it has an `exp`, it must not
+	// have passed, and be at most 5 minutes after its `iat`.
+	RequestJWT keyaccess.JWT `json:"requestJwt" validate:"required"`
+}
+
+type RefreshCredentialResponse struct {
+	CreateCredentialResponse
+
+	// The ID of the credential which was refreshed.
+	RefreshedFrom string `json:"refreshedFrom"`
+}
+
+// RefreshCredential godoc
+//
+// @Summary     Refresh Credential
+// @Description Issue a refreshable credential again, with a new expiry and the same claims, for its subject. The
+// @Description credential is issued in the same format, and valid for as long as the refreshed one, or as set by the
+// @Description issuance template it was issued from. The refreshed credential may have expired, but not be revoked or
+// @Description suspended, and can only be refreshed once.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       request body     RefreshCredentialRequest true "request body"
+// @Success     201     {object} RefreshCredentialResponse
+// @Failure     400     {string} string "Bad request"
+// @Failure     500     {string} string "Internal server error"
+// @Router      /v1/credentials/refresh [put]
+func (cr CredentialRouter) RefreshCredential(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request RefreshCredentialRequest
+	invalidRefreshCredentialRequest := "invalid refresh credential request"
+	if err := framework.Decode(r, &request); err != nil {
+		errMsg := invalidRefreshCredentialRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		errMsg := invalidRefreshCredentialRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	refreshResponse, err := cr.service.RefreshCredential(ctx, credential.RefreshCredentialRequest{RequestJWT: request.RequestJWT})
+	if err != nil {
+		errMsg := "could not refresh credential"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+	}
+
+	resp := RefreshCredentialResponse{
+		CreateCredentialResponse: CreateCredentialResponse{
+			Credential:      refreshResponse.Credential,
+			CredentialJWT:   refreshResponse.CredentialJWT,
+			CredentialSDJWT: refreshResponse.CredentialSDJWT,
+		},
+		RefreshedFrom: refreshResponse.RefreshedFrom,
+	}
+	return framework.Respond(ctx, w, resp, http.StatusCreated)
+}
+
 type GetCredentialResponse struct {
-	ID            string                        `json:"id"`
-	Credential    *credsdk.VerifiableCredential `json:"credential,omitempty"`
-	CredentialJWT *keyaccess.JWT                `json:"credentialJwt,omitempty"`
+	ID              string                        `json:"id"`
+	Credential      *credsdk.VerifiableCredential `json:"credential,omitempty"`
+	CredentialJWT   *keyaccess.JWT                `json:"credentialJwt,omitempty"`
+	CredentialSDJWT *keyaccess.SDJWT              `json:"credentialSdJwt,omitempty"`
 }
 
 // GetCredential godoc
@@ -169,9 +402,10 @@ func (cr CredentialRouter) GetCredential(ctx context.Context, w http.ResponseWri
 	}
 
 	resp := GetCredentialResponse{
-		ID:            gotCredential.ID,
-		Credential:    gotCredential.Credential,
-		CredentialJWT: gotCredential.CredentialJWT,
+		ID:              gotCredential.ID,
+		Credential:      gotCredential.Credential,
+		CredentialJWT:   gotCredential.CredentialJWT,
+		CredentialSDJWT: gotCredential.CredentialSDJWT,
 	}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
@@ -181,6 +415,14 @@ type GetCredentialStatusResponse struct {
 	Revoked bool `json:"revoked"`
 	// Whether the credential has been suspended.
 	Suspended bool `json:"suspended"`
+	// When the suspension of the credential is lifted, if it is temporary.
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	// Whether the expiration date of the credential has passed.
+	Expired bool `json:"expired"`
+	// The id of the status list credential holding the status of the credential.
+	StatusListCredentialID string `json:"statusListCredentialId,omitempty"`
+	// The index of the status of the credential in its status list.
+	StatusListIndex int `json:"statusListIndex,omitempty"`
 }
 
 // GetCredentialStatus godoc
@@ -211,8 +453,12 @@ func (cr CredentialRouter) GetCredentialStatus(ctx context.Context, w http.Respo
 	}
 
 	resp := GetCredentialStatusResponse{
-		Revoked:   getCredentialStatusResponse.Revoked,
-		Suspended: getCredentialStatusResponse.Suspended,
+		Revoked:                getCredentialStatusResponse.Revoked,
+		Suspended:              getCredentialStatusResponse.Suspended,
+		SuspendedUntil:         getCredentialStatusResponse.SuspendedUntil,
+		Expired:                getCredentialStatusResponse.Expired,
+		StatusListCredentialID: getCredentialStatusResponse.StatusListCredentialID,
+		StatusListIndex:        getCredentialStatusResponse.StatusListIndex,
 	}
 
 	return framework.Respond(ctx, w, resp, http.StatusOK)
@@ -263,25 +509,91 @@ func (cr CredentialRouter) GetCredentialStatusList(ctx context.Context, w http.R
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
+type StatusListUtilization struct {
+	// The id of the status list credential.
+	StatusListCredentialID string `json:"statusListCredentialId"`
+	Issuer                 string `json:"issuer"`
+	Schema                 string `json:"schema,omitempty"`
+	// Either "revocation" or "suspension".
+	StatusPurpose string `json:"statusPurpose"`
+	// The number of indexes of the status list.
+	Size int `json:"size"`
+	// The number of indexes of the status list allocated to credentials.
+	Allocated int `json:"allocated"`
+	// Whether new credentials are allocated indexes of this status list. The other status lists of the same issuer,
+	// schema and purpose are full.
+	Current bool `json:"current"`
+}
+
+type GetCredentialStatusListsResponse struct {
+	StatusLists []StatusListUtilization `json:"statusLists"`
+}
+
+// GetCredentialStatusLists godoc
+//
+// @Summary     Get Credential Status Lists
+// @Description Lists the status lists of an issuer, along with how many of their indexes are allocated.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       issuer query    string true "The issuer id" example(did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp)
+// @Success     200    {object} GetCredentialStatusListsResponse
+// @Failure     400    {string} string "Bad request"
+// @Failure     500    {string} string "Internal server error"
+// @Router      /v1/credentials/status [get]
+func (cr CredentialRouter) GetCredentialStatusLists(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	issuer := framework.GetQueryValue(r, IssuerParam)
+	if issuer == nil {
+		return framework.NewRequestErrorMsg("must use the issuer query parameter", http.StatusBadRequest)
+	}
+
+	gotStatusLists, err := cr.service.GetStatusLists(ctx, credential.GetStatusListsRequest{Issuer: *issuer})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not get status lists for issuer: %s", util.SanitizeLog(*issuer))
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+	}
+
+	statusLists := make([]StatusListUtilization, 0, len(gotStatusLists.StatusLists))
+	for _, statusList := range gotStatusLists.StatusLists {
+		statusLists = append(statusLists, StatusListUtilization{
+			StatusListCredentialID: statusList.StatusListCredentialID,
+			Issuer:                 statusList.Issuer,
+			Schema:                 statusList.Schema,
+			StatusPurpose:          string(statusList.StatusPurpose),
+			Size:                   statusList.Size,
+			Allocated:              statusList.Allocated,
+			Current:                statusList.Current,
+		})
+	}
+
+	resp := GetCredentialStatusListsResponse{StatusLists: statusLists}
+	return framework.Respond(ctx, w, resp, http.StatusOK)
+}
+
 type UpdateCredentialStatusRequest struct {
 	// The new revoked status of this credential. The status will be saved in the encodedList of the StatusList2021
 	// credential associated with this VC.
 	Revoked   bool `json:"revoked,omitempty"`
 	Suspended bool `json:"suspended,omitempty"`
+	// When set along with suspended, the suspension is lifted once this time has passed.
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
 }
 
 func (c UpdateCredentialStatusRequest) ToServiceRequest(id string) credential.UpdateCredentialStatusRequest {
 	return credential.UpdateCredentialStatusRequest{
-		ID:        id,
-		Revoked:   c.Revoked,
-		Suspended: c.Suspended,
+		ID:             id,
+		Revoked:        c.Revoked,
+		Suspended:      c.Suspended,
+		SuspendedUntil: c.SuspendedUntil,
 	}
 }
 
 type UpdateCredentialStatusResponse struct {
 	// The updated status of this credential.
-	Revoked   bool `json:"revoked"`
-	Suspended bool `json:"suspended"`
+	Revoked        bool       `json:"revoked"`
+	Suspended      bool       `json:"suspended"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
 }
 
 // UpdateCredentialStatus godoc
@@ -328,24 +640,127 @@ func (cr CredentialRouter) UpdateCredentialStatus(ctx context.Context, w http.Re
 	}
 
 	resp := UpdateCredentialStatusResponse{
-		Revoked:   gotCredential.Revoked,
-		Suspended: gotCredential.Suspended,
+		Revoked:        gotCredential.Revoked,
+		Suspended:      gotCredential.Suspended,
+		SuspendedUntil: gotCredential.SuspendedUntil,
 	}
 
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
+type BatchUpdateCredentialStatusItem struct {
+	// The ID of the credential whose status is updated.
+	ID string `json:"id" validate:"required"`
+
+	UpdateCredentialStatusRequest
+}
+
+type BatchUpdateCredentialStatusRequest struct {
+	// The status updates to make, each as it would be made by `PUT /v1/credentials/{id}/status`. At most 10000 may be
+	// made at once, and each credential may be updated only once.
+	Requests []BatchUpdateCredentialStatusItem `json:"requests" validate:"required,min=1,max=10000,dive"`
+}
+
+func (c BatchUpdateCredentialStatusRequest) ToServiceRequest() credential.BatchUpdateCredentialStatusRequest {
+	requests := make([]credential.UpdateCredentialStatusRequest, 0, len(c.Requests))
+	for _, r := range c.Requests {
+		requests = append(requests, r.ToServiceRequest(r.ID))
+	}
+	return credential.BatchUpdateCredentialStatusRequest{Requests: requests}
+}
+
+type BatchUpdateCredentialStatusResult struct {
+	// The ID of the credential whose status was updated.
+	ID string `json:"id"`
+
+	UpdateCredentialStatusResponse
+}
+
+type BatchUpdateCredentialStatusResponse struct {
+	// The updated status of each credential, in the order they were requested.
+	Results []BatchUpdateCredentialStatusResult `json:"results"`
+}
+
+// BatchUpdateCredentialStatus godoc
+//
+// @Summary     Batch Update Credential Status
+// @Description Update the status of many credentials at once. Either every status is updated or none is, and each
+// @Description status list holding the credentials is signed again only once.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       request body     BatchUpdateCredentialStatusRequest true "request body"
+// @Success     200     {object} BatchUpdateCredentialStatusResponse
+// @Failure     400     {string} string "Bad request"
+// @Failure     500     {string} string "Internal server error"
+// @Router      /v1/credentials/status/batch [put]
+func (cr CredentialRouter) BatchUpdateCredentialStatus(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request BatchUpdateCredentialStatusRequest
+	invalidBatchUpdateCredentialStatusRequest := "invalid batch update credential status request"
+	if err := framework.Decode(r, &request); err != nil {
+		errMsg := invalidBatchUpdateCredentialStatusRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		errMsg := invalidBatchUpdateCredentialStatusRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	batchResponse, err := cr.service.BatchUpdateCredentialStatus(ctx, request.ToServiceRequest())
+	if err != nil {
+		errMsg := "could not update credential statuses"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+	}
+
+	resp := BatchUpdateCredentialStatusResponse{Results: make([]BatchUpdateCredentialStatusResult, 0, len(batchResponse.Results))}
+	for i, result := range batchResponse.Results {
+		resp.Results = append(resp.Results, BatchUpdateCredentialStatusResult{
+			ID: request.Requests[i].ID,
+			UpdateCredentialStatusResponse: UpdateCredentialStatusResponse{
+				Revoked:        result.Revoked,
+				Suspended:      result.Suspended,
+				SuspendedUntil: result.SuspendedUntil,
+			},
+		})
+	}
+	return framework.Respond(ctx, w, resp, http.StatusOK)
+}
+
 type VerifyCredentialRequest struct {
-	// A credential secured via data integrity. Must have the "proof" property set.
-	DataIntegrityCredential *credsdk.VerifiableCredential `json:"credential,omitempty"`
+	// A credential secured via data integrity. Must have the "proof" property set to a JsonWebSignature2020 proof, and
+	// its contexts must define all of its properties.
+	// It is only validated once verified, since the request validator cannot dive into its credentialStatus.
+	DataIntegrityCredential *credsdk.VerifiableCredential `json:"credential,omitempty" validate:"-"`
 
 	// A JWT that encodes a credential.
 	CredentialJWT *keyaccess.JWT `json:"credentialJwt,omitempty"`
+
+	// An SD-JWT that encodes a credential, followed by the disclosures of the claims to verify and, optionally, a key
+	// binding JWT signed by the credential subject.
+	CredentialSDJWT *keyaccess.SDJWT `json:"credentialSdJwt,omitempty"`
+
+	// The audience the key binding JWT of the SD-JWT must have been issued for. Required to verify a key binding JWT.
+	KeyBindingAudience string `json:"keyBindingAudience,omitempty"`
+
+	// The nonce the key binding JWT of the SD-JWT must have. Required to verify a key binding JWT.
+	KeyBindingNonce string `json:"keyBindingNonce,omitempty"`
+
+	// The name of the verification policy to verify the credential with. Every check runs when empty.
+	Policy string `json:"policy,omitempty"`
 }
 
 func (vcr VerifyCredentialRequest) IsValid() bool {
-	return (vcr.DataIntegrityCredential != nil && vcr.CredentialJWT == nil) ||
-		(vcr.DataIntegrityCredential == nil && vcr.CredentialJWT != nil)
+	provided := 0
+	for _, present := range []bool{vcr.DataIntegrityCredential != nil, vcr.CredentialJWT != nil, vcr.CredentialSDJWT != nil} {
+		if present {
+			provided++
+		}
+	}
+	return provided == 1
 }
 
 type VerifyCredentialResponse struct {
@@ -354,16 +769,28 @@ type VerifyCredentialResponse struct {
 
 	// The reason why this credential couldn't be verified.
 	Reason string `json:"reason,omitempty"`
+
+	// Whether the credential was revoked by its issuer, as held by its status list.
+	Revoked bool `json:"revoked,omitempty"`
+	// Whether the credential was suspended by its issuer, as held by its status list.
+	Suspended bool `json:"suspended,omitempty"`
+
+	// The checks run on the credential, in the order they ran, each with its outcome, one of `passed`, `failed` or
+	// `skipped`, and the detail of why.
+	Checks []credmodel.CheckResult `json:"checks,omitempty"`
 }
 
 // VerifyCredential godoc
 //
 // @Summary     Verify Credential
 // @Description Verify a given credential by its id. The system does the following levels of verification:
-// @Description 1. Makes sure the credential has a valid signature
-// @Description 2. Makes sure the credential has is not expired
+// @Description 1. Makes sure the credential has a valid signature, by a key of its issuer meant for issuing credentials
+// @Description 2. Makes sure the credential has is not expired, and is already valid
 // @Description 3. Makes sure the credential complies with the VC Data Model
 // @Description 4. If the credential has a schema, makes sure its data complies with the schema
+// @Description 5. If the credential has a status list entry, makes sure it has not been revoked or suspended
+// @Description The checks run, and what they accept, are as per the named verification policy, if any. The response
+// @Description lists each check run, in the order they ran, with its outcome.
 // @Tags        CredentialAPI
 // @Accept      json
 // @Produce     json
@@ -381,7 +808,7 @@ func (cr CredentialRouter) VerifyCredential(ctx context.Context, w http.Response
 	}
 
 	if !request.IsValid() {
-		err := errors.New("request must contain either a Data Integrity Credential or a JWT Credential")
+		err := errors.New("request must contain either a Data Integrity Credential, a JWT Credential or an SD-JWT Credential")
 		logrus.WithError(err).Error()
 		return framework.NewRequestError(err, http.StatusBadRequest)
 	}
@@ -389,6 +816,10 @@ func (cr CredentialRouter) VerifyCredential(ctx context.Context, w http.Response
 	verificationResult, err := cr.service.VerifyCredential(ctx, credential.VerifyCredentialRequest{
 		DataIntegrityCredential: request.DataIntegrityCredential,
 		CredentialJWT:           request.CredentialJWT,
+		CredentialSDJWT:         request.CredentialSDJWT,
+		KeyBindingAudience:      request.KeyBindingAudience,
+		KeyBindingNonce:         request.KeyBindingNonce,
+		Policy:                  request.Policy,
 	})
 	if err != nil {
 		errMsg := "could not verify credential"
@@ -396,86 +827,404 @@ func (cr CredentialRouter) VerifyCredential(ctx context.Context, w http.Response
 		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
 	}
 
-	resp := VerifyCredentialResponse{Verified: verificationResult.Verified, Reason: verificationResult.Reason}
+	resp := VerifyCredentialResponse{
+		Verified:  verificationResult.Verified,
+		Reason:    verificationResult.Reason,
+		Revoked:   verificationResult.Revoked,
+		Suspended: verificationResult.Suspended,
+		Checks:    verificationResult.Checks,
+	}
+	return framework.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// VerificationPolicy sets the checks run when verifying a credential, and what they accept.
+type VerificationPolicy struct {
+	// Made of 1 to 64 letters, digits, dashes or underscores.
+	Name string `json:"name" validate:"required"`
+	// The checks run on credentials, among `didResolution`, `keyPurpose`, `signature`, `keyBinding`, `dataModel`,
+	// `expiry`, `notBefore`, `schema` and `status`. The DID resolution and signature checks always run. Every check runs
+	// when empty.
+	RequiredChecks []string `json:"requiredChecks,omitempty"`
+	// The JWS algorithms credentials may be signed with, e.g. "EdDSA". Any algorithm is accepted when empty.
+	AllowedAlgorithms []string `json:"allowedAlgorithms,omitempty"`
+	// The methods of the DIDs credentials may be issued by, e.g. "key". Any method is accepted when empty.
+	AllowedDIDMethods []string `json:"allowedDidMethods,omitempty"`
+	// How long after their issuance credentials are accepted, as a duration such as "720h". Credentials of any age are
+	// accepted when empty.
+	MaxCredentialAge string `json:"maxCredentialAge,omitempty"`
+	// Whether the schema check is skipped, rather than failed, when the schema of a credential cannot be resolved.
+	AllowUnresolvableSchema bool `json:"allowUnresolvableSchema,omitempty"`
+	// Whether the status check is skipped, rather than failed, when the status list of a credential cannot be resolved.
+	AllowUnresolvableStatus bool `json:"allowUnresolvableStatus,omitempty"`
+}
+
+func (p VerificationPolicy) toServicePolicy() (*credmodel.Policy, error) {
+	policy := credmodel.Policy{
+		Name:                    p.Name,
+		AllowedAlgorithms:       p.AllowedAlgorithms,
+		AllowedDIDMethods:       p.AllowedDIDMethods,
+		AllowUnresolvableSchema: p.AllowUnresolvableSchema,
+		AllowUnresolvableStatus: p.AllowUnresolvableStatus,
+	}
+	for _, check := range p.RequiredChecks {
+		policy.RequiredChecks = append(policy.RequiredChecks, credmodel.Check(check))
+	}
+	if p.MaxCredentialAge != "" {
+		maxAge, err := time.ParseDuration(p.MaxCredentialAge)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing maxCredentialAge")
+		}
+		policy.MaxCredentialAge = maxAge
+	}
+	return &policy, nil
+}
+
+func toVerificationPolicy(p credmodel.Policy) VerificationPolicy {
+	policy := VerificationPolicy{
+		Name:                    p.Name,
+		AllowedAlgorithms:       p.AllowedAlgorithms,
+		AllowedDIDMethods:       p.AllowedDIDMethods,
+		AllowUnresolvableSchema: p.AllowUnresolvableSchema,
+		AllowUnresolvableStatus: p.AllowUnresolvableStatus,
+	}
+	for _, check := range p.RequiredChecks {
+		policy.RequiredChecks = append(policy.RequiredChecks, string(check))
+	}
+	if p.MaxCredentialAge != 0 {
+		policy.MaxCredentialAge = p.MaxCredentialAge.String()
+	}
+	return policy
+}
+
+type CreateVerificationPolicyRequest struct {
+	VerificationPolicy
+}
+
+type CreateVerificationPolicyResponse struct {
+	Policy VerificationPolicy `json:"policy"`
+}
+
+// CreateVerificationPolicy godoc
+//
+// @Summary     Create Verification Policy
+// @Description Create a named verification policy, which sets the checks run when verifying a credential, and what
+// @Description they accept. Credentials are verified with a policy when it is named by the verification request, or
+// @Description by the presentation definition or manifest they are submitted against. Policies cannot have the name
+// @Description of another policy, including those defined in the config.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       request body     CreateVerificationPolicyRequest true "request body"
+// @Success     201     {object} CreateVerificationPolicyResponse
+// @Failure     400     {string} string "Bad request"
+// @Failure     500     {string} string "Internal server error"
+// @Router      /v1/credentials/verification/policies [put]
+func (cr CredentialRouter) CreateVerificationPolicy(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request CreateVerificationPolicyRequest
+	invalidCreatePolicyRequest := "invalid create verification policy request"
+	if err := framework.Decode(r, &request); err != nil {
+		errMsg := invalidCreatePolicyRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		errMsg := invalidCreatePolicyRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	policy, err := request.toServicePolicy()
+	if err != nil {
+		errMsg := invalidCreatePolicyRequest
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	created, err := cr.service.CreateVerificationPolicy(ctx, credential.CreateVerificationPolicyRequest{Policy: *policy})
+	if err != nil {
+		errMsg := "could not create verification policy"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	resp := CreateVerificationPolicyResponse{Policy: toVerificationPolicy(created.Policy)}
+	return framework.Respond(ctx, w, resp, http.StatusCreated)
+}
+
+type GetVerificationPolicyResponse struct {
+	Policy VerificationPolicy `json:"policy"`
+	// Whether the policy is defined in the config of the service, in which case it cannot be deleted.
+	Configured bool `json:"configured"`
+}
+
+// GetVerificationPolicy godoc
+//
+// @Summary     Get Verification Policy
+// @Description Get a verification policy by its name, whether it is defined in the config or was created through the
+// @Description API.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       id  path     string true "Name"
+// @Success     200 {object} GetVerificationPolicyResponse
+// @Failure     400 {string} string "Bad request"
+// @Router      /v1/credentials/verification/policies/{id} [get]
+func (cr CredentialRouter) GetVerificationPolicy(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	name := framework.GetParam(ctx, IDParam)
+	if name == nil {
+		errMsg := "cannot get verification policy without name parameter"
+		logrus.Error(errMsg)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	gotPolicy, err := cr.service.GetVerificationPolicy(ctx, credential.GetVerificationPolicyRequest{Name: *name})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not get verification policy with name: %s", util.SanitizeLog(*name))
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	resp := GetVerificationPolicyResponse{Policy: toVerificationPolicy(gotPolicy.Policy), Configured: gotPolicy.Configured}
+	return framework.Respond(ctx, w, resp, http.StatusOK)
+}
+
+type GetVerificationPoliciesResponse struct {
+	Policies []GetVerificationPolicyResponse `json:"policies"`
+}
+
+// GetVerificationPolicies godoc
+//
+// @Summary     Get Verification Policies
+// @Description Lists the verification policies defined in the config along with those created through the API, sorted
+// @Description by name.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} GetVerificationPoliciesResponse
+// @Failure     500 {string} string "Internal server error"
+// @Router      /v1/credentials/verification/policies [get]
+func (cr CredentialRouter) GetVerificationPolicies(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	gotPolicies, err := cr.service.GetVerificationPolicies(ctx)
+	if err != nil {
+		errMsg := "could not get verification policies"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+	}
+
+	policies := make([]GetVerificationPolicyResponse, 0, len(gotPolicies.Policies))
+	for _, policy := range gotPolicies.Policies {
+		policies = append(policies, GetVerificationPolicyResponse{
+			Policy:     toVerificationPolicy(policy.Policy),
+			Configured: policy.Configured,
+		})
+	}
+
+	resp := GetVerificationPoliciesResponse{Policies: policies}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
+// DeleteVerificationPolicy godoc
+//
+// @Summary     Delete Verification Policy
+// @Description Delete a verification policy created through the API by its name. The presentation definitions and
+// @Description manifests which name it can no longer be submitted to until a policy with the same name is created.
+// @Tags        CredentialAPI
+// @Accept      json
+// @Produce     json
+// @Param       id  path     string true "Name"
+// @Success     204 {string} string "No Content"
+// @Failure     400 {string} string "Bad request"
+// @Failure     500 {string} string "Internal server error"
+// @Router      /v1/credentials/verification/policies/{id} [delete]
+func (cr CredentialRouter) DeleteVerificationPolicy(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	name := framework.GetParam(ctx, IDParam)
+	if name == nil {
+		errMsg := "cannot delete verification policy without name parameter"
+		logrus.Error(errMsg)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	if err := cr.service.DeleteVerificationPolicy(ctx, credential.DeleteVerificationPolicyRequest{Name: *name}); err != nil {
+		errMsg := fmt.Sprintf("could not delete verification policy with name: %s", util.SanitizeLog(*name))
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	return framework.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
 type GetCredentialsResponse struct {
 	// Array of credential containers.
 	Credentials []credmodel.Container `json:"credentials"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetCredentials godoc
 //
 // @Summary     Get Credentials
-// @Description Checks for the presence of a query parameter and calls the associated filtered get method. Only one parameter is allowed to be specified.
+// @Description Lists the credentials that match the filter, in the given order. Filters follow https://google.aip.dev/160
+// @Description and may refer to issuer, subject, schema, type, revoked, suspended, issuanceDate and expiry, e.g.
+// @Description `issuer="did:key:abc" AND revoked=false`, `type:"EmployeeCredential"` or `expiry < "2024-01-01T00:00:00Z"`.
+// @Description Credentials without an expiration date have an expiry of 9999-12-31T23:59:59Z. Credentials can be
+// @Description ordered by issuer, subject, schema, issuanceDate and expiry, e.g. `expiry desc, issuer`.
+// @Description The issuer, schema and subject parameters list the credentials with that value, and only one of
+// @Description them may be given, without a filter or order.
 // @Tags        CredentialAPI
 // @Accept      json
 // @Produce     json
-// @Param       issuer  query    string false "The issuer id" example(did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp)
-// @Param       schema  query    string false "The credentialSchema.id value to filter by"
-// @Param       subject query    string false "The credentialSubject.id value to filter by"
-// @Success     200     {object} GetCredentialsResponse
-// @Failure     400     {string} string "Bad request"
-// @Failure     500     {string} string "Internal server error"
+// @Param       issuer    query    string false "The issuer id" example(did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp)
+// @Param       schema    query    string false "The credentialSchema.id value to filter by"
+// @Param       subject   query    string false "The credentialSubject.id value to filter by"
+// @Param       filter    query    string false "A standard filter expression conforming to https://google.aip.dev/160" example(issuer="did:key:abc" AND revoked=false)
+// @Param       orderBy   query    string false "A comma separated list of the fields to order by, each optionally followed by desc, conforming to https://google.aip.dev/132#ordering" example(issuanceDate desc)
+// @Param       pageSize  query    int    false "Maximum number of credentials to return. All are returned when unset."
+// @Param       pageToken query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetCredentialsResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/credentials [get]
 func (cr CredentialRouter) GetCredentials(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	issuer := framework.GetQueryValue(r, IssuerParam)
 	schema := framework.GetQueryValue(r, SchemaParam)
 	subject := framework.GetQueryValue(r, SubjectParam)
+	filter := framework.GetQueryValue(r, FilterParam)
+	orderBy := framework.GetQueryValue(r, OrderByParam)
 
-	err := framework.NewRequestErrorMsg("must use one of the following query parameters: issuer, subject, schema", http.StatusBadRequest)
+	err := framework.NewRequestErrorMsg("must use only one of the following query parameters: issuer, subject, schema, and not along with a filter or order", http.StatusBadRequest)
 
 	// check if there are multiple parameters set, which is not allowed
 	if (issuer != nil && subject != nil) || (issuer != nil && schema != nil) || (subject != nil && schema != nil) {
 		return err
 	}
+	legacy := issuer != nil || subject != nil || schema != nil
+	if legacy && (filter != nil || orderBy != nil) {
+		return err
+	}
+
+	page, pageErr := getPageRequest(r)
+	if pageErr != nil {
+		return pageErr
+	}
 
 	if issuer != nil {
-		return cr.getCredentialsByIssuer(ctx, *issuer, w, r)
+		return cr.getCredentialsByIssuer(ctx, *issuer, page, w)
 	}
 	if subject != nil {
-		return cr.getCredentialsBySubject(ctx, *subject, w, r)
+		return cr.getCredentialsBySubject(ctx, *subject, page, w)
 	}
 	if schema != nil {
-		return cr.getCredentialsBySchema(ctx, *schema, w, r)
+		return cr.getCredentialsBySchema(ctx, *schema, page, w)
+	}
+
+	var query listCredentialsQuery
+	if filter != nil {
+		query.filter = *filter
 	}
-	return err
+	if orderBy != nil {
+		query.orderBy = *orderBy
+	}
+	return cr.listCredentials(ctx, query, page, w)
+}
+
+// listCredentialsQuery holds the filter and order of a request listing credentials.
+type listCredentialsQuery struct {
+	filter  string
+	orderBy string
+}
+
+func (q listCredentialsQuery) GetFilter() string {
+	return q.filter
 }
 
-func (cr CredentialRouter) getCredentialsByIssuer(ctx context.Context, issuer string, w http.ResponseWriter, _ *http.Request) error {
-	gotCredentials, err := cr.service.GetCredentialsByIssuer(ctx, credential.GetCredentialByIssuerRequest{Issuer: issuer})
+func (q listCredentialsQuery) GetOrderBy() string {
+	return q.orderBy
+}
+
+func (cr CredentialRouter) listCredentials(ctx context.Context, query listCredentialsQuery, page svcframework.PageRequest, w http.ResponseWriter) error {
+	declarations, err := filtering.NewDeclarations(append(storage.FilterDeclarations(),
+		filtering.DeclareIdent("issuer", filtering.TypeString),
+		filtering.DeclareIdent("subject", filtering.TypeString),
+		filtering.DeclareIdent("schema", filtering.TypeString),
+		filtering.DeclareIdent("type", filtering.TypeList(filtering.TypeString)),
+		filtering.DeclareIdent("revoked", filtering.TypeBool),
+		filtering.DeclareIdent("suspended", filtering.TypeBool),
+		filtering.DeclareIdent("issuanceDate", filtering.TypeTimestamp),
+		filtering.DeclareIdent("expiry", filtering.TypeTimestamp),
+		filtering.DeclareIdent("true", filtering.TypeBool),
+		filtering.DeclareIdent("false", filtering.TypeBool),
+	)...)
+	if err != nil {
+		return framework.NewRequestError(
+			sdkutil.LoggingErrorMsg(err, "creating filter declarations"), http.StatusInternalServerError)
+	}
+
+	// Because parsing filters can be expensive, we limit is to a fixed len of chars. That should be more than enough
+	// for most use cases.
+	if len(query.GetFilter()) > FilterCharacterLimit {
+		err := errors.Errorf("filter longer than %d character size limit", FilterCharacterLimit)
+		return framework.NewRequestError(
+			sdkutil.LoggingErrorMsg(err, "invalid filter"), http.StatusBadRequest)
+	}
+	filter, err := filtering.ParseFilter(query, declarations)
+	if err != nil {
+		return framework.NewRequestError(
+			sdkutil.LoggingErrorMsg(err, "invalid filter"), http.StatusBadRequest)
+	}
+	orderBy, err := ordering.ParseOrderBy(query)
+	if err == nil {
+		err = orderBy.ValidateForPaths(credential.ListCredentialsOrderByFields...)
+	}
+	if err != nil {
+		return framework.NewRequestError(
+			sdkutil.LoggingErrorMsg(err, "invalid order"), http.StatusBadRequest)
+	}
+
+	gotCredentials, err := cr.service.ListCredentials(ctx, credential.ListCredentialsRequest{Filter: filter, OrderBy: orderBy, PageRequest: page})
+	if err != nil {
+		return framework.NewRequestError(
+			sdkutil.LoggingErrorMsg(err, "could not list credentials"), listErrorStatus(err))
+	}
+
+	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials, NextPageToken: gotCredentials.NextPageToken}
+	return framework.Respond(ctx, w, resp, http.StatusOK)
+}
+
+func (cr CredentialRouter) getCredentialsByIssuer(ctx context.Context, issuer string, page svcframework.PageRequest, w http.ResponseWriter) error {
+	gotCredentials, err := cr.service.GetCredentialsByIssuer(ctx, credential.GetCredentialByIssuerRequest{Issuer: issuer, PageRequest: page})
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get credentials for issuer: %s", util.SanitizeLog(issuer))
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
-	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials}
+	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials, NextPageToken: gotCredentials.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
-func (cr CredentialRouter) getCredentialsBySubject(ctx context.Context, subject string, w http.ResponseWriter, _ *http.Request) error {
-	gotCredentials, err := cr.service.GetCredentialsBySubject(ctx, credential.GetCredentialBySubjectRequest{Subject: subject})
+func (cr CredentialRouter) getCredentialsBySubject(ctx context.Context, subject string, page svcframework.PageRequest, w http.ResponseWriter) error {
+	gotCredentials, err := cr.service.GetCredentialsBySubject(ctx, credential.GetCredentialBySubjectRequest{Subject: subject, PageRequest: page})
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get credentials for subject: %s", util.SanitizeLog(subject))
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
-	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials}
+	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials, NextPageToken: gotCredentials.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
-func (cr CredentialRouter) getCredentialsBySchema(ctx context.Context, schema string, w http.ResponseWriter, _ *http.Request) error {
-	gotCredentials, err := cr.service.GetCredentialsBySchema(ctx, credential.GetCredentialBySchemaRequest{Schema: schema})
+func (cr CredentialRouter) getCredentialsBySchema(ctx context.Context, schema string, page svcframework.PageRequest, w http.ResponseWriter) error {
+	gotCredentials, err := cr.service.GetCredentialsBySchema(ctx, credential.GetCredentialBySchemaRequest{Schema: schema, PageRequest: page})
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get credentials for schema: %s", util.SanitizeLog(schema))
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
-	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials}
+	resp := GetCredentialsResponse{Credentials: gotCredentials.Credentials, NextPageToken: gotCredentials.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
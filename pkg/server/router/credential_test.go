@@ -8,6 +8,7 @@ import (
 	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/credential/status"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
@@ -17,6 +18,8 @@ import (
 	"github.com/goccy/go-json"
 
 	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
@@ -548,6 +551,361 @@ func TestCredentialRouter(t *testing.T) {
 
 	})
 
+	t.Run("Data Integrity Credential Status List Test Update Revoked Status", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		assert.NotNil(tt, bolt)
+
+		serviceConfig := config.CredentialServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "credential", ServiceEndpoint: "http://localhost:1234"}}
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, credService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		issuer := issuerDID.DID.ID
+		issuerKID := issuerDID.DID.VerificationMethod[0].ID
+		subject := "did:test:345"
+
+		// an unknown format is rejected
+		_, err = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:    issuer,
+			IssuerKID: issuerKID,
+			Subject:   subject,
+			Data:      map[string]any{"email": "Satoshi@Nakamoto.btc"},
+			Format:    "mso_mdoc",
+		})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported credential format<mso_mdoc>")
+
+		createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:    issuer,
+			IssuerKID: issuerKID,
+			Subject:   subject,
+			Data:      map[string]any{"email": "Satoshi@Nakamoto.btc"},
+			Expiry:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			Revocable: true,
+			Format:    exchange.LDPVC.CredentialFormat(),
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, createdCred)
+		assert.Empty(tt, createdCred.CredentialJWT)
+		assert.True(tt, createdCred.HasDataIntegrityCredential())
+		assert.Equal(tt, exchange.LDPVC.CredentialFormat(), createdCred.Format())
+		assert.Contains(tt, createdCred.Credential.Context, status.StatusList2021Context)
+
+		proofBytes, err := json.Marshal(createdCred.Credential.Proof)
+		assert.NoError(tt, err)
+		var proof map[string]any
+		err = json.Unmarshal(proofBytes, &proof)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "JsonWebSignature2020", proof["type"])
+		assert.Equal(tt, issuerKID, proof["verificationMethod"])
+
+		// the stored credential keeps its proof
+		gotCred, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: createdCred.ID})
+		assert.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(gotCred.ID, "urn:uuid:"))
+		createdCredBytes, err := json.Marshal(createdCred.Credential)
+		assert.NoError(tt, err)
+		gotCredBytes, err := json.Marshal(gotCred.Credential)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, string(createdCredBytes), string(gotCredBytes))
+		assert.Empty(tt, gotCred.CredentialJWT)
+
+		verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: gotCred.Credential})
+		assert.NoError(tt, err)
+		assert.True(tt, verified.Verified, verified.Reason)
+
+		// tampering with the credential breaks its proof
+		tampered, err := credint.CopyCredential(*gotCred.Credential)
+		assert.NoError(tt, err)
+		tampered.CredentialSubject["id"] = "did:test:678"
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: tampered})
+		assert.NoError(tt, err)
+		assert.False(tt, verified.Verified)
+
+		// the status list is created in the format of the credential
+		statusBytes, err := json.Marshal(createdCred.Credential.CredentialStatus)
+		assert.NoError(tt, err)
+		var statusEntry status.StatusList2021Entry
+		err = json.Unmarshal(statusBytes, &statusEntry)
+		assert.NoError(tt, err)
+		_, credStatusListID, ok := strings.Cut(statusEntry.StatusListCredential, "/v1/credentials/status/")
+		assert.True(tt, ok)
+
+		credStatusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: credStatusListID})
+		assert.NoError(tt, err)
+		assert.Equal(tt, exchange.LDPVC.CredentialFormat(), credStatusList.Format())
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: credStatusList.Credential})
+		assert.NoError(tt, err)
+		assert.True(tt, verified.Verified, verified.Reason)
+
+		updatedStatus, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: createdCred.ID, Revoked: true})
+		assert.NoError(tt, err)
+		assert.True(tt, updatedStatus.Revoked)
+
+		// and is signed again in that format once updated
+		credStatusListAfterRevoke, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: credStatusListID})
+		assert.NoError(tt, err)
+		assert.Equal(tt, exchange.LDPVC.CredentialFormat(), credStatusListAfterRevoke.Format())
+		valid, err := status.ValidateCredentialInStatusList(*createdCred.Credential, *credStatusListAfterRevoke.Credential)
+		assert.NoError(tt, err)
+		assert.True(tt, valid)
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: credStatusListAfterRevoke.Credential})
+		assert.NoError(tt, err)
+		assert.True(tt, verified.Verified, verified.Reason)
+
+		// a vc-jwt of the same issuer shares the status list
+		createdJWTCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:    issuer,
+			IssuerKID: issuerKID,
+			Subject:   subject,
+			Data:      map[string]any{"email": "Satoshi@Nakamoto.btc"},
+			Revocable: true,
+		})
+		assert.NoError(tt, err)
+		assert.Equal(tt, exchange.JWTVC.CredentialFormat(), createdJWTCred.Format())
+		jwtStatusBytes, err := json.Marshal(createdJWTCred.Credential.CredentialStatus)
+		assert.NoError(tt, err)
+		var jwtStatusEntry status.StatusList2021Entry
+		err = json.Unmarshal(jwtStatusBytes, &jwtStatusEntry)
+		assert.NoError(tt, err)
+		assert.Equal(tt, statusEntry.StatusListCredential, jwtStatusEntry.StatusListCredential)
+	})
+
+	t.Run("SD-JWT Credential Test", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		assert.NotNil(tt, bolt)
+
+		serviceConfig := config.CredentialServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "credential", ServiceEndpoint: "http://localhost:1234"}}
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, credService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		issuer := issuerDID.DID.ID
+		issuerKID := issuerDID.DID.VerificationMethod[0].ID
+		subjectKey, subjectDIDKey, err := didsdk.GenerateDIDKey(crypto.Ed25519)
+		assert.NoError(tt, err)
+		subjectDID, err := subjectDIDKey.Expand()
+		assert.NoError(tt, err)
+		subject := subjectDID.ID
+		data := map[string]any{
+			"email":   "Satoshi@Nakamoto.btc",
+			"address": map[string]any{"street": "Main St", "country": "US"},
+		}
+
+		// disclosable claims are only supported by sd-jwts
+		_, err = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:            issuer,
+			IssuerKID:         issuerKID,
+			Subject:           subject,
+			Data:              data,
+			Format:            exchange.JWTVC.CredentialFormat(),
+			DisclosableClaims: []string{"email"},
+		})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "disclosable claims are only supported by the vc+sd-jwt format")
+
+		// and must be present in the data
+		_, err = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:            issuer,
+			IssuerKID:         issuerKID,
+			Subject:           subject,
+			Data:              data,
+			DisclosableClaims: []string{"phone"},
+		})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "claim<phone> not found")
+
+		createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:            issuer,
+			IssuerKID:         issuerKID,
+			Subject:           subject,
+			Data:              data,
+			Revocable:         true,
+			DisclosableClaims: []string{"email", "address.street"},
+		})
+		assert.NoError(tt, err)
+		assert.Equal(tt, credint.SDJWTVC, createdCred.Format())
+		assert.Empty(tt, createdCred.CredentialJWT)
+		assert.Equal(tt, "Satoshi@Nakamoto.btc", createdCred.Credential.CredentialSubject["email"])
+
+		// the stored credential keeps its sd-jwt
+		gotCred, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: createdCred.ID})
+		assert.NoError(tt, err)
+		assert.Equal(tt, createdCred.CredentialSDJWT, gotCred.CredentialSDJWT)
+		assert.Equal(tt, credint.SDJWTVC, gotCred.Format())
+
+		verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialSDJWT: gotCred.CredentialSDJWT})
+		assert.NoError(tt, err)
+		assert.True(tt, verified.Verified, verified.Reason)
+
+		// the holder only presents the street
+		issuerJWT, disclosures, _, err := gotCred.CredentialSDJWT.Parse()
+		assert.NoError(tt, err)
+		assert.Len(tt, disclosures, 2)
+		var streetDisclosures []keyaccess.Disclosure
+		for _, d := range disclosures {
+			if d.Name == "street" {
+				streetDisclosures = append(streetDisclosures, d)
+			}
+		}
+		presented := keyaccess.NewSDJWT(issuerJWT, streetDisclosures, nil)
+		disclosedCred, err := credint.DisclosedCredential(presented)
+		assert.NoError(tt, err)
+		assert.NotContains(tt, disclosedCred.CredentialSubject, "email")
+		assert.Equal(tt, map[string]any{"street": "Main St", "country": "US"}, disclosedCred.CredentialSubject["address"])
+		assert.Equal(tt, subject, disclosedCred.CredentialSubject.GetID())
+
+		// bound to the subject's key
+		subjectKeyAccess, err := keyaccess.NewJWKKeyAccess(subject, subjectDID.VerificationMethod[0].ID, subjectKey)
+		assert.NoError(tt, err)
+		bound, err := subjectKeyAccess.SignKeyBinding(presented, "did:test:verifier", "nonce")
+		assert.NoError(tt, err)
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{
+			CredentialSDJWT:    bound,
+			KeyBindingAudience: "did:test:verifier",
+			KeyBindingNonce:    "nonce",
+		})
+		assert.NoError(tt, err)
+		assert.True(tt, verified.Verified, verified.Reason)
+
+		// which is only verified for the audience and nonce it was issued for
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{
+			CredentialSDJWT:    bound,
+			KeyBindingAudience: "did:test:verifier",
+			KeyBindingNonce:    "replayed",
+		})
+		assert.NoError(tt, err)
+		assert.False(tt, verified.Verified)
+		assert.Contains(tt, verified.Reason, "does not have the expected nonce")
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialSDJWT: bound})
+		assert.NoError(tt, err)
+		assert.False(tt, verified.Verified)
+		assert.Contains(tt, verified.Reason, "audience and nonce")
+
+		// a key binding by another key is not verified
+		otherKey, otherDIDKey, err := didsdk.GenerateDIDKey(crypto.Ed25519)
+		assert.NoError(tt, err)
+		otherDID, err := otherDIDKey.Expand()
+		assert.NoError(tt, err)
+		otherKeyAccess, err := keyaccess.NewJWKKeyAccess(otherDID.ID, otherDID.VerificationMethod[0].ID, otherKey)
+		assert.NoError(tt, err)
+		otherBound, err := otherKeyAccess.SignKeyBinding(presented, "did:test:verifier", "nonce")
+		assert.NoError(tt, err)
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{
+			CredentialSDJWT:    otherBound,
+			KeyBindingAudience: "did:test:verifier",
+			KeyBindingNonce:    "nonce",
+		})
+		assert.NoError(tt, err)
+		assert.False(tt, verified.Verified)
+
+		// nor is a disclosure the issuer did not sign
+		forged, err := keyaccess.NewDisclosure("email", "Hal@Finney.btc")
+		assert.NoError(tt, err)
+		verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{
+			CredentialSDJWT: keyaccess.NewSDJWT(issuerJWT, []keyaccess.Disclosure{*forged}, nil).Ptr(),
+		})
+		assert.NoError(tt, err)
+		assert.False(tt, verified.Verified)
+
+		// the status list is not issued as an sd-jwt
+		statusBytes, err := json.Marshal(createdCred.Credential.CredentialStatus)
+		assert.NoError(tt, err)
+		var statusEntry status.StatusList2021Entry
+		err = json.Unmarshal(statusBytes, &statusEntry)
+		assert.NoError(tt, err)
+		_, credStatusListID, ok := strings.Cut(statusEntry.StatusListCredential, "/v1/credentials/status/")
+		assert.True(tt, ok)
+		credStatusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: credStatusListID})
+		assert.NoError(tt, err)
+		assert.Equal(tt, exchange.JWTVC.CredentialFormat(), credStatusList.Format())
+
+		updatedStatus, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: createdCred.ID, Revoked: true})
+		assert.NoError(tt, err)
+		assert.True(tt, updatedStatus.Revoked)
+		gotCred, err = credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: createdCred.ID})
+		assert.NoError(tt, err)
+		assert.Equal(tt, createdCred.CredentialSDJWT, gotCred.CredentialSDJWT)
+	})
+
+	t.Run("Credential With Data Model Properties Test", func(tt *testing.T) {
+		issuer, issuerKID, _, credService := createCredServicePrereqs(tt)
+		request := credential.CreateCredentialRequest{
+			Issuer:    issuer,
+			IssuerKID: issuerKID,
+			Subject:   "did:test:345",
+			ID:        "urn:uuid:6fa2ad3b-4f15-4f4a-a1a5-2d3f5c9d3a47",
+			Context:   []any{"https://www.w3.org/2018/credentials/examples/v1", "https://w3id.org/citizenship/v1"},
+			Type:      "EmployeeCredential",
+			Data:      map[string]any{"email": "Satoshi@Nakamoto.btc"},
+			Evidence: []any{map[string]any{
+				"id":               "https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231",
+				"type":             []any{"DocumentVerification"},
+				"verifier":         "https://example.edu/issuers/14",
+				"evidenceDocument": "DriversLicense",
+			}},
+			TermsOfUse: []credsdk.TermsOfUse{{Type: "IssuerPolicy", ID: "http://example.com/policies/credential/4"}},
+		}
+		createdCred, err := credService.CreateCredential(context.Background(), request)
+		assert.NoError(tt, err)
+		assert.Equal(tt, request.ID, createdCred.ID)
+		cred := createdCred.Credential
+		assert.Equal(tt, request.ID, cred.ID)
+		assert.Equal(tt, []string{"https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1", "https://w3id.org/citizenship/v1"}, cred.Context)
+		assert.Equal(tt, []string{"VerifiableCredential", "EmployeeCredential"}, cred.Type)
+		assert.Len(tt, cred.Evidence, 1)
+		assert.Equal(tt, request.TermsOfUse, cred.TermsOfUse)
+
+		// the properties are signed into the credential
+		gotCred, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: request.ID})
+		assert.NoError(tt, err)
+		_, _, parsed, err := credsdk.ParseVerifiableCredentialFromJWT(gotCred.CredentialJWT.String())
+		assert.NoError(tt, err)
+		assert.Equal(tt, request.ID, parsed.ID)
+		assert.Equal(tt, "DriversLicense", parsed.Evidence[0].(map[string]any)["evidenceDocument"])
+		assert.Equal(tt, request.TermsOfUse, parsed.TermsOfUse)
+
+		// ids are unique
+		_, err = credService.CreateCredential(context.Background(), request)
+		assert.ErrorContains(tt, err, "already exists")
+		batchResp, err := credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+			Requests: []credential.CreateCredentialRequest{
+				{Issuer: issuer, IssuerKID: issuerKID, Subject: "did:test:345", ID: "urn:example:1", Data: map[string]any{}},
+				{Issuer: issuer, IssuerKID: issuerKID, Subject: "did:test:345", ID: "urn:example:1", Data: map[string]any{}},
+			},
+		})
+		assert.NoError(tt, err)
+		assert.Empty(tt, batchResp.Results[0].Error)
+		assert.Contains(tt, batchResp.Results[1].Error, "more than one request of the batch")
+
+		// properties must follow the data model
+		for _, bad := range []credential.CreateCredentialRequest{
+			{ID: "not a uri"},
+			{Context: "examples"},
+			{Type: 3},
+			{Evidence: []any{map[string]any{"id": "https://example.edu/evidence/1"}}},
+			{TermsOfUse: []credsdk.TermsOfUse{{ID: "http://example.com/policies/credential/4"}}},
+		} {
+			bad.Issuer, bad.IssuerKID, bad.Subject = issuer, issuerKID, "did:test:345"
+			_, err = credService.CreateCredential(context.Background(), bad)
+			assert.ErrorContains(tt, err, "invalid credential properties", "%+v", bad)
+		}
+	})
+
 	t.Run("Create Multiple Suspendable Credential Different Issuer SchemaID StatusPurpose Triples", func(tt *testing.T) {
 		bolt := setupTestDB(tt)
 		assert.NotNil(tt, bolt)
@@ -230,6 +230,9 @@ func (dr DIDRouter) GetDIDByMethod(ctx context.Context, w http.ResponseWriter, _
 
 type GetDIDsByMethodResponse struct {
 	DIDs []didsdk.Document `json:"dids,omitempty"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 type GetDIDsRequest struct {
@@ -245,30 +248,36 @@ type GetDIDsRequest struct {
 // @Tags        DecentralizedIdentityAPI
 // @Accept      json
 // @Produce     json
-// @Param       request body     GetDIDsRequest true "request body"
-// @Success     200     {object} GetDIDsByMethodResponse
-// @Failure     400     {string} string "Bad request"
-// @Failure     500     {string} string "Internal server error"
+// @Param       request   body     GetDIDsRequest true  "request body"
+// @Param       pageSize  query    int            false "Maximum number of DIDs to return. All are returned when unset."
+// @Param       pageToken query    string         false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetDIDsByMethodResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/dids [get]
-func (dr DIDRouter) GetDIDsByMethod(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+func (dr DIDRouter) GetDIDsByMethod(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	method := framework.GetParam(ctx, MethodParam)
 	if method == nil {
 		errMsg := "get DIDs by method request missing method parameter"
 		logrus.Error(errMsg)
 		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
 	}
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
 
 	// TODO(gabe) check if the method is supported, to tell whether this is a bad req or internal error
 	// TODO(gabe) differentiate between internal errors and not found DIDs
-	getDIDsRequest := did.GetDIDsRequest{Method: didsdk.Method(*method)}
+	getDIDsRequest := did.GetDIDsRequest{Method: didsdk.Method(*method), PageRequest: page}
 	gotDIDs, err := dr.service.GetDIDsByMethod(ctx, getDIDsRequest)
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get DIDs for method: %s", *method)
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
-	resp := GetDIDsByMethodResponse{DIDs: gotDIDs.DIDs}
+	resp := GetDIDsByMethodResponse{DIDs: gotDIDs.DIDs, NextPageToken: gotDIDs.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
@@ -337,7 +346,7 @@ func (dr DIDRouter) ResolveDID(ctx context.Context, w http.ResponseWriter, _ *ht
 	}
 
 	resolveDIDRequest := did.ResolveDIDRequest{DID: *id}
-	resolvedDID, err := dr.service.ResolveDID(resolveDIDRequest)
+	resolvedDID, err := dr.service.ResolveDID(ctx, resolveDIDRequest)
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get DID with id: %s", *id)
 		logrus.WithError(err).Error(errMsg)
@@ -118,6 +118,9 @@ func (ir IssuanceRouter) DeleteIssuanceTemplate(ctx context.Context, w http.Resp
 
 type ListIssuanceTemplatesResponse struct {
 	IssuanceTemplates []issuing.IssuanceTemplate `json:"issuanceTemplates"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // ListIssuanceTemplates godoc
@@ -127,18 +130,25 @@ type ListIssuanceTemplatesResponse struct {
 // @Tags        IssuingAPI
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} ListIssuanceTemplatesResponse
-// @Failure     400 {string} string "Bad request"
-// @Failure     500 {string} string "Internal server error"
+// @Param       pageSize  query    int    false "Maximum number of templates to return. All are returned when unset."
+// @Param       pageToken query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} ListIssuanceTemplatesResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/manifests [get]
-func (ir IssuanceRouter) ListIssuanceTemplates(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	gotManifests, err := ir.service.ListIssuanceTemplates(ctx, &issuing.ListIssuanceTemplatesRequest{})
+func (ir IssuanceRouter) ListIssuanceTemplates(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	gotManifests, err := ir.service.ListIssuanceTemplates(ctx, &issuing.ListIssuanceTemplatesRequest{PageRequest: page})
 
 	if err != nil {
 		return framework.NewRequestError(
 			sdkutil.LoggingErrorMsg(err, "could not get templates"), http.StatusBadRequest)
 	}
 
-	resp := ListIssuanceTemplatesResponse{IssuanceTemplates: gotManifests.IssuanceTemplates}
+	resp := ListIssuanceTemplatesResponse{IssuanceTemplates: gotManifests.IssuanceTemplates, NextPageToken: gotManifests.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
@@ -24,6 +24,11 @@ import (
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
 )
 
+const (
+	ManifestIDParam   string = "manifestId"
+	ApplicantDIDParam string = "applicantDid"
+)
+
 type ManifestRouter struct {
 	service *manifest.Service
 }
@@ -50,6 +55,9 @@ type CreateManifestRequest struct {
 	ClaimFormat            *exchange.ClaimFormat            `json:"format" validate:"required,dive"`
 	OutputDescriptors      []manifestsdk.OutputDescriptor   `json:"outputDescriptors" validate:"required,dive"`
 	PresentationDefinition *exchange.PresentationDefinition `json:"presentationDefinition,omitempty" validate:"omitempty,dive"`
+	// The name of the verification policy the credentials submitted with applications are verified with. Every check
+	// runs when empty.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 func (c CreateManifestRequest) ToServiceRequest() model.CreateManifestRequest {
@@ -62,6 +70,7 @@ func (c CreateManifestRequest) ToServiceRequest() model.CreateManifestRequest {
 		OutputDescriptors:      c.OutputDescriptors,
 		ClaimFormat:            c.ClaimFormat,
 		PresentationDefinition: c.PresentationDefinition,
+		VerificationPolicy:     c.VerificationPolicy,
 	}
 }
 
@@ -112,6 +121,8 @@ type GetManifestResponse struct {
 	ID          string                         `json:"id"`
 	Manifest    manifestsdk.CredentialManifest `json:"credential_manifest"`
 	ManifestJWT keyaccess.JWT                  `json:"manifestJwt"`
+	// The name of the verification policy the credentials submitted with applications are verified with, if any.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 // GetManifest godoc
@@ -141,15 +152,19 @@ func (mr ManifestRouter) GetManifest(ctx context.Context, w http.ResponseWriter,
 	}
 
 	resp := GetManifestResponse{
-		ID:          gotManifest.Manifest.ID,
-		Manifest:    gotManifest.Manifest,
-		ManifestJWT: gotManifest.ManifestJWT,
+		ID:                 gotManifest.Manifest.ID,
+		Manifest:           gotManifest.Manifest,
+		ManifestJWT:        gotManifest.ManifestJWT,
+		VerificationPolicy: gotManifest.VerificationPolicy,
 	}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
 type GetManifestsResponse struct {
 	Manifests []GetManifestResponse `json:"manifests,omitempty"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetManifests godoc
@@ -159,15 +174,22 @@ type GetManifestsResponse struct {
 // @Tags        ManifestAPI
 // @Accept      json
 // @Produce     json
-// @Param       issuer  query    string false "string issuer"
-// @Param       schema  query    string false "string schema"
-// @Param       subject query    string false "string subject"
-// @Success     200     {object} GetManifestsResponse
-// @Failure     400     {string} string "Bad request"
-// @Failure     500     {string} string "Internal server error"
+// @Param       issuer    query    string false "string issuer"
+// @Param       schema    query    string false "string schema"
+// @Param       subject   query    string false "string subject"
+// @Param       pageSize  query    int    false "Maximum number of manifests to return. All are returned when unset."
+// @Param       pageToken query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetManifestsResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/manifests [get]
-func (mr ManifestRouter) GetManifests(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	gotManifests, err := mr.service.GetManifests(ctx)
+func (mr ManifestRouter) GetManifests(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	gotManifests, err := mr.service.GetManifests(ctx, model.GetManifestsRequest{PageRequest: page})
 
 	if err != nil {
 		errMsg := "could not get manifests"
@@ -178,13 +200,14 @@ func (mr ManifestRouter) GetManifests(ctx context.Context, w http.ResponseWriter
 	manifests := make([]GetManifestResponse, 0, len(gotManifests.Manifests))
 	for _, m := range gotManifests.Manifests {
 		manifests = append(manifests, GetManifestResponse{
-			ID:          m.Manifest.ID,
-			Manifest:    m.Manifest,
-			ManifestJWT: m.ManifestJWT,
+			ID:                 m.Manifest.ID,
+			Manifest:           m.Manifest,
+			ManifestJWT:        m.ManifestJWT,
+			VerificationPolicy: m.VerificationPolicy,
 		})
 	}
 
-	resp := GetManifestsResponse{Manifests: manifests}
+	resp := GetManifestsResponse{Manifests: manifests, NextPageToken: gotManifests.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
@@ -286,6 +309,9 @@ type SubmitApplicationResponse struct {
 	// this is an any type to union Data Integrity and JWT style VCs
 	Credentials []any         `json:"verifiableCredentials,omitempty"`
 	ResponseJWT keyaccess.JWT `json:"responseJwt,omitempty"`
+	// The reports of the verification of the credentials submitted with the application, listing the checks run on
+	// each. An application with a credential which is not verified is denied.
+	Verifications []credential.VerificationReport `json:"verifications,omitempty"`
 }
 
 // SubmitApplication godoc
@@ -331,6 +357,9 @@ func (mr ManifestRouter) SubmitApplication(ctx context.Context, w http.ResponseW
 type GetApplicationResponse struct {
 	ID          string                            `json:"id"`
 	Application manifestsdk.CredentialApplication `json:"application"`
+	// The reports of the verification of the credentials submitted with the application, listing the checks run on
+	// each.
+	Verifications []credential.VerificationReport `json:"verifications,omitempty"`
 }
 
 // GetApplication godoc
@@ -360,37 +389,67 @@ func (mr ManifestRouter) GetApplication(ctx context.Context, w http.ResponseWrit
 	}
 
 	resp := GetApplicationResponse{
-		ID:          gotApplication.Application.ID,
-		Application: gotApplication.Application,
+		ID:            gotApplication.Application.ID,
+		Application:   gotApplication.Application,
+		Verifications: gotApplication.Verifications,
 	}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
 type GetApplicationsResponse struct {
 	Applications []manifestsdk.CredentialApplication `json:"applications"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetApplications godoc
 //
 // @Summary     Get applications
-// @Description Gets all the existing applications.
+// @Description Gets all the existing applications. At most one of the manifestId and applicantDid query parameters may
+// @Description be given to get only the applications for a manifest, or made by an applicant.
 // @Tags        ApplicationAPI
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} GetApplicationsResponse
-// @Failure     500 {string} string "Internal server error"
+// @Param       manifestId   query    string false "The manifest id value to filter by"
+// @Param       applicantDid query    string false "The applicant DID value to filter by"
+// @Param       pageSize     query    int    false "Maximum number of applications to return. All are returned when unset."
+// @Param       pageToken    query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200          {object} GetApplicationsResponse
+// @Failure     400          {string} string "Bad request"
+// @Failure     500          {string} string "Internal server error"
 // @Router      /v1/manifests/applications [get]
-func (mr ManifestRouter) GetApplications(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	gotApplications, err := mr.service.GetApplications(ctx)
+func (mr ManifestRouter) GetApplications(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	manifestID := framework.GetQueryValue(r, ManifestIDParam)
+	applicantDID := framework.GetQueryValue(r, ApplicantDIDParam)
+	if manifestID != nil && applicantDID != nil {
+		errMsg := fmt.Sprintf("must use at most one of the following query parameters: %s, %s", ManifestIDParam, ApplicantDIDParam)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	request := model.GetApplicationsRequest{PageRequest: page}
+	if manifestID != nil {
+		request.ManifestID = *manifestID
+	}
+	if applicantDID != nil {
+		request.ApplicantDID = *applicantDID
+	}
+	gotApplications, err := mr.service.GetApplications(ctx, request)
 
 	if err != nil {
 		errMsg := "could not get applications"
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
 	resp := GetApplicationsResponse{
-		Applications: gotApplications.Applications,
+		Applications:  gotApplications.Applications,
+		NextPageToken: gotApplications.NextPageToken,
 	}
 
 	return framework.Respond(ctx, w, resp, http.StatusOK)
@@ -469,6 +528,9 @@ func (mr ManifestRouter) GetResponse(ctx context.Context, w http.ResponseWriter,
 
 type GetResponsesResponse struct {
 	Responses []manifestsdk.CredentialResponse `json:"responses"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetResponses godoc
@@ -478,20 +540,29 @@ type GetResponsesResponse struct {
 // @Tags        ResponseAPI
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} GetResponsesResponse
-// @Failure     500 {string} string "Internal server error"
+// @Param       pageSize  query    int    false "Maximum number of responses to return. All are returned when unset."
+// @Param       pageToken query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetResponsesResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/manifests/responses [get]
-func (mr ManifestRouter) GetResponses(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	gotResponses, err := mr.service.GetResponses(ctx)
+func (mr ManifestRouter) GetResponses(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	gotResponses, err := mr.service.GetResponses(ctx, model.GetResponsesRequest{PageRequest: page})
 
 	if err != nil {
 		errMsg := "could not get responses"
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
 	resp := GetResponsesResponse{
-		Responses: gotResponses.Responses,
+		Responses:     gotResponses.Responses,
+		NextPageToken: gotResponses.NextPageToken,
 	}
 
 	return framework.Respond(ctx, w, resp, http.StatusOK)
@@ -575,8 +646,9 @@ func (mr ManifestRouter) ReviewApplication(ctx context.Context, w http.ResponseW
 			sdkutil.LoggingErrorMsg(err, "failed reviewing application"), http.StatusInternalServerError)
 	}
 	return framework.Respond(ctx, w, SubmitApplicationResponse{
-		Response:    applicationResponse.Response,
-		Credentials: applicationResponse.Credentials,
-		ResponseJWT: applicationResponse.ResponseJWT,
+		Response:      applicationResponse.Response,
+		Credentials:   applicationResponse.Credentials,
+		ResponseJWT:   applicationResponse.ResponseJWT,
+		Verifications: applicationResponse.Verifications,
 	}, http.StatusCreated)
 }
@@ -14,6 +14,7 @@ import (
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
 	manifestsvc "github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
 )
 
 type OperationRouter struct {
@@ -110,6 +111,9 @@ func (r GetOperationsRequest) ToServiceRequest() (operation.GetOperationsRequest
 
 type GetOperationsResponse struct {
 	Operations []Operation `json:"operations"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetOperations godoc
@@ -119,10 +123,12 @@ type GetOperationsResponse struct {
 // @Tags        OperationAPI
 // @Accept      json
 // @Produce     json
-// @Param       request body     GetOperationsRequest  true "request body"
-// @Success     200     {object} GetOperationsResponse "OK"
-// @Failure     400     {string} string                "Bad request"
-// @Failure     500     {string} string                "Internal server error"
+// @Param       request   body     GetOperationsRequest  true  "request body"
+// @Param       pageSize  query    int                   false "Maximum number of operations to return. All are returned when unset."
+// @Param       pageToken query    string                false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetOperationsResponse "OK"
+// @Failure     400       {string} string                "Bad request"
+// @Failure     500       {string} string                "Internal server error"
 // @Router      /v1/operations [get]
 func (o OperationRouter) GetOperations(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var request GetOperationsRequest
@@ -141,13 +147,16 @@ func (o OperationRouter) GetOperations(ctx context.Context, w http.ResponseWrite
 		return framework.NewRequestError(
 			sdkutil.LoggingErrorMsg(err, "invalid get operations request"), http.StatusBadRequest)
 	}
+	if req.PageRequest, err = getPageRequest(r); err != nil {
+		return err
+	}
 
 	ops, err := o.service.GetOperations(ctx, req)
 	if err != nil {
 		logrus.WithError(err).Error("getting operations from service")
-		return framework.NewRequestError(err, http.StatusInternalServerError)
+		return framework.NewRequestError(err, listErrorStatus(err))
 	}
-	resp := GetOperationsResponse{Operations: make([]Operation, 0, len(ops.Operations))}
+	resp := GetOperationsResponse{Operations: make([]Operation, 0, len(ops.Operations)), NextPageToken: ops.NextPageToken}
 	for _, op := range ops.Operations {
 		resp.Operations = append(resp.Operations, routerModel(op))
 	}
@@ -166,10 +175,13 @@ func routerModel(op operation.Operation) Operation {
 		switch r := op.Result.Response.(type) {
 		case manifestsvc.SubmitApplicationResponse:
 			routerOp.Result.Response = SubmitApplicationResponse{
-				Response:    r.Response,
-				Credentials: r.Credentials,
-				ResponseJWT: r.ResponseJWT,
+				Response:      r.Response,
+				Credentials:   r.Credentials,
+				ResponseJWT:   r.ResponseJWT,
+				Verifications: r.Verifications,
 			}
+		case batch.Response:
+			routerOp.Result.Response = batchCreateCredentialsResponse(r.Results)
 		default:
 			routerOp.Result.Response = r
 		}
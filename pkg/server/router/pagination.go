@@ -0,0 +1,44 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	PageSizeParam  = "pageSize"
+	PageTokenParam = "pageToken"
+)
+
+// getPageRequest reads the pagination query parameters of a list request, as described in
+// https://google.aip.dev/158. When no page size is given, all the results are returned.
+func getPageRequest(r *http.Request) (svcframework.PageRequest, error) {
+	var page svcframework.PageRequest
+	if pageSize := framework.GetQueryValue(r, PageSizeParam); pageSize != nil {
+		size, err := strconv.Atoi(*pageSize)
+		if err != nil || size < 0 {
+			errMsg := fmt.Sprintf("invalid %s: must be a non-negative integer", PageSizeParam)
+			return page, framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+		}
+		page.PageSize = size
+	}
+	if pageToken := framework.GetQueryValue(r, PageTokenParam); pageToken != nil {
+		page.PageToken = *pageToken
+	}
+	return page, nil
+}
+
+// listErrorStatus returns the HTTP status code for an error that happened while listing a collection.
+func listErrorStatus(err error) int {
+	if errors.Is(err, storage.ErrInvalidPageToken) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
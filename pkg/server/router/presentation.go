@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
@@ -49,6 +50,9 @@ type CreatePresentationDefinitionRequest struct {
 	// The privateKey associated with the KID will be used to sign an envelope that contains
 	// the created presentation definition.
 	AuthorKID string `json:"authorKid" validate:"required"`
+	// The name of the verification policy the credentials submitted against the definition are verified with. Every
+	// check runs when empty.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 type CreatePresentationDefinitionResponse struct {
@@ -93,6 +97,7 @@ func (pr PresentationRouter) CreateDefinition(ctx context.Context, w http.Respon
 		PresentationDefinition: *def,
 		Author:                 request.Author,
 		AuthorKID:              request.AuthorKID,
+		VerificationPolicy:     request.VerificationPolicy,
 	})
 	if err != nil {
 		logrus.WithError(err).Error(errMsg)
@@ -143,6 +148,9 @@ type GetPresentationDefinitionResponse struct {
 	// Signed envelope that contains the PresentationDefinition created using the privateKey of the author of the
 	// definition.
 	PresentationDefinitionJWT keyaccess.JWT `json:"presentationDefinitionJWT"`
+
+	// The name of the verification policy the credentials submitted against the definition are verified with, if any.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 // GetDefinition godoc
@@ -174,6 +182,7 @@ func (pr PresentationRouter) GetDefinition(ctx context.Context, w http.ResponseW
 	resp := GetPresentationDefinitionResponse{
 		PresentationDefinition:    def.PresentationDefinition,
 		PresentationDefinitionJWT: def.PresentationDefinitionJWT,
+		VerificationPolicy:        def.VerificationPolicy,
 	}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
@@ -183,6 +192,9 @@ type ListDefinitionsRequest struct {
 
 type ListDefinitionsResponse struct {
 	Definitions []*exchange.PresentationDefinition `json:"definitions"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // ListDefinitions godoc
@@ -192,21 +204,29 @@ type ListDefinitionsResponse struct {
 // @Tags        PresentationDefinitionAPI
 // @Accept      json
 // @Produce     json
-// @Param       request body     ListDefinitionsRequest true "request body"
-// @Success     200     {object} ListDefinitionsResponse
-// @Failure     400     {string} string "Bad request"
-// @Failure     500     {string} string "Internal server error"
+// @Param       request   body     ListDefinitionsRequest true  "request body"
+// @Param       pageSize  query    int                    false "Maximum number of definitions to return. All are returned when unset."
+// @Param       pageToken query    string                 false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} ListDefinitionsResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/presentations/definitions [get]
-func (pr PresentationRouter) ListDefinitions(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	svcResponse, err := pr.service.ListDefinitions(ctx)
+func (pr PresentationRouter) ListDefinitions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	svcResponse, err := pr.service.ListDefinitions(ctx, model.ListDefinitionsRequest{PageRequest: page})
 	if err != nil {
 		errMsg := "could not get definitions"
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
 	resp := ListDefinitionsResponse{
-		Definitions: svcResponse.Definitions,
+		Definitions:   svcResponse.Definitions,
+		NextPageToken: svcResponse.NextPageToken,
 	}
 
 	return framework.Respond(ctx, w, resp, http.StatusOK)
@@ -241,6 +261,52 @@ func (pr PresentationRouter) DeleteDefinition(ctx context.Context, w http.Respon
 	return framework.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
+type CreateChallengeResponse struct {
+	// The nonce the key binding JWTs of the SD-JWT credentials of a submission against the definition are to be issued
+	// with. It can only be submitted once.
+	Nonce string `json:"nonce"`
+
+	// The audience the key binding JWTs are to be issued for, which is the author of the definition.
+	Audience string `json:"audience"`
+
+	// When the nonce can no longer be submitted.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateChallenge godoc
+//
+// @Summary     Create Challenge
+// @Description Issues a single-use nonce for the key binding JWTs of the SD-JWT credentials of a submission against a presentation definition.
+// @Tags        PresentationDefinitionAPI
+// @Accept      json
+// @Produce     json
+// @Param       id  path     string true "ID"
+// @Success     201 {object} CreateChallengeResponse
+// @Failure     400 {string} string "Bad request"
+// @Router      /v1/presentations/definitions/{id}/challenges [put]
+func (pr PresentationRouter) CreateChallenge(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	id := framework.GetParam(ctx, IDParam)
+	if id == nil {
+		errMsg := "cannot create a challenge without a presentation definition ID parameter"
+		logrus.Error(errMsg)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	challenge, err := pr.service.CreateChallenge(ctx, model.CreateChallengeRequest{DefinitionID: *id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not create challenge for presentation definition with id: %s", *id)
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	resp := CreateChallengeResponse{
+		Nonce:     challenge.Nonce,
+		Audience:  challenge.Audience,
+		ExpiresAt: challenge.ExpiresAt,
+	}
+	return framework.Respond(ctx, w, resp, http.StatusCreated)
+}
+
 type CreateSubmissionRequest struct {
 	SubmissionJWT keyaccess.JWT `json:"submissionJwt" validate:"required"`
 }
@@ -361,6 +427,9 @@ func (l ListSubmissionRequest) GetFilter() string {
 
 type ListSubmissionResponse struct {
 	Submissions []model.Submission `json:"submissions"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // ListSubmissions godoc
@@ -370,10 +439,12 @@ type ListSubmissionResponse struct {
 // @Tags        PresentationSubmissionAPI
 // @Accept      json
 // @Produce     json
-// @Param       request body     ListSubmissionRequest true "request body"
-// @Success     200     {object} ListSubmissionResponse
-// @Failure     400     {string} string "Bad request"
-// @Failure     500     {string} string "Internal server error"
+// @Param       request   body     ListSubmissionRequest true  "request body"
+// @Param       pageSize  query    int                   false "Maximum number of submissions to return. All are returned when unset."
+// @Param       pageToken query    string                false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} ListSubmissionResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/presentations/submissions [get]
 func (pr PresentationRouter) ListSubmissions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var request ListSubmissionRequest
@@ -381,6 +452,10 @@ func (pr PresentationRouter) ListSubmissions(ctx context.Context, w http.Respons
 		return framework.NewRequestError(
 			sdkutil.LoggingErrorMsg(err, "invalid list submissions request"), http.StatusBadRequest)
 	}
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
 
 	const StatusIdentifier = "status"
 	declarations, err := filtering.NewDeclarations(
@@ -407,12 +482,12 @@ func (pr PresentationRouter) ListSubmissions(ctx context.Context, w http.Respons
 		return framework.NewRequestError(
 			sdkutil.LoggingErrorMsg(err, "invalid filter"), http.StatusBadRequest)
 	}
-	resp, err := pr.service.ListSubmissions(ctx, model.ListSubmissionRequest{Filter: filter})
+	resp, err := pr.service.ListSubmissions(ctx, model.ListSubmissionRequest{Filter: filter, PageRequest: page})
 	if err != nil {
 		return framework.NewRequestError(
-			sdkutil.LoggingErrorMsg(err, "failed listing submissions"), http.StatusInternalServerError)
+			sdkutil.LoggingErrorMsg(err, "failed listing submissions"), listErrorStatus(err))
 	}
-	return framework.Respond(ctx, w, ListSubmissionResponse{Submissions: resp.Submissions}, http.StatusOK)
+	return framework.Respond(ctx, w, ListSubmissionResponse{Submissions: resp.Submissions, NextPageToken: resp.NextPageToken}, http.StatusOK)
 }
 
 type ReviewSubmissionRequest struct {
@@ -59,7 +59,8 @@ func TestPresentationDefinitionService(t *testing.T) {
 	ka, err := keyaccess.NewJWKKeyAccessVerifier(authorDID.DID.ID, authorDID.DID.ID, privKey.(Public).Public())
 	require.NoError(t, err)
 
-	service, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, s, didService.GetResolver(), schemaService, keyStoreService)
+	credentialService := testCredentialService(t, s, keyStoreService, didService, schemaService)
+	service, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, s, didService.GetResolver(), schemaService, keyStoreService, credentialService, credentialService)
 	require.NoError(t, err)
 
 	t.Run("Create returns the created definition", func(t *testing.T) {
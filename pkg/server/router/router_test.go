@@ -1,7 +1,6 @@
 package router
 
 import (
-	"os"
 	"testing"
 
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
@@ -36,15 +35,10 @@ func (s *testService) Config() config.ServicesConfig {
 }
 
 func setupTestDB(t *testing.T) storage.ServiceStorage {
-	file, err := os.CreateTemp("", "bolt")
-	require.NoError(t, err)
-	name := file.Name()
-	file.Close()
-	s, err := storage.NewStorage(storage.Bolt, name)
+	s, err := storage.NewStorage(storage.Memory, nil)
 	require.NoError(t, err)
 	t.Cleanup(func() {
 		_ = s.Close()
-		_ = os.Remove(s.URI())
 	})
 	return s
 }
@@ -126,6 +126,9 @@ func (sr SchemaRouter) GetSchema(ctx context.Context, w http.ResponseWriter, _ *
 
 type GetSchemasResponse struct {
 	Schemas []GetSchemaResponse `json:"schemas,omitempty"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetSchemas godoc
@@ -135,15 +138,23 @@ type GetSchemasResponse struct {
 // @Tags        SchemaAPI
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} GetSchemasResponse
-// @Failure     500 {string} string "Internal server error"
+// @Param       pageSize  query    int    false "Maximum number of schemas to return. All are returned when unset."
+// @Param       pageToken query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetSchemasResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/schemas [get]
-func (sr SchemaRouter) GetSchemas(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	gotSchemas, err := sr.service.GetSchemas(ctx)
+func (sr SchemaRouter) GetSchemas(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	gotSchemas, err := sr.service.GetSchemas(ctx, schema.GetSchemasRequest{PageRequest: page})
 	if err != nil {
 		errMsg := "could not get schemas"
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
 	schemas := make([]GetSchemaResponse, 0, len(gotSchemas.Schemas))
@@ -151,7 +162,7 @@ func (sr SchemaRouter) GetSchemas(ctx context.Context, w http.ResponseWriter, _
 		schemas = append(schemas, GetSchemaResponse{Schema: s.Schema})
 	}
 
-	resp := GetSchemasResponse{Schemas: schemas}
+	resp := GetSchemasResponse{Schemas: schemas, NextPageToken: gotSchemas.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
@@ -188,7 +199,7 @@ func (sr SchemaRouter) VerifySchema(ctx context.Context, w http.ResponseWriter,
 		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
 	}
 
-	verificationResult, err := sr.service.VerifySchema(context.Background(), schema.VerifySchemaRequest{SchemaJWT: request.SchemaJWT})
+	verificationResult, err := sr.service.VerifySchema(ctx, schema.VerifySchemaRequest{SchemaJWT: request.SchemaJWT})
 	if err != nil {
 		errMsg := "could not verify schema"
 		logrus.WithError(err).Error(errMsg)
@@ -46,7 +46,7 @@ func TestSchemaRouter(t *testing.T) {
 		assert.Equal(tt, framework.StatusReady, schemaService.Status().Status)
 
 		// get all schemas (none)
-		gotSchemas, err := schemaService.GetSchemas(context.Background())
+		gotSchemas, err := schemaService.GetSchemas(context.Background(), schema.GetSchemasRequest{})
 		assert.NoError(tt, err)
 		assert.Empty(tt, gotSchemas.Schemas)
 
@@ -80,7 +80,7 @@ func TestSchemaRouter(t *testing.T) {
 		assert.EqualValues(tt, createdSchema.Schema, gotSchema.Schema)
 
 		// get all schemas, expect one
-		gotSchemas, err = schemaService.GetSchemas(context.Background())
+		gotSchemas, err = schemaService.GetSchemas(context.Background(), schema.GetSchemasRequest{})
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, gotSchemas.Schemas)
 		assert.Len(tt, gotSchemas.Schemas, 1)
@@ -94,7 +94,7 @@ func TestSchemaRouter(t *testing.T) {
 		assert.Equal(tt, "simple schema 2", createdSchema.Schema.Name)
 
 		// get all schemas, expect two
-		gotSchemas, err = schemaService.GetSchemas(context.Background())
+		gotSchemas, err = schemaService.GetSchemas(context.Background(), schema.GetSchemasRequest{})
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, gotSchemas.Schemas)
 		assert.Len(tt, gotSchemas.Schemas, 2)
@@ -107,7 +107,7 @@ func TestSchemaRouter(t *testing.T) {
 		assert.NoError(tt, err)
 
 		// get all schemas, expect one
-		gotSchemas, err = schemaService.GetSchemas(context.Background())
+		gotSchemas, err = schemaService.GetSchemas(context.Background(), schema.GetSchemasRequest{})
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, gotSchemas.Schemas)
 		assert.Len(tt, gotSchemas.Schemas, 1)
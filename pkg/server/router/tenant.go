@@ -0,0 +1,277 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+)
+
+type TenantRouter struct {
+	service *tenant.Service
+}
+
+func NewTenantRouter(s svcframework.Service) (*TenantRouter, error) {
+	if s == nil {
+		return nil, errors.New("service cannot be nil")
+	}
+	tenantService, ok := s.(*tenant.Service)
+	if !ok {
+		return nil, fmt.Errorf("could not create tenant router with service type: %s", s.Type())
+	}
+	return &TenantRouter{service: tenantService}, nil
+}
+
+// TenantConfig overrides the configuration of the services for the requests made on behalf of a tenant. Empty values
+// keep the configured value.
+type TenantConfig struct {
+	// Endpoint at which the service is reachable by the holders and verifiers of the tenant.
+	ServiceEndpoint string `json:"serviceEndpoint,omitempty"`
+	// How long credential applications are kept, as a duration such as "720h".
+	ApplicationTTL string `json:"applicationTtl,omitempty"`
+	// How long presentation submissions are kept, as a duration such as "168h".
+	SubmissionTTL string `json:"submissionTtl,omitempty"`
+}
+
+func (c TenantConfig) toServiceConfig() (*tenant.Config, error) {
+	config := tenant.Config{ServiceEndpoint: c.ServiceEndpoint}
+	var err error
+	if c.ApplicationTTL != "" {
+		if config.ApplicationTTL, err = time.ParseDuration(c.ApplicationTTL); err != nil {
+			return nil, errors.Wrap(err, "parsing applicationTtl")
+		}
+	}
+	if c.SubmissionTTL != "" {
+		if config.SubmissionTTL, err = time.ParseDuration(c.SubmissionTTL); err != nil {
+			return nil, errors.Wrap(err, "parsing submissionTtl")
+		}
+	}
+	return &config, nil
+}
+
+func toTenantConfig(c tenant.Config) TenantConfig {
+	config := TenantConfig{ServiceEndpoint: c.ServiceEndpoint}
+	if c.ApplicationTTL != 0 {
+		config.ApplicationTTL = c.ApplicationTTL.String()
+	}
+	if c.SubmissionTTL != 0 {
+		config.SubmissionTTL = c.SubmissionTTL.String()
+	}
+	return config
+}
+
+type Tenant struct {
+	// Made of 1 to 32 lowercase letters or digits. Requests are made on behalf of the tenant by passing it in the
+	// X-Tenant-ID header, along with its API key in the X-Tenant-API-Key header.
+	ID        string       `json:"id"`
+	Config    TenantConfig `json:"config"`
+	CreatedAt string       `json:"createdAt"`
+}
+
+func toTenant(t tenant.Tenant) Tenant {
+	return Tenant{ID: t.ID, Config: toTenantConfig(t.Config), CreatedAt: t.CreatedAt}
+}
+
+type CreateTenantRequest struct {
+	// Made of 1 to 32 lowercase letters or digits.
+	ID     string       `json:"id" validate:"required"`
+	Config TenantConfig `json:"config"`
+}
+
+type CreateTenantResponse struct {
+	Tenant Tenant `json:"tenant"`
+	// The API key requests made on behalf of the tenant are authenticated with, in the X-Tenant-API-Key header. It is
+	// not stored, so cannot be retrieved again, but can be rotated.
+	APIKey string `json:"apiKey"`
+}
+
+// CreateTenant godoc
+//
+// @Summary     Create Tenant
+// @Description Create a tenant, whose keys, data and webhooks are isolated from those of every other tenant. Tenants
+// @Description can only be managed by requests authenticated with the admin API key.
+// @Tags        TenantAPI
+// @Accept      json
+// @Produce     json
+// @Param       request         body     CreateTenantRequest true "request body"
+// @Param       X-Admin-API-Key header   string true "Admin API key"
+// @Success     201             {object} CreateTenantResponse
+// @Failure     400             {string} string "Bad request"
+// @Failure     401             {string} string "Unauthorized"
+// @Failure     500             {string} string "Internal server error"
+// @Router      /v1/tenants [put]
+func (tr TenantRouter) CreateTenant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request CreateTenantRequest
+	invalidCreateTenantRequest := "invalid create tenant request"
+	if err := framework.Decode(r, &request); err != nil {
+		logrus.WithError(err).Error(invalidCreateTenantRequest)
+		return framework.NewRequestError(errors.Wrap(err, invalidCreateTenantRequest), http.StatusBadRequest)
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		logrus.WithError(err).Error(invalidCreateTenantRequest)
+		return framework.NewRequestError(errors.Wrap(err, invalidCreateTenantRequest), http.StatusBadRequest)
+	}
+
+	config, err := request.Config.toServiceConfig()
+	if err != nil {
+		logrus.WithError(err).Error(invalidCreateTenantRequest)
+		return framework.NewRequestError(errors.Wrap(err, invalidCreateTenantRequest), http.StatusBadRequest)
+	}
+
+	createTenantResponse, err := tr.service.CreateTenant(ctx, tenant.CreateTenantRequest{ID: request.ID, Config: *config})
+	if err != nil {
+		errMsg := "could not create tenant"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	resp := CreateTenantResponse{Tenant: toTenant(createTenantResponse.Tenant), APIKey: createTenantResponse.APIKey}
+	return framework.Respond(ctx, w, resp, http.StatusCreated)
+}
+
+type GetTenantResponse struct {
+	Tenant Tenant `json:"tenant"`
+}
+
+// GetTenant godoc
+//
+// @Summary     Get Tenant
+// @Description Get a tenant by its ID
+// @Tags        TenantAPI
+// @Accept      json
+// @Produce     json
+// @Param       id              path     string true "ID"
+// @Param       X-Admin-API-Key header   string true "Admin API key"
+// @Success     200             {object} GetTenantResponse
+// @Failure     400             {string} string "Bad request"
+// @Failure     401             {string} string "Unauthorized"
+// @Failure     404             {string} string "Not found"
+// @Router      /v1/tenants/{id} [get]
+func (tr TenantRouter) GetTenant(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	id := framework.GetParam(ctx, IDParam)
+	if id == nil {
+		errMsg := "cannot get tenant without ID parameter"
+		logrus.Error(errMsg)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	gotTenant, err := tr.service.GetTenant(ctx, tenant.GetTenantRequest{ID: *id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not get tenant with id: %s", *id)
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+	if gotTenant == nil {
+		return framework.NewRequestErrorMsg(fmt.Sprintf("tenant with id<%s> does not exist", *id), http.StatusNotFound)
+	}
+
+	resp := GetTenantResponse{Tenant: toTenant(*gotTenant)}
+	return framework.Respond(ctx, w, resp, http.StatusOK)
+}
+
+type GetTenantsResponse struct {
+	Tenants []Tenant `json:"tenants,omitempty"`
+}
+
+// GetTenants godoc
+//
+// @Summary     Get Tenants
+// @Description Get all the tenants
+// @Tags        TenantAPI
+// @Accept      json
+// @Produce     json
+// @Param       X-Admin-API-Key header   string true "Admin API key"
+// @Success     200             {object} GetTenantsResponse
+// @Failure     400             {string} string "Bad request"
+// @Failure     401             {string} string "Unauthorized"
+// @Router      /v1/tenants [get]
+func (tr TenantRouter) GetTenants(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	gotTenants, err := tr.service.GetTenants(ctx)
+	if err != nil {
+		errMsg := "could not get tenants"
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	tenants := make([]Tenant, 0, len(gotTenants.Tenants))
+	for _, t := range gotTenants.Tenants {
+		tenants = append(tenants, toTenant(t))
+	}
+	return framework.Respond(ctx, w, GetTenantsResponse{Tenants: tenants}, http.StatusOK)
+}
+
+// DeleteTenant godoc
+//
+// @Summary     Delete Tenant
+// @Description Delete a tenant by its ID, along with all of its keys, data and webhooks
+// @Tags        TenantAPI
+// @Accept      json
+// @Produce     json
+// @Param       id              path     string true "ID"
+// @Param       X-Admin-API-Key header   string true "Admin API key"
+// @Success     204             {string} string "No Content"
+// @Failure     400             {string} string "Bad request"
+// @Failure     401             {string} string "Unauthorized"
+// @Router      /v1/tenants/{id} [delete]
+func (tr TenantRouter) DeleteTenant(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	id := framework.GetParam(ctx, IDParam)
+	if id == nil {
+		errMsg := "cannot delete tenant without ID parameter"
+		logrus.Error(errMsg)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	if err := tr.service.DeleteTenant(ctx, tenant.DeleteTenantRequest{ID: *id}); err != nil {
+		errMsg := fmt.Sprintf("could not delete tenant with id: %s", *id)
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	return framework.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+type RotateTenantAPIKeyResponse struct {
+	// The new API key of the tenant. It is not stored, so cannot be retrieved again.
+	APIKey string `json:"apiKey"`
+}
+
+// RotateTenantAPIKey godoc
+//
+// @Summary     Rotate Tenant API Key
+// @Description Replace the API key of a tenant with a new one, after which requests made on behalf of the tenant with
+// @Description the previous key are rejected. Tenants created before tenants had API keys need theirs rotated before
+// @Description requests can be made on their behalf.
+// @Tags        TenantAPI
+// @Accept      json
+// @Produce     json
+// @Param       id              path     string true "ID"
+// @Param       X-Admin-API-Key header   string true "Admin API key"
+// @Success     200             {object} RotateTenantAPIKeyResponse
+// @Failure     400             {string} string "Bad request"
+// @Failure     401             {string} string "Unauthorized"
+// @Router      /v1/tenants/{id}/api-key [put]
+func (tr TenantRouter) RotateTenantAPIKey(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	id := framework.GetParam(ctx, IDParam)
+	if id == nil {
+		errMsg := "cannot rotate tenant api key without ID parameter"
+		logrus.Error(errMsg)
+		return framework.NewRequestErrorMsg(errMsg, http.StatusBadRequest)
+	}
+
+	rotateResponse, err := tr.service.RotateTenantAPIKey(ctx, tenant.RotateTenantAPIKeyRequest{ID: *id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not rotate api key of tenant with id: %s", *id)
+		logrus.WithError(err).Error(errMsg)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusBadRequest)
+	}
+
+	return framework.Respond(ctx, w, RotateTenantAPIKeyResponse{APIKey: rotateResponse.APIKey}, http.StatusOK)
+}
@@ -67,7 +67,7 @@ func testCredentialService(t *testing.T, db storage.ServiceStorage, keyStore *ke
 func testManifestService(t *testing.T, db storage.ServiceStorage, keyStore *keystore.Service, did *did.Service, credential *credential.Service) *manifest.Service {
 	serviceConfig := config.ManifestServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "manifest"}}
 	// create a manifest service
-	manifestService, err := manifest.NewManifestService(serviceConfig, db, keyStore, did.GetResolver(), credential)
+	manifestService, err := manifest.NewManifestService(serviceConfig, db, db, keyStore, did.GetResolver(), credential)
 	require.NoError(t, err)
 	require.NotEmpty(t, manifestService)
 	return manifestService
@@ -130,6 +130,9 @@ func (wr WebhookRouter) GetWebhook(ctx context.Context, w http.ResponseWriter, _
 
 type GetWebhooksResponse struct {
 	Webhooks []GetWebhookResponse `json:"webhooks,omitempty"`
+
+	// Pass this token as the pageToken of the next request to get the next page. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // GetWebhooks godoc
@@ -139,15 +142,23 @@ type GetWebhooksResponse struct {
 // @Tags        WebhookAPI
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} GetWebhooksResponse
-// @Failure     500 {string} string "Internal server error"
+// @Param       pageSize  query    int    false "Maximum number of webhooks to return. All are returned when unset."
+// @Param       pageToken query    string false "Token returned by a previous call, used to get the next page"
+// @Success     200       {object} GetWebhooksResponse
+// @Failure     400       {string} string "Bad request"
+// @Failure     500       {string} string "Internal server error"
 // @Router      /v1/webhooks [get]
-func (wr WebhookRouter) GetWebhooks(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
-	gotWebhooks, err := wr.service.GetWebhooks(ctx)
+func (wr WebhookRouter) GetWebhooks(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := getPageRequest(r)
+	if err != nil {
+		return err
+	}
+
+	gotWebhooks, err := wr.service.GetWebhooks(ctx, webhook.GetWebhooksRequest{PageRequest: page})
 	if err != nil {
 		errMsg := "could not get webhooks"
 		logrus.WithError(err).Error(errMsg)
-		return framework.NewRequestError(errors.Wrap(err, errMsg), http.StatusInternalServerError)
+		return framework.NewRequestError(errors.Wrap(err, errMsg), listErrorStatus(err))
 	}
 
 	webhooks := make([]GetWebhookResponse, 0, len(gotWebhooks.Webhooks))
@@ -155,7 +166,7 @@ func (wr WebhookRouter) GetWebhooks(ctx context.Context, w http.ResponseWriter,
 		webhooks = append(webhooks, GetWebhookResponse{Webhook: w})
 	}
 
-	resp := GetWebhooksResponse{Webhooks: webhooks}
+	resp := GetWebhooksResponse{Webhooks: webhooks, NextPageToken: gotWebhooks.NextPageToken}
 	return framework.Respond(ctx, w, resp, http.StatusOK)
 }
 
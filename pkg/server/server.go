@@ -17,7 +17,7 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
 	"github.com/tbd54566975/ssi-service/pkg/service"
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
-	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
 )
 
 const (
@@ -39,7 +39,12 @@ const (
 	ResponsesPrefix        = "/responses"
 	KeyStorePrefix         = "/keys"
 	VerificationPath       = "/verification"
+	PoliciesPath           = "/policies"
+	BatchPath              = "/batch"
+	RefreshPath            = "/refresh"
+	APIKeyPath             = "/api-key"
 	WebhookPrefix          = "/webhooks"
+	TenantsPrefix          = "/tenants"
 )
 
 // SSIServer exposes all dependencies needed to run a http server and all its services
@@ -70,8 +75,12 @@ func NewSSIServer(shutdown chan os.Signal, config config.SSIServiceConfig) (*SSI
 	// get all instantiated services
 	services := ssi.GetServices()
 
-	// get webhook service
-	webhookService := ssi.GetService(svcframework.Webhook).(*webhook.Service)
+	// scope every request to its tenant, which is resolved with the tenant service
+	tenantService, ok := ssi.GetService(svcframework.Tenant).(*tenant.Service)
+	if !ok {
+		return nil, fmt.Errorf("could not get service: %s", svcframework.Tenant)
+	}
+	httpServer.AddMiddleware(middleware.Tenant(tenantService))
 
 	// service-level routers
 	httpServer.Handle(http.MethodGet, HealthPrefix, router.Health)
@@ -87,7 +96,7 @@ func NewSSIServer(shutdown chan os.Signal, config config.SSIServiceConfig) (*SSI
 	// start all services and their routers
 	logrus.Infof("Starting [%d] service routers...\n", len(services))
 	for _, s := range services {
-		if err := server.instantiateRouter(s, webhookService); err != nil {
+		if err := server.instantiateRouter(s); err != nil {
 			return nil, sdkutil.LoggingErrorMsgf(err, "unable to instantiate service router<%s>", s.Type())
 		}
 		logrus.Infof("Service router<%s> started successfully", s.Type())
@@ -98,15 +107,15 @@ func NewSSIServer(shutdown chan os.Signal, config config.SSIServiceConfig) (*SSI
 
 // instantiateRouter registers the HTTP router for a service with the HTTP server
 // NOTE: all service API router must be registered here
-func (s *SSIServer) instantiateRouter(service svcframework.Service, webhookService *webhook.Service) error {
+func (s *SSIServer) instantiateRouter(service svcframework.Service) error {
 	serviceType := service.Type()
 	switch serviceType {
 	case svcframework.DID:
-		return s.DecentralizedIdentityAPI(service, webhookService)
+		return s.DecentralizedIdentityAPI(service)
 	case svcframework.Schema:
-		return s.SchemaAPI(service, webhookService)
+		return s.SchemaAPI(service)
 	case svcframework.Credential:
-		return s.CredentialAPI(service, webhookService)
+		return s.CredentialAPI(service)
 	case svcframework.KeyStore:
 		return s.KeyStoreAPI(service)
 	case svcframework.Manifest:
@@ -119,13 +128,15 @@ func (s *SSIServer) instantiateRouter(service svcframework.Service, webhookServi
 		return s.IssuanceAPI(service)
 	case svcframework.Webhook:
 		return s.WebhookAPI(service)
+	case svcframework.Tenant:
+		return s.TenantAPI(service)
 	default:
 		return fmt.Errorf("could not instantiate API for service: %s", serviceType)
 	}
 }
 
 // DecentralizedIdentityAPI registers all HTTP router for the DID Service
-func (s *SSIServer) DecentralizedIdentityAPI(service svcframework.Service, webhookService *webhook.Service) (err error) {
+func (s *SSIServer) DecentralizedIdentityAPI(service svcframework.Service) (err error) {
 	didRouter, err := router.NewDIDRouter(service)
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, "creating DID router")
@@ -134,7 +145,7 @@ func (s *SSIServer) DecentralizedIdentityAPI(service svcframework.Service, webho
 	handlerPath := V1Prefix + DIDsPrefix
 
 	s.Handle(http.MethodGet, handlerPath, didRouter.GetDIDMethods)
-	s.Handle(http.MethodPut, path.Join(handlerPath, "/:method"), didRouter.CreateDIDByMethod, middleware.Webhook(webhookService, webhook.DID, webhook.Create))
+	s.Handle(http.MethodPut, path.Join(handlerPath, "/:method"), didRouter.CreateDIDByMethod)
 	s.Handle(http.MethodGet, path.Join(handlerPath, "/:method"), didRouter.GetDIDsByMethod)
 	s.Handle(http.MethodGet, path.Join(handlerPath, "/:method/:id"), didRouter.GetDIDByMethod)
 	s.Handle(http.MethodDelete, path.Join(handlerPath, "/:method/:id"), didRouter.SoftDeleteDIDByMethod)
@@ -144,7 +155,7 @@ func (s *SSIServer) DecentralizedIdentityAPI(service svcframework.Service, webho
 }
 
 // SchemaAPI registers all HTTP router for the SchemaID Service
-func (s *SSIServer) SchemaAPI(service svcframework.Service, webhookService *webhook.Service) (err error) {
+func (s *SSIServer) SchemaAPI(service svcframework.Service) (err error) {
 	schemaRouter, err := router.NewSchemaRouter(service)
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, "creating schema router")
@@ -152,15 +163,15 @@ func (s *SSIServer) SchemaAPI(service svcframework.Service, webhookService *webh
 
 	handlerPath := V1Prefix + SchemasPrefix
 
-	s.Handle(http.MethodPut, handlerPath, schemaRouter.CreateSchema, middleware.Webhook(webhookService, webhook.Schema, webhook.Create))
+	s.Handle(http.MethodPut, handlerPath, schemaRouter.CreateSchema)
 	s.Handle(http.MethodGet, path.Join(handlerPath, "/:id"), schemaRouter.GetSchema)
 	s.Handle(http.MethodGet, handlerPath, schemaRouter.GetSchemas)
 	s.Handle(http.MethodPut, path.Join(handlerPath, VerificationPath), schemaRouter.VerifySchema)
-	s.Handle(http.MethodDelete, path.Join(handlerPath, "/:id"), schemaRouter.DeleteSchema, middleware.Webhook(webhookService, webhook.Schema, webhook.Delete))
+	s.Handle(http.MethodDelete, path.Join(handlerPath, "/:id"), schemaRouter.DeleteSchema)
 	return
 }
 
-func (s *SSIServer) CredentialAPI(service svcframework.Service, webhookService *webhook.Service) (err error) {
+func (s *SSIServer) CredentialAPI(service svcframework.Service) (err error) {
 	credRouter, err := router.NewCredentialRouter(service)
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, "creating credential router")
@@ -168,17 +179,28 @@ func (s *SSIServer) CredentialAPI(service svcframework.Service, webhookService *
 
 	credentialHandlerPath := V1Prefix + CredentialsPrefix
 	statusHandlerPath := V1Prefix + CredentialsPrefix + StatusPrefix
+	policyHandlerPath := V1Prefix + CredentialsPrefix + VerificationPath + PoliciesPath
 
 	// Credentials
-	s.Handle(http.MethodPut, credentialHandlerPath, credRouter.CreateCredential, middleware.Webhook(webhookService, webhook.Credential, webhook.Create))
+	s.Handle(http.MethodPut, credentialHandlerPath, credRouter.CreateCredential)
+	s.Handle(http.MethodPut, path.Join(credentialHandlerPath, BatchPath), credRouter.BatchCreateCredentials)
+	s.Handle(http.MethodPut, path.Join(credentialHandlerPath, RefreshPath), credRouter.RefreshCredential)
 	s.Handle(http.MethodGet, credentialHandlerPath, credRouter.GetCredentials)
 	s.Handle(http.MethodGet, path.Join(credentialHandlerPath, "/:id"), credRouter.GetCredential)
 	s.Handle(http.MethodPut, path.Join(credentialHandlerPath, VerificationPath), credRouter.VerifyCredential)
-	s.Handle(http.MethodDelete, path.Join(credentialHandlerPath, "/:id"), credRouter.DeleteCredential, middleware.Webhook(webhookService, webhook.Credential, webhook.Delete))
+	s.Handle(http.MethodDelete, path.Join(credentialHandlerPath, "/:id"), credRouter.DeleteCredential)
+
+	// Verification Policies
+	s.Handle(http.MethodPut, policyHandlerPath, credRouter.CreateVerificationPolicy)
+	s.Handle(http.MethodGet, policyHandlerPath, credRouter.GetVerificationPolicies)
+	s.Handle(http.MethodGet, path.Join(policyHandlerPath, "/:id"), credRouter.GetVerificationPolicy)
+	s.Handle(http.MethodDelete, path.Join(policyHandlerPath, "/:id"), credRouter.DeleteVerificationPolicy)
 
 	// Credential Status
 	s.Handle(http.MethodGet, path.Join(credentialHandlerPath, "/:id", StatusPrefix), credRouter.GetCredentialStatus)
 	s.Handle(http.MethodPut, path.Join(credentialHandlerPath, "/:id", StatusPrefix), credRouter.UpdateCredentialStatus)
+	s.Handle(http.MethodGet, statusHandlerPath, credRouter.GetCredentialStatusLists)
+	s.Handle(http.MethodPut, path.Join(statusHandlerPath, BatchPath), credRouter.BatchUpdateCredentialStatus)
 	s.Handle(http.MethodGet, path.Join(statusHandlerPath, "/:id"), credRouter.GetCredentialStatusList)
 	return
 }
@@ -195,6 +217,7 @@ func (s *SSIServer) PresentationAPI(service svcframework.Service) (err error) {
 	s.Handle(http.MethodGet, path.Join(handlerPath, "/:id"), pRouter.GetDefinition)
 	s.Handle(http.MethodGet, handlerPath, pRouter.ListDefinitions)
 	s.Handle(http.MethodDelete, path.Join(handlerPath, "/:id"), pRouter.DeleteDefinition)
+	s.Handle(http.MethodPut, path.Join(handlerPath, "/:id", "/challenges"), pRouter.CreateChallenge)
 
 	submissionHandlerPath := V1Prefix + PresentationsPrefix + SubmissionsPrefix
 
@@ -294,3 +317,24 @@ func (s *SSIServer) WebhookAPI(service svcframework.Service) (err error) {
 	s.Handle(http.MethodGet, path.Join(handlerPath, "verbs"), webhookRouter.GetSupportedVerbs)
 	return
 }
+
+func (s *SSIServer) TenantAPI(service svcframework.Service) (err error) {
+	tenantRouter, err := router.NewTenantRouter(service)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "creating tenant router")
+	}
+
+	// tenants are managed by the operator of the service, who is authenticated with the admin api key
+	if s.ServerConfig.AdminAPIKey == "" {
+		logrus.Warn("no admin api key configured, so tenants cannot be managed")
+	}
+	adminOnly := middleware.Admin(s.ServerConfig.AdminAPIKey)
+
+	handlerPath := V1Prefix + TenantsPrefix
+	s.Handle(http.MethodPut, handlerPath, tenantRouter.CreateTenant, adminOnly)
+	s.Handle(http.MethodGet, handlerPath, tenantRouter.GetTenants, adminOnly)
+	s.Handle(http.MethodGet, path.Join(handlerPath, "/:id"), tenantRouter.GetTenant, adminOnly)
+	s.Handle(http.MethodDelete, path.Join(handlerPath, "/:id"), tenantRouter.DeleteTenant, adminOnly)
+	s.Handle(http.MethodPut, path.Join(handlerPath, "/:id", APIKeyPath), tenantRouter.RotateTenantAPIKey, adminOnly)
+	return
+}
@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -12,13 +14,18 @@ import (
 	"github.com/goccy/go-json"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/mr-tron/base58"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
 )
@@ -452,6 +459,99 @@ func TestCredentialAPI(t *testing.T) {
 		assert.Equal(tt, resp.Credential.CredentialSubject[credsdk.VerifiableCredentialIDProperty], getCredsResp.Credentials[0].Credential.CredentialSubject[credsdk.VerifiableCredentialIDProperty])
 	})
 
+	t.Run("Test List Credentials With Filter", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		otherIssuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+
+		// credentials of the issuer expire in 1, 2 and 3 days, the first of which is revoked
+		now := time.Now()
+		var created []router.CreateCredentialResponse
+		for i, issuer := range []*did.CreateDIDResponse{issuerDID, issuerDID, issuerDID, otherIssuerDID} {
+			w := httptest.NewRecorder()
+			createCredRequest := router.CreateCredentialRequest{
+				Issuer:    issuer.DID.ID,
+				IssuerKID: issuer.DID.VerificationMethod[0].ID,
+				Subject:   "did:abc:456",
+				Data:      map[string]any{"firstName": "Jack"},
+				Expiry:    now.Add(time.Duration(i+1) * 24 * time.Hour).Format(time.RFC3339),
+				Revocable: true,
+			}
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, createCredRequest))
+			require.NoError(tt, credRouter.CreateCredential(newRequestContext(), w, req))
+			var resp router.CreateCredentialResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			created = append(created, resp)
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", created[0].Credential.ID), newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: true}))
+		require.NoError(tt, credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": created[0].Credential.ID}), w, req))
+
+		listCredentials := func(query url.Values) ([]string, string, error) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?"+query.Encode(), nil)
+			if err := credRouter.GetCredentials(newRequestContext(), w, req); err != nil {
+				return nil, "", err
+			}
+			var resp router.GetCredentialsResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			ids := make([]string, 0, len(resp.Credentials))
+			for _, cred := range resp.Credentials {
+				ids = append(ids, cred.ID)
+			}
+			return ids, resp.NextPageToken, nil
+		}
+
+		ids, _, err := listCredentials(url.Values{"filter": {fmt.Sprintf(`issuer=%q AND revoked=false`, issuerDID.DID.ID)}, "orderBy": {"expiry desc"}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{created[2].Credential.ID, created[1].Credential.ID}, ids)
+
+		ids, _, err = listCredentials(url.Values{"filter": {fmt.Sprintf(`expiry < %q AND type:"VerifiableCredential"`, now.Add(36*time.Hour).Format(time.RFC3339))}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{created[0].Credential.ID}, ids)
+
+		ids, _, err = listCredentials(url.Values{"filter": {fmt.Sprintf(`issuer=%q`, otherIssuerDID.DID.ID)}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{created[3].Credential.ID}, ids)
+
+		// all the credentials are listed without a filter, page by page
+		ids, nextPageToken, err := listCredentials(url.Values{"orderBy": {"expiry"}, "pageSize": {"3"}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{created[0].Credential.ID, created[1].Credential.ID, created[2].Credential.ID}, ids)
+		assert.NotEmpty(tt, nextPageToken)
+		ids, nextPageToken, err = listCredentials(url.Values{"orderBy": {"expiry"}, "pageSize": {"3"}, "pageToken": {nextPageToken}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{created[3].Credential.ID}, ids)
+		assert.Empty(tt, nextPageToken)
+
+		// bad requests
+		for _, query := range []url.Values{
+			{"filter": {`unknown="value"`}},
+			{"filter": {`issuer=`}},
+			{"orderBy": {"firstName"}},
+			{"issuer": {issuerDID.DID.ID}, "filter": {"revoked=false"}},
+			{"filter": {strings.Repeat("a", router.FilterCharacterLimit+1)}},
+		} {
+			_, _, err = listCredentials(query)
+			assert.ErrorContains(tt, err, "", query.Encode())
+		}
+	})
+
 	t.Run("Test Delete Credential", func(tt *testing.T) {
 		bolt := setupTestDB(tt)
 		require.NotNil(tt, bolt)
@@ -573,17 +673,415 @@ func TestCredentialAPI(t *testing.T) {
 		assert.NotEmpty(tt, verifyResp)
 		assert.True(tt, verifyResp.Verified)
 
+		// each check is reported, in the order it ran
+		outcomes := make(map[credint.Check]credint.CheckOutcome)
+		var checks []credint.Check
+		for _, result := range verifyResp.Checks {
+			outcomes[result.Check] = result.Outcome
+			checks = append(checks, result.Check)
+		}
+		assert.Equal(tt, []credint.Check{credint.CheckDIDResolution, credint.CheckKeyPurpose, credint.CheckSignature,
+			credint.CheckDataModel, credint.CheckExpiry, credint.CheckNotBefore, credint.CheckSchema, credint.CheckStatus}, checks)
+		assert.Equal(tt, credint.CheckPassed, outcomes[credint.CheckSignature])
+		assert.Equal(tt, credint.CheckPassed, outcomes[credint.CheckExpiry])
+		assert.Equal(tt, credint.CheckSkipped, outcomes[credint.CheckSchema])
+		assert.Equal(tt, credint.CheckSkipped, outcomes[credint.CheckStatus])
+
 		// bad credential
 		requestValue = newRequestValue(tt, router.VerifyCredentialRequest{CredentialJWT: keyaccess.JWTPtr("bad")})
 		req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/verification", requestValue)
 		err = credRouter.VerifyCredential(newRequestContext(), w, req)
 		assert.NoError(tt, err)
 
+		verifyResp = router.VerifyCredentialResponse{}
 		err = json.NewDecoder(w.Body).Decode(&verifyResp)
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, verifyResp)
 		assert.False(tt, verifyResp.Verified)
 		assert.Contains(tt, verifyResp.Reason, "could not parse credential from JWT")
+		require.Len(tt, verifyResp.Checks, 1)
+		assert.Equal(tt, credint.CheckDataModel, verifyResp.Checks[0].Check)
+		assert.Equal(tt, credint.CheckFailed, verifyResp.Checks[0].Outcome)
+	})
+
+	t.Run("Test Verifying a Data Integrity Credential", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		createCredRequest := router.CreateCredentialRequest{
+			Issuer:    issuerDID.DID.ID,
+			IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+			Subject:   "did:abc:456",
+			Data: map[string]any{
+				"firstName": "Jack",
+				"lastName":  "Dorsey",
+			},
+			Expiry: time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			Format: "ldp_jwt",
+		}
+
+		// bad format
+		requestValue := newRequestValue(tt, createCredRequest)
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+		w := httptest.NewRecorder()
+		err = credRouter.CreateCredential(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid create credential request")
+
+		// good request
+		createCredRequest.Format = "ldp_vc"
+		requestValue = newRequestValue(tt, createCredRequest)
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+		w = httptest.NewRecorder()
+		err = credRouter.CreateCredential(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var resp router.CreateCredentialResponse
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		assert.NoError(tt, err)
+		assert.Empty(tt, resp.CredentialJWT)
+		require.NotEmpty(tt, resp.Credential)
+		assert.NotEmpty(tt, resp.Credential.Proof)
+		assert.Equal(tt, issuerDID.DID.ID, resp.Credential.Issuer)
+
+		// get it back
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s", resp.Credential.ID), nil)
+		err = credRouter.GetCredential(newRequestContextWithParams(map[string]string{"id": resp.Credential.ID}), w, req)
+		assert.NoError(tt, err)
+
+		var getCredResp router.GetCredentialResponse
+		err = json.NewDecoder(w.Body).Decode(&getCredResp)
+		assert.NoError(tt, err)
+		assert.Empty(tt, getCredResp.CredentialJWT)
+		assert.Equal(tt, resp.Credential.ID, getCredResp.Credential.ID)
+		assert.NotEmpty(tt, getCredResp.Credential.Proof)
+
+		// verify the credential
+		w = httptest.NewRecorder()
+		requestValue = newRequestValue(tt, router.VerifyCredentialRequest{DataIntegrityCredential: getCredResp.Credential})
+		req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/verification", requestValue)
+		err = credRouter.VerifyCredential(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var verifyResp router.VerifyCredentialResponse
+		err = json.NewDecoder(w.Body).Decode(&verifyResp)
+		assert.NoError(tt, err)
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+
+		verifyDataIntegrity := func(cred *credsdk.VerifiableCredential) router.VerifyCredentialResponse {
+			w := httptest.NewRecorder()
+			requestValue := newRequestValue(tt, router.VerifyCredentialRequest{DataIntegrityCredential: cred})
+			req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/verification", requestValue)
+			assert.NoError(tt, credRouter.VerifyCredential(newRequestContext(), w, req))
+			var verifyResp router.VerifyCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&verifyResp))
+			return verifyResp
+		}
+
+		// the proof covers the claims, which are not defined by the credentials context
+		tampered, err := credint.CopyCredential(*getCredResp.Credential)
+		require.NoError(tt, err)
+		tampered.CredentialSubject["firstName"] = "Mallory"
+		verifyResp = verifyDataIntegrity(tampered)
+		assert.False(tt, verifyResp.Verified)
+		assert.Equal(tt, []credint.Check{credint.CheckSignature}, credint.VerificationReport{Checks: verifyResp.Checks}.FailedChecks())
+
+		// claims which the contexts do not define cannot be verified
+		tampered, err = credint.CopyCredential(*getCredResp.Credential)
+		require.NoError(tt, err)
+		tampered.Context = []any{credsdk.VerifiableCredentialsLinkedDataContext}
+		verifyResp = verifyDataIntegrity(tampered)
+		assert.False(tt, verifyResp.Verified)
+		assert.Contains(tt, verifyResp.Reason, "a property its contexts do not define")
+
+		// nor proofs of another suite
+		tampered, err = credint.CopyCredential(*getCredResp.Credential)
+		require.NoError(tt, err)
+		proof, ok := (*tampered.Proof).(map[string]any)
+		require.True(tt, ok)
+		proof["type"] = "BbsBlsSignature2020"
+		verifyResp = verifyDataIntegrity(tampered)
+		assert.False(tt, verifyResp.Verified)
+		assert.Contains(tt, verifyResp.Reason, "unsupported proof type<BbsBlsSignature2020>")
+
+		// a credential issued by another issuer does not verify
+		getCredResp.Credential.Issuer = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+		w = httptest.NewRecorder()
+		requestValue = newRequestValue(tt, router.VerifyCredentialRequest{DataIntegrityCredential: getCredResp.Credential})
+		req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/verification", requestValue)
+		err = credRouter.VerifyCredential(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		err = json.NewDecoder(w.Body).Decode(&verifyResp)
+		assert.NoError(tt, err)
+		assert.False(tt, verifyResp.Verified)
+	})
+
+	t.Run("Test Verifying an Ed25519Signature2020 Credential", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		require.NoError(tt, err)
+
+		createCredRequest := router.CreateCredentialRequest{
+			Issuer:    issuerDID.DID.ID,
+			IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+			Subject:   "did:abc:456",
+			Data: map[string]any{
+				"firstName": "Jack",
+				"lastName":  "Dorsey",
+			},
+			Expiry:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+			Revocable: true,
+			ProofType: "Ed25519Signature2020",
+		}
+		createCredential := func(request router.CreateCredentialRequest) (*router.CreateCredentialResponse, error) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, request))
+			if err := credRouter.CreateCredential(newRequestContext(), w, req); err != nil {
+				return nil, err
+			}
+			var resp router.CreateCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			return &resp, nil
+		}
+		verifyDataIntegrity := func(cred *credsdk.VerifiableCredential) router.VerifyCredentialResponse {
+			w := httptest.NewRecorder()
+			requestValue := newRequestValue(tt, router.VerifyCredentialRequest{DataIntegrityCredential: cred})
+			req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/verification", requestValue)
+			assert.NoError(tt, credRouter.VerifyCredential(newRequestContext(), w, req))
+			var verifyResp router.VerifyCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&verifyResp))
+			return verifyResp
+		}
+
+		// proof types are only supported by the ldp_vc format
+		_, err = createCredential(createCredRequest)
+		assert.ErrorContains(tt, err, "proof types are only supported by the ldp_vc format")
+
+		createCredRequest.Format = "ldp_vc"
+		resp, err := createCredential(createCredRequest)
+		require.NoError(tt, err)
+		require.NotEmpty(tt, resp.Credential)
+		assert.Empty(tt, resp.CredentialJWT)
+		assert.Contains(tt, resp.Credential.Context, keyaccess.Ed25519Signature2020Context)
+		proof, ok := (*resp.Credential.Proof).(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, "Ed25519Signature2020", proof["type"])
+		assert.Equal(tt, issuerDID.DID.VerificationMethod[0].ID, proof["verificationMethod"])
+		assert.NotEmpty(tt, proof["proofValue"])
+		assert.Empty(tt, proof["jws"])
+
+		verifyResp := verifyDataIntegrity(resp.Credential)
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+
+		// the proof covers the claims
+		tampered, err := credint.CopyCredential(*resp.Credential)
+		require.NoError(tt, err)
+		tampered.CredentialSubject["firstName"] = "Mallory"
+		verifyResp = verifyDataIntegrity(tampered)
+		assert.False(tt, verifyResp.Verified)
+		assert.Equal(tt, []credint.Check{credint.CheckSignature}, credint.VerificationReport{Checks: verifyResp.Checks}.FailedChecks())
+
+		// the status list is signed with a proof of the same type, also once updated
+		statusBytes, err := json.Marshal(resp.Credential.CredentialStatus)
+		require.NoError(tt, err)
+		var statusEntry statussdk.StatusList2021Entry
+		require.NoError(tt, json.Unmarshal(statusBytes, &statusEntry))
+		_, statusListID, ok := strings.Cut(statusEntry.StatusListCredential, "/v1/credentials/status/")
+		require.True(tt, ok)
+		getStatusList := func() *credsdk.VerifiableCredential {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, statusEntry.StatusListCredential, nil)
+			assert.NoError(tt, credRouter.GetCredentialStatusList(newRequestContextWithParams(map[string]string{"id": statusListID}), w, req))
+			var statusListResp router.GetCredentialStatusListResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&statusListResp))
+			return statusListResp.Credential
+		}
+		statusList := getStatusList()
+		require.NotEmpty(tt, statusList)
+		assert.Equal(tt, "Ed25519Signature2020", (*statusList.Proof).(map[string]any)["type"])
+		verifyResp = verifyDataIntegrity(statusList)
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+
+		w := httptest.NewRecorder()
+		requestValue := newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: true})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", resp.Credential.ID), requestValue)
+		require.NoError(tt, credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": resp.Credential.ID}), w, req))
+
+		statusList = getStatusList()
+		require.NotEmpty(tt, statusList)
+		assert.Equal(tt, "Ed25519Signature2020", (*statusList.Proof).(map[string]any)["type"])
+		verifyResp = verifyDataIntegrity(statusList)
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+
+		// Ed25519Signature2020 proofs are only signed with Ed25519 keys
+		p256DID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.P256,
+		})
+		require.NoError(tt, err)
+		createCredRequest.Issuer = p256DID.DID.ID
+		createCredRequest.IssuerKID = p256DID.DID.VerificationMethod[0].ID
+		createCredRequest.Revocable = false
+		_, err = createCredential(createCredRequest)
+		assert.ErrorContains(tt, err, "Ed25519Signature2020 proofs can only be signed with Ed25519 keys")
+
+		// while JsonWebSignature2020 proofs are signed with any key
+		createCredRequest.ProofType = "JsonWebSignature2020"
+		resp, err = createCredential(createCredRequest)
+		require.NoError(tt, err)
+		assert.Equal(tt, "JsonWebSignature2020", (*resp.Credential.Proof).(map[string]any)["type"])
+		verifyResp = verifyDataIntegrity(resp.Credential)
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+	})
+
+	t.Run("Test Data Model 2.0 Credential", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		simpleSchema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"firstName": map[string]any{
+					"type": "string",
+				},
+			},
+		}
+		createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Author: "me", Name: "simple schema", Schema: simpleSchema})
+		assert.NoError(tt, err)
+
+		expiry := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+		createCredRequest := router.CreateCredentialRequest{
+			Issuer:           issuerDID.DID.ID,
+			IssuerKID:        issuerDID.DID.VerificationMethod[0].ID,
+			Subject:          "did:abc:456",
+			SchemaID:         createdSchema.ID,
+			Data:             map[string]any{"firstName": "Jack"},
+			Expiry:           expiry,
+			DataModelVersion: "2.0",
+		}
+		requestValue := newRequestValue(tt, createCredRequest)
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+		w := httptest.NewRecorder()
+		err = credRouter.CreateCredential(newRequestContext(), w, req)
+		require.NoError(tt, err)
+
+		var resp router.CreateCredentialResponse
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, resp.CredentialJWT)
+
+		// the jwt has the 2.0 media type, and its claims are the 2.0 credential
+		headers, err := keyaccess.GetJWTHeaders([]byte(resp.CredentialJWT.String()))
+		assert.NoError(tt, err)
+		assert.Equal(tt, keyaccess.VCV2JWTType, headers.Type())
+		assert.Equal(tt, keyaccess.VCV2ContentType, headers.ContentType())
+
+		payload, err := base64.RawURLEncoding.DecodeString(strings.Split(resp.CredentialJWT.String(), ".")[1])
+		assert.NoError(tt, err)
+		var claims map[string]any
+		err = json.Unmarshal(payload, &claims)
+		assert.NoError(tt, err)
+		assert.Equal(tt, credint.V2Context, claims["@context"].([]any)[0])
+		assert.Equal(tt, expiry, claims["validUntil"])
+		assert.NotEmpty(tt, claims["validFrom"])
+		assert.NotContains(tt, claims, "issuanceDate")
+		assert.NotContains(tt, claims, "expirationDate")
+		assert.NotContains(tt, claims, "vc")
+		assert.Equal(tt, credint.JSONSchemaType, claims["credentialSchema"].(map[string]any)["type"])
+
+		// the credential is verified
+		w = httptest.NewRecorder()
+		requestValue = newRequestValue(tt, router.VerifyCredentialRequest{CredentialJWT: resp.CredentialJWT})
+		req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/verification", requestValue)
+		err = credRouter.VerifyCredential(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var verifyResp router.VerifyCredentialResponse
+		err = json.NewDecoder(w.Body).Decode(&verifyResp)
+		assert.NoError(tt, err)
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+
+		// and stored with its validity
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s", resp.Credential.ID), nil)
+		err = credRouter.GetCredential(newRequestContextWithParams(map[string]string{"id": resp.Credential.ID}), w, req)
+		assert.NoError(tt, err)
+
+		var getCredResp router.GetCredentialResponse
+		err = json.NewDecoder(w.Body).Decode(&getCredResp)
+		assert.NoError(tt, err)
+		assert.Equal(tt, expiry, getCredResp.Credential.ExpirationDate)
+
+		// 2.0 credentials are only signed as jwts
+		createCredRequest.Format = "ldp_vc"
+		requestValue = newRequestValue(tt, createCredRequest)
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+		err = credRouter.CreateCredential(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "only supported by the jwt_vc format")
+
+		// issuers may be configured to issue 2.0 credentials by default
+		serviceConfig := config.CredentialServiceConfig{
+			BaseServiceConfig:  &config.BaseServiceConfig{Name: "credential"},
+			DataModelV2Issuers: []string{issuerDID.DID.ID},
+		}
+		credService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		require.NoError(tt, err)
+		serviceRequest := credential.CreateCredentialRequest{
+			Issuer:    issuerDID.DID.ID,
+			IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+			Subject:   "did:abc:456",
+			Data:      map[string]any{"firstName": "Jack"},
+		}
+		created, err := credService.CreateCredential(context.Background(), serviceRequest)
+		require.NoError(tt, err)
+		assert.Equal(tt, credint.DataModelV2, credint.Version(*created.Credential))
+
+		serviceRequest.DataModelVersion = credint.DataModelV1
+		created, err = credService.CreateCredential(context.Background(), serviceRequest)
+		require.NoError(tt, err)
+		assert.Equal(tt, credint.DataModelV1, credint.Version(*created.Credential))
+
+		serviceRequest.DataModelVersion = ""
+		serviceRequest.Format = "ldp_vc"
+		created, err = credService.CreateCredential(context.Background(), serviceRequest)
+		require.NoError(tt, err)
+		assert.Equal(tt, credint.DataModelV1, credint.Version(*created.Credential))
 	})
 
 	t.Run("Test Create Revocable Credential", func(tt *testing.T) {
@@ -902,4 +1400,931 @@ func TestCredentialAPI(t *testing.T) {
 		assert.Empty(tt, credListResp.Credential.CredentialStatus)
 		assert.Equal(tt, credListResp.Credential.ID, credStatusListID)
 	})
+
+	t.Run("Test Batch Create Credentials", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		createCredRequest := func(subject string, revocable bool) router.CreateCredentialRequest {
+			return router.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+				Subject:   subject,
+				Data: map[string]any{
+					"firstName": "Jack",
+					"lastName":  "Dorsey",
+				},
+				Revocable: revocable,
+			}
+		}
+
+		// an empty batch
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(tt, router.BatchCreateCredentialsRequest{}))
+		err = credRouter.BatchCreateCredentials(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid batch create credentials request")
+
+		// a batch with a credential which cannot be created
+		badRequest := createCredRequest("did:abc:789", false)
+		badRequest.IssuerKID = "did:abc:123#key-1"
+		batchRequest := router.BatchCreateCredentialsRequest{
+			Requests: []router.CreateCredentialRequest{
+				createCredRequest("did:abc:123", true),
+				badRequest,
+				createCredRequest("did:abc:456", true),
+				createCredRequest("did:abc:456", false),
+			},
+		}
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchCreateCredentials(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		assert.Equal(tt, http.StatusCreated, w.Code)
+
+		var resp router.BatchCreateCredentialsResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Nil(tt, resp.Operation)
+		require.Len(tt, resp.Results, 4)
+
+		assert.Contains(tt, resp.Results[1].Error, "getting key for signing credential<did:abc:123#key-1>")
+		assert.Nil(tt, resp.Results[1].Credential)
+		assert.Empty(tt, resp.Results[3].Credential.CredentialStatus)
+
+		// the revocable credentials share a status list, with an index each
+		statusOne, ok := resp.Results[0].Credential.CredentialStatus.(map[string]any)
+		require.True(tt, ok)
+		statusTwo, ok := resp.Results[2].Credential.CredentialStatus.(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, statusOne["statusListCredential"], statusTwo["statusListCredential"])
+		assert.NotEqual(tt, statusOne["statusListIndex"], statusTwo["statusListIndex"])
+
+		// the created credentials are stored
+		for _, i := range []int{0, 2, 3} {
+			assert.Empty(tt, resp.Results[i].Error)
+			assert.NotEmpty(tt, resp.Results[i].CredentialJWT)
+			credID := resp.Results[i].Credential.ID
+			req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s", credID), nil)
+			err = credRouter.GetCredential(newRequestContextWithParams(map[string]string{"id": credID}), httptest.NewRecorder(), req)
+			assert.NoError(tt, err)
+		}
+
+		// an atomic batch with a credential which cannot be created creates none
+		batchRequest.Atomic = true
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchCreateCredentials(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "creating credential<1>")
+
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?subject=did:abc:123", nil)
+		w = httptest.NewRecorder()
+		err = credRouter.GetCredentials(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		var getCredsResp router.GetCredentialsResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&getCredsResp))
+		assert.Len(tt, getCredsResp.Credentials, 1)
+	})
+
+	t.Run("Test Batch Create Credentials Operation", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		serviceConfig := config.CredentialServiceConfig{
+			BaseServiceConfig:       &config.BaseServiceConfig{Name: "credential"},
+			BatchOperationThreshold: 2,
+		}
+		credentialService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		require.NoError(tt, err)
+		credRouter, err := router.NewCredentialRouter(credentialService)
+		require.NoError(tt, err)
+		opRouter := setupOperationsRouter(tt, bolt)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		var batchRequest router.BatchCreateCredentialsRequest
+		for i := 0; i < 3; i++ {
+			batchRequest.Requests = append(batchRequest.Requests, router.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+				Subject:   fmt.Sprintf("did:abc:%d", i),
+				Data:      map[string]any{"index": i},
+				Revocable: true,
+			})
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchCreateCredentials(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		assert.Equal(tt, http.StatusAccepted, w.Code)
+
+		var resp router.BatchCreateCredentialsResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Empty(tt, resp.Results)
+		require.NotNil(tt, resp.Operation)
+		assert.Contains(tt, resp.Operation.ID, "credentials/batches/")
+
+		// the batch is created in the background
+		var op router.Operation
+		assert.Eventually(tt, func() bool {
+			req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/operations/%s", resp.Operation.ID), nil)
+			w = httptest.NewRecorder()
+			if err := opRouter.GetOperation(newRequestContextWithParams(map[string]string{"id": resp.Operation.ID}), w, req); err != nil {
+				return false
+			}
+			return json.NewDecoder(w.Body).Decode(&op) == nil && op.Done
+		}, 10*time.Second, 50*time.Millisecond)
+		assert.Empty(tt, op.Result.Error)
+
+		responseBytes, err := json.Marshal(op.Result.Response)
+		assert.NoError(tt, err)
+		var opResp router.BatchCreateCredentialsResponse
+		assert.NoError(tt, json.Unmarshal(responseBytes, &opResp))
+		require.Len(tt, opResp.Results, 3)
+		for i, result := range opResp.Results {
+			assert.Empty(tt, result.Error)
+			assert.NotEmpty(tt, result.CredentialJWT)
+			assert.Equal(tt, fmt.Sprintf("did:abc:%d", i), result.Credential.CredentialSubject.GetID())
+		}
+	})
+
+	t.Run("Test Status List Rollover", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		serviceConfig := config.CredentialServiceConfig{
+			BaseServiceConfig: &config.BaseServiceConfig{Name: "credential"},
+			StatusListSize:    2,
+		}
+		credentialService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		require.NoError(tt, err)
+		credRouter, err := router.NewCredentialRouter(credentialService)
+		require.NoError(tt, err)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		createCredRequest := func(i int) router.CreateCredentialRequest {
+			return router.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+				Subject:   fmt.Sprintf("did:abc:%d", i),
+				Data:      map[string]any{"index": i},
+				Revocable: true,
+			}
+		}
+
+		// the third credential fills the first status list, and the next ones are allocated indexes of new ones
+		var creds []credsdk.VerifiableCredential
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, createCredRequest(i)))
+			err = credRouter.CreateCredential(newRequestContext(), w, req)
+			assert.NoError(tt, err)
+
+			var resp router.CreateCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			creds = append(creds, *resp.Credential)
+		}
+		var batchRequest router.BatchCreateCredentialsRequest
+		for i := 3; i < 6; i++ {
+			batchRequest.Requests = append(batchRequest.Requests, createCredRequest(i))
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchCreateCredentials(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		var batchResp router.BatchCreateCredentialsResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&batchResp))
+		require.Len(tt, batchResp.Results, 3)
+		for _, result := range batchResp.Results {
+			assert.Empty(tt, result.Error)
+			creds = append(creds, *result.Credential)
+		}
+
+		statusListIDs := make(map[string]int)
+		for _, cred := range creds {
+			statusListIDs[cred.CredentialStatus.(map[string]any)["statusListCredential"].(string)]++
+		}
+		assert.Len(tt, statusListIDs, 3)
+		for _, count := range statusListIDs {
+			assert.Equal(tt, 2, count)
+		}
+		firstStatusListID := creds[0].CredentialStatus.(map[string]any)["statusListCredential"].(string)
+		assert.Equal(tt, firstStatusListID, creds[1].CredentialStatus.(map[string]any)["statusListCredential"])
+
+		// every credential records its status list and index
+		for _, cred := range creds {
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", cred.ID), nil)
+			err = credRouter.GetCredentialStatus(newRequestContextWithParams(map[string]string{"id": cred.ID}), w, req)
+			assert.NoError(tt, err)
+
+			var statusResp router.GetCredentialStatusResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&statusResp))
+			assert.Equal(tt, cred.CredentialStatus.(map[string]any)["statusListCredential"], statusResp.StatusListCredentialID)
+			assert.Equal(tt, cred.CredentialStatus.(map[string]any)["statusListIndex"], fmt.Sprint(statusResp.StatusListIndex))
+		}
+
+		// a credential of a full status list can still be revoked
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", creds[0].ID), newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: true}))
+		err = credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": creds[0].ID}), w, req)
+		assert.NoError(tt, err)
+
+		statusListUUID := firstStatusListID[strings.LastIndex(firstStatusListID, "/")+1:]
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, firstStatusListID, nil)
+		err = credRouter.GetCredentialStatusList(newRequestContextWithParams(map[string]string{"id": statusListUUID}), w, req)
+		assert.NoError(tt, err)
+		var statusListResp router.GetCredentialStatusListResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&statusListResp))
+		revoked, err := statussdk.ValidateCredentialInStatusList(creds[0], *statusListResp.Credential)
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+		revoked, err = statussdk.ValidateCredentialInStatusList(creds[1], *statusListResp.Credential)
+		assert.NoError(tt, err)
+		assert.False(tt, revoked)
+
+		// the status lists of the issuer are listed with their utilization
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/status?issuer=%s", issuerDID.DID.ID), nil)
+		err = credRouter.GetCredentialStatusLists(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		var listsResp router.GetCredentialStatusListsResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&listsResp))
+		require.Len(tt, listsResp.StatusLists, 3)
+		var current int
+		for _, statusList := range listsResp.StatusLists {
+			assert.Contains(tt, statusListIDs, statusList.StatusListCredentialID)
+			assert.Equal(tt, issuerDID.DID.ID, statusList.Issuer)
+			assert.Equal(tt, "revocation", statusList.StatusPurpose)
+			assert.Equal(tt, 2, statusList.Size)
+			assert.Equal(tt, 2, statusList.Allocated)
+			if statusList.Current {
+				current++
+			}
+		}
+		assert.Equal(tt, 1, current)
+
+		// the issuer is required
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status", nil)
+		err = credRouter.GetCredentialStatusLists(newRequestContext(), w, req)
+		assert.Error(tt, err)
+	})
+
+	t.Run("Test Incremental and Batch Status Updates", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		var creds []credsdk.VerifiableCredential
+		for i := 0; i < 4; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, router.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+				Subject:   fmt.Sprintf("did:abc:%d", i),
+				Data:      map[string]any{"index": i},
+				Revocable: true,
+			}))
+			err = credRouter.CreateCredential(newRequestContext(), w, req)
+			assert.NoError(tt, err)
+
+			var resp router.CreateCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			creds = append(creds, *resp.Credential)
+		}
+		statusListID := creds[0].CredentialStatus.(map[string]any)["statusListCredential"].(string)
+
+		assertRevoked := func(expected ...bool) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, statusListID, nil)
+			err := credRouter.GetCredentialStatusList(newRequestContextWithParams(map[string]string{"id": statusListID[strings.LastIndex(statusListID, "/")+1:]}), w, req)
+			assert.NoError(tt, err)
+			var statusListResp router.GetCredentialStatusListResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&statusListResp))
+			assert.NotEmpty(tt, statusListResp.CredentialJWT)
+			for i, cred := range creds {
+				revoked, err := statussdk.ValidateCredentialInStatusList(cred, *statusListResp.Credential)
+				assert.NoError(tt, err)
+				assert.Equal(tt, expected[i], revoked, "credential %d", i)
+			}
+		}
+
+		// revoking a credential sets only its bit
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", creds[0].ID), newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: true}))
+		err = credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": creds[0].ID}), w, req)
+		assert.NoError(tt, err)
+		assertRevoked(true, false, false, false)
+
+		// many statuses are updated at once, leaving the other bits untouched
+		batchRequest := router.BatchUpdateCredentialStatusRequest{Requests: []router.BatchUpdateCredentialStatusItem{
+			{ID: creds[1].ID, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true}},
+			{ID: creds[2].ID, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true}},
+		}}
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchUpdateCredentialStatus(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		var batchResp router.BatchUpdateCredentialStatusResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&batchResp))
+		require.Len(tt, batchResp.Results, 2)
+		assert.Equal(tt, creds[1].ID, batchResp.Results[0].ID)
+		assert.True(tt, batchResp.Results[0].Revoked)
+		assert.Equal(tt, creds[2].ID, batchResp.Results[1].ID)
+		assertRevoked(true, true, true, false)
+
+		// un-revoking clears the bit again
+		batchRequest = router.BatchUpdateCredentialStatusRequest{Requests: []router.BatchUpdateCredentialStatusItem{
+			{ID: creds[0].ID},
+			{ID: creds[1].ID, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true}},
+		}}
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchUpdateCredentialStatus(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		assertRevoked(false, true, true, false)
+
+		// a credential may only be updated once per batch
+		batchRequest = router.BatchUpdateCredentialStatusRequest{Requests: []router.BatchUpdateCredentialStatusItem{
+			{ID: creds[3].ID, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true}},
+			{ID: creds[3].ID},
+		}}
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchUpdateCredentialStatus(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is updated more than once")
+
+		// a batch is all or nothing
+		batchRequest = router.BatchUpdateCredentialStatusRequest{Requests: []router.BatchUpdateCredentialStatusItem{
+			{ID: creds[3].ID, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true}},
+			{ID: "missing", UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true}},
+		}}
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status/batch", newRequestValue(tt, batchRequest))
+		err = credRouter.BatchUpdateCredentialStatus(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assertRevoked(false, true, true, false)
+	})
+
+	t.Run("Test Credential Expiry and Temporary Suspension", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credService := testCredentialService(tt, bolt, keyStoreService, didService, schemaService)
+		credRouter, err := router.NewCredentialRouter(credService)
+		require.NoError(tt, err)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		createCredential := func(request router.CreateCredentialRequest) credsdk.VerifiableCredential {
+			request.Issuer = issuerDID.DID.ID
+			request.IssuerKID = issuerDID.DID.VerificationMethod[0].ID
+			request.Subject = "did:abc:456"
+			request.Data = map[string]any{"firstName": "Jack"}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, request))
+			err := credRouter.CreateCredential(newRequestContext(), w, req)
+			require.NoError(tt, err)
+			var resp router.CreateCredentialResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			return *resp.Credential
+		}
+		getStatus := func(id string) router.GetCredentialStatusResponse {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", id), nil)
+			err := credRouter.GetCredentialStatus(newRequestContextWithParams(map[string]string{"id": id}), w, req)
+			require.NoError(tt, err)
+			var resp router.GetCredentialStatusResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			return resp
+		}
+
+		// credentials past their expiration date are marked expired, once
+		expiredCred := createCredential(router.CreateCredentialRequest{Expiry: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)})
+		validCred := createCredential(router.CreateCredentialRequest{Expiry: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)})
+		assert.False(tt, getStatus(expiredCred.ID).Expired)
+
+		expired, err := credService.ExpireCredentials(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, expired)
+		assert.True(tt, getStatus(expiredCred.ID).Expired)
+		assert.False(tt, getStatus(validCred.ID).Expired)
+
+		expired, err = credService.ExpireCredentials(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, 0, expired)
+
+		// temporary suspensions are lifted once they are over
+		suspendableCred := createCredential(router.CreateCredentialRequest{Suspendable: true})
+
+		past := time.Now().Add(-time.Minute)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", suspendableCred.ID), newRequestValue(tt, router.UpdateCredentialStatusRequest{Suspended: true, SuspendedUntil: &past}))
+		err = credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": suspendableCred.ID}), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "must be in the future")
+
+		until := time.Now().Add(time.Second).UTC()
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", suspendableCred.ID), newRequestValue(tt, router.UpdateCredentialStatusRequest{Suspended: true, SuspendedUntil: &until}))
+		err = credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": suspendableCred.ID}), w, req)
+		assert.NoError(tt, err)
+		var updateResp router.UpdateCredentialStatusResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&updateResp))
+		assert.True(tt, updateResp.Suspended)
+		require.NotNil(tt, updateResp.SuspendedUntil)
+		assert.True(tt, until.Equal(*updateResp.SuspendedUntil))
+
+		lifted, err := credService.LiftSuspensions(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, 0, lifted)
+		assert.True(tt, getStatus(suspendableCred.ID).Suspended)
+
+		time.Sleep(time.Until(until))
+		lifted, err = credService.LiftSuspensions(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, lifted)
+		status := getStatus(suspendableCred.ID)
+		assert.False(tt, status.Suspended)
+		assert.Nil(tt, status.SuspendedUntil)
+	})
+
+	t.Run("Test Refresh Credential", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credService := testCredentialService(tt, bolt, keyStoreService, didService, schemaService)
+		credRouter, err := router.NewCredentialRouter(credService)
+		require.NoError(tt, err)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		require.NoError(tt, err)
+		holderDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		require.NoError(tt, err)
+		otherDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		require.NoError(tt, err)
+
+		createCredential := func(request router.CreateCredentialRequest) credsdk.VerifiableCredential {
+			request.Issuer = issuerDID.DID.ID
+			request.IssuerKID = issuerDID.DID.VerificationMethod[0].ID
+			request.Subject = holderDID.DID.ID
+			request.Data = map[string]any{"firstName": "Jack"}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, request))
+			err := credRouter.CreateCredential(newRequestContext(), w, req)
+			require.NoError(tt, err)
+			var resp router.CreateCredentialResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			return *resp.Credential
+		}
+		var refreshServiceID string
+		refreshCredentialFor := func(signerDID *did.CreateDIDResponse, id, audience string) (*router.RefreshCredentialResponse, error) {
+			privKeyBytes, err := base58.Decode(signerDID.PrivateKeyBase58)
+			require.NoError(tt, err)
+			privKey, err := crypto.BytesToPrivKey(privKeyBytes, signerDID.KeyType)
+			require.NoError(tt, err)
+			signer, err := keyaccess.NewJWKKeyAccess(signerDID.DID.ID, signerDID.DID.VerificationMethod[0].ID, privKey)
+			require.NoError(tt, err)
+			requestJWT, err := signer.Sign(map[string]any{credential.RefreshCredentialIDClaim: id, "aud": audience})
+			require.NoError(tt, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/refresh", newRequestValue(tt, router.RefreshCredentialRequest{RequestJWT: *requestJWT}))
+			if err = credRouter.RefreshCredential(newRequestContext(), w, req); err != nil {
+				return nil, err
+			}
+			var resp router.RefreshCredentialResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+			return &resp, nil
+		}
+		refreshCredential := func(signerDID *did.CreateDIDResponse, id string) (*router.RefreshCredentialResponse, error) {
+			return refreshCredentialFor(signerDID, id, refreshServiceID)
+		}
+
+		// refreshable credentials have a refresh service
+		cred := createCredential(router.CreateCredentialRequest{Refreshable: true, Revocable: true, Expiry: time.Now().Add(2 * time.Second).UTC().Format(time.RFC3339)})
+		require.NotNil(tt, cred.RefreshService)
+		assert.True(tt, strings.HasSuffix(cred.RefreshService.ID, "/v1/credentials/refresh"))
+		assert.Equal(tt, credential.RefreshServiceType, cred.RefreshService.Type)
+		refreshServiceID = cred.RefreshService.ID
+
+		// refresh requests must be issued for the refresh service
+		_, err = refreshCredentialFor(holderDID, cred.ID, "https://other-service.com/v1/credentials/refresh")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is not issued for audience")
+
+		// only the subject of the credential may refresh it
+		_, err = refreshCredential(otherDID, cred.ID)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is not the subject of credential")
+
+		// an expired credential is issued again with the same claims, valid for as long
+		oldIssuanceDate, err := time.Parse(time.RFC3339, cred.IssuanceDate)
+		require.NoError(tt, err)
+		oldExpirationDate, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+		require.NoError(tt, err)
+		time.Sleep(time.Until(oldExpirationDate))
+
+		refreshed, err := refreshCredential(holderDID, cred.ID)
+		require.NoError(tt, err)
+		assert.Equal(tt, cred.ID, refreshed.RefreshedFrom)
+		newCred := refreshed.Credential
+		require.NotNil(tt, newCred)
+		assert.NotEqual(tt, cred.ID, newCred.ID)
+		assert.Equal(tt, "Jack", newCred.CredentialSubject["firstName"])
+		assert.Equal(tt, holderDID.DID.ID, newCred.CredentialSubject.GetID())
+		assert.NotNil(tt, newCred.CredentialStatus)
+		assert.NotNil(tt, newCred.RefreshService)
+		issuanceDate, err := time.Parse(time.RFC3339, newCred.IssuanceDate)
+		require.NoError(tt, err)
+		expirationDate, err := time.Parse(time.RFC3339, newCred.ExpirationDate)
+		require.NoError(tt, err)
+		assert.True(tt, expirationDate.After(oldExpirationDate))
+		assert.WithinDuration(tt, issuanceDate.Add(oldExpirationDate.Sub(oldIssuanceDate)), expirationDate, time.Second)
+
+		// a credential is refreshed once, after which the credential issued in its place is refreshed
+		_, err = refreshCredential(holderDID, cred.ID)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "was already refreshed")
+
+		// revoked credentials are not refreshed
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", newCred.ID), newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: true}))
+		err = credRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": newCred.ID}), w, req)
+		require.NoError(tt, err)
+		_, err = refreshCredential(holderDID, newCred.ID)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "is revoked")
+
+		// credentials which are not refreshable have no refresh service
+		notRefreshable := createCredential(router.CreateCredentialRequest{})
+		assert.Nil(tt, notRefreshable.RefreshService)
+		_, err = refreshCredential(holderDID, notRefreshable.ID)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no refresh service")
+	})
+
+	t.Run("Test Verifying a Revoked Credential", func(tt *testing.T) {
+		// the status lists of the issuing service are served over HTTP, for other services to verify its credentials
+		var issuerRouter *router.CredentialRouter
+		statusListServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			_ = issuerRouter.GetCredentialStatusList(newRequestContextWithParams(map[string]string{"id": id}), w, r)
+		}))
+		defer statusListServer.Close()
+
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		serviceConfig := config.CredentialServiceConfig{
+			BaseServiceConfig: &config.BaseServiceConfig{Name: "credential", ServiceEndpoint: statusListServer.URL},
+		}
+		credentialService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		require.NoError(tt, err)
+		issuerRouter, err = router.NewCredentialRouter(credentialService)
+		require.NoError(tt, err)
+
+		// another service, which holds none of the status lists
+		verifierDB := setupTestDB(tt)
+		verifierKeyStoreService := testKeyStoreService(tt, verifierDB)
+		verifierDIDService := testDIDService(tt, verifierDB, verifierKeyStoreService)
+		verifierSchemaService := testSchemaService(tt, verifierDB, verifierKeyStoreService, verifierDIDService)
+		// which fetches them from the test server, on a loopback address
+		verifierConfig := config.CredentialServiceConfig{
+			BaseServiceConfig:          &config.BaseServiceConfig{Name: "credential"},
+			AllowPrivateStatusListURLs: true,
+		}
+		verifierService, err := credential.NewCredentialService(verifierConfig, verifierDB, verifierKeyStoreService, verifierDIDService.GetResolver(), verifierSchemaService)
+		require.NoError(tt, err)
+		verifierRouter, err := router.NewCredentialRouter(verifierService)
+		require.NoError(tt, err)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		verify := func(credRouter *router.CredentialRouter, request router.VerifyCredentialRequest) router.VerifyCredentialResponse {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(tt, request))
+			assert.NoError(tt, credRouter.VerifyCredential(newRequestContext(), w, req))
+			var verifyResp router.VerifyCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&verifyResp))
+			return verifyResp
+		}
+
+		var revokedRequest router.VerifyCredentialRequest
+		for _, test := range []struct {
+			name     string
+			request  router.CreateCredentialRequest
+			update   router.UpdateCredentialStatusRequest
+			expected router.VerifyCredentialResponse
+		}{
+			{
+				name:     "revocable jwt",
+				request:  router.CreateCredentialRequest{Revocable: true},
+				update:   router.UpdateCredentialStatusRequest{Revoked: true},
+				expected: router.VerifyCredentialResponse{Revoked: true},
+			},
+			{
+				name:     "suspendable data integrity",
+				request:  router.CreateCredentialRequest{Suspendable: true, Format: "ldp_vc"},
+				update:   router.UpdateCredentialStatusRequest{Suspended: true},
+				expected: router.VerifyCredentialResponse{Suspended: true},
+			},
+		} {
+			createCredRequest := test.request
+			createCredRequest.Issuer = issuerDID.DID.ID
+			createCredRequest.IssuerKID = issuerDID.DID.VerificationMethod[0].ID
+			createCredRequest.Subject = "did:abc:456"
+			createCredRequest.Data = map[string]any{"firstName": "Jack"}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, createCredRequest))
+			err = issuerRouter.CreateCredential(newRequestContext(), w, req)
+			require.NoError(tt, err, test.name)
+			var resp router.CreateCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+
+			verifyRequest := router.VerifyCredentialRequest{CredentialJWT: resp.CredentialJWT}
+			if resp.CredentialJWT == nil {
+				verifyRequest = router.VerifyCredentialRequest{DataIntegrityCredential: resp.Credential}
+			}
+
+			// verified against the status list of the issuing service
+			verifyResp := verify(issuerRouter, verifyRequest)
+			assert.True(tt, verifyResp.Verified, test.name, verifyResp.Reason)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", resp.Credential.ID), newRequestValue(tt, test.update))
+			err = issuerRouter.UpdateCredentialStatus(newRequestContextWithParams(map[string]string{"id": resp.Credential.ID}), w, req)
+			assert.NoError(tt, err, test.name)
+
+			// both by the issuing service, and by another one fetching the status list
+			for _, credRouter := range []*router.CredentialRouter{issuerRouter, verifierRouter} {
+				verifyResp = verify(credRouter, verifyRequest)
+				assert.False(tt, verifyResp.Verified, test.name)
+				assert.Equal(tt, test.expected.Revoked, verifyResp.Revoked, test.name)
+				assert.Equal(tt, test.expected.Suspended, verifyResp.Suspended, test.name)
+				assert.Contains(tt, verifyResp.Reason, resp.Credential.ID, test.name)
+			}
+			revokedRequest = verifyRequest
+		}
+
+		// services only fetch status lists from https URLs of public addresses, unless configured otherwise
+		publicOnlyRouter := testCredentialRouter(tt, verifierDB, verifierKeyStoreService, verifierDIDService, verifierSchemaService)
+		publicOnlyResp := verify(publicOnlyRouter, revokedRequest)
+		assert.False(tt, publicOnlyResp.Verified)
+		assert.False(tt, publicOnlyResp.Suspended)
+		assert.Contains(tt, publicOnlyResp.Reason, "could not resolve status list")
+
+		// a status list which cannot be resolved fails verification, so a new one is needed, which is not cached
+		statusListServer.Close()
+		otherIssuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, router.CreateCredentialRequest{
+			Issuer:    otherIssuerDID.DID.ID,
+			IssuerKID: otherIssuerDID.DID.VerificationMethod[0].ID,
+			Subject:   "did:abc:456",
+			Data:      map[string]any{"firstName": "Jack"},
+			Revocable: true,
+		}))
+		err = issuerRouter.CreateCredential(newRequestContext(), w, req)
+		require.NoError(tt, err)
+		var resp router.CreateCredentialResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+		verifyResp := verify(verifierRouter, router.VerifyCredentialRequest{CredentialJWT: resp.CredentialJWT})
+		assert.False(tt, verifyResp.Verified)
+		assert.False(tt, verifyResp.Revoked)
+		assert.Contains(tt, verifyResp.Reason, "could not resolve status list")
+
+		// unless the verification policy allows it
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification/policies", newRequestValue(tt, router.CreateVerificationPolicyRequest{
+			VerificationPolicy: router.VerificationPolicy{Name: "offline", AllowUnresolvableStatus: true},
+		}))
+		require.NoError(tt, verifierRouter.CreateVerificationPolicy(newRequestContext(), w, req))
+		verifyResp = verify(verifierRouter, router.VerifyCredentialRequest{CredentialJWT: resp.CredentialJWT, Policy: "offline"})
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+		report := credint.VerificationReport{Checks: verifyResp.Checks}
+		result, ok := report.Result(credint.CheckStatus)
+		assert.True(tt, ok)
+		assert.Equal(tt, credint.CheckSkipped, result.Outcome)
+		assert.Contains(tt, result.Detail, "could not resolve status list")
+	})
+
+	t.Run("Test Verification Policies", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		serviceConfig := config.CredentialServiceConfig{
+			BaseServiceConfig: &config.BaseServiceConfig{Name: "credential"},
+			VerificationPolicies: []config.VerificationPolicyConfig{
+				{Name: "recent", MaxCredentialAge: time.Hour},
+			},
+		}
+		credentialService, err := credential.NewCredentialService(serviceConfig, bolt, keyStoreService, didService.GetResolver(), schemaService)
+		require.NoError(tt, err)
+		credRouter, err := router.NewCredentialRouter(credentialService)
+		require.NoError(tt, err)
+
+		createPolicy := func(policy router.VerificationPolicy) error {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification/policies", newRequestValue(tt, router.CreateVerificationPolicyRequest{VerificationPolicy: policy}))
+			return credRouter.CreateVerificationPolicy(newRequestContext(), w, req)
+		}
+
+		// invalid policies
+		assert.ErrorContains(tt, createPolicy(router.VerificationPolicy{Name: "bad name"}), "letters, digits, dashes or underscores")
+		assert.ErrorContains(tt, createPolicy(router.VerificationPolicy{Name: "checks", RequiredChecks: []string{"unknown"}}), "unknown check<unknown>")
+		assert.ErrorContains(tt, createPolicy(router.VerificationPolicy{Name: "algs", AllowedAlgorithms: []string{"none"}}), "unknown algorithm<none>")
+		assert.ErrorContains(tt, createPolicy(router.VerificationPolicy{Name: "age", MaxCredentialAge: "a month"}), "parsing maxCredentialAge")
+
+		// policies cannot have the name of another policy
+		assert.ErrorContains(tt, createPolicy(router.VerificationPolicy{Name: "recent"}), "defined in the config")
+		assert.NoError(tt, createPolicy(router.VerificationPolicy{Name: "es256", AllowedAlgorithms: []string{"ES256"}}))
+		assert.ErrorContains(tt, createPolicy(router.VerificationPolicy{Name: "es256"}), "already exists")
+		assert.NoError(tt, createPolicy(router.VerificationPolicy{Name: "web", AllowedDIDMethods: []string{"web"}}))
+		assert.NoError(tt, createPolicy(router.VerificationPolicy{
+			Name:           "signature-only",
+			RequiredChecks: []string{"signature"},
+		}))
+
+		// get a policy
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/verification/policies/recent", nil)
+		err = credRouter.GetVerificationPolicy(newRequestContextWithParams(map[string]string{"id": "recent"}), w, req)
+		assert.NoError(tt, err)
+		var getResp router.GetVerificationPolicyResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&getResp))
+		assert.Equal(tt, router.GetVerificationPolicyResponse{
+			Policy:     router.VerificationPolicy{Name: "recent", MaxCredentialAge: "1h0m0s"},
+			Configured: true,
+		}, getResp)
+
+		// list policies, sorted by name
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/verification/policies", nil)
+		assert.NoError(tt, credRouter.GetVerificationPolicies(newRequestContext(), w, req))
+		var listResp router.GetVerificationPoliciesResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&listResp))
+		var names []string
+		for _, policy := range listResp.Policies {
+			names = append(names, policy.Policy.Name)
+		}
+		assert.Equal(tt, []string{"es256", "recent", "signature-only", "web"}, names)
+
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		require.NoError(tt, err)
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, router.CreateCredentialRequest{
+			Issuer:    issuerDID.DID.ID,
+			IssuerKID: issuerDID.DID.VerificationMethod[0].ID,
+			Subject:   "did:abc:456",
+			Data:      map[string]any{"firstName": "Jack"},
+		}))
+		require.NoError(tt, credRouter.CreateCredential(newRequestContext(), w, req))
+		var createResp router.CreateCredentialResponse
+		assert.NoError(tt, json.NewDecoder(w.Body).Decode(&createResp))
+
+		verify := func(policy string) router.VerifyCredentialResponse {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(tt, router.VerifyCredentialRequest{
+				CredentialJWT: createResp.CredentialJWT,
+				Policy:        policy,
+			}))
+			assert.NoError(tt, credRouter.VerifyCredential(newRequestContext(), w, req))
+			var verifyResp router.VerifyCredentialResponse
+			assert.NoError(tt, json.NewDecoder(w.Body).Decode(&verifyResp))
+			return verifyResp
+		}
+		outcome := func(resp router.VerifyCredentialResponse, check credint.Check) credint.CheckOutcome {
+			result, ok := credint.VerificationReport{Checks: resp.Checks}.Result(check)
+			assert.True(tt, ok, check)
+			return result.Outcome
+		}
+
+		// the credential was just issued, with EdDSA, by a did:key
+		verifyResp := verify("recent")
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+		assert.Equal(tt, credint.CheckPassed, outcome(verifyResp, credint.CheckExpiry))
+
+		verifyResp = verify("es256")
+		assert.False(tt, verifyResp.Verified)
+		assert.Equal(tt, []credint.Check{credint.CheckSignature}, credint.VerificationReport{Checks: verifyResp.Checks}.FailedChecks())
+		assert.Contains(tt, verifyResp.Reason, "algorithm<EdDSA> is not allowed by policy<es256>")
+
+		verifyResp = verify("web")
+		assert.False(tt, verifyResp.Verified)
+		assert.Equal(tt, []credint.Check{credint.CheckDIDResolution}, credint.VerificationReport{Checks: verifyResp.Checks}.FailedChecks())
+		assert.Equal(tt, credint.CheckSkipped, outcome(verifyResp, credint.CheckSignature))
+
+		// checks which are not required are skipped
+		verifyResp = verify("signature-only")
+		assert.True(tt, verifyResp.Verified, verifyResp.Reason)
+		assert.Equal(tt, credint.CheckPassed, outcome(verifyResp, credint.CheckDIDResolution))
+		assert.Equal(tt, credint.CheckPassed, outcome(verifyResp, credint.CheckSignature))
+		for _, check := range []credint.Check{credint.CheckKeyPurpose, credint.CheckDataModel, credint.CheckExpiry, credint.CheckSchema, credint.CheckStatus} {
+			assert.Equal(tt, credint.CheckSkipped, outcome(verifyResp, check), check)
+		}
+
+		// unknown policies cannot be verified with
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(tt, router.VerifyCredentialRequest{
+			CredentialJWT: createResp.CredentialJWT,
+			Policy:        "unknown",
+		}))
+		assert.ErrorContains(tt, credRouter.VerifyCredential(newRequestContext(), w, req), "verification policy not found")
+
+		// configured policies cannot be deleted
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/credentials/verification/policies/recent", nil)
+		err = credRouter.DeleteVerificationPolicy(newRequestContextWithParams(map[string]string{"id": "recent"}), w, req)
+		assert.ErrorContains(tt, err, "cannot be deleted")
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/credentials/verification/policies/web", nil)
+		assert.NoError(tt, credRouter.DeleteVerificationPolicy(newRequestContextWithParams(map[string]string{"id": "web"}), w, req))
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/verification/policies/web", nil)
+		err = credRouter.GetVerificationPolicy(newRequestContextWithParams(map[string]string{"id": "web"}), w, req)
+		assert.ErrorContains(tt, err, "does not exist")
+	})
 }
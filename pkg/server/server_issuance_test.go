@@ -440,7 +440,7 @@ func setupAllThings(t *testing.T) (*did.CreateDIDResponse, *schema.CreateSchemaR
 
 func testIssuanceRouter(t *testing.T, s storage.ServiceStorage) *router.IssuanceRouter {
 	serviceConfig := config.IssuingServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "test-issuing"}}
-	svc, err := issuing.NewIssuingService(serviceConfig, s)
+	svc, err := issuing.NewIssuingService(serviceConfig, s, s, s)
 	require.NoError(t, err)
 
 	r, err := router.NewIssuanceRouter(svc)
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/credential/manifest"
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/benbjohnson/clock"
 	"github.com/goccy/go-json"
@@ -483,6 +485,313 @@ func TestManifestAPI(t *testing.T) {
 		assert.NotEmpty(tt, vc2.CredentialStatus)
 	})
 
+	t.Run("Submit Application With Typed Credentials and Evidence", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		issuanceService := testIssuanceService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credentialService := testCredentialService(tt, bolt, keyStoreService, didService, schemaService)
+		manifestRouter, manifestSvc := testManifest(tt, bolt, keyStoreService, didService, credentialService)
+
+		// create an issuer
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		// create an applicant
+		applicantDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		// create a schema for the creds to be issued against
+		licenseSchema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"licenseType": map[string]any{
+					"type": "string",
+				},
+			},
+			"additionalProperties": true,
+		}
+		kid := issuerDID.DID.VerificationMethod[0].ID
+		createdSchema, err := schemaService.CreateSchema(
+			context.Background(),
+			schema.CreateSchemaRequest{Author: issuerDID.DID.ID, AuthorKID: kid, Name: "license schema", Schema: licenseSchema, Sign: true})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, createdSchema)
+
+		// issue a credential against the schema to the subject, from the issuer
+		createdCred, err := credentialService.CreateCredential(
+			context.Background(),
+			credential.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: kid,
+				Subject:   applicantDID.DID.ID,
+				SchemaID:  createdSchema.ID,
+				Data: map[string]any{
+					"licenseType": "WA-DL-CLASS-A",
+					"firstName":   "Tester",
+					"lastName":    "McTest",
+				},
+			})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, createdCred)
+
+		// good request
+		createManifestRequest := getValidManifestRequest(issuerDID.DID.ID, issuerDID.DID.VerificationMethod[0].ID, createdSchema.ID)
+
+		requestValue := newRequestValue(tt, createManifestRequest)
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests", requestValue)
+		w := httptest.NewRecorder()
+		err = manifestRouter.CreateManifest(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var resp router.CreateManifestResponse
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		assert.NoError(tt, err)
+
+		m := resp.Manifest
+		assert.NotEmpty(tt, m)
+		assert.Equal(tt, m.Issuer.ID, issuerDID.DID.ID)
+
+		// good application request
+		container := []credmodel.Container{{CredentialJWT: createdCred.CredentialJWT}}
+		applicationRequest := getValidApplicationRequest(m.ID, m.PresentationDefinition.ID, m.PresentationDefinition.InputDescriptors[0].ID, container)
+
+		// sign application
+		applicantPrivKeyBytes, err := base58.Decode(applicantDID.PrivateKeyBase58)
+		assert.NoError(tt, err)
+		applicantPrivKey, err := crypto.BytesToPrivKey(applicantPrivKeyBytes, applicantDID.KeyType)
+		assert.NoError(tt, err)
+		signer, err := keyaccess.NewJWKKeyAccess(applicantDID.DID.ID, applicantDID.DID.VerificationMethod[0].ID, applicantPrivKey)
+		assert.NoError(tt, err)
+		signed, err := signer.SignJSON(applicationRequest)
+		assert.NoError(tt, err)
+
+		expiryDateTime := time.Date(2022, 10, 31, 0, 0, 0, 0, time.UTC)
+		mockClock := clock.NewMock()
+		manifestSvc.Clock = mockClock
+		mockClock.Set(expiryDateTime)
+		expiryDuration := 5 * time.Second
+		templateRequest := getValidIssuanceTemplateRequest(m, issuerDID, createdSchema, expiryDateTime, expiryDuration)
+		// the evidence of a credential must have a type
+		templateRequest.IssuanceTemplate.Credentials[0].Evidence = []any{map[string]any{"verifier": "https://example.edu/issuers/14"}}
+		_, err = issuanceService.CreateIssuanceTemplate(context.Background(), templateRequest)
+		assert.ErrorContains(tt, err, "evidence<0> must have a type")
+
+		// the first credential is typed and has evidence, while the second has neither
+		templateRequest.IssuanceTemplate.Credentials[0].Evidence = []any{map[string]any{"type": "DocumentVerification"}}
+		templateRequest.IssuanceTemplate.Credentials[0].Type = []any{"StateCredential"}
+		issuanceTemplate, err := issuanceService.CreateIssuanceTemplate(context.Background(), templateRequest)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuanceTemplate)
+
+		applicationRequestValue := newRequestValue(tt, router.SubmitApplicationRequest{ApplicationJWT: *signed})
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests/applications", applicationRequestValue)
+		err = manifestRouter.SubmitApplication(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var op router.Operation
+		err = json.NewDecoder(w.Body).Decode(&op)
+		assert.NoError(tt, err)
+		assert.True(tt, op.Done)
+
+		var appResp router.SubmitApplicationResponse
+		respData, err := json.Marshal(op.Result.Response)
+		assert.NoError(tt, err)
+		err = json.Unmarshal(respData, &appResp)
+		assert.NoError(tt, err)
+		assert.Len(tt, appResp.Credentials, 2, "each output_descriptor in the definition should result in a credential")
+
+		_, _, vc, err := credsdk.ToCredential(appResp.Credentials[0])
+		assert.NoError(tt, err)
+		expectedSubject := credsdk.CredentialSubject{
+			"id":        applicantDID.DID.ID,
+			"state":     "CA",
+			"firstName": "Tester",
+			"lastName":  "McTest",
+		}
+		assert.Equal(tt, expectedSubject, vc.CredentialSubject)
+		assert.Equal(tt, []any{"VerifiableCredential", "StateCredential"}, vc.Type)
+		assert.Equal(tt, []any{map[string]any{"type": "DocumentVerification"}}, vc.Evidence)
+
+		_, _, vc2, err := credsdk.ToCredential(appResp.Credentials[1])
+		assert.NoError(tt, err)
+		assert.Equal(tt, []any{"VerifiableCredential"}, vc2.Type)
+		assert.Empty(tt, vc2.Evidence)
+	})
+
+	t.Run("Submit Application With SD-JWT Credentials", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		issuanceService := testIssuanceService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+		credentialService := testCredentialService(tt, bolt, keyStoreService, didService, schemaService)
+		manifestRouter, manifestSvc := testManifest(tt, bolt, keyStoreService, didService, credentialService)
+
+		// create an issuer
+		issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		// create an applicant
+		applicantDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+			Method:  didsdk.KeyMethod,
+			KeyType: crypto.Ed25519,
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuerDID)
+
+		// create a schema for the creds to be issued against
+		licenseSchema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"licenseType": map[string]any{
+					"type": "string",
+				},
+			},
+			"additionalProperties": true,
+		}
+		kid := issuerDID.DID.VerificationMethod[0].ID
+		createdSchema, err := schemaService.CreateSchema(
+			context.Background(),
+			schema.CreateSchemaRequest{Author: issuerDID.DID.ID, AuthorKID: kid, Name: "license schema", Schema: licenseSchema, Sign: true})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, createdSchema)
+
+		// issue a credential against the schema to the subject, from the issuer
+		createdCred, err := credentialService.CreateCredential(
+			context.Background(),
+			credential.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: kid,
+				Subject:   applicantDID.DID.ID,
+				SchemaID:  createdSchema.ID,
+				Data: map[string]any{
+					"licenseType": "WA-DL-CLASS-A",
+					"firstName":   "Tester",
+					"lastName":    "McTest",
+				},
+			})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, createdCred)
+
+		// good request
+		createManifestRequest := getValidManifestRequest(issuerDID.DID.ID, issuerDID.DID.VerificationMethod[0].ID, createdSchema.ID)
+
+		requestValue := newRequestValue(tt, createManifestRequest)
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests", requestValue)
+		w := httptest.NewRecorder()
+		err = manifestRouter.CreateManifest(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var resp router.CreateManifestResponse
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		assert.NoError(tt, err)
+
+		m := resp.Manifest
+		assert.NotEmpty(tt, m)
+		assert.Equal(tt, m.Issuer.ID, issuerDID.DID.ID)
+
+		// good application request
+		container := []credmodel.Container{{CredentialJWT: createdCred.CredentialJWT}}
+		applicationRequest := getValidApplicationRequest(m.ID, m.PresentationDefinition.ID, m.PresentationDefinition.InputDescriptors[0].ID, container)
+
+		// sign application
+		applicantPrivKeyBytes, err := base58.Decode(applicantDID.PrivateKeyBase58)
+		assert.NoError(tt, err)
+		applicantPrivKey, err := crypto.BytesToPrivKey(applicantPrivKeyBytes, applicantDID.KeyType)
+		assert.NoError(tt, err)
+		signer, err := keyaccess.NewJWKKeyAccess(applicantDID.DID.ID, applicantDID.DID.VerificationMethod[0].ID, applicantPrivKey)
+		assert.NoError(tt, err)
+		signed, err := signer.SignJSON(applicationRequest)
+		assert.NoError(tt, err)
+
+		expiryDateTime := time.Date(2022, 10, 31, 0, 0, 0, 0, time.UTC)
+		mockClock := clock.NewMock()
+		manifestSvc.Clock = mockClock
+		mockClock.Set(expiryDateTime)
+		expiryDuration := 5 * time.Second
+		templateRequest := getValidIssuanceTemplateRequest(m, issuerDID, createdSchema, expiryDateTime, expiryDuration)
+		// the second credential is issued as an sd-jwt
+		templateRequest.IssuanceTemplate.Credentials[1].DisclosableClaims = []string{"someCrazyObject"}
+		issuanceTemplate, err := issuanceService.CreateIssuanceTemplate(context.Background(), templateRequest)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, issuanceTemplate)
+
+		applicationRequestValue := newRequestValue(tt, router.SubmitApplicationRequest{ApplicationJWT: *signed})
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests/applications", applicationRequestValue)
+		err = manifestRouter.SubmitApplication(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var op router.Operation
+		err = json.NewDecoder(w.Body).Decode(&op)
+		assert.NoError(tt, err)
+		assert.True(tt, op.Done)
+
+		var appResp router.SubmitApplicationResponse
+		respData, err := json.Marshal(op.Result.Response)
+		assert.NoError(tt, err)
+		err = json.Unmarshal(respData, &appResp)
+		assert.NoError(tt, err)
+		assert.Len(tt, appResp.Credentials, 2, "each output_descriptor in the definition should result in a credential")
+
+		_, _, vc, err := credsdk.ToCredential(appResp.Credentials[0])
+		assert.NoError(tt, err)
+		expectedSubject := credsdk.CredentialSubject{
+			"id":        applicantDID.DID.ID,
+			"state":     "CA",
+			"firstName": "Tester",
+			"lastName":  "McTest",
+		}
+		assert.Equal(tt, expectedSubject, vc.CredentialSubject)
+
+		// the credential without disclosable claims is a vc-jwt, and the other an sd-jwt
+		credJWT, ok := appResp.Credentials[0].(string)
+		require.True(tt, ok)
+		assert.NotContains(tt, credJWT, "~")
+		assert.Equal(tt, string(exchange.JWTVC), appResp.Response.Fulfillment.DescriptorMap[1].Format)
+		sdJWT, ok := appResp.Credentials[1].(string)
+		require.True(tt, ok)
+		_, disclosures, _, err := keyaccess.SDJWT(sdJWT).Parse()
+		assert.NoError(tt, err)
+		assert.Len(tt, disclosures, 1)
+		vc2, err := credmodel.DisclosedCredential(keyaccess.SDJWT(sdJWT))
+		assert.NoError(tt, err)
+		expectedSubject = credsdk.CredentialSubject{
+			"id": applicantDID.DID.ID,
+			"someCrazyObject": map[string]any{
+				"foo": 123.,
+				"bar": false,
+				"baz": []any{
+					"yay", 123., nil,
+				},
+			},
+		}
+		assert.Equal(tt, expectedSubject, vc2.CredentialSubject)
+		assert.Equal(tt,
+			time.Date(2022, 10, 31, 0, 0, 5, 0, time.UTC).Format(time.RFC3339),
+			vc2.ExpirationDate,
+		)
+		assert.Equal(tt, createdSchema.ID, vc2.CredentialSchema.ID)
+		assert.NotEmpty(tt, vc2.CredentialStatus)
+	})
+
 	t.Run("Test Submit Application", func(tt *testing.T) {
 		bolt := setupTestDB(tt)
 		require.NotNil(tt, bolt)
@@ -637,6 +946,124 @@ func TestManifestAPI(t *testing.T) {
 		assert.Equal(tt, createdSchema.ID, vc.CredentialSchema.ID)
 	})
 
+	for _, proofType := range []cryptosuite.SignatureType{cryptosuite.JSONWebSignature2020, keyaccess.Ed25519Signature2020} {
+		t.Run("Test Submit Application With Data Integrity Credentials - "+string(proofType), func(tt *testing.T) {
+			bolt := setupTestDB(tt)
+			require.NotNil(tt, bolt)
+
+			keyStoreService := testKeyStoreService(tt, bolt)
+			didService := testDIDService(tt, bolt, keyStoreService)
+			schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+			credentialService := testCredentialService(tt, bolt, keyStoreService, didService, schemaService)
+			manifestRouter, _ := testManifest(tt, bolt, keyStoreService, didService, credentialService)
+
+			issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+				Method:  didsdk.KeyMethod,
+				KeyType: crypto.Ed25519,
+			})
+			assert.NoError(tt, err)
+			applicantDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+				Method:  didsdk.KeyMethod,
+				KeyType: crypto.Ed25519,
+			})
+			assert.NoError(tt, err)
+
+			licenseSchema := map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"licenseType": map[string]any{
+						"type": "string",
+					},
+				},
+				"additionalProperties": true,
+			}
+			kid := issuerDID.DID.VerificationMethod[0].ID
+			createdSchema, err := schemaService.CreateSchema(context.Background(),
+				schema.CreateSchemaRequest{Author: issuerDID.DID.ID, AuthorKID: kid, Name: "license schema", Schema: licenseSchema})
+			assert.NoError(tt, err)
+
+			// the applicant holds a data integrity credential
+			createdCred, err := credentialService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+				Issuer:    issuerDID.DID.ID,
+				IssuerKID: kid,
+				Subject:   applicantDID.DID.ID,
+				SchemaID:  createdSchema.ID,
+				Data:      map[string]any{"licenseType": "WA-DL-CLASS-A"},
+				Format:    exchange.LDPVC.CredentialFormat(),
+				ProofType: proofType,
+			})
+			assert.NoError(tt, err)
+			assert.True(tt, createdCred.HasDataIntegrityCredential())
+
+			// the manifest only claims linked data proofs, of a type the service does not support and then of the proof type
+			createManifestRequest := getValidManifestRequest(issuerDID.DID.ID, kid, createdSchema.ID)
+			createManifestRequest.ClaimFormat = &exchange.ClaimFormat{
+				LDPVC: &exchange.LDPType{ProofType: []cryptosuite.SignatureType{"BbsBlsSignature2020", proofType}},
+			}
+			createManifestRequest.PresentationDefinition.InputDescriptors[0].Constraints.Fields[0].Path = []string{"$.credentialSubject.licenseType"}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests", newRequestValue(tt, createManifestRequest))
+			err = manifestRouter.CreateManifest(newRequestContext(), w, req)
+			assert.NoError(tt, err)
+
+			var resp router.CreateManifestResponse
+			err = json.NewDecoder(w.Body).Decode(&resp)
+			assert.NoError(tt, err)
+			m := resp.Manifest
+
+			applicationRequest := getValidApplicationRequest(m.ID, m.PresentationDefinition.ID, m.PresentationDefinition.InputDescriptors[0].ID, []credmodel.Container{createdCred.Container})
+			applicationRequest.CredentialApplication.Format = createManifestRequest.ClaimFormat
+			applicationRequest.CredentialApplication.PresentationSubmission.DescriptorMap[0].Format = exchange.LDPVC.String()
+
+			applicantPrivKeyBytes, err := base58.Decode(applicantDID.PrivateKeyBase58)
+			assert.NoError(tt, err)
+			applicantPrivKey, err := crypto.BytesToPrivKey(applicantPrivKeyBytes, applicantDID.KeyType)
+			assert.NoError(tt, err)
+			signer, err := keyaccess.NewJWKKeyAccess(applicantDID.DID.ID, applicantDID.DID.VerificationMethod[0].ID, applicantPrivKey)
+			assert.NoError(tt, err)
+			signed, err := signer.SignJSON(applicationRequest)
+			assert.NoError(tt, err)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests/applications", newRequestValue(tt, router.SubmitApplicationRequest{ApplicationJWT: *signed}))
+			err = manifestRouter.SubmitApplication(newRequestContext(), w, req)
+			assert.NoError(tt, err)
+
+			var op router.Operation
+			err = json.NewDecoder(w.Body).Decode(&op)
+			assert.NoError(tt, err)
+
+			w = httptest.NewRecorder()
+			applicationID := storage.StatusObjectID(op.ID)
+			req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests/applications/"+applicationID+"/review", newRequestValue(tt, router.ReviewApplicationRequest{Approved: true}))
+			err = manifestRouter.ReviewApplication(newRequestContextWithParams(map[string]string{"id": applicationID}), w, req)
+			assert.NoError(tt, err)
+
+			var appResp router.SubmitApplicationResponse
+			err = json.NewDecoder(w.Body).Decode(&appResp)
+			assert.NoError(tt, err)
+
+			// the issued credentials carry an embedded proof, and are described as such
+			require.NotEmpty(tt, appResp.Response.Fulfillment)
+			require.Len(tt, appResp.Response.Fulfillment.DescriptorMap, 2)
+			require.Len(tt, appResp.Credentials, 2)
+			for i, descriptor := range appResp.Response.Fulfillment.DescriptorMap {
+				assert.Equal(tt, exchange.LDPVC.String(), descriptor.Format)
+
+				credMap, ok := appResp.Credentials[i].(map[string]any)
+				require.True(tt, ok)
+				container, err := credmodel.NewCredentialContainerFromMap(credMap)
+				assert.NoError(tt, err)
+				assert.Equal(tt, applicantDID.DID.ID, container.Credential.CredentialSubject.GetID())
+				assert.Equal(tt, proofType, keyaccess.ProofType(container.Credential))
+
+				verified, err := credentialService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: container.Credential})
+				assert.NoError(tt, err)
+				assert.True(tt, verified.Verified, verified.Reason)
+			}
+		})
+	}
+
 	t.Run("Test Denied Application", func(tt *testing.T) {
 		bolt := setupTestDB(tt)
 		require.NotNil(tt, bolt)
@@ -1065,6 +1492,33 @@ func TestManifestAPI(t *testing.T) {
 
 		assert.Len(tt, getApplicationsResp.Applications, 1)
 
+		// get applications filtered by manifest and by applicant
+		for _, query := range []string{"manifestId=" + m.ID, "applicantDid=" + applicantDID.DID.ID} {
+			req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/manifests/applications?"+query, nil)
+			err = manifestRouter.GetApplications(newRequestContext(), w, req)
+			assert.NoError(tt, err)
+
+			var filteredResp router.GetApplicationsResponse
+			err = json.NewDecoder(w.Body).Decode(&filteredResp)
+			assert.NoError(tt, err)
+			assert.Len(tt, filteredResp.Applications, 1)
+		}
+
+		// get applications for a manifest that has none
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/manifests/applications?manifestId=bad", nil)
+		err = manifestRouter.GetApplications(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+
+		var emptyResp router.GetApplicationsResponse
+		err = json.NewDecoder(w.Body).Decode(&emptyResp)
+		assert.NoError(tt, err)
+		assert.Empty(tt, emptyResp.Applications)
+
+		// both filters cannot be given together
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/manifests/applications?manifestId=bad&applicantDid=bad", nil)
+		err = manifestRouter.GetApplications(newRequestContext(), w, req)
+		assert.Error(tt, err)
+
 		// get the application
 		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/manifests/applications/%s", getApplicationsResp.Applications[0].ID), nil)
 		err = manifestRouter.GetApplication(newRequestContextWithParams(map[string]string{"id": getApplicationsResp.Applications[0].ID}), w, req)
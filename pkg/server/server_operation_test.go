@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
@@ -291,15 +290,10 @@ func TestOperationsAPI(t *testing.T) {
 }
 
 func setupTestDB(t *testing.T) storage.ServiceStorage {
-	file, err := os.CreateTemp("", "bolt")
-	require.NoError(t, err)
-	name := file.Name()
-	s, err := storage.NewStorage(storage.Bolt, name)
+	s, err := storage.NewStorage(storage.Memory, nil)
 	require.NoError(t, err)
 	t.Cleanup(func() {
 		_ = s.Close()
-		_ = file.Close()
-		_ = os.Remove(name)
 	})
 	return s
 }
@@ -326,7 +320,7 @@ func reviewSubmission(t *testing.T, pRouter *router.PresentationRouter, submissi
 }
 
 func setupOperationsRouter(t *testing.T, s storage.ServiceStorage) *router.OperationRouter {
-	svc, err := operation.NewOperationService(s)
+	svc, err := operation.NewOperationService(s, s, s)
 	assert.NoError(t, err)
 	opRouter, err := router.NewOperationRouter(svc)
 	assert.NoError(t, err)
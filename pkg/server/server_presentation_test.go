@@ -15,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -153,6 +154,32 @@ func TestPresentationAPI(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("Create returns error with an unknown verification policy", func(tt *testing.T) {
+		s := setupTestDB(tt)
+		pRouter, didService := setupPresentationRouter(tt, s)
+		authorDID := createDID(tt, didService)
+		request := router.CreatePresentationDefinitionRequest{
+			Name:    "name",
+			Purpose: "purpose",
+			InputDescriptors: []exchange.InputDescriptor{{
+				ID: "id",
+				Constraints: &exchange.Constraints{
+					Fields: []exchange.Field{{Path: []string{"$.credentialSubject.dateOfBirth"}}},
+				},
+			}},
+			Author:             authorDID.DID.ID,
+			AuthorKID:          authorDID.DID.VerificationMethod[0].ID,
+			VerificationPolicy: "unknown",
+		}
+		value := newRequestValue(tt, request)
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/definitions", value)
+		w := httptest.NewRecorder()
+
+		err = pRouter.CreateDefinition(newRequestContext(), w, req)
+
+		assert.ErrorContains(tt, err, "verification policy not found")
+	})
+
 	t.Run("Get without an ID returns error", func(tt *testing.T) {
 		s := setupTestDB(tt)
 		pRouter, _ := setupPresentationRouter(tt, s)
@@ -243,6 +270,105 @@ func TestPresentationAPI(t *testing.T) {
 			assert.Zero(ttt, resp.Result)
 		})
 
+		tt.Run("Create submission with SD-JWT credential only constrains disclosed claims", func(ttt *testing.T) {
+			s := setupTestDB(ttt)
+			pRouter, didService := setupPresentationRouter(ttt, s)
+			authorDID := createDID(ttt, didService)
+
+			holderSigner, holderDID := getSigner(ttt)
+			kid := authorDID.DID.VerificationMethod[0].ID
+			definition := createPresentationDefinition(ttt, pRouter, authorDID.DID.ID, kid)
+
+			issuerSigner, issuerDID := getSigner(ttt)
+			vc := VerifiableCredential(WithCredentialSubject(credential.CredentialSubject{
+				"dateOfBirth": "1987-01-02",
+				"givenName":   "Uribe",
+				"id":          holderDID.String(),
+			}))
+			vc.Issuer = issuerDID.String()
+			issuerKeyAccess := keyaccess.JWKKeyAccess{JWTSigner: &issuerSigner}
+			sdJWT, err := issuerKeyAccess.SignVerifiableCredentialSDJWT(vc, []string{"dateOfBirth", "givenName"})
+			require.NoError(ttt, err)
+			issuerJWT, disclosures, _, err := sdJWT.Parse()
+			require.NoError(ttt, err)
+			disclosuresByName := make(map[string]keyaccess.Disclosure)
+			for _, d := range disclosures {
+				disclosuresByName[d.Name] = d
+			}
+			holderKeyAccess := keyaccess.JWKKeyAccess{JWTSigner: &holderSigner}
+			submit := func(presented keyaccess.SDJWT) error {
+				request := createSubmissionRequestWithCredential(ttt, definition.PresentationDefinition.ID, authorDID.DID.ID, presented.String(), holderSigner, holderDID)
+				value := newRequestValue(ttt, request)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/submissions", value)
+				return pRouter.CreateSubmission(newRequestContext(), httptest.NewRecorder(), req)
+			}
+
+			// disclosing the date of birth fulfills the definition, once bound to a challenge for it
+			challenge := createChallenge(ttt, pRouter, definition.PresentationDefinition.ID)
+			assert.Equal(ttt, authorDID.DID.ID, challenge.Audience)
+			dateOfBirth := keyaccess.NewSDJWT(issuerJWT, []keyaccess.Disclosure{disclosuresByName["dateOfBirth"]}, nil)
+			presented, err := holderKeyAccess.SignKeyBinding(dateOfBirth, challenge.Audience, challenge.Nonce)
+			require.NoError(ttt, err)
+			require.NoError(ttt, submit(*presented))
+
+			// the nonce of the challenge can only be submitted once
+			err = submit(*presented)
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "is not that of an unexpired challenge")
+
+			// and must be that of a challenge the service issued
+			presented, err = holderKeyAccess.SignKeyBinding(dateOfBirth, challenge.Audience, "made-up-nonce")
+			require.NoError(ttt, err)
+			err = submit(*presented)
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "is not that of an unexpired challenge")
+
+			// while withholding the date of birth does not fulfill the definition
+			challenge = createChallenge(ttt, pRouter, definition.PresentationDefinition.ID)
+			presented, err = holderKeyAccess.SignKeyBinding(keyaccess.NewSDJWT(issuerJWT, []keyaccess.Disclosure{disclosuresByName["givenName"]}, nil), challenge.Audience, challenge.Nonce)
+			require.NoError(ttt, err)
+			err = submit(*presented)
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "not fulfilled for non-optional field: date_of_birth")
+
+			// nor does a key binding by someone other than the subject
+			challenge = createChallenge(ttt, pRouter, definition.PresentationDefinition.ID)
+			presented, err = issuerKeyAccess.SignKeyBinding(dateOfBirth, challenge.Audience, challenge.Nonce)
+			require.NoError(ttt, err)
+			err = submit(*presented)
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "verifying sd-jwt credential")
+
+			// nor does a key binding with the nonce of the challenge but for another audience
+			challenge = createChallenge(ttt, pRouter, definition.PresentationDefinition.ID)
+			presented, err = holderKeyAccess.SignKeyBinding(dateOfBirth, "did:example:other", challenge.Nonce)
+			require.NoError(ttt, err)
+			err = submit(*presented)
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "is not issued for audience")
+
+			// nor does a credential bound to the key of its holder without a key binding
+			token, err := jwt.Parse([]byte(issuerJWT), jwt.WithVerify(false), jwt.WithValidate(false))
+			require.NoError(ttt, err)
+			require.NoError(ttt, token.Set(keyaccess.ConfirmationClaim, map[string]any{"kid": holderSigner.KeyID()}))
+			// signing with the issuer's key sets its kid header
+			confirmedJWT, err := jwt.Sign(token, jwt.WithKey(issuerSigner.SignatureAlgorithm, issuerSigner.Key))
+			require.NoError(ttt, err)
+			err = submit(keyaccess.NewSDJWT(keyaccess.JWT(confirmedJWT), []keyaccess.Disclosure{disclosuresByName["dateOfBirth"]}, nil))
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "has no key binding JWT")
+		})
+
+		tt.Run("Create challenge requires an existing presentation definition", func(ttt *testing.T) {
+			s := setupTestDB(ttt)
+			pRouter, _ := setupPresentationRouter(ttt, s)
+
+			req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/definitions/unknown/challenges", nil)
+			err := pRouter.CreateChallenge(newRequestContextWithParams(map[string]string{"id": "unknown"}), httptest.NewRecorder(), req)
+			assert.Error(ttt, err)
+			assert.Contains(ttt, err.Error(), "presentation definition not found")
+		})
+
 		tt.Run("Review submission returns approved submission", func(ttt *testing.T) {
 			s := setupTestDB(ttt)
 			pRouter, didService := setupPresentationRouter(ttt, s)
@@ -384,6 +510,7 @@ func TestPresentationAPI(t *testing.T) {
 			}
 			diff := cmp.Diff(expectedSubmissions, resp.Submissions,
 				cmpopts.IgnoreFields(credential.VerifiablePresentation{}, "ID", "VerifiableCredential", "PresentationSubmission"),
+				cmpopts.IgnoreFields(model.Submission{}, "Verifications"),
 				cmpopts.SortSlices(func(l, r model.Submission) bool {
 					return l.VerifiablePresentation.Holder < r.VerifiablePresentation.Holder
 				}),
@@ -393,6 +520,11 @@ func TestPresentationAPI(t *testing.T) {
 			}
 			assert.Len(ttt, resp.Submissions[0].VerifiablePresentation.VerifiableCredential, 1)
 			assert.Len(ttt, resp.Submissions[1].VerifiablePresentation.VerifiableCredential, 1)
+			for _, submission := range resp.Submissions {
+				assert.Len(ttt, submission.Verifications, 1)
+				assert.True(ttt, submission.Verifications[0].Verified)
+				assert.NotEmpty(ttt, submission.Verifications[0].Checks)
+			}
 
 			assert.ElementsMatch(ttt,
 				[]string{
@@ -472,6 +604,7 @@ func TestPresentationAPI(t *testing.T) {
 			}
 			diff := cmp.Diff(expectedSubmissions, resp.Submissions,
 				cmpopts.IgnoreFields(credential.VerifiablePresentation{}, "ID", "PresentationSubmission", "VerifiableCredential"),
+				cmpopts.IgnoreFields(model.Submission{}, "Verifications"),
 				cmpopts.SortSlices(func(l, r model.Submission) bool {
 					return l.VerifiablePresentation.Holder < r.VerifiablePresentation.Holder
 				}),
@@ -528,7 +661,8 @@ func setupPresentationRouter(t *testing.T, s storage.ServiceStorage) (*router.Pr
 	didService := testDIDService(t, s, keyStoreService)
 	schemaService := testSchemaService(t, s, keyStoreService, didService)
 
-	service, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, s, didService.GetResolver(), schemaService, keyStoreService)
+	credentialService := testCredentialService(t, s, keyStoreService, didService, schemaService)
+	service, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, s, didService.GetResolver(), schemaService, keyStoreService, credentialService, credentialService)
 	assert.NoError(t, err)
 
 	pRouter, err := router.NewPresentationRouter(service)
@@ -565,6 +699,10 @@ func createSubmissionRequest(t *testing.T, definitionID, requesterDID string, vc
 	vc.Issuer = didKey.String()
 	vcData, err := credential.SignVerifiableCredentialJWT(issuerSigner, vc)
 	require.NoError(t, err)
+	return createSubmissionRequestWithCredential(t, definitionID, requesterDID, keyaccess.JWT(vcData), holderSigner, holderDID)
+}
+
+func createSubmissionRequestWithCredential(t *testing.T, definitionID, requesterDID string, cred any, holderSigner crypto.JWTSigner, holderDID didsdk.DIDKey) router.CreateSubmissionRequest {
 	ps := exchange.PresentationSubmission{
 		ID:           uuid.NewString(),
 		DefinitionID: definitionID,
@@ -583,7 +721,7 @@ func createSubmissionRequest(t *testing.T, definitionID, requesterDID string, vc
 		Holder:                 holderDID.String(),
 		Type:                   []string{credential.VerifiablePresentationType},
 		PresentationSubmission: ps,
-		VerifiableCredential:   []any{keyaccess.JWT(vcData)},
+		VerifiableCredential:   []any{cred},
 	}
 
 	signed, err := credential.SignVerifiablePresentationJWT(holderSigner, credential.JWTVVPParameters{Audience: requesterDID}, vp)
@@ -631,6 +769,16 @@ func WithInputDescriptors(inputDescriptors []exchange.InputDescriptor) Definitio
 	}
 }
 
+func createChallenge(t *testing.T, pRouter *router.PresentationRouter, definitionID string) router.CreateChallengeResponse {
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/presentations/definitions/%s/challenges", definitionID), nil)
+	w := httptest.NewRecorder()
+
+	require.NoError(t, pRouter.CreateChallenge(newRequestContextWithParams(map[string]string{"id": definitionID}), w, req))
+	var resp router.CreateChallengeResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	return resp
+}
+
 func createPresentationDefinition(t *testing.T, pRouter *router.PresentationRouter, author, authorKID string, opts ...DefinitionOption) router.CreatePresentationDefinitionResponse {
 	request := router.CreatePresentationDefinitionRequest{
 		Name:    "name",
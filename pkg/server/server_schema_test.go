@@ -207,6 +207,60 @@ func TestSchemaAPI(t *testing.T) {
 		assert.Len(tt, getSchemasResp.Schemas, 1)
 	})
 
+	t.Run("Test Get Schemas Paginated", func(tt *testing.T) {
+		bolt := setupTestDB(tt)
+		require.NotNil(tt, bolt)
+
+		keyStoreService := testKeyStoreService(tt, bolt)
+		didService := testDIDService(tt, bolt, keyStoreService)
+		schemaService := testSchemaRouter(tt, bolt, keyStoreService, didService)
+
+		// create a few schemas
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			schemaRequest := router.CreateSchemaRequest{Author: "did:test", Name: fmt.Sprintf("test schema %d", i), Schema: getTestSchema()}
+			createReq := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/schemas", newRequestValue(tt, schemaRequest))
+			err := schemaService.CreateSchema(newRequestContext(), w, createReq)
+			assert.NoError(tt, err)
+		}
+
+		// page through them two at a time
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/schemas?pageSize=2", nil)
+		err := schemaService.GetSchemas(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		var firstPage router.GetSchemasResponse
+		err = json.NewDecoder(w.Body).Decode(&firstPage)
+		assert.NoError(tt, err)
+		assert.Len(tt, firstPage.Schemas, 2)
+		assert.NotEmpty(tt, firstPage.NextPageToken)
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/schemas?pageSize=2&pageToken="+firstPage.NextPageToken, nil)
+		err = schemaService.GetSchemas(newRequestContext(), w, req)
+		assert.NoError(tt, err)
+		var secondPage router.GetSchemasResponse
+		err = json.NewDecoder(w.Body).Decode(&secondPage)
+		assert.NoError(tt, err)
+		assert.Len(tt, secondPage.Schemas, 1)
+		assert.Empty(tt, secondPage.NextPageToken)
+		assert.NotContains(tt, []string{firstPage.Schemas[0].Schema.ID, firstPage.Schemas[1].Schema.ID}, secondPage.Schemas[0].Schema.ID)
+
+		// bad page size
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/schemas?pageSize=-1", nil)
+		err = schemaService.GetSchemas(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid pageSize")
+
+		// bad page token
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/schemas?pageToken=bad", nil)
+		err = schemaService.GetSchemas(newRequestContext(), w, req)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid page token")
+	})
+
 	t.Run("Test Delete SchemaID", func(tt *testing.T) {
 		bolt := setupTestDB(tt)
 		require.NotNil(tt, bolt)
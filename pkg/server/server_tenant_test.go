@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/server/middleware"
+	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+func TestTenantAPI(t *testing.T) {
+	t.Run("Test Create And Get Tenant", func(tt *testing.T) {
+		db := storage.NewTenantStorage(setupTestDB(tt))
+		tenantRouter, _ := testTenant(tt, db)
+
+		createTenantRequest := router.CreateTenantRequest{
+			ID:     "acme",
+			Config: router.TenantConfig{ServiceEndpoint: "https://acme.example.com", ApplicationTTL: "1h"},
+		}
+		req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/tenants", newRequestValue(tt, createTenantRequest))
+		w := httptest.NewRecorder()
+		err := tenantRouter.CreateTenant(newRequestContext(), w, req)
+		require.NoError(tt, err)
+
+		var resp router.CreateTenantResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(tt, "acme", resp.Tenant.ID)
+		assert.Equal(tt, "https://acme.example.com", resp.Tenant.Config.ServiceEndpoint)
+		assert.Equal(tt, "1h0m0s", resp.Tenant.Config.ApplicationTTL)
+		assert.Empty(tt, resp.Tenant.Config.SubmissionTTL)
+		assert.NotEmpty(tt, resp.APIKey)
+
+		// a tenant cannot be created twice
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/tenants", newRequestValue(tt, createTenantRequest))
+		err = tenantRouter.CreateTenant(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "already exists")
+
+		// tenant ids are restricted, so that the namespaces of two tenants never overlap
+		badTenantRequest := router.CreateTenantRequest{ID: "ac-me"}
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/tenants", newRequestValue(tt, badTenantRequest))
+		err = tenantRouter.CreateTenant(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "invalid tenant id")
+
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/tenants/acme", nil)
+		w = httptest.NewRecorder()
+		err = tenantRouter.GetTenant(newRequestContextWithParams(map[string]string{"id": "acme"}), w, req)
+		require.NoError(tt, err)
+		var getResp router.GetTenantResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&getResp))
+		assert.Equal(tt, resp.Tenant, getResp.Tenant)
+		// the api key of a tenant is not stored, nor is its hash returned
+		assert.NotContains(tt, w.Body.String(), resp.APIKey)
+		assert.NotContains(tt, w.Body.String(), "apiKey")
+
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/tenants/globex", nil)
+		err = tenantRouter.GetTenant(newRequestContextWithParams(map[string]string{"id": "globex"}), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "does not exist")
+
+		req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/tenants", nil)
+		w = httptest.NewRecorder()
+		err = tenantRouter.GetTenants(newRequestContext(), w, req)
+		require.NoError(tt, err)
+		var getTenantsResp router.GetTenantsResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&getTenantsResp))
+		assert.Len(tt, getTenantsResp.Tenants, 1)
+
+		// the api key of a tenant can be rotated
+		req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/tenants/acme/api-key", nil)
+		w = httptest.NewRecorder()
+		err = tenantRouter.RotateTenantAPIKey(newRequestContextWithParams(map[string]string{"id": "acme"}), w, req)
+		require.NoError(tt, err)
+		var rotateResp router.RotateTenantAPIKeyResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&rotateResp))
+		assert.NotEmpty(tt, rotateResp.APIKey)
+		assert.NotEqual(tt, resp.APIKey, rotateResp.APIKey)
+		err = tenantRouter.RotateTenantAPIKey(newRequestContextWithParams(map[string]string{"id": "globex"}), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "does not exist")
+
+		// tenants cannot manage tenants
+		tenantCtx := storage.WithTenant(newRequestContext(), "acme")
+		err = tenantRouter.GetTenants(tenantCtx, httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "cannot be managed")
+	})
+
+	t.Run("Test Tenant Middleware", func(tt *testing.T) {
+		db := storage.NewTenantStorage(setupTestDB(tt))
+		_, tenantService := testTenant(tt, db)
+		created, err := tenantService.CreateTenant(context.Background(), tenant.CreateTenantRequest{
+			ID:     "acme",
+			Config: tenant.Config{SubmissionTTL: time.Hour},
+		})
+		require.NoError(tt, err)
+
+		var gotCtx context.Context
+		handler := middleware.Tenant(tenantService)(func(ctx context.Context, _ http.ResponseWriter, _ *http.Request) error {
+			gotCtx = ctx
+			return nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials", nil)
+		require.NoError(tt, handler(newRequestContext(), httptest.NewRecorder(), req))
+		assert.Empty(tt, storage.TenantFromContext(gotCtx))
+		assert.Equal(tt, time.Minute, tenant.SubmissionTTL(gotCtx, time.Minute))
+
+		// requests naming a tenant must be authenticated with its api key
+		gotCtx = nil
+		req.Header.Set(middleware.TenantHeader, "acme")
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "could not authenticate request on behalf of tenant<acme>")
+		assert.Nil(tt, gotCtx)
+		req.Header.Set(middleware.TenantAPIKeyHeader, "not-the-key")
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "could not authenticate request on behalf of tenant<acme>")
+		assert.Nil(tt, gotCtx)
+
+		req.Header.Set(middleware.TenantAPIKeyHeader, created.APIKey)
+		require.NoError(tt, handler(newRequestContext(), httptest.NewRecorder(), req))
+		assert.Equal(tt, "acme", storage.TenantFromContext(gotCtx))
+		assert.Equal(tt, time.Hour, tenant.SubmissionTTL(gotCtx, time.Minute))
+
+		// the api key of another tenant does not authenticate the request
+		other, err := tenantService.CreateTenant(context.Background(), tenant.CreateTenantRequest{ID: "initech"})
+		require.NoError(tt, err)
+		req.Header.Set(middleware.TenantAPIKeyHeader, other.APIKey)
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "could not authenticate request on behalf of tenant<acme>")
+
+		// nor does a rotated key
+		rotated, err := tenantService.RotateTenantAPIKey(context.Background(), tenant.RotateTenantAPIKeyRequest{ID: "acme"})
+		require.NoError(tt, err)
+		req.Header.Set(middleware.TenantAPIKeyHeader, created.APIKey)
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "could not authenticate request on behalf of tenant<acme>")
+		req.Header.Set(middleware.TenantAPIKeyHeader, rotated.APIKey)
+		require.NoError(tt, handler(newRequestContext(), httptest.NewRecorder(), req))
+
+		// tenants created before tenants had api keys need theirs rotated
+		tenantStorage, err := tenant.NewTenantStorage(db)
+		require.NoError(tt, err)
+		_, err = tenantStorage.StoreTenant(context.Background(), tenant.Tenant{ID: "legacy"})
+		require.NoError(tt, err)
+		req.Header.Set(middleware.TenantHeader, "legacy")
+		req.Header.Set(middleware.TenantAPIKeyHeader, "")
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "could not authenticate request on behalf of tenant<legacy>")
+		rotated, err = tenantService.RotateTenantAPIKey(context.Background(), tenant.RotateTenantAPIKeyRequest{ID: "legacy"})
+		require.NoError(tt, err)
+		req.Header.Set(middleware.TenantAPIKeyHeader, rotated.APIKey)
+		require.NoError(tt, handler(newRequestContext(), httptest.NewRecorder(), req))
+		assert.Equal(tt, "legacy", storage.TenantFromContext(gotCtx))
+
+		// tenants which do not exist cannot be told apart from a wrong api key
+		req.Header.Set(middleware.TenantHeader, "globex")
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "could not authenticate request on behalf of tenant<globex>")
+
+		req.Header.Set(middleware.TenantHeader, "Not A Tenant")
+		err = handler(newRequestContext(), httptest.NewRecorder(), req)
+		assert.ErrorContains(tt, err, "invalid tenant id")
+	})
+
+	t.Run("Test Tenant Admin Routes", func(tt *testing.T) {
+		serviceConfig, err := config.LoadConfig("")
+		require.NoError(tt, err)
+		serviceConfig.Services.StorageProvider = string(storage.Memory)
+		serviceConfig.Server.AdminAPIKey = "admin-api-key"
+		server, err := NewSSIServer(make(chan os.Signal, 1), *serviceConfig)
+		require.NoError(tt, err)
+
+		serve := func(method, target string, body any, headers map[string]string) *httptest.ResponseRecorder {
+			var req *http.Request
+			if body != nil {
+				req = httptest.NewRequest(method, target, newRequestValue(tt, body))
+			} else {
+				req = httptest.NewRequest(method, target, nil)
+			}
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+			return w
+		}
+		admin := map[string]string{middleware.AdminAPIKeyHeader: "admin-api-key"}
+
+		w := serve(http.MethodPut, "/v1/tenants", router.CreateTenantRequest{ID: "acme"}, nil)
+		assert.Equal(tt, http.StatusUnauthorized, w.Code)
+		w = serve(http.MethodPut, "/v1/tenants", router.CreateTenantRequest{ID: "acme"}, admin)
+		require.Equal(tt, http.StatusCreated, w.Code, w.Body.String())
+		var created router.CreateTenantResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&created))
+
+		// without the admin api key, the api key of a tenant cannot be rotated, nor can tenants be read or deleted
+		w = serve(http.MethodPut, "/v1/tenants/acme/api-key", nil, nil)
+		assert.Equal(tt, http.StatusUnauthorized, w.Code)
+		w = serve(http.MethodPut, "/v1/tenants/acme/api-key", nil, map[string]string{middleware.AdminAPIKeyHeader: "not-the-key"})
+		assert.Equal(tt, http.StatusUnauthorized, w.Code)
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			w = serve(method, "/v1/tenants/acme", nil, nil)
+			assert.Equal(tt, http.StatusUnauthorized, w.Code, method)
+		}
+		w = serve(http.MethodGet, "/v1/tenants", nil, nil)
+		assert.Equal(tt, http.StatusUnauthorized, w.Code)
+
+		// nor with the api key of a tenant
+		w = serve(http.MethodPut, "/v1/tenants/acme/api-key", nil, map[string]string{
+			middleware.TenantHeader:       "acme",
+			middleware.TenantAPIKeyHeader: created.APIKey,
+		})
+		assert.Equal(tt, http.StatusUnauthorized, w.Code)
+
+		w = serve(http.MethodPut, "/v1/tenants/acme/api-key", nil, admin)
+		require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+		var rotated router.RotateTenantAPIKeyResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&rotated))
+		assert.NotEqual(tt, created.APIKey, rotated.APIKey)
+
+		// when no admin api key is configured, tenants cannot be managed at all
+		serviceConfig.Server.AdminAPIKey = ""
+		server, err = NewSSIServer(make(chan os.Signal, 1), *serviceConfig)
+		require.NoError(tt, err)
+		w = serve(http.MethodPut, "/v1/tenants/acme/api-key", nil, map[string]string{middleware.AdminAPIKeyHeader: ""})
+		assert.Equal(tt, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Test Tenant Isolation", func(tt *testing.T) {
+		db := storage.NewTenantStorage(setupTestDB(tt))
+		_, tenantService := testTenant(tt, db)
+		keyStoreService := testKeyStoreService(tt, db)
+		webhookService := testWebhookService(tt, db)
+
+		ctx := context.Background()
+		_, err := tenantService.CreateTenant(ctx, tenant.CreateTenantRequest{ID: "acme"})
+		require.NoError(tt, err)
+		acmeCtx := storage.WithTenant(ctx, "acme")
+
+		// keys stored by a tenant are only visible to it
+		_, privKey, err := crypto.GenerateKeyByKeyType(crypto.Ed25519)
+		require.NoError(tt, err)
+		privKeyBytes, err := crypto.PrivKeyToBytes(privKey)
+		require.NoError(tt, err)
+		keyID := "did:test:acme#key-1"
+		err = keyStoreService.StoreKey(acmeCtx, keystore.StoreKeyRequest{
+			ID:               keyID,
+			Type:             crypto.Ed25519,
+			Controller:       "did:test:acme",
+			PrivateKeyBase58: base58.Encode(privKeyBytes),
+		})
+		require.NoError(tt, err)
+
+		gotKey, err := keyStoreService.GetKey(acmeCtx, keystore.GetKeyRequest{ID: keyID})
+		require.NoError(tt, err)
+		assert.Equal(tt, keyID, gotKey.ID)
+		_, err = keyStoreService.GetKey(ctx, keystore.GetKeyRequest{ID: keyID})
+		assert.Error(tt, err)
+
+		// so are webhooks
+		_, err = webhookService.CreateWebhook(acmeCtx, webhook.CreateWebhookRequest{
+			Noun: webhook.Credential,
+			Verb: webhook.Create,
+			URL:  "https://acme.example.com/hook",
+		})
+		require.NoError(tt, err)
+		webhooks, err := webhookService.GetWebhooks(acmeCtx, webhook.GetWebhooksRequest{})
+		require.NoError(tt, err)
+		assert.Len(tt, webhooks.Webhooks, 1)
+		webhooks, err = webhookService.GetWebhooks(ctx, webhook.GetWebhooksRequest{})
+		require.NoError(tt, err)
+		assert.Empty(tt, webhooks.Webhooks)
+
+		// deleting the tenant deletes its data
+		require.NoError(tt, tenantService.DeleteTenant(ctx, tenant.DeleteTenantRequest{ID: "acme"}))
+		gotTenant, err := tenantService.ResolveTenant(ctx, "acme")
+		require.NoError(tt, err)
+		assert.Nil(tt, gotTenant)
+		namespaces, err := db.Namespaces(acmeCtx)
+		require.NoError(tt, err)
+		assert.Empty(tt, namespaces)
+		assert.Error(tt, tenantService.DeleteTenant(ctx, tenant.DeleteTenantRequest{ID: "acme"}))
+	})
+}
+
+func testTenant(t *testing.T, db *storage.TenantStorage) (*router.TenantRouter, *tenant.Service) {
+	tenantService, err := tenant.NewTenantService(db)
+	require.NoError(t, err)
+	require.NotEmpty(t, tenantService)
+
+	// create router for service
+	tenantRouter, err := router.NewTenantRouter(tenantService)
+	require.NoError(t, err)
+	require.NotEmpty(t, tenantRouter)
+
+	return tenantRouter, tenantService
+}
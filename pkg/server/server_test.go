@@ -52,6 +52,7 @@ func TestHealthCheckAPI(t *testing.T) {
 	shutdown := make(chan os.Signal, 1)
 	serviceConfig, err := config.LoadConfig("")
 	assert.NoError(t, err)
+	serviceConfig.Services.StorageProvider = string(storage.Memory)
 	server, err := NewSSIServer(shutdown, *serviceConfig)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, server)
@@ -224,7 +225,7 @@ func testIssuanceService(t *testing.T, db storage.ServiceStorage) *issuing.Servi
 		BaseServiceConfig: &config.BaseServiceConfig{Name: "test-issuance"},
 	}
 
-	s, err := issuing.NewIssuingService(cfg, db)
+	s, err := issuing.NewIssuingService(cfg, db, db, db)
 	require.NoError(t, err)
 	require.NotEmpty(t, s)
 	return s
@@ -299,7 +300,7 @@ func testCredentialRouter(t *testing.T, bolt storage.ServiceStorage, keyStore *k
 func testManifest(t *testing.T, db storage.ServiceStorage, keyStore *keystore.Service, did *did.Service, credential *credential.Service) (*router.ManifestRouter, *manifest.Service) {
 	serviceConfig := config.ManifestServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "manifest"}}
 	// create a manifest service
-	manifestService, err := manifest.NewManifestService(serviceConfig, db, keyStore, did.GetResolver(), credential)
+	manifestService, err := manifest.NewManifestService(serviceConfig, db, db, keyStore, did.GetResolver(), credential)
 	require.NoError(t, err)
 	require.NotEmpty(t, manifestService)
 
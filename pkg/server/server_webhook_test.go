@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
 	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 func TestWebhookAPI(t *testing.T) {
@@ -218,7 +219,7 @@ func TestWebhookAPI(t *testing.T) {
 
 		webhookService := testWebhookService(tt, db)
 
-		gotWebhooks, err := webhookService.GetWebhooks(context.Background())
+		gotWebhooks, err := webhookService.GetWebhooks(context.Background(), webhook.GetWebhooksRequest{})
 		assert.NoError(tt, err)
 		assert.Len(tt, gotWebhooks.Webhooks, 0)
 
@@ -243,7 +244,7 @@ func TestWebhookAPI(t *testing.T) {
 		})
 		assert.NoError(tt, err)
 
-		gotWebhooks, err = webhookService.GetWebhooks(context.Background())
+		gotWebhooks, err = webhookService.GetWebhooks(context.Background(), webhook.GetWebhooksRequest{})
 		assert.NoError(tt, err)
 		assert.Len(tt, gotWebhooks.Webhooks, 2)
 	})
@@ -277,4 +278,77 @@ func TestWebhookAPI(t *testing.T) {
 		assert.ErrorContains(tt, err, "webhook does not exist")
 		assert.Nil(tt, gotWebhook)
 	})
+	t.Run("Test Handle Change", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		require.NotNil(tt, db)
+
+		webhookService := testWebhookService(tt, db)
+
+		payloads := make(chan webhook.Payload, 1)
+		status := http.StatusServiceUnavailable
+		hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload webhook.Payload
+			assert.NoError(tt, json.NewDecoder(r.Body).Decode(&payload))
+			w.WriteHeader(status)
+			payloads <- payload
+		}))
+		tt.Cleanup(hook.Close)
+
+		_, err := webhookService.CreateWebhook(context.Background(), webhook.CreateWebhookRequest{
+			Noun: "Credential",
+			Verb: "Update",
+			URL:  hook.URL,
+		})
+		assert.NoError(tt, err)
+
+		event := storage.ChangeEvent{
+			ID:        "1",
+			Type:      storage.ChangeUpdate,
+			Namespace: "credential",
+			Key:       "credential-id",
+			Value:     []byte(`{"id":"credential-id","credentialId":"urn:uuid:1234","token":"a.b.c","issuer":"did:key:z6Mk"}`),
+		}
+
+		// a failed post is reported, so that the outbox delivers the change again
+		assert.Error(tt, webhookService.HandleChange(context.Background(), event))
+		<-payloads
+
+		status = http.StatusOK
+		assert.NoError(tt, webhookService.HandleChange(context.Background(), event))
+		payload := <-payloads
+		assert.Equal(tt, webhook.Credential, payload.Noun)
+		assert.Equal(tt, webhook.Update, payload.Verb)
+		assert.Equal(tt, "1", payload.EventID)
+		assert.Equal(tt, "credential-id", payload.ResourceID)
+		// the credential is posted as the API returns it, without the fields which are internal to the service
+		assert.Equal(tt, map[string]any{"id": "urn:uuid:1234", "credentialJwt": "a.b.c"}, payload.Data)
+
+		// changes to namespaces without a noun, or without webhooks, are not posted
+		event.Namespace = "status-list-credential"
+		assert.NoError(tt, webhookService.HandleChange(context.Background(), event))
+		event.Namespace = "credential"
+		event.Type = storage.ChangeCreate
+		assert.NoError(tt, webhookService.HandleChange(context.Background(), event))
+		assert.Empty(tt, payloads)
+
+		// the namespaces of each DID method are posted to the webhooks registered for DIDs
+		_, err = webhookService.CreateWebhook(context.Background(), webhook.CreateWebhookRequest{
+			Noun: "DID",
+			Verb: "Delete",
+			URL:  hook.URL,
+		})
+		assert.NoError(tt, err)
+		didEvent := storage.ChangeEvent{
+			ID:        "2",
+			Type:      storage.ChangeDelete,
+			Namespace: "did-web",
+			Key:       "did:web:example.com",
+			Value:     []byte(`{"id":"did:web:example.com","did":{"id":"did:web:example.com"},"softDeleted":false}`),
+		}
+		assert.NoError(tt, webhookService.HandleChange(context.Background(), didEvent))
+		payload = <-payloads
+		assert.Equal(tt, webhook.DID, payload.Noun)
+		assert.Equal(tt, webhook.Delete, payload.Verb)
+		assert.Equal(tt, map[string]any{"did": map[string]any{"id": "did:web:example.com"}}, payload.Data)
+	})
 }
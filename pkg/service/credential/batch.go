@@ -0,0 +1,242 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/operation"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
+	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	// DefaultBatchOperationThreshold is the number of credentials above which batches are created in the background,
+	// unless configured otherwise.
+	DefaultBatchOperationThreshold = 100
+	// MaxBatchSize is the largest number of credentials which may be created in a single batch.
+	MaxBatchSize = 10000
+)
+
+// BatchCreateCredentials creates the credentials of every request of the batch in a single transaction, allocating the
+// status list indexes of the credentials which share a status list at once, and signing the credentials in parallel.
+// Batches larger than the configured threshold are created in the background, and an operation tracking them is
+// returned instead of their results.
+func (s Service) BatchCreateCredentials(ctx context.Context, request BatchCreateCredentialsRequest) (*BatchCreateCredentialsResponse, error) {
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid batch create credentials request")
+	}
+	if len(request.Requests) > MaxBatchSize {
+		return nil, sdkutil.LoggingNewErrorf("batch of %d credentials is larger than the maximum of %d", len(request.Requests), MaxBatchSize)
+	}
+
+	if len(request.Requests) <= s.batchOperationThreshold() {
+		results, err := s.createCredentials(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return &BatchCreateCredentialsResponse{Results: results}, nil
+	}
+
+	opID := batch.IDFromBatchID(uuid.NewString())
+	if err := s.opsStorage.StoreOperation(ctx, opstorage.StoredOperation{ID: opID}, 0); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store batch operation")
+	}
+	go s.createCredentialsOperation(detachedContext{Context: ctx}, opID, request)
+	return &BatchCreateCredentialsResponse{Operation: &operation.Operation{ID: opID}}, nil
+}
+
+func (s Service) batchOperationThreshold() int {
+	if s.config.BatchOperationThreshold > 0 {
+		return s.config.BatchOperationThreshold
+	}
+	return DefaultBatchOperationThreshold
+}
+
+// createCredentialsOperation creates the credentials of the batch, and marks the operation tracking it as done along
+// with their results.
+func (s Service) createCredentialsOperation(ctx context.Context, opID string, request BatchCreateCredentialsRequest) {
+	op := opstorage.StoredOperation{ID: opID, Done: true}
+	results, err := s.createCredentials(ctx, request)
+	if err == nil {
+		op.Response, err = json.Marshal(batch.Response{Results: results})
+	}
+	if err != nil {
+		op.Error = err.Error()
+	}
+	if err = s.opsStorage.StoreOperation(ctx, op, 0); err != nil {
+		logrus.WithError(err).Errorf("could not store batch operation<%s>", opID)
+	}
+}
+
+// statusListGroup is the credentials of a batch which share a status list, by their position in the batch.
+type statusListGroup struct {
+	slcMetadata StatusListCredentialMetadata
+	positions   []int
+}
+
+// createCredentials creates the credentials of the batch, returning the result of each of its requests. When the
+// batch is atomic, an error is returned instead as soon as any of its credentials cannot be created.
+func (s Service) createCredentials(ctx context.Context, request BatchCreateCredentialsRequest) ([]batch.Result, error) {
+	results := make([]batch.Result, len(request.Requests))
+	prepared := make([]*preparedCredential, len(request.Requests))
+	schemas := make(schemaCache)
+	groups := make(map[string]*statusListGroup)
+	ids := make(map[string]bool)
+	for i, credRequest := range request.Requests {
+		p, err := s.prepareCredential(ctx, credRequest, schemas)
+		if err == nil && ids[credRequest.ID] {
+			err = sdkutil.LoggingNewErrorf("credential id<%s> is used by more than one request of the batch", credRequest.ID)
+		}
+		if err != nil {
+			if request.Atomic {
+				return nil, errors.Wrapf(err, "preparing credential<%d>", i)
+			}
+			results[i].Error = err.Error()
+			continue
+		}
+		prepared[i] = p
+		if credRequest.ID != "" {
+			ids[credRequest.ID] = true
+		}
+		if !credRequest.hasStatus() {
+			continue
+		}
+		slcMetadata := s.statusListCredentialMetadata(credRequest)
+		key := slcMetadata.statusListCredentialWatchKey.Key
+		if _, ok := groups[key]; !ok {
+			groups[key] = &statusListGroup{slcMetadata: slcMetadata}
+		}
+		groups[key].positions = append(groups[key].positions, i)
+	}
+
+	// status lists are allocated from in a stable order
+	sortedGroups := make([]*statusListGroup, 0, len(groups))
+	watchKeys := make([]storage.WatchKey, 0, 3*len(groups)+len(ids))
+	for _, group := range groups {
+		sortedGroups = append(sortedGroups, group)
+		watchKeys = append(watchKeys, group.slcMetadata.watchKeys()...)
+	}
+	for id := range ids {
+		watchKeys = append(watchKeys, s.storage.GetCredentialIDWatchKey(id))
+	}
+	sort.Slice(sortedGroups, func(i, j int) bool {
+		return sortedGroups[i].slcMetadata.statusListCredentialWatchKey.Key < sortedGroups[j].slcMetadata.statusListCredentialWatchKey.Key
+	})
+
+	returnValue, err := s.storage.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		// the results of the preparation are copied, since the transaction may be retried
+		txResults := append([]batch.Result(nil), results...)
+		return s.createCredentialsBusinessLogic(ctx, tx, request.Atomic, prepared, sortedGroups, txResults)
+	}, watchKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute")
+	}
+
+	txResults, ok := returnValue.([]batch.Result)
+	if !ok {
+		return nil, errors.New("Problem with casting to batch results")
+	}
+	return txResults, nil
+}
+
+func (s Service) createCredentialsBusinessLogic(ctx context.Context, tx storage.Tx, atomic bool, prepared []*preparedCredential, groups []*statusListGroup, results []batch.Result) ([]batch.Result, error) {
+	for _, group := range groups {
+		first := prepared[group.positions[0]]
+		allocations, err := s.allocateStatusListIndexes(ctx, tx, first, group.slcMetadata, len(group.positions))
+		if err != nil {
+			if atomic {
+				return nil, err
+			}
+			for _, i := range group.positions {
+				results[i].Error = err.Error()
+			}
+			continue
+		}
+		for j, i := range group.positions {
+			if err = s.setCredentialStatus(ctx, prepared[i], allocations[j]); err != nil {
+				if atomic {
+					return nil, errors.Wrapf(err, "setting status of credential<%d>", i)
+				}
+				results[i].Error = err.Error()
+			}
+		}
+	}
+
+	s.signPreparedCredentials(ctx, prepared, results)
+
+	for i, result := range results {
+		if result.Error != "" {
+			if atomic {
+				return nil, errors.Errorf("creating credential<%d>: %s", i, result.Error)
+			}
+			continue
+		}
+		// the ids were checked when preparing the credentials, but may have been taken since
+		exists, err := s.storage.CredentialExistsTx(ctx, tx, result.Container.ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists && !atomic {
+			results[i] = batch.Result{Error: fmt.Sprintf("credential with id<%s> already exists", result.Container.ID)}
+			continue
+		}
+		if err = s.storage.StoreNewCredentialTx(ctx, tx, StoreCredentialRequest{Container: *result.Container}); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "saving credential<%d>", i)
+		}
+		if err = s.storeRefreshTx(ctx, tx, prepared[i].request, *result.Container, ""); err != nil {
+			return nil, errors.Wrapf(err, "saving refresh of credential<%d>", i)
+		}
+	}
+	return results, nil
+}
+
+// signPreparedCredentials signs the prepared credentials which have no error in their results in parallel, setting
+// the container of each in its result, or the error it could not be signed with.
+func (s Service) signPreparedCredentials(ctx context.Context, prepared []*preparedCredential, results []batch.Result) {
+	positions := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range positions {
+				container, err := s.signPreparedCredential(ctx, prepared[i])
+				if err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+				results[i].Container = container
+			}
+		}()
+	}
+	for i := range prepared {
+		if prepared[i] != nil && results[i].Error == "" {
+			positions <- i
+		}
+	}
+	close(positions)
+	wg.Wait()
+}
+
+// detachedContext keeps the values of its parent, such as its tenant, but is never cancelled, so that a batch created
+// in the background outlives the request which started it.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
@@ -1,7 +1,21 @@
 package credential
 
 import (
+	"time"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/pkg/errors"
+	"go.einride.tech/aip/filtering"
+	"go.einride.tech/aip/ordering"
+
 	"github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/issuing"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
 )
 
 const (
@@ -12,15 +26,42 @@ type CreateCredentialRequest struct {
 	Issuer    string `json:"issuer" validate:"required"`
 	IssuerKID string `json:"issuerKid" validate:"required"`
 	Subject   string `json:"subject" validate:"required"`
-	// A context is optional. If not present, we'll apply default, required context values.
-	Context string `json:"context,omitempty"`
+	// The id of the credential is optional. If present, it must be a URI no other credential of the service has.
+	// Otherwise, one is generated.
+	ID string `json:"id,omitempty"`
+	// A context is optional. It is either a context URI or a list of them, which are added to the default, required
+	// context values.
+	Context any `json:"context,omitempty"`
+	// Types are optional. They are either a type or a list of them, which are added to VerifiableCredential.
+	Type any `json:"type,omitempty"`
+	// Evidence is optional. Each evidence is an object with a type, and an id which is a URI when present.
+	Evidence []any `json:"evidence,omitempty"`
+	// Terms of use are optional. Each has a type, and an id which is a URI when present.
+	TermsOfUse []credsdk.TermsOfUse `json:"termsOfUse,omitempty"`
 	// A schema ID is optional. If present, we'll attempt to look it up and validate the data against it.
 	SchemaID    string         `json:"schemaId,omitempty"`
 	Data        map[string]any `json:"data,omitempty"`
 	Expiry      string         `json:"expiry,omitempty"`
 	Revocable   bool           `json:"revocable,omitempty"`
 	Suspendable bool           `json:"suspendable,omitempty"`
-	// TODO(gabe) support more capabilities like signature type, format, status, and more.
+	// The format the credential is signed in, either jwt_vc, ldp_vc or vc+sd-jwt. Defaults to vc+sd-jwt when there
+	// are disclosable claims, and to jwt_vc otherwise.
+	Format exchange.CredentialFormat `json:"format,omitempty"`
+	// The type of the proof of an ldp_vc credential, either JsonWebSignature2020 or Ed25519Signature2020, which
+	// requires an Ed25519 issuer key. Defaults to JsonWebSignature2020.
+	ProofType cryptosuite.SignatureType `json:"proofType,omitempty"`
+	// The version of the VC data model the credential conforms to, either 1.1 or 2.0. Defaults to 2.0 for the issuers
+	// configured to issue 2.0 credentials, and to 1.1 otherwise. 2.0 credentials are only signed as jwt_vc.
+	DataModelVersion credential.DataModelVersion `json:"dataModelVersion,omitempty"`
+	// Paths of the claims of the credential subject which the holder may selectively disclose, such as
+	// address.street. Only valid for vc+sd-jwt credentials.
+	DisclosableClaims []string `json:"disclosableClaims,omitempty"`
+	// Whether the credential has a refresh service, through which its subject may have it issued again with a new
+	// expiry.
+	Refreshable bool `json:"refreshable,omitempty"`
+	// The credential template the credential is issued from, which is run again when the credential is refreshed. Only
+	// set by the manifest service, for the credentials it issues from issuance templates.
+	RefreshTemplate *issuing.CredentialTemplate `json:"-"`
 }
 
 // CreateCredentialResponse holds a resulting credential from credential creation, which is an XOR type:
@@ -29,6 +70,58 @@ type CreateCredentialResponse struct {
 	credential.Container `json:"credential,omitempty"`
 }
 
+// BatchCreateCredentialsRequest creates the credentials of many requests at once.
+type BatchCreateCredentialsRequest struct {
+	Requests []CreateCredentialRequest `json:"requests" validate:"required,min=1,dive"`
+	// Whether either every credential is created or none is. Otherwise, the credentials which cannot be created are
+	// reported in their results, and the others are created.
+	Atomic bool `json:"atomic,omitempty"`
+}
+
+// BatchCreateCredentialsResponse holds either the result of each request of the batch, in the order they were made,
+// or, when the batch is created in the background, the operation tracking it, whose response holds the results once
+// done.
+type BatchCreateCredentialsResponse struct {
+	Results   []batch.Result       `json:"results,omitempty"`
+	Operation *operation.Operation `json:"operation,omitempty"`
+}
+
+// RefreshCredentialRequest asks for a refreshable credential to be issued again. The request JWT is signed by the
+// subject of the credential, whose DID is its issuer, and holds the ID of the credential in its credentialId claim.
+type RefreshCredentialRequest struct {
+	RequestJWT keyaccess.JWT `json:"requestJwt" validate:"required"`
+}
+
+// RefreshCredentialResponse holds the credential issued in place of the refreshed one.
+type RefreshCredentialResponse struct {
+	credential.Container `json:"credential,omitempty"`
+	// The ID of the credential which was refreshed.
+	RefreshedFrom string `json:"refreshedFrom"`
+}
+
+// VerificationPolicy is a policy credentials can be verified with, along with whether it is defined in the config of
+// the service, in which case it cannot be deleted through the API.
+type VerificationPolicy struct {
+	credential.Policy
+	Configured bool `json:"configured"`
+}
+
+type CreateVerificationPolicyRequest struct {
+	Policy credential.Policy `json:"policy" validate:"required"`
+}
+
+type GetVerificationPolicyRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type GetVerificationPoliciesResponse struct {
+	Policies []VerificationPolicy `json:"policies,omitempty"`
+}
+
+type DeleteVerificationPolicyRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
 type GetCredentialRequest struct {
 	ID string `json:"id" validate:"required"`
 }
@@ -38,19 +131,31 @@ type GetCredentialResponse struct {
 }
 
 type GetCredentialByIssuerRequest struct {
-	Issuer string `json:"issuer" validate:"required"`
+	Issuer      string `json:"issuer" validate:"required"`
+	PageRequest framework.PageRequest
 }
 
 type GetCredentialBySubjectRequest struct {
-	Subject string `json:"subject" validate:"required"`
+	Subject     string `json:"subject" validate:"required"`
+	PageRequest framework.PageRequest
 }
 
 type GetCredentialBySchemaRequest struct {
-	Schema string `json:"schema" validate:"required"`
+	Schema      string `json:"schema" validate:"required"`
+	PageRequest framework.PageRequest
+}
+
+// ListCredentialsRequest lists the credentials that match the filter, sorted by the fields of OrderBy. The fields are
+// among ListCredentialsOrderByFields.
+type ListCredentialsRequest struct {
+	Filter      filtering.Filter
+	OrderBy     ordering.OrderBy
+	PageRequest framework.PageRequest
 }
 
 type GetCredentialsResponse struct {
-	Credentials []credential.Container `json:"credentials,omitempty"`
+	Credentials   []credential.Container `json:"credentials,omitempty"`
+	NextPageToken string                 `json:"nextPageToken,omitempty"`
 }
 
 type DeleteCredentialRequest struct {
@@ -62,19 +167,37 @@ type GetCredentialStatusRequest struct {
 }
 
 type GetCredentialStatusResponse struct {
-	Revoked   bool `json:"revoked" validate:"required"`
-	Suspended bool `json:"suspended" validate:"required"`
+	Revoked        bool       `json:"revoked" validate:"required"`
+	Suspended      bool       `json:"suspended" validate:"required"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	Expired        bool       `json:"expired"`
+
+	StatusListCredentialID string `json:"statusListCredentialId,omitempty"`
+	StatusListIndex        int    `json:"statusListIndex,omitempty"`
 }
 
 type UpdateCredentialStatusRequest struct {
 	ID        string `json:"id" validate:"required"`
 	Revoked   bool   `json:"revoked" validate:"required"`
 	Suspended bool   `json:"suspended" validate:"required"`
+	// When set along with Suspended, the suspension is lifted once this time has passed.
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
 }
 
 type UpdateCredentialStatusResponse struct {
-	Revoked   bool `json:"revoked" validate:"required"`
-	Suspended bool `json:"suspended" validate:"required"`
+	Revoked        bool       `json:"revoked" validate:"required"`
+	Suspended      bool       `json:"suspended" validate:"required"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+}
+
+type BatchUpdateCredentialStatusRequest struct {
+	Requests []UpdateCredentialStatusRequest `json:"requests" validate:"required,min=1"`
+}
+
+// BatchUpdateCredentialStatusResponse holds the updated status of each credential of the batch, in the order of its
+// requests.
+type BatchUpdateCredentialStatusResponse struct {
+	Results []UpdateCredentialStatusResponse `json:"results"`
 }
 
 type GetCredentialStatusListRequest struct {
@@ -85,6 +208,14 @@ type GetCredentialStatusListResponse struct {
 	credential.Container `json:"credential,omitempty"`
 }
 
+type GetStatusListsRequest struct {
+	Issuer string `json:"issuer" validate:"required"`
+}
+
+type GetStatusListsResponse struct {
+	StatusLists []StatusListUtilization `json:"statusLists"`
+}
+
 func (csr CreateCredentialRequest) isStatusValid() bool {
 	if csr.Revocable && csr.Suspendable {
 		return false
@@ -92,6 +223,74 @@ func (csr CreateCredentialRequest) isStatusValid() bool {
 	return true
 }
 
+// format returns the format the credential is to be signed in.
+func (csr CreateCredentialRequest) format() (exchange.CredentialFormat, error) {
+	switch csr.Format {
+	case "":
+		if len(csr.DisclosableClaims) > 0 {
+			return credential.SDJWTVC, nil
+		}
+		return exchange.JWTVC.CredentialFormat(), nil
+	case exchange.JWTVC.CredentialFormat(), exchange.LDPVC.CredentialFormat():
+		if len(csr.DisclosableClaims) > 0 {
+			return "", errors.Errorf("disclosable claims are only supported by the %s format", credential.SDJWTVC)
+		}
+		return csr.Format, nil
+	case credential.SDJWTVC:
+		return credential.SDJWTVC, nil
+	default:
+		return "", errors.Errorf("unsupported credential format<%s>, must be one of %s, %s or %s", csr.Format, exchange.JWTVC, exchange.LDPVC, credential.SDJWTVC)
+	}
+}
+
+// proofType returns the type of the proof of the credential, signed in the given format, or an empty type when the
+// format has no embedded proof.
+func (csr CreateCredentialRequest) proofType(format exchange.CredentialFormat) (cryptosuite.SignatureType, error) {
+	if format != exchange.LDPVC.CredentialFormat() {
+		if csr.ProofType != "" {
+			return "", errors.Errorf("proof types are only supported by the %s format", exchange.LDPVC)
+		}
+		return "", nil
+	}
+	switch csr.ProofType {
+	case "", cryptosuite.JSONWebSignature2020:
+		return cryptosuite.JSONWebSignature2020, nil
+	case keyaccess.Ed25519Signature2020:
+		return keyaccess.Ed25519Signature2020, nil
+	default:
+		return "", errors.Errorf("unsupported proof type<%s>, must be one of %s or %s", csr.ProofType, cryptosuite.JSONWebSignature2020, keyaccess.Ed25519Signature2020)
+	}
+}
+
+// dataModelVersion returns the version of the VC data model of the credential, signed in the given format. Issuers
+// configured to issue 2.0 credentials only do so for the formats supporting 2.0, and issue 1.1 credentials otherwise.
+func (csr CreateCredentialRequest) dataModelVersion(format exchange.CredentialFormat, v2Issuer bool) (credential.DataModelVersion, error) {
+	supportsV2 := format == exchange.JWTVC.CredentialFormat()
+	switch csr.DataModelVersion {
+	case credential.DataModelV2:
+		if !supportsV2 {
+			return "", errors.Errorf("data model version<%s> is only supported by the %s format", credential.DataModelV2, exchange.JWTVC)
+		}
+		return credential.DataModelV2, nil
+	case "":
+		if v2Issuer && supportsV2 {
+			return credential.DataModelV2, nil
+		}
+	}
+	return credential.DataModelV1, nil
+}
+
+// validateProperties validates the optional properties of the credential against the VC data model.
+func (csr CreateCredentialRequest) validateProperties() error {
+	if csr.ID != "" && !credential.IsURI(csr.ID) {
+		return errors.Errorf("credential id<%s> is not a URI", csr.ID)
+	}
+	if !csr.DataModelVersion.IsValid() {
+		return errors.Errorf("unsupported data model version<%s>", csr.DataModelVersion)
+	}
+	return credential.ValidateProperties(csr.Context, csr.Type, csr.Evidence, csr.TermsOfUse)
+}
+
 func (csr CreateCredentialRequest) hasStatus() bool {
 	return csr.Suspendable || csr.Revocable
 }
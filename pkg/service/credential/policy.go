@@ -0,0 +1,119 @@
+package credential
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+// configuredPolicies validates the verification policies of the config, returning them by name.
+func configuredPolicies(configs []config.VerificationPolicyConfig) (map[string]credint.Policy, error) {
+	policies := make(map[string]credint.Policy, len(configs))
+	for _, c := range configs {
+		policy := credint.Policy{
+			Name:                    c.Name,
+			AllowedAlgorithms:       c.AllowedAlgorithms,
+			AllowedDIDMethods:       c.AllowedDIDMethods,
+			MaxCredentialAge:        c.MaxCredentialAge,
+			AllowUnresolvableSchema: c.AllowUnresolvableSchema,
+			AllowUnresolvableStatus: c.AllowUnresolvableStatus,
+		}
+		for _, check := range c.RequiredChecks {
+			policy.RequiredChecks = append(policy.RequiredChecks, credint.Check(check))
+		}
+		if err := policy.IsValid(); err != nil {
+			return nil, errors.Wrapf(err, "invalid verification policy<%s>", c.Name)
+		}
+		if _, ok := policies[policy.Name]; ok {
+			return nil, errors.Errorf("verification policy<%s> is defined more than once", policy.Name)
+		}
+		policies[policy.Name] = policy
+	}
+	return policies, nil
+}
+
+// CreateVerificationPolicy stores a verification policy, which cannot have the name of another policy, including those
+// defined in the config.
+func (s Service) CreateVerificationPolicy(ctx context.Context, request CreateVerificationPolicyRequest) (*VerificationPolicy, error) {
+	if err := request.Policy.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid verification policy")
+	}
+	if _, ok := s.policies[request.Policy.Name]; ok {
+		return nil, errors.Errorf("verification policy<%s> is defined in the config", request.Policy.Name)
+	}
+
+	logrus.Debugf("creating verification policy: %s", request.Policy.Name)
+	stored, err := s.storage.StoreVerificationPolicy(ctx, request.Policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "storing verification policy")
+	}
+	if !stored {
+		return nil, errors.Errorf("verification policy<%s> already exists", request.Policy.Name)
+	}
+	return &VerificationPolicy{Policy: request.Policy}, nil
+}
+
+// GetVerificationPolicy returns the verification policy with the given name, whether it is defined in the config or
+// was created through the API.
+func (s Service) GetVerificationPolicy(ctx context.Context, request GetVerificationPolicyRequest) (*VerificationPolicy, error) {
+	if policy, ok := s.policies[request.Name]; ok {
+		return &VerificationPolicy{Policy: policy, Configured: true}, nil
+	}
+	policy, err := s.storage.GetVerificationPolicy(ctx, request.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting verification policy")
+	}
+	if policy == nil {
+		return nil, errors.Errorf("verification policy<%s> does not exist", request.Name)
+	}
+	return &VerificationPolicy{Policy: *policy}, nil
+}
+
+// GetVerificationPolicies returns the verification policies defined in the config along with those created through the
+// API, sorted by name.
+func (s Service) GetVerificationPolicies(ctx context.Context) (*GetVerificationPoliciesResponse, error) {
+	stored, err := s.storage.GetVerificationPolicies(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting verification policies")
+	}
+	policies := make([]VerificationPolicy, 0, len(s.policies)+len(stored))
+	for _, policy := range s.policies {
+		policies = append(policies, VerificationPolicy{Policy: policy, Configured: true})
+	}
+	for _, policy := range stored {
+		policies = append(policies, VerificationPolicy{Policy: policy})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	return &GetVerificationPoliciesResponse{Policies: policies}, nil
+}
+
+// DeleteVerificationPolicy deletes a verification policy created through the API. The presentation definitions and
+// manifests which name it can no longer be submitted to until a policy with the same name is created.
+func (s Service) DeleteVerificationPolicy(ctx context.Context, request DeleteVerificationPolicyRequest) error {
+	if _, ok := s.policies[request.Name]; ok {
+		return errors.Errorf("verification policy<%s> is defined in the config, and cannot be deleted", request.Name)
+	}
+
+	logrus.Debugf("deleting verification policy: %s", request.Name)
+	return s.storage.DeleteVerificationPolicy(ctx, request.Name)
+}
+
+// ResolvePolicy returns the verification policy with the given name, or credint.ErrPolicyNotFound when there is none.
+func (s Service) ResolvePolicy(ctx context.Context, name string) (*credint.Policy, error) {
+	if policy, ok := s.policies[name]; ok {
+		return &policy, nil
+	}
+	policy, err := s.storage.GetVerificationPolicy(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting verification policy")
+	}
+	if policy == nil {
+		return nil, errors.Wrapf(credint.ErrPolicyNotFound, "policy<%s>", name)
+	}
+	return policy, nil
+}
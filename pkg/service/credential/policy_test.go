@@ -0,0 +1,48 @@
+package credential
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+func TestConfiguredPolicies(t *testing.T) {
+	t.Run("returns the policies by name", func(tt *testing.T) {
+		policies, err := configuredPolicies([]config.VerificationPolicyConfig{
+			{Name: "recent", RequiredChecks: []string{"signature", "expiry"}, MaxCredentialAge: time.Hour},
+			{Name: "eddsa", AllowedAlgorithms: []string{"EdDSA"}, AllowedDIDMethods: []string{"key"}},
+		})
+		require.NoError(tt, err)
+		assert.Len(tt, policies, 2)
+		assert.Equal(tt, []credint.Check{credint.CheckSignature, credint.CheckExpiry}, policies["recent"].RequiredChecks)
+		assert.Equal(tt, time.Hour, policies["recent"].MaxCredentialAge)
+		assert.Equal(tt, []string{"key"}, policies["eddsa"].AllowedDIDMethods)
+	})
+
+	t.Run("rejects invalid policies", func(tt *testing.T) {
+		for _, test := range []struct {
+			policy   config.VerificationPolicyConfig
+			expected string
+		}{
+			{policy: config.VerificationPolicyConfig{}, expected: "letters, digits, dashes or underscores"},
+			{policy: config.VerificationPolicyConfig{Name: "a/b"}, expected: "letters, digits, dashes or underscores"},
+			{policy: config.VerificationPolicyConfig{Name: "checks", RequiredChecks: []string{"revocation"}}, expected: "unknown check<revocation>"},
+			{policy: config.VerificationPolicyConfig{Name: "algs", AllowedAlgorithms: []string{"none"}}, expected: "unknown algorithm<none>"},
+			{policy: config.VerificationPolicyConfig{Name: "methods", AllowedDIDMethods: []string{"did:key"}}, expected: "invalid DID method<did:key>"},
+			{policy: config.VerificationPolicyConfig{Name: "age", MaxCredentialAge: -time.Hour}, expected: "cannot be negative"},
+		} {
+			_, err := configuredPolicies([]config.VerificationPolicyConfig{test.policy})
+			assert.ErrorContains(tt, err, test.expected)
+		}
+	})
+
+	t.Run("rejects policies defined more than once", func(tt *testing.T) {
+		_, err := configuredPolicies([]config.VerificationPolicyConfig{{Name: "recent"}, {Name: "recent"}})
+		assert.ErrorContains(tt, err, "defined more than once")
+	})
+}
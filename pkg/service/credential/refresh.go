@@ -0,0 +1,282 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/service/issuing"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	// RefreshServiceType is the type of the refresh service of refreshable credentials.
+	RefreshServiceType = "VerifiableCredentialRefreshService2021"
+
+	// RefreshCredentialIDClaim is the claim of refresh request JWTs which holds the ID of the credential to refresh.
+	RefreshCredentialIDClaim = "credentialId"
+
+	// RefreshRequestMaxAge is how long after it is issued a refresh request JWT is accepted, which is also the longest
+	// it may be valid for, and refreshRequestClockSkew how far the clocks of the holder and the service may differ.
+	RefreshRequestMaxAge    = 5 * time.Minute
+	refreshRequestClockSkew = time.Minute
+)
+
+// StoredRefresh is how a refreshable credential is issued again. It is stored apart from the credential, since
+// updating the status of a credential stores it again from its container.
+type StoredRefresh struct {
+	CredentialID string `json:"credentialId"`
+	// The request the credential was created from, without its id and expiry.
+	Request CreateCredentialRequest `json:"request"`
+	// How long the credential was valid for from its issuance, which the credential issued in its place is valid for
+	// too. Zero when the credential does not expire.
+	ValidFor time.Duration `json:"validFor,omitempty"`
+	// The credential template the credential was issued from, if any, which is run again on refresh.
+	Template *issuing.CredentialTemplate `json:"template,omitempty"`
+
+	// The ID of the credential this one was issued in place of, if any.
+	RefreshedFrom string `json:"refreshedFrom,omitempty"`
+	// The ID of the credential issued in place of this one, once it is refreshed. A credential is refreshed once.
+	RefreshedBy string `json:"refreshedBy,omitempty"`
+}
+
+// refreshServiceID returns the ID of the refresh service of the credentials issued by the service, which is the URL of
+// the refresh endpoint.
+func (s Service) refreshServiceID(ctx context.Context) string {
+	return fmt.Sprintf("%s/v1/credentials/refresh", tenant.ServiceEndpoint(ctx, s.config.ServiceEndpoint))
+}
+
+// newStoredRefresh returns how the created credential of the request is refreshed.
+func newStoredRefresh(request CreateCredentialRequest, container credint.Container, refreshedFrom string) (*StoredRefresh, error) {
+	refresh := StoredRefresh{
+		CredentialID:  container.ID,
+		Request:       request,
+		Template:      request.RefreshTemplate,
+		RefreshedFrom: refreshedFrom,
+	}
+	refresh.Request.ID = ""
+	refresh.Request.Expiry = ""
+	cred := container.Credential
+	if cred != nil && cred.ExpirationDate != "" {
+		issuanceDate, err := time.Parse(time.RFC3339, cred.IssuanceDate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing issuance date of credential<%s>", container.ID)
+		}
+		expirationDate, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing expiration date of credential<%s>", container.ID)
+		}
+		refresh.ValidFor = expirationDate.Sub(issuanceDate)
+	}
+	return &refresh, nil
+}
+
+// storeRefreshTx stores how the created credential of the request is refreshed, when it is refreshable.
+func (s Service) storeRefreshTx(ctx context.Context, tx storage.Tx, request CreateCredentialRequest, container credint.Container, refreshedFrom string) error {
+	if !request.Refreshable {
+		return nil
+	}
+	refresh, err := newStoredRefresh(request, container, refreshedFrom)
+	if err != nil {
+		return sdkutil.LoggingError(err)
+	}
+	if err = s.storage.StoreRefreshTx(ctx, tx, *refresh); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "saving credential refresh")
+	}
+	return nil
+}
+
+// checkRefreshable returns an error when the credential with the given id, which is refreshed as described by the
+// refresh, cannot be refreshed.
+func (r *StoredRefresh) checkRefreshable(id string) error {
+	if r == nil {
+		return sdkutil.LoggingNewErrorf("credential<%s> has no refresh service", id)
+	}
+	if r.RefreshedBy != "" {
+		return sdkutil.LoggingNewErrorf("credential<%s> was already refreshed by credential<%s>", id, r.RefreshedBy)
+	}
+	return nil
+}
+
+// refreshRequest returns the request of the credential issued at now in place of the refreshed one. It has the same
+// claims, and is valid for as long, unless it was issued from a credential template, which is run again.
+func (r StoredRefresh) refreshRequest(now time.Time) (*CreateCredentialRequest, error) {
+	request := r.Request
+	request.Data = make(map[string]any, len(r.Request.Data))
+	for k, v := range r.Request.Data {
+		request.Data[k] = v
+	}
+	request.RefreshTemplate = r.Template
+	if r.ValidFor != 0 {
+		request.Expiry = now.Add(r.ValidFor).Format(time.RFC3339)
+	}
+
+	ct := r.Template
+	if ct == nil {
+		return &request, nil
+	}
+	// claims taken from the application cannot be looked up again, so they keep their value
+	for k, v := range ct.Data {
+		if vs, ok := v.(string); ok && strings.HasPrefix(vs, "$") {
+			continue
+		}
+		request.Data[k] = v
+	}
+	request.Expiry = ""
+	if ct.Expiry.Time != nil {
+		if !now.Before(*ct.Expiry.Time) {
+			return nil, errors.Errorf("credential template<%s> expires its credentials at %s, which has passed", ct.ID, ct.Expiry.Time.Format(time.RFC3339))
+		}
+		request.Expiry = ct.Expiry.Time.Format(time.RFC3339)
+	}
+	if ct.Expiry.Duration != nil {
+		request.Expiry = now.Add(*ct.Expiry.Duration).Format(time.RFC3339)
+	}
+	request.Revocable = ct.Revocable
+	request.Refreshable = ct.Refreshable
+	if len(ct.DisclosableClaims) > 0 {
+		request.Format = credint.SDJWTVC
+		request.DisclosableClaims = ct.DisclosableClaims
+	}
+	return &request, nil
+}
+
+// checkRefreshRequestClaims returns an error when the refresh request token is not issued for the refresh service with
+// the given id, or is not recent at now: it must have been issued at most RefreshRequestMaxAge ago, and when it
+// expires, not have expired nor be valid for longer than RefreshRequestMaxAge, so that a request cannot be replayed
+// for longer than that.
+func checkRefreshRequestClaims(token jwt.Token, refreshServiceID string, now time.Time) error {
+	issuedForService := false
+	for _, aud := range token.Audience() {
+		issuedForService = issuedForService || aud == refreshServiceID
+	}
+	if !issuedForService {
+		return errors.Errorf("refresh request is not issued for audience<%s>", refreshServiceID)
+	}
+	issuedAt := token.IssuedAt()
+	if issuedAt.IsZero() {
+		return errors.New("refresh request has no iat claim")
+	}
+	if issuedAt.After(now.Add(refreshRequestClockSkew)) {
+		return errors.New("refresh request is issued in the future")
+	}
+	if issuedAt.Before(now.Add(-RefreshRequestMaxAge)) {
+		return errors.Errorf("refresh request was issued more than %s ago", RefreshRequestMaxAge)
+	}
+	if expiration := token.Expiration(); !expiration.IsZero() {
+		if !now.Before(expiration.Add(refreshRequestClockSkew)) {
+			return errors.New("refresh request has expired")
+		}
+		if expiration.Sub(issuedAt) > RefreshRequestMaxAge {
+			return errors.Errorf("refresh request is valid for more than %s", RefreshRequestMaxAge)
+		}
+	}
+	if !token.NotBefore().IsZero() && now.Add(refreshRequestClockSkew).Before(token.NotBefore()) {
+		return errors.New("refresh request is not valid yet")
+	}
+	return nil
+}
+
+// RefreshCredential issues a refreshable credential again, with a new expiry and the same claims, for the subject of
+// the credential who signed the request, which must be recent and issued for the refresh service. The credential may
+// have expired, but not be revoked or suspended, and is refreshed once: the credential issued in its place is refreshed
+// next.
+func (s Service) RefreshCredential(ctx context.Context, request RefreshCredentialRequest) (*RefreshCredentialResponse, error) {
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid refresh credential request")
+	}
+
+	_, token, err := util.ParseJWT(request.RequestJWT)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not parse refresh request")
+	}
+	holder := token.Issuer()
+	if holder == "" {
+		return nil, sdkutil.LoggingNewError("refresh request has no issuer")
+	}
+	claim, ok := token.PrivateClaims()[RefreshCredentialIDClaim]
+	id, isString := claim.(string)
+	if !ok || !isString || id == "" {
+		return nil, sdkutil.LoggingNewErrorf("refresh request has no %s claim", RefreshCredentialIDClaim)
+	}
+	if err = checkRefreshRequestClaims(token, s.refreshServiceID(ctx), time.Now()); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid refresh request")
+	}
+	if err = s.verifier.VerifyJWT(ctx, holder, request.RequestJWT); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not verify refresh request signed by: %s", holder)
+	}
+
+	logrus.Debugf("refreshing credential<%s> for holder: %s", id, holder)
+
+	gotCred, err := s.storage.GetCredential(ctx, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", id)
+	}
+	if gotCred.Subject != holder {
+		return nil, sdkutil.LoggingNewErrorf("refresh request signer<%s> is not the subject of credential<%s>", holder, id)
+	}
+	if gotCred.Revoked {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> is revoked", id)
+	}
+	if gotCred.Suspended {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> is suspended", id)
+	}
+
+	refresh, err := s.storage.GetRefresh(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = refresh.checkRefreshable(id); err != nil {
+		return nil, err
+	}
+	refreshRequest, err := refresh.refreshRequest(time.Now())
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not refresh credential: %s", id)
+	}
+
+	watchKeys := []storage.WatchKey{s.storage.GetRefreshWatchKey(id)}
+	var slcMetadata StatusListCredentialMetadata
+	if refreshRequest.hasStatus() {
+		slcMetadata = s.statusListCredentialMetadata(*refreshRequest)
+		watchKeys = append(watchKeys, slcMetadata.watchKeys()...)
+	}
+
+	returnValue, err := s.storage.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		// the credential may have been refreshed since it was read
+		refresh, err := s.storage.GetRefreshTx(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err = refresh.checkRefreshable(id); err != nil {
+			return nil, err
+		}
+
+		created, err := s.createCredentialBusinessLogic(ctx, *refreshRequest, tx, slcMetadata, id)
+		if err != nil {
+			return nil, err
+		}
+		refresh.RefreshedBy = created.ID
+		if err = s.storage.StoreRefreshTx(ctx, tx, *refresh); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "saving credential refresh")
+		}
+		return &RefreshCredentialResponse{Container: created.Container, RefreshedFrom: id}, nil
+	}, watchKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute")
+	}
+
+	refreshResponse, ok := returnValue.(*RefreshCredentialResponse)
+	if !ok {
+		return nil, errors.New("Problem with casting to RefreshCredentialResponse")
+	}
+	return refreshResponse, nil
+}
@@ -0,0 +1,107 @@
+package credential
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/issuing"
+)
+
+func TestStoredRefresh_RefreshRequest(t *testing.T) {
+	now := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("keeps the claims and validity of the credential", func(tt *testing.T) {
+		refresh := StoredRefresh{
+			Request:  CreateCredentialRequest{Subject: "did:abc:123", Data: map[string]any{"firstName": "Jack"}, Refreshable: true},
+			ValidFor: time.Hour,
+		}
+		request, err := refresh.refreshRequest(now)
+		require.NoError(tt, err)
+		assert.Equal(tt, "Jack", request.Data["firstName"])
+		assert.Equal(tt, now.Add(time.Hour).Format(time.RFC3339), request.Expiry)
+		assert.True(tt, request.Refreshable)
+
+		// the stored claims are not changed
+		request.Data["firstName"] = "Jill"
+		assert.Equal(tt, "Jack", refresh.Request.Data["firstName"])
+
+		refresh.ValidFor = 0
+		request, err = refresh.refreshRequest(now)
+		require.NoError(tt, err)
+		assert.Empty(tt, request.Expiry)
+	})
+
+	t.Run("runs the credential template again", func(tt *testing.T) {
+		duration := 24 * time.Hour
+		template := &issuing.CredentialTemplate{
+			ID:                "template",
+			Data:              issuing.ClaimTemplates{"licenseType": "Class D", "firstName": "$.credentialSubject.firstName"},
+			Expiry:            issuing.TimeLike{Duration: &duration},
+			Revocable:         true,
+			Refreshable:       true,
+			DisclosableClaims: []string{"firstName"},
+		}
+		refresh := StoredRefresh{
+			Request:  CreateCredentialRequest{Subject: "did:abc:123", Data: map[string]any{"licenseType": "Class D", "firstName": "Jack"}, Refreshable: true},
+			ValidFor: time.Hour,
+			Template: template,
+		}
+		request, err := refresh.refreshRequest(now)
+		require.NoError(tt, err)
+		assert.Equal(tt, map[string]any{"licenseType": "Class D", "firstName": "Jack"}, request.Data)
+		assert.Equal(tt, now.Add(duration).Format(time.RFC3339), request.Expiry)
+		assert.True(tt, request.Revocable)
+		assert.Equal(tt, credint.SDJWTVC, request.Format)
+		assert.Equal(tt, []string{"firstName"}, request.DisclosableClaims)
+		assert.Equal(tt, template, request.RefreshTemplate)
+
+		// credentials are not issued again past the fixed expiry of the template
+		expiry := now.Add(-time.Minute)
+		template.Expiry = issuing.TimeLike{Time: &expiry}
+		_, err = refresh.refreshRequest(now)
+		assert.ErrorContains(tt, err, "has passed")
+	})
+}
+
+func TestCheckRefreshRequestClaims(t *testing.T) {
+	now := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	serviceID := "https://ssi-service.com/v1/credentials/refresh"
+	newToken := func(tt *testing.T, claims map[string]any) jwt.Token {
+		token := jwt.New()
+		for k, v := range claims {
+			require.NoError(tt, token.Set(k, v))
+		}
+		return token
+	}
+
+	t.Run("accepts recent requests issued for the refresh service", func(tt *testing.T) {
+		assert.NoError(tt, checkRefreshRequestClaims(newToken(tt, map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now.Add(-time.Minute)}), serviceID, now))
+		assert.NoError(tt, checkRefreshRequestClaims(newToken(tt, map[string]any{jwt.AudienceKey: []string{"other", serviceID}, jwt.IssuedAtKey: now, jwt.ExpirationKey: now.Add(time.Minute)}), serviceID, now))
+	})
+
+	t.Run("rejects requests which are not recent or not issued for the refresh service", func(tt *testing.T) {
+		for _, test := range []struct {
+			claims   map[string]any
+			expected string
+		}{
+			{claims: map[string]any{jwt.IssuedAtKey: now}, expected: "is not issued for audience"},
+			{claims: map[string]any{jwt.AudienceKey: "https://other.com/v1/credentials/refresh", jwt.IssuedAtKey: now}, expected: "is not issued for audience"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID}, expected: "has no iat claim"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.ExpirationKey: now.Add(time.Minute)}, expected: "has no iat claim"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now.Add(-RefreshRequestMaxAge - time.Second)}, expected: "was issued more than"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now.Add(time.Hour)}, expected: "is issued in the future"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now.Add(-time.Minute), jwt.ExpirationKey: now.Add(-time.Hour)}, expected: "has expired"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now, jwt.NotBeforeKey: now.Add(time.Hour)}, expected: "is not valid yet"},
+			// a far future expiry does not let a request be replayed once it is no longer recent
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now, jwt.ExpirationKey: now.Add(100 * 365 * 24 * time.Hour)}, expected: "is valid for more than"},
+			{claims: map[string]any{jwt.AudienceKey: serviceID, jwt.IssuedAtKey: now.Add(-time.Hour), jwt.ExpirationKey: now.Add(100 * 365 * 24 * time.Hour)}, expected: "was issued more than"},
+		} {
+			assert.ErrorContains(tt, checkRefreshRequestClaims(newToken(tt, test.claims), serviceID, now), test.expected)
+		}
+	})
+}
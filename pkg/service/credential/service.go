@@ -3,12 +3,16 @@ package credential
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
 	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/google/uuid"
@@ -21,14 +25,21 @@ import (
 	"github.com/tbd54566975/ssi-service/internal/util"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 type Service struct {
-	storage  *Storage
-	config   config.CredentialServiceConfig
-	verifier *credint.Verifier
+	storage    *Storage
+	opsStorage *operation.Storage
+	config     config.CredentialServiceConfig
+	verifier   *credint.Verifier
+	// policies are the verification policies defined in the config, by name
+	policies map[string]credint.Policy
+	// statusLists resolves the status lists issued by the service, and caches those of other issuers
+	statusLists *credint.CachingStatusListResolver
 
 	// external dependencies
 	keyStore *keystore.Service
@@ -44,6 +55,9 @@ func (s Service) Status() framework.Status {
 	if s.storage == nil {
 		ae.AppendString("no storage configured")
 	}
+	if s.opsStorage == nil {
+		ae.AppendString("no operation storage configured")
+	}
 	if s.verifier == nil {
 		ae.AppendString("no credential verifier configured")
 	}
@@ -71,20 +85,37 @@ func NewCredentialService(config config.CredentialServiceConfig, s storage.Servi
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the credential service")
 	}
-	verifier, err := credint.NewCredentialVerifier(didResolver, schema)
+	opsStorage, err := operation.NewOperationStorage(s)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate verifier for the credential service")
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the operations")
 	}
 	service := Service{
-		storage:  credentialStorage,
-		config:   config,
-		verifier: verifier,
-		keyStore: keyStore,
-		schema:   schema,
+		storage:    credentialStorage,
+		opsStorage: opsStorage,
+		config:     config,
+		keyStore:   keyStore,
+		schema:     schema,
+	}
+	// the status lists issued by the service are resolved from its storage
+	var statusListClient *http.Client
+	if config.AllowPrivateStatusListURLs {
+		statusListClient = &http.Client{Timeout: credint.StatusListFetchTimeout}
+	}
+	service.statusLists = credint.NewCachingStatusListResolver(service, statusListClient)
+	verifier, err := credint.NewCredentialVerifier(didResolver, schema, service.statusLists)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate verifier for the credential service")
 	}
+	service.verifier = verifier
 	if !service.Status().IsReady() {
 		return nil, errors.New(service.Status().Message)
 	}
+	if config.StatusListSize < 0 {
+		return nil, sdkutil.LoggingNewErrorf("status list size<%d> cannot be negative", config.StatusListSize)
+	}
+	if service.policies, err = configuredPolicies(config.VerificationPolicies); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid verification policies")
+	}
 	return &service, nil
 }
 
@@ -94,22 +125,11 @@ func (s Service) CreateCredential(ctx context.Context, request CreateCredentialR
 	var slcMetadata StatusListCredentialMetadata
 
 	if request.hasStatus() && request.isStatusValid() {
-
-		statusPurpose := statussdk.StatusRevocation
-
-		if request.Suspendable {
-			statusPurpose = statussdk.StatusSuspension
-		}
-
-		statusListCredentialWatchKey := s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
-		statusListCredentialIndexPoolWatchKey := s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
-		statusListCredentialCurrentIndexWatchKey := s.storage.GetStatusListCurrentIndexWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
-
-		watchKeys = append(watchKeys, statusListCredentialWatchKey)
-		watchKeys = append(watchKeys, statusListCredentialIndexPoolWatchKey)
-		watchKeys = append(watchKeys, statusListCredentialCurrentIndexWatchKey)
-
-		slcMetadata = StatusListCredentialMetadata{statusListCredentialWatchKey: statusListCredentialWatchKey, statusListIndexPoolWatchKey: statusListCredentialIndexPoolWatchKey, statusListCurrentIndexWatchKey: statusListCredentialCurrentIndexWatchKey}
+		slcMetadata = s.statusListCredentialMetadata(request)
+		watchKeys = append(watchKeys, slcMetadata.watchKeys()...)
+	}
+	if request.ID != "" {
+		watchKeys = append(watchKeys, s.storage.GetCredentialIDWatchKey(request.ID))
 	}
 
 	returnFunc := s.createCredentialFunc(request, slcMetadata)
@@ -127,21 +147,139 @@ func (s Service) CreateCredential(ctx context.Context, request CreateCredentialR
 	return credResponse, nil
 }
 
+func (s Service) statusListSize() int {
+	if s.config.StatusListSize > 0 {
+		return s.config.StatusListSize
+	}
+	return defaultStatusListSize
+}
+
+// issuesDataModelV2 returns whether the issuer is configured to issue VC data model 2.0 credentials by default.
+func (s Service) issuesDataModelV2(issuer string) bool {
+	for _, v2Issuer := range s.config.DataModelV2Issuers {
+		if v2Issuer == issuer {
+			return true
+		}
+	}
+	return false
+}
+
+// statusListCredentialMetadata returns the metadata of the status list of the credential of the request.
+func (s Service) statusListCredentialMetadata(request CreateCredentialRequest) StatusListCredentialMetadata {
+	statusPurpose := statussdk.StatusRevocation
+
+	if request.Suspendable {
+		statusPurpose = statussdk.StatusSuspension
+	}
+
+	return StatusListCredentialMetadata{
+		statusListCredentialWatchKey:   s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statusPurpose)),
+		statusListIndexPoolWatchKey:    s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, request.SchemaID, string(statusPurpose)),
+		statusListCurrentIndexWatchKey: s.storage.GetStatusListCurrentIndexWatchKey(request.Issuer, request.SchemaID, string(statusPurpose)),
+	}
+}
+
 func (s Service) createCredentialFunc(request CreateCredentialRequest, slcMetadata StatusListCredentialMetadata) storage.BusinessLogicFunc {
 	return func(ctx context.Context, tx storage.Tx) (any, error) {
-		return s.createCredentialBusinessLogic(ctx, request, tx, slcMetadata)
+		return s.createCredentialBusinessLogic(ctx, request, tx, slcMetadata, "")
 	}
 }
 
-func (s Service) createCredentialBusinessLogic(ctx context.Context, request CreateCredentialRequest, tx storage.Tx, slcMetadata StatusListCredentialMetadata) (*CreateCredentialResponse, error) {
+// createCredentialBusinessLogic creates the credential of the request, which is issued in place of the refreshed
+// credential with the id refreshedFrom when it is set.
+func (s Service) createCredentialBusinessLogic(ctx context.Context, request CreateCredentialRequest, tx storage.Tx, slcMetadata StatusListCredentialMetadata, refreshedFrom string) (*CreateCredentialResponse, error) {
 	logrus.Debugf("creating credential: %+v", request)
 
+	prepared, err := s.prepareCredential(ctx, request, make(schemaCache))
+	if err != nil {
+		return nil, err
+	}
+
+	if request.hasStatus() {
+		allocations, err := s.allocateStatusListIndexes(ctx, tx, prepared, slcMetadata, 1)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.setCredentialStatus(ctx, prepared, allocations[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	container, err := s.signPreparedCredential(ctx, prepared)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialStorageRequest := StoreCredentialRequest{
+		Container: *container,
+	}
+
+	if err = s.storage.StoreNewCredentialTx(ctx, tx, credentialStorageRequest); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "saving credential")
+	}
+	if err = s.storeRefreshTx(ctx, tx, request, *container, refreshedFrom); err != nil {
+		return nil, err
+	}
+
+	response := CreateCredentialResponse{Container: *container}
+	return &response, nil
+}
+
+// preparedCredential is a credential being created, built from its request up to its status, which is only set once
+// an index of its status list is allocated.
+type preparedCredential struct {
+	request     CreateCredentialRequest
+	format      exchange.CredentialFormat
+	proofType   cryptosuite.SignatureType
+	version     credint.DataModelVersion
+	builder     credential.VerifiableCredentialBuilder
+	knownSchema *schemalib.VCJSONSchema
+}
+
+// schemaCache holds the schemas resolved while preparing credentials, by id.
+type schemaCache map[string]*schemalib.VCJSONSchema
+
+// prepareCredential builds the credential of the request, without its status. Schemas are resolved through the cache.
+func (s Service) prepareCredential(ctx context.Context, request CreateCredentialRequest, schemas schemaCache) (*preparedCredential, error) {
 	if !request.isStatusValid() {
 		return nil, sdkutil.LoggingNewError("credential may have at most one status")
 	}
+	format, err := request.format()
+	if err != nil {
+		return nil, sdkutil.LoggingError(err)
+	}
+	if err = request.validateProperties(); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid credential properties")
+	}
+	proofType, err := request.proofType(format)
+	if err != nil {
+		return nil, sdkutil.LoggingError(err)
+	}
+	version, err := request.dataModelVersion(format, s.issuesDataModelV2(request.Issuer))
+	if err != nil {
+		return nil, sdkutil.LoggingError(err)
+	}
 
 	builder := credential.NewVerifiableCredentialBuilder()
 
+	if request.ID != "" {
+		exists, err := s.storage.CredentialExists(ctx, request.ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, sdkutil.LoggingNewErrorf("credential with id<%s> already exists", request.ID)
+		}
+		if err = builder.SetID(request.ID); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not set credential id")
+		}
+	} else if format == exchange.LDPVC.CredentialFormat() {
+		// a data integrity proof only covers the statements about absolute IRIs, which a bare uuid is not
+		if err = builder.SetID("urn:uuid:" + builder.ID); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not set credential id")
+		}
+	}
+
 	if err := builder.SetIssuer(request.Issuer); err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not build credential when setting issuer: %s", request.Issuer)
 	}
@@ -159,10 +297,29 @@ func (s Service) createCredentialBusinessLogic(ctx context.Context, request Crea
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not set subject: %+v", subject)
 	}
 
-	// if a context value exists, set it
-	if request.Context != "" {
+	// if context values exist, add them
+	if request.Context != nil {
 		if err := builder.AddContext(request.Context); err != nil {
-			return nil, sdkutil.LoggingErrorMsgf(err, "could not add context to credential: %s", request.Context)
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not add context to credential: %v", request.Context)
+		}
+	}
+
+	// if type values exist, add them
+	if request.Type != nil {
+		if err := builder.AddType(request.Type); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not add type to credential: %v", request.Type)
+		}
+	}
+
+	if len(request.Evidence) > 0 {
+		if err := builder.SetEvidence(request.Evidence); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not set evidence for credential")
+		}
+	}
+
+	if len(request.TermsOfUse) > 0 {
+		if err := builder.SetTermsOfUse(request.TermsOfUse); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not set terms of use for credential")
 		}
 	}
 
@@ -170,11 +327,15 @@ func (s Service) createCredentialBusinessLogic(ctx context.Context, request Crea
 	var knownSchema *schemalib.VCJSONSchema
 	if request.SchemaID != "" {
 		// resolve schema and save it for validation later
-		gotSchema, err := s.schema.GetSchema(ctx, schema.GetSchemaRequest{ID: request.SchemaID})
-		if err != nil {
-			return nil, sdkutil.LoggingErrorMsgf(err, "failed to create credential; could not get schema: %s", request.SchemaID)
+		knownSchema = schemas[request.SchemaID]
+		if knownSchema == nil {
+			gotSchema, err := s.schema.GetSchema(ctx, schema.GetSchemaRequest{ID: request.SchemaID})
+			if err != nil {
+				return nil, sdkutil.LoggingErrorMsgf(err, "failed to create credential; could not get schema: %s", request.SchemaID)
+			}
+			knownSchema = &gotSchema.Schema
+			schemas[request.SchemaID] = knownSchema
 		}
-		knownSchema = &gotSchema.Schema
 
 		credSchema := credential.CredentialSchema{
 			ID:   request.SchemaID,
@@ -192,117 +353,132 @@ func (s Service) createCredentialBusinessLogic(ctx context.Context, request Crea
 		}
 	}
 
+	if request.Refreshable {
+		refreshService := credential.RefreshService{ID: s.refreshServiceID(ctx), Type: RefreshServiceType}
+		if err := builder.SetRefreshService(refreshService); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not set refresh service for credential")
+		}
+	}
+
 	if err := builder.SetIssuanceDate(time.Now().Format(time.RFC3339)); err != nil {
 		errMsg := fmt.Sprintf("could not set credential issuance date")
 		return nil, sdkutil.LoggingErrorMsg(err, errMsg)
 	}
 
-	if request.hasStatus() {
-		credID := builder.ID
-		issuerID := request.Issuer
-		issuerKID := request.IssuerKID
-		schemaID := request.SchemaID
-
-		statusPurpose := statussdk.StatusRevocation
-
-		if request.Suspendable {
-			statusPurpose = statussdk.StatusSuspension
-		}
+	return &preparedCredential{request: request, format: format, proofType: proofType, version: version, builder: builder, knownSchema: knownSchema}, nil
+}
 
-		var slCredential *credential.VerifiableCredential
-		var statusListCredentialID string
-		var randomIndex int
-		var err error
+// statusPurpose returns the purpose of the status list of the credential.
+func (p preparedCredential) statusPurpose() statussdk.StatusPurpose {
+	if p.request.Suspendable {
+		return statussdk.StatusSuspension
+	}
+	return statussdk.StatusRevocation
+}
 
-		statusListCredential, err := s.storage.GetStatusListCredentialKeyData(
-			ctx,
-			issuerID,
-			schemaID,
-			statusPurpose,
-		)
-		if err != nil {
-			return nil, errors.Wrap(err, "getting status list credential key data")
-		}
+// statusListAllocation is an index of a status list credential reserved for the status of a credential.
+type statusListAllocation struct {
+	statusListCredentialID string
+	index                  int
+}
 
-		if statusListCredential == nil {
-			// creates status list credential with random index
-			randomIndex, slCredential, err = createStatusListCredential(ctx, tx, s, statusPurpose, issuerID, issuerKID, schemaID, slcMetadata)
-			if err != nil {
-				return nil, sdkutil.LoggingErrorMsgf(err, "problem with getting status list credential")
-			}
+// allocateStatusListIndexes reserves count indexes of the status list described by the metadata, which is that of the
+// prepared credential. A new status list credential is created whenever the current one is full, or when none exists
+// yet, so the indexes may belong to several status lists.
+func (s Service) allocateStatusListIndexes(ctx context.Context, tx storage.Tx, prepared *preparedCredential, slcMetadata StatusListCredentialMetadata, count int) ([]statusListAllocation, error) {
+	statusListCredential, err := s.storage.GetStatusListCredentialTx(ctx, tx, slcMetadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting status list credential")
+	}
+	var statusListCredentialID string
+	if statusListCredential != nil {
+		statusListCredentialID = statusListCredential.Credential.ID
+	}
 
-			statusListCredentialID = slCredential.ID
-		} else {
-			randomIndex, err = s.storage.GetNextStatusListRandomIndex(ctx, slcMetadata)
+	allocations := make([]statusListAllocation, 0, count)
+	for {
+		if statusListCredentialID != "" {
+			indexes, err := s.storage.AllocateStatusListIndexesTx(ctx, tx, slcMetadata, count-len(allocations))
 			if err != nil {
 				return nil, sdkutil.LoggingErrorMsg(err, "problem with getting status list index")
 			}
-
-			statusListCredentialID = statusListCredential.Credential.ID
-
-			if err := s.storage.IncrementStatusListIndexTx(ctx, tx, slcMetadata); err != nil {
-				return nil, errors.Wrap(err, "incrementing status list index")
+			for _, index := range indexes {
+				allocations = append(allocations, statusListAllocation{statusListCredentialID: statusListCredentialID, index: index})
 			}
 		}
-
-		status := statussdk.StatusList2021Entry{
-			ID:                   fmt.Sprintf(`%s/v1/credentials/%s/status`, s.config.ServiceEndpoint, credID),
-			Type:                 statussdk.StatusList2021EntryType,
-			StatusPurpose:        statusPurpose,
-			StatusListIndex:      strconv.Itoa(randomIndex),
-			StatusListCredential: statusListCredentialID,
+		if len(allocations) == count {
+			return allocations, nil
 		}
 
-		if err := builder.SetCredentialStatus(status); err != nil {
-			return nil, sdkutil.LoggingErrorMsg(err, "could not set credential status")
+		// the status list is full, or does not exist yet, so a new one is created with a random index
+		// a status list has no claims worth withholding, so it is not issued as an sd-jwt
+		statusListFormat := prepared.format
+		if prepared.format == credint.SDJWTVC {
+			statusListFormat = exchange.JWTVC.CredentialFormat()
+		}
+		request := prepared.request
+		randomIndex, slCredential, err := createStatusListCredential(ctx, tx, s, prepared.statusPurpose(), request.Issuer, request.IssuerKID, request.SchemaID, statusListFormat, prepared.proofType, slcMetadata)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "problem with getting status list credential")
 		}
+		statusListCredentialID = slCredential.ID
+		allocations = append(allocations, statusListAllocation{statusListCredentialID: statusListCredentialID, index: randomIndex})
 	}
+}
 
-	cred, err := builder.Build()
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not build credential")
+// setCredentialStatus sets the status of the prepared credential to the allocated index of its status list.
+func (s Service) setCredentialStatus(ctx context.Context, prepared *preparedCredential, allocation statusListAllocation) error {
+	status := statussdk.StatusList2021Entry{
+		ID:                   fmt.Sprintf(`%s/v1/credentials/%s/status`, tenant.ServiceEndpoint(ctx, s.config.ServiceEndpoint), prepared.builder.ID),
+		Type:                 statussdk.StatusList2021EntryType,
+		StatusPurpose:        prepared.statusPurpose(),
+		StatusListIndex:      strconv.Itoa(allocation.index),
+		StatusListCredential: allocation.statusListCredentialID,
 	}
 
-	// verify the built schema complies with the schema we've set
-	if knownSchema != nil {
-		if err = schemalib.IsCredentialValidForVCJSONSchema(*cred, *knownSchema); err != nil {
-			return nil, sdkutil.LoggingErrorMsgf(err, "credential data does not comply with the provided schema: %s", request.SchemaID)
+	if err := prepared.builder.SetCredentialStatus(status); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not set credential status")
+	}
+	// the status entry is only covered by a data integrity proof when its terms are defined
+	if prepared.format == exchange.LDPVC.CredentialFormat() {
+		if err := prepared.builder.AddContext(statussdk.StatusList2021Context); err != nil {
+			return sdkutil.LoggingErrorMsg(err, "could not add status list context to credential")
 		}
 	}
+	return nil
+}
 
-	// TODO(gabe) support Data Integrity creds too https://github.com/TBD54566975/ssi-service/issues/105
-	credCopy, err := credint.CopyCredential(*cred)
+// signPreparedCredential builds the prepared credential, checks it against its schema, and signs it.
+func (s Service) signPreparedCredential(ctx context.Context, prepared *preparedCredential) (*credint.Container, error) {
+	cred, err := prepared.builder.Build()
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not copy credential")
-	}
-	credJWT, err := s.signCredentialJWT(ctx, request.IssuerKID, *credCopy)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "signing credential")
+		return nil, sdkutil.LoggingErrorMsg(err, "could not build credential")
 	}
-
-	container := credint.Container{
-		ID:            cred.ID,
-		IssuerKID:     request.IssuerKID,
-		Credential:    cred,
-		CredentialJWT: credJWT,
-		Revoked:       false,
-		Suspended:     false,
+	if prepared.version == credint.DataModelV2 {
+		if err = credint.ToV2(cred); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not make credential a data model 2.0 credential")
+		}
 	}
 
-	credentialStorageRequest := StoreCredentialRequest{
-		Container: container,
+	// verify the built schema complies with the schema we've set
+	if prepared.knownSchema != nil {
+		if err = schemalib.IsCredentialValidForVCJSONSchema(*cred, *prepared.knownSchema); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "credential data does not comply with the provided schema: %s", prepared.request.SchemaID)
+		}
 	}
 
-	if err = s.storage.StoreCredentialTx(ctx, tx, credentialStorageRequest); err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "saving credential")
+	container, err := s.signCredential(ctx, prepared.request.IssuerKID, *cred, prepared.format, prepared.proofType, prepared.request.DisclosableClaims...)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "signing credential")
 	}
-
-	response := CreateCredentialResponse{Container: container}
-	return &response, nil
+	return container, nil
 }
 
-func createStatusListCredential(ctx context.Context, tx storage.Tx, s Service, statusPurpose statussdk.StatusPurpose, issuerID, issuerKID, schemaID string, slcMetadata StatusListCredentialMetadata) (int, *credential.VerifiableCredential, error) {
-	statusListID := fmt.Sprintf("%s/v1/credentials/status/%s", s.config.ServiceEndpoint, uuid.NewString())
+// createStatusListCredential creates the status list credential of the issuer, schema and purpose of the metadata,
+// signed in the format, and with the proof type, of the credential which first needs it, returning the index allocated to that credential. The
+// status list has as many indexes as configured when it is created.
+func createStatusListCredential(ctx context.Context, tx storage.Tx, s Service, statusPurpose statussdk.StatusPurpose, issuerID, issuerKID, schemaID string, format exchange.CredentialFormat, proofType cryptosuite.SignatureType, slcMetadata StatusListCredentialMetadata) (int, *credential.VerifiableCredential, error) {
+	statusListID := fmt.Sprintf("%s/v1/credentials/status/%s", tenant.ServiceEndpoint(ctx, s.config.ServiceEndpoint), uuid.NewString())
 
 	generatedStatusListCredential, err := statussdk.GenerateStatusList2021Credential(statusListID, issuerID, statusPurpose, []credential.VerifiableCredential{})
 	if err != nil {
@@ -317,23 +493,16 @@ func createStatusListCredential(ctx context.Context, tx storage.Tx, s Service, s
 		generatedStatusListCredential.CredentialSchema = &credSchema
 	}
 
-	statusListCredJWT, err := s.signCredentialJWT(ctx, issuerKID, *generatedStatusListCredential)
+	statusListContainer, err := s.signCredential(ctx, issuerKID, *generatedStatusListCredential, format, proofType)
 	if err != nil {
 		return -1, nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
 	}
 
-	statusListContainer := credint.Container{
-		ID:            generatedStatusListCredential.ID,
-		IssuerKID:     issuerKID,
-		Credential:    generatedStatusListCredential,
-		CredentialJWT: statusListCredJWT,
-	}
-
 	statusListStorageRequest := StoreCredentialRequest{
-		Container: statusListContainer,
+		Container: *statusListContainer,
 	}
 
-	randomIndex, err := s.storage.CreateStatusListCredentialTx(ctx, tx, statusListStorageRequest, slcMetadata)
+	randomIndex, err := s.storage.CreateStatusListCredentialTx(ctx, tx, statusListStorageRequest, slcMetadata, s.statusListSize())
 	if err != nil {
 		return -1, nil, errors.Wrap(err, "creating status list credential")
 	}
@@ -341,8 +510,52 @@ func createStatusListCredential(ctx context.Context, tx storage.Tx, s Service, s
 	return randomIndex, generatedStatusListCredential, nil
 }
 
-// signCredentialJWT signs a credential and returns it as a vc-jwt
-func (s Service) signCredentialJWT(ctx context.Context, issuerKID string, cred credential.VerifiableCredential) (*keyaccess.JWT, error) {
+// signCredential signs a credential in the given format. The container of a vc-jwt or an sd-jwt holds both the token
+// and the credential it was built from, while the container of a data integrity credential holds the credential along
+// with its embedded proof, of the given type. The claims of the credential subject at the disclosable paths are only selectively
+// disclosable in an sd-jwt.
+func (s Service) signCredential(ctx context.Context, issuerKID string, cred credential.VerifiableCredential, format exchange.CredentialFormat, proofType cryptosuite.SignatureType, disclosablePaths ...string) (*credint.Container, error) {
+	container := credint.Container{
+		ID:        cred.ID,
+		IssuerKID: issuerKID,
+	}
+	switch format {
+	case exchange.JWTVC.CredentialFormat():
+		credCopy, err := credint.CopyCredential(cred)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not copy credential")
+		}
+		credJWT, err := s.signCredentialJWT(ctx, issuerKID, *credCopy)
+		if err != nil {
+			return nil, err
+		}
+		container.Credential = &cred
+		container.CredentialJWT = credJWT
+	case exchange.LDPVC.CredentialFormat():
+		signedCred, err := s.signCredentialDataIntegrity(ctx, issuerKID, cred, proofType)
+		if err != nil {
+			return nil, err
+		}
+		container.Credential = signedCred
+	case credint.SDJWTVC:
+		credCopy, err := credint.CopyCredential(cred)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not copy credential")
+		}
+		credSDJWT, err := s.signCredentialSDJWT(ctx, issuerKID, *credCopy, disclosablePaths)
+		if err != nil {
+			return nil, err
+		}
+		container.Credential = &cred
+		container.CredentialSDJWT = credSDJWT
+	default:
+		return nil, sdkutil.LoggingNewErrorf("unsupported credential format<%s>", format)
+	}
+	return &container, nil
+}
+
+// getSigningKey returns the key with the given ID, which must be controlled by the issuer of the credential.
+func (s Service) getSigningKey(ctx context.Context, issuerKID string, cred credential.VerifiableCredential) (*keystore.GetKeyResponse, error) {
 	gotKey, err := s.keyStore.GetKey(ctx, keystore.GetKeyRequest{ID: issuerKID})
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "getting key for signing credential<%s>", issuerKID)
@@ -350,10 +563,31 @@ func (s Service) signCredentialJWT(ctx context.Context, issuerKID string, cred c
 	if gotKey.Controller != cred.Issuer.(string) {
 		return nil, sdkutil.LoggingNewErrorf("key controller<%s> does not match credential issuer<%s> for key<%s>", gotKey.Controller, cred.Issuer, issuerKID)
 	}
+	return gotKey, nil
+}
+
+// signCredentialJWT signs a credential and returns it as a vc-jwt, or, for a VC data model 2.0 credential, as a JWT
+// whose claims set is the credential
+func (s Service) signCredentialJWT(ctx context.Context, issuerKID string, cred credential.VerifiableCredential) (*keyaccess.JWT, error) {
+	gotKey, err := s.getSigningKey(ctx, issuerKID, cred)
+	if err != nil {
+		return nil, err
+	}
 	keyAccess, err := keyaccess.NewJWKKeyAccess(issuerKID, gotKey.ID, gotKey.Key)
 	if err != nil {
 		return nil, errors.Wrapf(err, "creating key access for signing credential with key<%s>", gotKey.ID)
 	}
+	if credint.Version(cred) == credint.DataModelV2 {
+		credJSON, err := credint.V2JSON(cred)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not convert credential to JSON")
+		}
+		credToken, err := keyAccess.SignVerifiableCredentialV2(credJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not sign credential with key<%s>", gotKey.ID)
+		}
+		return credToken, nil
+	}
 	credToken, err := keyAccess.SignVerifiableCredential(cred)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not sign credential with key<%s>", gotKey.ID)
@@ -361,19 +595,100 @@ func (s Service) signCredentialJWT(ctx context.Context, issuerKID string, cred c
 	return credToken, nil
 }
 
+// signCredentialSDJWT signs a credential and returns it as an sd-jwt, along with the disclosures of the claims at the
+// given paths of its credential subject
+func (s Service) signCredentialSDJWT(ctx context.Context, issuerKID string, cred credential.VerifiableCredential, disclosablePaths []string) (*keyaccess.SDJWT, error) {
+	gotKey, err := s.getSigningKey(ctx, issuerKID, cred)
+	if err != nil {
+		return nil, err
+	}
+	keyAccess, err := keyaccess.NewJWKKeyAccess(issuerKID, gotKey.ID, gotKey.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating key access for signing credential with key<%s>", gotKey.ID)
+	}
+	credToken, err := keyAccess.SignVerifiableCredentialSDJWT(cred, disclosablePaths)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not sign credential with key<%s>", gotKey.ID)
+	}
+	return credToken, nil
+}
+
+// issuerDependentVocab is the vocabulary of the claims of data integrity credentials which their contexts do not
+// define, which is the vocabulary VC data model 2.0 credentials have such claims in.
+const issuerDependentVocab = "https://www.w3.org/ns/credentials/issuer-dependent#"
+
+// signCredentialDataIntegrity signs a copy of the credential with a proof of the given type, whose verification method
+// is the signing key. A proof only covers the claims defined by the contexts of the credential, so the copy has an
+// embedded context defining any other claim in the issuer dependent vocabulary.
+func (s Service) signCredentialDataIntegrity(ctx context.Context, issuerKID string, cred credential.VerifiableCredential, proofType cryptosuite.SignatureType) (*credential.VerifiableCredential, error) {
+	gotKey, err := s.getSigningKey(ctx, issuerKID, cred)
+	if err != nil {
+		return nil, err
+	}
+	keyAccess, err := keyaccess.NewDataIntegrityKeyAccess(gotKey.Controller, gotKey.ID, gotKey.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating key access for signing credential with key<%s>", gotKey.ID)
+	}
+	keyAccess.ProofType = proofType
+	credCopy, err := credint.CopyCredential(cred)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not copy credential")
+	}
+	contexts, err := sdkutil.InterfaceToInterfaceArray(credCopy.Context)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "malformed credential context")
+	}
+	if proofType == keyaccess.Ed25519Signature2020 && !hasContext(contexts, keyaccess.Ed25519Signature2020Context) {
+		// the terms of the proof are only defined by the context of its suite
+		contexts = append(contexts, keyaccess.Ed25519Signature2020Context)
+	}
+	credCopy.Context = append(contexts, map[string]any{"@vocab": issuerDependentVocab})
+	if _, err = keyAccess.Sign(credCopy); err != nil {
+		return nil, errors.Wrapf(err, "could not sign credential with key<%s>", gotKey.ID)
+	}
+	return credCopy, nil
+}
+
+// hasContext returns whether the contexts include the given context URI.
+func hasContext(contexts []any, context string) bool {
+	for _, c := range contexts {
+		if c == context {
+			return true
+		}
+	}
+	return false
+}
+
 type VerifyCredentialRequest struct {
 	DataIntegrityCredential *credential.VerifiableCredential `json:"credential,omitempty"`
 	CredentialJWT           *keyaccess.JWT                   `json:"credentialJwt,omitempty"`
+	// An SD-JWT along with the disclosures the holder chose to present and, optionally, a key binding JWT.
+	CredentialSDJWT *keyaccess.SDJWT `json:"credentialSdJwt,omitempty"`
+	// The audience and nonce the key binding JWT of the SD-JWT must have been issued for.
+	KeyBindingAudience string `json:"keyBindingAudience,omitempty"`
+	KeyBindingNonce    string `json:"keyBindingNonce,omitempty"`
+	// The name of the verification policy to verify the credential with. Every check runs when empty.
+	Policy string `json:"policy,omitempty"`
 }
 
-// IsValid checks if the request is valid, meaning there is at least one data integrity (with proof)
-// OR jwt credential, but not both
+// IsValid checks if the request is valid, meaning there is exactly one of a data integrity (with proof), a jwt or an
+// sd-jwt credential
 func (vcr VerifyCredentialRequest) IsValid() error {
-	if vcr.DataIntegrityCredential == nil && vcr.CredentialJWT == nil {
-		return errors.New("either a credential or a credential JWT must be provided")
+	provided := 0
+	if vcr.DataIntegrityCredential != nil && vcr.DataIntegrityCredential.Proof != nil {
+		provided++
+	}
+	if vcr.CredentialJWT != nil {
+		provided++
+	}
+	if vcr.CredentialSDJWT != nil {
+		provided++
+	}
+	if provided == 0 && vcr.DataIntegrityCredential == nil {
+		return errors.New("either a credential, a credential JWT or a credential SD-JWT must be provided")
 	}
-	if (vcr.DataIntegrityCredential != nil && vcr.DataIntegrityCredential.Proof != nil) && vcr.CredentialJWT != nil {
-		return errors.New("only one of credential or credential JWT can be provided")
+	if provided > 1 {
+		return errors.New("only one of credential, credential JWT or credential SD-JWT can be provided")
 	}
 	return nil
 }
@@ -381,14 +696,21 @@ func (vcr VerifyCredentialRequest) IsValid() error {
 type VerifyCredentialResponse struct {
 	Verified bool   `json:"verified"`
 	Reason   string `json:"reason,omitempty"`
+	// The checks run on the credential, in the order they ran, with their outcome.
+	Checks []credint.CheckResult `json:"checks,omitempty"`
+	// Whether the credential was revoked or suspended by its issuer, as held by its status list.
+	Revoked   bool `json:"revoked,omitempty"`
+	Suspended bool `json:"suspended,omitempty"`
 }
 
-// VerifyCredential does three levels of verification on a credential:
-// 1. Makes sure the credential has a valid signature
-// 2. Makes sure the credential has is not expired
+// VerifyCredential does several levels of verification on a credential, and reports the outcome of each:
+// 1. Makes sure the credential has a valid signature, by a key of its issuer meant for issuing credentials
+// 2. Makes sure the credential has is not expired, and is already valid
 // 3. Makes sure the credential complies with the VC Data Model
 // 4. If the credential has a schema, makes sure its data complies with the schema
-// LATER: Makes sure the credential has not been revoked, other checks.
+// 5. If the credential has a status list entry, makes sure it has not been revoked or suspended
+// The checks run, and what they accept, are as per the policy named by the request, if any.
+// LATER: other checks.
 // Note: https://github.com/TBD54566975/ssi-sdk/issues/213
 func (s Service) VerifyCredential(ctx context.Context, request VerifyCredentialRequest) (*VerifyCredentialResponse, error) {
 
@@ -398,18 +720,61 @@ func (s Service) VerifyCredential(ctx context.Context, request VerifyCredentialR
 		return nil, sdkutil.LoggingErrorMsg(err, "invalid verify credential request")
 	}
 
-	if request.CredentialJWT != nil {
-		err := s.verifier.VerifyJWTCredential(ctx, *request.CredentialJWT)
+	var policy credint.Policy
+	if request.Policy != "" {
+		resolved, err := s.ResolvePolicy(ctx, request.Policy)
 		if err != nil {
-			return &VerifyCredentialResponse{Verified: false, Reason: err.Error()}, nil
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not resolve verification policy: %s", request.Policy)
 		}
+		policy = *resolved
+	}
+
+	var report *credint.VerificationReport
+	if request.CredentialSDJWT != nil {
+		report = s.verifier.VerifySDJWTCredential(ctx, *request.CredentialSDJWT, policy, request.KeyBindingAudience, request.KeyBindingNonce)
+	} else if request.CredentialJWT != nil {
+		report = s.verifier.VerifyJWTCredential(ctx, *request.CredentialJWT, policy)
 	} else {
-		if err := s.verifier.VerifyDataIntegrityCredential(ctx, *request.DataIntegrityCredential); err != nil {
-			return &VerifyCredentialResponse{Verified: false, Reason: err.Error()}, nil
-		}
+		report = s.verifier.VerifyDataIntegrityCredential(ctx, *request.DataIntegrityCredential, policy)
 	}
+	response := VerifyCredentialResponse{Verified: report.Verified, Reason: report.Reason(), Checks: report.Checks}
+	var statusErr credint.StatusError
+	if errors.As(report.Err(), &statusErr) {
+		response.Revoked = statusErr.Revoked
+		response.Suspended = statusErr.Suspended
+	}
+	return &response, nil
+}
+
+// StatusListResolver returns the resolver the service checks the statuses of credentials with, so that other verifiers
+// may share its cache of the status lists of other issuers.
+func (s Service) StatusListResolver() *credint.CachingStatusListResolver {
+	return s.statusLists
+}
 
-	return &VerifyCredentialResponse{Verified: true}, nil
+// RefreshStatusLists fetches the cached status lists of other issuers again, before they expire.
+func (s Service) RefreshStatusLists(ctx context.Context) error {
+	return s.statusLists.Refresh(ctx)
+}
+
+// ResolveStatusList returns the status list credential issued by the service with the given id, or
+// credint.ErrStatusListNotFound when the service did not issue it.
+func (s Service) ResolveStatusList(ctx context.Context, id string) (*credint.Container, error) {
+	gotCred, err := s.storage.GetStatusListCredential(ctx, ExtractID(id))
+	if err != nil {
+		if strings.Contains(err.Error(), credentialNotFoundErrMsg) {
+			return nil, credint.ErrStatusListNotFound
+		}
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get status list credential: %s", id)
+	}
+	if gotCred.CredentialID != id {
+		return nil, credint.ErrStatusListNotFound
+	}
+	return &credint.Container{
+		ID:            gotCred.CredentialID,
+		Credential:    gotCred.Credential,
+		CredentialJWT: gotCred.CredentialJWT,
+	}, nil
 }
 
 func (s Service) GetCredential(ctx context.Context, request GetCredentialRequest) (*GetCredentialResponse, error) {
@@ -425,9 +790,10 @@ func (s Service) GetCredential(ctx context.Context, request GetCredentialRequest
 	}
 	response := GetCredentialResponse{
 		credint.Container{
-			ID:            gotCred.CredentialID,
-			Credential:    gotCred.Credential,
-			CredentialJWT: gotCred.CredentialJWT,
+			ID:              gotCred.CredentialID,
+			Credential:      gotCred.Credential,
+			CredentialJWT:   gotCred.CredentialJWT,
+			CredentialSDJWT: gotCred.CredentialSDJWT,
 		},
 	}
 	return &response, nil
@@ -437,7 +803,7 @@ func (s Service) GetCredentialsByIssuer(ctx context.Context, request GetCredenti
 
 	logrus.Debugf("getting credential(s) for issuer: %s", util.SanitizeLog(request.Issuer))
 
-	gotCreds, err := s.storage.GetCredentialsByIssuer(ctx, request.Issuer)
+	gotCreds, nextPageToken, err := s.storage.GetCredentialsByIssuer(ctx, request.Issuer, request.PageRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential(s) for issuer: %s", request.Issuer)
 	}
@@ -445,14 +811,15 @@ func (s Service) GetCredentialsByIssuer(ctx context.Context, request GetCredenti
 	creds := make([]credint.Container, 0, len(gotCreds))
 	for _, cred := range gotCreds {
 		container := credint.Container{
-			ID:            cred.CredentialID,
-			Credential:    cred.Credential,
-			CredentialJWT: cred.CredentialJWT,
+			ID:              cred.CredentialID,
+			Credential:      cred.Credential,
+			CredentialJWT:   cred.CredentialJWT,
+			CredentialSDJWT: cred.CredentialSDJWT,
 		}
 		creds = append(creds, container)
 	}
 
-	response := GetCredentialsResponse{Credentials: creds}
+	response := GetCredentialsResponse{Credentials: creds, NextPageToken: nextPageToken}
 	return &response, nil
 }
 
@@ -460,7 +827,7 @@ func (s Service) GetCredentialsBySubject(ctx context.Context, request GetCredent
 
 	logrus.Debugf("getting credential(s) for subject: %s", util.SanitizeLog(request.Subject))
 
-	gotCreds, err := s.storage.GetCredentialsBySubject(ctx, request.Subject)
+	gotCreds, nextPageToken, err := s.storage.GetCredentialsBySubject(ctx, request.Subject, request.PageRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential(s) for subject: %s", request.Subject)
 	}
@@ -468,13 +835,14 @@ func (s Service) GetCredentialsBySubject(ctx context.Context, request GetCredent
 	creds := make([]credint.Container, 0, len(gotCreds))
 	for _, cred := range gotCreds {
 		container := credint.Container{
-			ID:            cred.CredentialID,
-			Credential:    cred.Credential,
-			CredentialJWT: cred.CredentialJWT,
+			ID:              cred.CredentialID,
+			Credential:      cred.Credential,
+			CredentialJWT:   cred.CredentialJWT,
+			CredentialSDJWT: cred.CredentialSDJWT,
 		}
 		creds = append(creds, container)
 	}
-	response := GetCredentialsResponse{Credentials: creds}
+	response := GetCredentialsResponse{Credentials: creds, NextPageToken: nextPageToken}
 	return &response, nil
 }
 
@@ -482,7 +850,7 @@ func (s Service) GetCredentialsBySchema(ctx context.Context, request GetCredenti
 
 	logrus.Debugf("getting credential(s) for schema: %s", util.SanitizeLog(request.Schema))
 
-	gotCreds, err := s.storage.GetCredentialsBySchema(ctx, request.Schema)
+	gotCreds, nextPageToken, err := s.storage.GetCredentialsBySchema(ctx, request.Schema, request.PageRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential(s) for schema: %s", request.Schema)
 	}
@@ -490,13 +858,44 @@ func (s Service) GetCredentialsBySchema(ctx context.Context, request GetCredenti
 	creds := make([]credint.Container, 0, len(gotCreds))
 	for _, cred := range gotCreds {
 		container := credint.Container{
-			ID:            cred.CredentialID,
-			Credential:    cred.Credential,
-			CredentialJWT: cred.CredentialJWT,
+			ID:              cred.CredentialID,
+			Credential:      cred.Credential,
+			CredentialJWT:   cred.CredentialJWT,
+			CredentialSDJWT: cred.CredentialSDJWT,
+		}
+		creds = append(creds, container)
+	}
+	response := GetCredentialsResponse{Credentials: creds, NextPageToken: nextPageToken}
+	return &response, nil
+}
+
+// ListCredentialsOrderByFields are the fields credentials can be listed in the order of.
+var ListCredentialsOrderByFields = []string{"issuer", "subject", "schema", "issuanceDate", "expiry"}
+
+// ListCredentials returns the credentials that match the filter of the request, in the requested order.
+func (s Service) ListCredentials(ctx context.Context, request ListCredentialsRequest) (*GetCredentialsResponse, error) {
+	logrus.Debug("listing credentials")
+
+	if err := request.OrderBy.ValidateForPaths(ListCredentialsOrderByFields...); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid order of credentials")
+	}
+
+	gotCreds, nextPageToken, err := s.storage.ListCredentials(ctx, request.Filter, request.OrderBy, request.PageRequest)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not list credentials")
+	}
+
+	creds := make([]credint.Container, 0, len(gotCreds))
+	for _, cred := range gotCreds {
+		container := credint.Container{
+			ID:              cred.CredentialID,
+			Credential:      cred.Credential,
+			CredentialJWT:   cred.CredentialJWT,
+			CredentialSDJWT: cred.CredentialSDJWT,
 		}
 		creds = append(creds, container)
 	}
-	response := GetCredentialsResponse{Credentials: creds}
+	response := GetCredentialsResponse{Credentials: creds, NextPageToken: nextPageToken}
 	return &response, nil
 }
 
@@ -511,7 +910,12 @@ func (s Service) GetCredentialStatus(ctx context.Context, request GetCredentialS
 		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
 	}
 	response := GetCredentialStatusResponse{
-		Revoked: gotCred.Revoked,
+		Revoked:                gotCred.Revoked,
+		Suspended:              gotCred.Suspended,
+		SuspendedUntil:         gotCred.SuspendedUntil,
+		Expired:                gotCred.Expired,
+		StatusListCredentialID: gotCred.StatusListCredentialID,
+		StatusListIndex:        gotCred.StatusListIndex,
 	}
 	return &response, nil
 }
@@ -528,175 +932,51 @@ func (s Service) GetCredentialStatusList(ctx context.Context, request GetCredent
 	}
 	response := GetCredentialStatusListResponse{
 		credint.Container{
-			ID:            gotCred.CredentialID,
-			Credential:    gotCred.Credential,
-			CredentialJWT: gotCred.CredentialJWT,
+			ID:              gotCred.CredentialID,
+			Credential:      gotCred.Credential,
+			CredentialJWT:   gotCred.CredentialJWT,
+			CredentialSDJWT: gotCred.CredentialSDJWT,
 		},
 	}
 	return &response, nil
 }
 
+// UpdateCredentialStatus updates the status of the credential, setting its bit in its status list, which is signed
+// again.
 func (s Service) UpdateCredentialStatus(ctx context.Context, request UpdateCredentialStatusRequest) (*UpdateCredentialStatusResponse, error) {
-	gotCred, err := s.storage.GetCredential(ctx, request.ID)
+	update, err := s.prepareCredentialStatusUpdate(ctx, request)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+		return nil, err
 	}
 
-	statusPurpose := gotCred.Credential.CredentialStatus.(map[string]any)["statusPurpose"].(string)
-	if len(statusPurpose) == 0 {
-		return nil, sdkutil.LoggingNewErrorf("status purpose could not be derived from credential status")
-	}
-
-	statusListCredential, err := s.storage.GetStatusListCredentialKeyData(ctx, gotCred.Issuer, gotCred.Schema, statussdk.StatusPurpose(statusPurpose))
-	if err != nil {
-		return nil, errors.Wrap(err, "getting status list watch key uuid data")
-	}
-
-	if statusListCredential == nil {
-		return nil, errors.Wrap(err, "status list credential should exist in order to update")
-	}
-
-	statusListCredentialWatchKey := s.storage.GetStatusListCredentialWatchKey(gotCred.Issuer, gotCred.Schema, statusPurpose)
-
-	slcMetadata := StatusListCredentialMetadata{statusListCredentialWatchKey: statusListCredentialWatchKey}
-
-	watchKeys := []storage.WatchKey{statusListCredentialWatchKey}
-	returnFunc := s.updateCredentialStatusFunc(request, slcMetadata)
-
-	returnValue, err := s.storage.db.Execute(ctx, returnFunc, watchKeys)
+	returnValue, err := s.storage.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		return s.updateCredentialStatusesBusinessLogic(ctx, tx, []*credentialStatusUpdate{update})
+	}, update.watchKeys())
 	if err != nil {
 		return nil, errors.Wrap(err, "execute")
 	}
 
-	credResponse, ok := returnValue.(*UpdateCredentialStatusResponse)
+	responses, ok := returnValue.([]UpdateCredentialStatusResponse)
 	if !ok {
 		return nil, errors.New("casting to UpdateCredentialStatusResponse")
 	}
 
-	return credResponse, nil
+	return &responses[0], nil
 }
 
-func (s Service) updateCredentialStatusFunc(request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata) storage.BusinessLogicFunc {
-	return func(ctx context.Context, tx storage.Tx) (any, error) {
-		return s.updateCredentialStatusBusinessLogic(ctx, tx, request, slcMetadata)
+// GetStatusLists returns the status lists of the issuer, along with how many of their indexes are allocated.
+func (s Service) GetStatusLists(ctx context.Context, request GetStatusListsRequest) (*GetStatusListsResponse, error) {
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid get status lists request")
 	}
-}
-
-func (s Service) updateCredentialStatusBusinessLogic(ctx context.Context, tx storage.Tx, request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata) (*UpdateCredentialStatusResponse, error) {
-	logrus.Debugf("updating credential status: %s to Revoked: %v", request.ID, request.Revoked)
 
-	if request.Suspended && request.Revoked {
-		return nil, sdkutil.LoggingNewErrorf("cannot update both suspended and revoked status")
-	}
+	logrus.Debugf("getting status lists of issuer: %s", util.SanitizeLog(request.Issuer))
 
-	gotCred, err := s.storage.GetCredential(ctx, request.ID)
+	statusLists, err := s.storage.GetStatusListUtilizationsByIssuer(ctx, request.Issuer)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
-	}
-	if !gotCred.IsValid() {
-		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
-	}
-
-	// if the request is the same as what the current credential is there is no action
-	if gotCred.Revoked == request.Revoked && gotCred.Suspended == request.Suspended {
-		logrus.Warn("request and credential have same status, no action is needed")
-		response := UpdateCredentialStatusResponse{Revoked: gotCred.Revoked, Suspended: gotCred.Suspended}
-		return &response, nil
-	}
-
-	container, err := updateCredentialStatus(ctx, tx, s, gotCred, request, slcMetadata)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "updating credential")
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get status lists of issuer: %s", request.Issuer)
 	}
-
-	response := UpdateCredentialStatusResponse{Revoked: container.Revoked, Suspended: container.Suspended}
-	return &response, nil
-}
-
-func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCred *StoredCredential, request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata) (*credint.Container, error) {
-	// store the credential with updated status
-	container := credint.Container{
-		ID:            gotCred.ID,
-		IssuerKID:     gotCred.IssuerKID,
-		Credential:    gotCred.Credential,
-		CredentialJWT: gotCred.CredentialJWT,
-		Revoked:       request.Revoked,
-		Suspended:     request.Suspended,
-	}
-
-	storageRequest := StoreCredentialRequest{
-		Container: container,
-	}
-
-	if err := s.storage.StoreCredentialTx(ctx, tx, storageRequest); err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential")
-	}
-
-	statusListCredentialID := gotCred.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
-
-	if len(statusListCredentialID) == 0 {
-		return nil, sdkutil.LoggingNewErrorf("problem with getting status list credential id")
-	}
-
-	creds, err := s.storage.GetCredentialsByIssuerAndSchema(ctx, gotCred.Issuer, gotCred.Schema)
-	if err != nil {
-		return nil, sdkutil.LoggingNewErrorf("problem with getting status list credential for issuer: %s schema: %s", gotCred.Issuer, gotCred.Schema)
-	}
-
-	var revokedOrSuspendedStatusCreds []credential.VerifiableCredential
-	for _, cred := range creds {
-		// we add the current cred to the creds list based on request, not on what could be in stale database that the tx has not updated yet
-		if cred.Credential.ID == gotCred.Credential.ID {
-			continue
-		}
-
-		if request.Revoked && cred.Credential.CredentialStatus != nil && cred.Revoked {
-			revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, *cred.Credential)
-		} else if request.Suspended && cred.Credential.CredentialStatus != nil && cred.Suspended {
-			revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, *cred.Credential)
-		}
-	}
-
-	// add current one since it has not been saved yet and wont be available in the creds array
-	if request.Revoked == true || request.Suspended == true {
-		revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, *gotCred.Credential)
-	}
-
-	statusPurpose := statussdk.StatusRevocation
-
-	if request.Suspended {
-		statusPurpose = statussdk.StatusSuspension
-	}
-
-	generatedStatusListCredential, err := statussdk.GenerateStatusList2021Credential(statusListCredentialID, gotCred.Issuer, statusPurpose, revokedOrSuspendedStatusCreds)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not generate status list")
-	}
-
-	generatedStatusListCredential.CredentialSchema = gotCred.Credential.CredentialSchema
-
-	statusListCredJWT, err := s.signCredentialJWT(ctx, gotCred.IssuerKID, *generatedStatusListCredential)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
-	}
-
-	// store the status list credential
-	statusListContainer := credint.Container{
-		ID:            generatedStatusListCredential.ID,
-		IssuerKID:     gotCred.IssuerKID,
-		Credential:    generatedStatusListCredential,
-		CredentialJWT: statusListCredJWT,
-	}
-
-	storageRequest = StoreCredentialRequest{
-		Container: statusListContainer,
-	}
-
-	if err = s.storage.StoreStatusListCredentialTx(ctx, tx, storageRequest, slcMetadata); err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential status list")
-	}
-
-	return &container, nil
+	return &GetStatusListsResponse{StatusLists: statusLists}, nil
 }
 
 func (s Service) GetCredentialsByIssuerAndSchemaWithStatus(ctx context.Context, issuer string, schema string) ([]credential.VerifiableCredential, error) {
@@ -0,0 +1,328 @@
+package credential
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"sort"
+	"time"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const encodedListProperty = "encodedList"
+
+// BatchUpdateCredentialStatus updates the statuses of many credentials in a single transaction. The bits of the
+// credentials which share a status list are set at once, so that each status list is signed again only once.
+func (s Service) BatchUpdateCredentialStatus(ctx context.Context, request BatchUpdateCredentialStatusRequest) (*BatchUpdateCredentialStatusResponse, error) {
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid batch update credential status request")
+	}
+	if len(request.Requests) > MaxBatchSize {
+		return nil, sdkutil.LoggingNewErrorf("batch of %d credential statuses is larger than the maximum of %d", len(request.Requests), MaxBatchSize)
+	}
+
+	updates := make([]*credentialStatusUpdate, 0, len(request.Requests))
+	watchKeys := make(map[storage.WatchKey]bool)
+	credentialIDs := make(map[string]bool, len(request.Requests))
+	for _, updateRequest := range request.Requests {
+		if credentialIDs[updateRequest.ID] {
+			return nil, sdkutil.LoggingNewErrorf("status of credential<%s> is updated more than once", updateRequest.ID)
+		}
+		credentialIDs[updateRequest.ID] = true
+
+		update, err := s.prepareCredentialStatusUpdate(ctx, updateRequest)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, update)
+		for _, key := range update.watchKeys() {
+			watchKeys[key] = true
+		}
+	}
+	keys := make([]storage.WatchKey, 0, len(watchKeys))
+	for key := range watchKeys {
+		keys = append(keys, key)
+	}
+
+	returnValue, err := s.storage.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		return s.updateCredentialStatusesBusinessLogic(ctx, tx, updates)
+	}, keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute")
+	}
+
+	responses, ok := returnValue.([]UpdateCredentialStatusResponse)
+	if !ok {
+		return nil, errors.New("casting to UpdateCredentialStatusResponse")
+	}
+	return &BatchUpdateCredentialStatusResponse{Results: responses}, nil
+}
+
+// credentialStatusUpdate is the update of the status of a stored credential, along with the status list holding it.
+type credentialStatusUpdate struct {
+	request     UpdateCredentialStatusRequest
+	credential  *StoredCredential
+	slcMetadata StatusListCredentialMetadata
+	// the key of the status list once it was full and replaced
+	fullStatusListCredentialWatchKey storage.WatchKey
+}
+
+// watchKeys returns the keys of the status list of the credential, which must be watched by the transactions setting
+// its bits.
+func (u credentialStatusUpdate) watchKeys() []storage.WatchKey {
+	return []storage.WatchKey{u.slcMetadata.statusListCredentialWatchKey, u.fullStatusListCredentialWatchKey}
+}
+
+// prepareCredentialStatusUpdate gets the credential of the request, along with the keys of its status list, which is
+// either the current one of the issuer, schema and purpose of the credential, or one which was full when it was
+// replaced.
+func (s Service) prepareCredentialStatusUpdate(ctx context.Context, request UpdateCredentialStatusRequest) (*credentialStatusUpdate, error) {
+	if request.Suspended && request.Revoked {
+		return nil, sdkutil.LoggingNewErrorf("cannot update both suspended and revoked status")
+	}
+	if request.SuspendedUntil != nil {
+		if !request.Suspended {
+			return nil, sdkutil.LoggingNewErrorf("suspendedUntil can only be set when suspending a credential")
+		}
+		if !request.SuspendedUntil.After(time.Now()) {
+			return nil, sdkutil.LoggingNewErrorf("suspendedUntil<%s> must be in the future", request.SuspendedUntil.Format(time.RFC3339))
+		}
+	}
+
+	gotCred, err := s.storage.GetCredential(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
+	}
+
+	if gotCred.StatusListCredentialID == "" || gotCred.Credential.CredentialStatus == nil {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> has no status list", request.ID)
+	}
+	statusPurpose, _ := gotCred.Credential.CredentialStatus.(map[string]any)["statusPurpose"].(string)
+	if len(statusPurpose) == 0 {
+		return nil, sdkutil.LoggingNewErrorf("status purpose could not be derived from credential status")
+	}
+
+	return &credentialStatusUpdate{
+		request:                          request,
+		credential:                       gotCred,
+		slcMetadata:                      StatusListCredentialMetadata{statusListCredentialWatchKey: s.storage.GetStatusListCredentialWatchKey(gotCred.Issuer, gotCred.Schema, statusPurpose)},
+		fullStatusListCredentialWatchKey: s.storage.GetFullStatusListCredentialWatchKey(gotCred.Issuer, gotCred.Schema, statusPurpose, gotCred.StatusListCredentialID),
+	}, nil
+}
+
+// updateCredentialStatusesBusinessLogic stores each credential with its updated status, then sets the bits of the
+// credentials whose status changed in their status lists, signing each status list again once.
+func (s Service) updateCredentialStatusesBusinessLogic(ctx context.Context, tx storage.Tx, updates []*credentialStatusUpdate) ([]UpdateCredentialStatusResponse, error) {
+	responses := make([]UpdateCredentialStatusResponse, 0, len(updates))
+	statusLists := make(map[string][]*credentialStatusUpdate)
+	for _, update := range updates {
+		gotCred := update.credential
+		request := update.request
+		responses = append(responses, UpdateCredentialStatusResponse{
+			Revoked:        request.Revoked,
+			Suspended:      request.Suspended,
+			SuspendedUntil: request.SuspendedUntil,
+		})
+
+		// if the request is the same as what the current credential is there is no action
+		statusChanged := gotCred.Revoked != request.Revoked || gotCred.Suspended != request.Suspended
+		if !statusChanged && sameTime(gotCred.SuspendedUntil, request.SuspendedUntil) {
+			logrus.Warnf("request and credential<%s> have same status, no action is needed", request.ID)
+			continue
+		}
+
+		// store the credential with updated status
+		container := credint.Container{
+			ID:              gotCred.ID,
+			IssuerKID:       gotCred.IssuerKID,
+			Credential:      gotCred.Credential,
+			CredentialJWT:   gotCred.CredentialJWT,
+			CredentialSDJWT: gotCred.CredentialSDJWT,
+			Revoked:         request.Revoked,
+			Suspended:       request.Suspended,
+			SuspendedUntil:  request.SuspendedUntil,
+			Expired:         gotCred.Expired,
+		}
+		if err := s.storage.StoreCredentialTx(ctx, tx, StoreCredentialRequest{Container: container}); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not store credential: %s", request.ID)
+		}
+		if statusChanged {
+			statusLists[gotCred.StatusListCredentialID] = append(statusLists[gotCred.StatusListCredentialID], update)
+		}
+	}
+
+	// status lists are signed in a stable order
+	statusListIDs := make([]string, 0, len(statusLists))
+	for id := range statusLists {
+		statusListIDs = append(statusListIDs, id)
+	}
+	sort.Strings(statusListIDs)
+	for _, id := range statusListIDs {
+		if err := s.setStatusListBits(ctx, tx, id, statusLists[id]); err != nil {
+			return nil, errors.Wrapf(err, "updating status list<%s>", id)
+		}
+	}
+	return responses, nil
+}
+
+// setStatusListBits sets the bits of the credentials of the updates in the status list credential with the given id,
+// according to its purpose, then signs it again. The status list keeps the format it was created in.
+func (s Service) setStatusListBits(ctx context.Context, tx storage.Tx, statusListCredentialID string, updates []*credentialStatusUpdate) error {
+	statusListCredential, statusListCredentialKey, err := s.storage.GetStatusListCredentialByIDTx(ctx, tx, updates[0].slcMetadata, statusListCredentialID)
+	if err != nil {
+		return errors.Wrap(err, "getting status list credential")
+	}
+	statusPurpose, _ := statusListCredential.Credential.CredentialSubject["statusPurpose"].(string)
+	encodedList, _ := statusListCredential.Credential.CredentialSubject[encodedListProperty].(string)
+
+	bits, err := expandBitstring(encodedList)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not expand status list")
+	}
+	for _, update := range updates {
+		request := update.request
+		requestPurpose := statussdk.StatusRevocation
+		if request.Suspended {
+			requestPurpose = statussdk.StatusSuspension
+		}
+		if (request.Revoked || request.Suspended) && statussdk.StatusPurpose(statusPurpose) != requestPurpose {
+			return sdkutil.LoggingNewErrorf("credential<%s> has a different status purpose<%s> value than the status credential<%s>", update.credential.Credential.ID, statusPurpose, requestPurpose)
+		}
+		bits.SetTo(uint(update.credential.StatusListIndex), request.Revoked || request.Suspended)
+	}
+	if encodedList, err = compressBitstring(bits); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not compress status list")
+	}
+
+	statusListCred, err := credint.CopyCredential(*statusListCredential.Credential)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not copy status list credential")
+	}
+	statusListCred.Proof = nil
+	statusListCred.CredentialSubject[encodedListProperty] = encodedList
+
+	statusListContainer, err := s.signCredential(ctx, statusListCredential.IssuerKID, *statusListCred, statusListCredential.Format(), statusListCredential.ProofType())
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
+	}
+	storageRequest := StoreCredentialRequest{Container: *statusListContainer}
+	if err = s.storage.StoreStatusListCredentialTx(ctx, tx, storageRequest, *statusListCredentialKey, statusListCredential.statusListSize()); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not store credential status list")
+	}
+	return nil
+}
+
+// LiftSuspensions lifts the temporary suspensions which are due, clearing the bits of their credentials in their
+// status lists. It returns how many suspensions were lifted.
+func (s Service) LiftSuspensions(ctx context.Context) (int, error) {
+	suspended, err := s.storage.GetTemporarilySuspendedCredentials(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	var requests []UpdateCredentialStatusRequest
+	for _, cred := range suspended {
+		if cred.Suspended && cred.SuspendedUntil != nil && !cred.SuspendedUntil.After(now) {
+			requests = append(requests, UpdateCredentialStatusRequest{ID: cred.CredentialID, Revoked: cred.Revoked})
+		}
+	}
+
+	lifted := 0
+	for start := 0; start < len(requests); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if _, err = s.BatchUpdateCredentialStatus(ctx, BatchUpdateCredentialStatusRequest{Requests: requests[start:end]}); err != nil {
+			return lifted, errors.Wrap(err, "lifting suspensions")
+		}
+		lifted += end - start
+	}
+	return lifted, nil
+}
+
+// ExpireCredentials marks the credentials whose expiration date has passed as expired. Like any other update of a
+// stored credential, marking it is delivered to the webhooks registered for credential updates. It returns how many
+// credentials were marked.
+func (s Service) ExpireCredentials(ctx context.Context) (int, error) {
+	keys, err := s.storage.GetCredentialKeysPendingExpiration(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	expired := 0
+	ae := sdkutil.NewAppendError()
+	for _, key := range keys {
+		marked, err := s.storage.MarkCredentialExpired(ctx, key, now)
+		if err != nil {
+			ae.Append(err)
+			continue
+		}
+		if marked {
+			expired++
+		}
+	}
+	return expired, ae.Error()
+}
+
+// sameTime returns whether both times are unset, or both are set to the same instant.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// expandBitstring decodes the encoded list of a status list credential into its bits. It is the reverse of
+// compressBitstring: https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-expansion-algorithm
+func expandBitstring(encodedList string) (*bitset.BitSet, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encodedList)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding bitstring")
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing bitstring")
+	}
+	defer zr.Close()
+	bitstring, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing bitstring")
+	}
+	var bits bitset.BitSet
+	if err = bits.UnmarshalBinary(bitstring); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling bitstring")
+	}
+	return &bits, nil
+}
+
+// compressBitstring encodes the bits of a status list into the encoded list of its credential, just as the status list
+// credentials of the SDK are: https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-generation-algorithm
+func compressBitstring(bits *bitset.BitSet) (string, error) {
+	bitstring, err := bits.MarshalBinary()
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling bitstring")
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(bitstring); err != nil {
+		return "", errors.Wrap(err, "compressing bitstring")
+	}
+	if err = zw.Close(); err != nil {
+		return "", errors.Wrap(err, "compressing bitstring")
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -5,18 +5,27 @@ import (
 	"fmt"
 	"math/rand"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.einride.tech/aip/filtering"
+	"go.einride.tech/aip/ordering"
 
 	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -30,9 +39,11 @@ type StoredCredential struct {
 
 	CredentialID string `json:"credentialId"`
 
-	// only one of these fields should be present
-	Credential    *credential.VerifiableCredential `json:"credential,omitempty"`
-	CredentialJWT *keyaccess.JWT                   `json:"token,omitempty"`
+	// only one of these fields should be present, other than the credential an sd-jwt was signed from, which is kept
+	// along with it
+	Credential      *credential.VerifiableCredential `json:"credential,omitempty"`
+	CredentialJWT   *keyaccess.JWT                   `json:"token,omitempty"`
+	CredentialSDJWT *keyaccess.SDJWT                 `json:"sdJwt,omitempty"`
 
 	Issuer       string `json:"issuer"`
 	IssuerKID    string `json:"issuerKid"`
@@ -41,12 +52,29 @@ type StoredCredential struct {
 	IssuanceDate string `json:"issuanceDate"`
 	Revoked      bool   `json:"revoked"`
 	Suspended    bool   `json:"suspended"`
+
+	// When the suspension of the credential is lifted, if it is temporary.
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	// Whether the expiration date of the credential has passed, once marked by the expiration job.
+	Expired bool `json:"expired,omitempty"`
+
+	// The status list credential holding the status of the credential, and the index of the status in it. Only set for
+	// credentials with a status.
+	StatusListCredentialID string `json:"statusListCredentialId,omitempty"`
+	StatusListIndex        int    `json:"statusListIndex,omitempty"`
+
+	// The number of indexes of a status list credential. Only set for status list credentials, and unset for those
+	// created before the size was configurable, which have defaultStatusListSize indexes.
+	StatusListSize int `json:"statusListSize,omitempty"`
 }
 
 type WriteContext struct {
-	namespace string
-	key       string
-	value     []byte
+	namespace    string
+	key          string
+	value        []byte
+	indexEntries []storage.IndexEntry
+	// credentialID is the id of the credential written, which is not the key it is written under.
+	credentialID string
 }
 
 type StatusListCredentialMetadata struct {
@@ -55,8 +83,13 @@ type StatusListCredentialMetadata struct {
 	statusListCurrentIndexWatchKey storage.WatchKey
 }
 
+// watchKeys returns the keys which must be watched by the transactions allocating indexes of the status list.
+func (m StatusListCredentialMetadata) watchKeys() []storage.WatchKey {
+	return []storage.WatchKey{m.statusListCredentialWatchKey, m.statusListIndexPoolWatchKey, m.statusListCurrentIndexWatchKey}
+}
+
 func (sc StoredCredential) IsValid() bool {
-	return sc.ID != "" && (sc.HasDataIntegrityCredential() || sc.HasJWTCredential())
+	return sc.ID != "" && (sc.HasDataIntegrityCredential() || sc.HasJWTCredential() || sc.HasSDJWTCredential())
 }
 
 func (sc StoredCredential) HasDataIntegrityCredential() bool {
@@ -67,18 +100,277 @@ func (sc StoredCredential) HasJWTCredential() bool {
 	return sc.CredentialJWT != nil
 }
 
+func (sc StoredCredential) HasSDJWTCredential() bool {
+	return sc.CredentialSDJWT != nil
+}
+
+// Format returns the format the credential is signed in, or an empty format when it is not signed.
+func (sc StoredCredential) Format() exchange.CredentialFormat {
+	if sc.HasSDJWTCredential() {
+		return credint.SDJWTVC
+	}
+	if sc.HasJWTCredential() {
+		return exchange.JWTVC.CredentialFormat()
+	}
+	if sc.HasDataIntegrityCredential() {
+		return exchange.LDPVC.CredentialFormat()
+	}
+	return ""
+}
+
+// ProofType returns the type of the embedded proof of a data integrity credential, or an empty type for the other
+// formats.
+func (sc StoredCredential) ProofType() cryptosuite.SignatureType {
+	if sc.Format() != exchange.LDPVC.CredentialFormat() {
+		return ""
+	}
+	return keyaccess.ProofType(sc.Credential)
+}
+
+// neverExpires is the expiry filters compare credentials without an expiration date by, so they sort after all others.
+var neverExpires = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// FilterVariablesMap returns the values filters on credentials may refer to. Credentials without an expiration date
+// have an expiry of 9999-12-31T23:59:59Z, and dates which cannot be parsed are left out, so filters on them fail to
+// evaluate.
+func (sc StoredCredential) FilterVariablesMap() map[string]any {
+	vars := map[string]any{
+		"issuer":    sc.Issuer,
+		"subject":   sc.Subject,
+		"schema":    sc.Schema,
+		"type":      []string{},
+		"revoked":   sc.Revoked,
+		"suspended": sc.Suspended,
+		"expiry":    neverExpires,
+		// "true" and "false" are currently being parsed as identifiers, so we need to pass in the values that they
+		// evaluate to.
+		"true":  true,
+		"false": false,
+	}
+	if issuanceDate, err := time.Parse(time.RFC3339, sc.IssuanceDate); err == nil {
+		vars["issuanceDate"] = issuanceDate
+	}
+	if sc.Credential == nil {
+		return vars
+	}
+	vars["type"] = credentialTypes(sc.Credential.Type)
+	if sc.Credential.ExpirationDate != "" {
+		delete(vars, "expiry")
+		if expiry, err := time.Parse(time.RFC3339, sc.Credential.ExpirationDate); err == nil {
+			vars["expiry"] = expiry
+		}
+	}
+	return vars
+}
+
+// credentialTypes returns the types of a credential, which may be a single string or a list of them.
+func credentialTypes(credType any) []string {
+	switch t := credType.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return []string{}
+}
+
+// indexEntries returns the entries which make the credential retrievable by its issuer, subject, schema and status
+// list, and by whether it is yet to expire or temporarily suspended.
+func (sc StoredCredential) indexEntries() []storage.IndexEntry {
+	entries := []storage.IndexEntry{
+		storage.NewIndexEntry(issuerIndex, sc.Issuer),
+		storage.NewIndexEntry(subjectIndex, sc.Subject),
+		storage.NewIndexEntry(schemaIndex, sc.Schema),
+		storage.NewIndexEntry(issuerSchemaIndex, sc.Issuer, sc.Schema),
+	}
+	if sc.StatusListCredentialID != "" {
+		entries = append(entries, storage.NewIndexEntry(statusListCredentialIndex, sc.StatusListCredentialID))
+	}
+	if sc.Credential != nil && sc.Credential.ExpirationDate != "" && !sc.Expired {
+		entries = append(entries, storage.NewIndexEntry(pendingExpirationIndex, pendingIndexValue))
+	}
+	if sc.Suspended && sc.SuspendedUntil != nil {
+		entries = append(entries, storage.NewIndexEntry(temporarySuspensionIndex, pendingIndexValue))
+	}
+	return entries
+}
+
+// statusListIndexEntries returns the entries which make the status list credential retrievable by its ID and issuer.
+func (sc StoredCredential) statusListIndexEntries() []storage.IndexEntry {
+	return []storage.IndexEntry{
+		storage.NewIndexEntry(idIndex, ExtractID(sc.CredentialID)),
+		storage.NewIndexEntry(issuerIndex, sc.Issuer),
+	}
+}
+
+// statusListSize returns the number of indexes of the status list credential.
+func (sc StoredCredential) statusListSize() int {
+	if sc.StatusListSize == 0 {
+		return defaultStatusListSize
+	}
+	return sc.StatusListSize
+}
+
+// setStatusListEntry records the status list and index of the status of the credential, when it has a status list
+// entry.
+func (sc *StoredCredential) setStatusListEntry() error {
+	if sc.Credential == nil || sc.Credential.CredentialStatus == nil {
+		return nil
+	}
+	statusBytes, err := json.Marshal(sc.Credential.CredentialStatus)
+	if err != nil {
+		return errors.Wrap(err, "marshalling credential status")
+	}
+	var entry statussdk.StatusList2021Entry
+	if err = json.Unmarshal(statusBytes, &entry); err != nil {
+		return errors.Wrap(err, "unmarshalling credential status")
+	}
+	if entry.StatusListCredential == "" {
+		return nil
+	}
+	index, err := strconv.Atoi(entry.StatusListIndex)
+	if err != nil {
+		return errors.Wrapf(err, "parsing status list index<%s>", entry.StatusListIndex)
+	}
+	sc.StatusListCredentialID = entry.StatusListCredential
+	sc.StatusListIndex = index
+	return nil
+}
+
+func credentialIndexEntries(value []byte) ([]storage.IndexEntry, error) {
+	var stored StoredCredential
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential")
+	}
+	return stored.indexEntries(), nil
+}
+
+// migrateCredentialStatusListEntry records the status list and index of the status of a stored credential.
+func migrateCredentialStatusListEntry(_ context.Context, _ string, value []byte) ([]byte, error) {
+	var stored StoredCredential
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential")
+	}
+	if stored.StatusListCredentialID != "" {
+		return value, nil
+	}
+	if err := stored.setStatusListEntry(); err != nil {
+		return nil, err
+	}
+	if stored.StatusListCredentialID == "" {
+		return value, nil
+	}
+	return json.Marshal(stored)
+}
+
+func statusListCredentialIndexEntries(value []byte) ([]storage.IndexEntry, error) {
+	var stored StoredCredential
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling status list credential")
+	}
+	return stored.statusListIndexEntries(), nil
+}
+
 const (
 	credentialNamespace                    = "credential"
 	statusListCredentialNamespace          = "status-list-credential"
 	statusListCredentialIndexPoolNamespace = "status-list-index-pool"
 	statusListCredentialCurrentIndex       = "status-list-current-index"
+	credentialRefreshNamespace             = "credential-refresh"
+	verificationPolicyNamespace            = "verification-policy"
+	// credentialIDNamespace holds the storage key of each credential, by the id of the credential.
+	credentialIDNamespace = "credential-id"
 
-	// A a minimum revocation bitString length of 131,072, or 16KB uncompressed
-	bitStringLength = 8 * 1024 * 16
+	// The number of indexes of status lists when not configured, a minimum revocation bitString length of 131,072, or
+	// 16KB uncompressed
+	defaultStatusListSize = 8 * 1024 * 16
 
 	credentialNotFoundErrMsg = "credential not found"
+
+	// Names of the indexes over stored credentials. Status list credentials are only indexed by ID.
+	issuerIndex       = "issuer"
+	subjectIndex      = "subject"
+	schemaIndex       = "schema"
+	issuerSchemaIndex = "issuer-schema"
+	idIndex           = "id"
+
+	// statusListCredentialIndex is the name of the index over the status list credential of stored credentials.
+	statusListCredentialIndex = "status-list-credential"
+
+	// pendingExpirationIndex is the name of the index over the credentials which have an expiration date, and are not
+	// yet marked as expired, and temporarySuspensionIndex that over the credentials whose suspension is lifted at a set
+	// time. Both only have entries with pendingIndexValue.
+	pendingExpirationIndex   = "pending-expiration"
+	temporarySuspensionIndex = "temporary-suspension"
+	pendingIndexValue        = "pending"
 )
 
+func init() {
+	if err := storage.RegisterMigrations(
+		storage.Migration{
+			Namespace:   credentialNamespace,
+			Version:     1,
+			Description: "index credentials by issuer, subject and schema",
+			Index:       credentialIndexEntries,
+		},
+		storage.Migration{
+			Namespace:   statusListCredentialNamespace,
+			Version:     1,
+			Description: "index status list credentials by id",
+			Index:       statusListCredentialIndexEntries,
+		},
+		storage.Migration{
+			Namespace:   credentialNamespace,
+			Version:     2,
+			Description: "record the status list of credentials, and index them by it",
+			Migrate:     migrateCredentialStatusListEntry,
+			Index:       credentialIndexEntries,
+		},
+		storage.Migration{
+			Namespace:   statusListCredentialNamespace,
+			Version:     2,
+			Description: "index status list credentials by issuer",
+			Index:       statusListCredentialIndexEntries,
+		},
+		storage.Migration{
+			Namespace:   credentialNamespace,
+			Version:     3,
+			Description: "index credentials which are yet to expire",
+			Index:       credentialIndexEntries,
+		},
+	); err != nil {
+		panic(err)
+	}
+	webhook.RegisterResource(credentialNamespace, webhook.Resource{Noun: webhook.Credential, Data: credentialWebhookData})
+}
+
+// credentialWebhookData returns a stored credential in the shape it is returned by the API with.
+func credentialWebhookData(value []byte) (any, error) {
+	var stored StoredCredential
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling stored credential")
+	}
+	return struct {
+		ID              string                           `json:"id"`
+		Credential      *credential.VerifiableCredential `json:"credential,omitempty"`
+		CredentialJWT   *keyaccess.JWT                   `json:"credentialJwt,omitempty"`
+		CredentialSDJWT *keyaccess.SDJWT                 `json:"credentialSdJwt,omitempty"`
+	}{
+		ID:              stored.CredentialID,
+		Credential:      stored.Credential,
+		CredentialJWT:   stored.CredentialJWT,
+		CredentialSDJWT: stored.CredentialSDJWT,
+	}, nil
+}
+
 type Storage struct {
 	db storage.ServiceStorage
 }
@@ -95,34 +387,6 @@ func NewCredentialStorage(db storage.ServiceStorage) (*Storage, error) {
 	return &Storage{db: db}, nil
 }
 
-func (cs *Storage) GetNextStatusListRandomIndex(ctx context.Context, slcMetadata StatusListCredentialMetadata) (int, error) {
-	gotUniqueNumBytes, err := cs.db.Read(ctx, slcMetadata.statusListIndexPoolWatchKey.Namespace, slcMetadata.statusListIndexPoolWatchKey.Key)
-	if err != nil {
-		return -1, sdkutil.LoggingErrorMsgf(err, "reading status list")
-	}
-
-	if len(gotUniqueNumBytes) == 0 {
-		return -1, sdkutil.LoggingNewErrorf("could not get unique numbers from db")
-	}
-
-	var uniqueNums []int
-	if err = json.Unmarshal(gotUniqueNumBytes, &uniqueNums); err != nil {
-		return -1, sdkutil.LoggingErrorMsgf(err, "unmarshalling unique numbers")
-	}
-
-	gotCurrentListIndexBytes, err := cs.db.Read(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key)
-	if err != nil {
-		return -1, sdkutil.LoggingErrorMsgf(err, "could not get list index")
-	}
-
-	var statusListIndex StatusListIndex
-	if err = json.Unmarshal(gotCurrentListIndexBytes, &statusListIndex); err != nil {
-		return -1, sdkutil.LoggingErrorMsgf(err, "unmarshalling unique numbers")
-	}
-
-	return uniqueNums[statusListIndex.Index], nil
-}
-
 func (cs *Storage) WriteMany(ctx context.Context, writeContexts []WriteContext) error {
 	namespaces := make([]string, 0)
 	keys := make([]string, 0)
@@ -137,31 +401,85 @@ func (cs *Storage) WriteMany(ctx context.Context, writeContexts []WriteContext)
 	return cs.db.WriteMany(ctx, namespaces, keys, values)
 }
 
-func (cs *Storage) IncrementStatusListIndexTx(ctx context.Context, tx storage.Tx, slcMetadata StatusListCredentialMetadata) error {
-	gotCurrentListIndexBytes, err := cs.db.Read(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key)
+// GetStatusListCredentialTx returns the status list credential described by the metadata, read within the
+// transaction, or nil when it has not been created yet.
+func (cs *Storage) GetStatusListCredentialTx(ctx context.Context, tx storage.Tx, slcMetadata StatusListCredentialMetadata) (*StoredCredential, error) {
+	storedCredBytes, err := tx.Read(ctx, slcMetadata.statusListCredentialWatchKey.Namespace, slcMetadata.statusListCredentialWatchKey.Key)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "reading status list credential")
+	}
+	if len(storedCredBytes) == 0 {
+		return nil, nil
+	}
+
+	var stored StoredCredential
+	if err = json.Unmarshal(storedCredBytes, &stored); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "unmarshalling status list credential")
+	}
+	return &stored, nil
+}
+
+// AllocateStatusListIndexTx reserves the next index of the status list described by the metadata. The index pool and
+// the current position in it are read within the transaction, and the advanced position is written through it. Both
+// keys must be watched, so that concurrent allocations are retried instead of handing out the same index twice.
+func (cs *Storage) AllocateStatusListIndexTx(ctx context.Context, tx storage.Tx, slcMetadata StatusListCredentialMetadata) (int, error) {
+	indexes, err := cs.AllocateStatusListIndexesTx(ctx, tx, slcMetadata, 1)
+	if err != nil {
+		return -1, err
+	}
+	if len(indexes) == 0 {
+		return -1, sdkutil.LoggingNewError("no more indexes available for status list index")
+	}
+	return indexes[0], nil
+}
+
+// AllocateStatusListIndexesTx reserves up to count of the next indexes of the status list described by the metadata at
+// once, advancing the current position in the index pool by a single write. Fewer indexes are returned when the
+// status list fills up, and none once it is full. The same keys as for AllocateStatusListIndexTx must be watched.
+func (cs *Storage) AllocateStatusListIndexesTx(ctx context.Context, tx storage.Tx, slcMetadata StatusListCredentialMetadata, count int) ([]int, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	gotUniqueNumBytes, err := tx.Read(ctx, slcMetadata.statusListIndexPoolWatchKey.Namespace, slcMetadata.statusListIndexPoolWatchKey.Key)
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not get list index")
+		return nil, sdkutil.LoggingErrorMsg(err, "reading status list index pool")
+	}
+	if len(gotUniqueNumBytes) == 0 {
+		return nil, sdkutil.LoggingNewError("could not get unique numbers from db")
+	}
+
+	var uniqueNums []int
+	if err = json.Unmarshal(gotUniqueNumBytes, &uniqueNums); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "unmarshalling unique numbers")
+	}
+
+	gotCurrentListIndexBytes, err := tx.Read(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not get list index")
 	}
 
 	var statusListIndex StatusListIndex
 	if err = json.Unmarshal(gotCurrentListIndexBytes, &statusListIndex); err != nil {
-		return sdkutil.LoggingErrorMsg(err, "unmarshalling unique numbers")
+		return nil, sdkutil.LoggingErrorMsg(err, "unmarshalling status list index")
 	}
 
-	if statusListIndex.Index >= bitStringLength-1 {
-		return sdkutil.LoggingErrorMsg(err, "no more indexes available for status list index")
+	if left := len(uniqueNums) - statusListIndex.Index; count > left {
+		count = left
+	}
+	if count <= 0 {
+		return nil, nil
 	}
 
-	statusListIndexBytes, err := json.Marshal(StatusListIndex{Index: statusListIndex.Index + 1})
+	statusListIndexBytes, err := json.Marshal(StatusListIndex{Index: statusListIndex.Index + count})
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not marshal status list index bytes")
+		return nil, sdkutil.LoggingErrorMsg(err, "could not marshal status list index bytes")
 	}
 
-	if err := tx.Write(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key, statusListIndexBytes); err != nil {
-		return sdkutil.LoggingErrorMsg(err, "problem writing current list index to db")
+	if err = tx.Write(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key, statusListIndexBytes); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "problem writing current list index to db")
 	}
 
-	return nil
+	return uniqueNums[statusListIndex.Index : statusListIndex.Index+count], nil
 }
 
 func (cs *Storage) StoreCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest) error {
@@ -170,20 +488,70 @@ func (cs *Storage) StoreCredentialTx(ctx context.Context, tx storage.Tx, request
 		return errors.Wrap(err, "building stored credential")
 
 	}
-	return tx.Write(ctx, wc.namespace, wc.key, wc.value)
+	if err = storage.WriteIndexedTx(ctx, tx, wc.namespace, wc.key, wc.value, wc.indexEntries...); err != nil {
+		return err
+	}
+	if err = tx.Write(ctx, credentialIDNamespace, wc.credentialID, []byte(wc.key)); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not store key of credential: %s", wc.credentialID)
+	}
+	return nil
+}
+
+// StoreNewCredentialTx stores a credential being created, failing when a credential with its id is already stored.
+// The watch key of the id, from GetCredentialIDWatchKey, must be passed to the transaction for the check to hold
+// against concurrent creations.
+func (cs *Storage) StoreNewCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest) error {
+	wc, err := cs.getStoreCredentialWriteContext(request, credentialNamespace)
+	if err != nil {
+		return errors.Wrap(err, "building stored credential")
+	}
+	exists, err := cs.CredentialExistsTx(ctx, tx, wc.credentialID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return sdkutil.LoggingNewErrorf("credential with id<%s> already exists", wc.credentialID)
+	}
+	return cs.StoreCredentialTx(ctx, tx, request)
+}
+
+// CredentialExistsTx returns whether the key of a credential with the given id is recorded, read within the
+// transaction.
+func (cs *Storage) CredentialExistsTx(ctx context.Context, tx storage.Tx, id string) (bool, error) {
+	key, err := tx.Read(ctx, credentialIDNamespace, id)
+	if err != nil {
+		return false, sdkutil.LoggingErrorMsgf(err, "could not read key of credential: %s", id)
+	}
+	return key != nil, nil
 }
 
-// CreateStatusListCredentialTx creates a new status list credential with the provided metadata and stores it in the database as a transaction.
-// The function generates a unique random number and stores it along with the metadata in the database and then returns it
-func (cs *Storage) CreateStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata) (int, error) {
+// CreateStatusListCredentialTx creates a new status list credential of the given size with the provided metadata and
+// stores it in the database as a transaction, generating a pool of its indexes in random order. It returns the first
+// index of the pool, which is allocated. When the metadata already describes a status list, which is full, it is kept
+// under a key of its own, so that the statuses it holds can still be updated, and the new status list replaces it.
+func (cs *Storage) CreateStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata, size int) (int, error) {
+	full, err := cs.GetStatusListCredentialTx(ctx, tx, slcMetadata)
+	if err != nil {
+		return -1, err
+	}
+	if full != nil {
+		fullBytes, err := json.Marshal(full)
+		if err != nil {
+			return -1, sdkutil.LoggingErrorMsgf(err, "could not marshal full status list credential: %s", full.CredentialID)
+		}
+		key := fullStatusListKey(slcMetadata.statusListCredentialWatchKey.Key, full.CredentialID)
+		if err = storage.WriteIndexedTx(ctx, tx, statusListCredentialNamespace, key, fullBytes, full.statusListIndexEntries()...); err != nil {
+			return -1, sdkutil.LoggingErrorMsgf(err, "could not store full status list credential: %s", full.CredentialID)
+		}
+	}
 
-	randUniqueList := randomUniqueNum(bitStringLength)
+	randUniqueList := randomUniqueNum(size)
 	uniqueNumBytes, err := json.Marshal(randUniqueList)
 	if err != nil {
 		return -1, sdkutil.LoggingErrorMsg(err, "could not marshal random unique numbers")
 	}
 
-	if err := tx.Write(context.Background(), slcMetadata.statusListIndexPoolWatchKey.Namespace, slcMetadata.statusListIndexPoolWatchKey.Key, uniqueNumBytes); err != nil {
+	if err := tx.Write(ctx, slcMetadata.statusListIndexPoolWatchKey.Namespace, slcMetadata.statusListIndexPoolWatchKey.Key, uniqueNumBytes); err != nil {
 		return -1, sdkutil.LoggingErrorMsg(err, "problem writing status list indexes to db")
 	}
 
@@ -193,14 +561,15 @@ func (cs *Storage) CreateStatusListCredentialTx(ctx context.Context, tx storage.
 		return -1, sdkutil.LoggingErrorMsg(err, "could not marshal status list index bytes")
 	}
 
-	if err := tx.Write(context.Background(), slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key, statusListIndexBytes); err != nil {
+	if err := tx.Write(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key, statusListIndexBytes); err != nil {
 		return -1, sdkutil.LoggingErrorMsg(err, "problem writing current list index to db")
 	}
 
-	return randUniqueList[0], cs.StoreStatusListCredentialTx(ctx, tx, request, slcMetadata)
+	return randUniqueList[0], cs.StoreStatusListCredentialTx(ctx, tx, request, slcMetadata.statusListCredentialWatchKey, size)
 }
 
-func (cs *Storage) StoreStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata) error {
+// StoreStatusListCredentialTx stores the status list credential of the given size under the given key.
+func (cs *Storage) StoreStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, key storage.WatchKey, size int) error {
 	if !request.IsValid() {
 		return sdkutil.LoggingNewError("store request request is not valid")
 	}
@@ -210,39 +579,52 @@ func (cs *Storage) StoreStatusListCredentialTx(ctx context.Context, tx storage.T
 	if err != nil {
 		return errors.Wrap(err, "building stored credential")
 	}
+	storedCredential.StatusListSize = size
 
 	storedCredBytes, err := json.Marshal(storedCredential)
 	if err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "could not store request: %s", storedCredential.CredentialID)
 	}
 
-	return tx.Write(ctx, slcMetadata.statusListCredentialWatchKey.Namespace, slcMetadata.statusListCredentialWatchKey.Key, storedCredBytes)
+	return storage.WriteIndexedTx(ctx, tx, key.Namespace, key.Key, storedCredBytes, storedCredential.statusListIndexEntries()...)
 }
 
-func (cs *Storage) GetStatusListCredential(ctx context.Context, id string) (*StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, statusListCredentialNamespace)
+// GetStatusListCredentialByIDTx returns the status list credential with the given id, read within the transaction,
+// along with the key it is stored under. The status list is either the one described by the metadata, or one which
+// was full when it was replaced, whose key is returned by GetFullStatusListCredentialWatchKey.
+func (cs *Storage) GetStatusListCredentialByIDTx(ctx context.Context, tx storage.Tx, slcMetadata StatusListCredentialMetadata, id string) (*StoredCredential, *storage.WatchKey, error) {
+	current, err := cs.GetStatusListCredentialTx(ctx, tx, slcMetadata)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for cred with id: %s", id)
+		return nil, nil, err
+	}
+	if current != nil && current.CredentialID == id {
+		return current, &slcMetadata.statusListCredentialWatchKey, nil
 	}
 
-	var storedCreds []StoredCredential
-	for _, key := range keys {
-		credBytes, err := cs.db.Read(ctx, statusListCredentialNamespace, key)
-		if err != nil {
-			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-			continue
-		}
-		var cred StoredCredential
-		if err = json.Unmarshal(credBytes, &cred); err != nil {
-			logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
-		}
-		if ExtractID(cred.CredentialID) == id {
-			storedCreds = append(storedCreds, cred)
-		}
+	key := storage.WatchKey{Namespace: statusListCredentialNamespace, Key: fullStatusListKey(slcMetadata.statusListCredentialWatchKey.Key, id)}
+	fullBytes, err := tx.Read(ctx, key.Namespace, key.Key)
+	if err != nil {
+		return nil, nil, sdkutil.LoggingErrorMsgf(err, "reading status list credential: %s", id)
+	}
+	if len(fullBytes) == 0 {
+		return nil, nil, sdkutil.LoggingNewErrorf("could not get status list credential from storage %s with id: %s", credentialNotFoundErrMsg, id)
+	}
+	var full StoredCredential
+	if err = json.Unmarshal(fullBytes, &full); err != nil {
+		return nil, nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling status list credential: %s", id)
 	}
+	return &full, &key, nil
+}
 
+func (cs *Storage) GetStatusListCredential(ctx context.Context, id string) (*StoredCredential, error) {
+	keys, err := storage.ReadIndex(ctx, cs.db, statusListCredentialNamespace, idIndex, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for cred with id: %s", id)
+	}
+
+	storedCreds := cs.readCredentials(ctx, statusListCredentialNamespace, keys)
 	if len(storedCreds) == 0 {
-		logrus.Warnf("no credentials able to be retrieved for id: %s", id)
+		return nil, sdkutil.LoggingNewErrorf("could not get status list credential from storage %s with id: %s", credentialNotFoundErrMsg, id)
 	}
 
 	if len(storedCreds) > 1 {
@@ -269,9 +651,11 @@ func (cs *Storage) getStoreCredentialWriteContext(request StoreCredentialRequest
 	}
 
 	wc := WriteContext{
-		namespace: namespace,
-		key:       storedCredential.ID,
-		value:     storedCredBytes,
+		namespace:    namespace,
+		key:          storedCredential.ID,
+		value:        storedCredBytes,
+		indexEntries: storedCredential.indexEntries(),
+		credentialID: storedCredential.CredentialID,
 	}
 
 	return &wc, nil
@@ -282,7 +666,7 @@ func buildStoredCredential(request StoreCredentialRequest) (*StoredCredential, e
 	// assume we have a Data Integrity credential
 	cred := request.Credential
 	if request.HasJWTCredential() {
-		_, _, parsedCred, err := credential.ParseVerifiableCredentialFromJWT(request.CredentialJWT.String())
+		_, parsedCred, err := credint.ParseJWTCredential(request.CredentialJWT.String())
 		if err != nil {
 			return nil, errors.Wrap(err, "could not parse credential from jwt")
 		}
@@ -290,6 +674,13 @@ func buildStoredCredential(request StoreCredentialRequest) (*StoredCredential, e
 		// if we have a JWT credential, update the reference
 		cred = parsedCred
 	}
+	if request.HasSDJWTCredential() && cred == nil {
+		disclosedCred, err := credint.DisclosedCredential(*request.CredentialSDJWT)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse credential from sd-jwt")
+		}
+		cred = disclosedCred
+	}
 
 	credID := cred.ID
 	// Note: we assume the issuer is always a string for now
@@ -301,268 +692,574 @@ func buildStoredCredential(request StoreCredentialRequest) (*StoredCredential, e
 	if cred.CredentialSchema != nil {
 		schema = cred.CredentialSchema.ID
 	}
-	return &StoredCredential{
-		ID:            createPrefixKey(credID, issuer, subject, schema),
-		CredentialID:  credID,
-		Credential:    cred,
-		CredentialJWT: request.CredentialJWT,
-		Issuer:        issuer,
-		IssuerKID:     request.IssuerKID,
-		Subject:       subject,
-		Schema:        schema,
-		IssuanceDate:  cred.IssuanceDate,
-		Revoked:       request.Revoked,
-		Suspended:     request.Suspended,
-	}, nil
+	stored := StoredCredential{
+		ID:              createPrefixKey(credID, issuer, subject, schema),
+		CredentialID:    credID,
+		Credential:      cred,
+		CredentialJWT:   request.CredentialJWT,
+		CredentialSDJWT: request.CredentialSDJWT,
+		Issuer:          issuer,
+		IssuerKID:       request.IssuerKID,
+		Subject:         subject,
+		Schema:          schema,
+		IssuanceDate:    cred.IssuanceDate,
+		Revoked:         request.Revoked,
+		Suspended:       request.Suspended,
+		SuspendedUntil:  request.SuspendedUntil,
+		Expired:         request.Expired,
+	}
+	if err := stored.setStatusListEntry(); err != nil {
+		return nil, errors.Wrap(err, "recording credential status list")
+	}
+	return &stored, nil
 }
 
 func (cs *Storage) GetCredential(ctx context.Context, id string) (*StoredCredential, error) {
-	return cs.getCredential(ctx, id, credentialNamespace)
+	stored, err := cs.findCredential(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, sdkutil.LoggingNewErrorf("could not get credential from storage %s with id: %s", credentialNotFoundErrMsg, id)
+	}
+	return stored, nil
 }
 
-func (cs *Storage) getCredential(ctx context.Context, id string, namespace string) (*StoredCredential, error) {
-	prefixValues, err := cs.db.ReadPrefix(ctx, namespace, id)
+// CredentialExists returns whether a credential with the given id is stored.
+func (cs *Storage) CredentialExists(ctx context.Context, id string) (bool, error) {
+	stored, err := cs.findCredential(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return stored != nil, nil
+}
+
+// findCredential returns the credential with the given id, or nil when it is not stored. The credential is read from
+// the key recorded for its id. Credentials stored before such keys were recorded are looked up by the start of their
+// key, which is their id followed by their issuer, and then by their exact id, since an id can itself contain "-is:".
+func (cs *Storage) findCredential(ctx context.Context, id string) (*StoredCredential, error) {
+	key, err := cs.db.Read(ctx, credentialIDNamespace, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read key of credential: %s", id)
+	}
+	if key != nil {
+		credBytes, err := cs.db.Read(ctx, credentialNamespace, string(key))
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential from storage: %s", id)
+		}
+		if credBytes == nil {
+			return nil, nil
+		}
+		var stored StoredCredential
+		if err = json.Unmarshal(credBytes, &stored); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling stored credential: %s", id)
+		}
+		return &stored, nil
+	}
+
+	prefixValues, err := cs.db.ReadPrefix(ctx, credentialNamespace, id+"-is:")
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential from storage: %s", id)
 	}
-	if len(prefixValues) > 1 {
-		return nil, sdkutil.LoggingNewErrorf("could not get credential from storage; multiple prefix values matched credential id: %s", id)
+	var found *StoredCredential
+	for _, credBytes := range prefixValues {
+		var stored StoredCredential
+		if err = json.Unmarshal(credBytes, &stored); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling stored credential: %s", id)
+		}
+		if stored.CredentialID != id {
+			continue
+		}
+		if found != nil {
+			return nil, sdkutil.LoggingNewErrorf("could not get credential from storage; multiple credentials have id: %s", id)
+		}
+		found = &stored
 	}
+	return found, nil
+}
 
-	// since we know the map now only has a single value, we break after the first element
-	var credBytes []byte
-	for _, v := range prefixValues {
-		credBytes = v
-		break
+// StoreRefreshTx stores how the credential it is for is refreshed, replacing what was stored for it before.
+func (cs *Storage) StoreRefreshTx(ctx context.Context, tx storage.Tx, refresh StoredRefresh) error {
+	refreshBytes, err := json.Marshal(refresh)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "marshalling refresh of credential: %s", refresh.CredentialID)
 	}
-	if len(credBytes) == 0 {
-		return nil, sdkutil.LoggingNewErrorf("could not get credential from storage %s with id: %s", credentialNotFoundErrMsg, id)
+	return tx.Write(ctx, credentialRefreshNamespace, refresh.CredentialID, refreshBytes)
+}
+
+// GetRefreshTx gets how the credential with the given id is refreshed, or nil when it is not refreshable.
+func (cs *Storage) GetRefreshTx(ctx context.Context, tx storage.Tx, id string) (*StoredRefresh, error) {
+	refreshBytes, err := tx.Read(ctx, credentialRefreshNamespace, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get refresh of credential from storage: %s", id)
 	}
+	return unmarshalRefresh(id, refreshBytes)
+}
 
-	var stored StoredCredential
-	if err = json.Unmarshal(credBytes, &stored); err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling stored credential: %s", id)
+// GetRefresh gets how the credential with the given id is refreshed, or nil when it is not refreshable.
+func (cs *Storage) GetRefresh(ctx context.Context, id string) (*StoredRefresh, error) {
+	refreshBytes, err := cs.db.Read(ctx, credentialRefreshNamespace, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get refresh of credential from storage: %s", id)
 	}
-	return &stored, nil
+	return unmarshalRefresh(id, refreshBytes)
 }
 
-// Note: this is a lazy  implementation. Optimizations are to be had by adjusting prefix
-// queries, and nested buckets. It is not intended that bolt is run in production, or at any scale,
-// so this is not much of a concern.
+func unmarshalRefresh(id string, refreshBytes []byte) (*StoredRefresh, error) {
+	if len(refreshBytes) == 0 {
+		return nil, nil
+	}
+	var stored StoredRefresh
+	if err := json.Unmarshal(refreshBytes, &stored); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling refresh of credential: %s", id)
+	}
+	return &stored, nil
+}
 
-// GetCredentialsByIssuer gets all credentials stored with a prefix key containing the issuer value
-// The method is greedy, meaning if multiple values are found and some fail during processing, we will
-// return only the successful values and log an error for the failures.
-func (cs *Storage) GetCredentialsByIssuer(ctx context.Context, issuer string) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, credentialNamespace)
+// StoreVerificationPolicy stores the policy, unless a policy with the same name already exists, in which case false is
+// returned.
+func (cs *Storage) StoreVerificationPolicy(ctx context.Context, policy credint.Policy) (bool, error) {
+	policyBytes, err := json.Marshal(policy)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
+		return false, sdkutil.LoggingErrorMsgf(err, "marshalling verification policy<%s>", policy.Name)
 	}
-	// see if the prefix keys contains the issuer value
-	var issuerKeys []string
-	for _, k := range keys {
-		if strings.Contains(k, issuer) {
-			issuerKeys = append(issuerKeys, k)
+	stored, err := cs.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		existing, err := tx.Read(ctx, verificationPolicyNamespace, policy.Name)
+		if err != nil {
+			return false, err
 		}
+		if existing != nil {
+			return false, nil
+		}
+		return true, tx.Write(ctx, verificationPolicyNamespace, policy.Name, policyBytes)
+	}, []storage.WatchKey{{Namespace: verificationPolicyNamespace, Key: policy.Name}})
+	if err != nil {
+		return false, sdkutil.LoggingErrorMsgf(err, "storing verification policy<%s>", policy.Name)
 	}
-	if len(issuerKeys) == 0 {
-		logrus.Warnf("no credentials found for issuer: %s", util.SanitizeLog(issuer))
+	return stored.(bool), nil
+}
+
+// GetVerificationPolicy gets the policy with the given name, or nil when it does not exist.
+func (cs *Storage) GetVerificationPolicy(ctx context.Context, name string) (*credint.Policy, error) {
+	policyBytes, err := cs.db.Read(ctx, verificationPolicyNamespace, name)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "reading verification policy<%s>", name)
+	}
+	if len(policyBytes) == 0 {
 		return nil, nil
 	}
+	var policy credint.Policy
+	if err = json.Unmarshal(policyBytes, &policy); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling verification policy<%s>", name)
+	}
+	return &policy, nil
+}
 
-	// now get each credential by key
-	var storedCreds []StoredCredential
-	for _, key := range issuerKeys {
-		credBytes, err := cs.db.Read(ctx, credentialNamespace, key)
-		if err != nil {
-			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-		} else {
-			var cred StoredCredential
-			if err = json.Unmarshal(credBytes, &cred); err != nil {
-				logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
-			}
-			storedCreds = append(storedCreds, cred)
+// GetVerificationPolicies gets all the stored policies, sorted by name.
+func (cs *Storage) GetVerificationPolicies(ctx context.Context) ([]credint.Policy, error) {
+	policiesBytes, err := cs.db.ReadAll(ctx, verificationPolicyNamespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "reading verification policies")
+	}
+	policies := make([]credint.Policy, 0, len(policiesBytes))
+	for name, policyBytes := range policiesBytes {
+		var policy credint.Policy
+		if err = json.Unmarshal(policyBytes, &policy); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling verification policy<%s>", name)
 		}
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	return policies, nil
+}
+
+// DeleteVerificationPolicy deletes the policy with the given name.
+func (cs *Storage) DeleteVerificationPolicy(ctx context.Context, name string) error {
+	if err := cs.db.Delete(ctx, verificationPolicyNamespace, name); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "deleting verification policy<%s>", name)
 	}
+	return nil
+}
 
+// GetCredentialsByIssuer gets a page of the credentials issued by the given issuer.
+// The method is greedy, meaning if multiple values are found and some fail during processing, we will
+// return only the successful values and log an error for the failures.
+func (cs *Storage) GetCredentialsByIssuer(ctx context.Context, issuer string, page framework.PageRequest) ([]StoredCredential, string, error) {
+	issuerKeys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, issuerIndex, issuer)
+	if err != nil {
+		return nil, "", sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
+	}
+	if len(issuerKeys) == 0 {
+		logrus.Warnf("no credentials found for issuer: %s", util.SanitizeLog(issuer))
+		return nil, "", nil
+	}
+
+	storedCreds, nextPageToken, err := cs.getCredentialsPage(ctx, issuerKeys, page)
+	if err != nil {
+		return nil, "", err
+	}
 	if len(storedCreds) == 0 {
 		logrus.Warnf("no credentials able to be retrieved for issuer: %s", issuerKeys)
 	}
 
-	return storedCreds, nil
+	return storedCreds, nextPageToken, nil
 }
 
-// GetCredentialsBySubject gets all credentials stored with a prefix key containing the subject value
+// GetCredentialsBySubject gets a page of the credentials whose subject is the given subject.
 // The method is greedy, meaning if multiple values are found...and some fail during processing, we will
 // return only the successful values and log an error for the failures.
-func (cs *Storage) GetCredentialsBySubject(ctx context.Context, subject string) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, credentialNamespace)
+func (cs *Storage) GetCredentialsBySubject(ctx context.Context, subject string, page framework.PageRequest) ([]StoredCredential, string, error) {
+	subjectKeys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, subjectIndex, subject)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for subject: %s", subject)
-	}
-
-	// see if the prefix keys contains the subject value
-	var subjectKeys []string
-	for _, k := range keys {
-		if strings.Contains(k, subject) {
-			subjectKeys = append(subjectKeys, k)
-		}
+		return nil, "", sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for subject: %s", subject)
 	}
 	if len(subjectKeys) == 0 {
 		logrus.Warnf("no credentials found for subject: %s", util.SanitizeLog(subject))
-		return nil, nil
+		return nil, "", nil
 	}
 
-	// now get each credential by key
-	var storedCreds []StoredCredential
-	for _, key := range subjectKeys {
-		credBytes, err := cs.db.Read(ctx, credentialNamespace, key)
-		if err != nil {
-			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-		} else {
-			var cred StoredCredential
-			if err := json.Unmarshal(credBytes, &cred); err != nil {
-				logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
-			}
-			storedCreds = append(storedCreds, cred)
-		}
+	storedCreds, nextPageToken, err := cs.getCredentialsPage(ctx, subjectKeys, page)
+	if err != nil {
+		return nil, "", err
 	}
-
 	if len(storedCreds) == 0 {
 		logrus.Warnf("no credentials able to be retrieved for subject: %s", subjectKeys)
 	}
 
-	return storedCreds, nil
+	return storedCreds, nextPageToken, nil
 }
 
-// GetCredentialsBySchema gets all credentials stored with a prefix key containing the schema value
+// GetCredentialsBySchema gets a page of the credentials which conform to the given schema.
 // The method is greedy, meaning if multiple values are found...and some fail during processing, we will
 // return only the successful values and log an error for the failures.
-func (cs *Storage) GetCredentialsBySchema(ctx context.Context, schema string) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, credentialNamespace)
+func (cs *Storage) GetCredentialsBySchema(ctx context.Context, schema string, page framework.PageRequest) ([]StoredCredential, string, error) {
+	schemaKeys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, schemaIndex, schema)
+	if err != nil {
+		return nil, "", sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for schema: %s", schema)
+	}
+	if len(schemaKeys) == 0 {
+		logrus.Warnf("no credentials found for schema: %s", util.SanitizeLog(schema))
+		return nil, "", nil
+	}
+
+	storedCreds, nextPageToken, err := cs.getCredentialsPage(ctx, schemaKeys, page)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(storedCreds) == 0 {
+		logrus.Warnf("no credentials able to be retrieved for schema: %s", schemaKeys)
+	}
+
+	return storedCreds, nextPageToken, nil
+}
+
+// ListCredentials returns the requested page of the credentials that match the filter, sorted by orderBy, or by their
+// keys when it has no fields. Filters on the issuer, subject or schema alone are answered from their index, while any
+// other filter reads all the credentials. Credentials on which the filter fails to evaluate are included.
+func (cs *Storage) ListCredentials(ctx context.Context, filter filtering.Filter, orderBy ordering.OrderBy, page framework.PageRequest) ([]StoredCredential, string, error) {
+	candidates, err := cs.readFilterCandidates(ctx, filter)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for schema: %s", schema)
+		return nil, "", err
 	}
 
-	// see if the prefix keys contains the schema value
-	query := "sc:" + schema
-	var schemaKeys []string
-	for _, k := range keys {
-		if strings.HasSuffix(k, query) {
-			schemaKeys = append(schemaKeys, k)
+	shouldInclude, err := storage.NewIncludeFunc(filter)
+	if err != nil {
+		return nil, "", err
+	}
+	storedCreds := make([]StoredCredential, 0, len(candidates))
+	for _, cred := range candidates {
+		include, err := shouldInclude(cred)
+		// We explicitly ignore evaluation errors and simply include them in the result.
+		if err != nil || include {
+			storedCreds = append(storedCreds, cred)
 		}
 	}
-	if len(schemaKeys) == 0 {
-		logrus.Warnf("no credentials found for schema: %s", util.SanitizeLog(schema))
-		return nil, nil
+	sortCredentials(storedCreds, orderBy)
+
+	start, end, nextPageToken, err := storage.PaginateOffset(len(storedCreds), page.PageToken, page.PageSize)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "paginating credentials")
+	}
+	return storedCreds[start:end], nextPageToken, nil
+}
+
+// readFilterCandidates reads the credentials the filter may match.
+func (cs *Storage) readFilterCandidates(ctx context.Context, filter filtering.Filter) ([]StoredCredential, error) {
+	for ident, index := range map[string]string{"issuer": issuerIndex, "subject": subjectIndex, "schema": schemaIndex} {
+		value, ok := storage.EqualityFilterValue(filter, ident)
+		if !ok {
+			continue
+		}
+		keys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, index, value)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for %s: %s", ident, value)
+		}
+		return cs.readCredentials(ctx, credentialNamespace, keys), nil
+	}
+
+	allData, err := cs.db.ReadAll(ctx, credentialNamespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not read all credentials")
+	}
+	storedCreds := make([]StoredCredential, 0, len(allData))
+	for key, data := range allData {
+		var cred StoredCredential
+		if err = json.Unmarshal(data, &cred); err != nil {
+			logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
+			continue
+		}
+		storedCreds = append(storedCreds, cred)
+	}
+	return storedCreds, nil
+}
+
+// sortCredentials sorts the credentials by the fields of orderBy, which are names of their filter variables, and then
+// by their keys.
+func sortCredentials(creds []StoredCredential, orderBy ordering.OrderBy) {
+	vars := make(map[string]map[string]any, len(creds))
+	for _, cred := range creds {
+		vars[cred.ID] = cred.FilterVariablesMap()
+	}
+	sort.SliceStable(creds, func(i, j int) bool {
+		for _, field := range orderBy.Fields {
+			c := compareFilterValues(vars[creds[i].ID][field.Path], vars[creds[j].ID][field.Path])
+			if c == 0 {
+				continue
+			}
+			if field.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return creds[i].ID < creds[j].ID
+	})
+}
+
+// compareFilterValues compares two filter variables of the same kind, returning -1, 0 or 1. Values which are missing
+// or cannot be compared are equal.
+func compareFilterValues(a, b any) int {
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok && av != bv {
+			if av {
+				return 1
+			}
+			return -1
+		}
 	}
+	return 0
+}
 
-	// now get each credential by key
+// getCredentialsPage reads the credentials for the requested page of the given keys. Only the keys are held in memory
+// while paging, and the credentials themselves are read only for the keys in the page.
+func (cs *Storage) getCredentialsPage(ctx context.Context, keys []string, page framework.PageRequest) ([]StoredCredential, string, error) {
+	pageKeys, nextPageToken, err := storage.PaginateKeys(keys, page.PageToken, page.PageSize)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "paginating credential keys")
+	}
+	return cs.readCredentials(ctx, credentialNamespace, pageKeys), nextPageToken, nil
+}
+
+// readCredentials reads the credentials stored under each of the keys, skipping those which cannot be read.
+func (cs *Storage) readCredentials(ctx context.Context, namespace string, keys []string) []StoredCredential {
 	var storedCreds []StoredCredential
-	for _, key := range schemaKeys {
-		credBytes, err := cs.db.Read(ctx, credentialNamespace, key)
+	for _, key := range keys {
+		credBytes, err := cs.db.Read(ctx, namespace, key)
 		if err != nil {
 			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
 		} else {
 			var cred StoredCredential
-			if err := json.Unmarshal(credBytes, &cred); err != nil {
+			if err = json.Unmarshal(credBytes, &cred); err != nil {
 				logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
 			}
 			storedCreds = append(storedCreds, cred)
 		}
 	}
-
-	if len(storedCreds) == 0 {
-		logrus.Warnf("no credentials able to be retrieved for schema: %s", schemaKeys)
-	}
-
-	return storedCreds, nil
+	return storedCreds
 }
 
-// GetCredentialsByIssuerAndSchema gets all credentials stored with a prefix key containing the issuer value
+// GetCredentialsByIssuerAndSchema gets all credentials issued by the given issuer which conform to the given schema.
 // The method is greedy, meaning if multiple values are found...and some fail during processing, we will
 // return only the successful values and log an error for the failures.
 func (cs *Storage) GetCredentialsByIssuerAndSchema(ctx context.Context, issuer string, schema string) ([]StoredCredential, error) {
 	return cs.getCredentialsByIssuerAndSchema(ctx, issuer, schema, credentialNamespace)
 }
 
+// GetStatusListCredentialsByIssuerSchemaPurpose gets the status list credential for the <issuer, schema, statusPurpose>
+// triplet, which is stored under the key built from the triplet.
 func (cs *Storage) GetStatusListCredentialsByIssuerSchemaPurpose(ctx context.Context, issuer string, schema string, statusPurpose statussdk.StatusPurpose) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, statusListCredentialNamespace)
+	credBytes, err := cs.db.Read(ctx, statusListCredentialNamespace, getStatusListKey(issuer, schema, string(statusPurpose)))
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
 	}
 
-	query := "sc:" + schema + "-sp:" + string(statusPurpose)
-	var issuerSchemaKeys []string
-	for _, k := range keys {
-		if strings.Contains(k, issuer) && strings.HasSuffix(k, query) {
-			issuerSchemaKeys = append(issuerSchemaKeys, k)
-		}
-	}
-
-	if len(issuerSchemaKeys) == 0 {
+	if len(credBytes) == 0 {
 		logrus.Warnf("no status list credentials found for issuer: %s schema %s and status purpose %s", util.SanitizeLog(issuer), util.SanitizeLog(schema), util.SanitizeLog(string(statusPurpose)))
 		return nil, nil
 	}
 
-	// now get each credential by key
-	storedCreds := make([]StoredCredential, 0, len(issuerSchemaKeys))
-	for _, key := range issuerSchemaKeys {
-		credBytes, err := cs.db.Read(ctx, statusListCredentialNamespace, key)
+	var cred StoredCredential
+	if err = json.Unmarshal(credBytes, &cred); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling status list credential for issuer: %s", issuer)
+	}
+	return []StoredCredential{cred}, nil
+}
+
+// GetCredentialsByStatusList gets all credentials whose status is held by the status list credential with the given
+// id.
+func (cs *Storage) GetCredentialsByStatusList(ctx context.Context, statusListCredentialID string) ([]StoredCredential, error) {
+	keys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, statusListCredentialIndex, statusListCredentialID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for status list: %s", statusListCredentialID)
+	}
+	return cs.readCredentials(ctx, credentialNamespace, keys), nil
+}
+
+// GetCredentialKeysPendingExpiration returns the keys of the credentials which have an expiration date, and are not
+// yet marked as expired.
+func (cs *Storage) GetCredentialKeysPendingExpiration(ctx context.Context) ([]string, error) {
+	keys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, pendingExpirationIndex, pendingIndexValue)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not read credential storage while searching for creds pending expiration")
+	}
+	return keys, nil
+}
+
+// GetTemporarilySuspendedCredentials gets all credentials whose suspension is lifted at a set time.
+func (cs *Storage) GetTemporarilySuspendedCredentials(ctx context.Context) ([]StoredCredential, error) {
+	keys, err := storage.ReadIndex(ctx, cs.db, credentialNamespace, temporarySuspensionIndex, pendingIndexValue)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not read credential storage while searching for temporarily suspended creds")
+	}
+	return cs.readCredentials(ctx, credentialNamespace, keys), nil
+}
+
+// MarkCredentialExpired marks the credential stored under the given key as expired when its expiration date is at or
+// before now, and returns whether it did.
+func (cs *Storage) MarkCredentialExpired(ctx context.Context, key string, now time.Time) (bool, error) {
+	marked, err := cs.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		credBytes, err := tx.Read(ctx, credentialNamespace, key)
 		if err != nil {
-			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-			return nil, err
+			return false, errors.Wrapf(err, "reading credential with key: %s", key)
+		}
+		if credBytes == nil {
+			return false, nil
+		}
+		var stored StoredCredential
+		if err = json.Unmarshal(credBytes, &stored); err != nil {
+			return false, errors.Wrapf(err, "unmarshalling credential with key: %s", key)
+		}
+		if stored.Expired || stored.Credential == nil || stored.Credential.ExpirationDate == "" {
+			return false, nil
+		}
+		expirationDate, err := time.Parse(time.RFC3339, stored.Credential.ExpirationDate)
+		if err != nil {
+			return false, errors.Wrapf(err, "parsing expiration date of credential: %s", stored.CredentialID)
+		}
+		if expirationDate.After(now) {
+			return false, nil
 		}
 
-		var cred StoredCredential
-		if err = json.Unmarshal(credBytes, &cred); err != nil {
-			logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
+		stored.Expired = true
+		credBytes, err = json.Marshal(stored)
+		if err != nil {
+			return false, errors.Wrapf(err, "marshalling credential: %s", stored.CredentialID)
 		}
+		if err = storage.WriteIndexedTx(ctx, tx, credentialNamespace, key, credBytes, stored.indexEntries()...); err != nil {
+			return false, errors.Wrapf(err, "storing credential: %s", stored.CredentialID)
+		}
+		return true, nil
+	}, []storage.WatchKey{{Namespace: credentialNamespace, Key: key}})
+	if err != nil {
+		return false, err
+	}
+	return marked.(bool), nil
+}
 
-		storedCreds = append(storedCreds, cred)
+// StatusListUtilization describes a status list credential, and how many of its indexes are allocated.
+type StatusListUtilization struct {
+	StatusListCredentialID string
+	Issuer                 string
+	Schema                 string
+	StatusPurpose          statussdk.StatusPurpose
+	Size                   int
+	Allocated              int
+	// Whether new credentials are allocated indexes of the status list. The other status lists of its issuer, schema
+	// and purpose are full.
+	Current bool
+}
+
+// GetStatusListUtilizationsByIssuer describes every status list credential of the given issuer.
+func (cs *Storage) GetStatusListUtilizationsByIssuer(ctx context.Context, issuer string) ([]StatusListUtilization, error) {
+	keys, err := storage.ReadIndex(ctx, cs.db, statusListCredentialNamespace, issuerIndex, issuer)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read status list credentials of issuer: %s", issuer)
 	}
 
-	if len(storedCreds) == 0 {
-		logrus.Warnf("no credentials able to be retrieved for issuer: %s", issuerSchemaKeys)
+	utilizations := make([]StatusListUtilization, 0, len(keys))
+	for _, key := range keys {
+		storedBytes, err := cs.db.Read(ctx, statusListCredentialNamespace, key)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "reading status list credential with key: %s", key)
+		}
+		if len(storedBytes) == 0 {
+			continue
+		}
+		var stored StoredCredential
+		if err = json.Unmarshal(storedBytes, &stored); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling status list credential with key: %s", key)
+		}
+
+		statusPurpose, _ := stored.Credential.CredentialSubject["statusPurpose"].(string)
+		utilization := StatusListUtilization{
+			StatusListCredentialID: stored.CredentialID,
+			Issuer:                 stored.Issuer,
+			Schema:                 stored.Schema,
+			StatusPurpose:          statussdk.StatusPurpose(statusPurpose),
+			Size:                   stored.statusListSize(),
+			Allocated:              stored.statusListSize(),
+			Current:                key == getStatusListKey(stored.Issuer, stored.Schema, statusPurpose),
+		}
+		if utilization.Current {
+			currentIndexBytes, err := cs.db.Read(ctx, statusListCredentialCurrentIndex, key)
+			if err != nil {
+				return nil, sdkutil.LoggingErrorMsgf(err, "reading current index of status list credential: %s", stored.CredentialID)
+			}
+			var currentIndex StatusListIndex
+			if err = json.Unmarshal(currentIndexBytes, &currentIndex); err != nil {
+				return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling current index of status list credential: %s", stored.CredentialID)
+			}
+			utilization.Allocated = currentIndex.Index
+		}
+		utilizations = append(utilizations, utilization)
 	}
 
-	return storedCreds, nil
+	sort.Slice(utilizations, func(i, j int) bool {
+		return utilizations[i].StatusListCredentialID < utilizations[j].StatusListCredentialID
+	})
+	return utilizations, nil
 }
 
 func (cs *Storage) getCredentialsByIssuerAndSchema(ctx context.Context, issuer string, schema string, namespace string) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, namespace)
+	issuerSchemaKeys, err := storage.ReadIndex(ctx, cs.db, namespace, issuerSchemaIndex, issuer, schema)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
 	}
 
-	query := "sc:" + schema
-	var issuerSchemaKeys []string
-	for _, k := range keys {
-		if strings.Contains(k, issuer) && strings.HasSuffix(k, query) {
-			issuerSchemaKeys = append(issuerSchemaKeys, k)
-		}
-	}
-
 	if len(issuerSchemaKeys) == 0 {
 		logrus.Warnf("no credentials found for issuer: %s and schema %s", util.SanitizeLog(issuer), util.SanitizeLog(schema))
 		return nil, nil
 	}
 
-	// now get each credential by key
-	var storedCreds []StoredCredential
-	for _, key := range issuerSchemaKeys {
-		credBytes, err := cs.db.Read(ctx, namespace, key)
-		if err != nil {
-			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-		} else {
-			var cred StoredCredential
-			if err = json.Unmarshal(credBytes, &cred); err != nil {
-				logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
-			}
-			storedCreds = append(storedCreds, cred)
-		}
-	}
-
+	storedCreds := cs.readCredentials(ctx, namespace, issuerSchemaKeys)
 	if len(storedCreds) == 0 {
 		logrus.Warnf("no credentials able to be retrieved for issuer: %s", issuerSchemaKeys)
 	}
@@ -571,7 +1268,23 @@ func (cs *Storage) getCredentialsByIssuerAndSchema(ctx context.Context, issuer s
 }
 
 func (cs *Storage) DeleteCredential(ctx context.Context, id string) error {
-	return cs.deleteCredential(ctx, id, credentialNamespace)
+	if err := cs.deleteCredential(ctx, id, credentialNamespace); err != nil {
+		return err
+	}
+	if err := cs.db.Delete(ctx, credentialIDNamespace, id); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not delete key of credential: %s", id)
+	}
+	refreshable, err := cs.db.Exists(ctx, credentialRefreshNamespace, id)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not get refresh of credential from storage: %s", id)
+	}
+	if !refreshable {
+		return nil
+	}
+	if err = cs.db.Delete(ctx, credentialRefreshNamespace, id); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not delete refresh of credential: %s", id)
+	}
+	return nil
 }
 
 func (cs *Storage) DeleteStatusListCredential(ctx context.Context, id string) error {
@@ -601,12 +1314,22 @@ func (cs *Storage) deleteCredential(ctx context.Context, id string, namespace st
 
 	// re-create the prefix key to delete
 	prefix := createPrefixKey(id, gotCred.Issuer, gotCred.Subject, gotCred.Schema)
-	if err = cs.db.Delete(ctx, namespace, prefix); err != nil {
+	if err = storage.DeleteIndexed(ctx, cs.db, namespace, prefix); err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "could not delete credential: %s", id)
 	}
 	return nil
 }
 
+// GetCredentialIDWatchKey returns the key of the storage key of the credential with the given id.
+func (cs *Storage) GetCredentialIDWatchKey(id string) storage.WatchKey {
+	return storage.WatchKey{Namespace: credentialIDNamespace, Key: id}
+}
+
+// GetRefreshWatchKey returns the key of the refresh of the credential with the given id.
+func (cs *Storage) GetRefreshWatchKey(id string) storage.WatchKey {
+	return storage.WatchKey{Namespace: credentialRefreshNamespace, Key: id}
+}
+
 func (cs *Storage) GetStatusListCredentialWatchKey(issuer, schema, statusPurpose string) storage.WatchKey {
 	return storage.WatchKey{Namespace: statusListCredentialNamespace, Key: getStatusListKey(issuer, schema, statusPurpose)}
 }
@@ -619,6 +1342,12 @@ func (cs *Storage) GetStatusListCurrentIndexWatchKey(issuer, schema, statusPurpo
 	return storage.WatchKey{Namespace: statusListCredentialCurrentIndex, Key: getStatusListKey(issuer, schema, statusPurpose)}
 }
 
+// GetFullStatusListCredentialWatchKey returns the key of the status list credential with the given id, once it was
+// full and replaced by another status list of the same issuer, schema and purpose.
+func (cs *Storage) GetFullStatusListCredentialWatchKey(issuer, schema, statusPurpose, statusListCredentialID string) storage.WatchKey {
+	return storage.WatchKey{Namespace: statusListCredentialNamespace, Key: fullStatusListKey(getStatusListKey(issuer, schema, statusPurpose), statusListCredentialID)}
+}
+
 func (cs *Storage) GetStatusListCredentialKeyData(ctx context.Context, issuer string, schema string, statusPurpose statussdk.StatusPurpose) (*StoredCredential, error) {
 	storedStatusListCreds, err := cs.GetStatusListCredentialsByIssuerSchemaPurpose(ctx, issuer, schema, statusPurpose)
 	if err != nil {
@@ -657,6 +1386,12 @@ func getStatusListKey(issuer, schema, statusPurpose string) string {
 	return strings.Join([]string{"is:" + issuer, "sc:" + schema, "sp:" + statusPurpose}, "-")
 }
 
+// fullStatusListKey returns the key of a full status list credential, from the key of the status lists of its issuer,
+// schema and purpose.
+func fullStatusListKey(statusListKey, statusListCredentialID string) string {
+	return strings.Join([]string{statusListKey, "id:" + ExtractID(statusListCredentialID)}, "-")
+}
+
 // unique key for a credential
 func createPrefixKey(id, issuer, subject, schema string) string {
 	return strings.Join([]string{id, "is:" + issuer, "su:" + subject, "sc:" + schema}, "-")
@@ -1,9 +1,19 @@
 package credential
 
 import (
+	"context"
+	"os"
 	"testing"
 
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 func TestCredentialStorage(t *testing.T) {
@@ -24,4 +34,127 @@ func TestCredentialStorage(t *testing.T) {
 		uuid = ExtractID("badinput")
 		assert.Empty(tt, uuid)
 	})
+
+	t.Run("Migrate credentials stored before their status list was recorded", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		cs, err := NewCredentialStorage(db)
+		require.NoError(tt, err)
+
+		statusListID := "http://localhost:1234/v1/credentials/status/ceccb36e-a386-494a-8bf5-d86f953485dd"
+		toStore := StoredCredential{
+			ID:           "legacy",
+			CredentialID: "legacy",
+			Credential: &credsdk.VerifiableCredential{
+				ID: "legacy",
+				CredentialStatus: statussdk.StatusList2021Entry{
+					ID:                   "legacy/status",
+					Type:                 statussdk.StatusList2021EntryType,
+					StatusPurpose:        statussdk.StatusRevocation,
+					StatusListIndex:      "42",
+					StatusListCredential: statusListID,
+				},
+			},
+			Issuer: "did:example:issuer",
+		}
+		credBytes, err := json.Marshal(toStore)
+		require.NoError(tt, err)
+		require.NoError(tt, db.Write(context.Background(), credentialNamespace, toStore.ID, credBytes))
+
+		gotCreds, err := cs.GetCredentialsByStatusList(context.Background(), statusListID)
+		assert.NoError(tt, err)
+		assert.Empty(tt, gotCreds)
+
+		_, err = storage.RunMigrations(context.Background(), db, storage.RegisteredMigrations(), false)
+		assert.NoError(tt, err)
+
+		gotCreds, err = cs.GetCredentialsByStatusList(context.Background(), statusListID)
+		assert.NoError(tt, err)
+		require.Len(tt, gotCreds, 1)
+		assert.Equal(tt, statusListID, gotCreds[0].StatusListCredentialID)
+		assert.Equal(tt, 42, gotCreds[0].StatusListIndex)
+	})
+
+	t.Run("Get credentials whose id starts with the id of another", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		cs, err := NewCredentialStorage(db)
+		require.NoError(tt, err)
+
+		for _, id := range []string{"urn:example:1", "urn:example:10"} {
+			_, err = db.Execute(context.Background(), func(ctx context.Context, tx storage.Tx) (any, error) {
+				return nil, cs.StoreNewCredentialTx(ctx, tx, testStoreCredentialRequest(id))
+			}, []storage.WatchKey{cs.GetCredentialIDWatchKey(id)})
+			require.NoError(tt, err)
+		}
+
+		gotCred, err := cs.GetCredential(context.Background(), "urn:example:1")
+		assert.NoError(tt, err)
+		require.NotNil(tt, gotCred)
+		assert.Equal(tt, "urn:example:1", gotCred.CredentialID)
+
+		require.NoError(tt, cs.DeleteCredential(context.Background(), "urn:example:1"))
+		exists, err := cs.CredentialExists(context.Background(), "urn:example:1")
+		assert.NoError(tt, err)
+		assert.False(tt, exists)
+		exists, err = cs.CredentialExists(context.Background(), "urn:example:10")
+		assert.NoError(tt, err)
+		assert.True(tt, exists)
+
+		_, err = db.Execute(context.Background(), func(ctx context.Context, tx storage.Tx) (any, error) {
+			return nil, cs.StoreNewCredentialTx(ctx, tx, testStoreCredentialRequest("urn:example:10"))
+		}, []storage.WatchKey{cs.GetCredentialIDWatchKey("urn:example:10")})
+		assert.ErrorContains(tt, err, "credential with id<urn:example:10> already exists")
+	})
+
+	t.Run("Get credentials stored before their key was recorded", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		cs, err := NewCredentialStorage(db)
+		require.NoError(tt, err)
+
+		for _, id := range []string{"urn:example:1", "urn:example:1-is:did:example:issuer"} {
+			stored, err := buildStoredCredential(testStoreCredentialRequest(id))
+			require.NoError(tt, err)
+			credBytes, err := json.Marshal(stored)
+			require.NoError(tt, err)
+			require.NoError(tt, db.Write(context.Background(), credentialNamespace, stored.ID, credBytes))
+		}
+
+		gotCred, err := cs.GetCredential(context.Background(), "urn:example:1")
+		assert.NoError(tt, err)
+		require.NotNil(tt, gotCred)
+		assert.Equal(tt, "urn:example:1", gotCred.CredentialID)
+
+		_, err = cs.GetCredential(context.Background(), "urn:example")
+		assert.ErrorContains(tt, err, credentialNotFoundErrMsg)
+	})
+}
+
+func testStoreCredentialRequest(id string) StoreCredentialRequest {
+	var proof crypto.Proof = map[string]any{"type": "JsonWebSignature2020"}
+	return StoreCredentialRequest{
+		Container: credint.Container{
+			ID: id,
+			Credential: &credsdk.VerifiableCredential{
+				ID:                id,
+				Issuer:            "did:example:issuer",
+				IssuanceDate:      "2023-01-01T00:00:00Z",
+				CredentialSubject: credsdk.CredentialSubject{"id": "did:example:subject"},
+				Proof:             &proof,
+			},
+		},
+	}
+}
+
+func setupTestDB(t *testing.T) storage.ServiceStorage {
+	file, err := os.CreateTemp("", "bolt")
+	require.NoError(t, err)
+	name := file.Name()
+	err = file.Close()
+	require.NoError(t, err)
+	s, err := storage.NewStorage(storage.Bolt, name)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+		_ = os.Remove(s.URI())
+	})
+	return s
 }
@@ -6,6 +6,8 @@ import (
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 )
 
 // MethodHandler describes the functionality of *all* possible DID service, regardless of method
@@ -16,7 +18,7 @@ type MethodHandler interface {
 	CreateDID(ctx context.Context, request CreateDIDRequest) (*CreateDIDResponse, error)
 	// TODO(gabe): support query parameters to get soft deleted and other DIDs https://github.com/TBD54566975/ssi-service/issues/364
 	GetDID(ctx context.Context, request GetDIDRequest) (*GetDIDResponse, error)
-	GetDIDs(ctx context.Context) (*GetDIDsResponse, error)
+	GetDIDs(ctx context.Context, page framework.PageRequest) (*GetDIDsResponse, error)
 	SoftDeleteDID(ctx context.Context, request DeleteDIDRequest) error
 }
 
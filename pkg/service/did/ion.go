@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 )
 
@@ -254,20 +255,18 @@ func (h *ionHandler) GetDID(ctx context.Context, request GetDIDRequest) (*GetDID
 }
 
 // GetDIDs returns all DIDs we have in storage for ION, it is not feasible to get all DIDs from the network
-func (h *ionHandler) GetDIDs(ctx context.Context) (*GetDIDsResponse, error) {
+func (h *ionHandler) GetDIDs(ctx context.Context, page framework.PageRequest) (*GetDIDsResponse, error) {
 	logrus.Debug("getting stored did:ion DIDs")
 
-	gotDIDs, err := h.storage.GetDIDs(ctx, did.KeyMethod.String(), new(ionStoredDID))
+	gotDIDs, nextPageToken, err := h.storage.GetActiveDIDsPage(ctx, did.KeyMethod.String(), page, new(ionStoredDID))
 	if err != nil {
 		return nil, fmt.Errorf("error getting did:ion DIDs")
 	}
 	dids := make([]did.Document, 0, len(gotDIDs))
 	for _, gotDID := range gotDIDs {
-		if !gotDID.IsSoftDeleted() {
-			dids = append(dids, gotDID.GetDocument())
-		}
+		dids = append(dids, gotDID.GetDocument())
 	}
-	return &GetDIDsResponse{DIDs: dids}, nil
+	return &GetDIDsResponse{DIDs: dids, NextPageToken: nextPageToken}, nil
 }
 
 // SoftDeleteDID soft deletes a DID from storage but has no effect on the DID's state on the network
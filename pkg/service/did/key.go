@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 )
 
@@ -99,20 +100,18 @@ func (h *keyHandler) GetDID(ctx context.Context, request GetDIDRequest) (*GetDID
 	return &GetDIDResponse{DID: gotDID.DID}, nil
 }
 
-func (h *keyHandler) GetDIDs(ctx context.Context) (*GetDIDsResponse, error) {
+func (h *keyHandler) GetDIDs(ctx context.Context, page framework.PageRequest) (*GetDIDsResponse, error) {
 	logrus.Debug("getting did:key DIDs")
 
-	gotDIDs, err := h.storage.GetDIDsDefault(ctx, did.KeyMethod.String())
+	gotDIDs, nextPageToken, err := h.storage.GetActiveDIDsPageDefault(ctx, did.KeyMethod.String(), page)
 	if err != nil {
 		return nil, fmt.Errorf("error getting did:key DIDs")
 	}
 	dids := make([]did.Document, 0, len(gotDIDs))
 	for _, gotDID := range gotDIDs {
-		if !gotDID.IsSoftDeleted() {
-			dids = append(dids, gotDID.GetDocument())
-		}
+		dids = append(dids, gotDID.GetDocument())
 	}
-	return &GetDIDsResponse{DIDs: dids}, nil
+	return &GetDIDsResponse{DIDs: dids, NextPageToken: nextPageToken}, nil
 }
 
 func (h *keyHandler) SoftDeleteDID(ctx context.Context, request DeleteDIDRequest) error {
@@ -5,6 +5,8 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 )
 
 type GetSupportedMethodsResponse struct {
@@ -63,12 +65,14 @@ type GetKeyFromDIDResponse struct {
 }
 
 type GetDIDsRequest struct {
-	Method didsdk.Method `json:"method" validate:"required"`
+	Method      didsdk.Method `json:"method" validate:"required"`
+	PageRequest framework.PageRequest
 }
 
 // GetDIDsResponse is the JSON-serializable response for getting all DIDs for a given method
 type GetDIDsResponse struct {
-	DIDs []didsdk.Document `json:"dids"`
+	DIDs          []didsdk.Document `json:"dids"`
+	NextPageToken string            `json:"nextPageToken,omitempty"`
 }
 
 type DeleteDIDRequest struct {
@@ -0,0 +1,94 @@
+package resolution
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+// remoteResolutionCacheTTL is how long DIDs resolved by a remote resolver are cached for.
+const remoteResolutionCacheTTL = 5 * time.Minute
+
+// cachingResolver caches the resolution results of a remote resolver, such as the universal resolver, for a while.
+type cachingResolver struct {
+	resolver didsdk.Resolver
+
+	mu    sync.Mutex
+	cache map[string]cachedResolution
+}
+
+type cachedResolution struct {
+	result   didsdk.ResolutionResult
+	expiry   time.Time
+	lastUsed time.Time
+}
+
+var _ didsdk.Resolver = (*cachingResolver)(nil)
+
+func newCachingResolver(resolver didsdk.Resolver) *cachingResolver {
+	return &cachingResolver{resolver: resolver, cache: make(map[string]cachedResolution)}
+}
+
+func (cr *cachingResolver) Resolve(ctx context.Context, did string, opts ...didsdk.ResolutionOption) (*didsdk.ResolutionResult, error) {
+	now := time.Now()
+	cr.mu.Lock()
+	cached, ok := cr.cache[did]
+	if ok && now.Before(cached.expiry) {
+		cached.lastUsed = now
+		cr.cache[did] = cached
+		cr.mu.Unlock()
+		return &cached.result, nil
+	}
+	cr.mu.Unlock()
+
+	result, err := cr.resolver.Resolve(ctx, did, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cr.mu.Lock()
+	cr.cache[did] = cachedResolution{result: *result, expiry: now.Add(remoteResolutionCacheTTL), lastUsed: now}
+	cr.mu.Unlock()
+	return result, nil
+}
+
+func (cr *cachingResolver) Methods() []didsdk.Method {
+	return cr.resolver.Methods()
+}
+
+// Refresh resolves the cached DIDs again, so that resolving them does not wait on the remote resolver once they expire.
+// DIDs which were not resolved within the cache TTL are dropped instead, and those which cannot be resolved are kept
+// until they expire.
+func (cr *cachingResolver) Refresh(ctx context.Context) error {
+	now := time.Now()
+	cr.mu.Lock()
+	dids := make([]string, 0, len(cr.cache))
+	for did, cached := range cr.cache {
+		if now.Sub(cached.lastUsed) > remoteResolutionCacheTTL {
+			delete(cr.cache, did)
+			continue
+		}
+		dids = append(dids, did)
+	}
+	cr.mu.Unlock()
+
+	ae := sdkutil.NewAppendError()
+	for _, did := range dids {
+		result, err := cr.resolver.Resolve(ctx, did)
+		if err != nil {
+			ae.Append(errors.Wrapf(err, "resolving DID<%s>", did))
+			continue
+		}
+		cr.mu.Lock()
+		if cached, ok := cr.cache[did]; ok {
+			cached.result = *result
+			cached.expiry = time.Now().Add(remoteResolutionCacheTTL)
+			cr.cache[did] = cached
+		}
+		cr.mu.Unlock()
+	}
+	return ae.Error()
+}
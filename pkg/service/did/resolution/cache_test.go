@@ -0,0 +1,61 @@
+package resolution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingResolver struct {
+	resolutions map[string]int
+	fail        bool
+}
+
+func (r *countingResolver) Resolve(_ context.Context, did string, _ ...didsdk.ResolutionOption) (*didsdk.ResolutionResult, error) {
+	if r.fail {
+		return nil, errors.New("unavailable")
+	}
+	r.resolutions[did]++
+	return &didsdk.ResolutionResult{Document: didsdk.Document{ID: did}}, nil
+}
+
+func (r *countingResolver) Methods() []didsdk.Method {
+	return []didsdk.Method{"web"}
+}
+
+func TestCachingResolver(t *testing.T) {
+	remote := &countingResolver{resolutions: make(map[string]int)}
+	cache := newCachingResolver(remote)
+	ctx := context.Background()
+
+	result, err := cache.Resolve(ctx, "did:web:example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "did:web:example.com", result.Document.ID)
+	_, err = cache.Resolve(ctx, "did:web:example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 1, remote.resolutions["did:web:example.com"])
+
+	// refreshing resolves the cached DIDs again
+	require.NoError(t, cache.Refresh(ctx))
+	assert.Equal(t, 2, remote.resolutions["did:web:example.com"])
+
+	// DIDs which cannot be resolved again are kept until they expire
+	remote.fail = true
+	assert.Error(t, cache.Refresh(ctx))
+	_, err = cache.Resolve(ctx, "did:web:example.com")
+	require.NoError(t, err)
+
+	// DIDs which were not used within the TTL are dropped
+	remote.fail = false
+	cached := cache.cache["did:web:example.com"]
+	cached.lastUsed = time.Now().Add(-2 * remoteResolutionCacheTTL)
+	cache.cache["did:web:example.com"] = cached
+	require.NoError(t, cache.Refresh(ctx))
+	assert.Empty(t, cache.cache)
+	assert.Equal(t, 2, remote.resolutions["did:web:example.com"])
+}
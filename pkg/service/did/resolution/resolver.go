@@ -17,7 +17,8 @@ type ServiceResolver struct {
 	resolutionMethods []string
 	hr                didsdk.Resolver
 	lr                didsdk.Resolver
-	ur                *universalResolver
+	// ur caches the results of the universal resolver
+	ur *cachingResolver
 }
 
 var _ didsdk.Resolver = (*ServiceResolver)(nil)
@@ -36,12 +37,13 @@ func NewServiceResolver(handlerResolver didsdk.Resolver, localResolutionMethods
 	}
 
 	// instantiate universal resolver
-	var ur *universalResolver
+	var ur *cachingResolver
 	if universalResolverURL != "" {
-		ur, err = newUniversalResolver(universalResolverURL)
+		universal, err := newUniversalResolver(universalResolverURL)
 		if err != nil {
 			return nil, errors.Wrap(err, "instantiating universal resolver")
 		}
+		ur = newCachingResolver(universal)
 	}
 
 	return &ServiceResolver{
@@ -55,7 +57,7 @@ func NewServiceResolver(handlerResolver didsdk.Resolver, localResolutionMethods
 // Resolve resolves a DID using a combination of local and universal resolvers. The ordering is as follows:
 // 1. Try to resolve with the handlers we have, wrapping the resulting DID in resolution result
 // 2. Try to resolve with the local resolver
-// 3. Try to resolve with the universal resolver
+// 3. Try to resolve with the universal resolver, whose results are cached for a while
 // TODO(gabe) avoid caching DIDs that should be externally resolved https://github.com/TBD54566975/ssi-service/issues/361
 func (sr *ServiceResolver) Resolve(ctx context.Context, did string, opts ...didsdk.ResolutionOption) (*didsdk.ResolutionResult, error) {
 	// check the did is valid
@@ -94,6 +96,14 @@ func (sr *ServiceResolver) Resolve(ctx context.Context, did string, opts ...dids
 	return nil, fmt.Errorf("unable to resolve DID %s", did)
 }
 
+// Refresh resolves the DIDs cached from the universal resolver again, before they expire.
+func (sr *ServiceResolver) Refresh(ctx context.Context) error {
+	if sr.ur == nil {
+		return nil
+	}
+	return sr.ur.Refresh(ctx)
+}
+
 func (sr *ServiceResolver) Methods() []didsdk.Method {
 	methods := make([]didsdk.Method, 0, len(sr.resolutionMethods))
 	for _, m := range sr.resolutionMethods {
@@ -65,6 +65,11 @@ func (s *Service) GetResolver() didsdk.Resolver {
 	return s.resolver
 }
 
+// RefreshResolutionCache resolves the DIDs cached from remote resolvers again, before they expire.
+func (s *Service) RefreshResolutionCache(ctx context.Context) error {
+	return s.resolver.Refresh(ctx)
+}
+
 func NewDIDService(config config.DIDServiceConfig, s storage.ServiceStorage, keyStore *keystore.Service) (*Service, error) {
 	didStorage, err := NewDIDStorage(s)
 	if err != nil {
@@ -132,11 +137,11 @@ func (s *Service) instantiateHandlerForMethod(method didsdk.Method) error {
 	return nil
 }
 
-func (s *Service) ResolveDID(request ResolveDIDRequest) (*ResolveDIDResponse, error) {
+func (s *Service) ResolveDID(ctx context.Context, request ResolveDIDRequest) (*ResolveDIDResponse, error) {
 	if request.DID == "" {
 		return nil, sdkutil.LoggingNewError("cannot resolve empty DID")
 	}
-	resolved, err := s.Resolve(context.Background(), request.DID)
+	resolved, err := s.Resolve(ctx, request.DID)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +204,7 @@ func (s *Service) GetDIDsByMethod(ctx context.Context, request GetDIDsRequest) (
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get handler for method<%s>", method)
 	}
-	return handler.GetDIDs(ctx)
+	return handler.GetDIDs(ctx, request.PageRequest)
 }
 
 func (s *Service) SoftDeleteDIDByMethod(ctx context.Context, request DeleteDIDRequest) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/TBD54566975/ssi-sdk/did"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
@@ -12,6 +13,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -20,6 +23,10 @@ const (
 	keyNamespace = "key"
 	webNamespace = "web"
 	ionNamespace = "ion"
+
+	// softDeletedIndex indexes stored DIDs by whether they have been soft deleted, so that only the DIDs which haven't
+	// been need to be read when listing.
+	softDeletedIndex = "soft-deleted"
 )
 
 var (
@@ -57,6 +64,42 @@ func (d DefaultStoredDID) IsSoftDeleted() bool {
 	return d.SoftDeleted
 }
 
+func init() {
+	for _, ns := range []string{keyNamespace, webNamespace, ionNamespace} {
+		if err := storage.RegisterMigrations(storage.Migration{
+			Namespace:   didMethodToNamespace[ns],
+			Version:     1,
+			Description: "index DIDs by whether they are soft deleted",
+			Index:       softDeletedIndexEntries,
+		}); err != nil {
+			panic(err)
+		}
+		webhook.RegisterResource(didMethodToNamespace[ns], webhook.Resource{Noun: webhook.DID, Data: didWebhookData})
+	}
+}
+
+// didWebhookData returns a stored DID of any method in the shape it is returned by the API with.
+func didWebhookData(value []byte) (any, error) {
+	var stored DefaultStoredDID
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling stored DID")
+	}
+	return GetDIDResponse{DID: stored.DID}, nil
+}
+
+// softDeletedIndexEntries is a storage.IndexFunc for stored DIDs of any method.
+func softDeletedIndexEntries(value []byte) ([]storage.IndexEntry, error) {
+	var stored DefaultStoredDID
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling stored DID")
+	}
+	return []storage.IndexEntry{softDeletedIndexEntry(stored)}, nil
+}
+
+func softDeletedIndexEntry(did StoredDID) storage.IndexEntry {
+	return storage.NewIndexEntry(softDeletedIndex, strconv.FormatBool(did.IsSoftDeleted()))
+}
+
 type Storage struct {
 	db storage.ServiceStorage
 }
@@ -78,7 +121,7 @@ func (ds *Storage) StoreDID(ctx context.Context, did StoredDID) error {
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, couldNotStoreDIDErr)
 	}
-	return ds.db.Write(ctx, ns, did.GetID(), didBytes)
+	return storage.WriteIndexed(ctx, ds.db, ns, did.GetID(), didBytes, softDeletedIndexEntry(did))
 }
 
 // GetDID attempts to get a DID from the database. It will return an error if it cannot.
@@ -119,43 +162,108 @@ func (ds *Storage) GetDIDDefault(ctx context.Context, id string) (*DefaultStored
 // The out parameter must be a pointer to a struct for a type that implement the StoredDID interface.
 // The result is a slice of the type of the out parameter (an array of pointers to the type of the out parameter).)
 func (ds *Storage) GetDIDs(ctx context.Context, method string, outType StoredDID) ([]StoredDID, error) {
+	gotDIDs, _, err := ds.GetDIDsPage(ctx, method, framework.PageRequest{}, outType)
+	return gotDIDs, err
+}
+
+// GetDIDsPage is like GetDIDs, but only gets the DIDs in the requested page. The token of the following page is
+// returned alongside the DIDs, and is empty when there are no more pages.
+func (ds *Storage) GetDIDsPage(ctx context.Context, method string, page framework.PageRequest, outType StoredDID) ([]StoredDID, string, error) {
 	if err := validateOut(outType); err != nil {
-		return nil, errors.Wrap(err, "validating the out type")
+		return nil, "", errors.Wrap(err, "validating the out type")
 	}
 	couldNotGetDIDsErr := fmt.Sprintf("could not get DIDs for method: %s", method)
 	ns, err := getNamespaceForMethod(method)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
+		return nil, "", sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
 	}
-	gotDIDs, err := ds.db.ReadAll(ctx, ns)
+	gotDIDs, nextPageToken, err := ds.db.ReadPage(ctx, ns, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
+		return nil, "", sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
 	}
 	if len(gotDIDs) == 0 {
 		logrus.Infof("no DIDs found for method: %s", method)
-		return nil, nil
+		return nil, nextPageToken, nil
+	}
+	return unmarshalStoredDIDs(gotDIDs, outType), nextPageToken, nil
+}
+
+// GetActiveDIDsPage is like GetDIDsPage, but only gets the DIDs which have not been soft deleted.
+func (ds *Storage) GetActiveDIDsPage(ctx context.Context, method string, page framework.PageRequest, outType StoredDID) ([]StoredDID, string, error) {
+	if err := validateOut(outType); err != nil {
+		return nil, "", errors.Wrap(err, "validating the out type")
+	}
+	couldNotGetDIDsErr := fmt.Sprintf("could not get DIDs for method: %s", method)
+	ns, err := getNamespaceForMethod(method)
+	if err != nil {
+		return nil, "", sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
+	}
+	activeKeys, err := storage.ReadIndex(ctx, ds.db, ns, softDeletedIndex, strconv.FormatBool(false))
+	if err != nil {
+		return nil, "", sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
+	}
+	pageKeys, nextPageToken, err := storage.PaginateKeys(activeKeys, page.PageToken, page.PageSize)
+	if err != nil {
+		return nil, "", sdkutil.LoggingErrorMsg(err, couldNotGetDIDsErr)
+	}
+	if len(pageKeys) == 0 {
+		logrus.Infof("no DIDs found for method: %s", method)
+		return nil, nextPageToken, nil
+	}
+
+	gotDIDs := make(map[string][]byte, len(pageKeys))
+	for _, key := range pageKeys {
+		didBytes, err := ds.db.Read(ctx, ns, key)
+		if err != nil {
+			logrus.WithError(err).Errorf("could not read DID: %s", key)
+			continue
+		}
+		gotDIDs[key] = didBytes
+	}
+	return unmarshalStoredDIDs(gotDIDs, outType), nextPageToken, nil
+}
+
+// GetActiveDIDsPageDefault is a convenience method for GetActiveDIDsPage for DIDs stored as a DefaultStoredDID.
+func (ds *Storage) GetActiveDIDsPageDefault(ctx context.Context, method string, page framework.PageRequest) ([]DefaultStoredDID, string, error) {
+	gotDIDs, nextPageToken, err := ds.GetActiveDIDsPage(ctx, method, page, new(DefaultStoredDID))
+	if err != nil {
+		return nil, "", err
 	}
+	return toDefaultStoredDIDs(gotDIDs), nextPageToken, nil
+}
 
+// unmarshalStoredDIDs unmarshals each of the values into a new value of the type pointed to by outType, skipping
+// those which can't be unmarshalled.
+func unmarshalStoredDIDs(gotDIDs map[string][]byte, outType StoredDID) []StoredDID {
 	out := make([]StoredDID, 0, len(gotDIDs))
 	for _, didBytes := range gotDIDs {
 		nextDID := reflect.New(reflect.TypeOf(outType).Elem()).Interface()
-		if err = json.Unmarshal(didBytes, &nextDID); err == nil {
+		if err := json.Unmarshal(didBytes, &nextDID); err == nil {
 			out = append(out, nextDID.(StoredDID))
 		}
 	}
-	return out, nil
+	return out
 }
 
-func (ds *Storage) GetDIDsDefault(ctx context.Context, method string) ([]DefaultStoredDID, error) {
-	gotDIDs, err := ds.GetDIDs(ctx, method, new(DefaultStoredDID))
-	if err != nil {
-		return nil, err
-	}
+func toDefaultStoredDIDs(gotDIDs []StoredDID) []DefaultStoredDID {
 	typedDIDs := make([]DefaultStoredDID, len(gotDIDs))
 	for i, gotDID := range gotDIDs {
 		typedDIDs[i] = *gotDID.(*DefaultStoredDID)
 	}
-	return typedDIDs, nil
+	return typedDIDs
+}
+
+func (ds *Storage) GetDIDsDefault(ctx context.Context, method string) ([]DefaultStoredDID, error) {
+	gotDIDs, _, err := ds.GetDIDsPageDefault(ctx, method, framework.PageRequest{})
+	return gotDIDs, err
+}
+
+func (ds *Storage) GetDIDsPageDefault(ctx context.Context, method string, page framework.PageRequest) ([]DefaultStoredDID, string, error) {
+	gotDIDs, nextPageToken, err := ds.GetDIDsPage(ctx, method, page, new(DefaultStoredDID))
+	if err != nil {
+		return nil, "", err
+	}
+	return toDefaultStoredDIDs(gotDIDs), nextPageToken, nil
 }
 
 func (ds *Storage) DeleteDID(ctx context.Context, id string) error {
@@ -164,7 +272,7 @@ func (ds *Storage) DeleteDID(ctx context.Context, id string) error {
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, couldNotGetDIDErr)
 	}
-	if err = ds.db.Delete(ctx, ns, id); err != nil {
+	if err = storage.DeleteIndexed(ctx, ds.db, ns, id); err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "could not delete DID: %s", id)
 	}
 	return nil
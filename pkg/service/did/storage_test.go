@@ -6,9 +6,11 @@ import (
 	"testing"
 
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -207,6 +209,31 @@ func TestStorage(t *testing.T) {
 		assert.Len(tt, gotDIDs, 1)
 		assert.Contains(tt, gotDIDs, toStore2)
 	})
+
+	t.Run("Migrate DIDs stored before they were indexed", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		ds, err := NewDIDStorage(db)
+		assert.NoError(tt, err)
+
+		toStore := DefaultStoredDID{
+			ID:  "did:key:legacy",
+			DID: didsdk.Document{ID: "did:key:legacy"},
+		}
+		didBytes, err := json.Marshal(toStore)
+		require.NoError(tt, err)
+		require.NoError(tt, db.Write(context.Background(), didMethodToNamespace[keyNamespace], toStore.ID, didBytes))
+
+		gotDIDs, _, err := ds.GetActiveDIDsPageDefault(context.Background(), didsdk.KeyMethod.String(), framework.PageRequest{})
+		assert.NoError(tt, err)
+		assert.Empty(tt, gotDIDs)
+
+		_, err = storage.RunMigrations(context.Background(), db, storage.RegisteredMigrations(), false)
+		assert.NoError(tt, err)
+
+		gotDIDs, _, err = ds.GetActiveDIDsPageDefault(context.Background(), didsdk.KeyMethod.String(), framework.PageRequest{})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []DefaultStoredDID{toStore}, gotDIDs)
+	})
 }
 
 func setupTestDB(t *testing.T) storage.ServiceStorage {
@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 )
 
@@ -130,20 +131,18 @@ func (h *webHandler) GetDID(ctx context.Context, request GetDIDRequest) (*GetDID
 	return &GetDIDResponse{DID: gotDID.GetDocument()}, nil
 }
 
-func (h *webHandler) GetDIDs(ctx context.Context) (*GetDIDsResponse, error) {
+func (h *webHandler) GetDIDs(ctx context.Context, page framework.PageRequest) (*GetDIDsResponse, error) {
 	logrus.Debug("getting did:web DID")
 
-	gotDIDs, err := h.storage.GetDIDsDefault(ctx, did.WebMethod.String())
+	gotDIDs, nextPageToken, err := h.storage.GetActiveDIDsPageDefault(ctx, did.WebMethod.String(), page)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting did:web DIDs")
 	}
 	dids := make([]did.Document, 0, len(gotDIDs))
 	for _, gotDID := range gotDIDs {
-		if !gotDID.IsSoftDeleted() {
-			dids = append(dids, gotDID.GetDocument())
-		}
+		dids = append(dids, gotDID.GetDocument())
 	}
-	return &GetDIDsResponse{DIDs: dids}, nil
+	return &GetDIDsResponse{DIDs: dids, NextPageToken: nextPageToken}, nil
 }
 
 func (h *webHandler) SoftDeleteDID(ctx context.Context, request DeleteDIDRequest) error {
@@ -17,6 +17,7 @@ const (
 	Presentation Type = "presentation"
 	Operation    Type = "operation"
 	Webhook      Type = "webhook"
+	Tenant       Type = "tenant"
 
 	StatusReady    StatusState = "ready"
 	StatusNotReady StatusState = "not_ready"
@@ -44,3 +45,11 @@ type Service interface {
 	Type() Type
 	Status() Status
 }
+
+// PageRequest selects a page of a collection, following https://google.aip.dev/158.
+type PageRequest struct {
+	// Maximum number of results to return. When zero, all the results are returned.
+	PageSize int
+	// Token returned as the next page token of a previous call. When empty, the first page is returned.
+	PageToken string
+}
@@ -4,8 +4,11 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"go.einride.tech/aip/filtering"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 )
 
 type GetIssuanceTemplateRequest struct {
@@ -45,8 +48,34 @@ type CredentialTemplate struct {
 	// Parameter to determine the expiry of the credential.
 	Expiry TimeLike `json:"expiry,omitempty"`
 
+	// Optional.
+	// A context URI or a list of them, which are added to the default context of the credentials created.
+	Context any `json:"@context,omitempty"`
+
+	// Optional.
+	// A type or a list of them, which are added to the VerifiableCredential type of the credentials created.
+	Type any `json:"type,omitempty"`
+
+	// Optional.
+	// Evidence of the credentials created. Each evidence is an object with a type, and an id which is a URI when
+	// present.
+	Evidence []any `json:"evidence,omitempty"`
+
+	// Optional.
+	// Terms of use of the credentials created. Each has a type, and an id which is a URI when present.
+	TermsOfUse []credential.TermsOfUse `json:"termsOfUse,omitempty"`
+
 	// Whether the credentials created should be revocable.
 	Revocable bool `json:"revocable"`
+
+	// Optional.
+	// Paths of the claims of the credentialSubject which the holder may selectively disclose, such as address.street.
+	// When present, the credentials created are SD-JWTs, regardless of the formats of the manifest.
+	DisclosableClaims []string `json:"disclosableClaims,omitempty"`
+
+	// Whether the credentials created have a refresh service, through which their subject may have them issued again
+	// with a new expiry. Refreshing runs the template again, but claims looked up from the application keep their value.
+	Refreshable bool `json:"refreshable,omitempty"`
 }
 
 type IssuanceTemplate struct {
@@ -100,6 +129,9 @@ type DeleteIssuanceTemplateRequest struct {
 type ListIssuanceTemplatesRequest struct {
 	// A parsed filter expression conforming to https://google.aip.dev/160.
 	Filter filtering.Filter
+
+	// The page of templates to return, as described in https://google.aip.dev/158.
+	PageRequest framework.PageRequest
 }
 
 func (r ListIssuanceTemplatesRequest) Validate() error {
@@ -109,4 +141,7 @@ func (r ListIssuanceTemplatesRequest) Validate() error {
 type ListIssuanceTemplatesResponse struct {
 	// The issuance templates that satisfy the query conditions.
 	IssuanceTemplates []IssuanceTemplate `json:"issuanceTemplates"`
+
+	// Token of the next page of templates. Empty when there are no more pages.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
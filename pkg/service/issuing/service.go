@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
@@ -19,16 +20,18 @@ type Service struct {
 	schemaStorage   schema.Storage
 }
 
-func NewIssuingService(config config.IssuingServiceConfig, s storage.ServiceStorage) (*Service, error) {
+// NewIssuingService creates the issuing service, which checks that the manifests and schemas referenced by templates
+// exist in manifestDB and schemaDB, the storage of the manifest and schema services.
+func NewIssuingService(config config.IssuingServiceConfig, s, manifestDB, schemaDB storage.ServiceStorage) (*Service, error) {
 	issuingStorage, err := NewIssuingStorage(s)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating issuing storage")
 	}
-	manifestStorage, err := manifeststg.NewManifestStorage(s)
+	manifestStorage, err := manifeststg.NewManifestStorage(manifestDB)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating manifest storage")
 	}
-	schemaStorage, err := schema.NewSchemaStorage(s)
+	schemaStorage, err := schema.NewSchemaStorage(schemaDB)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating manifest storage")
 	}
@@ -69,6 +72,14 @@ func (s *Service) CreateIssuanceTemplate(ctx context.Context, request *CreateIss
 				return nil, errors.Wrapf(err, "getting schema at index %d", i)
 			}
 		}
+		for _, path := range c.DisclosableClaims {
+			if path == "" || path == "id" {
+				return nil, errors.Errorf("claim<%s> cannot be disclosable at index %d", path, i)
+			}
+		}
+		if err := credint.ValidateProperties(c.Context, c.Type, c.Evidence, c.TermsOfUse); err != nil {
+			return nil, errors.Wrapf(err, "invalid credential properties at index %d", i)
+		}
 	}
 
 	if _, err := s.manifestStorage.GetManifest(ctx, request.IssuanceTemplate.CredentialManifest); err != nil {
@@ -103,13 +114,14 @@ func (s *Service) ListIssuanceTemplates(ctx context.Context, request *ListIssuan
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	ops, err := s.storage.ListIssuanceTemplates(ctx)
+	ops, nextPageToken, err := s.storage.ListIssuanceTemplates(ctx, request.PageRequest)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching ops from storage")
 	}
 
 	resp := &ListIssuanceTemplatesResponse{
 		IssuanceTemplates: ops,
+		NextPageToken:     nextPageToken,
 	}
 	return resp, nil
 }
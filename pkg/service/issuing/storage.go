@@ -6,6 +6,7 @@ import (
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -65,20 +66,20 @@ func (s Storage) DeleteIssuanceTemplate(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s Storage) ListIssuanceTemplates(ctx context.Context) ([]IssuanceTemplate, error) {
-	m, err := s.db.ReadAll(ctx, namespace)
+func (s Storage) ListIssuanceTemplates(ctx context.Context, page framework.PageRequest) ([]IssuanceTemplate, string, error) {
+	m, nextPageToken, err := s.db.ReadPage(ctx, namespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading all")
+		return nil, "", errors.Wrap(err, "reading all")
 	}
 	ts := make([]IssuanceTemplate, len(m))
 	i := 0
 	for k, v := range m {
 		if err = json.Unmarshal(v, &ts[i]); err != nil {
-			return nil, errors.Wrapf(err, "unmarshalling template with key <%s>", k)
+			return nil, "", errors.Wrapf(err, "unmarshalling template with key <%s>", k)
 		}
 		i++
 	}
-	return ts, nil
+	return ts, nextPageToken, nil
 }
 
 func (s Storage) GetIssuanceTemplatesByManifestID(ctx context.Context, manifestID string) ([]StoredIssuanceTemplate, error) {
@@ -58,7 +58,7 @@ func NewKeyStoreService(config config.KeyStoreServiceConfig, s storage.ServiceSt
 	keyStoreStorage, err := NewKeyStoreStorage(s, ServiceKey{
 		Base58Key:  serviceKey,
 		Base58Salt: serviceKeySalt,
-	})
+	}, config.ServiceKeyPassword)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "instantiating storage for the keystore service")
 	}
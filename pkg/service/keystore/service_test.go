@@ -1,6 +1,7 @@
 package keystore
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
@@ -140,3 +141,58 @@ func TestStoreAndGetKey(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, signer)
 }
+
+func TestImportArchiveReencryptsKeys(t *testing.T) {
+	ctx := context.Background()
+	serviceConfig := config.KeyStoreServiceConfig{
+		BaseServiceConfig:  &config.BaseServiceConfig{Name: "test-keyStore"},
+		ServiceKeyPassword: "test-password",
+	}
+
+	source, err := storage.NewStorage(storage.Memory, nil)
+	require.NoError(t, err)
+	sourceKeyStore, err := NewKeyStoreService(serviceConfig, source)
+	require.NoError(t, err)
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	err = sourceKeyStore.StoreKey(ctx, StoreKeyRequest{
+		ID:               "test-id",
+		Type:             crypto.Ed25519,
+		Controller:       "test-controller",
+		PrivateKeyBase58: base58.Encode(privKey),
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	_, err = storage.ExportArchive(ctx, source, &archive)
+	require.NoError(t, err)
+
+	// the target generated its own service key on startup
+	target, err := storage.NewStorage(storage.Memory, nil)
+	require.NoError(t, err)
+	targetKeyStore, err := NewKeyStoreService(serviceConfig, target)
+	require.NoError(t, err)
+	targetServiceKey, err := target.Read(ctx, namespace, skKey)
+	require.NoError(t, err)
+
+	transform, err := ArchiveTransform(ctx, target, bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	require.NotNil(t, transform)
+	_, err = storage.ImportArchive(ctx, target, bytes.NewReader(archive.Bytes()), transform)
+	require.NoError(t, err)
+
+	// the key is readable with the target's service key, which was kept
+	storedServiceKey, err := target.Read(ctx, namespace, skKey)
+	assert.NoError(t, err)
+	assert.Equal(t, targetServiceKey, storedServiceKey)
+	keyResponse, err := targetKeyStore.GetKey(ctx, GetKeyRequest{ID: "test-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, privKey, keyResponse.Key)
+
+	// an empty target takes the service key of the archive along with the keys
+	empty, err := storage.NewStorage(storage.Memory, nil)
+	require.NoError(t, err)
+	transform, err = ArchiveTransform(ctx, empty, bytes.NewReader(archive.Bytes()))
+	assert.NoError(t, err)
+	assert.Nil(t, transform)
+}
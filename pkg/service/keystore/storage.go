@@ -1,13 +1,16 @@
 package keystore
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/mr-tron/base58"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 
@@ -50,14 +53,18 @@ const (
 type Storage struct {
 	db         storage.ServiceStorage
 	serviceKey []byte
+	// password from which the service keys of tenants are derived
+	password string
 }
 
-func NewKeyStoreStorage(db storage.ServiceStorage, key ServiceKey) (*Storage, error) {
+// NewKeyStoreStorage stores the service key of the default tenant. Each tenant has its own service key, which is
+// derived from the password the first time it is needed.
+func NewKeyStoreStorage(db storage.ServiceStorage, key ServiceKey, password string) (*Storage, error) {
 	keyBytes, err := base58.Decode(key.Base58Key)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not decode service key")
 	}
-	bolt := &Storage{db: db, serviceKey: keyBytes}
+	bolt := &Storage{db: db, serviceKey: keyBytes, password: password}
 
 	// first, store the service key
 	if err = bolt.storeServiceKey(context.Background(), key); err != nil {
@@ -80,6 +87,9 @@ func (kss *Storage) storeServiceKey(ctx context.Context, key ServiceKey) error {
 
 // getAndSetServiceKey attempts to get the service key from memory, and if not available rehydrates it from the DB
 func (kss *Storage) getAndSetServiceKey(ctx context.Context) ([]byte, error) {
+	if storage.TenantFromContext(ctx) != "" {
+		return kss.getOrCreateTenantServiceKey(ctx)
+	}
 	if len(kss.serviceKey) != 0 {
 		return kss.serviceKey, nil
 	}
@@ -92,8 +102,50 @@ func (kss *Storage) getAndSetServiceKey(ctx context.Context) ([]byte, error) {
 		return nil, sdkutil.LoggingNewError(keyNotFoundErrMsg)
 	}
 
+	keyBytes, err := decodeServiceKey(storedKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	kss.serviceKey = keyBytes
+	return keyBytes, nil
+}
+
+// getOrCreateTenantServiceKey returns the service key of the tenant of the context, creating it when the tenant has
+// none yet. Tenant service keys are not kept in memory, so that a deleted tenant does not leave its key behind.
+func (kss *Storage) getOrCreateTenantServiceKey(ctx context.Context) ([]byte, error) {
+	result, err := kss.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		storedKeyBytes, err := tx.Read(ctx, namespace, skKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get service key")
+		}
+		if len(storedKeyBytes) != 0 {
+			return decodeServiceKey(storedKeyBytes)
+		}
+
+		serviceKey, serviceKeySalt, err := GenerateServiceKey(kss.password)
+		if err != nil {
+			return nil, errors.Wrap(err, "generating service key")
+		}
+		keyBytes, err := json.Marshal(ServiceKey{Base58Key: serviceKey, Base58Salt: serviceKeySalt})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal service key")
+		}
+		if err = tx.Write(ctx, namespace, skKey, keyBytes); err != nil {
+			return nil, errors.Wrap(err, "could not store service key")
+		}
+		return base58.Decode(serviceKey)
+	}, []storage.WatchKey{{Namespace: namespace, Key: skKey}})
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting service key of tenant<%s>", storage.TenantFromContext(ctx))
+	}
+	return result.([]byte), nil
+}
+
+// decodeServiceKey returns the key bytes of a stored ServiceKey
+func decodeServiceKey(storedKeyBytes []byte) ([]byte, error) {
 	var stored ServiceKey
-	if err = json.Unmarshal(storedKeyBytes, &stored); err != nil {
+	if err := json.Unmarshal(storedKeyBytes, &stored); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not unmarshal service key")
 	}
 
@@ -101,11 +153,57 @@ func (kss *Storage) getAndSetServiceKey(ctx context.Context) ([]byte, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "could not decode service key")
 	}
-
-	kss.serviceKey = keyBytes
 	return keyBytes, nil
 }
 
+// ArchiveTransform returns the transform with which an archive written by storage.ExportArchive is imported into the
+// target storage. Keys are archived encrypted with the service key of the exporting service. When the target storage
+// already holds a different service key, the keys are re-encrypted with it, and the target's service key is kept.
+// Otherwise, the keys and the service key of the archive are imported as they are, and no transform is needed. Keys
+// which cannot be decrypted with the archived service key were unreadable by the exporting service too, and are
+// imported unchanged.
+func ArchiveTransform(ctx context.Context, target storage.ServiceStorage, archive io.ReadSeeker) (storage.ArchiveTransformFunc, error) {
+	sourceKeyBytes, err := storage.ReadArchiveRecord(archive, namespace, skKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading service key from archive")
+	}
+	targetKeyBytes, err := target.Read(ctx, namespace, skKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading service key from target storage")
+	}
+	if len(sourceKeyBytes) == 0 || len(targetKeyBytes) == 0 {
+		return nil, nil
+	}
+
+	sourceKey, err := decodeServiceKey(sourceKeyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "archived service key")
+	}
+	targetKey, err := decodeServiceKey(targetKeyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "target service key")
+	}
+	if bytes.Equal(sourceKey, targetKey) {
+		return nil, nil
+	}
+
+	return func(_ context.Context, ns, key string, value []byte) ([]byte, error) {
+		if ns != namespace {
+			return value, nil
+		}
+		if key == skKey {
+			return nil, nil
+		}
+		decrypted, err := DecryptKey(sourceKey, value)
+		if err != nil {
+			// the key was written with an earlier service key, and could not be read by the exporting service either
+			logrus.WithError(err).Warnf("could not decrypt key<%s> with the archived service key, importing it as is", key)
+			return value, nil
+		}
+		return EncryptKey(targetKey, decrypted)
+	}, nil
+}
+
 func (kss *Storage) StoreKey(ctx context.Context, key StoredKey) error {
 	// TODO(gabe): conflict checking on key id
 	id := key.ID
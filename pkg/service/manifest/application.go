@@ -8,6 +8,7 @@ import (
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/lestrrat-go/jwx/v2/jws"
 
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	didint "github.com/tbd54566975/ssi-service/internal/did"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
@@ -18,10 +19,15 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 )
 
+// credentialsProperty is the property of the JSON of a credential application holding its credentials.
+const credentialsProperty = "verifiableCredentials"
+
 // validateCredentialApplication validates the credential application's signature(s) in addition to making sure it
 // is a valid credential application, and complies with its corresponding manifest. it returns the ids of unfulfilled
-// input descriptors along with an error if validation fails.
-func (s Service) validateCredentialApplication(ctx context.Context, credManifest manifest.CredentialManifest, request model.SubmitApplicationRequest) (inputDescriptorIDs []string, err error) {
+// input descriptors along with an error if validation fails, and the reports of the verification of the credentials
+// submitted with the application, once they are verified as per the named policy, if any. An application with a
+// credential which is not verified is denied.
+func (s Service) validateCredentialApplication(ctx context.Context, credManifest manifest.CredentialManifest, policy string, request model.SubmitApplicationRequest) (inputDescriptorIDs []string, verifications []credint.VerificationReport, err error) {
 	// parse headers
 	headers, err := keyaccess.GetJWTHeaders([]byte(request.ApplicationJWT.String()))
 	if err != nil {
@@ -52,8 +58,15 @@ func (s Service) validateCredentialApplication(ctx context.Context, credManifest
 		return
 	}
 
+	// constraints on data model 2.0 credentials are evaluated against their JSON
+	applicationJSON, jsonErr := applicationJSONWithV2Credentials(request.ApplicationJSON)
+	if jsonErr != nil {
+		err = sdkutil.LoggingErrorMsgf(jsonErr, "could not read credentials of application: %s", credApp.ID)
+		return
+	}
+
 	// next, validate that the credential(s) provided in the application are valid
-	unfulfilledInputDescriptorIDs, validationErr := manifest.IsValidCredentialApplicationForManifest(credManifest, request.ApplicationJSON)
+	unfulfilledInputDescriptorIDs, validationErr := manifest.IsValidCredentialApplicationForManifest(credManifest, applicationJSON)
 	if validationErr != nil {
 		resp := errresp.GetErrorResponse(validationErr)
 		// a valid error response means this is an application level error, and we should return a credential denial
@@ -77,21 +90,50 @@ func (s Service) validateCredentialApplication(ctx context.Context, credManifest
 	}
 
 	// signature and validity checks for each credential submitted with the application
+	var failed []string
 	for _, credentialContainer := range request.Credentials {
 		verificationResult, verificationErr := s.credential.VerifyCredential(ctx, credential.VerifyCredentialRequest{
 			DataIntegrityCredential: credentialContainer.Credential,
 			CredentialJWT:           credentialContainer.CredentialJWT,
+			CredentialSDJWT:         credentialContainer.CredentialSDJWT,
+			Policy:                  policy,
 		})
 
 		if verificationErr != nil {
-			err = sdkutil.LoggingNewErrorf("could not verify credential: %s", credentialContainer.Credential.ID)
+			err = sdkutil.LoggingErrorMsgf(verificationErr, "could not verify credential: %s", credentialContainer.ID)
 			return
 		}
 
+		verifications = append(verifications, credint.VerificationReport{
+			CredentialID: credentialContainer.ID,
+			Verified:     verificationResult.Verified,
+			Checks:       verificationResult.Checks,
+		})
 		if !verificationResult.Verified {
-			err = sdkutil.LoggingNewErrorf("submitted credential<%s> is not valid: %s", credentialContainer.Credential.ID, verificationResult.Reason)
-			return
+			failed = append(failed, fmt.Sprintf("%s: %s", credentialContainer.ID, verificationResult.Reason))
 		}
 	}
+	if len(failed) > 0 {
+		err = errresp.NewErrorResponsef(DenialResponse, "submitted credential(s) not verified: %s", strings.Join(failed, ", "))
+	}
 	return
 }
+
+// applicationJSONWithV2Credentials returns a copy of the JSON of a credential application in which each VC data model
+// 2.0 JWT credential is replaced with its JSON.
+func applicationJSONWithV2Credentials(applicationJSON map[string]any) (map[string]any, error) {
+	credentials, ok := applicationJSON[credentialsProperty].([]any)
+	if !ok {
+		return applicationJSON, nil
+	}
+	v2Credentials, err := credint.V2JWTsAsJSON(credentials)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]any, len(applicationJSON))
+	for k, v := range applicationJSON {
+		res[k] = v
+	}
+	res[credentialsProperty] = v2Credentials
+	return res, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	cred "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
 )
 
@@ -22,6 +23,8 @@ type CreateManifestRequest struct {
 	OutputDescriptors      []manifestsdk.OutputDescriptor   `json:"outputDescriptors" validate:"required,dive"`
 	ClaimFormat            *exchange.ClaimFormat            `json:"format" validate:"required,dive"`
 	PresentationDefinition *exchange.PresentationDefinition `json:"presentationDefinition,omitempty" validate:"omitempty,dive"`
+	// The name of the policy the credentials submitted with applications are verified with, if any.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 type CreateManifestResponse struct {
@@ -44,12 +47,18 @@ type GetManifestRequest struct {
 }
 
 type GetManifestResponse struct {
-	Manifest    manifestsdk.CredentialManifest `json:"manifest"`
-	ManifestJWT keyaccess.JWT                  `json:"manifestJwt,omitempty"`
+	Manifest           manifestsdk.CredentialManifest `json:"manifest"`
+	ManifestJWT        keyaccess.JWT                  `json:"manifestJwt,omitempty"`
+	VerificationPolicy string                         `json:"verificationPolicy,omitempty"`
+}
+
+type GetManifestsRequest struct {
+	PageRequest framework.PageRequest
 }
 
 type GetManifestsResponse struct {
-	Manifests []GetManifestResponse `json:"manifests,omitempty"`
+	Manifests     []GetManifestResponse `json:"manifests,omitempty"`
+	NextPageToken string                `json:"nextPageToken,omitempty"`
 }
 
 type DeleteManifestRequest struct {
@@ -70,6 +79,9 @@ type SubmitApplicationResponse struct {
 	Response    manifestsdk.CredentialResponse `json:"response" validate:"required"`
 	Credentials []any                          `json:"credentials,omitempty"`
 	ResponseJWT keyaccess.JWT                  `json:"responseJwt,omitempty" validate:"required"`
+	// The reports of the verification of the credentials submitted with the application, listing the checks run on
+	// each.
+	Verifications []cred.VerificationReport `json:"verifications,omitempty"`
 }
 
 type GetApplicationRequest struct {
@@ -81,10 +93,21 @@ type GetApplicationResponse struct {
 	// SubmissionApplicationResponse is guaranteed to exist.
 	Status      string
 	Application manifestsdk.CredentialApplication `json:"application"`
+	// The reports of the verification of the credentials submitted with the application.
+	Verifications []cred.VerificationReport `json:"verifications,omitempty"`
+}
+
+// GetApplicationsRequest gets the applications for a manifest, or made by an applicant. At most one of ManifestID and
+// ApplicantDID may be set. All applications are returned when neither is set.
+type GetApplicationsRequest struct {
+	ManifestID   string
+	ApplicantDID string
+	PageRequest  framework.PageRequest
 }
 
 type GetApplicationsResponse struct {
-	Applications []manifestsdk.CredentialApplication `json:"applications,omitempty"`
+	Applications  []manifestsdk.CredentialApplication `json:"applications,omitempty"`
+	NextPageToken string                              `json:"nextPageToken,omitempty"`
 }
 
 type DeleteApplicationRequest struct {
@@ -113,8 +136,13 @@ type GetResponseResponse struct {
 	ResponseJWT keyaccess.JWT
 }
 
+type GetResponsesRequest struct {
+	PageRequest framework.PageRequest
+}
+
 type GetResponsesResponse struct {
-	Responses []manifestsdk.CredentialResponse `json:"responses,omitempty"`
+	Responses     []manifestsdk.CredentialResponse `json:"responses,omitempty"`
+	NextPageToken string                           `json:"nextPageToken,omitempty"`
 }
 
 type DeleteResponseRequest struct {
@@ -124,9 +152,10 @@ type DeleteResponseRequest struct {
 // ServiceModel creates a SubmitApplicationResponse from a given StoredResponse.
 func ServiceModel(storedResponse *storage.StoredResponse) SubmitApplicationResponse {
 	return SubmitApplicationResponse{
-		Response:    storedResponse.Response,
-		Credentials: cred.ContainersToInterface(storedResponse.Credentials),
-		ResponseJWT: storedResponse.ResponseJWT,
+		Response:      storedResponse.Response,
+		Credentials:   cred.ContainersToInterface(storedResponse.Credentials),
+		ResponseJWT:   storedResponse.ResponseJWT,
+		Verifications: storedResponse.Verifications,
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/credential/manifest"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	errresp "github.com/TBD54566975/ssi-sdk/error"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/oliveagle/jsonpath"
@@ -78,6 +79,7 @@ func (s Service) buildCredentialResponse(
 		}
 	}
 	creds := make([]cred.Container, 0, len(credManifest.OutputDescriptors))
+	format, proofType := credentialFormat(credManifest)
 	for _, od := range credManifest.OutputDescriptors {
 		credentialRequest := credential.CreateCredentialRequest{
 			Issuer:    credManifest.Issuer.ID,
@@ -85,7 +87,9 @@ func (s Service) buildCredentialResponse(
 			Subject:   applicantDID,
 			SchemaID:  od.Schema,
 			// TODO(gabe) need to add in data here to match the request + schema
-			Data: make(map[string]any),
+			Data:      make(map[string]any),
+			Format:    format,
+			ProofType: proofType,
 		}
 		if template != nil {
 			err := s.applyIssuanceTemplate(&credentialRequest, template, templateMap, od, applicationJSON, credManifest, application)
@@ -106,17 +110,16 @@ func (s Service) buildCredentialResponse(
 	// build descriptor map based on credential type
 	descriptors := make([]exchange.SubmissionDescriptor, 0, len(creds))
 	for i, c := range creds {
-		var format string
-		if c.HasDataIntegrityCredential() {
-			format = string(exchange.LDPVC)
-		}
-		if c.HasJWTCredential() {
-			format = string(exchange.JWTVC)
+		// descriptors only take the registered claim formats, among which an sd-jwt, being a vc-jwt followed by its
+		// disclosures, is a jwt_vc
+		format := c.Format()
+		if format == cred.SDJWTVC {
+			format = exchange.JWTVC.CredentialFormat()
 		}
 		descriptors = append(
 			descriptors, exchange.SubmissionDescriptor{
 				ID:     c.ID,
-				Format: format,
+				Format: string(format),
 				Path:   fmt.Sprintf("$.verifiableCredentials[%d]", i),
 			},
 		)
@@ -142,6 +145,27 @@ func (s Service) buildCredentialResponse(
 	return credRes, creds, nil
 }
 
+// credentialFormat returns the format the credentials of the manifest are issued in, which is ldp_vc when the manifest
+// only claims Linked Data Proof formats, and jwt_vc otherwise, along with the type of the proofs of ldp_vc credentials:
+// the first of the proof types the manifest claims which the service supports, or the default one when there is none.
+func credentialFormat(credManifest manifest.CredentialManifest) (exchange.CredentialFormat, cryptosuite.SignatureType) {
+	f := credManifest.Format
+	if f == nil || f.JWT != nil || f.JWTVC != nil || (f.LDP == nil && f.LDPVC == nil) {
+		return exchange.JWTVC.CredentialFormat(), ""
+	}
+	for _, ldpType := range []*exchange.LDPType{f.LDPVC, f.LDP} {
+		if ldpType == nil {
+			continue
+		}
+		for _, proofType := range ldpType.ProofType {
+			if proofType == cryptosuite.JSONWebSignature2020 || proofType == keyaccess.Ed25519Signature2020 {
+				return exchange.LDPVC.CredentialFormat(), proofType
+			}
+		}
+	}
+	return exchange.LDPVC.CredentialFormat(), ""
+}
+
 func (s Service) applyRequestData(credentialRequest *credential.CreateCredentialRequest, credentialOverrides map[string]model.CredentialOverride, od manifest.OutputDescriptor) {
 	if credentialOverride, ok := credentialOverrides[od.ID]; ok {
 		for k, v := range credentialOverride.Data {
@@ -196,7 +220,20 @@ func (s Service) applyIssuanceTemplate(
 		credentialRequest.Expiry = s.Clock.Now().Add(*ct.Expiry.Duration).Format(time.RFC3339)
 	}
 
+	credentialRequest.Context = ct.Context
+	credentialRequest.Type = ct.Type
+	credentialRequest.Evidence = ct.Evidence
+	credentialRequest.TermsOfUse = ct.TermsOfUse
 	credentialRequest.Revocable = ct.Revocable
+	credentialRequest.Refreshable = ct.Refreshable
+	if ct.Refreshable {
+		credentialRequest.RefreshTemplate = ct
+	}
+
+	if len(ct.DisclosableClaims) > 0 {
+		credentialRequest.Format = cred.SDJWTVC
+		credentialRequest.DisclosableClaims = ct.DisclosableClaims
+	}
 	return nil
 }
 
@@ -238,6 +275,28 @@ func getCredential(
 func fromFormat(format exchange.CredentialFormat, claim any) (any, error) {
 	switch format {
 	case exchange.JWTVC.CredentialFormat():
+		if keyaccess.IsSDJWT(claim.(string)) {
+			claims, err := cred.DisclosedClaims(keyaccess.SDJWT(claim.(string)))
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing sd-jwt as %s", exchange.JWTVC)
+			}
+
+			vc, ok := claims["vc"]
+			if !ok {
+				return nil, errors.New("\"vc\" field not found in claim")
+			}
+
+			return vc, nil
+		}
+
+		if cred.IsV2JWT(claim.(string)) {
+			_, v2Cred, err := cred.ParseJWTCredential(claim.(string))
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing data model 2.0 credential as %s", exchange.JWTVC)
+			}
+			return cred.V2JSON(*v2Cred)
+		}
+
 		_, token, err := util.ParseJWT(keyaccess.JWT(claim.(string)))
 		if err != nil {
 			return nil, errors.Wrapf(err, "parsing jwt as %s", exchange.JWTVC)
@@ -249,6 +308,11 @@ func fromFormat(format exchange.CredentialFormat, claim any) (any, error) {
 		}
 
 		return claims, nil
+	case exchange.LDPVC.CredentialFormat():
+		if _, ok := claim.(map[string]any); !ok {
+			return nil, errors.Errorf("%s claim is not a JSON object", exchange.LDPVC)
+		}
+		return claim, nil
 	default:
 		return nil, errors.Errorf("unsupported format %s", format)
 	}
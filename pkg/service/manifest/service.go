@@ -24,6 +24,7 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/operation"
 	opcredential "github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -72,7 +73,9 @@ func (s Service) Config() config.ManifestServiceConfig {
 	return s.config
 }
 
-func NewManifestService(config config.ManifestServiceConfig, s storage.ServiceStorage, keyStore *keystore.Service, didResolver didsdk.Resolver, credential *credential.Service) (*Service, error) {
+// NewManifestService creates the manifest service, which reads the issuance templates of manifests from issuingDB, the
+// storage of the issuing service. Applications are stored along with their operations, in s.
+func NewManifestService(config config.ManifestServiceConfig, s, issuingDB storage.ServiceStorage, keyStore *keystore.Service, didResolver didsdk.Resolver, credential *credential.Service) (*Service, error) {
 	manifestStorage, err := manifeststg.NewManifestStorage(s)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the manifest service")
@@ -81,7 +84,7 @@ func NewManifestService(config config.ManifestServiceConfig, s storage.ServiceSt
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the operations")
 	}
-	issuingStorage, err := issuing.NewIssuingStorage(s)
+	issuingStorage, err := issuing.NewIssuingStorage(issuingDB)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for issuance templates")
 	}
@@ -157,6 +160,11 @@ func (s Service) CreateManifest(ctx context.Context, request model.CreateManifes
 			)
 		}
 	}
+	if request.VerificationPolicy != "" {
+		if _, err := s.credential.ResolvePolicy(ctx, request.VerificationPolicy); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not resolve verification policy: %s", request.VerificationPolicy)
+		}
+	}
 
 	// build the manifest
 	m, err := builder.Build()
@@ -172,11 +180,12 @@ func (s Service) CreateManifest(ctx context.Context, request model.CreateManifes
 
 	// store the manifest
 	storageRequest := manifeststg.StoredManifest{
-		ID:          m.ID,
-		Issuer:      m.Issuer.ID,
-		IssuerKID:   request.IssuerKID,
-		Manifest:    *m,
-		ManifestJWT: *manifestJWT,
+		ID:                 m.ID,
+		Issuer:             m.Issuer.ID,
+		IssuerKID:          request.IssuerKID,
+		Manifest:           *m,
+		ManifestJWT:        *manifestJWT,
+		VerificationPolicy: request.VerificationPolicy,
 	}
 
 	if err = s.storage.StoreManifest(ctx, storageRequest); err != nil {
@@ -213,12 +222,16 @@ func (s Service) GetManifest(ctx context.Context, request model.GetManifestReque
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get manifest: %s", request.ID)
 	}
 
-	response := model.GetManifestResponse{Manifest: gotManifest.Manifest, ManifestJWT: gotManifest.ManifestJWT}
+	response := model.GetManifestResponse{
+		Manifest:           gotManifest.Manifest,
+		ManifestJWT:        gotManifest.ManifestJWT,
+		VerificationPolicy: gotManifest.VerificationPolicy,
+	}
 	return &response, nil
 }
 
-func (s Service) GetManifests(ctx context.Context) (*model.GetManifestsResponse, error) {
-	gotManifests, err := s.storage.GetManifests(ctx)
+func (s Service) GetManifests(ctx context.Context, request model.GetManifestsRequest) (*model.GetManifestsResponse, error) {
+	gotManifests, nextPageToken, err := s.storage.GetManifests(ctx, request.PageRequest)
 
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not get manifests(s)")
@@ -226,10 +239,10 @@ func (s Service) GetManifests(ctx context.Context) (*model.GetManifestsResponse,
 
 	manifests := make([]model.GetManifestResponse, 0, len(gotManifests))
 	for _, m := range gotManifests {
-		response := model.GetManifestResponse{Manifest: m.Manifest, ManifestJWT: m.ManifestJWT}
+		response := model.GetManifestResponse{Manifest: m.Manifest, ManifestJWT: m.ManifestJWT, VerificationPolicy: m.VerificationPolicy}
 		manifests = append(manifests, response)
 	}
-	response := model.GetManifestsResponse{Manifests: manifests}
+	response := model.GetManifestsResponse{Manifests: manifests, NextPageToken: nextPageToken}
 	return &response, nil
 }
 
@@ -272,7 +285,7 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 	opID := opcredential.IDFromResponseID(applicationID)
 
 	// validate the application
-	unfulfilledInputDescriptorIDs, validationErr := s.validateCredentialApplication(ctx, gotManifest.Manifest, request)
+	unfulfilledInputDescriptorIDs, verifications, validationErr := s.validateCredentialApplication(ctx, gotManifest.Manifest, gotManifest.VerificationPolicy, request)
 	if validationErr != nil {
 		resp := errresp.GetErrorResponse(validationErr)
 		if resp.ErrorType == DenialResponse {
@@ -280,7 +293,7 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 			if err != nil {
 				return nil, sdkutil.LoggingErrorMsg(err, "could not build denial credential response")
 			}
-			sarData, err := json.Marshal(manifeststg.StoredResponse{Response: *denialResp})
+			sarData, err := json.Marshal(manifeststg.StoredResponse{Response: *denialResp, Verifications: verifications})
 			if err != nil {
 				return nil, sdkutil.LoggingErrorMsg(err, "marshalling response")
 			}
@@ -289,7 +302,7 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 				Done:     true,
 				Response: sarData,
 			}
-			if err = s.opsStorage.StoreOperation(ctx, storedOp); err != nil {
+			if err = s.opsStorage.StoreOperation(ctx, storedOp, tenant.ApplicationTTL(ctx, s.config.ApplicationTTL)); err != nil {
 				return nil, sdkutil.LoggingErrorMsg(err, "storing operation")
 			}
 
@@ -308,17 +321,18 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 		Application:    request.Application,
 		Credentials:    request.Credentials,
 		ApplicationJWT: request.ApplicationJWT,
+		Verifications:  verifications,
 	}
-	if err = s.storage.StoreApplication(ctx, storageRequest); err != nil {
+	if err = s.storage.StoreApplication(ctx, storageRequest, tenant.ApplicationTTL(ctx, s.config.ApplicationTTL)); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not store application")
 	}
 
 	storedOp := &opstorage.StoredOperation{ID: opID}
-	if err = s.opsStorage.StoreOperation(ctx, *storedOp); err != nil {
+	if err = s.opsStorage.StoreOperation(ctx, *storedOp, tenant.ApplicationTTL(ctx, s.config.ApplicationTTL)); err != nil {
 		return nil, errors.Wrap(err, "storing operation")
 	}
 
-	autoStoredOp, err := s.attemptAutomaticIssuance(ctx, request, manifestID, applicantDID, applicationID, *gotManifest)
+	autoStoredOp, err := s.attemptAutomaticIssuance(ctx, request, manifestID, applicantDID, applicationID, *gotManifest, verifications)
 	if err != nil {
 		return nil, err
 	}
@@ -332,7 +346,7 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 // attemptAutomaticIssuance checks if there is an issuance template for the manifest, and if so,
 // attempts to issue a credential against it
 func (s Service) attemptAutomaticIssuance(ctx context.Context, request model.SubmitApplicationRequest, manifestID,
-	applicantDID, applicationID string, gotManifest manifeststg.StoredManifest) (*opstorage.StoredOperation, error) {
+	applicantDID, applicationID string, gotManifest manifeststg.StoredManifest, verifications []credint.VerificationReport) (*opstorage.StoredOperation, error) {
 	issuanceTemplates, err := s.issuanceTemplateStorage.GetIssuanceTemplatesByManifestID(ctx, manifestID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching issuance templates by manifest ID")
@@ -363,12 +377,13 @@ func (s Service) attemptAutomaticIssuance(ctx context.Context, request model.Sub
 	}
 
 	storedResponse := manifeststg.StoredResponse{
-		ID:           credResp.ID,
-		ManifestID:   manifestID,
-		ApplicantDID: applicantDID,
-		Response:     *credResp,
-		Credentials:  creds,
-		ResponseJWT:  *responseJWT,
+		ID:            credResp.ID,
+		ManifestID:    manifestID,
+		ApplicantDID:  applicantDID,
+		Response:      *credResp,
+		Credentials:   creds,
+		ResponseJWT:   *responseJWT,
+		Verifications: verifications,
 	}
 	_, storedOp, err := s.storage.ReviewApplication(ctx, applicationID, true,
 		"automatic from issuing template", opcredential.IDFromResponseID(applicationID), storedResponse)
@@ -423,12 +438,13 @@ func (s Service) ReviewApplication(ctx context.Context, request model.ReviewAppl
 
 	// store the response we've generated
 	storeResponseRequest := manifeststg.StoredResponse{
-		ID:           credResp.ID,
-		ManifestID:   manifestID,
-		ApplicantDID: applicantDID,
-		Response:     *credResp,
-		Credentials:  creds,
-		ResponseJWT:  *responseJWT,
+		ID:            credResp.ID,
+		ManifestID:    manifestID,
+		ApplicantDID:  applicantDID,
+		Response:      *credResp,
+		Credentials:   creds,
+		ResponseJWT:   *responseJWT,
+		Verifications: application.Verifications,
 	}
 	storedResponse, _, err := s.storage.ReviewApplication(ctx, request.ID, request.Approved, request.Reason,
 		opcredential.IDFromResponseID(request.ID), storeResponseRequest)
@@ -448,14 +464,26 @@ func (s Service) GetApplication(ctx context.Context, request model.GetApplicatio
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get application: %s", request.ID)
 	}
 
-	response := model.GetApplicationResponse{Application: gotApp.Application}
+	response := model.GetApplicationResponse{Application: gotApp.Application, Verifications: gotApp.Verifications}
 	return &response, nil
 }
 
-func (s Service) GetApplications(ctx context.Context) (*model.GetApplicationsResponse, error) {
+func (s Service) GetApplications(ctx context.Context, request model.GetApplicationsRequest) (*model.GetApplicationsResponse, error) {
 	logrus.Debugf("getting application(s)")
 
-	gotApps, err := s.storage.GetApplications(ctx)
+	var gotApps []manifeststg.StoredApplication
+	var nextPageToken string
+	var err error
+	switch {
+	case request.ManifestID != "" && request.ApplicantDID != "":
+		return nil, sdkutil.LoggingNewError("cannot get applications by both manifest and applicant")
+	case request.ManifestID != "":
+		gotApps, nextPageToken, err = s.storage.GetApplicationsByManifest(ctx, request.ManifestID, request.PageRequest)
+	case request.ApplicantDID != "":
+		gotApps, nextPageToken, err = s.storage.GetApplicationsByApplicant(ctx, request.ApplicantDID, request.PageRequest)
+	default:
+		gotApps, nextPageToken, err = s.storage.GetApplications(ctx, request.PageRequest)
+	}
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not get application(s)")
 	}
@@ -465,7 +493,7 @@ func (s Service) GetApplications(ctx context.Context) (*model.GetApplicationsRes
 		apps = append(apps, cred.Application)
 	}
 
-	response := model.GetApplicationsResponse{Applications: apps}
+	response := model.GetApplicationsResponse{Applications: apps, NextPageToken: nextPageToken}
 	return &response, nil
 }
 
@@ -495,10 +523,10 @@ func (s Service) GetResponse(ctx context.Context, request model.GetResponseReque
 	return &response, nil
 }
 
-func (s Service) GetResponses(ctx context.Context) (*model.GetResponsesResponse, error) {
+func (s Service) GetResponses(ctx context.Context, request model.GetResponsesRequest) (*model.GetResponsesResponse, error) {
 	logrus.Debugf("getting response(s)")
 
-	gotResponses, err := s.storage.GetResponses(ctx)
+	gotResponses, nextPageToken, err := s.storage.GetResponses(ctx, request.PageRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not get response(s)")
 	}
@@ -508,7 +536,7 @@ func (s Service) GetResponses(ctx context.Context) (*model.GetResponsesResponse,
 		responses = append(responses, res.Response)
 	}
 
-	response := model.GetResponsesResponse{Responses: responses}
+	response := model.GetResponsesResponse{Responses: responses, NextPageToken: nextPageToken}
 	return &response, nil
 }
 
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential/manifest"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
@@ -11,10 +12,12 @@ import (
 
 	cred "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/storage/namespace"
 	opsubmission "github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -22,6 +25,10 @@ const (
 	manifestNamespace = "manifest"
 
 	responseNamespace = "response"
+
+	// Names of the indexes over stored applications.
+	manifestIDIndex   = "manifest-id"
+	applicantDIDIndex = "applicant-did"
 )
 
 type StoredManifest struct {
@@ -30,6 +37,8 @@ type StoredManifest struct {
 	IssuerKID   string                      `json:"issuerKid"`
 	Manifest    manifest.CredentialManifest `json:"manifest"`
 	ManifestJWT keyaccess.JWT               `json:"manifestJwt"`
+	// The name of the policy the credentials submitted with applications are verified with, if any.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 type StoredApplication struct {
@@ -41,6 +50,24 @@ type StoredApplication struct {
 	Application    manifest.CredentialApplication `json:"application"`
 	Credentials    []cred.Container               `json:"credentials"`
 	ApplicationJWT keyaccess.JWT                  `json:"applicationJwt"`
+	// The reports of the verification of the credentials submitted with the application.
+	Verifications []cred.VerificationReport `json:"verifications,omitempty"`
+}
+
+// indexEntries returns the entries which make the application retrievable by its manifest and applicant.
+func (sa StoredApplication) indexEntries() []storage.IndexEntry {
+	return []storage.IndexEntry{
+		storage.NewIndexEntry(manifestIDIndex, sa.ManifestID),
+		storage.NewIndexEntry(applicantDIDIndex, sa.ApplicantDID),
+	}
+}
+
+func applicationIndexEntries(value []byte) ([]storage.IndexEntry, error) {
+	var stored StoredApplication
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling application")
+	}
+	return stored.indexEntries(), nil
 }
 
 type StoredResponse struct {
@@ -50,6 +77,39 @@ type StoredResponse struct {
 	Response     manifest.CredentialResponse `json:"response"`
 	Credentials  []cred.Container            `json:"credentials"`
 	ResponseJWT  keyaccess.JWT               `json:"responseJwt"`
+	// The reports of the verification of the credentials submitted with the application responded to.
+	Verifications []cred.VerificationReport `json:"verifications,omitempty"`
+}
+
+func init() {
+	if err := storage.RegisterMigrations(storage.Migration{
+		Namespace:   credential.ApplicationNamespace,
+		Version:     1,
+		Description: "index applications by manifest and applicant",
+		Index:       applicationIndexEntries,
+	}); err != nil {
+		panic(err)
+	}
+	webhook.RegisterResource(manifestNamespace, webhook.Resource{Noun: webhook.Manifest, Data: manifestWebhookData})
+}
+
+// manifestWebhookData returns a stored manifest in the shape it is returned by the API with.
+func manifestWebhookData(value []byte) (any, error) {
+	var stored StoredManifest
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling stored manifest")
+	}
+	return struct {
+		ID                 string                      `json:"id"`
+		Manifest           manifest.CredentialManifest `json:"credential_manifest"`
+		ManifestJWT        keyaccess.JWT               `json:"manifestJwt"`
+		VerificationPolicy string                      `json:"verificationPolicy,omitempty"`
+	}{
+		ID:                 stored.ID,
+		Manifest:           stored.Manifest,
+		ManifestJWT:        stored.ManifestJWT,
+		VerificationPolicy: stored.VerificationPolicy,
+	}, nil
 }
 
 type Storage struct {
@@ -90,15 +150,15 @@ func (ms *Storage) GetManifest(ctx context.Context, id string) (*StoredManifest,
 	return &stored, nil
 }
 
-// GetManifests attempts to get all stored manifests. It will return those it can even if it has trouble with some.
-func (ms *Storage) GetManifests(ctx context.Context) ([]StoredManifest, error) {
-	gotManifests, err := ms.db.ReadAll(ctx, manifestNamespace)
+// GetManifests attempts to get a page of stored manifests. It will return those it can even if it has trouble with some.
+func (ms *Storage) GetManifests(ctx context.Context, page framework.PageRequest) ([]StoredManifest, string, error) {
+	gotManifests, nextPageToken, err := ms.db.ReadPage(ctx, manifestNamespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "getting all manifests")
+		return nil, "", sdkutil.LoggingErrorMsgf(err, "getting all manifests")
 	}
 	if len(gotManifests) == 0 {
 		logrus.Info("no manifests to get")
-		return nil, nil
+		return nil, nextPageToken, nil
 	}
 	var stored []StoredManifest
 	for _, manifestBytes := range gotManifests {
@@ -109,7 +169,7 @@ func (ms *Storage) GetManifests(ctx context.Context) ([]StoredManifest, error) {
 			logrus.Errorf("could not unmarshal manifest while getting all manifests: %s", err.Error())
 		}
 	}
-	return stored, nil
+	return stored, nextPageToken, nil
 }
 
 func (ms *Storage) DeleteManifest(ctx context.Context, id string) error {
@@ -119,7 +179,8 @@ func (ms *Storage) DeleteManifest(ctx context.Context, id string) error {
 	return nil
 }
 
-func (ms *Storage) StoreApplication(ctx context.Context, application StoredApplication) error {
+// StoreApplication writes the application, which is removed once the ttl has passed when it is positive.
+func (ms *Storage) StoreApplication(ctx context.Context, application StoredApplication, ttl time.Duration) error {
 	id := application.Application.ID
 	if id == "" {
 		return sdkutil.LoggingNewError("could not store application without an ID")
@@ -128,7 +189,7 @@ func (ms *Storage) StoreApplication(ctx context.Context, application StoredAppli
 	if err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "could not store application: %s", id)
 	}
-	return ms.db.Write(ctx, credential.ApplicationNamespace, id, applicationBytes)
+	return storage.WriteIndexedWithTTL(ctx, ms.db, credential.ApplicationNamespace, id, applicationBytes, ttl, application.indexEntries()...)
 }
 
 func (ms *Storage) GetApplication(ctx context.Context, id string) (*StoredApplication, error) {
@@ -146,15 +207,15 @@ func (ms *Storage) GetApplication(ctx context.Context, id string) (*StoredApplic
 	return &stored, nil
 }
 
-// GetApplications attempts to get all stored applications. It will return those it can even if it has trouble with some.
-func (ms *Storage) GetApplications(ctx context.Context) ([]StoredApplication, error) {
-	gotApplications, err := ms.db.ReadAll(ctx, credential.ApplicationNamespace)
+// GetApplications attempts to get a page of stored applications. It will return those it can even if it has trouble with some.
+func (ms *Storage) GetApplications(ctx context.Context, page framework.PageRequest) ([]StoredApplication, string, error) {
+	gotApplications, nextPageToken, err := ms.db.ReadPage(ctx, credential.ApplicationNamespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "getting all applications")
+		return nil, "", sdkutil.LoggingErrorMsg(err, "getting all applications")
 	}
 	if len(gotApplications) == 0 {
 		logrus.Info("no applications to get")
-		return nil, nil
+		return nil, nextPageToken, nil
 	}
 	var stored []StoredApplication
 	for appKey, applicationBytes := range gotApplications {
@@ -165,11 +226,42 @@ func (ms *Storage) GetApplications(ctx context.Context) ([]StoredApplication, er
 			logrus.WithError(err).Errorf("could not unmarshal stored application while getting all applications: %s", appKey)
 		}
 	}
-	return stored, nil
+	return stored, nextPageToken, nil
+}
+
+// GetApplicationsByManifest gets a page of the stored applications for the given manifest.
+func (ms *Storage) GetApplicationsByManifest(ctx context.Context, manifestID string, page framework.PageRequest) ([]StoredApplication, string, error) {
+	return ms.getIndexedApplications(ctx, manifestIDIndex, manifestID, page)
+}
+
+// GetApplicationsByApplicant gets a page of the stored applications made by the given applicant.
+func (ms *Storage) GetApplicationsByApplicant(ctx context.Context, applicantDID string, page framework.PageRequest) ([]StoredApplication, string, error) {
+	return ms.getIndexedApplications(ctx, applicantDIDIndex, applicantDID, page)
+}
+
+func (ms *Storage) getIndexedApplications(ctx context.Context, index, value string, page framework.PageRequest) ([]StoredApplication, string, error) {
+	keys, err := storage.ReadIndex(ctx, ms.db, credential.ApplicationNamespace, index, value)
+	if err != nil {
+		return nil, "", sdkutil.LoggingErrorMsgf(err, "getting applications with %s: %s", index, value)
+	}
+	pageKeys, nextPageToken, err := storage.PaginateKeys(keys, page.PageToken, page.PageSize)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "paginating application keys")
+	}
+	stored := make([]StoredApplication, 0, len(pageKeys))
+	for _, key := range pageKeys {
+		gotApplication, err := ms.GetApplication(ctx, key)
+		if err != nil {
+			logrus.WithError(err).Errorf("could not get stored application while getting applications with %s: %s", index, value)
+			continue
+		}
+		stored = append(stored, *gotApplication)
+	}
+	return stored, nextPageToken, nil
 }
 
 func (ms *Storage) DeleteApplication(ctx context.Context, id string) error {
-	if err := ms.db.Delete(ctx, credential.ApplicationNamespace, id); err != nil {
+	if err := storage.DeleteIndexed(ctx, ms.db, credential.ApplicationNamespace, id); err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "deleting application: %s", id)
 	}
 	return nil
@@ -202,15 +294,15 @@ func (ms *Storage) GetResponse(ctx context.Context, id string) (*StoredResponse,
 	return &stored, nil
 }
 
-// GetResponses attempts to get all stored responses. It will return those it can even if it has trouble with some.
-func (ms *Storage) GetResponses(ctx context.Context) ([]StoredResponse, error) {
-	gotResponses, err := ms.db.ReadAll(ctx, responseNamespace)
+// GetResponses attempts to get a page of stored responses. It will return those it can even if it has trouble with some.
+func (ms *Storage) GetResponses(ctx context.Context, page framework.PageRequest) ([]StoredResponse, string, error) {
+	gotResponses, nextPageToken, err := ms.db.ReadPage(ctx, responseNamespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "getting all responses")
+		return nil, "", sdkutil.LoggingErrorMsg(err, "getting all responses")
 	}
 	if len(gotResponses) == 0 {
 		logrus.Info("no responses to get")
-		return nil, nil
+		return nil, nextPageToken, nil
 	}
 	var stored []StoredResponse
 	for responseKey, responseBytes := range gotResponses {
@@ -221,7 +313,7 @@ func (ms *Storage) GetResponses(ctx context.Context) ([]StoredResponse, error) {
 			logrus.WithError(err).Errorf("could not unmarshal stored response while getting all responses: %s", responseKey)
 		}
 	}
-	return stored, nil
+	return stored, nextPageToken, nil
 }
 
 func (ms *Storage) DeleteResponse(ctx context.Context, id string) error {
@@ -0,0 +1,30 @@
+package batch
+
+import (
+	"fmt"
+
+	"github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+const (
+	// ParentResource is the prefix of the credential batch parent resource.
+	ParentResource = "credentials/batches"
+)
+
+// IDFromBatchID returns an operation ID from the batch ID.
+func IDFromBatchID(id string) string {
+	return fmt.Sprintf("%s/%s", ParentResource, id)
+}
+
+// Result is the outcome of one of the credential requests of a batch, which holds either the created credential or
+// the reason it could not be created.
+type Result struct {
+	Container *credential.Container `json:"container,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// Response is the response of a batch operation, holding the result of each of its credential requests in the order
+// they were made.
+type Response struct {
+	Results []Result `json:"results"`
+}
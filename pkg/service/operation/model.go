@@ -3,6 +3,8 @@ package operation
 import (
 	"github.com/TBD54566975/ssi-sdk/util"
 	"go.einride.tech/aip/filtering"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 )
 
 type Result struct {
@@ -17,8 +19,9 @@ type Operation struct {
 }
 
 type GetOperationsRequest struct {
-	Parent string `validate:"required"`
-	Filter filtering.Filter
+	Parent      string `validate:"required"`
+	Filter      filtering.Filter
+	PageRequest framework.PageRequest
 }
 
 func (r GetOperationsRequest) Validate() error {
@@ -26,7 +29,8 @@ func (r GetOperationsRequest) Validate() error {
 }
 
 type GetOperationsResponse struct {
-	Operations []Operation
+	Operations    []Operation
+	NextPageToken string
 }
 
 type GetOperationRequest struct {
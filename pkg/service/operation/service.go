@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
@@ -13,6 +14,7 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	manifestmodel "github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
 	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
@@ -48,13 +50,14 @@ func (s Service) GetOperations(ctx context.Context, request GetOperationsRequest
 		return nil, errors.Wrap(err, "invalid request")
 	}
 
-	ops, err := s.storage.GetOperations(ctx, request.Parent, request.Filter)
+	ops, nextPageToken, err := s.storage.GetOperations(ctx, request.Parent, request.Filter, request.PageRequest)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching ops from storage")
 	}
 
 	resp := &GetOperationsResponse{
-		Operations: make([]Operation, len(ops)),
+		Operations:    make([]Operation, len(ops)),
+		NextPageToken: nextPageToken,
 	}
 	for i, op := range ops {
 		op := op
@@ -95,6 +98,12 @@ func ServiceModel(op opstorage.StoredOperation) (*Operation, error) {
 				return nil, errors.Wrap(err, "unmarshalling cred response")
 			}
 			newOp.Result.Response = manifestmodel.ServiceModel(&s)
+		case strings.HasPrefix(op.ID, batch.ParentResource):
+			var r batch.Response
+			if err := json.Unmarshal(op.Response, &r); err != nil {
+				return nil, errors.Wrap(err, "unmarshalling batch response")
+			}
+			newOp.Result.Response = r
 		default:
 			return nil, errors.New("unknown response type")
 		}
@@ -127,8 +136,26 @@ func (s Service) CancelOperation(ctx context.Context, request CancelOperationReq
 	return ServiceModel(*storedOp)
 }
 
-func NewOperationService(s storage.ServiceStorage) (*Service, error) {
-	opStorage, err := NewOperationStorage(s)
+// PurgeOperations deletes the operations of every parent resource which have been done for longer than retention, and
+// returns how many were deleted.
+func (s Service) PurgeOperations(ctx context.Context, retention time.Duration) (int, error) {
+	now := time.Now().UTC()
+	purged := 0
+	for _, parent := range []string{submission.ParentResource, credential.ParentResource, batch.ParentResource} {
+		n, err := s.storage.PurgeOperations(ctx, parent, now.Add(-retention), now)
+		purged += n
+		if err != nil {
+			return purged, errors.Wrapf(err, "purging operations of %s", parent)
+		}
+	}
+	return purged, nil
+}
+
+// NewOperationService reads the operations of submissions from submissionStorage, the storage of the presentation
+// service, those of credential applications from applicationStorage, the storage of the manifest service, and those
+// of credential batches from batchStorage, the storage of the credential service.
+func NewOperationService(submissionStorage, applicationStorage, batchStorage storage.ServiceStorage) (*Service, error) {
+	opStorage, err := NewOperationStorageByParent(submissionStorage, applicationStorage, batchStorage)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "creating operation storage")
 	}
@@ -3,6 +3,7 @@ package operation
 import (
 	"context"
 	"strings"
+	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
@@ -10,6 +11,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.einride.tech/aip/filtering"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/storage/namespace"
@@ -21,8 +24,38 @@ const (
 	cancelledReason = "operation cancelled"
 )
 
+// Storage stores operations along with the resources they track, so that both can be updated in a single transaction.
+// Submissions, credential applications and credentials may be stored by different storage providers, so their
+// operations are too.
 type Storage struct {
-	db storage.ServiceStorage
+	submissionDB  storage.ServiceStorage
+	applicationDB storage.ServiceStorage
+	batchDB       storage.ServiceStorage
+}
+
+// db returns the storage of the operations of the given parent resource, e.g. "presentations/submissions", or false
+// when the parent resource is not recognized.
+func (b Storage) db(parent string) (storage.ServiceStorage, bool) {
+	switch parent {
+	case submission.ParentResource:
+		return b.submissionDB, true
+	case credential.ParentResource:
+		return b.applicationDB, true
+	case batch.ParentResource:
+		return b.batchDB, true
+	default:
+		return nil, false
+	}
+}
+
+// dbFromID returns the storage of the operation with the given id.
+func (b Storage) dbFromID(id string) (storage.ServiceStorage, error) {
+	if i := strings.LastIndex(id, "/"); i != -1 {
+		if db, ok := b.db(id[:i]); ok {
+			return db, nil
+		}
+	}
+	return nil, errors.Errorf("unrecognized id structure<%s>", id)
 }
 
 func (b Storage) CancelOperation(ctx context.Context, id string) (*opstorage.StoredOperation, error) {
@@ -30,19 +63,19 @@ func (b Storage) CancelOperation(ctx context.Context, id string) (*opstorage.Sto
 	var err error
 	switch {
 	case strings.HasPrefix(id, submission.ParentResource):
-		_, opData, err = b.db.UpdateValueAndOperation(
-			ctx,
+		_, opData, err = storage.UpdateIndexedValueAndOperation(
+			ctx, b.submissionDB,
 			submission.Namespace, opstorage.StatusObjectID(id), storage.NewUpdater(map[string]any{
 				"status": submission.StatusCancelled,
 				"reason": cancelledReason,
-			}),
+			}), submission.IndexEntries,
 			namespace.FromID(id), id, submission.OperationUpdater{
 				UpdaterWithMap: storage.NewUpdater(map[string]any{
 					"done": true,
 				}),
 			})
 	case strings.HasPrefix(id, credential.ParentResource):
-		_, opData, err = b.db.UpdateValueAndOperation(
+		_, opData, err = b.applicationDB.UpdateValueAndOperation(
 			ctx,
 			credential.ApplicationNamespace, opstorage.StatusObjectID(id), storage.NewUpdater(map[string]any{
 				"status": credential.StatusCancelled,
@@ -54,6 +87,8 @@ func (b Storage) CancelOperation(ctx context.Context, id string) (*opstorage.Sto
 				}),
 			},
 		)
+	case strings.HasPrefix(id, batch.ParentResource):
+		return nil, errors.New("credential batches cannot be cancelled")
 	default:
 		return nil, errors.New("unrecognized id structure")
 	}
@@ -68,16 +103,26 @@ func (b Storage) CancelOperation(ctx context.Context, id string) (*opstorage.Sto
 	return &op, nil
 }
 
-func (b Storage) StoreOperation(ctx context.Context, op opstorage.StoredOperation) error {
+// StoreOperation writes the operation, which is removed once the ttl has passed when it is positive. Done operations
+// are stamped with the time they finished unless they already are.
+func (b Storage) StoreOperation(ctx context.Context, op opstorage.StoredOperation, ttl time.Duration) error {
 	id := op.ID
 	if id == "" {
 		return sdkutil.LoggingNewError("ID is required for storing operations")
 	}
+	if op.Done && op.DoneAt == nil {
+		now := time.Now().UTC()
+		op.DoneAt = &now
+	}
 	jsonBytes, err := json.Marshal(op)
 	if err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "marshalling operation with id: %s", id)
 	}
-	if err = b.db.Write(ctx, namespace.FromID(id), id, jsonBytes); err != nil {
+	db, err := b.dbFromID(id)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "storing operation with id: %s", id)
+	}
+	if err = db.WriteWithTTL(ctx, namespace.FromID(id), id, jsonBytes, ttl); err != nil {
 		return sdkutil.LoggingErrorMsg(err, "writing to db")
 	}
 	return nil
@@ -85,7 +130,11 @@ func (b Storage) StoreOperation(ctx context.Context, op opstorage.StoredOperatio
 
 func (b Storage) GetOperation(ctx context.Context, id string) (opstorage.StoredOperation, error) {
 	var stored opstorage.StoredOperation
-	jsonBytes, err := b.db.Read(ctx, namespace.FromID(id), id)
+	db, err := b.dbFromID(id)
+	if err != nil {
+		return stored, sdkutil.LoggingNewErrorf("operation not found with id: %s", id)
+	}
+	jsonBytes, err := db.Read(ctx, namespace.FromID(id), id)
 	if err != nil {
 		return stored, sdkutil.LoggingErrorMsgf(err, "reading operation with id: %s", id)
 	}
@@ -98,15 +147,21 @@ func (b Storage) GetOperation(ctx context.Context, id string) (opstorage.StoredO
 	return stored, nil
 }
 
-func (b Storage) GetOperations(ctx context.Context, parent string, filter filtering.Filter) ([]opstorage.StoredOperation, error) {
-	operations, err := b.db.ReadAll(ctx, namespace.FromParent(parent))
+// GetOperations returns the operations in the requested page that match the filter. Since the filter is applied after
+// reading the page, fewer operations than the page size may be returned even when there are more pages.
+func (b Storage) GetOperations(ctx context.Context, parent string, filter filtering.Filter, page framework.PageRequest) ([]opstorage.StoredOperation, string, error) {
+	db, ok := b.db(parent)
+	if !ok {
+		return nil, "", nil
+	}
+	operations, nextPageToken, err := db.ReadPage(ctx, namespace.FromParent(parent), page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not get all operations")
+		return nil, "", sdkutil.LoggingErrorMsgf(err, "could not get all operations")
 	}
 
 	shouldInclude, err := storage.NewIncludeFunc(filter)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	stored := make([]opstorage.StoredOperation, 0, len(operations))
 	for i, manifestBytes := range operations {
@@ -120,19 +175,72 @@ func (b Storage) GetOperations(ctx context.Context, parent string, filter filter
 			stored = append(stored, nextOp)
 		}
 	}
-	return stored, nil
+	return stored, nextPageToken, nil
 }
 
 func (b Storage) DeleteOperation(ctx context.Context, id string) error {
-	if err := b.db.Delete(ctx, namespace.FromID(id), id); err != nil {
+	db, err := b.dbFromID(id)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "deleting operation: %s", id)
+	}
+	if err = db.Delete(ctx, namespace.FromID(id), id); err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "deleting operation: %s", id)
 	}
 	return nil
 }
 
+// PurgeOperations deletes the operations of the parent resource which finished before doneBefore, and returns how many
+// were deleted. Done operations which were never stamped with the time they finished are stamped with now instead, so
+// that they are deleted once they have been done for as long.
+func (b Storage) PurgeOperations(ctx context.Context, parent string, doneBefore, now time.Time) (int, error) {
+	db, ok := b.db(parent)
+	if !ok {
+		return 0, sdkutil.LoggingNewErrorf("unrecognized parent resource<%s>", parent)
+	}
+	operations, err := db.ReadAll(ctx, namespace.FromParent(parent))
+	if err != nil {
+		return 0, sdkutil.LoggingErrorMsgf(err, "reading operations of %s", parent)
+	}
+
+	purged := 0
+	for id, opBytes := range operations {
+		var op opstorage.StoredOperation
+		if err = json.Unmarshal(opBytes, &op); err != nil {
+			logrus.WithError(err).WithField("operation_id", id).Warn("Skipping operation")
+			continue
+		}
+		if !op.Done {
+			continue
+		}
+		if op.DoneAt == nil {
+			op.DoneAt = &now
+			if err = b.StoreOperation(ctx, op, 0); err != nil {
+				return purged, errors.Wrapf(err, "stamping operation<%s>", id)
+			}
+			continue
+		}
+		if !op.DoneAt.Before(doneBefore) {
+			continue
+		}
+		if err = db.Delete(ctx, namespace.FromParent(parent), id); err != nil {
+			return purged, sdkutil.LoggingErrorMsgf(err, "deleting operation: %s", id)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// NewOperationStorage stores the operations of every parent resource in db.
 func NewOperationStorage(db storage.ServiceStorage) (*Storage, error) {
-	if db == nil {
+	return NewOperationStorageByParent(db, db, db)
+}
+
+// NewOperationStorageByParent stores the operations of submissions in submissionDB, where the presentation service
+// stores submissions, those of credential applications in applicationDB, where the manifest service stores
+// applications, and those of credential batches in batchDB, where the credential service stores credentials.
+func NewOperationStorageByParent(submissionDB, applicationDB, batchDB storage.ServiceStorage) (*Storage, error) {
+	if submissionDB == nil || applicationDB == nil || batchDB == nil {
 		return nil, errors.New("bolt db reference is nil")
 	}
-	return &Storage{db: db}, nil
+	return &Storage{submissionDB: submissionDB, applicationDB: applicationDB, batchDB: batchDB}, nil
 }
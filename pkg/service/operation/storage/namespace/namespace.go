@@ -3,6 +3,7 @@ package namespace
 import (
 	"strings"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
 )
@@ -10,6 +11,7 @@ import (
 const (
 	namespace                   = "operation_submission"
 	credentialResponseNamespace = "operation_credential_response"
+	credentialBatchNamespace    = "operation_credential_batch"
 )
 
 // FromID returns a namespace from a given operation ID. An empty string is returned when the namespace cannot
@@ -30,6 +32,8 @@ func FromParent(parent string) string {
 		return namespace
 	case credential.ParentResource:
 		return credentialResponseNamespace
+	case batch.ParentResource:
+		return credentialBatchNamespace
 	default:
 		return ""
 	}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"strings"
+	"time"
 
 	"go.einride.tech/aip/filtering"
 )
@@ -17,6 +18,10 @@ type StoredOperation struct {
 
 	// Populated only when Done == true and Error == ""
 	Response []byte `json:"response,omitempty"`
+
+	// When this operation finished. Operations which are marked done along with the resource they track, such as
+	// reviewed applications, are not stamped until they are first seen by the operation purge job.
+	DoneAt *time.Time `json:"doneAt,omitempty"`
 }
 
 func (s StoredOperation) FilterVariablesMap() map[string]any {
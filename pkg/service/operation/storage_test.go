@@ -2,14 +2,19 @@ package operation
 
 import (
 	"context"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.einride.tech/aip/filtering"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/batch"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/storage/namespace"
@@ -17,15 +22,10 @@ import (
 )
 
 func setupTestDB(t *testing.T) storage.ServiceStorage {
-	file, err := os.CreateTemp("", "bolt")
-	require.NoError(t, err)
-	name := file.Name()
-	s, err := storage.NewStorage(storage.Bolt, name)
+	s, err := storage.NewStorage(storage.Memory, nil)
 	require.NoError(t, err)
 	t.Cleanup(func() {
 		_ = s.Close()
-		_ = file.Close()
-		_ = os.Remove(name)
 	})
 	return s
 }
@@ -96,7 +96,8 @@ func TestStorage_CancelOperation(t *testing.T) {
 			require.NoError(t, s.Write(context.Background(), namespace.FromParent("credentials/responses"), "credentials/responses/hello", opData))
 
 			b := Storage{
-				db: tt.fields.db,
+				submissionDB:  tt.fields.db,
+				applicationDB: tt.fields.db,
 			}
 			got, err := b.CancelOperation(context.Background(), tt.args.id)
 			if (err != nil) != tt.wantErr {
@@ -109,3 +110,90 @@ func TestStorage_CancelOperation(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_OperationsByParent(t *testing.T) {
+	submissionDB := setupTestDB(t)
+	applicationDB := setupTestDB(t)
+	batchDB := setupTestDB(t)
+	b, err := NewOperationStorageByParent(submissionDB, applicationDB, batchDB)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	submissionOpID := "presentations/submissions/hello"
+	applicationOpID := "credentials/responses/hello"
+	batchOpID := "credentials/batches/hello"
+	require.NoError(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: submissionOpID}, 0))
+	require.NoError(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: applicationOpID}, 0))
+	require.NoError(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: batchOpID}, 0))
+
+	// each operation is stored along with the resource it tracks
+	exists, err := submissionDB.Exists(ctx, namespace.FromID(submissionOpID), submissionOpID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = applicationDB.Exists(ctx, namespace.FromID(applicationOpID), applicationOpID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = batchDB.Exists(ctx, namespace.FromID(batchOpID), batchOpID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = submissionDB.Exists(ctx, namespace.FromID(applicationOpID), applicationOpID)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	got, err := b.GetOperation(ctx, applicationOpID)
+	require.NoError(t, err)
+	require.Equal(t, applicationOpID, got.ID)
+
+	ops, _, err := b.GetOperations(ctx, "presentations/submissions", filtering.Filter{}, framework.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	require.Equal(t, submissionOpID, ops[0].ID)
+
+	// batches run to completion once started
+	_, err = b.CancelOperation(ctx, batchOpID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "credential batches cannot be cancelled")
+
+	require.Error(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: "unknown/hello"}, 0))
+}
+
+func TestStorage_PurgeOperations(t *testing.T) {
+	b, err := NewOperationStorage(setupTestDB(t))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	old := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+	require.NoError(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: "credentials/batches/old", Done: true, DoneAt: &old}, 0))
+	require.NoError(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: "credentials/batches/recent", Done: true, DoneAt: &recent}, 0))
+	require.NoError(t, b.StoreOperation(ctx, opstorage.StoredOperation{ID: "credentials/batches/pending"}, 0))
+
+	// operations marked done along with the resource they track have no completion time yet
+	data, err := json.Marshal(opstorage.StoredOperation{ID: "credentials/batches/unstamped", Done: true})
+	require.NoError(t, err)
+	require.NoError(t, b.batchDB.Write(ctx, namespace.FromParent(batch.ParentResource), "credentials/batches/unstamped", data))
+
+	purged, err := b.PurgeOperations(ctx, batch.ParentResource, now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = b.GetOperation(ctx, "credentials/batches/old")
+	require.Error(t, err)
+	_, err = b.GetOperation(ctx, "credentials/batches/recent")
+	require.NoError(t, err)
+	_, err = b.GetOperation(ctx, "credentials/batches/pending")
+	require.NoError(t, err)
+	unstamped, err := b.GetOperation(ctx, "credentials/batches/unstamped")
+	require.NoError(t, err)
+	require.NotNil(t, unstamped.DoneAt)
+	assert.True(t, unstamped.DoneAt.Equal(now))
+
+	// once stamped, it is purged after the retention period
+	purged, err = b.PurgeOperations(ctx, batch.ParentResource, now.Add(time.Hour), now.Add(25*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	_, err = b.PurgeOperations(ctx, "unknown", now, now)
+	require.Error(t, err)
+}
@@ -19,6 +19,8 @@ const (
 	Namespace = "presentation_submission"
 	// ParentResource is the prefix of the submission parent resource.
 	ParentResource = "presentations/submissions"
+	// StatusIndex is the name of the index over the status of stored submissions.
+	StatusIndex = "status"
 )
 
 // Status indicates the current state of a submission.
@@ -74,3 +76,14 @@ func (u OperationUpdater) Validate(v []byte) error {
 }
 
 var _ storage.ResponseSettingUpdater = (*OperationUpdater)(nil)
+
+// IndexEntries is a storage.IndexFunc which returns the index entries of a stored submission.
+func IndexEntries(value []byte) ([]storage.IndexEntry, error) {
+	var stored struct {
+		Status Status `json:"status"`
+	}
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling submission")
+	}
+	return []storage.IndexEntry{storage.NewIndexEntry(StatusIndex, stored.Status.String())}, nil
+}
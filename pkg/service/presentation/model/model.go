@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/util"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
 )
 
@@ -16,6 +19,8 @@ type CreatePresentationDefinitionRequest struct {
 	PresentationDefinition exchange.PresentationDefinition `json:"presentationDefinition" validate:"required"`
 	Author                 string                          `json:"author" validate:"required"`
 	AuthorKID              string                          `json:"authorKid" validate:"required"`
+	// The name of the policy the credentials submitted against the definition are verified with, if any.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
 }
 
 func (cpr CreatePresentationDefinitionRequest) IsValid() error {
@@ -25,6 +30,7 @@ func (cpr CreatePresentationDefinitionRequest) IsValid() error {
 type CreatePresentationDefinitionResponse struct {
 	PresentationDefinition    exchange.PresentationDefinition `json:"presentationDefinition"`
 	PresentationDefinitionJWT keyaccess.JWT                   `json:"presentationDefinitionJWT"`
+	VerificationPolicy        string                          `json:"verificationPolicy,omitempty"`
 }
 
 type GetPresentationDefinitionRequest struct {
@@ -35,12 +41,25 @@ type GetPresentationDefinitionResponse struct {
 	ID                        string                          `json:"id"`
 	PresentationDefinition    exchange.PresentationDefinition `json:"presentationDefinition"`
 	PresentationDefinitionJWT keyaccess.JWT                   `json:"presentationDefinitionJWT"`
+	VerificationPolicy        string                          `json:"verificationPolicy,omitempty"`
 }
 
 type DeletePresentationDefinitionRequest struct {
 	ID string `json:"id" validate:"required"`
 }
 
+type CreateChallengeRequest struct {
+	DefinitionID string `json:"definitionId" validate:"required"`
+}
+
+// CreateChallengeResponse holds the nonce and audience the key binding JWTs of the SD-JWT credentials of a submission
+// against the definition are to be issued for. The nonce can only be submitted once, until it expires.
+type CreateChallengeResponse struct {
+	Nonce     string    `json:"nonce"`
+	Audience  string    `json:"audience"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 type CreateSubmissionRequest struct {
 	Presentation  credsdk.VerifiablePresentation  `json:"presentation" validate:"required"`
 	SubmissionJWT keyaccess.JWT                   `json:"submissionJwt,omitempty" validate:"required"`
@@ -69,7 +88,8 @@ type DeleteSubmissionRequest struct {
 }
 
 type ListSubmissionRequest struct {
-	Filter filtering.Filter
+	Filter      filtering.Filter
+	PageRequest framework.PageRequest
 }
 
 type Submission struct {
@@ -79,6 +99,9 @@ type Submission struct {
 	Reason string `json:"reason"`
 	// The verifiable presentation containing the presentation_submission along with the credentials presented.
 	VerifiablePresentation *credsdk.VerifiablePresentation `json:"verifiablePresentation,omitempty"`
+	// The reports of the verification of each credential submitted along with the presentation, listing the checks
+	// run on it.
+	Verifications []credential.VerificationReport `json:"verifications,omitempty"`
 }
 
 func (r Submission) GetSubmission() *exchange.PresentationSubmission {
@@ -98,11 +121,17 @@ func (r Submission) GetSubmission() *exchange.PresentationSubmission {
 }
 
 type ListSubmissionResponse struct {
-	Submissions []Submission `json:"submissions"`
+	Submissions   []Submission `json:"submissions"`
+	NextPageToken string       `json:"nextPageToken,omitempty"`
+}
+
+type ListDefinitionsRequest struct {
+	PageRequest framework.PageRequest
 }
 
 type ListDefinitionsResponse struct {
-	Definitions []*exchange.PresentationDefinition `json:"definitions"`
+	Definitions   []*exchange.PresentationDefinition `json:"definitions"`
+	NextPageToken string                             `json:"nextPageToken,omitempty"`
 }
 
 type ReviewSubmissionRequest struct {
@@ -122,5 +151,6 @@ func ServiceModel(storedSubmission *storage.StoredSubmission) Submission {
 		Status:                 storedSubmission.Status.String(),
 		Reason:                 storedSubmission.Reason,
 		VerifiablePresentation: &storedSubmission.VerifiablePresentation,
+		Verifications:          storedSubmission.Verifications,
 	}
 }
@@ -3,11 +3,13 @@ package presentation
 import (
 	"context"
 	"fmt"
+	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/jws"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -23,9 +25,13 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation/model"
 	presentationstorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
+// challengeTTL is how long the nonce of a challenge can be submitted after it is issued.
+const challengeTTL = 10 * time.Minute
+
 type Service struct {
 	storage    *Storage
 	keystore   *keystore.Service
@@ -34,6 +40,7 @@ type Service struct {
 	resolver   didsdk.Resolver
 	schema     *schema.Service
 	verifier   *credential.Verifier
+	policies   credential.PolicyResolution
 }
 
 func (s Service) Type() framework.Type {
@@ -58,7 +65,10 @@ func (s Service) Config() config.PresentationServiceConfig {
 	return s.config
 }
 
-func NewPresentationService(config config.PresentationServiceConfig, s storage.ServiceStorage, resolver didsdk.Resolver, schema *schema.Service, keystore *keystore.Service) (*Service, error) {
+// NewPresentationService creates the presentation service. The status lists of the credentials submitted to it are
+// resolved through statusLists when it holds them, and are otherwise fetched over HTTP. The verification policies named
+// by presentation definitions are resolved through policies.
+func NewPresentationService(config config.PresentationServiceConfig, s storage.ServiceStorage, resolver didsdk.Resolver, schema *schema.Service, keystore *keystore.Service, statusLists credential.StatusListResolution, policies credential.PolicyResolution) (*Service, error) {
 	presentationStorage, err := NewPresentationStorage(s)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate definition storage for the presentation service")
@@ -67,10 +77,13 @@ func NewPresentationService(config config.PresentationServiceConfig, s storage.S
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the operations")
 	}
-	verifier, err := credential.NewCredentialVerifier(resolver, schema)
+	verifier, err := credential.NewCredentialVerifier(resolver, schema, statusLists)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate verifier")
 	}
+	if policies == nil {
+		return nil, sdkutil.LoggingNewError("policies cannot be nil")
+	}
 	service := Service{
 		storage:    presentationStorage,
 		keystore:   keystore,
@@ -79,6 +92,7 @@ func NewPresentationService(config config.PresentationServiceConfig, s storage.S
 		resolver:   resolver,
 		schema:     schema,
 		verifier:   verifier,
+		policies:   policies,
 	}
 	if !service.Status().IsReady() {
 		return nil, errors.New(service.Status().Message)
@@ -99,17 +113,24 @@ func (s Service) CreatePresentationDefinition(ctx context.Context, request model
 		return nil, sdkutil.LoggingErrorMsg(err, "provided value is not a valid presentation definition")
 	}
 
+	if request.VerificationPolicy != "" {
+		if _, err := s.policies.ResolvePolicy(ctx, request.VerificationPolicy); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not resolve verification policy: %s", request.VerificationPolicy)
+		}
+	}
+
 	storedPresentation := StoredPresentation{
 		ID:                     request.PresentationDefinition.ID,
 		PresentationDefinition: request.PresentationDefinition,
 		Author:                 request.Author,
 		AuthorKID:              request.AuthorKID,
+		VerificationPolicy:     request.VerificationPolicy,
 	}
 
 	if err := s.storage.StorePresentation(ctx, storedPresentation); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not store presentation")
 	}
-	defJWT, err := s.keystore.Sign(context.Background(), storedPresentation.AuthorKID, exchange.PresentationDefinitionEnvelope{PresentationDefinition: storedPresentation.PresentationDefinition})
+	defJWT, err := s.keystore.Sign(ctx, storedPresentation.AuthorKID, exchange.PresentationDefinitionEnvelope{PresentationDefinition: storedPresentation.PresentationDefinition})
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "signing presentation definition enveloper with author<%s>", storedPresentation.Author)
 	}
@@ -117,6 +138,7 @@ func (s Service) CreatePresentationDefinition(ctx context.Context, request model
 	var m model.CreatePresentationDefinitionResponse
 	m.PresentationDefinition = storedPresentation.PresentationDefinition
 	m.PresentationDefinitionJWT = *defJWT
+	m.VerificationPolicy = storedPresentation.VerificationPolicy
 	return &m, nil
 }
 
@@ -139,6 +161,7 @@ func (s Service) GetPresentationDefinition(ctx context.Context, request model.Ge
 		ID:                        storedPresentation.ID,
 		PresentationDefinition:    storedPresentation.PresentationDefinition,
 		PresentationDefinitionJWT: *defJWT,
+		VerificationPolicy:        storedPresentation.VerificationPolicy,
 	}, nil
 }
 
@@ -152,6 +175,29 @@ func (s Service) DeletePresentationDefinition(ctx context.Context, request model
 	return nil
 }
 
+// CreateChallenge issues a nonce for a submission against the presentation definition, which the key binding JWTs of
+// its SD-JWT credentials are to be issued for, along with the author of the definition, so that they cannot be replayed
+// in another submission. The nonce expires after challengeTTL.
+func (s Service) CreateChallenge(ctx context.Context, request model.CreateChallengeRequest) (*model.CreateChallengeResponse, error) {
+	definition, err := s.storage.GetPresentation(ctx, request.DefinitionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting presentation definition")
+	}
+	challenge := StoredChallenge{
+		Nonce:        uuid.NewString(),
+		DefinitionID: definition.ID,
+		ExpiresAt:    time.Now().Add(challengeTTL),
+	}
+	if err = s.storage.StoreChallenge(ctx, challenge); err != nil {
+		return nil, errors.Wrap(err, "storing challenge")
+	}
+	return &model.CreateChallengeResponse{
+		Nonce:     challenge.Nonce,
+		Audience:  definition.Author,
+		ExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
 // CreateSubmission houses the main service logic for presentation submission creation. It validates the input, and
 // produces a presentation submission value that conforms with the Submission specification.
 func (s Service) CreateSubmission(ctx context.Context, request model.CreateSubmissionRequest) (*operation.Operation, error) {
@@ -191,35 +237,69 @@ func (s Service) CreateSubmission(ctx context.Context, request model.CreateSubmi
 		return nil, errors.Wrap(err, "getting presentation definition")
 	}
 
+	// the credentials are verified as per the policy of the definition, if any
+	var policy credential.Policy
+	if definition.VerificationPolicy != "" {
+		resolved, err := s.policies.ResolvePolicy(ctx, definition.VerificationPolicy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving verification policy<%s> of presentation definition", definition.VerificationPolicy)
+		}
+		policy = *resolved
+	}
+
+	nonce, err := s.consumeKeyBindingChallenge(ctx, definition.ID, request.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	verifications := make([]credential.VerificationReport, 0, len(request.Credentials))
 	for _, cred := range request.Credentials {
 		if !cred.IsValid() {
 			return nil, errors.Errorf("invalid credential %+v", cred)
 		}
-		if cred.CredentialJWT != nil {
-			if err = s.verifier.VerifyJWTCredential(ctx, *cred.CredentialJWT); err != nil {
+		if cred.CredentialSDJWT != nil {
+			// key binding JWTs are expected to be issued for the author of the definition
+			report := s.verifier.VerifySDJWTCredential(ctx, *cred.CredentialSDJWT, policy, definition.Author, nonce)
+			if err = report.Err(); err != nil {
+				return nil, errors.Wrapf(err, "verifying sd-jwt credential %s", cred.CredentialSDJWT)
+			}
+			verifications = append(verifications, *report)
+		} else if cred.CredentialJWT != nil {
+			report := s.verifier.VerifyJWTCredential(ctx, *cred.CredentialJWT, policy)
+			if err = report.Err(); err != nil {
 				return nil, errors.Wrapf(err, "verifying jwt credential %s", cred.CredentialJWT)
 			}
+			verifications = append(verifications, *report)
 		} else {
 			if cred.HasDataIntegrityCredential() {
-				if err = s.verifier.VerifyDataIntegrityCredential(ctx, *cred.Credential); err != nil {
+				report := s.verifier.VerifyDataIntegrityCredential(ctx, *cred.Credential, policy)
+				if err = report.Err(); err != nil {
 					return nil, errors.Wrapf(err, "verifying data integrity credential %+v", cred.Credential)
 				}
+				verifications = append(verifications, *report)
 			}
 		}
 	}
 
+	// constraints on sd-jwt credentials are only evaluated against the claims they disclose
+	disclosedPresentation, err := credential.PresentationWithDisclosedClaims(request.Presentation)
+	if err != nil {
+		return nil, errors.Wrap(err, "disclosing presentation credentials")
+	}
+
 	// TODO(gabe) plug in additional credential verification logic here
-	if _, err = exchange.VerifyPresentationSubmissionVP(definition.PresentationDefinition, request.Presentation); err != nil {
+	if _, err = exchange.VerifyPresentationSubmissionVP(definition.PresentationDefinition, *disclosedPresentation); err != nil {
 		return nil, errors.Wrap(err, "verifying presentation submission vp")
 	}
 
 	storedSubmission := presentationstorage.StoredSubmission{
 		Status:                 submission.StatusPending,
 		VerifiablePresentation: request.Presentation,
+		Verifications:          verifications,
 	}
 
 	// TODO(andres): IO requests should be done in parallel, once we have context wired up.
-	if err = s.storage.StoreSubmission(ctx, storedSubmission); err != nil {
+	if err = s.storage.StoreSubmission(ctx, storedSubmission, tenant.SubmissionTTL(ctx, s.config.SubmissionTTL)); err != nil {
 		return nil, errors.Wrap(err, "could not store presentation")
 	}
 
@@ -232,7 +312,7 @@ func (s Service) CreateSubmission(ctx context.Context, request model.CreateSubmi
 		ID:   opID,
 		Done: false,
 	}
-	if err = s.opsStorage.StoreOperation(ctx, storedOp); err != nil {
+	if err = s.opsStorage.StoreOperation(ctx, storedOp, tenant.SubmissionTTL(ctx, s.config.SubmissionTTL)); err != nil {
 		return nil, errors.Wrap(err, "could not store operation")
 	}
 
@@ -242,6 +322,41 @@ func (s Service) CreateSubmission(ctx context.Context, request model.CreateSubmi
 	}, nil
 }
 
+// consumeKeyBindingChallenge returns the nonce the key binding JWTs of the submitted SD-JWT credentials are issued for,
+// once it is checked to be that of an unexpired challenge for the definition, which is then consumed. It is empty when
+// none of the credentials have a key binding JWT.
+func (s Service) consumeKeyBindingChallenge(ctx context.Context, definitionID string, creds []credential.Container) (string, error) {
+	var nonce string
+	for _, cred := range creds {
+		if cred.CredentialSDJWT == nil {
+			continue
+		}
+		// malformed SD-JWTs are reported when they are verified
+		if _, _, keyBinding, err := cred.CredentialSDJWT.Parse(); err != nil || keyBinding == nil {
+			continue
+		}
+		credNonce, err := cred.CredentialSDJWT.KeyBindingNonce()
+		if err != nil {
+			return "", errors.Wrapf(err, "getting key binding nonce of sd-jwt credential %s", cred.CredentialSDJWT)
+		}
+		if nonce != "" && credNonce != nonce {
+			return "", errors.New("key binding jwts of the submitted credentials have different nonces")
+		}
+		nonce = credNonce
+	}
+	if nonce == "" {
+		return "", nil
+	}
+	challenge, err := s.storage.ConsumeChallenge(ctx, nonce, definitionID)
+	if err != nil {
+		return "", errors.Wrap(err, "consuming key binding challenge")
+	}
+	if challenge == nil {
+		return "", errors.Errorf("key binding nonce is not that of an unexpired challenge for presentation definition %s", definitionID)
+	}
+	return nonce, nil
+}
+
 func (s Service) GetSubmission(ctx context.Context, request model.GetSubmissionRequest) (*model.GetSubmissionResponse, error) {
 	logrus.Debugf("getting presentation submission: %s", request.ID)
 
@@ -257,12 +372,12 @@ func (s Service) GetSubmission(ctx context.Context, request model.GetSubmissionR
 func (s Service) ListSubmissions(ctx context.Context, request model.ListSubmissionRequest) (*model.ListSubmissionResponse, error) {
 	logrus.Debug("listing presentation submissions")
 
-	subs, err := s.storage.ListSubmissions(ctx, request.Filter)
+	subs, nextPageToken, err := s.storage.ListSubmissions(ctx, request.Filter, request.PageRequest)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching submissions from storage")
 	}
 
-	resp := &model.ListSubmissionResponse{Submissions: make([]model.Submission, 0, len(subs))}
+	resp := &model.ListSubmissionResponse{Submissions: make([]model.Submission, 0, len(subs)), NextPageToken: nextPageToken}
 	for _, sub := range subs {
 		sub := sub // What's this?? see https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
 		resp.Submissions = append(resp.Submissions, model.ServiceModel(&sub))
@@ -285,15 +400,15 @@ func (s Service) ReviewSubmission(ctx context.Context, request model.ReviewSubmi
 	return &m, nil
 }
 
-func (s Service) ListDefinitions(ctx context.Context) (*model.ListDefinitionsResponse, error) {
+func (s Service) ListDefinitions(ctx context.Context, request model.ListDefinitionsRequest) (*model.ListDefinitionsResponse, error) {
 	logrus.Debug("listing presentation definitions")
 
-	defs, err := s.storage.ListDefinitions(ctx)
+	defs, nextPageToken, err := s.storage.ListDefinitions(ctx, request.PageRequest)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetching definitions from storage")
 	}
 
-	resp := &model.ListDefinitionsResponse{Definitions: make([]*exchange.PresentationDefinition, 0, len(defs))}
+	resp := &model.ListDefinitionsResponse{Definitions: make([]*exchange.PresentationDefinition, 0, len(defs)), NextPageToken: nextPageToken}
 	for _, def := range defs {
 		// What's this?? see https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
 		def := def
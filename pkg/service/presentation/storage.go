@@ -3,6 +3,7 @@ package presentation
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
@@ -11,15 +12,18 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.einride.tech/aip/filtering"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/storage/namespace"
 	opsubmission "github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
 	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 const (
 	presentationDefinitionNamespace = "presentation_definition"
+	challengeNamespace              = "presentation-challenge"
 )
 
 type StoredPresentation struct {
@@ -27,6 +31,47 @@ type StoredPresentation struct {
 	PresentationDefinition exchange.PresentationDefinition `json:"presentationDefinition"`
 	Author                 string                          `json:"issuerID"`
 	AuthorKID              string                          `json:"issuerKid"`
+	// The name of the policy the credentials submitted against the definition are verified with, if any.
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
+}
+
+// StoredChallenge is a nonce issued for the key binding JWTs of the SD-JWT credentials of a single submission against a
+// presentation definition.
+type StoredChallenge struct {
+	Nonce        string    `json:"nonce"`
+	DefinitionID string    `json:"definitionId"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+func init() {
+	if err := storage.RegisterMigrations(storage.Migration{
+		Namespace:   opsubmission.Namespace,
+		Version:     1,
+		Description: "index submissions by status",
+		Index:       opsubmission.IndexEntries,
+	}); err != nil {
+		panic(err)
+	}
+	webhook.RegisterResource(presentationDefinitionNamespace, webhook.Resource{
+		Noun: webhook.Presentation,
+		Data: presentationDefinitionWebhookData,
+	})
+}
+
+// presentationDefinitionWebhookData returns a stored presentation definition in the shape it is returned by the API
+// with, other than its JWT, which is signed when it is read.
+func presentationDefinitionWebhookData(value []byte) (any, error) {
+	var stored StoredPresentation
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling stored presentation definition")
+	}
+	return struct {
+		PresentationDefinition exchange.PresentationDefinition `json:"presentation_definition"`
+		VerificationPolicy     string                          `json:"verificationPolicy,omitempty"`
+	}{
+		PresentationDefinition: stored.PresentationDefinition,
+		VerificationPolicy:     stored.VerificationPolicy,
+	}, nil
 }
 
 type Storage struct {
@@ -41,11 +86,13 @@ func (ps *Storage) UpdateSubmission(ctx context.Context, id string, approved boo
 	if approved {
 		m["status"] = opsubmission.StatusApproved
 	}
-	submissionData, operationData, err := ps.db.UpdateValueAndOperation(
+	submissionData, operationData, err := storage.UpdateIndexedValueAndOperation(
 		ctx,
+		ps.db,
 		opsubmission.Namespace,
 		id,
 		storage.NewUpdater(m),
+		opsubmission.IndexEntries,
 		namespace.FromID(opID),
 		opID,
 		opsubmission.OperationUpdater{
@@ -68,15 +115,22 @@ func (ps *Storage) UpdateSubmission(ctx context.Context, id string, approved boo
 	return s, op, nil
 }
 
-func (ps *Storage) ListSubmissions(ctx context.Context, filter filtering.Filter) ([]prestorage.StoredSubmission, error) {
-	allData, err := ps.db.ReadAll(ctx, opsubmission.Namespace)
+// ListSubmissions returns the submissions in the requested page that match the filter. Filters on the status alone
+// are answered from the status index. Any other filter is applied after reading the page, so fewer submissions than
+// the page size may be returned even when there are more pages.
+func (ps *Storage) ListSubmissions(ctx context.Context, filter filtering.Filter, page framework.PageRequest) ([]prestorage.StoredSubmission, string, error) {
+	if status, ok := storage.EqualityFilterValue(filter, "status"); ok {
+		return ps.listSubmissionsByStatus(ctx, status, page)
+	}
+
+	allData, nextPageToken, err := ps.db.ReadPage(ctx, opsubmission.Namespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading all data")
+		return nil, "", errors.Wrap(err, "reading all data")
 	}
 
 	shouldInclude, err := storage.NewIncludeFunc(filter)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	storedSubmissions := make([]prestorage.StoredSubmission, 0, len(allData))
 	for key, data := range allData {
@@ -94,7 +148,37 @@ func (ps *Storage) ListSubmissions(ctx context.Context, filter filtering.Filter)
 			storedSubmissions = append(storedSubmissions, ss)
 		}
 	}
-	return storedSubmissions, nil
+	return storedSubmissions, nextPageToken, nil
+}
+
+func (ps *Storage) listSubmissionsByStatus(ctx context.Context, status string, page framework.PageRequest) ([]prestorage.StoredSubmission, string, error) {
+	keys, err := storage.ReadIndex(ctx, ps.db, opsubmission.Namespace, opsubmission.StatusIndex, status)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "reading status index")
+	}
+	pageKeys, nextPageToken, err := storage.PaginateKeys(keys, page.PageToken, page.PageSize)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "paginating submission keys")
+	}
+
+	storedSubmissions := make([]prestorage.StoredSubmission, 0, len(pageKeys))
+	for _, key := range pageKeys {
+		data, err := ps.db.Read(ctx, opsubmission.Namespace, key)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "reading submission<%s>", key)
+		}
+		if len(data) == 0 {
+			// the submission expired after the index was read
+			continue
+		}
+		var ss prestorage.StoredSubmission
+		if err = json.Unmarshal(data, &ss); err != nil {
+			logrus.WithError(err).WithField("key", key).Error("unmarshalling submission")
+			continue
+		}
+		storedSubmissions = append(storedSubmissions, ss)
+	}
+	return storedSubmissions, nextPageToken, nil
 }
 
 func NewPresentationStorage(db storage.ServiceStorage) (*Storage, error) {
@@ -142,7 +226,45 @@ func (ps *Storage) DeletePresentation(ctx context.Context, id string) error {
 	return nil
 }
 
-func (ps *Storage) StoreSubmission(ctx context.Context, s prestorage.StoredSubmission) error {
+// StoreChallenge writes the challenge, which is removed once it expires.
+func (ps *Storage) StoreChallenge(ctx context.Context, challenge StoredChallenge) error {
+	challengeBytes, err := json.Marshal(challenge)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not marshal challenge for presentation definition: %s", challenge.DefinitionID)
+	}
+	return ps.db.WriteWithTTL(ctx, challengeNamespace, challenge.Nonce, challengeBytes, time.Until(challenge.ExpiresAt))
+}
+
+// ConsumeChallenge removes the challenge with the given nonce, and returns it, when it was issued for the given
+// presentation definition and has not expired. Otherwise, the challenge is left as is, and nil is returned.
+func (ps *Storage) ConsumeChallenge(ctx context.Context, nonce, definitionID string) (*StoredChallenge, error) {
+	consumed, err := ps.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		challengeBytes, err := tx.Read(ctx, challengeNamespace, nonce)
+		if err != nil {
+			return nil, err
+		}
+		if len(challengeBytes) == 0 {
+			return nil, nil
+		}
+		var challenge StoredChallenge
+		if err = json.Unmarshal(challengeBytes, &challenge); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling challenge")
+		}
+		// expired challenges may be read until they are swept
+		if challenge.DefinitionID != definitionID || time.Now().After(challenge.ExpiresAt) {
+			return nil, nil
+		}
+		return &challenge, tx.Delete(ctx, challengeNamespace, nonce)
+	}, []storage.WatchKey{{Namespace: challengeNamespace, Key: nonce}})
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not consume challenge for presentation definition: %s", definitionID)
+	}
+	challenge, _ := consumed.(*StoredChallenge)
+	return challenge, nil
+}
+
+// StoreSubmission writes the submission, which is removed once the ttl has passed when it is positive.
+func (ps *Storage) StoreSubmission(ctx context.Context, s prestorage.StoredSubmission, ttl time.Duration) error {
 	sub, ok := s.VerifiablePresentation.PresentationSubmission.(exchange.PresentationSubmission)
 	if !ok {
 		return sdkutil.LoggingNewError("asserting that field is of type exchange.PresentationSubmission")
@@ -157,7 +279,11 @@ func (ps *Storage) StoreSubmission(ctx context.Context, s prestorage.StoredSubmi
 	if err != nil {
 		return sdkutil.LoggingNewErrorf("could not store submission definition: %s", id)
 	}
-	return ps.db.Write(ctx, opsubmission.Namespace, id, jsonBytes)
+	entries, err := opsubmission.IndexEntries(jsonBytes)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not index submission definition: %s", id)
+	}
+	return storage.WriteIndexedWithTTL(ctx, ps.db, opsubmission.Namespace, id, jsonBytes, ttl, entries...)
 }
 
 func (ps *Storage) GetSubmission(ctx context.Context, id string) (*prestorage.StoredSubmission, error) {
@@ -175,18 +301,18 @@ func (ps *Storage) GetSubmission(ctx context.Context, id string) (*prestorage.St
 	return &stored, nil
 }
 
-func (ps *Storage) ListDefinitions(ctx context.Context) ([]prestorage.StoredDefinition, error) {
-	m, err := ps.db.ReadAll(ctx, presentationDefinitionNamespace)
+func (ps *Storage) ListDefinitions(ctx context.Context, page framework.PageRequest) ([]prestorage.StoredDefinition, string, error) {
+	m, nextPageToken, err := ps.db.ReadPage(ctx, presentationDefinitionNamespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading all")
+		return nil, "", errors.Wrap(err, "reading all")
 	}
 	ts := make([]prestorage.StoredDefinition, len(m))
 	i := 0
 	for k, v := range m {
 		if err = json.Unmarshal(v, &ts[i]); err != nil {
-			return nil, errors.Wrapf(err, "unmarshalling template with key <%s>", k)
+			return nil, "", errors.Wrapf(err, "unmarshalling template with key <%s>", k)
 		}
 		i++
 	}
-	return ts, nil
+	return ts, nextPageToken, nil
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/pkg/errors"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
 	"go.einride.tech/aip/filtering"
@@ -29,6 +30,8 @@ type StoredSubmission struct {
 	Status                 submission.Status                 `json:"status"`
 	Reason                 string                            `json:"reason"`
 	VerifiablePresentation credential.VerifiablePresentation `json:"vp"`
+	// The reports of the verification of each credential submitted along with the presentation.
+	Verifications []credint.VerificationReport `json:"verifications,omitempty"`
 }
 
 func (s StoredSubmission) FilterVariablesMap() map[string]any {
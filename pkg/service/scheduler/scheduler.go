@@ -0,0 +1,379 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// The scheduler stores when each job last ran and when it is next due in the jobs namespace, so that the schedule
+// survives restarts and is shared by every instance of the service using the same storage. Before running a job, an
+// instance takes a lease on it in the leases namespace, so that a job runs on a single instance at a time. Leases
+// expire after the lease duration, so that a job is taken over by another instance when the one running it stops. The
+// instance running a job renews its lease every third of the lease duration, and stops the job when it cannot.
+const (
+	jobNamespace   = "scheduler-jobs"
+	leaseNamespace = "scheduler-leases"
+)
+
+// TickInterval is how often the scheduler checks for jobs which are due.
+var TickInterval = 10 * time.Second
+
+// Job is a task which runs periodically in the background.
+type Job struct {
+	// Name identifies the job across restarts, so it must not change.
+	Name     string
+	Interval time.Duration
+	// PerTenant jobs run for the default tenant and then for every tenant, with a context scoped to each.
+	PerTenant bool
+	// PerInstance jobs run on every instance of the service, e.g. to refresh in-memory caches. They take no lease, and
+	// their schedule is not stored.
+	PerInstance bool
+	Run         func(ctx context.Context) error
+}
+
+// StoredJob records the last run of a job, and when it is next due.
+type StoredJob struct {
+	Name      string    `json:"name"`
+	LastRun   time.Time `json:"lastRun"`
+	NextRun   time.Time `json:"nextRun"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+type lease struct {
+	Holder string    `json:"holder"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// TenantsFunc returns the tenants the PerTenant jobs run for, besides the default tenant.
+type TenantsFunc func(ctx context.Context) ([]tenant.Tenant, error)
+
+// Scheduler runs jobs at their interval, until it is closed.
+type Scheduler struct {
+	db            storage.ServiceStorage
+	leaseDuration time.Duration
+	tenants       TenantsFunc
+	jobs          []Job
+	instanceID    string
+
+	// nextRuns holds when each of the PerInstance jobs is next due, by name.
+	nextRuns map[string]time.Time
+
+	mu   sync.Mutex
+	stop func()
+}
+
+// NewScheduler creates a scheduler of the given jobs, which stores their schedule and leases in db. The tenants the
+// PerTenant jobs run for are listed by tenants when it is not nil.
+func NewScheduler(db storage.ServiceStorage, leaseDuration time.Duration, tenants TenantsFunc, jobs ...Job) (*Scheduler, error) {
+	if db == nil {
+		return nil, errors.New("scheduler storage is nil")
+	}
+	if leaseDuration <= 0 {
+		return nil, errors.New("scheduler lease duration must be positive")
+	}
+	names := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		if job.Name == "" {
+			return nil, errors.New("job name is required")
+		}
+		if names[job.Name] {
+			return nil, errors.Errorf("job<%s> already exists", job.Name)
+		}
+		names[job.Name] = true
+		if job.Interval <= 0 {
+			return nil, errors.Errorf("interval of job<%s> must be positive", job.Name)
+		}
+		if job.Run == nil {
+			return nil, errors.Errorf("job<%s> has nothing to run", job.Name)
+		}
+	}
+	return &Scheduler{
+		db:            db,
+		leaseDuration: leaseDuration,
+		tenants:       tenants,
+		jobs:          jobs,
+		instanceID:    uuid.NewString(),
+		nextRuns:      make(map[string]time.Time),
+	}, nil
+}
+
+// Start runs the jobs which are due in the background, every TickInterval, until the scheduler is closed.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(TickInterval)
+		defer ticker.Stop()
+		for {
+			if s.db.IsOpen() {
+				s.runDue(ctx, time.Now())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	s.stop = func() {
+		cancel()
+		<-done
+	}
+}
+
+// Close stops running jobs, waiting for the one running to return. The storage is not closed.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	stop := s.stop
+	s.stop = nil
+	s.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// runDue runs every job which is due at now, one after the other, and returns the names of those which ran on this
+// instance.
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) []string {
+	var ran []string
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		logger := logrus.WithField("job", job.Name)
+		if job.PerInstance {
+			if now.Before(s.nextRuns[job.Name]) {
+				continue
+			}
+			s.nextRuns[job.Name] = now.Add(job.Interval)
+			if err := s.run(ctx, job); err != nil {
+				logger.WithError(err).Error("running scheduled job")
+			}
+			ran = append(ran, job.Name)
+			continue
+		}
+
+		acquired, err := s.acquire(ctx, job, now)
+		if err != nil {
+			logger.WithError(err).Error("acquiring lease on scheduled job")
+			continue
+		}
+		if !acquired {
+			continue
+		}
+		runErr := s.runLeased(ctx, job, now)
+		if runErr != nil {
+			logger.WithError(runErr).Error("running scheduled job")
+		}
+		if err = s.release(ctx, job, now, runErr); err != nil {
+			logger.WithError(err).Error("releasing lease on scheduled job")
+		}
+		ran = append(ran, job.Name)
+	}
+	return ran
+}
+
+// runLeased runs the job whose lease was acquired at now, renewing the lease until the job returns. The context of the
+// job is canceled when the lease cannot be renewed, e.g. because another instance took it over, so that the job does
+// not keep running on two instances at once.
+func (s *Scheduler) runLeased(ctx context.Context, job Job, now time.Time) error {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		start := time.Now()
+		ticker := time.NewTicker(s.leaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if err := s.renew(ctx, job, now.Add(time.Since(start))); err != nil {
+				logrus.WithField("job", job.Name).WithError(err).Error("renewing lease on scheduled job, stopping it")
+				cancel()
+				return
+			}
+		}
+	}()
+	err := s.run(jobCtx, job)
+	close(done)
+	<-renewed
+	return err
+}
+
+// run runs the job, once for each tenant when it is a PerTenant job. The job still runs for the other tenants when it
+// fails for one.
+func (s *Scheduler) run(ctx context.Context, job Job) error {
+	if !job.PerTenant {
+		return job.Run(ctx)
+	}
+	ctx = storage.WithTenant(ctx, "")
+	ae := sdkutil.NewAppendError()
+	if err := job.Run(ctx); err != nil {
+		ae.Append(errors.Wrap(err, "running for the default tenant"))
+	}
+	if s.tenants == nil {
+		return ae.Error()
+	}
+	tenants, err := s.tenants(ctx)
+	if err != nil {
+		ae.Append(errors.Wrap(err, "getting tenants"))
+		return ae.Error()
+	}
+	for _, t := range tenants {
+		if err = job.Run(tenant.NewContext(storage.WithTenant(ctx, t.ID), t)); err != nil {
+			ae.Append(errors.Wrapf(err, "running for tenant<%s>", t.ID))
+		}
+	}
+	return ae.Error()
+}
+
+// acquire takes the lease on the job when it is due at now and no other instance holds an unexpired lease on it, and
+// returns whether it did.
+func (s *Scheduler) acquire(ctx context.Context, job Job, now time.Time) (bool, error) {
+	acquired, err := s.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		jobBytes, err := tx.Read(ctx, jobNamespace, job.Name)
+		if err != nil {
+			return false, errors.Wrap(err, "reading job")
+		}
+		if jobBytes != nil {
+			var stored StoredJob
+			if err = json.Unmarshal(jobBytes, &stored); err != nil {
+				return false, errors.Wrap(err, "unmarshalling job")
+			}
+			if now.Before(stored.NextRun) {
+				return false, nil
+			}
+		}
+		held, err := readLease(ctx, tx, job.Name)
+		if err != nil {
+			return false, err
+		}
+		if held != nil && held.Holder != s.instanceID && now.Before(held.Expiry) {
+			return false, nil
+		}
+		leaseBytes, err := json.Marshal(lease{Holder: s.instanceID, Expiry: now.Add(s.leaseDuration)})
+		if err != nil {
+			return false, errors.Wrap(err, "marshalling lease")
+		}
+		if err = tx.WriteWithTTL(ctx, leaseNamespace, job.Name, leaseBytes, s.leaseDuration); err != nil {
+			return false, errors.Wrap(err, "writing lease")
+		}
+		return true, nil
+	}, watchKeys(job.Name))
+	if err != nil {
+		return false, err
+	}
+	return acquired.(bool), nil
+}
+
+// renew extends the lease this instance holds on the job to the lease duration from now. It fails when the lease is no
+// longer held by this instance, e.g. because it expired and was taken over by another instance.
+func (s *Scheduler) renew(ctx context.Context, job Job, now time.Time) error {
+	_, err := s.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		held, err := readLease(ctx, tx, job.Name)
+		if err != nil {
+			return nil, err
+		}
+		if held == nil || held.Holder != s.instanceID {
+			return nil, errors.Errorf("lease on job<%s> is no longer held by this instance", job.Name)
+		}
+		leaseBytes, err := json.Marshal(lease{Holder: s.instanceID, Expiry: now.Add(s.leaseDuration)})
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling lease")
+		}
+		if err = tx.WriteWithTTL(ctx, leaseNamespace, job.Name, leaseBytes, s.leaseDuration); err != nil {
+			return nil, errors.Wrap(err, "writing lease")
+		}
+		return nil, nil
+	}, watchKeys(job.Name))
+	return err
+}
+
+// release records the run of the job which started at start, and gives up the lease on it. Nothing is recorded when
+// another instance took the lease over in the meantime, since that instance records its own run, nor when it already
+// did.
+func (s *Scheduler) release(ctx context.Context, job Job, start time.Time, runErr error) error {
+	stored := StoredJob{Name: job.Name, LastRun: start, NextRun: start.Add(job.Interval)}
+	if runErr != nil {
+		stored.LastError = runErr.Error()
+	}
+	jobBytes, err := json.Marshal(stored)
+	if err != nil {
+		return errors.Wrap(err, "marshalling job")
+	}
+	_, err = s.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		held, err := readLease(ctx, tx, job.Name)
+		if err != nil {
+			return nil, err
+		}
+		if held != nil {
+			if held.Holder != s.instanceID {
+				return nil, errors.Errorf("lease on job<%s> was taken over by another instance", job.Name)
+			}
+			if err = tx.Delete(ctx, leaseNamespace, job.Name); err != nil {
+				return nil, errors.Wrap(err, "deleting lease")
+			}
+		}
+		previousBytes, err := tx.Read(ctx, jobNamespace, job.Name)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading job")
+		}
+		if previousBytes != nil {
+			var previous StoredJob
+			if err = json.Unmarshal(previousBytes, &previous); err != nil {
+				return nil, errors.Wrap(err, "unmarshalling job")
+			}
+			if previous.LastRun.After(start) {
+				return nil, errors.Errorf("job<%s> ran again on another instance", job.Name)
+			}
+		}
+		if err = tx.Write(ctx, jobNamespace, job.Name, jobBytes); err != nil {
+			return nil, errors.Wrap(err, "writing job")
+		}
+		return nil, nil
+	}, watchKeys(job.Name))
+	return err
+}
+
+func readLease(ctx context.Context, tx storage.Tx, name string) (*lease, error) {
+	leaseBytes, err := tx.Read(ctx, leaseNamespace, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading lease")
+	}
+	if leaseBytes == nil {
+		return nil, nil
+	}
+	var held lease
+	if err = json.Unmarshal(leaseBytes, &held); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling lease")
+	}
+	return &held, nil
+}
+
+func watchKeys(name string) []storage.WatchKey {
+	return []storage.WatchKey{
+		{Namespace: jobNamespace, Key: name},
+		{Namespace: leaseNamespace, Key: name},
+	}
+}
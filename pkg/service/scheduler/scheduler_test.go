@@ -0,0 +1,244 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+func setupTestDB(t *testing.T) storage.ServiceStorage {
+	s, err := storage.NewStorage(storage.Memory, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func readStoredJob(t *testing.T, db storage.ServiceStorage, name string) StoredJob {
+	jobBytes, err := db.Read(context.Background(), jobNamespace, name)
+	require.NoError(t, err)
+	require.NotEmpty(t, jobBytes)
+	var stored StoredJob
+	require.NoError(t, json.Unmarshal(jobBytes, &stored))
+	return stored
+}
+
+func TestNewScheduler(t *testing.T) {
+	db := setupTestDB(t)
+	run := func(context.Context) error { return nil }
+
+	_, err := NewScheduler(nil, time.Minute, nil)
+	assert.Error(t, err)
+	_, err = NewScheduler(db, 0, nil)
+	assert.Error(t, err)
+	_, err = NewScheduler(db, time.Minute, nil, Job{Interval: time.Minute, Run: run})
+	assert.ErrorContains(t, err, "job name is required")
+	_, err = NewScheduler(db, time.Minute, nil, Job{Name: "job", Run: run})
+	assert.ErrorContains(t, err, "must be positive")
+	_, err = NewScheduler(db, time.Minute, nil, Job{Name: "job", Interval: time.Minute})
+	assert.ErrorContains(t, err, "has nothing to run")
+	_, err = NewScheduler(db, time.Minute, nil,
+		Job{Name: "job", Interval: time.Minute, Run: run},
+		Job{Name: "job", Interval: time.Hour, Run: run})
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("runs jobs at their interval", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		runs := 0
+		s, err := NewScheduler(db, time.Minute, nil, Job{Name: "job", Interval: time.Hour, Run: func(context.Context) error {
+			runs++
+			return nil
+		}})
+		require.NoError(tt, err)
+
+		ctx := context.Background()
+		now := time.Now()
+		assert.Equal(tt, []string{"job"}, s.runDue(ctx, now))
+		assert.Empty(tt, s.runDue(ctx, now.Add(30*time.Minute)))
+		assert.Equal(tt, []string{"job"}, s.runDue(ctx, now.Add(time.Hour)))
+		assert.Equal(tt, 2, runs)
+
+		stored := readStoredJob(tt, db, "job")
+		assert.True(tt, stored.LastRun.Equal(now.Add(time.Hour)))
+		assert.True(tt, stored.NextRun.Equal(now.Add(2*time.Hour)))
+		assert.Empty(tt, stored.LastError)
+
+		// the lease is given up once the job has run
+		exists, err := db.Exists(ctx, leaseNamespace, "job")
+		require.NoError(tt, err)
+		assert.False(tt, exists)
+	})
+
+	t.Run("records failures", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		s, err := NewScheduler(db, time.Minute, nil, Job{Name: "job", Interval: time.Hour, Run: func(context.Context) error {
+			return errors.New("boom")
+		}})
+		require.NoError(tt, err)
+
+		now := time.Now()
+		assert.Equal(tt, []string{"job"}, s.runDue(context.Background(), now))
+		stored := readStoredJob(tt, db, "job")
+		assert.Contains(tt, stored.LastError, "boom")
+		assert.True(tt, stored.NextRun.Equal(now.Add(time.Hour)))
+	})
+
+	t.Run("runs a job on a single instance at a time", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		job := Job{Name: "job", Interval: time.Hour, Run: func(context.Context) error { return nil }}
+		first, err := NewScheduler(db, 10*time.Minute, nil, job)
+		require.NoError(tt, err)
+		second, err := NewScheduler(db, 10*time.Minute, nil, job)
+		require.NoError(tt, err)
+
+		// the first instance holds the lease, e.g. because it is still running the job
+		ctx := context.Background()
+		now := time.Now()
+		acquired, err := first.acquire(ctx, job, now)
+		require.NoError(tt, err)
+		require.True(tt, acquired)
+		assert.Empty(tt, second.runDue(ctx, now.Add(time.Minute)))
+
+		// once the lease expires, e.g. because the first instance stopped, the job is taken over
+		assert.Equal(tt, []string{"job"}, second.runDue(ctx, now.Add(10*time.Minute)))
+		assert.Empty(tt, first.runDue(ctx, now.Add(11*time.Minute)))
+
+		// the first instance does not record its run over the one of the second
+		assert.Error(tt, first.release(ctx, job, now, nil))
+		stored := readStoredJob(tt, db, "job")
+		assert.True(tt, stored.LastRun.Equal(now.Add(10*time.Minute)))
+	})
+
+	t.Run("renews the lease while the job runs", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		leaseDuration := 300 * time.Millisecond
+		var second *Scheduler
+		job := Job{Name: "job", Interval: time.Hour, Run: func(ctx context.Context) error {
+			// the job outlasts its lease, which is renewed, so that it is not taken over
+			time.Sleep(2 * leaseDuration)
+			acquired, err := second.acquire(context.Background(), Job{Name: "job", Interval: time.Hour}, time.Now())
+			if err != nil {
+				return err
+			}
+			if acquired {
+				return errors.New("lease was taken over")
+			}
+			return ctx.Err()
+		}}
+		first, err := NewScheduler(db, leaseDuration, nil, job)
+		require.NoError(tt, err)
+		second, err = NewScheduler(db, leaseDuration, nil, job)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, []string{"job"}, first.runDue(context.Background(), time.Now()))
+		assert.Empty(tt, readStoredJob(tt, db, "job").LastError)
+	})
+
+	t.Run("stops the job when its lease cannot be renewed", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		leaseDuration := 300 * time.Millisecond
+		var runErr error
+		job := Job{Name: "job", Interval: time.Hour, Run: func(ctx context.Context) error {
+			// another instance takes the lease over, e.g. because this one was paused past its expiry
+			leaseBytes, err := json.Marshal(lease{Holder: "other", Expiry: time.Now().Add(time.Hour)})
+			if err != nil {
+				return err
+			}
+			if err = db.Write(ctx, leaseNamespace, "job", leaseBytes); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				runErr = ctx.Err()
+			case <-time.After(5 * time.Second):
+				runErr = errors.New("job was not stopped")
+			}
+			return runErr
+		}}
+		s, err := NewScheduler(db, leaseDuration, nil, job)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, []string{"job"}, s.runDue(context.Background(), time.Now()))
+		assert.ErrorIs(tt, runErr, context.Canceled)
+		// the run is not recorded, since the lease was taken over
+		exists, err := db.Exists(context.Background(), jobNamespace, "job")
+		require.NoError(tt, err)
+		assert.False(tt, exists)
+	})
+
+	t.Run("runs per tenant jobs for every tenant", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		tenants := func(context.Context) ([]tenant.Tenant, error) {
+			return []tenant.Tenant{{ID: "acme"}, {ID: "globex", Config: tenant.Config{ServiceEndpoint: "https://globex.example"}}}, nil
+		}
+		var ranFor, endpoints []string
+		s, err := NewScheduler(db, time.Minute, tenants, Job{Name: "job", Interval: time.Hour, PerTenant: true, Run: func(ctx context.Context) error {
+			ranFor = append(ranFor, storage.TenantFromContext(ctx))
+			endpoints = append(endpoints, tenant.ServiceEndpoint(ctx, "default"))
+			if storage.TenantFromContext(ctx) == "acme" {
+				return errors.New("boom")
+			}
+			return nil
+		}})
+		require.NoError(tt, err)
+
+		s.runDue(context.Background(), time.Now())
+		assert.Equal(tt, []string{"", "acme", "globex"}, ranFor)
+		assert.Equal(tt, []string{"default", "default", "https://globex.example"}, endpoints)
+		stored := readStoredJob(tt, db, "job")
+		assert.Contains(tt, stored.LastError, "tenant<acme>")
+	})
+
+	t.Run("runs per instance jobs on every instance", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		runs := 0
+		job := Job{Name: "job", Interval: time.Hour, PerInstance: true, Run: func(context.Context) error {
+			runs++
+			return nil
+		}}
+		first, err := NewScheduler(db, time.Minute, nil, job)
+		require.NoError(tt, err)
+		second, err := NewScheduler(db, time.Minute, nil, job)
+		require.NoError(tt, err)
+
+		ctx := context.Background()
+		now := time.Now()
+		assert.Equal(tt, []string{"job"}, first.runDue(ctx, now))
+		assert.Equal(tt, []string{"job"}, second.runDue(ctx, now))
+		assert.Empty(tt, first.runDue(ctx, now.Add(time.Minute)))
+		assert.Equal(tt, 2, runs)
+
+		exists, err := db.Exists(ctx, jobNamespace, "job")
+		require.NoError(tt, err)
+		assert.False(tt, exists)
+	})
+
+	t.Run("runs in the background until closed", func(tt *testing.T) {
+		db := setupTestDB(tt)
+		ran := make(chan struct{}, 1)
+		s, err := NewScheduler(db, time.Minute, nil, Job{Name: "job", Interval: time.Hour, Run: func(context.Context) error {
+			ran <- struct{}{}
+			return nil
+		}})
+		require.NoError(tt, err)
+
+		s.Start()
+		select {
+		case <-ran:
+		case <-time.After(5 * time.Second):
+			tt.Fatal("job did not run")
+		}
+		s.Close()
+	})
+}
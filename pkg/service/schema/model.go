@@ -5,6 +5,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/util"
 
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 )
 
 const (
@@ -40,8 +41,13 @@ type VerifySchemaResponse struct {
 	Reason   string `json:"reason,omitempty"`
 }
 
+type GetSchemasRequest struct {
+	PageRequest framework.PageRequest
+}
+
 type GetSchemasResponse struct {
-	Schemas []GetSchemaResponse `json:"schemas,omitempty"`
+	Schemas       []GetSchemaResponse `json:"schemas,omitempty"`
+	NextPageToken string              `json:"nextPageToken,omitempty"`
 }
 
 type GetSchemaRequest struct {
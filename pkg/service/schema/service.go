@@ -230,11 +230,11 @@ func (s Service) verifySchemaJWT(ctx context.Context, token keyaccess.JWT) (*sch
 	return &parsedSchema, nil
 }
 
-func (s Service) GetSchemas(ctx context.Context) (*GetSchemasResponse, error) {
+func (s Service) GetSchemas(ctx context.Context, request GetSchemasRequest) (*GetSchemasResponse, error) {
 
 	logrus.Debug("getting all schema")
 
-	storedSchemas, err := s.storage.GetSchemas(ctx)
+	storedSchemas, nextPageToken, err := s.storage.GetSchemas(ctx, request.PageRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "error getting schemas")
 	}
@@ -247,7 +247,7 @@ func (s Service) GetSchemas(ctx context.Context) (*GetSchemasResponse, error) {
 		})
 	}
 
-	return &GetSchemasResponse{Schemas: schemas}, nil
+	return &GetSchemasResponse{Schemas: schemas, NextPageToken: nextPageToken}, nil
 }
 
 func (s Service) GetSchema(ctx context.Context, request GetSchemaRequest) (*GetSchemaResponse, error) {
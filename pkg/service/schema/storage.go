@@ -9,6 +9,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
@@ -24,6 +26,19 @@ type StoredSchema struct {
 	SchemaJWT *keyaccess.JWT      `json:"token,omitempty"`
 }
 
+func init() {
+	webhook.RegisterResource(namespace, webhook.Resource{Noun: webhook.Schema, Data: schemaWebhookData})
+}
+
+// schemaWebhookData returns a stored schema in the shape it is returned by the API with.
+func schemaWebhookData(value []byte) (any, error) {
+	var stored StoredSchema
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling stored schema")
+	}
+	return GetSchemaResponse{ID: stored.ID, Schema: stored.Schema, SchemaJWT: stored.SchemaJWT}, nil
+}
+
 type Storage struct {
 	db storage.ServiceStorage
 }
@@ -72,17 +87,17 @@ func (ss *Storage) GetSchema(ctx context.Context, id string) (*StoredSchema, err
 	return &stored, nil
 }
 
-// GetSchemas attempts to get all stored schemas. It will return those it can even if it has trouble with some.
-func (ss *Storage) GetSchemas(ctx context.Context) ([]StoredSchema, error) {
-	gotSchemas, err := ss.db.ReadAll(ctx, namespace)
+// GetSchemas attempts to get a page of stored schemas. It will return those it can even if it has trouble with some.
+func (ss *Storage) GetSchemas(ctx context.Context, page framework.PageRequest) ([]StoredSchema, string, error) {
+	gotSchemas, nextPageToken, err := ss.db.ReadPage(ctx, namespace, page.PageToken, page.PageSize)
 	if err != nil {
 		errMsg := "could not get all schemas"
 		logrus.WithError(err).Error(errMsg)
-		return nil, errors.Wrap(err, errMsg)
+		return nil, "", errors.Wrap(err, errMsg)
 	}
 	if len(gotSchemas) == 0 {
 		logrus.Info("no schemas to get")
-		return nil, nil
+		return nil, nextPageToken, nil
 	}
 	var stored []StoredSchema
 	for _, schemaBytes := range gotSchemas {
@@ -91,7 +106,7 @@ func (ss *Storage) GetSchemas(ctx context.Context) ([]StoredSchema, error) {
 			stored = append(stored, nextSchema)
 		}
 	}
-	return stored, nil
+	return stored, nextPageToken, nil
 }
 
 func (ss *Storage) DeleteSchema(ctx context.Context, id string) error {
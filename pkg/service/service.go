@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/sirupsen/logrus"
 
 	"github.com/tbd54566975/ssi-service/config"
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
@@ -14,14 +18,31 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
+	"github.com/tbd54566975/ssi-service/pkg/service/scheduler"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
 	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
+// defaultOutboxRetention is how long changes are kept in the outbox when no retention is configured.
+const defaultOutboxRetention = 7 * 24 * time.Hour
+
+// Defaults of the scheduler config, used when a value is not configured.
+const (
+	defaultSchedulerLeaseDuration       = 10 * time.Minute
+	defaultCredentialExpirationInterval = time.Hour
+	defaultSuspensionInterval           = time.Minute
+	defaultOperationPurgeInterval       = time.Hour
+	defaultOperationRetention           = 30 * 24 * time.Hour
+	defaultCacheRefreshInterval         = time.Minute
+)
+
 // SSIService represents all services and their dependencies independent of transport
 type SSIService struct {
-	services []framework.Service
+	services  []framework.Service
+	storages  *serviceStorages
+	scheduler *scheduler.Scheduler
 }
 
 // InstantiateSSIService creates a new instance of the SSIS which instantiates all services and their
@@ -30,16 +51,29 @@ func InstantiateSSIService(config config.ServicesConfig) (*SSIService, error) {
 	if err := validateServiceConfig(config); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate SSI Service, invalid config")
 	}
-	services, err := instantiateServices(config)
+	storages, err := instantiateServiceStorages(config)
 	if err != nil {
+		return nil, err
+	}
+	services, jobScheduler, err := instantiateServices(config, storages)
+	if err != nil {
+		_ = storages.Close()
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not instantiate the ssi service")
 	}
-	return &SSIService{services: services}, nil
+	return &SSIService{services: services, storages: storages, scheduler: jobScheduler}, nil
+}
+
+// Close stops running scheduled jobs and delivering changes, and closes the storage providers of all services.
+func (ssi *SSIService) Close() error {
+	if ssi.scheduler != nil {
+		ssi.scheduler.Close()
+	}
+	return ssi.storages.Close()
 }
 
 func validateServiceConfig(config config.ServicesConfig) error {
-	if !storage.IsStorageAvailable(storage.Type(config.StorageProvider)) {
-		return fmt.Errorf("%s storage provider configured, but not available", config.StorageProvider)
+	if err := validateStorageConfig(config); err != nil {
+		return err
 	}
 	if config.KeyStoreConfig.IsEmpty() {
 		return fmt.Errorf("%s no config provided", framework.KeyStore)
@@ -68,6 +102,23 @@ func validateServiceConfig(config config.ServicesConfig) error {
 	return nil
 }
 
+// validateStorageConfig validates the parts of the config needed to instantiate storage, which is all the storage
+// commands use.
+func validateStorageConfig(config config.ServicesConfig) error {
+	if !storage.IsStorageAvailable(storage.Type(config.StorageProvider)) {
+		return fmt.Errorf("%s storage provider configured, but not available", config.StorageProvider)
+	}
+	if err := validateServiceStorageConfigs(config); err != nil {
+		return err
+	}
+	if !config.EncryptionConfig.IsEmpty() {
+		if config.EncryptionConfig.Passwords[config.EncryptionConfig.CurrentPasswordID] == "" {
+			return fmt.Errorf("no password configured for current encryption password id<%s>", config.EncryptionConfig.CurrentPasswordID)
+		}
+	}
+	return nil
+}
+
 // GetServices returns the instantiated service providers
 func (ssi *SSIService) GetServices() []framework.Service {
 	return ssi.services
@@ -83,59 +134,310 @@ func (ssi *SSIService) GetService(serviceType framework.Type) framework.Service
 	return nil
 }
 
-// instantiateServices begins all instantiates and their dependencies
-func instantiateServices(config config.ServicesConfig) ([]framework.Service, error) {
-	storageProvider, err := storage.NewStorage(storage.Type(config.StorageProvider), config.StorageOption)
+// instantiateServices begins all instantiates and their dependencies, and the scheduler of their background jobs,
+// which is nil when disabled.
+func instantiateServices(config config.ServicesConfig, storages *serviceStorages) ([]framework.Service, *scheduler.Scheduler, error) {
+	if _, err := storages.migrate(context.Background(), false); err != nil {
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not migrate storage")
+	}
+
+	retention := config.OutboxConfig.Retention
+	if retention == 0 {
+		retention = defaultOutboxRetention
+	}
+	if err := storages.wrap(retention); err != nil {
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the storage outbox")
+	}
+
+	// tenants are kept in the default storage, and their data in that of every service
+	tenantService, err := tenant.NewTenantService(storages.defaultStorage().tenants, storages.allTenants()...)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not instantiate storage provider: %s", config.StorageProvider)
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the tenant service")
 	}
 
-	webhookService, err := webhook.NewWebhookService(config.WebhookConfig, storageProvider)
+	webhookService, err := webhook.NewWebhookService(config.WebhookConfig, storages.get(framework.Webhook))
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the webhook service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the webhook service")
+	}
+	if err = storages.subscribe(string(framework.Webhook), webhookService.HandleChange); err != nil {
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not subscribe the webhook service to the storage outbox")
 	}
 
-	keyStoreService, err := keystore.NewKeyStoreService(config.KeyStoreConfig, storageProvider)
+	keyStoreService, err := keystore.NewKeyStoreService(config.KeyStoreConfig, storages.get(framework.KeyStore))
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate keystore service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate keystore service")
 	}
 
-	didService, err := did.NewDIDService(config.DIDConfig, storageProvider, keyStoreService)
+	didService, err := did.NewDIDService(config.DIDConfig, storages.get(framework.DID), keyStoreService)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the DID service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the DID service")
 	}
 	didResolver := didService.GetResolver()
 
-	schemaService, err := schema.NewSchemaService(config.SchemaConfig, storageProvider, keyStoreService, didResolver)
+	schemaService, err := schema.NewSchemaService(config.SchemaConfig, storages.get(framework.Schema), keyStoreService, didResolver)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the schema service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the schema service")
 	}
 
-	issuingService, err := issuing.NewIssuingService(config.IssuingServiceConfig, storageProvider)
+	issuingService, err := issuing.NewIssuingService(config.IssuingServiceConfig, storages.get(framework.Issuing),
+		storages.get(framework.Manifest), storages.get(framework.Schema))
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the issuing service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the issuing service")
 	}
 
-	credentialService, err := credential.NewCredentialService(config.CredentialConfig, storageProvider, keyStoreService, didResolver, schemaService)
+	credentialService, err := credential.NewCredentialService(config.CredentialConfig, storages.get(framework.Credential), keyStoreService, didResolver, schemaService)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the credential service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the credential service")
 	}
 
-	manifestService, err := manifest.NewManifestService(config.ManifestConfig, storageProvider, keyStoreService, didResolver, credentialService)
+	manifestService, err := manifest.NewManifestService(config.ManifestConfig, storages.get(framework.Manifest),
+		storages.get(framework.Issuing), keyStoreService, didResolver, credentialService)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the manifest service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the manifest service")
 	}
 
-	presentationService, err := presentation.NewPresentationService(config.PresentationConfig, storageProvider, didResolver, schemaService, keyStoreService)
+	presentationService, err := presentation.NewPresentationService(config.PresentationConfig, storages.get(framework.Presentation), didResolver, schemaService, keyStoreService, credentialService.StatusListResolver(), credentialService)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the presentation service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the presentation service")
 	}
 
-	operationService, err := operation.NewOperationService(storageProvider)
+	// operations are stored along with the submissions, applications and credentials they track
+	operationService, err := operation.NewOperationService(storages.get(framework.Presentation), storages.get(framework.Manifest), storages.get(framework.Credential))
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the operation service")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the operation service")
+	}
+
+	var jobScheduler *scheduler.Scheduler
+	if !config.SchedulerConfig.Disabled {
+		jobScheduler, err = instantiateScheduler(config.SchedulerConfig, storages, tenantService, didService, credentialService, operationService)
+		if err != nil {
+			return nil, nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the scheduler")
+		}
+	}
+
+	storages.start()
+	if jobScheduler != nil {
+		jobScheduler.Start()
 	}
 
 	return []framework.Service{keyStoreService, didService, schemaService, issuingService, credentialService,
-		manifestService, presentationService, operationService, webhookService}, nil
+		manifestService, presentationService, operationService, webhookService, tenantService}, jobScheduler, nil
+}
+
+// instantiateScheduler schedules the background jobs of the services. The schedule is kept in the default storage,
+// without going through its outbox, since the changes to it are of no interest to subscribers.
+func instantiateScheduler(config config.SchedulerConfig, storages *serviceStorages, tenantService *tenant.Service,
+	didService *did.Service, credentialService *credential.Service, operationService *operation.Service) (*scheduler.Scheduler, error) {
+	operationRetention := durationOrDefault(config.OperationRetention, defaultOperationRetention)
+	jobs := []scheduler.Job{
+		{
+			Name:      "expire-credentials",
+			Interval:  durationOrDefault(config.CredentialExpirationInterval, defaultCredentialExpirationInterval),
+			PerTenant: true,
+			Run: func(ctx context.Context) error {
+				expired, err := credentialService.ExpireCredentials(ctx)
+				logJobResult(ctx, "marked %d credentials expired", expired)
+				return err
+			},
+		},
+		{
+			Name:      "lift-suspensions",
+			Interval:  durationOrDefault(config.SuspensionInterval, defaultSuspensionInterval),
+			PerTenant: true,
+			Run: func(ctx context.Context) error {
+				lifted, err := credentialService.LiftSuspensions(ctx)
+				logJobResult(ctx, "lifted %d credential suspensions", lifted)
+				return err
+			},
+		},
+		{
+			Name:      "purge-operations",
+			Interval:  durationOrDefault(config.OperationPurgeInterval, defaultOperationPurgeInterval),
+			PerTenant: true,
+			Run: func(ctx context.Context) error {
+				purged, err := operationService.PurgeOperations(ctx, operationRetention)
+				logJobResult(ctx, "purged %d operations", purged)
+				return err
+			},
+		},
+		{
+			Name:        "refresh-caches",
+			Interval:    durationOrDefault(config.CacheRefreshInterval, defaultCacheRefreshInterval),
+			PerInstance: true,
+			Run: func(ctx context.Context) error {
+				ae := sdkutil.NewAppendError()
+				if err := credentialService.RefreshStatusLists(ctx); err != nil {
+					ae.Append(err)
+				}
+				if err := didService.RefreshResolutionCache(ctx); err != nil {
+					ae.Append(err)
+				}
+				return ae.Error()
+			},
+		},
+	}
+	tenants := func(ctx context.Context) ([]tenant.Tenant, error) {
+		resp, err := tenantService.GetTenants(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Tenants, nil
+	}
+	leaseDuration := durationOrDefault(config.LeaseDuration, defaultSchedulerLeaseDuration)
+	return scheduler.NewScheduler(storages.defaultStorage().db, leaseDuration, tenants, jobs...)
+}
+
+func durationOrDefault(d, defaultDuration time.Duration) time.Duration {
+	if d == 0 {
+		return defaultDuration
+	}
+	return d
+}
+
+// logJobResult logs what a scheduled job did, when it did anything.
+func logJobResult(ctx context.Context, format string, n int) {
+	if n > 0 {
+		logrus.WithField("tenant", storage.TenantFromContext(ctx)).Infof(format, n)
+	}
+}
+
+// MigrateStorage runs the registered migrations against the storage providers of all services, without starting any
+// of the services. In a dry run nothing is written, and the results describe what would be migrated.
+func MigrateStorage(ctx context.Context, config config.ServicesConfig, dryRun bool) ([]storage.MigrationResult, error) {
+	if err := validateStorageConfig(config); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid config")
+	}
+	storages, err := instantiateServiceStorages(config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = storages.Close()
+	}()
+	results, err := storages.migrate(ctx, dryRun)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not migrate storage")
+	}
+	return results, nil
+}
+
+// EncryptExistingValues encrypts the values of the encrypted namespaces of the storage providers of all services which
+// were written before their namespace was encrypted, and returns how many there were. It must be run once a namespace
+// is added to the encrypted ones, since its existing values are not read until they are encrypted. In a dry run nothing
+// is written.
+func EncryptExistingValues(ctx context.Context, config config.ServicesConfig, dryRun bool) (int, error) {
+	storages, err := instantiateEncryptedServiceStorages(config)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = storages.Close()
+	}()
+	encrypted := 0
+	for _, st := range storages.providers {
+		n, err := st.db.(*storage.EncryptedStorage).EncryptExistingValues(ctx, dryRun)
+		if err != nil {
+			return 0, sdkutil.LoggingErrorMsgf(err, "could not encrypt existing values of %s storage", st.provider)
+		}
+		encrypted += n
+	}
+	return encrypted, nil
+}
+
+// RotateDataKeys creates new data keys for the given encrypted namespaces, or for all of them when none are given, in
+// the storage providers of all services. Values are written with the new data keys from then on, and those written
+// before remain readable. Running instances of the service keep writing with the previous data keys until they are
+// restarted.
+func RotateDataKeys(ctx context.Context, config config.ServicesConfig, namespaces ...string) error {
+	storages, err := instantiateEncryptedServiceStorages(config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = storages.Close()
+	}()
+	if len(namespaces) == 0 {
+		namespaces = append([]string{storage.OutboxNamespace}, config.EncryptionConfig.Namespaces...)
+	}
+	for _, st := range storages.providers {
+		for _, namespace := range namespaces {
+			if err = st.db.(*storage.EncryptedStorage).RotateDataKey(ctx, namespace); err != nil {
+				return sdkutil.LoggingErrorMsgf(err, "could not rotate data key of namespace<%s> in %s storage", namespace, st.provider)
+			}
+		}
+	}
+	return nil
+}
+
+// instantiateEncryptedServiceStorages creates the storage providers of all services, which must be encrypted.
+func instantiateEncryptedServiceStorages(config config.ServicesConfig) (*serviceStorages, error) {
+	if err := validateStorageConfig(config); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid config")
+	}
+	if config.EncryptionConfig.IsEmpty() {
+		return nil, sdkutil.LoggingNewError("no namespaces are configured to be encrypted")
+	}
+	return instantiateServiceStorages(config)
+}
+
+// ExportStorage writes every namespace of the storage of the given service to an archive, without starting any of the
+// services. The storage configured in the services block is exported when no service is given. Values of encrypted
+// namespaces are written decrypted, except for the keys of the keystore, which stay encrypted with the service key.
+func ExportStorage(ctx context.Context, config config.ServicesConfig, serviceType framework.Type, w io.Writer) (*storage.ArchiveManifest, error) {
+	storageProvider, err := instantiateArchiveStorage(config, serviceType)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = storageProvider.Close()
+	}()
+	manifest, err := storage.ExportArchive(ctx, storageProvider, w)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not export storage")
+	}
+	return manifest, nil
+}
+
+// ImportStorage loads an archive written by ExportStorage into the storage of the given service, without starting any
+// of the services. When the storage already has a service key, the keys of the keystore are re-encrypted with it.
+func ImportStorage(ctx context.Context, config config.ServicesConfig, serviceType framework.Type, r io.ReadSeeker) (*storage.ArchiveManifest, error) {
+	storageProvider, err := instantiateArchiveStorage(config, serviceType)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = storageProvider.Close()
+	}()
+	transform, err := keystore.ArchiveTransform(ctx, storageProvider, r)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not prepare keystore import")
+	}
+	manifest, err := storage.ImportArchive(ctx, storageProvider, r, transform)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not import storage")
+	}
+	return manifest, nil
+}
+
+// instantiateArchiveStorage creates the storage provider of the given service, or the one configured in the services
+// block when no service is given. Archives hold the namespaces of a single provider, since those of two providers may
+// hold the same keys.
+func instantiateArchiveStorage(config config.ServicesConfig, serviceType framework.Type) (storage.ServiceStorage, error) {
+	if err := validateStorageConfig(config); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid config")
+	}
+	provider, option := config.StorageProvider, config.StorageOption
+	if serviceType != "" {
+		found := false
+		for _, c := range serviceStorageConfigs(config) {
+			if c.serviceType == serviceType {
+				provider, option = c.baseConfig.Storage(provider, option)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("service<%s> has no storage of its own", serviceType)
+		}
+	}
+	return instantiateStorage(config, provider, option)
 }
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// serviceStorage is a storage provider used by one or more services, along with the configuration it was created
+// from.
+type serviceStorage struct {
+	provider string
+	option   any
+
+	// db is the provider, once migrated wrapped by outbox and tenants, which are nil before
+	db      storage.ServiceStorage
+	outbox  *storage.OutboxStorage
+	tenants *storage.TenantStorage
+}
+
+// serviceStorages holds the storage providers of the services. Services configured with the same provider and options
+// share a single instance of it, so that e.g. a bolt file is only opened once, and so that the flows which span
+// services, such as reviewing an application, stay within a single provider when they are configured to.
+type serviceStorages struct {
+	// providers holds every distinct provider. The first is the one configured in the services block, which is used by
+	// every service without a storage of its own, and which holds the tenants.
+	providers []*serviceStorage
+	byService map[framework.Type]*serviceStorage
+}
+
+type serviceStorageConfig struct {
+	serviceType framework.Type
+	baseConfig  *config.BaseServiceConfig
+}
+
+// serviceStorageConfigs returns the base configuration of every service which may configure its own storage, in the
+// order the services are instantiated.
+func serviceStorageConfigs(servicesConfig config.ServicesConfig) []serviceStorageConfig {
+	return []serviceStorageConfig{
+		{framework.KeyStore, servicesConfig.KeyStoreConfig.BaseServiceConfig},
+		{framework.DID, servicesConfig.DIDConfig.BaseServiceConfig},
+		{framework.Issuing, servicesConfig.IssuingServiceConfig.BaseServiceConfig},
+		{framework.Schema, servicesConfig.SchemaConfig.BaseServiceConfig},
+		{framework.Credential, servicesConfig.CredentialConfig.BaseServiceConfig},
+		{framework.Manifest, servicesConfig.ManifestConfig.BaseServiceConfig},
+		{framework.Presentation, servicesConfig.PresentationConfig.BaseServiceConfig},
+		{framework.Webhook, servicesConfig.WebhookConfig.BaseServiceConfig},
+	}
+}
+
+// validateServiceStorageConfigs checks that the storage provider of every service is available.
+func validateServiceStorageConfigs(servicesConfig config.ServicesConfig) error {
+	for _, c := range serviceStorageConfigs(servicesConfig) {
+		provider, _ := c.baseConfig.Storage(servicesConfig.StorageProvider, servicesConfig.StorageOption)
+		if !storage.IsStorageAvailable(storage.Type(provider)) {
+			return fmt.Errorf("%s storage provider configured for %s, but not available", provider, c.serviceType)
+		}
+	}
+	return nil
+}
+
+// instantiateServiceStorages creates the storage provider of every service, encrypting them when configured to.
+func instantiateServiceStorages(servicesConfig config.ServicesConfig) (*serviceStorages, error) {
+	storages := serviceStorages{byService: make(map[framework.Type]*serviceStorage)}
+	if _, err := storages.instantiate(servicesConfig, servicesConfig.StorageProvider, servicesConfig.StorageOption); err != nil {
+		return nil, err
+	}
+	for _, c := range serviceStorageConfigs(servicesConfig) {
+		provider, option := c.baseConfig.Storage(servicesConfig.StorageProvider, servicesConfig.StorageOption)
+		s, err := storages.instantiate(servicesConfig, provider, option)
+		if err != nil {
+			_ = storages.Close()
+			return nil, err
+		}
+		storages.byService[c.serviceType] = s
+	}
+	return &storages, nil
+}
+
+// instantiate returns the provider with the given configuration, creating it unless a service already uses it.
+func (s *serviceStorages) instantiate(servicesConfig config.ServicesConfig, provider string, option any) (*serviceStorage, error) {
+	for _, existing := range s.providers {
+		if existing.provider == provider && reflect.DeepEqual(existing.option, option) {
+			return existing, nil
+		}
+	}
+	db, err := instantiateStorage(servicesConfig, provider, option)
+	if err != nil {
+		return nil, err
+	}
+	created := serviceStorage{provider: provider, option: option, db: db}
+	s.providers = append(s.providers, &created)
+	return &created, nil
+}
+
+// defaultStorage returns the provider configured in the services block.
+func (s *serviceStorages) defaultStorage() *serviceStorage {
+	return s.providers[0]
+}
+
+// get returns the storage of the given service, once wrapped. Services without a configuration of their own use the
+// default storage.
+func (s *serviceStorages) get(serviceType framework.Type) *storage.TenantStorage {
+	if st, ok := s.byService[serviceType]; ok {
+		return st.tenants
+	}
+	return s.defaultStorage().tenants
+}
+
+// wrap records the changes made to every provider in its outbox, and scopes the operations of services to the tenant
+// of their context. Changes are only recorded once migrated, so that migrations do not replay every stored value to
+// subscribers.
+func (s *serviceStorages) wrap(retention time.Duration) error {
+	for _, st := range s.providers {
+		outbox, err := storage.NewOutboxStorage(st.db, retention)
+		if err != nil {
+			return err
+		}
+		st.outbox = outbox
+		st.tenants = storage.NewTenantStorage(outbox)
+		st.db = st.tenants
+	}
+	return nil
+}
+
+// subscribe subscribes the handler to the outbox of every provider.
+func (s *serviceStorages) subscribe(name string, handler storage.ChangeHandler) error {
+	for _, st := range s.providers {
+		if err := st.outbox.Subscribe(name, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start starts delivering the changes recorded in the outbox of every provider.
+func (s *serviceStorages) start() {
+	for _, st := range s.providers {
+		st.outbox.Start()
+	}
+}
+
+// allTenants returns the storage of every provider, once wrapped.
+func (s *serviceStorages) allTenants() []*storage.TenantStorage {
+	tenants := make([]*storage.TenantStorage, 0, len(s.providers))
+	for _, st := range s.providers {
+		tenants = append(tenants, st.tenants)
+	}
+	return tenants
+}
+
+// Close closes every provider.
+func (s *serviceStorages) Close() error {
+	ae := sdkutil.NewAppendError()
+	for _, st := range s.providers {
+		if err := st.db.Close(); err != nil {
+			ae.Append(errors.Wrapf(err, "closing %s storage", st.provider))
+		}
+	}
+	return ae.Error()
+}
+
+// migrate runs the registered migrations against every provider, for the default tenant and then for every tenant.
+// The namespaces of the results of tenants carry their tenant prefix.
+func (s *serviceStorages) migrate(ctx context.Context, dryRun bool) ([]storage.MigrationResult, error) {
+	tenants, err := tenant.NewTenantStorage(storage.NewTenantStorage(s.defaultStorage().db))
+	if err != nil {
+		return nil, err
+	}
+	allTenants, err := tenants.GetTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []storage.MigrationResult
+	for _, st := range s.providers {
+		providerResults, err := storage.RunMigrations(ctx, st.db, storage.RegisteredMigrations(), dryRun)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrating %s storage", st.provider)
+		}
+		results = append(results, providerResults...)
+
+		tenantStorage := storage.NewTenantStorage(st.db)
+		for _, t := range allTenants {
+			tenantResults, err := storage.RunMigrations(storage.WithTenant(ctx, t.ID), tenantStorage, storage.RegisteredMigrations(), dryRun)
+			if err != nil {
+				return nil, errors.Wrapf(err, "migrating tenant<%s> of %s storage", t.ID, st.provider)
+			}
+			for _, result := range tenantResults {
+				result.Namespace = storage.TenantNamespace(t.ID, result.Namespace)
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+// instantiateStorage creates the given storage provider, encrypting it when configured to.
+func instantiateStorage(servicesConfig config.ServicesConfig, provider string, option any) (storage.ServiceStorage, error) {
+	storageProvider, err := storage.NewStorage(storage.Type(provider), option)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not instantiate storage provider: %s", provider)
+	}
+	if !servicesConfig.EncryptionConfig.IsEmpty() {
+		storageProvider, err = instantiateEncryptedStorage(servicesConfig.EncryptionConfig, storageProvider)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate encrypted storage")
+		}
+	}
+	return storageProvider, nil
+}
+
+// instantiateEncryptedStorage wraps the storage provider so that the values of the configured namespaces are
+// encrypted. Passwords other than the current one are only used to re-encrypt the data keys they had encrypted. The
+// outbox is always encrypted, since its changes hold the values of any namespace.
+func instantiateEncryptedStorage(config config.EncryptionConfig, storageProvider storage.ServiceStorage) (storage.ServiceStorage, error) {
+	current := storage.EncryptionPassword{ID: config.CurrentPasswordID, Password: config.Passwords[config.CurrentPasswordID]}
+	previous := make([]storage.EncryptionPassword, 0, len(config.Passwords))
+	for id, password := range config.Passwords {
+		if id != current.ID {
+			previous = append(previous, storage.EncryptionPassword{ID: id, Password: password})
+		}
+	}
+	namespaces := append([]string{storage.OutboxNamespace}, config.Namespaces...)
+	return storage.NewEncryptedStorage(context.Background(), storageProvider, namespaces, current, previous...)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/tenant"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+func testServicesConfig(t *testing.T) config.ServicesConfig {
+	keystoreDB := filepath.Join(t.TempDir(), "keystore.db")
+	return config.ServicesConfig{
+		StorageProvider: "memory",
+		KeyStoreConfig: config.KeyStoreServiceConfig{
+			BaseServiceConfig:  &config.BaseServiceConfig{Name: "keystore", StorageProvider: "bolt", StorageOption: keystoreDB},
+			ServiceKeyPassword: "test-password",
+		},
+		DIDConfig: config.DIDServiceConfig{
+			BaseServiceConfig:      &config.BaseServiceConfig{Name: "did"},
+			Methods:                []string{"key"},
+			LocalResolutionMethods: []string{"key"},
+		},
+		SchemaConfig: config.SchemaServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "schema"}},
+		CredentialConfig: config.CredentialServiceConfig{
+			BaseServiceConfig: &config.BaseServiceConfig{Name: "credential", ServiceEndpoint: config.DefaultServiceEndpoint},
+		},
+		ManifestConfig:       config.ManifestServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "manifest"}},
+		PresentationConfig:   config.PresentationServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "presentation"}},
+		IssuingServiceConfig: config.IssuingServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "issuing"}},
+		// the same provider and options as the services block, so it shares its storage
+		WebhookConfig: config.WebhookServiceConfig{BaseServiceConfig: &config.BaseServiceConfig{Name: "webhook", StorageProvider: "memory"}},
+	}
+}
+
+func TestServiceStorages(t *testing.T) {
+	t.Run("services configured alike share a provider", func(tt *testing.T) {
+		servicesConfig := testServicesConfig(tt)
+		servicesConfig.DIDConfig.StorageProvider = "bolt"
+		servicesConfig.DIDConfig.StorageOption = servicesConfig.KeyStoreConfig.StorageOption
+
+		storages, err := instantiateServiceStorages(servicesConfig)
+		require.NoError(tt, err)
+		defer func() {
+			assert.NoError(tt, storages.Close())
+		}()
+
+		require.Len(tt, storages.providers, 2)
+		assert.Equal(tt, "memory", storages.defaultStorage().provider)
+		assert.Same(tt, storages.byService[framework.KeyStore], storages.byService[framework.DID])
+		assert.Equal(tt, "bolt", storages.byService[framework.KeyStore].provider)
+		assert.Same(tt, storages.defaultStorage(), storages.byService[framework.Webhook])
+		assert.Same(tt, storages.defaultStorage(), storages.byService[framework.Credential])
+	})
+
+	t.Run("unavailable providers are rejected", func(tt *testing.T) {
+		servicesConfig := testServicesConfig(tt)
+		servicesConfig.SchemaConfig.StorageProvider = "mongo"
+		assert.ErrorContains(tt, validateServiceConfig(servicesConfig), "mongo storage provider configured for schema")
+	})
+
+	t.Run("services use their own storage", func(tt *testing.T) {
+		servicesConfig := testServicesConfig(tt)
+		ssi, err := InstantiateSSIService(servicesConfig)
+		require.NoError(tt, err)
+
+		ctx := context.Background()
+		tenantService := ssi.GetService(framework.Tenant).(*tenant.Service)
+		_, err = tenantService.CreateTenant(ctx, tenant.CreateTenantRequest{ID: "acme"})
+		require.NoError(tt, err)
+
+		_, privKey, err := crypto.GenerateKeyByKeyType(crypto.Ed25519)
+		require.NoError(tt, err)
+		privKeyBytes, err := crypto.PrivKeyToBytes(privKey)
+		require.NoError(tt, err)
+		keyStoreService := ssi.GetService(framework.KeyStore).(*keystore.Service)
+		for _, keyCtx := range []context.Context{ctx, storage.WithTenant(ctx, "acme")} {
+			err = keyStoreService.StoreKey(keyCtx, keystore.StoreKeyRequest{
+				ID:               "did:test:me#key-1",
+				Type:             crypto.Ed25519,
+				Controller:       "did:test:me",
+				PrivateKeyBase58: base58.Encode(privKeyBytes),
+			})
+			require.NoError(tt, err)
+		}
+
+		keystoreStorage := ssi.storages.byService[framework.KeyStore].db
+		defaultStorage := ssi.storages.defaultStorage().db
+		assert.Contains(tt, namespaces(tt, ctx, keystoreStorage), "keystore")
+		assert.Contains(tt, namespaces(tt, storage.WithTenant(ctx, "acme"), keystoreStorage), "keystore")
+		assert.NotContains(tt, namespaces(tt, ctx, defaultStorage), "keystore")
+		assert.Contains(tt, namespaces(tt, ctx, defaultStorage), "tenants")
+
+		// deleting a tenant deletes its data from the storage of every service
+		require.NoError(tt, tenantService.DeleteTenant(ctx, tenant.DeleteTenantRequest{ID: "acme"}))
+		assert.Empty(tt, namespaces(tt, storage.WithTenant(ctx, "acme"), keystoreStorage))
+
+		require.NoError(tt, ssi.Close())
+	})
+}
+
+func namespaces(t *testing.T, ctx context.Context, db storage.ServiceStorage) []string {
+	namespaces, err := db.Namespaces(ctx)
+	require.NoError(t, err)
+	return namespaces
+}
@@ -0,0 +1,115 @@
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+)
+
+// Tenant is an isolated issuer, whose keys, data and webhooks are only visible to the requests made on its behalf.
+type Tenant struct {
+	ID        string `json:"id" validate:"required"`
+	Config    Config `json:"config"`
+	CreatedAt string `json:"createdAt"`
+	// The hex encoded SHA-256 hash of the API key requests made on behalf of the tenant are authenticated with. The key
+	// itself is only known to whoever created the tenant, or last rotated its key. Empty for the tenants created before
+	// tenants had API keys, on whose behalf no request is made until their key is rotated.
+	APIKeyHash string `json:"apiKeyHash,omitempty"`
+}
+
+// HasAPIKey returns whether the given API key is the one of the tenant.
+func (t Tenant) HasAPIKey(apiKey string) bool {
+	if t.APIKeyHash == "" || apiKey == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashAPIKey(apiKey)), []byte(t.APIKeyHash)) == 1
+}
+
+func hashAPIKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// Config overrides the configuration of the services for the requests of a tenant. Zero values keep the configured
+// value.
+type Config struct {
+	// Endpoint at which the service is reachable by the holders and verifiers of the tenant, e.g. to fetch the status
+	// lists of its credentials.
+	ServiceEndpoint string `json:"serviceEndpoint,omitempty"`
+	// How long credential applications are kept.
+	ApplicationTTL time.Duration `json:"applicationTtl,omitempty"`
+	// How long presentation submissions are kept.
+	SubmissionTTL time.Duration `json:"submissionTtl,omitempty"`
+}
+
+type CreateTenantRequest struct {
+	ID     string `json:"id" validate:"required"`
+	Config Config `json:"config"`
+}
+
+// CreateTenantResponse holds the created tenant, and the API key requests made on its behalf are authenticated with,
+// which is not stored.
+type CreateTenantResponse struct {
+	Tenant Tenant `json:"tenant"`
+	APIKey string `json:"apiKey"`
+}
+
+type GetTenantRequest struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type GetTenantsResponse struct {
+	Tenants []Tenant `json:"tenants,omitempty"`
+}
+
+type DeleteTenantRequest struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type RotateTenantAPIKeyRequest struct {
+	ID string `json:"id" validate:"required"`
+}
+
+// RotateTenantAPIKeyResponse holds the new API key of the tenant, which is not stored.
+type RotateTenantAPIKeyResponse struct {
+	APIKey string `json:"apiKey"`
+}
+
+type configContextKey struct{}
+
+// NewContext returns a context carrying the config overrides of the tenant the request is made on behalf of.
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, configContextKey{}, t.Config)
+}
+
+// ConfigFromContext returns the config overrides of the tenant of the context, which are empty for the default tenant.
+func ConfigFromContext(ctx context.Context) Config {
+	c, _ := ctx.Value(configContextKey{}).(Config)
+	return c
+}
+
+// ServiceEndpoint returns the service endpoint of the tenant of the context, or serviceEndpoint when not overridden.
+func ServiceEndpoint(ctx context.Context, serviceEndpoint string) string {
+	if c := ConfigFromContext(ctx); c.ServiceEndpoint != "" {
+		return c.ServiceEndpoint
+	}
+	return serviceEndpoint
+}
+
+// ApplicationTTL returns the application ttl of the tenant of the context, or ttl when not overridden.
+func ApplicationTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	if c := ConfigFromContext(ctx); c.ApplicationTTL != 0 {
+		return c.ApplicationTTL
+	}
+	return ttl
+}
+
+// SubmissionTTL returns the submission ttl of the tenant of the context, or ttl when not overridden.
+func SubmissionTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	if c := ConfigFromContext(ctx); c.SubmissionTTL != 0 {
+		return c.SubmissionTTL
+	}
+	return ttl
+}
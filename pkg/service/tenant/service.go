@@ -0,0 +1,193 @@
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// apiKeySize is the number of random bytes of the API keys of tenants.
+const apiKeySize = 32
+
+// Service manages tenants. Tenants can only be managed by requests made on behalf of the default tenant.
+type Service struct {
+	storage *Storage
+	// dbs holds the storage of every service, in which the data of tenants is migrated and deleted
+	dbs []*storage.TenantStorage
+}
+
+func (s Service) Type() framework.Type {
+	return framework.Tenant
+}
+
+func (s Service) Status() framework.Status {
+	ae := sdkutil.NewAppendError()
+	if s.storage == nil {
+		ae.AppendString("no storage configured")
+	}
+	if !ae.IsEmpty() {
+		return framework.Status{
+			Status:  framework.StatusNotReady,
+			Message: fmt.Sprintf("tenant service is not ready: %s", ae.Error().Error()),
+		}
+	}
+	return framework.Status{Status: framework.StatusReady}
+}
+
+// NewTenantService stores tenants in db. When services use storage providers other than db, those are passed as
+// others, so that the data of tenants is also migrated and deleted in them.
+func NewTenantService(db *storage.TenantStorage, others ...*storage.TenantStorage) (*Service, error) {
+	tenantStorage, err := NewTenantStorage(db)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the tenant service")
+	}
+	dbs := []*storage.TenantStorage{db}
+	for _, other := range others {
+		if other != db {
+			dbs = append(dbs, other)
+		}
+	}
+	service := Service{storage: tenantStorage, dbs: dbs}
+	if !service.Status().IsReady() {
+		return nil, errors.New(service.Status().Message)
+	}
+	return &service, nil
+}
+
+// CreateTenant creates a tenant, and brings its namespaces to the latest schema version, so that the migrations run
+// at startup leave them untouched. The response holds the API key of the tenant, which cannot be retrieved again.
+func (s Service) CreateTenant(ctx context.Context, request CreateTenantRequest) (*CreateTenantResponse, error) {
+	if err := checkDefaultTenant(ctx); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTenantID(request.ID); err != nil {
+		return nil, err
+	}
+	if request.Config.ApplicationTTL < 0 || request.Config.SubmissionTTL < 0 {
+		return nil, errors.New("tenant ttls cannot be negative")
+	}
+
+	apiKey, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("creating tenant: %s", request.ID)
+	t := Tenant{
+		ID:         request.ID,
+		Config:     request.Config,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		APIKeyHash: hashAPIKey(apiKey),
+	}
+	stored, err := s.storage.StoreTenant(ctx, t)
+	if err != nil {
+		return nil, errors.Wrap(err, "storing tenant")
+	}
+	if !stored {
+		return nil, fmt.Errorf("tenant<%s> already exists", request.ID)
+	}
+	for _, db := range s.dbs {
+		if _, err = storage.RunMigrations(storage.WithTenant(ctx, t.ID), db, storage.RegisteredMigrations(), false); err != nil {
+			return nil, errors.Wrapf(err, "migrating storage of tenant<%s>", t.ID)
+		}
+	}
+	return &CreateTenantResponse{Tenant: t, APIKey: apiKey}, nil
+}
+
+// RotateTenantAPIKey replaces the API key of the tenant with a new one, after which requests made with the previous key
+// are rejected. The response holds the new key, which cannot be retrieved again.
+func (s Service) RotateTenantAPIKey(ctx context.Context, request RotateTenantAPIKeyRequest) (*RotateTenantAPIKeyResponse, error) {
+	if err := checkDefaultTenant(ctx); err != nil {
+		return nil, err
+	}
+	apiKey, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("rotating api key of tenant: %s", request.ID)
+	stored, err := s.storage.StoreAPIKeyHash(ctx, request.ID, hashAPIKey(apiKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "storing tenant api key")
+	}
+	if !stored {
+		return nil, fmt.Errorf("tenant<%s> does not exist", request.ID)
+	}
+	return &RotateTenantAPIKeyResponse{APIKey: apiKey}, nil
+}
+
+// newAPIKey returns a random API key of apiKeySize bytes, base64url encoded.
+func newAPIKey() (string, error) {
+	key := make([]byte, apiKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.Wrap(err, "generating tenant api key")
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// GetTenant returns the tenant with the given ID, or nil when it does not exist.
+func (s Service) GetTenant(ctx context.Context, request GetTenantRequest) (*Tenant, error) {
+	if err := checkDefaultTenant(ctx); err != nil {
+		return nil, err
+	}
+	return s.storage.GetTenant(ctx, request.ID)
+}
+
+// ResolveTenant returns the tenant with the given ID, or nil when it does not exist. Unlike the other methods, it may
+// be called on behalf of any tenant, since it is used to resolve the tenant of requests, which must then be
+// authenticated with its API key.
+func (s Service) ResolveTenant(ctx context.Context, id string) (*Tenant, error) {
+	return s.storage.GetTenant(storage.WithTenant(ctx, ""), id)
+}
+
+func (s Service) GetTenants(ctx context.Context) (*GetTenantsResponse, error) {
+	if err := checkDefaultTenant(ctx); err != nil {
+		return nil, err
+	}
+	tenants, err := s.storage.GetTenants(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting tenants")
+	}
+	return &GetTenantsResponse{Tenants: tenants}, nil
+}
+
+// DeleteTenant deletes the tenant along with all of its data, including its keys.
+func (s Service) DeleteTenant(ctx context.Context, request DeleteTenantRequest) error {
+	if err := checkDefaultTenant(ctx); err != nil {
+		return err
+	}
+	t, err := s.storage.GetTenant(ctx, request.ID)
+	if err != nil {
+		return errors.Wrap(err, "getting tenant")
+	}
+	if t == nil {
+		return fmt.Errorf("tenant<%s> does not exist", request.ID)
+	}
+
+	logrus.Debugf("deleting tenant: %s", request.ID)
+	if err = s.storage.DeleteTenant(ctx, request.ID); err != nil {
+		return err
+	}
+	for _, db := range s.dbs[1:] {
+		if err = db.DeleteTenant(ctx, request.ID); err != nil {
+			return sdkutil.LoggingErrorMsgf(err, "deleting data of tenant<%s>", request.ID)
+		}
+	}
+	return nil
+}
+
+func checkDefaultTenant(ctx context.Context) error {
+	if tenantID := storage.TenantFromContext(ctx); tenantID != "" {
+		return fmt.Errorf("tenants cannot be managed on behalf of tenant<%s>", tenantID)
+	}
+	return nil
+}
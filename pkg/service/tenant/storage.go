@@ -0,0 +1,121 @@
+package tenant
+
+import (
+	"context"
+	"sort"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// namespace holds the tenants, keyed by ID. It belongs to the default tenant, and must not be a prefix of the
+// namespaces of tenants, which start with "tenant-".
+const namespace = "tenants"
+
+type Storage struct {
+	db *storage.TenantStorage
+}
+
+func NewTenantStorage(db *storage.TenantStorage) (*Storage, error) {
+	if db == nil {
+		return nil, errors.New("db reference is nil")
+	}
+	return &Storage{db: db}, nil
+}
+
+// StoreTenant stores the tenant, unless a tenant with the same ID already exists, in which case false is returned.
+func (ts *Storage) StoreTenant(ctx context.Context, t Tenant) (bool, error) {
+	tenantBytes, err := json.Marshal(t)
+	if err != nil {
+		return false, sdkutil.LoggingErrorMsgf(err, "marshalling tenant<%s>", t.ID)
+	}
+	stored, err := ts.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		existing, err := tx.Read(ctx, namespace, t.ID)
+		if err != nil {
+			return false, err
+		}
+		if existing != nil {
+			return false, nil
+		}
+		return true, tx.Write(ctx, namespace, t.ID, tenantBytes)
+	}, []storage.WatchKey{{Namespace: namespace, Key: t.ID}})
+	if err != nil {
+		return false, sdkutil.LoggingErrorMsgf(err, "storing tenant<%s>", t.ID)
+	}
+	return stored.(bool), nil
+}
+
+// StoreAPIKeyHash sets the API key hash of the tenant with the given ID, unless it does not exist, in which case false
+// is returned.
+func (ts *Storage) StoreAPIKeyHash(ctx context.Context, id, apiKeyHash string) (bool, error) {
+	stored, err := ts.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		tenantBytes, err := tx.Read(ctx, namespace, id)
+		if err != nil {
+			return false, err
+		}
+		if len(tenantBytes) == 0 {
+			return false, nil
+		}
+		var t Tenant
+		if err = json.Unmarshal(tenantBytes, &t); err != nil {
+			return false, errors.Wrapf(err, "unmarshalling tenant<%s>", id)
+		}
+		t.APIKeyHash = apiKeyHash
+		if tenantBytes, err = json.Marshal(t); err != nil {
+			return false, errors.Wrapf(err, "marshalling tenant<%s>", id)
+		}
+		return true, tx.Write(ctx, namespace, id, tenantBytes)
+	}, []storage.WatchKey{{Namespace: namespace, Key: id}})
+	if err != nil {
+		return false, sdkutil.LoggingErrorMsgf(err, "storing api key of tenant<%s>", id)
+	}
+	return stored.(bool), nil
+}
+
+// GetTenant returns the tenant with the given ID, or nil when it does not exist.
+func (ts *Storage) GetTenant(ctx context.Context, id string) (*Tenant, error) {
+	tenantBytes, err := ts.db.Read(ctx, namespace, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "reading tenant<%s>", id)
+	}
+	if len(tenantBytes) == 0 {
+		return nil, nil
+	}
+	var t Tenant
+	if err = json.Unmarshal(tenantBytes, &t); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling tenant<%s>", id)
+	}
+	return &t, nil
+}
+
+// GetTenants returns all the tenants, sorted by ID.
+func (ts *Storage) GetTenants(ctx context.Context) ([]Tenant, error) {
+	tenantsBytes, err := ts.db.ReadAll(ctx, namespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "reading tenants")
+	}
+	tenants := make([]Tenant, 0, len(tenantsBytes))
+	for id, tenantBytes := range tenantsBytes {
+		var t Tenant
+		if err = json.Unmarshal(tenantBytes, &t); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling tenant<%s>", id)
+		}
+		tenants = append(tenants, t)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+// DeleteTenant deletes the tenant, and then all of its data.
+func (ts *Storage) DeleteTenant(ctx context.Context, id string) error {
+	if err := ts.db.Delete(ctx, namespace, id); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "deleting tenant<%s>", id)
+	}
+	if err := ts.db.DeleteTenant(ctx, id); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "deleting data of tenant<%s>", id)
+	}
+	return nil
+}
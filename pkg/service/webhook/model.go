@@ -1,6 +1,10 @@
 package webhook
 
-import "net/url"
+import (
+	"net/url"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+)
 
 // In the context of webhooks, it's common to use noun.verb notation to describe events,
 // such as "credential.create" or "schema.delete".
@@ -21,20 +25,35 @@ const (
 // Supported Verbs
 const (
 	Create = Verb("Create")
+	Update = Verb("Update")
 	Delete = Verb("Delete")
 )
 
+// Resource is a kind of stored value which webhooks can be registered for.
+type Resource struct {
+	Noun Noun
+	// Data returns the data posted for a stored value, in the shape it is returned by the API with, so that stored
+	// fields which are internal to the service are not posted. The stored value is posted as is when nil.
+	Data func(value []byte) (any, error)
+}
+
 type Webhook struct {
 	Noun Noun     `json:"noun" validate:"required"`
 	Verb Verb     `json:"verb" validate:"required"`
 	URLS []string `json:"urls" validate:"required"`
 }
 
+// Payload is posted to the URLs of a webhook whenever a stored value changes. EventID identifies the change, which may
+// be posted more than once, and Data is the resource as it is returned by the API, once written, or before it was
+// deleted.
 type Payload struct {
-	Noun Noun   `json:"noun" validate:"required"`
-	Verb Verb   `json:"verb" validate:"required"`
-	URL  string `json:"url" validate:"required"`
-	Data any    `json:"data,omitempty"`
+	Noun       Noun   `json:"noun" validate:"required"`
+	Verb       Verb   `json:"verb" validate:"required"`
+	URL        string `json:"url" validate:"required"`
+	EventID    string `json:"eventId,omitempty"`
+	Tenant     string `json:"tenant,omitempty"`
+	ResourceID string `json:"resourceId,omitempty"`
+	Data       any    `json:"data,omitempty"`
 }
 
 type CreateWebhookRequest struct {
@@ -56,8 +75,13 @@ type GetWebhookResponse struct {
 	Webhook Webhook `json:"webhook"`
 }
 
+type GetWebhooksRequest struct {
+	PageRequest framework.PageRequest
+}
+
 type GetWebhooksResponse struct {
-	Webhooks []Webhook `json:"webhooks,omitempty"`
+	Webhooks      []Webhook `json:"webhooks,omitempty"`
+	NextPageToken string    `json:"nextPageToken,omitempty"`
 }
 
 type DeleteWebhookRequest struct {
@@ -105,7 +129,7 @@ func (n Noun) IsValid() bool {
 
 func (v Verb) isValid() bool {
 	switch v {
-	case Create, Delete:
+	case Create, Update, Delete:
 		return true
 	default:
 		return false
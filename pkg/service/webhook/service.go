@@ -115,15 +115,15 @@ func (s Service) GetWebhook(ctx context.Context, request GetWebhookRequest) (*Ge
 	return &GetWebhookResponse{Webhook: *webhook}, nil
 }
 
-func (s Service) GetWebhooks(ctx context.Context) (*GetWebhooksResponse, error) {
+func (s Service) GetWebhooks(ctx context.Context, request GetWebhooksRequest) (*GetWebhooksResponse, error) {
 	logrus.Debug("getting all webhooks")
 
-	webhooks, err := s.storage.GetWebhooks(ctx)
+	webhooks, nextPageToken, err := s.storage.GetWebhooks(ctx, request.PageRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "get webhooks")
 	}
 
-	return &GetWebhooksResponse{Webhooks: webhooks}, nil
+	return &GetWebhooksResponse{Webhooks: webhooks, NextPageToken: nextPageToken}, nil
 }
 
 // DeleteWebhook deletes a webhook from the storage by removing a given DIDWebID from the list of URLs associated with the webhook.
@@ -162,40 +162,96 @@ func (s Service) GetSupportedNouns() GetSupportedNounsResponse {
 }
 
 func (s Service) GetSupportedVerbs() GetSupportedVerbsResponse {
-	return GetSupportedVerbsResponse{Verbs: []Verb{Create, Delete}}
+	return GetSupportedVerbsResponse{Verbs: []Verb{Create, Update, Delete}}
 }
 
-func (s Service) PublishWebhook(ctx context.Context, noun Noun, verb Verb, payloadReader io.Reader) {
-	webhook, err := s.storage.GetWebhook(context.Background(), string(noun), string(verb))
-	if err != nil {
-		logrus.WithError(err).Warn("get webhook")
-		return
+// HandleChange posts a change to stored values to the webhooks which the tenant of the change registered for its noun
+// and verb. It is subscribed to the storage outbox, which delivers the change again when any of the webhooks could not
+// be posted to.
+func (s Service) HandleChange(ctx context.Context, event storage.ChangeEvent) error {
+	tenantID, namespace := storage.SplitTenantNamespace(event.Namespace)
+	ctx = storage.WithTenant(ctx, tenantID)
+	resource, ok := resources[namespace]
+	if !ok {
+		return nil
+	}
+	verb, ok := changeVerb(event.Type)
+	if !ok {
+		return nil
 	}
 
+	webhook, err := s.storage.GetWebhook(ctx, string(resource.Noun), string(verb))
+	if err != nil {
+		return errors.Wrap(err, "get webhook")
+	}
 	if webhook == nil {
-		return
+		return nil
 	}
 
-	payloadBytes, err := io.ReadAll(payloadReader)
+	data, err := payloadData(resource, event.Value)
 	if err != nil {
-		logrus.WithError(err).Warn("converting payload to bytes")
-		return
+		return errors.Wrapf(err, "building payload data of %s", resource.Noun)
 	}
-
-	postPayload := Payload{Noun: noun, Verb: verb, Data: string(payloadBytes)}
+	postPayload := Payload{
+		Noun:       resource.Noun,
+		Verb:       verb,
+		EventID:    event.ID,
+		Tenant:     tenantID,
+		ResourceID: event.Key,
+		Data:       data,
+	}
+	ae := sdkutil.NewAppendError()
 	for _, url := range webhook.URLS {
 		postPayload.URL = url
 		postJSONData, err := json.Marshal(postPayload)
 		if err != nil {
-			logrus.Warn("marshal payload")
-			continue
+			return errors.Wrap(err, "marshal payload")
 		}
 
-		err = s.post(ctx, url, string(postJSONData))
-		if err != nil {
-			logrus.Warnf("posting payload to %s", url)
+		if err = s.post(ctx, url, string(postJSONData)); err != nil {
+			ae.Append(errors.Wrapf(err, "posting payload to %s", url))
+		}
+	}
+	return ae.Error()
+}
+
+// resources are the kinds of stored values webhooks can be registered for, by the namespace they are stored in.
+var resources = make(map[string]Resource)
+
+// RegisterResource registers the kind of values stored in a namespace, so that their changes are posted to the
+// webhooks registered for its noun. It is called by the services from their init functions, before any change is
+// handled, and panics when the namespace is registered twice.
+func RegisterResource(namespace string, resource Resource) {
+	if _, ok := resources[namespace]; ok {
+		panic(fmt.Sprintf("webhook resource already registered for namespace<%s>", namespace))
+	}
+	resources[namespace] = resource
+}
+
+// payloadData returns the data posted for a changed value, in the shape its resource is returned by the API with.
+func payloadData(resource Resource, value []byte) (any, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	if resource.Data == nil {
+		if !json.Valid(value) {
+			return string(value), nil
 		}
+		return json.RawMessage(value), nil
+	}
+	return resource.Data(value)
+}
+
+func changeVerb(changeType storage.ChangeType) (Verb, bool) {
+	switch changeType {
+	case storage.ChangeCreate:
+		return Create, true
+	case storage.ChangeUpdate:
+		return Update, true
+	case storage.ChangeDelete:
+		return Delete, true
 	}
+	return "", false
 }
 
 func (s Service) post(ctx context.Context, url string, json string) error {
@@ -211,6 +267,9 @@ func (s Service) post(ctx context.Context, url string, json string) error {
 	if err != nil {
 		return errors.Wrap(err, "client http client")
 	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
 
 	if !is2xxResponse(resp.StatusCode) {
 		body, err := io.ReadAll(resp.Body)
@@ -220,9 +279,9 @@ func (s Service) post(ctx context.Context, url string, json string) error {
 		return fmt.Errorf("status code %v not in the 200s. body: %s", resp.StatusCode, string(body))
 	}
 
-	return err
+	return nil
 }
 
 func is2xxResponse(statusCode int) bool {
-	return statusCode/100 != 2
+	return statusCode/100 == 2
 }
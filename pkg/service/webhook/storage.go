@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -49,10 +50,10 @@ func (whs *Storage) GetWebhook(ctx context.Context, noun, verb string) (*Webhook
 	return &webhook, nil
 }
 
-func (whs *Storage) GetWebhooks(ctx context.Context) ([]Webhook, error) {
-	gotWebhooks, err := whs.db.ReadAll(ctx, webhookNamespace)
+func (whs *Storage) GetWebhooks(ctx context.Context, page framework.PageRequest) ([]Webhook, string, error) {
+	gotWebhooks, nextPageToken, err := whs.db.ReadPage(ctx, webhookNamespace, page.PageToken, page.PageSize)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not get all webhooks")
+		return nil, "", sdkutil.LoggingErrorMsg(err, "could not get all webhooks")
 	}
 
 	var webhooks []Webhook
@@ -65,7 +66,7 @@ func (whs *Storage) GetWebhooks(ctx context.Context) ([]Webhook, error) {
 		}
 	}
 
-	return webhooks, nil
+	return webhooks, nextPageToken, nil
 }
 
 func (whs *Storage) DeleteWebhook(ctx context.Context, noun, verb string) error {
@@ -0,0 +1,358 @@
+package storage
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// An archive holds a copy of every namespace of a storage, so that the state of a service can be backed up, or moved
+// to another storage provider. It is a gzipped tar file with a JSON lines file per namespace, holding one record per
+// key, and a manifest listing the namespaces with the number of records and the checksum of each file. The manifest is
+// written last, once all the checksums are known.
+const (
+	ArchiveVersion = 1
+
+	archiveManifestFile     = "manifest.json"
+	archiveNamespacesDir    = "namespaces/"
+	archiveNamespaceFileExt = ".jsonl"
+	archiveImportBatchSize  = 1000
+)
+
+// ArchiveManifest describes the contents of an archive.
+type ArchiveManifest struct {
+	Version    int                `json:"version"`
+	CreatedAt  string             `json:"createdAt"`
+	Source     Type               `json:"source"`
+	Namespaces []ArchiveNamespace `json:"namespaces"`
+}
+
+// ArchiveNamespace describes the file holding the records of a namespace. SHA256 is the hex encoded checksum of the
+// file.
+type ArchiveNamespace struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Count  int    `json:"count"`
+	SHA256 string `json:"sha256"`
+}
+
+type archiveRecord struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// ArchiveTransformFunc is applied to every record of an archive as it is imported. It returns the value to write, or
+// nil to skip the record.
+type ArchiveTransformFunc func(ctx context.Context, namespace, key string, value []byte) ([]byte, error)
+
+// ExportArchive writes every namespace of the storage to an archive, one namespace at a time. Each namespace is read
+// at once, but the storage is not locked in between, so an archive of a running service is only consistent within
+// each namespace.
+func ExportArchive(ctx context.Context, db ServiceStorage, w io.Writer) (*ArchiveManifest, error) {
+	namespaces, err := db.Namespaces(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing namespaces")
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	manifest := ArchiveManifest{
+		Version:    ArchiveVersion,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		Source:     db.Type(),
+		Namespaces: make([]ArchiveNamespace, 0, len(namespaces)),
+	}
+	for _, namespace := range namespaces {
+		values, err := db.ReadAll(ctx, namespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading namespace<%s>", namespace)
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			// expiries are not archived, and the deadline of an imported record would no longer apply
+			if strings.HasPrefix(namespace, getIndexNamespace("")) && strings.HasPrefix(key, indexExpiryKeyPrefix) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		for _, key := range keys {
+			if err = encoder.Encode(archiveRecord{Key: key, Value: values[key]}); err != nil {
+				return nil, errors.Wrapf(err, "encoding key<%s> of namespace<%s>", key, namespace)
+			}
+		}
+		file := archiveNamespaceFile(namespace)
+		if err = writeArchiveFile(tw, file, buf.Bytes()); err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(buf.Bytes())
+		manifest.Namespaces = append(manifest.Namespaces, ArchiveNamespace{
+			Name:   namespace,
+			File:   file,
+			Count:  len(keys),
+			SHA256: hex.EncodeToString(checksum[:]),
+		})
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling manifest")
+	}
+	if err = writeArchiveFile(tw, archiveManifestFile, manifestBytes); err != nil {
+		return nil, err
+	}
+	if err = tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "closing tar writer")
+	}
+	if err = gw.Close(); err != nil {
+		return nil, errors.Wrap(err, "closing gzip writer")
+	}
+	return &manifest, nil
+}
+
+// VerifyArchive reads the whole archive, and checks that every namespace file matches the count and checksum of the
+// manifest.
+func VerifyArchive(r io.Reader) (*ArchiveManifest, error) {
+	var manifest *ArchiveManifest
+	files := make(map[string]ArchiveNamespace)
+	err := walkArchive(r, func(name string, content io.Reader) error {
+		if name == archiveManifestFile {
+			manifest = new(ArchiveManifest)
+			if err := json.NewDecoder(content).Decode(manifest); err != nil {
+				return errors.Wrap(err, "decoding manifest")
+			}
+			return nil
+		}
+		count, checksum, err := readArchiveRecords(content, nil)
+		if err != nil {
+			return errors.Wrapf(err, "reading file<%s>", name)
+		}
+		files[name] = ArchiveNamespace{File: name, Count: count, SHA256: checksum}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, errors.New("archive has no manifest")
+	}
+	if manifest.Version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version<%d>", manifest.Version)
+	}
+	for _, namespace := range manifest.Namespaces {
+		file, ok := files[namespace.File]
+		if !ok {
+			return nil, fmt.Errorf("archive is missing the file<%s> of namespace<%s>", namespace.File, namespace.Name)
+		}
+		if file.Count != namespace.Count || file.SHA256 != namespace.SHA256 {
+			return nil, fmt.Errorf("file<%s> of namespace<%s> does not match the manifest", namespace.File, namespace.Name)
+		}
+		delete(files, namespace.File)
+	}
+	if len(files) > 0 {
+		return nil, fmt.Errorf("archive holds %d files which are not in the manifest", len(files))
+	}
+	return manifest, nil
+}
+
+// ReadArchiveRecord returns the value of a single key of the archive, or nil when the archive does not hold it. The
+// archive is read from the start, and is not verified.
+func ReadArchiveRecord(r io.ReadSeeker, namespace, key string) ([]byte, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "seeking to the start of the archive")
+	}
+	var value []byte
+	file := archiveNamespaceFile(namespace)
+	err := walkArchive(r, func(name string, content io.Reader) error {
+		if name != file {
+			return nil
+		}
+		_, _, err := readArchiveRecords(content, func(record archiveRecord) error {
+			if record.Key == key {
+				value = record.Value
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ImportArchive verifies the archive, and then writes all of its records to the storage, passing them through the
+// transform when it is not nil. Records overwrite the values already stored under the same keys, and other values are
+// left untouched. Namespaces are written in batches, so a failed import may leave a namespace partially imported.
+func ImportArchive(ctx context.Context, db ServiceStorage, r io.ReadSeeker, transform ArchiveTransformFunc) (*ArchiveManifest, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "seeking to the start of the archive")
+	}
+	manifest, err := VerifyArchive(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying archive")
+	}
+	namespaces := make(map[string]ArchiveNamespace, len(manifest.Namespaces))
+	for _, namespace := range manifest.Namespaces {
+		namespaces[namespace.File] = namespace
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "seeking to the start of the archive")
+	}
+	err = walkArchive(r, func(name string, content io.Reader) error {
+		namespace, ok := namespaces[name]
+		if !ok {
+			return nil
+		}
+		var batch []archiveRecord
+		imported := 0
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			batchNamespaces := make([]string, len(batch))
+			keys := make([]string, len(batch))
+			values := make([][]byte, len(batch))
+			for i, record := range batch {
+				batchNamespaces[i] = namespace.Name
+				keys[i] = record.Key
+				values[i] = record.Value
+			}
+			imported += len(batch)
+			batch = batch[:0]
+			return db.WriteMany(ctx, batchNamespaces, keys, values)
+		}
+		_, checksum, err := readArchiveRecords(content, func(record archiveRecord) error {
+			if transform != nil {
+				value, err := transform(ctx, namespace.Name, record.Key, record.Value)
+				if err != nil {
+					return errors.Wrapf(err, "transforming key<%s>", record.Key)
+				}
+				if value == nil {
+					return nil
+				}
+				record.Value = value
+			}
+			batch = append(batch, record)
+			if len(batch) < archiveImportBatchSize {
+				return nil
+			}
+			return flush()
+		})
+		if err != nil {
+			return errors.Wrapf(err, "importing namespace<%s>", namespace.Name)
+		}
+		if checksum != namespace.SHA256 {
+			return fmt.Errorf("file<%s> of namespace<%s> changed while being imported", name, namespace.Name)
+		}
+		if err = flush(); err != nil {
+			return errors.Wrapf(err, "importing namespace<%s>", namespace.Name)
+		}
+		logrus.Infof("imported %d of %d records into namespace<%s>", imported, namespace.Count, namespace.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// archiveNamespaceFile returns the name of the file holding a namespace, escaped so that it is always a single path
+// element.
+func archiveNamespaceFile(namespace string) string {
+	return archiveNamespacesDir + url.PathEscape(namespace) + archiveNamespaceFileExt
+}
+
+func writeArchiveFile(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now().UTC(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "writing header of file<%s>", name)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return errors.Wrapf(err, "writing file<%s>", name)
+	}
+	return nil
+}
+
+// walkArchive calls fileFunc with the name and content of every regular file of the archive, in order.
+func walkArchive(r io.Reader, fileFunc func(name string, content io.Reader) error) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, "opening gzip reader")
+	}
+	defer func() {
+		_ = gr.Close()
+	}()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading archive")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Name != archiveManifestFile && !strings.HasPrefix(header.Name, archiveNamespacesDir) {
+			return fmt.Errorf("unexpected file<%s> in archive", header.Name)
+		}
+		if err = fileFunc(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// readArchiveRecords decodes every record of a namespace file, passing each to recordFunc when it is not nil, and
+// returns the number of records along with the checksum of the file.
+func readArchiveRecords(content io.Reader, recordFunc func(record archiveRecord) error) (int, string, error) {
+	hash := sha256.New()
+	reader := bufio.NewReader(io.TeeReader(content, hash))
+	count := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var record archiveRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return 0, "", errors.Wrapf(err, "decoding record<%d>", count)
+			}
+			count++
+			if recordFunc != nil {
+				if err := recordFunc(record); err != nil {
+					return 0, "", err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, "", errors.Wrap(err, "reading records")
+		}
+	}
+	return count, hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	ctx := context.Background()
+	source := setupMemoryDB(t)
+	require.NoError(t, source.Write(ctx, "drivers", "max", []byte(`{"name":"Max Verstappen"}`)))
+	require.NoError(t, source.Write(ctx, "drivers", "checo", []byte(`{"name":"Sergio Perez"}`)))
+	require.NoError(t, source.Write(ctx, "teams/f1", "red-bull", []byte("\x00binary\n")))
+
+	var archive bytes.Buffer
+	manifest, err := ExportArchive(ctx, source, &archive)
+	require.NoError(t, err)
+	assert.Equal(t, Memory, manifest.Source)
+	assert.Len(t, manifest.Namespaces, 2)
+	assert.Equal(t, "drivers", manifest.Namespaces[0].Name)
+	assert.Equal(t, 2, manifest.Namespaces[0].Count)
+	assert.Equal(t, "namespaces/teams%2Ff1.jsonl", manifest.Namespaces[1].File)
+
+	verified, err := VerifyArchive(bytes.NewReader(archive.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, manifest, verified)
+
+	value, err := ReadArchiveRecord(bytes.NewReader(archive.Bytes()), "drivers", "checo")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"name":"Sergio Perez"}`), value)
+	value, err = ReadArchiveRecord(bytes.NewReader(archive.Bytes()), "drivers", "lando")
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		imported, err := ImportArchive(ctx, db, bytes.NewReader(archive.Bytes()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, manifest, imported)
+
+		for _, namespace := range []string{"drivers", "teams/f1"} {
+			want, err := source.ReadAll(ctx, namespace)
+			require.NoError(t, err)
+			got, err := db.ReadAll(ctx, namespace)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	}
+}
+
+func TestArchiveLeavesOutExpiries(t *testing.T) {
+	ctx := context.Background()
+	source := setupMemoryDB(t)
+	require.NoError(t, WriteIndexedWithTTL(ctx, source, "drivers", "max", []byte("max"), time.Hour, NewIndexEntry("team", "red-bull")))
+	var archive bytes.Buffer
+	_, err := ExportArchive(ctx, source, &archive)
+	require.NoError(t, err)
+
+	target := setupMemoryDB(t)
+	_, err = ImportArchive(ctx, target, bytes.NewReader(archive.Bytes()), nil)
+	require.NoError(t, err)
+	keys, err := ReadIndex(ctx, target, "drivers", "team", "red-bull")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"max"}, keys)
+	deadline, err := target.Read(ctx, getIndexNamespace("drivers"), indexExpiryKeyPrefix+"max")
+	assert.NoError(t, err)
+	assert.Nil(t, deadline)
+}
+
+func TestImportArchiveWithTransform(t *testing.T) {
+	ctx := context.Background()
+	source := setupMemoryDB(t)
+	require.NoError(t, source.Write(ctx, "drivers", "max", []byte("max")))
+	require.NoError(t, source.Write(ctx, "drivers", "checo", []byte("checo")))
+	var archive bytes.Buffer
+	_, err := ExportArchive(ctx, source, &archive)
+	require.NoError(t, err)
+
+	target := setupMemoryDB(t)
+	_, err = ImportArchive(ctx, target, bytes.NewReader(archive.Bytes()), func(_ context.Context, namespace, key string, value []byte) ([]byte, error) {
+		if key == "checo" {
+			return nil, nil
+		}
+		return append([]byte(namespace+"/"), value...), nil
+	})
+	assert.NoError(t, err)
+	got, err := target.ReadAll(ctx, "drivers")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"max": []byte("drivers/max")}, got)
+}
+
+func TestArchiveTampered(t *testing.T) {
+	ctx := context.Background()
+	source := setupMemoryDB(t)
+	require.NoError(t, source.Write(ctx, "drivers", "max", []byte("max")))
+	var archive bytes.Buffer
+	_, err := ExportArchive(ctx, source, &archive)
+	require.NoError(t, err)
+
+	tampered := rewriteArchive(t, archive.Bytes(), func(name string, content []byte) []byte {
+		return bytes.ReplaceAll(content, []byte(`"max"`), []byte(`"lando"`))
+	})
+	_, err = VerifyArchive(bytes.NewReader(tampered))
+	assert.ErrorContains(t, err, "does not match the manifest")
+
+	target := setupMemoryDB(t)
+	_, err = ImportArchive(ctx, target, bytes.NewReader(tampered), nil)
+	assert.Error(t, err)
+	namespaces, err := target.Namespaces(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, namespaces)
+
+	withoutManifest := rewriteArchive(t, archive.Bytes(), func(name string, content []byte) []byte {
+		if name == archiveManifestFile {
+			return nil
+		}
+		return content
+	})
+	_, err = VerifyArchive(bytes.NewReader(withoutManifest))
+	assert.ErrorContains(t, err, "archive has no manifest")
+}
+
+// rewriteArchive returns a copy of the archive with every file replaced by the result of rewrite, which drops the file
+// when it returns nil.
+func rewriteArchive(t *testing.T, archive []byte, rewrite func(name string, content []byte) []byte) []byte {
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		content = rewrite(header.Name, content)
+		if content == nil {
+			continue
+		}
+		header.Size = int64(len(content))
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return out.Bytes()
+}
@@ -3,11 +3,13 @@ package storage
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"strings"
 	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/cenkalti/backoff/v4"
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -22,10 +24,22 @@ func init() {
 
 const (
 	DBFilePrefix = "ssi-service"
+
+	// Values written with a TTL are tracked in two buckets which are not exposed as namespaces. The expiry bucket
+	// orders them by deadline for the sweep:
+	//
+	//	deadline (unix nanoseconds, 8 bytes, big endian) || namespace || 0x00 || key -> empty
+	//
+	// and the expiry keys bucket maps namespace || 0x00 || key to the deadline, so that it can be found again when the
+	// value is rewritten or deleted.
+	boltExpiryBucket     = "ssi-service-expiry"
+	boltExpiryKeysBucket = "ssi-service-expiry-keys"
+	boltDeadlineSize     = 8
 )
 
 type BoltDB struct {
-	db *bolt.DB
+	db          *bolt.DB
+	stopSweeper func()
 }
 
 // Init instantiates a file-based storage instance for Bolt https://github.com/boltdb/bolt
@@ -48,6 +62,7 @@ func (b *BoltDB) Init(options interface{}) error {
 		return err
 	}
 	b.db = db
+	b.stopSweeper = startExpirySweeper(b)
 	return nil
 }
 
@@ -69,11 +84,16 @@ func (b *BoltDB) Type() Type {
 }
 
 func (b *BoltDB) Close() error {
+	if b.stopSweeper != nil {
+		b.stopSweeper()
+	}
 	return b.db.Close()
 }
 
+// boltTx buffers the writes of a transaction, which are applied together once the watch keys have been checked.
 type boltTx struct {
-	tx *bolt.Tx
+	db     *BoltDB
+	writes []bufferedWrite
 }
 
 func (b *BoltDB) Exists(_ context.Context, namespace, key string) (bool, error) {
@@ -97,45 +117,116 @@ func (b *BoltDB) Exists(_ context.Context, namespace, key string) (bool, error)
 	return exists, err
 }
 
-// TODO: Implement to be transactional
+// Read opens a read-only transaction of its own, since bolt cannot keep one open while the same goroutine writes.
+func (btx *boltTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	if value, ok := readBuffered(btx.writes, namespace, key); ok {
+		return value, nil
+	}
+	var value []byte
+	err := btx.db.db.View(func(tx *bolt.Tx) error {
+		value = readTx(tx, namespace, key)
+		return nil
+	})
+	return value, err
+}
+
 func (btx *boltTx) Write(_ context.Context, namespace, key string, value []byte) error {
-	return writeFunc(namespace, key, value)(btx.tx)
+	btx.writes = append(btx.writes, bufferedWrite{namespace: namespace, key: key, value: bytes.Clone(value)})
+	return nil
 }
 
-// Execute runs the provided function within a transaction. Any failure during execution results in a rollback.
-// It is recommended to not open transactions within businessLogicFunc, as there are situation in which the interplay
-// between transactions may cause deadlocks.
-func (b *BoltDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, _ []WatchKey) (any, error) {
-	t, err := b.db.Begin(true)
-	if err != nil {
-		return nil, errors.Wrap(err, "beginning transaction")
-	}
+func (btx *boltTx) WriteWithTTL(_ context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	btx.writes = append(btx.writes, bufferedWrite{namespace: namespace, key: key, value: bytes.Clone(value), ttl: ttl})
+	return nil
+}
 
-	bTx := boltTx{tx: t}
-	// Make sure the transaction rolls back in the event of a panic.
-	defer func() {
-		if t.DB() != nil {
-			err = t.Rollback()
-			if err != nil {
-				logrus.Error("unable to roll back")
+func (btx *boltTx) Delete(_ context.Context, namespace, key string) error {
+	btx.writes = append(btx.writes, bufferedWrite{namespace: namespace, key: key, delete: true})
+	return nil
+}
+
+// Execute runs the provided function within a transaction. Writes made through the transaction are buffered, so that
+// businessLogicFunc does not hold bolt's single write lock, and are applied in one write transaction once it returns.
+// The values of the watchKeys are snapshotted before businessLogicFunc runs, and compared again within that write
+// transaction. When any of them changed, the writes are discarded and the function is retried with exponential
+// backoff, mirroring the behavior of the redis implementation.
+func (b *BoltDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
+	var finalOutput any
+	txf := func() error {
+		snapshot := make([][]byte, len(watchKeys))
+		err := b.db.View(func(tx *bolt.Tx) error {
+			for i, wk := range watchKeys {
+				snapshot[i] = readTx(tx, wk.Namespace, wk.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return backoff.Permanent(errors.Wrap(err, "reading watch keys"))
 		}
-	}()
 
-	// If an error is returned from the function then rollback and return error.
-	result, err := businessLogicFunc(ctx, &bTx)
-	if err != nil {
-		if rollbackErr := t.Rollback(); rollbackErr != nil {
-			logrus.Errorf("problem rolling back %s", rollbackErr)
-			return nil, errors.Wrap(rollbackErr, "rolling back transaction")
+		btx := &boltTx{db: b}
+		output, err := businessLogicFunc(ctx, btx)
+		if err != nil {
+			return backoff.Permanent(errors.Wrap(err, "executing business logic func"))
+		}
+
+		err = b.db.Update(func(tx *bolt.Tx) error {
+			for i, wk := range watchKeys {
+				if !bytes.Equal(readTx(tx, wk.Namespace, wk.Key), snapshot[i]) {
+					return errWatchKeyChanged
+				}
+			}
+			for _, w := range btx.writes {
+				if w.delete {
+					if bucket := tx.Bucket([]byte(w.namespace)); bucket != nil {
+						if err := bucket.Delete([]byte(w.key)); err != nil {
+							return err
+						}
+					}
+					if err := clearExpiry(tx, w.namespace, w.key); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := writeWithTTLFunc(w.namespace, w.key, w.value, w.ttl)(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, errWatchKeyChanged) {
+			return err
+		}
+		if err != nil {
+			return backoff.Permanent(errors.Wrap(err, "committing transaction"))
+		}
+		finalOutput = output
+		return nil
+	}
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxElapsedTime = MaxElapsedTime
+
+	err := backoff.Retry(func() error {
+		err := txf()
+		if errors.Is(err, errWatchKeyChanged) {
+			logrus.Warn("Optimistic lock lost. Retrying..")
 		}
-		return nil, errors.Wrap(err, "executing business logic func")
+		return err
+	}, expBackoff)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute after retrying")
 	}
+	return finalOutput, nil
+}
 
-	if err := t.Commit(); err != nil {
-		return nil, errors.Wrap(err, "committing transaction")
+// readTx returns a copy of the value of the key, since values returned by bolt are only valid within the transaction.
+func readTx(tx *bolt.Tx, namespace, key string) []byte {
+	bucket := tx.Bucket([]byte(namespace))
+	if bucket == nil {
+		return nil
 	}
-	return result, nil
+	return bytes.Clone(bucket.Get([]byte(key)))
 }
 
 func (b *BoltDB) Write(_ context.Context, namespace string, key string, value []byte) error {
@@ -152,6 +243,102 @@ func writeFunc(namespace string, key string, value []byte) func(tx *bolt.Tx) err
 	}
 }
 
+func (b *BoltDB) WriteWithTTL(_ context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	return b.db.Update(writeWithTTLFunc(namespace, key, value, ttl))
+}
+
+func writeWithTTLFunc(namespace, key string, value []byte, ttl time.Duration) func(tx *bolt.Tx) error {
+	return func(tx *bolt.Tx) error {
+		if err := writeFunc(namespace, key, value)(tx); err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			return nil
+		}
+		return setExpiry(tx, namespace, key, time.Now().Add(ttl))
+	}
+}
+
+// setExpiry replaces the deadline of the key, if it had one, with the given deadline.
+func setExpiry(tx *bolt.Tx, namespace, key string, deadline time.Time) error {
+	if err := clearExpiry(tx, namespace, key); err != nil {
+		return err
+	}
+	expiryBucket, err := tx.CreateBucketIfNotExists([]byte(boltExpiryBucket))
+	if err != nil {
+		return err
+	}
+	keysBucket, err := tx.CreateBucketIfNotExists([]byte(boltExpiryKeysBucket))
+	if err != nil {
+		return err
+	}
+	expiryKey := boltExpiryKey(namespace, key)
+	deadlineBytes := make([]byte, boltDeadlineSize)
+	binary.BigEndian.PutUint64(deadlineBytes, uint64(deadline.UnixNano()))
+	if err = expiryBucket.Put(append(deadlineBytes, expiryKey...), []byte{}); err != nil {
+		return err
+	}
+	return keysBucket.Put(expiryKey, deadlineBytes)
+}
+
+// clearExpiry removes the deadline of the key, if it has one.
+func clearExpiry(tx *bolt.Tx, namespace, key string) error {
+	keysBucket := tx.Bucket([]byte(boltExpiryKeysBucket))
+	if keysBucket == nil {
+		return nil
+	}
+	expiryKey := boltExpiryKey(namespace, key)
+	deadlineBytes := keysBucket.Get(expiryKey)
+	if deadlineBytes == nil {
+		return nil
+	}
+	if err := tx.Bucket([]byte(boltExpiryBucket)).Delete(append(bytes.Clone(deadlineBytes), expiryKey...)); err != nil {
+		return err
+	}
+	return keysBucket.Delete(expiryKey)
+}
+
+func boltExpiryKey(namespace, key string) []byte {
+	return []byte(namespace + "\x00" + key)
+}
+
+func (b *BoltDB) sweepExpired(_ context.Context, now time.Time) (int, error) {
+	removed := 0
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		expiryBucket := tx.Bucket([]byte(boltExpiryBucket))
+		if expiryBucket == nil {
+			return nil
+		}
+		var expired [][]byte
+		cursor := expiryBucket.Cursor()
+		for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+			if int64(binary.BigEndian.Uint64(k[:boltDeadlineSize])) > now.UnixNano() {
+				break
+			}
+			expired = append(expired, bytes.Clone(k))
+		}
+		keysBucket := tx.Bucket([]byte(boltExpiryKeysBucket))
+		for _, k := range expired {
+			expiryKey := k[boltDeadlineSize:]
+			namespace, key, _ := bytes.Cut(expiryKey, []byte("\x00"))
+			if bucket := tx.Bucket(namespace); bucket != nil {
+				if err := bucket.Delete(key); err != nil {
+					return err
+				}
+			}
+			if err := expiryBucket.Delete(k); err != nil {
+				return err
+			}
+			if err := keysBucket.Delete(expiryKey); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
 func (b *BoltDB) WriteMany(_ context.Context, namespaces, keys []string, values [][]byte) error {
 	if len(namespaces) != len(keys) && len(namespaces) != len(values) {
 		return errors.New("namespaces, keys, and values, are not of equal length")
@@ -221,6 +408,42 @@ func (b *BoltDB) ReadAll(_ context.Context, namespace string) (map[string][]byte
 	return result, err
 }
 
+// ReadPage reads a page of values from the namespace in key order. The page token holds the last key returned.
+func (b *BoltDB) ReadPage(_ context.Context, namespace, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	token, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	result := make(map[string][]byte)
+	var nextPageToken string
+	err = b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			logrus.Warnf("namespace<%s> does not exist", namespace)
+			return nil
+		}
+		cursor := bucket.Cursor()
+		k, v := cursor.First()
+		if token.LastKey != "" {
+			k, v = cursor.Seek([]byte(token.LastKey))
+			if k != nil && string(k) == token.LastKey {
+				k, v = cursor.Next()
+			}
+		}
+		var lastKey string
+		for ; k != nil; k, v = cursor.Next() {
+			if pageSize > 0 && len(result) == pageSize {
+				nextPageToken = pageTokenFromLastKey(lastKey)
+				break
+			}
+			result[string(k)] = v
+			lastKey = string(k)
+		}
+		return nil
+	})
+	return result, nextPageToken, err
+}
+
 func (b *BoltDB) ReadAllKeys(_ context.Context, namespace string) ([]string, error) {
 	var result []string
 	err := b.db.View(func(tx *bolt.Tx) error {
@@ -238,13 +461,30 @@ func (b *BoltDB) ReadAllKeys(_ context.Context, namespace string) ([]string, err
 	return result, err
 }
 
+func (b *BoltDB) Namespaces(_ context.Context) ([]string, error) {
+	var result []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			if string(name) == boltExpiryBucket || string(name) == boltExpiryKeysBucket {
+				return nil
+			}
+			result = append(result, string(name))
+			return nil
+		})
+	})
+	return result, err
+}
+
 func (b *BoltDB) Delete(_ context.Context, namespace, key string) error {
 	return b.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			return sdkutil.LoggingNewErrorf("namespace<%s> does not exist", namespace)
 		}
-		return bucket.Delete([]byte(key))
+		if err := bucket.Delete([]byte(key)); err != nil {
+			return err
+		}
+		return clearExpiry(tx, namespace, key)
 	})
 }
 
@@ -253,6 +493,21 @@ func (b *BoltDB) DeleteNamespace(_ context.Context, namespace string) error {
 		if err := tx.DeleteBucket([]byte(namespace)); err != nil {
 			return sdkutil.LoggingErrorMsgf(err, "could not delete namespace<%s>", namespace)
 		}
+		keysBucket := tx.Bucket([]byte(boltExpiryKeysBucket))
+		if keysBucket == nil {
+			return nil
+		}
+		var keys []string
+		prefix := boltExpiryKey(namespace, "")
+		cursor := keysBucket.Cursor()
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			keys = append(keys, string(k[len(prefix):]))
+		}
+		for _, key := range keys {
+			if err := clearExpiry(tx, namespace, key); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 }
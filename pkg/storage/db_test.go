@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,9 +18,11 @@ import (
 func getDBImplementations(t *testing.T) []ServiceStorage {
 	boltDB := setupBoltDB(t)
 	redisDB := setupRedisDB(t)
+	sqlDB := setupSQLDB(t)
+	memoryDB := setupMemoryDB(t)
 
 	dbImpls := make([]ServiceStorage, 0)
-	dbImpls = append(dbImpls, boltDB, redisDB)
+	dbImpls = append(dbImpls, boltDB, redisDB, sqlDB, memoryDB)
 	return dbImpls
 }
 
@@ -34,6 +39,11 @@ func setupBoltDB(t *testing.T) *BoltDB {
 }
 
 func setupRedisDB(t *testing.T) *RedisDB {
+	db, _ := setupRedisDBWithServer(t)
+	return db
+}
+
+func setupRedisDBWithServer(t *testing.T) (*RedisDB, *miniredis.Miniredis) {
 	server := miniredis.RunT(t)
 	options := make(map[string]interface{})
 	options["address"] = server.Addr()
@@ -47,7 +57,35 @@ func setupRedisDB(t *testing.T) *RedisDB {
 		_ = db.Close()
 	})
 
-	return db.(*RedisDB)
+	return db.(*RedisDB), server
+}
+
+func setupSQLDB(t *testing.T) *SQLDB {
+	options := map[string]interface{}{
+		SQLDriverOption: SQLiteDriver,
+		SQLDSNOption:    filepath.Join(t.TempDir(), "test.sqlite"),
+	}
+	db, err := NewStorage(SQL, options)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, db)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db.(*SQLDB)
+}
+
+func setupMemoryDB(t *testing.T) *MemoryDB {
+	db, err := NewStorage(Memory, nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, db)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db.(*MemoryDB)
 }
 
 func TestDB(t *testing.T) {
@@ -216,6 +254,133 @@ func TestDBReadAllKeysForNamespace(t *testing.T) {
 	}
 }
 
+func TestDBNamespaces(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+
+		namespaces, err := db.Namespaces(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, namespaces)
+
+		dummyData := []byte("dummy")
+		err = db.Write(context.Background(), "blockchains-orange", "bitcoin-testnet", dummyData)
+		assert.NoError(t, err)
+		err = db.Write(context.Background(), "blockchains-grey", "eth-testnet", dummyData)
+		assert.NoError(t, err)
+		err = db.Write(context.Background(), "blockchains-grey", "eth-mainnet", dummyData)
+		assert.NoError(t, err)
+
+		namespaces, err = db.Namespaces(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"blockchains-grey", "blockchains-orange"}, namespaces)
+	}
+}
+
+func TestDBReadPage(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+
+		namespace := "drivers"
+		otherNamespace := "drivers-retired"
+		for i := 0; i < 7; i++ {
+			err := db.Write(context.Background(), namespace, fmt.Sprintf("driver-%d", i), []byte(fmt.Sprintf("%d", i)))
+			assert.NoError(t, err)
+		}
+		err := db.Write(context.Background(), otherNamespace, "driver-99", []byte("99"))
+		assert.NoError(t, err)
+
+		// page through the namespace
+		all := make(map[string][]byte)
+		pageToken := ""
+		pages := 0
+		for {
+			page, nextPageToken, err := db.ReadPage(context.Background(), namespace, pageToken, 3)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(page), 3)
+			for k, v := range page {
+				_, seen := all[k]
+				assert.False(t, seen, "key %s returned twice", k)
+				all[k] = v
+			}
+			pages++
+			if nextPageToken == "" {
+				break
+			}
+			pageToken = nextPageToken
+		}
+		assert.Len(t, all, 7)
+		assert.Equal(t, []byte("3"), all["driver-3"])
+		assert.GreaterOrEqual(t, pages, 3)
+
+		// no page size returns everything
+		page, nextPageToken, err := db.ReadPage(context.Background(), namespace, "", 0)
+		assert.NoError(t, err)
+		assert.Len(t, page, 7)
+		assert.Empty(t, nextPageToken)
+
+		// empty namespace
+		page, nextPageToken, err = db.ReadPage(context.Background(), "dne", "", 3)
+		assert.NoError(t, err)
+		assert.Len(t, page, 0)
+		assert.Empty(t, nextPageToken)
+
+		// bad token
+		_, _, err = db.ReadPage(context.Background(), namespace, "not-a-token", 3)
+		assert.Error(t, err)
+	}
+}
+
+func TestPaginateKeys(t *testing.T) {
+	keys := []string{"e", "c", "a", "d", "b"}
+
+	page, nextPageToken, err := PaginateKeys(keys, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, page)
+	assert.NotEmpty(t, nextPageToken)
+
+	page, nextPageToken, err = PaginateKeys(keys, nextPageToken, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, page)
+	assert.NotEmpty(t, nextPageToken)
+
+	page, nextPageToken, err = PaginateKeys(keys, nextPageToken, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e"}, page)
+	assert.Empty(t, nextPageToken)
+
+	page, nextPageToken, err = PaginateKeys(keys, "", 0)
+	assert.NoError(t, err)
+	assert.Len(t, page, 5)
+	assert.Empty(t, nextPageToken)
+}
+
+func TestPaginateOffset(t *testing.T) {
+	start, end, nextPageToken, err := PaginateOffset(5, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, []int{start, end})
+	assert.NotEmpty(t, nextPageToken)
+
+	start, end, nextPageToken, err = PaginateOffset(5, nextPageToken, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, []int{start, end})
+	assert.NotEmpty(t, nextPageToken)
+
+	start, end, nextPageToken, err = PaginateOffset(5, nextPageToken, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, []int{start, end})
+	assert.Empty(t, nextPageToken)
+
+	// values removed in between calls shorten the last page
+	_, _, nextPageToken, err = PaginateOffset(5, "", 4)
+	assert.NoError(t, err)
+	start, end, _, err = PaginateOffset(3, nextPageToken, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 3}, []int{start, end})
+
+	_, _, _, err = PaginateOffset(5, "bad", 2)
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
 func TestDBEmptyNamespace(t *testing.T) {
 	for _, dbImpl := range getDBImplementations(t) {
 		db := dbImpl
@@ -505,3 +670,325 @@ func TestDB_UpdatedSubmissionAndOperationTxFn(t *testing.T) {
 		}
 	}
 }
+
+func TestDB_Execute(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		namespace := "execute"
+
+		// a successful business logic func commits its writes
+		result, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+			return "done", tx.Write(ctx, namespace, "committed", []byte("value"))
+		}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "done", result)
+
+		value, err := db.Read(context.Background(), namespace, "committed")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+
+		// a failing business logic func rolls back its writes
+		_, err = db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+			if err := tx.Write(ctx, namespace, "rolled-back", []byte("value")); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("failure")
+		}, nil)
+		assert.Error(t, err)
+
+		exists, err := db.Exists(context.Background(), namespace, "rolled-back")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	}
+}
+
+func TestDB_ExecuteReadsWithinTransaction(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		namespace := "execute-read"
+		require.NoError(t, db.Write(context.Background(), namespace, "existing", []byte("before")))
+
+		_, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+			value, err := tx.Read(ctx, namespace, "existing")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("before"), value)
+
+			value, err = tx.Read(ctx, namespace, "missing")
+			require.NoError(t, err)
+			assert.Nil(t, value)
+
+			// reads reflect the writes made earlier in the transaction
+			require.NoError(t, tx.Write(ctx, namespace, "existing", []byte("after")))
+			value, err = tx.Read(ctx, namespace, "existing")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("after"), value)
+
+			require.NoError(t, tx.Delete(ctx, namespace, "existing"))
+			value, err = tx.Read(ctx, namespace, "existing")
+			require.NoError(t, err)
+			assert.Nil(t, value)
+			return nil, tx.Write(ctx, namespace, "new", []byte("value"))
+		}, nil)
+		require.NoError(t, err)
+
+		exists, err := db.Exists(context.Background(), namespace, "existing")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		value, err := db.Read(context.Background(), namespace, "new")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+	}
+}
+
+func TestDB_ExecuteRetriesWhenWatchKeyChanges(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		namespace := "watched"
+		require.NoError(t, db.Write(context.Background(), namespace, "counter", []byte("0")))
+
+		calls := 0
+		_, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+			calls++
+			if calls == 1 {
+				// simulate a concurrent writer modifying the watched key
+				if err := db.Write(ctx, namespace, "counter", []byte("1")); err != nil {
+					return nil, err
+				}
+			}
+			return nil, tx.Write(ctx, namespace, "counter", []byte("2"))
+		}, []WatchKey{{Namespace: namespace, Key: "counter"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+
+		value, err := db.Read(context.Background(), namespace, "counter")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("2"), value)
+	}
+}
+
+func TestDBIndexes(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		namespace := "indexed"
+
+		// values may contain the characters used to build index keys
+		issuerA := "did:web:my-issuer.com/a"
+		issuerB := "did:key:z6Mk-issuer"
+		require.NoError(t, WriteIndexed(ctx, db, namespace, "cred-1", []byte("1"),
+			NewIndexEntry("issuer", issuerA), NewIndexEntry("issuer-schema", issuerA, "schema-1")))
+		require.NoError(t, WriteIndexed(ctx, db, namespace, "cred-2", []byte("2"),
+			NewIndexEntry("issuer", issuerA), NewIndexEntry("issuer-schema", issuerA, "schema-2")))
+		require.NoError(t, WriteIndexed(ctx, db, namespace, "cred-3", []byte("3"),
+			NewIndexEntry("issuer", issuerB), NewIndexEntry("issuer-schema", issuerB, "schema-1")))
+
+		keys, err := ReadIndex(ctx, db, namespace, "issuer", issuerA)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cred-1", "cred-2"}, keys)
+
+		// a value which is a prefix of an indexed value does not match
+		keys, err = ReadIndex(ctx, db, namespace, "issuer", "did:web:my-issuer.com")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		keys, err = ReadIndex(ctx, db, namespace, "issuer-schema", issuerA, "schema-1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cred-1"}, keys)
+
+		// rewriting a record replaces its index entries
+		require.NoError(t, WriteIndexed(ctx, db, namespace, "cred-1", []byte("1"), NewIndexEntry("issuer", issuerB)))
+		keys, err = ReadIndex(ctx, db, namespace, "issuer", issuerA)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cred-2"}, keys)
+		keys, err = ReadIndex(ctx, db, namespace, "issuer", issuerB)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cred-1", "cred-3"}, keys)
+		keys, err = ReadIndex(ctx, db, namespace, "issuer-schema", issuerA, "schema-1")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		// the records themselves are unaffected by their index entries
+		allKeys, err := db.ReadAllKeys(ctx, namespace)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"cred-1", "cred-2", "cred-3"}, allKeys)
+
+		// deleting a record deletes its index entries
+		require.NoError(t, DeleteIndexed(ctx, db, namespace, "cred-3"))
+		keys, err = ReadIndex(ctx, db, namespace, "issuer", issuerB)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cred-1"}, keys)
+		exists, err := db.Exists(ctx, namespace, "cred-3")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		// index names can't contain the key separator
+		err = WriteIndexed(ctx, db, namespace, "cred-4", []byte("4"), NewIndexEntry("bad/name", "value"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid index name")
+	}
+}
+
+func TestDB_UpdateIndexedValueAndOperation(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		namespace := "indexed-simple"
+		opNamespace := "operation"
+		statusIndexFunc := func(value []byte) ([]IndexEntry, error) {
+			var s testStruct
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, err
+			}
+			return []IndexEntry{NewIndexEntry("status", fmt.Sprint(s.Status))}, nil
+		}
+
+		data, err := json.Marshal(testStruct{Status: 0})
+		require.NoError(t, err)
+		require.NoError(t, WriteIndexed(ctx, db, namespace, "123", data, NewIndexEntry("status", "0")))
+		data, err = json.Marshal(operation{Done: false})
+		require.NoError(t, err)
+		require.NoError(t, db.Write(ctx, opNamespace, "op123", data))
+
+		gotFirstData, gotOpData, err := UpdateIndexedValueAndOperation(ctx, db, namespace, "123",
+			NewUpdater(map[string]any{"status": 1, "reason": "hello"}), statusIndexFunc,
+			opNamespace, "op123", testOpUpdater{NewUpdater(map[string]any{"done": true})})
+		require.NoError(t, err)
+
+		var gotFirst testStruct
+		assert.NoError(t, json.Unmarshal(gotFirstData, &gotFirst))
+		assert.Equal(t, testStruct{Status: 1, Reason: "hello"}, gotFirst)
+		var gotOp operation
+		assert.NoError(t, json.Unmarshal(gotOpData, &gotOp))
+		assert.True(t, gotOp.Done)
+		assert.JSONEq(t, string(gotFirstData), string(gotOp.Response))
+
+		// the index entries follow the updated value
+		keys, err := ReadIndex(ctx, db, namespace, "status", "0")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+		keys, err = ReadIndex(ctx, db, namespace, "status", "1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"123"}, keys)
+
+		// nothing is written when the update fails
+		_, _, err = UpdateIndexedValueAndOperation(ctx, db, namespace, "123",
+			NewUpdater(map[string]any{"status": 2}), statusIndexFunc,
+			opNamespace, "crazy key", testOpUpdater{NewUpdater(map[string]any{"done": true})})
+		assert.Error(t, err)
+		keys, err = ReadIndex(ctx, db, namespace, "status", "2")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+	}
+}
+
+// expiringDB is a storage along with a function which moves its clock forward by the given duration, and removes the
+// values which expired in the meantime.
+type expiringDB struct {
+	ServiceStorage
+	fastForward func(d time.Duration)
+}
+
+func getExpiringDBImplementations(t *testing.T) []expiringDB {
+	redisDB, server := setupRedisDBWithServer(t)
+	dbs := []expiringDB{{ServiceStorage: redisDB, fastForward: server.FastForward}}
+	for _, db := range []ServiceStorage{setupBoltDB(t), setupSQLDB(t), setupMemoryDB(t)} {
+		sweeper := db.(expirySweeper)
+		dbs = append(dbs, expiringDB{ServiceStorage: db, fastForward: func(d time.Duration) {
+			_, err := sweeper.sweepExpired(context.Background(), time.Now().Add(d))
+			require.NoError(t, err)
+		}})
+	}
+	return dbs
+}
+
+func TestDB_WriteWithTTL(t *testing.T) {
+	for _, dbImpl := range getExpiringDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		namespace := "expiring"
+
+		require.NoError(t, db.WriteWithTTL(ctx, namespace, "expired", []byte("1"), time.Hour))
+		require.NoError(t, db.WriteWithTTL(ctx, namespace, "later", []byte("2"), 3*time.Hour))
+		require.NoError(t, db.Write(ctx, namespace, "kept", []byte("3")))
+		require.NoError(t, db.WriteWithTTL(ctx, namespace, "no-ttl", []byte("4"), 0))
+
+		// writing again keeps the expiry, while deleting clears it
+		require.NoError(t, db.WriteWithTTL(ctx, namespace, "rewritten", []byte("5"), time.Hour))
+		require.NoError(t, db.Write(ctx, namespace, "rewritten", []byte("6")))
+		require.NoError(t, db.WriteWithTTL(ctx, namespace, "deleted", []byte("7"), time.Hour))
+		require.NoError(t, db.Delete(ctx, namespace, "deleted"))
+		require.NoError(t, db.Write(ctx, namespace, "deleted", []byte("8")))
+
+		_, err := db.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+			return nil, tx.WriteWithTTL(ctx, namespace, "transaction", []byte("9"), time.Hour)
+		}, nil)
+		require.NoError(t, err)
+
+		keys, err := db.ReadAllKeys(ctx, namespace)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 7)
+
+		db.fastForward(2 * time.Hour)
+		keys, err = db.ReadAllKeys(ctx, namespace)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"later", "kept", "no-ttl", "deleted"}, keys, db.Type())
+
+		// the bookkeeping of expiring keys is not exposed as a namespace
+		namespaces, err := db.Namespaces(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{namespace}, namespaces)
+	}
+}
+
+func TestDB_WriteIndexedWithTTL(t *testing.T) {
+	for _, dbImpl := range getExpiringDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		namespace := "expiring-indexed"
+		opNamespace := "operation"
+		statusIndexFunc := func(value []byte) ([]IndexEntry, error) {
+			var s testStruct
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, err
+			}
+			return []IndexEntry{NewIndexEntry("status", fmt.Sprint(s.Status))}, nil
+		}
+
+		data, err := json.Marshal(testStruct{Status: 0})
+		require.NoError(t, err)
+		require.NoError(t, WriteIndexedWithTTL(ctx, db.ServiceStorage, namespace, "123", data, time.Hour, NewIndexEntry("status", "0")))
+		require.NoError(t, WriteIndexed(ctx, db.ServiceStorage, namespace, "456", data, NewIndexEntry("status", "0")))
+		data, err = json.Marshal(operation{Done: false})
+		require.NoError(t, err)
+		require.NoError(t, db.WriteWithTTL(ctx, opNamespace, "op123", data, time.Hour))
+
+		// the entry added by the update expires along with the value
+		_, _, err = UpdateIndexedValueAndOperation(ctx, db.ServiceStorage, namespace, "123",
+			NewUpdater(map[string]any{"status": 1}), statusIndexFunc,
+			opNamespace, "op123", testOpUpdater{NewUpdater(map[string]any{"done": true})})
+		require.NoError(t, err)
+		keys, err := ReadIndex(ctx, db.ServiceStorage, namespace, "status", "1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"123"}, keys)
+
+		db.fastForward(2 * time.Hour)
+		keys, err = ReadIndex(ctx, db.ServiceStorage, namespace, "status", "1")
+		assert.NoError(t, err)
+		assert.Empty(t, keys, db.Type())
+		keys, err = ReadIndex(ctx, db.ServiceStorage, namespace, "status", "0")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"456"}, keys)
+		exists, err := db.Exists(ctx, namespace, "123")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		exists, err = db.Exists(ctx, opNamespace, "op123")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		indexKeys, err := db.ReadAllKeys(ctx, getIndexNamespace(namespace))
+		assert.NoError(t, err)
+		for _, key := range indexKeys {
+			assert.NotContains(t, key, "123")
+		}
+	}
+}
@@ -0,0 +1,574 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/tbd54566975/ssi-service/internal/util"
+)
+
+// Encrypted namespaces use envelope encryption: each namespace has its own data keys, which encrypt its values, and the
+// data keys are themselves stored encrypted with a key derived from a password. Changing the password only requires
+// re-encrypting the data keys, and rotating a data key only affects the values written after the rotation.
+//
+// Encrypted values are stored as
+//
+//	encryptedValuePrefix || data key version (4 bytes, big endian) || XChaCha20-Poly1305 nonce and ciphertext
+//
+// with namespace || "/" || key as additional data, so that a value moved to another key or namespace of the wrapped
+// storage fails to decrypt. Values without the prefix were written before the namespace was encrypted, and are not read
+// until EncryptExistingValues has encrypted them, so that a value written to the wrapped storage is not trusted as
+// plaintext.
+const (
+	encryptionKeysNamespace = "encryption-keys"
+	encryptedValuePrefix    = "\x00enc"
+	dataKeyVersionSize      = 4
+)
+
+// EncryptionPassword is a password from which the key that encrypts data keys is derived. The ID is stored alongside
+// each encrypted data key, so that passwords may be rotated.
+type EncryptionPassword struct {
+	ID       string
+	Password string
+}
+
+type wrappedDataKey struct {
+	Version    uint32 `json:"version"`
+	PasswordID string `json:"passwordId"`
+	Salt       []byte `json:"salt"`
+	Key        []byte `json:"key"`
+}
+
+type storedDataKeys struct {
+	Current uint32           `json:"current"`
+	Keys    []wrappedDataKey `json:"keys"`
+}
+
+type dataKeys struct {
+	current uint32
+	keys    map[uint32][]byte
+}
+
+// EncryptedStorage is a ServiceStorage which encrypts the values of a set of namespaces, and of their secondary
+// indexes, before handing them to the storage it wraps. Keys are not encrypted. All other namespaces, and the methods
+// which do not handle values, are passed through unchanged.
+type EncryptedStorage struct {
+	ServiceStorage
+
+	namespaces map[string]bool
+	current    EncryptionPassword
+	salt       []byte
+	passwords  map[string]string
+
+	mu          sync.RWMutex
+	dataKeys    map[string]*dataKeys
+	derivedKeys map[string][]byte
+}
+
+// NewEncryptedStorage wraps db so that the values of the given namespaces are encrypted. New data keys are encrypted
+// with the current password. Data keys which were encrypted with one of the previous passwords are re-encrypted with
+// the current one, after which the previous passwords are no longer needed.
+func NewEncryptedStorage(ctx context.Context, db ServiceStorage, namespaces []string, current EncryptionPassword, previous ...EncryptionPassword) (*EncryptedStorage, error) {
+	if current.ID == "" || current.Password == "" {
+		return nil, errors.New("encryption password and its id cannot be empty")
+	}
+	salt, err := util.GenerateSalt(util.Argon2SaltSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating salt for encryption password")
+	}
+	e := &EncryptedStorage{
+		ServiceStorage: db,
+		namespaces:     make(map[string]bool, len(namespaces)),
+		current:        current,
+		salt:           salt,
+		passwords:      map[string]string{current.ID: current.Password},
+		dataKeys:       make(map[string]*dataKeys, len(namespaces)),
+		derivedKeys:    make(map[string][]byte),
+	}
+	for _, p := range previous {
+		if _, ok := e.passwords[p.ID]; ok {
+			return nil, errors.Errorf("duplicate encryption password id<%s>", p.ID)
+		}
+		e.passwords[p.ID] = p.Password
+	}
+	for _, namespace := range namespaces {
+		if namespace == "" || namespace == encryptionKeysNamespace {
+			return nil, errors.Errorf("namespace<%s> cannot be encrypted", namespace)
+		}
+		e.namespaces[namespace] = true
+	}
+	for namespace := range e.namespaces {
+		if err = e.loadDataKeys(ctx, namespace, false); err != nil {
+			return nil, errors.Wrapf(err, "loading data keys of namespace<%s>", namespace)
+		}
+	}
+	return e, nil
+}
+
+// RotateDataKey creates a new data key for the namespace, which is used for all the values written from then on.
+// Values written before the rotation remain readable. Other instances of the service pick up the new data key the
+// first time they read a value encrypted with it, so they must have been started with the current password.
+func (e *EncryptedStorage) RotateDataKey(ctx context.Context, namespace string) error {
+	if !e.namespaces[namespace] {
+		return errors.Errorf("namespace<%s> is not encrypted", namespace)
+	}
+	return e.loadDataKeys(ctx, namespace, true)
+}
+
+// EncryptExistingValues encrypts the values of the encrypted namespaces, and of their indexes and tenant copies, which
+// were written before the namespace was encrypted, and returns how many there were. In a dry run nothing is written.
+func (e *EncryptedStorage) EncryptExistingValues(ctx context.Context, dryRun bool) (int, error) {
+	namespaces, err := e.Namespaces(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "listing namespaces")
+	}
+	encrypted := 0
+	for _, namespace := range namespaces {
+		if _, ok := e.dataKeysNamespace(namespace); !ok {
+			continue
+		}
+		values, err := e.ServiceStorage.ReadAll(ctx, namespace)
+		if err != nil {
+			return 0, errors.Wrapf(err, "reading namespace<%s>", namespace)
+		}
+		for key, value := range values {
+			if len(value) == 0 || bytes.HasPrefix(value, []byte(encryptedValuePrefix)) {
+				continue
+			}
+			encrypted++
+			if dryRun {
+				continue
+			}
+			if err = e.encryptExistingValue(ctx, namespace, key); err != nil {
+				return 0, errors.Wrapf(err, "encrypting value of key<%s> in namespace<%s>", key, namespace)
+			}
+		}
+	}
+	return encrypted, nil
+}
+
+// encryptExistingValue encrypts the value of the key unless it was encrypted since it was read, e.g. by an instance of
+// the service writing to it.
+func (e *EncryptedStorage) encryptExistingValue(ctx context.Context, namespace, key string) error {
+	_, err := e.ServiceStorage.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		value, err := tx.Read(ctx, namespace, key)
+		if err != nil || len(value) == 0 || bytes.HasPrefix(value, []byte(encryptedValuePrefix)) {
+			return nil, err
+		}
+		encrypted, err := e.encrypt(namespace, key, value)
+		if err != nil {
+			return nil, err
+		}
+		return nil, tx.Write(ctx, namespace, key, encrypted)
+	}, []WatchKey{{Namespace: namespace, Key: key}})
+	return err
+}
+
+func (e *EncryptedStorage) Write(ctx context.Context, namespace, key string, value []byte) error {
+	encrypted, err := e.encrypt(namespace, key, value)
+	if err != nil {
+		return err
+	}
+	return e.ServiceStorage.Write(ctx, namespace, key, encrypted)
+}
+
+func (e *EncryptedStorage) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	encrypted, err := e.encrypt(namespace, key, value)
+	if err != nil {
+		return err
+	}
+	return e.ServiceStorage.WriteWithTTL(ctx, namespace, key, encrypted, ttl)
+}
+
+func (e *EncryptedStorage) WriteMany(ctx context.Context, namespaces, keys []string, values [][]byte) error {
+	if len(namespaces) != len(keys) || len(namespaces) != len(values) {
+		return errors.New("namespaces, keys and values must be the same length")
+	}
+	encrypted := make([][]byte, len(values))
+	for i := range values {
+		var err error
+		if encrypted[i], err = e.encrypt(namespaces[i], keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return e.ServiceStorage.WriteMany(ctx, namespaces, keys, encrypted)
+}
+
+func (e *EncryptedStorage) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	value, err := e.ServiceStorage.Read(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	return e.decrypt(ctx, namespace, key, value)
+}
+
+func (e *EncryptedStorage) ReadAll(ctx context.Context, namespace string) (map[string][]byte, error) {
+	values, err := e.ServiceStorage.ReadAll(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return e.decryptAll(ctx, namespace, values)
+}
+
+func (e *EncryptedStorage) ReadPage(ctx context.Context, namespace, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	values, nextPageToken, err := e.ServiceStorage.ReadPage(ctx, namespace, pageToken, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	decrypted, err := e.decryptAll(ctx, namespace, values)
+	if err != nil {
+		return nil, "", err
+	}
+	return decrypted, nextPageToken, nil
+}
+
+func (e *EncryptedStorage) ReadPrefix(ctx context.Context, namespace, prefix string) (map[string][]byte, error) {
+	values, err := e.ServiceStorage.ReadPrefix(ctx, namespace, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return e.decryptAll(ctx, namespace, values)
+}
+
+// Namespaces leaves out the namespace holding the data keys, which only has meaning for this storage.
+func (e *EncryptedStorage) Namespaces(ctx context.Context) ([]string, error) {
+	namespaces, err := e.ServiceStorage.Namespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if namespace != encryptionKeysNamespace {
+			result = append(result, namespace)
+		}
+	}
+	return result, nil
+}
+
+// Update is performed by the wrapper, since the wrapped storage cannot read the JSON of encrypted values.
+func (e *EncryptedStorage) Update(ctx context.Context, namespace string, key string, values map[string]any) ([]byte, error) {
+	if _, ok := e.dataKeysNamespace(namespace); !ok {
+		return e.ServiceStorage.Update(ctx, namespace, key, values)
+	}
+	result, err := e.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		data, err := applyUpdater(ctx, tx, namespace, key, NewUpdater(values))
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, namespace, key, data); err != nil {
+			return nil, errors.Wrap(err, "writing to db")
+		}
+		return data, nil
+	}, []WatchKey{{Namespace: namespace, Key: key}})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+// UpdateValueAndOperation is performed by the wrapper when either namespace is encrypted, for the same reason as Update.
+func (e *EncryptedStorage) UpdateValueAndOperation(ctx context.Context, namespace, key string, updater Updater, opNamespace, opKey string, opUpdater ResponseSettingUpdater) (first, op []byte, err error) {
+	_, encrypted := e.dataKeysNamespace(namespace)
+	_, opEncrypted := e.dataKeysNamespace(opNamespace)
+	if !encrypted && !opEncrypted {
+		return e.ServiceStorage.UpdateValueAndOperation(ctx, namespace, key, updater, opNamespace, opKey, opUpdater)
+	}
+	type updated struct {
+		first, op []byte
+	}
+	result, err := e.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		firstData, err := applyUpdater(ctx, tx, namespace, key, updater)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, namespace, key, firstData); err != nil {
+			return nil, errors.Wrap(err, "writing to db")
+		}
+		opUpdater.SetUpdatedResponse(firstData)
+		opData, err := applyUpdater(ctx, tx, opNamespace, opKey, opUpdater)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, opNamespace, opKey, opData); err != nil {
+			return nil, errors.Wrap(err, "writing operation")
+		}
+		return updated{first: firstData, op: opData}, nil
+	}, []WatchKey{{Namespace: namespace, Key: key}, {Namespace: opNamespace, Key: opKey}})
+	if err != nil {
+		return nil, nil, err
+	}
+	u := result.(updated)
+	return u.first, u.op, nil
+}
+
+func (e *EncryptedStorage) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
+	return e.ServiceStorage.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		return businessLogicFunc(ctx, &encryptedTx{tx: tx, storage: e})
+	}, watchKeys)
+}
+
+type encryptedTx struct {
+	tx      Tx
+	storage *EncryptedStorage
+}
+
+func (t *encryptedTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	value, err := t.tx.Read(ctx, namespace, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return t.storage.decrypt(ctx, namespace, key, value)
+}
+
+func (t *encryptedTx) Write(ctx context.Context, namespace, key string, value []byte) error {
+	encrypted, err := t.storage.encrypt(namespace, key, value)
+	if err != nil {
+		return err
+	}
+	return t.tx.Write(ctx, namespace, key, encrypted)
+}
+
+func (t *encryptedTx) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	encrypted, err := t.storage.encrypt(namespace, key, value)
+	if err != nil {
+		return err
+	}
+	return t.tx.WriteWithTTL(ctx, namespace, key, encrypted, ttl)
+}
+
+func (t *encryptedTx) Delete(ctx context.Context, namespace, key string) error {
+	return t.tx.Delete(ctx, namespace, key)
+}
+
+// dataKeysNamespace returns the namespace whose data keys encrypt the values of the given namespace. Index namespaces
+// use the data keys of the namespace they index, and the namespaces of tenants those of the namespace they are a copy
+// of.
+func (e *EncryptedStorage) dataKeysNamespace(namespace string) (string, bool) {
+	_, namespace = SplitTenantNamespace(namespace)
+	if e.namespaces[namespace] {
+		return namespace, true
+	}
+	indexed := strings.TrimPrefix(namespace, getIndexNamespace(""))
+	if indexed != namespace && e.namespaces[indexed] {
+		return indexed, true
+	}
+	return "", false
+}
+
+func (e *EncryptedStorage) encrypt(namespace, key string, value []byte) ([]byte, error) {
+	keysNamespace, ok := e.dataKeysNamespace(namespace)
+	if !ok {
+		return value, nil
+	}
+	e.mu.RLock()
+	keys := e.dataKeys[keysNamespace]
+	e.mu.RUnlock()
+
+	ciphertext, err := util.XChaCha20Poly1305EncryptWithAAD(keys.keys[keys.current], value, additionalData(namespace, key))
+	if err != nil {
+		return nil, errors.Wrapf(err, "encrypting value in namespace<%s>", namespace)
+	}
+	encrypted := make([]byte, 0, len(encryptedValuePrefix)+dataKeyVersionSize+len(ciphertext))
+	encrypted = append(encrypted, encryptedValuePrefix...)
+	encrypted = binary.BigEndian.AppendUint32(encrypted, keys.current)
+	return append(encrypted, ciphertext...), nil
+}
+
+func (e *EncryptedStorage) decrypt(ctx context.Context, namespace, key string, value []byte) ([]byte, error) {
+	keysNamespace, ok := e.dataKeysNamespace(namespace)
+	if !ok || len(value) == 0 {
+		return value, nil
+	}
+	if !bytes.HasPrefix(value, []byte(encryptedValuePrefix)) {
+		return nil, errors.Errorf("value of key<%s> in namespace<%s> is not encrypted", key, namespace)
+	}
+	if len(value) < len(encryptedValuePrefix)+dataKeyVersionSize {
+		return nil, errors.Errorf("encrypted value in namespace<%s> is too short", namespace)
+	}
+	version := binary.BigEndian.Uint32(value[len(encryptedValuePrefix):])
+	dataKey, err := e.getDataKey(ctx, keysNamespace, version)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := util.XChaCha20Poly1305DecryptWithAAD(dataKey, value[len(encryptedValuePrefix)+dataKeyVersionSize:], additionalData(namespace, key))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting value of key<%s> in namespace<%s>", key, namespace)
+	}
+	return decrypted, nil
+}
+
+// additionalData is the additional data the value of the key is encrypted with, which binds it to the key.
+func additionalData(namespace, key string) []byte {
+	return []byte(namespace + "/" + key)
+}
+
+func (e *EncryptedStorage) decryptAll(ctx context.Context, namespace string, values map[string][]byte) (map[string][]byte, error) {
+	if _, ok := e.dataKeysNamespace(namespace); !ok {
+		return values, nil
+	}
+	decrypted := make(map[string][]byte, len(values))
+	for k, v := range values {
+		d, err := e.decrypt(ctx, namespace, k, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "key<%s>", k)
+		}
+		decrypted[k] = d
+	}
+	return decrypted, nil
+}
+
+// getDataKey returns the data key with the given version, reloading the data keys of the namespace when the version
+// is unknown, as it may have been created by another instance of the service.
+func (e *EncryptedStorage) getDataKey(ctx context.Context, namespace string, version uint32) ([]byte, error) {
+	e.mu.RLock()
+	key, ok := e.dataKeys[namespace].keys[version]
+	e.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if err := e.loadDataKeys(ctx, namespace, false); err != nil {
+		return nil, errors.Wrapf(err, "reloading data keys of namespace<%s>", namespace)
+	}
+	e.mu.RLock()
+	key, ok = e.dataKeys[namespace].keys[version]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("data key version<%d> of namespace<%s> not found", version, namespace)
+	}
+	return key, nil
+}
+
+// loadDataKeys reads the data keys of the namespace into memory, creating the first one if there are none. When rotate
+// is set, a new data key is created and made current. Data keys encrypted with a previous password are re-encrypted
+// with the current password.
+func (e *EncryptedStorage) loadDataKeys(ctx context.Context, namespace string, rotate bool) error {
+	result, err := e.ServiceStorage.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		storedBytes, err := tx.Read(ctx, encryptionKeysNamespace, namespace)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading data keys")
+		}
+		var stored storedDataKeys
+		if len(storedBytes) != 0 {
+			if err = json.Unmarshal(storedBytes, &stored); err != nil {
+				return nil, errors.Wrap(err, "unmarshalling data keys")
+			}
+		}
+
+		e.mu.RLock()
+		cached := e.dataKeys[namespace]
+		e.mu.RUnlock()
+		loaded := &dataKeys{current: stored.Current, keys: make(map[uint32][]byte, len(stored.Keys)+1)}
+		changed := false
+		for i, wrapped := range stored.Keys {
+			if cached != nil && cached.keys[wrapped.Version] != nil && wrapped.PasswordID == e.current.ID {
+				loaded.keys[wrapped.Version] = cached.keys[wrapped.Version]
+				continue
+			}
+			key, err := e.unwrapDataKey(wrapped)
+			if err != nil {
+				return nil, err
+			}
+			loaded.keys[wrapped.Version] = key
+			if wrapped.PasswordID != e.current.ID {
+				if stored.Keys[i], err = e.wrapDataKey(wrapped.Version, key); err != nil {
+					return nil, err
+				}
+				changed = true
+			}
+		}
+
+		if rotate || len(stored.Keys) == 0 {
+			version := stored.Current + 1
+			key := make([]byte, chacha20poly1305.KeySize)
+			if _, err = rand.Read(key); err != nil {
+				return nil, errors.Wrap(err, "generating data key")
+			}
+			wrapped, err := e.wrapDataKey(version, key)
+			if err != nil {
+				return nil, err
+			}
+			stored.Keys = append(stored.Keys, wrapped)
+			stored.Current = version
+			loaded.keys[version] = key
+			loaded.current = version
+			changed = true
+		}
+
+		if changed {
+			storedBytes, err = json.Marshal(stored)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshalling data keys")
+			}
+			if err = tx.Write(ctx, encryptionKeysNamespace, namespace, storedBytes); err != nil {
+				return nil, errors.Wrap(err, "writing data keys")
+			}
+		}
+		return loaded, nil
+	}, []WatchKey{{Namespace: encryptionKeysNamespace, Key: namespace}})
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.dataKeys[namespace] = result.(*dataKeys)
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *EncryptedStorage) wrapDataKey(version uint32, key []byte) (wrappedDataKey, error) {
+	keyEncryptionKey, err := e.deriveKey(e.current.ID, e.salt)
+	if err != nil {
+		return wrappedDataKey{}, err
+	}
+	wrapped, err := util.XChaCha20Poly1305Encrypt(keyEncryptionKey, key)
+	if err != nil {
+		return wrappedDataKey{}, errors.Wrapf(err, "encrypting data key version<%d>", version)
+	}
+	return wrappedDataKey{Version: version, PasswordID: e.current.ID, Salt: e.salt, Key: wrapped}, nil
+}
+
+func (e *EncryptedStorage) unwrapDataKey(wrapped wrappedDataKey) ([]byte, error) {
+	keyEncryptionKey, err := e.deriveKey(wrapped.PasswordID, wrapped.Salt)
+	if err != nil {
+		return nil, err
+	}
+	key, err := util.XChaCha20Poly1305Decrypt(keyEncryptionKey, wrapped.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting data key version<%d> with password<%s>", wrapped.Version, wrapped.PasswordID)
+	}
+	return key, nil
+}
+
+// deriveKey derives the key encryption key of a password and salt, caching it since derivation is deliberately slow.
+func (e *EncryptedStorage) deriveKey(passwordID string, salt []byte) ([]byte, error) {
+	cacheKey := passwordID + indexKeySeparator + base64.RawURLEncoding.EncodeToString(salt)
+	e.mu.RLock()
+	key, ok := e.derivedKeys[cacheKey]
+	e.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	password, ok := e.passwords[passwordID]
+	if !ok {
+		return nil, errors.Errorf("encryption password<%s> is not configured", passwordID)
+	}
+	key, err := util.Argon2KeyGen(password, salt, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, errors.Wrapf(err, "deriving key of encryption password<%s>", passwordID)
+	}
+	e.mu.Lock()
+	e.derivedKeys[cacheKey] = key
+	e.mu.Unlock()
+	return key, nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedStorage(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+
+		// a value written before encryption was enabled
+		legacy := []byte(`{"name":"legacy"}`)
+		require.NoError(t, db.Write(ctx, "secret", "legacy", legacy))
+
+		encrypted, err := NewEncryptedStorage(ctx, db, []string{"secret"}, EncryptionPassword{ID: "1", Password: "password"})
+		require.NoError(t, err)
+
+		value := []byte(`{"name":"Max Verstappen"}`)
+		require.NoError(t, encrypted.Write(ctx, "secret", "max", value))
+		require.NoError(t, encrypted.Write(ctx, "plain", "max", value))
+
+		// only the configured namespace is encrypted in the wrapped storage
+		stored, err := db.Read(ctx, "secret", "max")
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(stored, []byte("Verstappen")))
+		stored, err = db.Read(ctx, "plain", "max")
+		assert.NoError(t, err)
+		assert.Equal(t, value, stored)
+
+		got, err := encrypted.Read(ctx, "secret", "max")
+		assert.NoError(t, err)
+		assert.Equal(t, value, got)
+
+		// values written before encryption was enabled are not read until they are encrypted
+		_, err = encrypted.Read(ctx, "secret", "legacy")
+		assert.ErrorContains(t, err, "value of key<legacy> in namespace<secret> is not encrypted")
+		_, err = encrypted.ReadAll(ctx, "secret")
+		assert.Error(t, err)
+		n, err := encrypted.EncryptExistingValues(ctx, true)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		stored, err = db.Read(ctx, "secret", "legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, legacy, stored)
+		n, err = encrypted.EncryptExistingValues(ctx, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		stored, err = db.Read(ctx, "secret", "legacy")
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(stored, []byte("legacy")))
+		got, err = encrypted.Read(ctx, "secret", "legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, legacy, got)
+		n, err = encrypted.EncryptExistingValues(ctx, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+
+		all, err := encrypted.ReadAll(ctx, "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"max": value, "legacy": legacy}, all)
+		prefixed, err := encrypted.ReadPrefix(ctx, "secret", "ma")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"max": value}, prefixed)
+		page, _, err := encrypted.ReadPage(ctx, "secret", "", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, all, page)
+
+		// updates are applied to the decrypted value, and stored encrypted
+		updated, err := encrypted.Update(ctx, "secret", "max", map[string]any{"team": "Red Bull"})
+		assert.NoError(t, err)
+		var updatedValue map[string]any
+		assert.NoError(t, json.Unmarshal(updated, &updatedValue))
+		assert.Equal(t, map[string]any{"name": "Max Verstappen", "team": "Red Bull"}, updatedValue)
+		got, err = encrypted.Read(ctx, "secret", "max")
+		assert.NoError(t, err)
+		assert.Equal(t, updated, got)
+		stored, err = db.Read(ctx, "secret", "max")
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(stored, []byte("Red Bull")))
+
+		// index entries are encrypted along with the values they index
+		require.NoError(t, WriteIndexed(ctx, encrypted, "secret", "checo", value, NewIndexEntry("team", "Red Bull")))
+		keys, err := ReadIndex(ctx, encrypted, "secret", "team", "Red Bull")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"checo"}, keys)
+		stored, err = db.Read(ctx, getIndexNamespace("secret"), indexRecordKeyPrefix+"checo")
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(stored, []byte("team")))
+
+		// values written through a transaction are encrypted
+		_, err = encrypted.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+			return nil, tx.Write(ctx, "secret", "lando", value)
+		}, nil)
+		assert.NoError(t, err)
+		stored, err = db.Read(ctx, "secret", "lando")
+		assert.NoError(t, err)
+		assert.NotEqual(t, value, stored)
+		got, err = encrypted.Read(ctx, "secret", "lando")
+		assert.NoError(t, err)
+		assert.Equal(t, value, got)
+
+		// values are bound to their key, so that a value moved to another key or namespace cannot be decrypted
+		stored, err = db.Read(ctx, "secret", "max")
+		require.NoError(t, err)
+		require.NoError(t, db.Write(ctx, "secret", "moved", stored))
+		_, err = encrypted.Read(ctx, "secret", "moved")
+		assert.ErrorContains(t, err, "decrypting value of key<moved>")
+		require.NoError(t, db.Write(ctx, getIndexNamespace("secret"), "max", stored))
+		_, err = encrypted.Read(ctx, getIndexNamespace("secret"), "max")
+		assert.Error(t, err)
+		require.NoError(t, db.Delete(ctx, "secret", "moved"))
+		require.NoError(t, db.Delete(ctx, getIndexNamespace("secret"), "max"))
+
+		// the data keys cannot be decrypted with another password
+		_, err = NewEncryptedStorage(ctx, db, []string{"secret"}, EncryptionPassword{ID: "1", Password: "wrong"})
+		assert.Error(t, err)
+	}
+}
+
+func TestEncryptedStorageRotation(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		first := EncryptionPassword{ID: "1", Password: "first"}
+		second := EncryptionPassword{ID: "2", Password: "second"}
+
+		instance1, err := NewEncryptedStorage(ctx, db, []string{"secret"}, first)
+		require.NoError(t, err)
+		before := []byte(`"before rotation"`)
+		require.NoError(t, instance1.Write(ctx, "secret", "before", before))
+
+		// a second instance starts with a new password, re-encrypting the data keys
+		instance2, err := NewEncryptedStorage(ctx, db, []string{"secret"}, second, first)
+		require.NoError(t, err)
+		got, err := instance2.Read(ctx, "secret", "before")
+		assert.NoError(t, err)
+		assert.Equal(t, before, got)
+
+		// the previous password is no longer needed
+		instance3, err := NewEncryptedStorage(ctx, db, []string{"secret"}, second)
+		require.NoError(t, err)
+		got, err = instance3.Read(ctx, "secret", "before")
+		assert.NoError(t, err)
+		assert.Equal(t, before, got)
+
+		// the first instance keeps working with the data keys it had loaded
+		got, err = instance1.Read(ctx, "secret", "before")
+		assert.NoError(t, err)
+		assert.Equal(t, before, got)
+
+		// values written after rotating the data key are readable by the other instances
+		require.NoError(t, instance3.RotateDataKey(ctx, "secret"))
+		after := []byte(`"after rotation"`)
+		require.NoError(t, instance3.Write(ctx, "secret", "after", after))
+		got, err = instance2.Read(ctx, "secret", "after")
+		assert.NoError(t, err)
+		assert.Equal(t, after, got)
+		got, err = instance2.Read(ctx, "secret", "before")
+		assert.NoError(t, err)
+		assert.Equal(t, before, got)
+
+		// the first instance's password no longer decrypts the new data key
+		_, err = instance1.Read(ctx, "secret", "after")
+		assert.Error(t, err)
+
+		err = instance3.RotateDataKey(ctx, "plain")
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExpirySweepInterval is how often the providers without native expiry remove the values whose TTL has passed.
+var ExpirySweepInterval = time.Minute
+
+// expirySweeper is implemented by the providers which keep their own index of expiring keys.
+type expirySweeper interface {
+	// sweepExpired removes every value which expired at or before now, and returns how many were removed.
+	sweepExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// startExpirySweeper sweeps expired values every ExpirySweepInterval, until the returned function is called.
+func startExpirySweeper(sweeper expirySweeper) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ExpirySweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				removed, err := sweeper.sweepExpired(ctx, now)
+				if err != nil {
+					logrus.WithError(err).Error("sweeping expired values")
+					continue
+				}
+				if removed > 0 {
+					logrus.Debugf("removed %d expired values", removed)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -1,11 +1,16 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"go.einride.tech/aip/filtering"
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 // FilterVarsMapper is an interface that encapsulates the FilterVariablesMap method. This interface is meant to be
@@ -51,19 +56,180 @@ func NewIncludeFunc(filter filtering.Filter) (IncludeFunc, error) {
 	}, nil
 }
 
-func simpleEquals(lhs ref.Val, rhs ref.Val) ref.Val {
-	return lhs.Equal(rhs)
+// FilterDeclarations returns the declarations of the functions filters evaluated by NewIncludeFunc may call: AND, OR
+// and NOT, the comparisons of booleans (= and != only), strings and timestamps, the comparison of a timestamp to an
+// RFC3339 string, the timestamp function, and the : function on lists of strings, which holds when the list contains
+// the string.
+func FilterDeclarations() []filtering.DeclarationOption {
+	comparisons := make([]filtering.DeclarationOption, 0, len(filterComparisons))
+	for _, function := range filterComparisons {
+		overloads := []*expr.Decl_FunctionDecl_Overload{
+			filtering.NewFunctionOverload(function.name+"_string", filtering.TypeBool, filtering.TypeString, filtering.TypeString),
+			filtering.NewFunctionOverload(function.name+"_timestamp", filtering.TypeBool, filtering.TypeTimestamp, filtering.TypeTimestamp),
+			filtering.NewFunctionOverload(function.name+"_timestamp_string", filtering.TypeBool, filtering.TypeTimestamp, filtering.TypeString),
+		}
+		if function.equality {
+			overloads = append(overloads, filtering.NewFunctionOverload(function.name+"_bool", filtering.TypeBool, filtering.TypeBool, filtering.TypeBool))
+		}
+		comparisons = append(comparisons, filtering.DeclareFunction(function.name, overloads...))
+	}
+	return append(comparisons,
+		filtering.DeclareFunction(filtering.FunctionAnd,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadAndBool, filtering.TypeBool, filtering.TypeBool, filtering.TypeBool)),
+		filtering.DeclareFunction(filtering.FunctionOr,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadOrBool, filtering.TypeBool, filtering.TypeBool, filtering.TypeBool)),
+		filtering.DeclareFunction(filtering.FunctionNot,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadNotBool, filtering.TypeBool, filtering.TypeBool)),
+		filtering.DeclareFunction(filtering.FunctionHas,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadHasListString, filtering.TypeBool, filtering.TypeList(filtering.TypeString), filtering.TypeString)),
+		filtering.DeclareFunction(filtering.FunctionTimestamp,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadTimestampString, filtering.TypeTimestamp, filtering.TypeString)),
+	)
+}
+
+// filterComparisons are the comparison functions of filters, with whether the comparison of operands holds given the
+// result of comparing them. Only equality applies to booleans.
+var filterComparisons = []struct {
+	name     string
+	equality bool
+	holds    func(cmp types.Int) bool
+}{
+	{name: filtering.FunctionEquals, equality: true, holds: func(cmp types.Int) bool { return cmp == 0 }},
+	{name: filtering.FunctionNotEquals, equality: true, holds: func(cmp types.Int) bool { return cmp != 0 }},
+	{name: filtering.FunctionLessThan, holds: func(cmp types.Int) bool { return cmp < 0 }},
+	{name: filtering.FunctionLessEquals, holds: func(cmp types.Int) bool { return cmp <= 0 }},
+	{name: filtering.FunctionGreaterThan, holds: func(cmp types.Int) bool { return cmp > 0 }},
+	{name: filtering.FunctionGreaterEquals, holds: func(cmp types.Int) bool { return cmp >= 0 }},
 }
 
 func newCelEnv() (*cel.Env, error) {
-	return cel.NewEnv(
-		cel.Function("=",
-			cel.Overload("=_bool",
+	opts := make([]cel.EnvOption, 0, len(filterComparisons)+4)
+	for _, function := range filterComparisons {
+		compare := compareBinding(function.holds)
+		overloads := []cel.FunctionOpt{
+			cel.Overload(function.name+"_string",
+				[]*cel.Type{cel.StringType, cel.StringType},
+				cel.BoolType,
+				cel.BinaryBinding(compare)),
+			cel.Overload(function.name+"_timestamp",
+				[]*cel.Type{cel.TimestampType, cel.TimestampType},
+				cel.BoolType,
+				cel.BinaryBinding(compare)),
+			cel.Overload(function.name+"_timestamp_string",
+				[]*cel.Type{cel.TimestampType, cel.StringType},
+				cel.BoolType,
+				cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+					return compare(lhs, parseTimestamp(rhs))
+				})),
+		}
+		if function.equality {
+			overloads = append(overloads, cel.Overload(function.name+"_bool",
 				[]*cel.Type{cel.BoolType, cel.BoolType},
 				cel.BoolType,
-				cel.BinaryBinding(simpleEquals)),
-			cel.Overload("=_string",
-				[]*cel.Type{cel.StringType, cel.StringType},
+				cel.BinaryBinding(compare)))
+		}
+		opts = append(opts, cel.Function(function.name, overloads...))
+	}
+	return cel.NewEnv(append(opts,
+		cel.Function(filtering.FunctionAnd,
+			cel.Overload(filtering.FunctionOverloadAndBool,
+				[]*cel.Type{cel.BoolType, cel.BoolType},
+				cel.BoolType,
+				cel.BinaryBinding(logicalBinding(func(lhs, rhs types.Bool) types.Bool { return lhs && rhs })))),
+		cel.Function(filtering.FunctionOr,
+			cel.Overload(filtering.FunctionOverloadOrBool,
+				[]*cel.Type{cel.BoolType, cel.BoolType},
+				cel.BoolType,
+				cel.BinaryBinding(logicalBinding(func(lhs, rhs types.Bool) types.Bool { return lhs || rhs })))),
+		cel.Function(filtering.FunctionNot,
+			cel.Overload(filtering.FunctionOverloadNotBool,
+				[]*cel.Type{cel.BoolType},
+				cel.BoolType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					b, ok := value.(types.Bool)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(value)
+					}
+					return !b
+				}))),
+		cel.Function(filtering.FunctionHas,
+			cel.Overload(filtering.FunctionOverloadHasListString,
+				[]*cel.Type{cel.ListType(cel.StringType), cel.StringType},
 				cel.BoolType,
-				cel.BinaryBinding(simpleEquals))))
+				cel.BinaryBinding(func(lhs ref.Val, rhs ref.Val) ref.Val {
+					list, ok := lhs.(traits.Container)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(lhs)
+					}
+					return list.Contains(rhs)
+				}))),
+		// the timestamp function is evaluated by the standard timestamp function of cel, which parses RFC3339 strings
+	)...)
+}
+
+// logicalBinding returns a binding which applies op to its boolean operands.
+func logicalBinding(op func(lhs, rhs types.Bool) types.Bool) func(lhs ref.Val, rhs ref.Val) ref.Val {
+	return func(lhs ref.Val, rhs ref.Val) ref.Val {
+		l, ok := lhs.(types.Bool)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(lhs)
+		}
+		r, ok := rhs.(types.Bool)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(rhs)
+		}
+		return op(l, r)
+	}
+}
+
+// compareBinding returns a binding which compares its operands, and returns whether holds for the result.
+func compareBinding(holds func(cmp types.Int) bool) func(lhs ref.Val, rhs ref.Val) ref.Val {
+	return func(lhs ref.Val, rhs ref.Val) ref.Val {
+		comparer, ok := lhs.(traits.Comparer)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(lhs)
+		}
+		cmp, ok := comparer.Compare(rhs).(types.Int)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(rhs)
+		}
+		return types.Bool(holds(cmp))
+	}
+}
+
+// parseTimestamp parses an RFC3339 string into a timestamp.
+func parseTimestamp(value ref.Val) ref.Val {
+	s, ok := value.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(value)
+	}
+	t, err := time.Parse(time.RFC3339, string(s))
+	if err != nil {
+		return types.NewErr("invalid timestamp<%s>: %s", s, err)
+	}
+	return types.Timestamp{Time: t}
+}
+
+// EqualityFilterValue returns the value the identifier is compared to when the whole filter is a single string
+// equality of the form `ident = "value"`. Such filters can be answered with an index instead of evaluating the filter
+// on every object.
+func EqualityFilterValue(filter filtering.Filter, ident string) (string, bool) {
+	if filter.CheckedExpr == nil {
+		return "", false
+	}
+	call := filter.CheckedExpr.GetExpr().GetCallExpr()
+	if call == nil || call.GetFunction() != filtering.FunctionEquals || len(call.GetArgs()) != 2 {
+		return "", false
+	}
+	if call.GetArgs()[0].GetIdentExpr().GetName() != ident {
+		return "", false
+	}
+	constant := call.GetArgs()[1].GetConstExpr()
+	if constant == nil {
+		return "", false
+	}
+	if _, isString := constant.GetConstantKind().(*expr.Constant_StringValue); !isString {
+		return "", false
+	}
+	return constant.GetStringValue(), true
 }
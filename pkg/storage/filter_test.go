@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.einride.tech/aip/filtering"
+)
+
+type filterRequest string
+
+func (r filterRequest) GetFilter() string {
+	return string(r)
+}
+
+type testFilterVars map[string]any
+
+func (v testFilterVars) FilterVariablesMap() map[string]any {
+	return v
+}
+
+func TestNewIncludeFunc(t *testing.T) {
+	declarations, err := filtering.NewDeclarations(append(FilterDeclarations(),
+		filtering.DeclareIdent("name", filtering.TypeString),
+		filtering.DeclareIdent("done", filtering.TypeBool),
+		filtering.DeclareIdent("tags", filtering.TypeList(filtering.TypeString)),
+		filtering.DeclareIdent("created", filtering.TypeTimestamp),
+		filtering.DeclareIdent("true", filtering.TypeBool),
+		filtering.DeclareIdent("false", filtering.TypeBool),
+	)...)
+	require.NoError(t, err)
+
+	vars := testFilterVars{
+		"name":    "jack",
+		"done":    false,
+		"tags":    []string{"a", "b"},
+		"created": time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		"true":    true,
+		"false":   false,
+	}
+	tests := map[string]bool{
+		``:                                 true,
+		`name = "jack"`:                    true,
+		`name != "jack"`:                   false,
+		`name = "jack" AND done = false`:   true,
+		`name = "jill" OR done = true`:     false,
+		`NOT done = true`:                  true,
+		`tags:"a"`:                         true,
+		`tags:"c"`:                         false,
+		`name > "jill"`:                    false,
+		`created > "2023-04-30T00:00:00Z"`: true,
+		`created < timestamp("2023-04-30T00:00:00Z")`:                             false,
+		`created >= "2023-05-01T00:00:00Z" AND created <= "2023-05-01T00:00:00Z"`: true,
+	}
+	for expression, want := range tests {
+		filter, err := filtering.ParseFilter(filterRequest(expression), declarations)
+		require.NoError(t, err, expression)
+		include, err := NewIncludeFunc(filter)
+		require.NoError(t, err, expression)
+		got, err := include(vars)
+		require.NoError(t, err, expression)
+		assert.Equal(t, want, got, expression)
+	}
+
+	// timestamps must be RFC3339 strings
+	_, err = filtering.ParseFilter(filterRequest(`created > "yesterday"`), declarations)
+	assert.Error(t, err)
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// Secondary indexes let records be looked up by the values of their fields without scanning their whole namespace.
+// All the indexes over the records of a namespace live in a single index namespace, which holds two kinds of keys:
+//
+//	i/<index name>/<encoded value>/<record key> -> record key, one for each index entry of a record
+//	r/<record key>                              -> the index entries of the record, used to remove stale entries
+//	x/<record key>                              -> the deadline of a record written with a TTL
+//
+// Values are base64url encoded so that they may contain any character, including the separators. The keys of a record
+// written with a TTL expire along with it, and entries added when it is rewritten are given the time it has left.
+const (
+	indexNamespacePrefix = "index"
+	indexEntryKeyPrefix  = "i/"
+	indexRecordKeyPrefix = "r/"
+	indexExpiryKeyPrefix = "x/"
+	indexKeySeparator    = "/"
+	indexValueSeparator  = "\x00"
+)
+
+// IndexEntry makes a record retrievable from the index with the given name, by the given value.
+type IndexEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewIndexEntry returns an entry for the named index. More than one value may be given for compound indexes, in
+// which case the same values must be given to ReadIndex.
+func NewIndexEntry(name string, values ...string) IndexEntry {
+	return IndexEntry{Name: name, Value: strings.Join(values, indexValueSeparator)}
+}
+
+// IndexFunc returns the index entries of a stored value.
+type IndexFunc func(value []byte) ([]IndexEntry, error)
+
+// WriteIndexed writes the value and its index entries within a single transaction. Entries the record had previously
+// been written with, and which are not part of entries, are removed.
+func WriteIndexed(ctx context.Context, db ServiceStorage, namespace, key string, value []byte, entries ...IndexEntry) error {
+	_, err := db.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		return nil, WriteIndexedTx(ctx, tx, namespace, key, value, entries...)
+	}, []WatchKey{IndexWatchKey(namespace, key)})
+	return err
+}
+
+// WriteIndexedWithTTL is like WriteIndexed, but the value and its index entries are removed once the ttl has passed.
+func WriteIndexedWithTTL(ctx context.Context, db ServiceStorage, namespace, key string, value []byte, ttl time.Duration, entries ...IndexEntry) error {
+	_, err := db.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		return nil, writeIndexedTx(ctx, tx, namespace, key, value, ttl, entries...)
+	}, []WatchKey{IndexWatchKey(namespace, key)})
+	return err
+}
+
+// WriteIndexedTx is like WriteIndexed, but writes within an existing transaction. Callers should include the watch key
+// returned by IndexWatchKey when executing the transaction.
+func WriteIndexedTx(ctx context.Context, tx Tx, namespace, key string, value []byte, entries ...IndexEntry) error {
+	return writeIndexedTx(ctx, tx, namespace, key, value, 0, entries...)
+}
+
+// writeIndexedTx writes the value and its index entries with the given ttl. When the ttl is not positive, they are
+// given the time the record has left, if it was written with a TTL.
+func writeIndexedTx(ctx context.Context, tx Tx, namespace, key string, value []byte, ttl time.Duration, entries ...IndexEntry) error {
+	for _, entry := range entries {
+		if entry.Name == "" || strings.Contains(entry.Name, indexKeySeparator) {
+			return errors.Errorf("invalid index name<%s>", entry.Name)
+		}
+	}
+	previous, err := readIndexEntries(ctx, tx, namespace, key)
+	if err != nil {
+		return err
+	}
+
+	indexNamespace := getIndexNamespace(namespace)
+	if ttl > 0 {
+		deadline := time.Now().Add(ttl).UTC().Format(time.RFC3339Nano)
+		if err = tx.WriteWithTTL(ctx, indexNamespace, indexExpiryKeyPrefix+key, []byte(deadline), ttl); err != nil {
+			return errors.Wrapf(err, "writing expiry of key<%s>", key)
+		}
+	} else if ttl, err = readRemainingTTL(ctx, tx, namespace, key); err != nil {
+		return err
+	}
+
+	current := make(map[IndexEntry]bool, len(entries))
+	for _, entry := range entries {
+		current[entry] = true
+	}
+	for _, entry := range previous {
+		if !current[entry] {
+			if err = tx.Delete(ctx, indexNamespace, getIndexEntryKey(entry, key)); err != nil {
+				return errors.Wrapf(err, "deleting stale index entry<%s> of key<%s>", entry.Name, key)
+			}
+		}
+	}
+
+	if err = tx.WriteWithTTL(ctx, namespace, key, value, ttl); err != nil {
+		return errors.Wrapf(err, "writing key<%s>", key)
+	}
+	for entry := range current {
+		if err = tx.WriteWithTTL(ctx, indexNamespace, getIndexEntryKey(entry, key), []byte(key), ttl); err != nil {
+			return errors.Wrapf(err, "writing index entry<%s> of key<%s>", entry.Name, key)
+		}
+	}
+	entriesBytes, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "marshalling index entries")
+	}
+	return tx.WriteWithTTL(ctx, indexNamespace, indexRecordKeyPrefix+key, entriesBytes, ttl)
+}
+
+// readRemainingTTL returns the time left until the record expires, or 0 when it was not written with a TTL. A record
+// past its deadline, which has not been swept yet, is given the shortest TTL so that it goes with the next sweep.
+func readRemainingTTL(ctx context.Context, tx Tx, namespace, key string) (time.Duration, error) {
+	deadlineBytes, err := tx.Read(ctx, getIndexNamespace(namespace), indexExpiryKeyPrefix+key)
+	if err != nil {
+		return 0, errors.Wrapf(err, "reading expiry of key<%s>", key)
+	}
+	if len(deadlineBytes) == 0 {
+		return 0, nil
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, string(deadlineBytes))
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing expiry of key<%s>", key)
+	}
+	if remaining := time.Until(deadline); remaining > time.Millisecond {
+		return remaining, nil
+	}
+	return time.Millisecond, nil
+}
+
+// DeleteIndexed deletes the key along with all of its index entries within a single transaction.
+func DeleteIndexed(ctx context.Context, db ServiceStorage, namespace, key string) error {
+	_, err := db.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		return nil, DeleteIndexedTx(ctx, tx, namespace, key)
+	}, []WatchKey{IndexWatchKey(namespace, key)})
+	return err
+}
+
+// DeleteIndexedTx is like DeleteIndexed, but deletes within an existing transaction.
+func DeleteIndexedTx(ctx context.Context, tx Tx, namespace, key string) error {
+	entries, err := readIndexEntries(ctx, tx, namespace, key)
+	if err != nil {
+		return err
+	}
+	indexNamespace := getIndexNamespace(namespace)
+	for _, entry := range entries {
+		if err = tx.Delete(ctx, indexNamespace, getIndexEntryKey(entry, key)); err != nil {
+			return errors.Wrapf(err, "deleting index entry<%s> of key<%s>", entry.Name, key)
+		}
+	}
+	if err = tx.Delete(ctx, indexNamespace, indexRecordKeyPrefix+key); err != nil {
+		return errors.Wrapf(err, "deleting index entries of key<%s>", key)
+	}
+	if err = tx.Delete(ctx, indexNamespace, indexExpiryKeyPrefix+key); err != nil {
+		return errors.Wrapf(err, "deleting expiry of key<%s>", key)
+	}
+	return tx.Delete(ctx, namespace, key)
+}
+
+// ReadIndex returns the sorted keys of the records in the namespace which were written with an entry for the named
+// index with the given values. Only the matching index entries are read.
+func ReadIndex(ctx context.Context, db ServiceStorage, namespace, name string, values ...string) ([]string, error) {
+	prefix := getIndexEntryKey(NewIndexEntry(name, values...), "")
+	matches, err := db.ReadPrefix(ctx, getIndexNamespace(namespace), prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading index<%s>", name)
+	}
+	keys := make([]string, 0, len(matches))
+	for _, key := range matches {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// UpdateIndexedValueAndOperation is like ServiceStorage.UpdateValueAndOperation for a namespace that has indexes. The
+// index entries of the updated value, as returned by indexFunc, are written in the same transaction.
+func UpdateIndexedValueAndOperation(ctx context.Context, db ServiceStorage, namespace, key string, updater Updater, indexFunc IndexFunc, opNamespace, opKey string, opUpdater ResponseSettingUpdater) (first, op []byte, err error) {
+	type updated struct {
+		first, op []byte
+	}
+	businessLogicFunc := func(ctx context.Context, tx Tx) (any, error) {
+		firstData, err := applyUpdater(ctx, tx, namespace, key, updater)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := indexFunc(firstData)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting index entries")
+		}
+		if err = WriteIndexedTx(ctx, tx, namespace, key, firstData, entries...); err != nil {
+			return nil, err
+		}
+
+		opUpdater.SetUpdatedResponse(firstData)
+		opData, err := applyUpdater(ctx, tx, opNamespace, opKey, opUpdater)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, opNamespace, opKey, opData); err != nil {
+			return nil, errors.Wrap(err, "writing operation")
+		}
+		return updated{first: firstData, op: opData}, nil
+	}
+	watchKeys := []WatchKey{{Namespace: namespace, Key: key}, {Namespace: opNamespace, Key: opKey}, IndexWatchKey(namespace, key)}
+	result, err := db.Execute(ctx, businessLogicFunc, watchKeys)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to execute transaction")
+	}
+	u := result.(updated)
+	return u.first, u.op, nil
+}
+
+// IndexWatchKey returns the key which changes whenever the index entries of the record change.
+func IndexWatchKey(namespace, key string) WatchKey {
+	return WatchKey{Namespace: getIndexNamespace(namespace), Key: indexRecordKeyPrefix + key}
+}
+
+func applyUpdater(ctx context.Context, tx Tx, namespace, key string, updater Updater) ([]byte, error) {
+	v, err := tx.Read(ctx, namespace, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get error with namespace: %s key: %s", namespace, key)
+	}
+	if v == nil {
+		return nil, errors.Errorf("key not found %s", key)
+	}
+	if err = updater.Validate(v); err != nil {
+		return nil, errors.Wrap(err, "validating update")
+	}
+	return updater.Update(v)
+}
+
+func readIndexEntries(ctx context.Context, tx Tx, namespace, key string) ([]IndexEntry, error) {
+	entriesBytes, err := tx.Read(ctx, getIndexNamespace(namespace), indexRecordKeyPrefix+key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading index entries of key<%s>", key)
+	}
+	if len(entriesBytes) == 0 {
+		return nil, nil
+	}
+	var entries []IndexEntry
+	if err = json.Unmarshal(entriesBytes, &entries); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling index entries of key<%s>", key)
+	}
+	return entries, nil
+}
+
+func getIndexNamespace(namespace string) string {
+	return MakeNamespace(indexNamespacePrefix, namespace)
+}
+
+func getIndexEntryKey(entry IndexEntry, key string) string {
+	encodedValue := base64.RawURLEncoding.EncodeToString([]byte(entry.Value))
+	return indexEntryKeyPrefix + entry.Name + indexKeySeparator + encodedValue + indexKeySeparator + key
+}
@@ -0,0 +1,389 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	if err := RegisterStorage(new(MemoryDB)); err != nil {
+		panic(err)
+	}
+}
+
+// MemoryDB is a ServiceStorage which keeps all values in process memory, meant for tests and throwaway deployments.
+// Nothing is persisted, and all values are dropped when it is closed. Every key carries a version which is incremented
+// on every write and delete, and is used to implement the optimistic concurrency semantics of WatchKey in Execute.
+// Values written with a TTL are removed by a background sweep.
+type MemoryDB struct {
+	mu          sync.RWMutex
+	open        bool
+	namespaces  map[string]map[string][]byte
+	versions    map[WatchKey]uint64
+	expiries    map[WatchKey]time.Time
+	stopSweeper func()
+}
+
+// memoryTx buffers the writes of a transaction, which are applied together once the watch keys have been checked.
+type memoryTx struct {
+	db  *MemoryDB
+	ops []bufferedWrite
+}
+
+func (mtx *memoryTx) Read(_ context.Context, namespace, key string) ([]byte, error) {
+	if value, ok := readBuffered(mtx.ops, namespace, key); ok {
+		return value, nil
+	}
+	mtx.db.mu.RLock()
+	defer mtx.db.mu.RUnlock()
+	return bytes.Clone(mtx.db.namespaces[namespace][key]), nil
+}
+
+func (mtx *memoryTx) Write(_ context.Context, namespace, key string, value []byte) error {
+	mtx.ops = append(mtx.ops, bufferedWrite{namespace: namespace, key: key, value: bytes.Clone(value)})
+	return nil
+}
+
+func (mtx *memoryTx) WriteWithTTL(_ context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	mtx.ops = append(mtx.ops, bufferedWrite{namespace: namespace, key: key, value: bytes.Clone(value), ttl: ttl})
+	return nil
+}
+
+func (mtx *memoryTx) Delete(_ context.Context, namespace, key string) error {
+	mtx.ops = append(mtx.ops, bufferedWrite{namespace: namespace, key: key, delete: true})
+	return nil
+}
+
+// Init instantiates an empty in-memory storage instance. No options are used.
+func (m *MemoryDB) Init(_ interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.open {
+		return fmt.Errorf("memory db already opened")
+	}
+	m.open = true
+	m.namespaces = make(map[string]map[string][]byte)
+	m.versions = make(map[WatchKey]uint64)
+	m.expiries = make(map[WatchKey]time.Time)
+	m.stopSweeper = startExpirySweeper(m)
+	return nil
+}
+
+func (m *MemoryDB) URI() string {
+	return string(Memory)
+}
+
+func (m *MemoryDB) IsOpen() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.open
+}
+
+func (m *MemoryDB) Type() Type {
+	return Memory
+}
+
+func (m *MemoryDB) Close() error {
+	if m.stopSweeper != nil {
+		m.stopSweeper()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.open = false
+	m.namespaces = make(map[string]map[string][]byte)
+	m.versions = make(map[WatchKey]uint64)
+	m.expiries = make(map[WatchKey]time.Time)
+	return nil
+}
+
+func (m *MemoryDB) put(namespace, key string, value []byte) {
+	bucket, ok := m.namespaces[namespace]
+	if !ok {
+		bucket = make(map[string][]byte)
+		m.namespaces[namespace] = bucket
+	}
+	bucket[key] = bytes.Clone(value)
+	m.versions[WatchKey{Namespace: namespace, Key: key}]++
+}
+
+// putWithTTL writes the value, and sets it to expire once the ttl has passed when it is positive.
+func (m *MemoryDB) putWithTTL(namespace, key string, value []byte, ttl time.Duration) {
+	m.put(namespace, key, value)
+	if ttl > 0 {
+		m.expiries[WatchKey{Namespace: namespace, Key: key}] = time.Now().Add(ttl)
+	}
+}
+
+func (m *MemoryDB) remove(namespace, key string) {
+	bucket, ok := m.namespaces[namespace]
+	if !ok {
+		return
+	}
+	if _, ok = bucket[key]; !ok {
+		return
+	}
+	delete(bucket, key)
+	m.versions[WatchKey{Namespace: namespace, Key: key}]++
+	delete(m.expiries, WatchKey{Namespace: namespace, Key: key})
+}
+
+func (m *MemoryDB) sweepExpired(_ context.Context, now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := 0
+	for wk, deadline := range m.expiries {
+		if !deadline.After(now) {
+			m.remove(wk.Namespace, wk.Key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Execute runs the provided function within a transaction. Writes made through the transaction are buffered, and
+// applied atomically once businessLogicFunc returns. When any of the watchKeys changed in the meantime, the writes are
+// discarded and the function is retried with exponential backoff, mirroring the behavior of the redis implementation.
+func (m *MemoryDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
+	var finalOutput any
+	txf := func() error {
+		m.mu.RLock()
+		snapshot := make([]uint64, len(watchKeys))
+		for i, wk := range watchKeys {
+			snapshot[i] = m.versions[wk]
+		}
+		m.mu.RUnlock()
+
+		mtx := &memoryTx{db: m}
+		output, err := businessLogicFunc(ctx, mtx)
+		if err != nil {
+			return backoff.Permanent(errors.Wrap(err, "executing business logic func"))
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, wk := range watchKeys {
+			if m.versions[wk] != snapshot[i] {
+				return errWatchKeyChanged
+			}
+		}
+		for _, op := range mtx.ops {
+			if op.delete {
+				m.remove(op.namespace, op.key)
+			} else {
+				m.putWithTTL(op.namespace, op.key, op.value, op.ttl)
+			}
+		}
+		finalOutput = output
+		return nil
+	}
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxElapsedTime = MaxElapsedTime
+
+	err := backoff.Retry(func() error {
+		err := txf()
+		if errors.Is(err, errWatchKeyChanged) {
+			logrus.Warn("Optimistic lock lost. Retrying..")
+		}
+		return err
+	}, expBackoff)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute after retrying")
+	}
+	return finalOutput, nil
+}
+
+func (m *MemoryDB) Exists(_ context.Context, namespace, key string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.namespaces[namespace][key]
+	return exists, nil
+}
+
+func (m *MemoryDB) Write(_ context.Context, namespace, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(namespace, key, value)
+	return nil
+}
+
+func (m *MemoryDB) WriteWithTTL(_ context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.putWithTTL(namespace, key, value, ttl)
+	return nil
+}
+
+func (m *MemoryDB) WriteMany(_ context.Context, namespaces, keys []string, values [][]byte) error {
+	if len(namespaces) != len(keys) || len(namespaces) != len(values) {
+		return errors.New("namespaces, keys, and values, are not of equal length")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range namespaces {
+		m.put(namespaces[i], keys[i], values[i])
+	}
+	return nil
+}
+
+func (m *MemoryDB) Read(_ context.Context, namespace, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bucket, ok := m.namespaces[namespace]
+	if !ok {
+		logrus.Warnf("namespace<%s> does not exist", namespace)
+		return nil, nil
+	}
+	return bytes.Clone(bucket[key]), nil
+}
+
+// ReadPrefix does a prefix query within a namespace.
+func (m *MemoryDB) ReadPrefix(_ context.Context, namespace, prefix string) (map[string][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string][]byte)
+	for k, v := range m.namespaces[namespace] {
+		if strings.HasPrefix(k, prefix) {
+			result[k] = bytes.Clone(v)
+		}
+	}
+	return result, nil
+}
+
+func (m *MemoryDB) ReadAll(_ context.Context, namespace string) (map[string][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string][]byte, len(m.namespaces[namespace]))
+	for k, v := range m.namespaces[namespace] {
+		result[k] = bytes.Clone(v)
+	}
+	return result, nil
+}
+
+// ReadPage reads a page of values from the namespace in key order. The page token holds the last key returned.
+func (m *MemoryDB) ReadPage(_ context.Context, namespace, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bucket := m.namespaces[namespace]
+	keys := make([]string, 0, len(bucket))
+	for k := range bucket {
+		keys = append(keys, k)
+	}
+	page, nextPageToken, err := PaginateKeys(keys, pageToken, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	result := make(map[string][]byte, len(page))
+	for _, k := range page {
+		result[k] = bytes.Clone(bucket[k])
+	}
+	return result, nextPageToken, nil
+}
+
+func (m *MemoryDB) ReadAllKeys(_ context.Context, namespace string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bucket, ok := m.namespaces[namespace]
+	if !ok {
+		return nil, nil
+	}
+	result := make([]string, 0, len(bucket))
+	for k := range bucket {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (m *MemoryDB) Namespaces(_ context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]string, 0, len(m.namespaces))
+	for namespace := range m.namespaces {
+		result = append(result, namespace)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (m *MemoryDB) Delete(_ context.Context, namespace, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.namespaces[namespace]; !ok {
+		return sdkutil.LoggingNewErrorf("namespace<%s> does not exist", namespace)
+	}
+	m.remove(namespace, key)
+	return nil
+}
+
+func (m *MemoryDB) DeleteNamespace(_ context.Context, namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket, ok := m.namespaces[namespace]
+	if !ok {
+		return sdkutil.LoggingNewErrorf("could not delete namespace<%s>, namespace does not exist", namespace)
+	}
+	for k := range bucket {
+		m.versions[WatchKey{Namespace: namespace, Key: k}]++
+		delete(m.expiries, WatchKey{Namespace: namespace, Key: k})
+	}
+	delete(m.namespaces, namespace)
+	return nil
+}
+
+func (m *MemoryDB) Update(_ context.Context, namespace string, key string, values map[string]any) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, err := m.update(namespace, key, NewUpdater(values))
+	if err != nil {
+		return nil, err
+	}
+	m.put(namespace, key, data)
+	return data, nil
+}
+
+// UpdateValueAndOperation updates the value stored in (namespace,key) with the new values specified in the map.
+// The updated value is then stored inside the (opNamespace, opKey), and the "done" value is set to true.
+func (m *MemoryDB) UpdateValueAndOperation(_ context.Context, namespace, key string, updater Updater, opNamespace, opKey string, opUpdater ResponseSettingUpdater) (first, op []byte, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	first, err = m.update(namespace, key, updater)
+	if err != nil {
+		return nil, nil, err
+	}
+	opUpdater.SetUpdatedResponse(first)
+	op, err = m.update(opNamespace, opKey, opUpdater)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.put(namespace, key, first)
+	m.put(opNamespace, opKey, op)
+	return first, op, nil
+}
+
+// update returns the result of applying the updater to the stored value, without writing it. The caller must hold the
+// write lock.
+func (m *MemoryDB) update(namespace, key string, updater Updater) ([]byte, error) {
+	bucket, ok := m.namespaces[namespace]
+	if !ok {
+		return nil, sdkutil.LoggingNewErrorf("namespace<%s> does not exist", namespace)
+	}
+	v, ok := bucket[key]
+	if !ok {
+		return nil, sdkutil.LoggingNewErrorf("key not found %s", key)
+	}
+	if err := updater.Validate(v); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "validating update")
+	}
+	return updater.Update(bytes.Clone(v))
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// migrationNamespace holds the schema version of every namespace with registered migrations, keyed by namespace.
+const migrationNamespace = "migration"
+
+// MigrateFunc transforms a value stored with the previous schema version of its namespace into the current one.
+type MigrateFunc func(ctx context.Context, key string, value []byte) ([]byte, error)
+
+// Migration upgrades the values stored in a namespace to the given schema version. The migrations of a namespace are
+// numbered from 1, and run in order, at most once each.
+type Migration struct {
+	Namespace   string
+	Version     int
+	Description string
+	// Migrate transforms each stored value. Since a migration which is interrupted is run again from the start, it must
+	// also accept values it has already migrated. When nil, values are left unchanged.
+	Migrate MigrateFunc
+	// Index, when set, returns the index entries each value is rewritten with. See WriteIndexed.
+	Index IndexFunc
+}
+
+// MigrationResult describes the migrations run on a namespace.
+type MigrationResult struct {
+	Namespace   string `json:"namespace"`
+	FromVersion int    `json:"fromVersion"`
+	ToVersion   int    `json:"toVersion"`
+	// Migrated is the number of values which were rewritten, or would have been in a dry run.
+	Migrated int `json:"migrated"`
+}
+
+type schemaVersion struct {
+	Version   int    `json:"version"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+var (
+	registeredMigrations   = make(map[string][]Migration)
+	registeredMigrationsMu sync.Mutex
+)
+
+// RegisterMigrations registers migrations to be run at startup. The migrations of each namespace must be registered in
+// version order, starting at 1, without gaps.
+func RegisterMigrations(migrations ...Migration) error {
+	registeredMigrationsMu.Lock()
+	defer registeredMigrationsMu.Unlock()
+	for _, m := range migrations {
+		if m.Namespace == "" || m.Namespace == migrationNamespace {
+			return fmt.Errorf("invalid migration namespace<%s>", m.Namespace)
+		}
+		expected := len(registeredMigrations[m.Namespace]) + 1
+		if m.Version != expected {
+			return fmt.Errorf("migration of namespace<%s> has version<%d>, expected<%d>", m.Namespace, m.Version, expected)
+		}
+		registeredMigrations[m.Namespace] = append(registeredMigrations[m.Namespace], m)
+	}
+	return nil
+}
+
+// RegisteredMigrations returns all the migrations registered with RegisterMigrations.
+func RegisteredMigrations() []Migration {
+	registeredMigrationsMu.Lock()
+	defer registeredMigrationsMu.Unlock()
+	var migrations []Migration
+	for _, namespaceMigrations := range registeredMigrations {
+		migrations = append(migrations, namespaceMigrations...)
+	}
+	return migrations
+}
+
+// RunMigrations brings every namespace with migrations up to its latest schema version, and returns what was done for
+// each namespace which was not already up to date. Namespaces which hold no values are marked as being at the latest
+// version without running anything. In a dry run the migrations are only applied in memory, to report what would
+// change, and nothing is written.
+func RunMigrations(ctx context.Context, db ServiceStorage, migrations []Migration, dryRun bool) ([]MigrationResult, error) {
+	byNamespace := make(map[string][]Migration)
+	for _, m := range migrations {
+		byNamespace[m.Namespace] = append(byNamespace[m.Namespace], m)
+	}
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace, namespaceMigrations := range byNamespace {
+		sort.Slice(namespaceMigrations, func(i, j int) bool { return namespaceMigrations[i].Version < namespaceMigrations[j].Version })
+		for i, m := range namespaceMigrations {
+			if m.Version != i+1 {
+				return nil, fmt.Errorf("migrations of namespace<%s> are not numbered consecutively from 1", namespace)
+			}
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	results := make([]MigrationResult, 0)
+	for _, namespace := range namespaces {
+		result, err := migrateNamespace(ctx, db, namespace, byNamespace[namespace], dryRun)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrating namespace<%s>", namespace)
+		}
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+	return results, nil
+}
+
+func migrateNamespace(ctx context.Context, db ServiceStorage, namespace string, migrations []Migration, dryRun bool) (*MigrationResult, error) {
+	current, err := readSchemaVersion(ctx, db, namespace)
+	if err != nil {
+		return nil, err
+	}
+	latest := migrations[len(migrations)-1].Version
+	if current > latest {
+		return nil, fmt.Errorf("stored schema version<%d> is newer than the latest known version<%d>", current, latest)
+	}
+	if current == latest {
+		return nil, nil
+	}
+
+	result := MigrationResult{Namespace: namespace, FromVersion: current, ToVersion: latest}
+	values, err := db.ReadAll(ctx, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading values")
+	}
+	if current == 0 && len(values) == 0 {
+		if dryRun {
+			return &result, nil
+		}
+		return &result, writeSchemaVersion(ctx, db, namespace, latest)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	migrated := make(map[string]bool)
+	for _, m := range migrations[current:] {
+		logrus.Infof("running migration<%d> of namespace<%s>: %s", m.Version, namespace, m.Description)
+		for _, key := range keys {
+			value := values[key]
+			if m.Migrate != nil {
+				if value, err = m.Migrate(ctx, key, values[key]); err != nil {
+					return nil, errors.Wrapf(err, "running migration<%d> on key<%s>", m.Version, key)
+				}
+			}
+			if m.Index == nil && bytes.Equal(value, values[key]) {
+				continue
+			}
+			values[key] = value
+			migrated[key] = true
+			if dryRun {
+				continue
+			}
+			if err = writeMigratedValue(ctx, db, namespace, key, value, m.Index); err != nil {
+				return nil, errors.Wrapf(err, "writing key<%s> migrated to version<%d>", key, m.Version)
+			}
+		}
+		if dryRun {
+			continue
+		}
+		if err = writeSchemaVersion(ctx, db, namespace, m.Version); err != nil {
+			return nil, err
+		}
+	}
+	result.Migrated = len(migrated)
+	return &result, nil
+}
+
+func writeMigratedValue(ctx context.Context, db ServiceStorage, namespace, key string, value []byte, indexFunc IndexFunc) error {
+	if indexFunc == nil {
+		return db.Write(ctx, namespace, key, value)
+	}
+	entries, err := indexFunc(value)
+	if err != nil {
+		return errors.Wrap(err, "getting index entries")
+	}
+	return WriteIndexed(ctx, db, namespace, key, value, entries...)
+}
+
+func readSchemaVersion(ctx context.Context, db ServiceStorage, namespace string) (int, error) {
+	versionBytes, err := db.Read(ctx, migrationNamespace, namespace)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading schema version")
+	}
+	if len(versionBytes) == 0 {
+		return 0, nil
+	}
+	var version schemaVersion
+	if err = json.Unmarshal(versionBytes, &version); err != nil {
+		return 0, errors.Wrap(err, "unmarshalling schema version")
+	}
+	return version.Version, nil
+}
+
+func writeSchemaVersion(ctx context.Context, db ServiceStorage, namespace string, version int) error {
+	versionBytes, err := json.Marshal(schemaVersion{Version: version, UpdatedAt: time.Now().UTC().Format(time.RFC3339)})
+	if err != nil {
+		return errors.Wrap(err, "marshalling schema version")
+	}
+	if err = db.Write(ctx, migrationNamespace, namespace, versionBytes); err != nil {
+		return errors.Wrap(err, "writing schema version")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type driverV0 struct {
+	Name string `json:"name"`
+}
+
+type driverV1 struct {
+	FullName string `json:"fullName"`
+	Team     string `json:"team"`
+}
+
+func getDriverMigrations(namespace string) []Migration {
+	renameName := func(_ context.Context, _ string, value []byte) ([]byte, error) {
+		var raw map[string]any
+		if err := json.Unmarshal(value, &raw); err != nil {
+			return nil, err
+		}
+		name, ok := raw["name"]
+		if !ok {
+			return value, nil
+		}
+		return json.Marshal(driverV1{FullName: name.(string), Team: "unknown"})
+	}
+	indexTeam := func(value []byte) ([]IndexEntry, error) {
+		var driver driverV1
+		if err := json.Unmarshal(value, &driver); err != nil {
+			return nil, err
+		}
+		return []IndexEntry{NewIndexEntry("team", driver.Team)}, nil
+	}
+	return []Migration{
+		{Namespace: namespace, Version: 1, Description: "rename name to fullName", Migrate: renameName},
+		{Namespace: namespace, Version: 2, Description: "index drivers by team", Index: indexTeam},
+	}
+}
+
+func TestRunMigrations(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		namespace := "drivers"
+		migrations := getDriverMigrations(namespace)
+
+		v0, err := json.Marshal(driverV0{Name: "Max Verstappen"})
+		require.NoError(t, err)
+		require.NoError(t, db.Write(ctx, namespace, "max", v0))
+
+		// a dry run reports the changes without writing them
+		results, err := RunMigrations(ctx, db, migrations, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []MigrationResult{{Namespace: namespace, FromVersion: 0, ToVersion: 2, Migrated: 1}}, results)
+		stored, err := db.Read(ctx, namespace, "max")
+		assert.NoError(t, err)
+		assert.Equal(t, v0, stored)
+		version, err := readSchemaVersion(ctx, db, namespace)
+		assert.NoError(t, err)
+		assert.Zero(t, version)
+
+		results, err = RunMigrations(ctx, db, migrations, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []MigrationResult{{Namespace: namespace, FromVersion: 0, ToVersion: 2, Migrated: 1}}, results)
+		stored, err = db.Read(ctx, namespace, "max")
+		assert.NoError(t, err)
+		var migrated driverV1
+		assert.NoError(t, json.Unmarshal(stored, &migrated))
+		assert.Equal(t, driverV1{FullName: "Max Verstappen", Team: "unknown"}, migrated)
+		keys, err := ReadIndex(ctx, db, namespace, "team", "unknown")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"max"}, keys)
+		version, err = readSchemaVersion(ctx, db, namespace)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+
+		// migrations only run once
+		results, err = RunMigrations(ctx, db, migrations, false)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+
+		// a database written by a newer version cannot be migrated
+		_, err = RunMigrations(ctx, db, migrations[:1], false)
+		assert.Error(t, err)
+	}
+}
+
+func TestRunMigrationsOnEmptyNamespace(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+		migrations := []Migration{{
+			Namespace: "empty",
+			Version:   1,
+			Migrate: func(context.Context, string, []byte) ([]byte, error) {
+				return nil, errors.New("should not run")
+			},
+		}}
+
+		results, err := RunMigrations(ctx, db, migrations, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []MigrationResult{{Namespace: "empty", FromVersion: 0, ToVersion: 1}}, results)
+
+		// values written afterwards are already in the latest version
+		require.NoError(t, db.Write(ctx, "empty", "new", []byte("{}")))
+		results, err = RunMigrations(ctx, db, migrations, false)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	}
+}
+
+func TestRegisterMigrations(t *testing.T) {
+	namespace := "test-register-migrations"
+	err := RegisterMigrations(Migration{Namespace: namespace, Version: 2})
+	assert.Error(t, err)
+
+	err = RegisterMigrations(getDriverMigrations(namespace)...)
+	assert.NoError(t, err)
+	registered := 0
+	for _, m := range RegisteredMigrations() {
+		if m.Namespace == namespace {
+			registered++
+		}
+	}
+	assert.Equal(t, 2, registered)
+
+	err = RegisterMigrations(Migration{Namespace: namespace, Version: 2})
+	assert.Error(t, err)
+
+	_, err = RunMigrations(context.Background(), setupMemoryDB(t), []Migration{{Namespace: namespace, Version: 2}}, false)
+	assert.Error(t, err)
+}
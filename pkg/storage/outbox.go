@@ -0,0 +1,526 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// The outbox records a ChangeEvent for every value created, updated or deleted through an OutboxStorage, in the same
+// transaction as the change itself, so that a change is never committed without its event, nor the other way round.
+// Events are stored in the outbox namespace under keys which sort in the order they were recorded, and delivered to
+// every subscriber at least once. The events each subscriber has handled are recorded in the deliveries namespace,
+// under keys prefixed by its name, and an event is removed once all subscribers have handled it. Neither namespace
+// name is a prefix of the other, since redis lists a namespace by matching the prefix of its keys.
+const (
+	OutboxNamespace = "outbox-events"
+
+	outboxDeliveriesNamespace = "outbox-deliveries"
+	outboxNamespacePrefix     = "outbox-"
+	outboxDeliveryKeySep      = "/"
+)
+
+var (
+	// OutboxPollInterval is how often the outbox is checked for events to deliver, besides whenever an event is
+	// recorded by this instance of the service. It is also the delay before the first retry of a failed delivery.
+	OutboxPollInterval = time.Second
+	// OutboxMaxAttempts is how many times delivering an event to a subscriber is attempted before giving up on it.
+	OutboxMaxAttempts = 10
+)
+
+type ChangeType string
+
+const (
+	ChangeCreate ChangeType = "create"
+	ChangeUpdate ChangeType = "update"
+	ChangeDelete ChangeType = "delete"
+)
+
+// ChangeEvent describes a change to a single key. Value is the value the key was written with, or the value it held
+// before it was deleted.
+type ChangeEvent struct {
+	ID        string     `json:"id"`
+	Type      ChangeType `json:"type"`
+	Namespace string     `json:"namespace"`
+	Key       string     `json:"key"`
+	Value     []byte     `json:"value,omitempty"`
+	Time      time.Time  `json:"time"`
+}
+
+// ChangeHandler reacts to a change event. When it returns an error, the event is delivered again later, so it must be
+// safe to handle the same event more than once.
+type ChangeHandler func(ctx context.Context, event ChangeEvent) error
+
+type outboxSubscriber struct {
+	name    string
+	handler ChangeHandler
+	// attempts and retryAt track the failed deliveries, by event id
+	attempts map[string]int
+	retryAt  map[string]time.Time
+}
+
+// OutboxStorage is a ServiceStorage which records a ChangeEvent in the outbox for every change to the values of the
+// storage it wraps, except for those of the outbox itself and of secondary indexes. Changes made to the wrapped
+// storage directly, and values removed once their TTL has passed, are not recorded.
+type OutboxStorage struct {
+	ServiceStorage
+
+	retention   time.Duration
+	subscribers []*outboxSubscriber
+	notify      chan struct{}
+
+	mu   sync.Mutex
+	stop func()
+}
+
+// NewOutboxStorage wraps db so that its changes are recorded in the outbox. Events, and the records of their delivery,
+// are removed after the retention period even when they were not delivered to every subscriber.
+func NewOutboxStorage(db ServiceStorage, retention time.Duration) (*OutboxStorage, error) {
+	if retention <= 0 {
+		return nil, errors.New("outbox retention must be positive")
+	}
+	return &OutboxStorage{
+		ServiceStorage: db,
+		retention:      retention,
+		notify:         make(chan struct{}, 1),
+	}, nil
+}
+
+// Subscribe registers a handler for every change event. Subscribers are identified by name, which must stay the same
+// across restarts so that the events they already handled are not delivered again. Subscribers must be registered
+// before the outbox is started.
+func (o *OutboxStorage) Subscribe(name string, handler ChangeHandler) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stop != nil {
+		return errors.New("cannot subscribe to a started outbox")
+	}
+	if name == "" || strings.Contains(name, outboxDeliveryKeySep) {
+		return errors.Errorf("invalid outbox subscriber name<%s>", name)
+	}
+	for _, s := range o.subscribers {
+		if s.name == name {
+			return errors.Errorf("outbox subscriber<%s> already exists", name)
+		}
+	}
+	o.subscribers = append(o.subscribers, &outboxSubscriber{
+		name:     name,
+		handler:  handler,
+		attempts: make(map[string]int),
+		retryAt:  make(map[string]time.Time),
+	})
+	return nil
+}
+
+// Start delivers the events of the outbox to the subscribers in the background, until the storage is closed. When
+// several instances of the service share a storage, each delivers every event, so subscribers may see it more than
+// once.
+func (o *OutboxStorage) Start() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stop != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(OutboxPollInterval)
+		defer ticker.Stop()
+		for {
+			if o.IsOpen() {
+				if err := o.dispatch(ctx, time.Now()); err != nil && ctx.Err() == nil {
+					logrus.WithError(err).Error("delivering outbox events")
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-o.notify:
+			}
+		}
+	}()
+	o.stop = func() {
+		cancel()
+		<-done
+	}
+}
+
+// Close stops delivering events, and closes the wrapped storage.
+func (o *OutboxStorage) Close() error {
+	o.mu.Lock()
+	stop := o.stop
+	o.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+	return o.ServiceStorage.Close()
+}
+
+func (o *OutboxStorage) Write(ctx context.Context, namespace, key string, value []byte) error {
+	return o.WriteWithTTL(ctx, namespace, key, value, 0)
+}
+
+func (o *OutboxStorage) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	if !o.tracked(namespace) {
+		return o.ServiceStorage.WriteWithTTL(ctx, namespace, key, value, ttl)
+	}
+	_, err := o.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		return nil, tx.WriteWithTTL(ctx, namespace, key, value, ttl)
+	}, []WatchKey{{Namespace: namespace, Key: key}})
+	return err
+}
+
+func (o *OutboxStorage) WriteMany(ctx context.Context, namespaces, keys []string, values [][]byte) error {
+	if len(namespaces) != len(keys) || len(namespaces) != len(values) {
+		return errors.New("namespaces, keys, and values, are not of equal length")
+	}
+	var watchKeys []WatchKey
+	for i := range namespaces {
+		if o.tracked(namespaces[i]) {
+			watchKeys = append(watchKeys, WatchKey{Namespace: namespaces[i], Key: keys[i]})
+		}
+	}
+	if len(watchKeys) == 0 {
+		return o.ServiceStorage.WriteMany(ctx, namespaces, keys, values)
+	}
+	_, err := o.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		for i := range namespaces {
+			if err := tx.Write(ctx, namespaces[i], keys[i], values[i]); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}, watchKeys)
+	return err
+}
+
+// Delete records the deletion when the key exists. Otherwise, the wrapped storage deletes it, so that it reports the
+// same error it would for a missing key.
+func (o *OutboxStorage) Delete(ctx context.Context, namespace, key string) error {
+	if !o.tracked(namespace) {
+		return o.ServiceStorage.Delete(ctx, namespace, key)
+	}
+	deleted, err := o.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		value, err := tx.Read(ctx, namespace, key)
+		if err != nil || value == nil {
+			return false, err
+		}
+		return true, tx.Delete(ctx, namespace, key)
+	}, []WatchKey{{Namespace: namespace, Key: key}})
+	if err != nil {
+		return err
+	}
+	if !deleted.(bool) {
+		return o.ServiceStorage.Delete(ctx, namespace, key)
+	}
+	return nil
+}
+
+// DeleteNamespace records the deletion of every key of the namespace, before deleting the namespace itself when the
+// wrapped storage still lists it.
+func (o *OutboxStorage) DeleteNamespace(ctx context.Context, namespace string) error {
+	if !o.tracked(namespace) {
+		return o.ServiceStorage.DeleteNamespace(ctx, namespace)
+	}
+	keys, err := o.ServiceStorage.ReadAllKeys(ctx, namespace)
+	if err != nil {
+		return errors.Wrapf(err, "reading keys of namespace<%s>", namespace)
+	}
+	if len(keys) > 0 {
+		watchKeys := make([]WatchKey, 0, len(keys))
+		for _, key := range keys {
+			watchKeys = append(watchKeys, WatchKey{Namespace: namespace, Key: key})
+		}
+		_, err = o.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+			for _, key := range keys {
+				if err := tx.Delete(ctx, namespace, key); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}, watchKeys)
+		if err != nil {
+			return err
+		}
+		namespaces, err := o.ServiceStorage.Namespaces(ctx)
+		if err != nil {
+			return errors.Wrap(err, "listing namespaces")
+		}
+		if i := sort.SearchStrings(namespaces, namespace); i == len(namespaces) || namespaces[i] != namespace {
+			return nil
+		}
+	}
+	return o.ServiceStorage.DeleteNamespace(ctx, namespace)
+}
+
+// Update is performed by the wrapper, so that the change is recorded in the same transaction.
+func (o *OutboxStorage) Update(ctx context.Context, namespace string, key string, values map[string]any) ([]byte, error) {
+	if !o.tracked(namespace) {
+		return o.ServiceStorage.Update(ctx, namespace, key, values)
+	}
+	result, err := o.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		data, err := applyUpdater(ctx, tx, namespace, key, NewUpdater(values))
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, namespace, key, data); err != nil {
+			return nil, errors.Wrap(err, "writing to db")
+		}
+		return data, nil
+	}, []WatchKey{{Namespace: namespace, Key: key}})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+// UpdateValueAndOperation is performed by the wrapper when either namespace is tracked, for the same reason as Update.
+func (o *OutboxStorage) UpdateValueAndOperation(ctx context.Context, namespace, key string, updater Updater, opNamespace, opKey string, opUpdater ResponseSettingUpdater) (first, op []byte, err error) {
+	if !o.tracked(namespace) && !o.tracked(opNamespace) {
+		return o.ServiceStorage.UpdateValueAndOperation(ctx, namespace, key, updater, opNamespace, opKey, opUpdater)
+	}
+	type updated struct {
+		first, op []byte
+	}
+	result, err := o.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		firstData, err := applyUpdater(ctx, tx, namespace, key, updater)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, namespace, key, firstData); err != nil {
+			return nil, errors.Wrap(err, "writing to db")
+		}
+		opUpdater.SetUpdatedResponse(firstData)
+		opData, err := applyUpdater(ctx, tx, opNamespace, opKey, opUpdater)
+		if err != nil {
+			return nil, err
+		}
+		if err = tx.Write(ctx, opNamespace, opKey, opData); err != nil {
+			return nil, errors.Wrap(err, "writing operation")
+		}
+		return updated{first: firstData, op: opData}, nil
+	}, []WatchKey{{Namespace: namespace, Key: key}, {Namespace: opNamespace, Key: opKey}})
+	if err != nil {
+		return nil, nil, err
+	}
+	u := result.(updated)
+	return u.first, u.op, nil
+}
+
+// Execute records the changes made through the transaction in the outbox. Whether a write creates or updates a value
+// is decided by reading it within the transaction, so keys written by businessLogicFunc should also be watch keys for
+// the decision to be accurate under concurrent writes.
+func (o *OutboxStorage) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
+	recorded := false
+	result, err := o.ServiceStorage.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		otx := &outboxTx{tx: tx, storage: o}
+		result, err := businessLogicFunc(ctx, otx)
+		recorded = otx.recorded
+		return result, err
+	}, watchKeys)
+	if err == nil && recorded {
+		select {
+		case o.notify <- struct{}{}:
+		default:
+		}
+	}
+	return result, err
+}
+
+// tracked returns whether changes to the namespace are recorded in the outbox. The secondary indexes of tenants are
+// not tracked either.
+func (o *OutboxStorage) tracked(namespace string) bool {
+	_, namespace = SplitTenantNamespace(namespace)
+	return !strings.HasPrefix(namespace, outboxNamespacePrefix) && !strings.HasPrefix(namespace, getIndexNamespace(""))
+}
+
+// record writes the event for a change within the transaction.
+func (o *OutboxStorage) record(ctx context.Context, tx Tx, changeType ChangeType, namespace, key string, value []byte) error {
+	now := time.Now().UTC()
+	event := ChangeEvent{
+		ID:        fmt.Sprintf("%020d-%s", now.UnixNano(), uuid.NewString()),
+		Type:      changeType,
+		Namespace: namespace,
+		Key:       key,
+		Value:     value,
+		Time:      now,
+	}
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshalling change event")
+	}
+	if err = tx.WriteWithTTL(ctx, OutboxNamespace, event.ID, eventBytes, o.retention); err != nil {
+		return errors.Wrap(err, "writing change event")
+	}
+	return nil
+}
+
+type outboxTx struct {
+	tx       Tx
+	storage  *OutboxStorage
+	recorded bool
+}
+
+func (t *outboxTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	return t.tx.Read(ctx, namespace, key)
+}
+
+func (t *outboxTx) Write(ctx context.Context, namespace, key string, value []byte) error {
+	return t.WriteWithTTL(ctx, namespace, key, value, 0)
+}
+
+func (t *outboxTx) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	if !t.storage.tracked(namespace) {
+		return t.tx.WriteWithTTL(ctx, namespace, key, value, ttl)
+	}
+	previous, err := t.tx.Read(ctx, namespace, key)
+	if err != nil {
+		return errors.Wrapf(err, "reading key<%s> before writing it", key)
+	}
+	if err = t.tx.WriteWithTTL(ctx, namespace, key, value, ttl); err != nil {
+		return err
+	}
+	changeType := ChangeCreate
+	if previous != nil {
+		changeType = ChangeUpdate
+	}
+	if err = t.storage.record(ctx, t.tx, changeType, namespace, key, value); err != nil {
+		return err
+	}
+	t.recorded = true
+	return nil
+}
+
+func (t *outboxTx) Delete(ctx context.Context, namespace, key string) error {
+	if !t.storage.tracked(namespace) {
+		return t.tx.Delete(ctx, namespace, key)
+	}
+	previous, err := t.tx.Read(ctx, namespace, key)
+	if err != nil {
+		return errors.Wrapf(err, "reading key<%s> before deleting it", key)
+	}
+	if err = t.tx.Delete(ctx, namespace, key); err != nil {
+		return err
+	}
+	if previous == nil {
+		return nil
+	}
+	if err = t.storage.record(ctx, t.tx, ChangeDelete, namespace, key, previous); err != nil {
+		return err
+	}
+	t.recorded = true
+	return nil
+}
+
+// dispatch delivers the pending events of the outbox to every subscriber which has not handled them yet, oldest first,
+// and removes the events which all subscribers have handled. Failed deliveries are retried by later calls, with an
+// exponential backoff, until OutboxMaxAttempts is reached.
+func (o *OutboxStorage) dispatch(ctx context.Context, now time.Time) error {
+	if len(o.subscribers) == 0 {
+		return nil
+	}
+	ids, err := o.ServiceStorage.ReadAllKeys(ctx, OutboxNamespace)
+	if err != nil {
+		return errors.Wrap(err, "reading outbox")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Strings(ids)
+
+	delivered := make([]map[string]bool, len(o.subscribers))
+	for i, s := range o.subscribers {
+		deliveries, err := o.ServiceStorage.ReadPrefix(ctx, outboxDeliveriesNamespace, s.deliveryKey(""))
+		if err != nil {
+			return errors.Wrapf(err, "reading deliveries of outbox subscriber<%s>", s.name)
+		}
+		delivered[i] = make(map[string]bool, len(deliveries))
+		for key := range deliveries {
+			delivered[i][strings.TrimPrefix(key, s.deliveryKey(""))] = true
+		}
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return nil
+		}
+		eventBytes, err := o.ServiceStorage.Read(ctx, OutboxNamespace, id)
+		if err != nil {
+			return errors.Wrapf(err, "reading change event<%s>", id)
+		}
+		if eventBytes == nil {
+			// expired since the outbox was read
+			continue
+		}
+		var event ChangeEvent
+		if err = json.Unmarshal(eventBytes, &event); err != nil {
+			return errors.Wrapf(err, "unmarshalling change event<%s>", id)
+		}
+
+		done := true
+		for i, s := range o.subscribers {
+			if delivered[i][id] {
+				continue
+			}
+			if !s.deliver(ctx, event, now) {
+				done = false
+				continue
+			}
+			if err = o.ServiceStorage.WriteWithTTL(ctx, outboxDeliveriesNamespace, s.deliveryKey(id), []byte(now.UTC().Format(time.RFC3339Nano)), o.retention); err != nil {
+				return errors.Wrapf(err, "recording delivery of change event<%s> to outbox subscriber<%s>", id, s.name)
+			}
+		}
+		if !done {
+			continue
+		}
+		if err = o.ServiceStorage.Delete(ctx, OutboxNamespace, id); err != nil {
+			return errors.Wrapf(err, "deleting change event<%s>", id)
+		}
+		for _, s := range o.subscribers {
+			if err = o.ServiceStorage.Delete(ctx, outboxDeliveriesNamespace, s.deliveryKey(id)); err != nil {
+				return errors.Wrapf(err, "deleting delivery of change event<%s> to outbox subscriber<%s>", id, s.name)
+			}
+		}
+	}
+	return nil
+}
+
+// deliveryKey returns the key recording that the subscriber handled the event.
+func (s *outboxSubscriber) deliveryKey(eventID string) string {
+	return s.name + outboxDeliveryKeySep + eventID
+}
+
+// deliver hands the event to the subscriber, unless a previous attempt failed too recently, and returns whether the
+// subscriber is done with it: either it was handled, or the subscriber gave up after OutboxMaxAttempts.
+func (s *outboxSubscriber) deliver(ctx context.Context, event ChangeEvent, now time.Time) bool {
+	if retryAt, ok := s.retryAt[event.ID]; ok && now.Before(retryAt) {
+		return false
+	}
+	err := s.handler(ctx, event)
+	if err == nil {
+		delete(s.attempts, event.ID)
+		delete(s.retryAt, event.ID)
+		return true
+	}
+	s.attempts[event.ID]++
+	attempts := s.attempts[event.ID]
+	if attempts >= OutboxMaxAttempts {
+		logrus.WithError(err).Errorf("giving up on delivering change event<%s> to outbox subscriber<%s> after %d attempts", event.ID, s.name, attempts)
+		delete(s.attempts, event.ID)
+		delete(s.retryAt, event.ID)
+		return true
+	}
+	logrus.WithError(err).Warnf("delivering change event<%s> to outbox subscriber<%s>", event.ID, s.name)
+	s.retryAt[event.ID] = now.Add(OutboxPollInterval << (attempts - 1))
+	return false
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readOutbox(t *testing.T, db ServiceStorage) []ChangeEvent {
+	values, err := db.ReadAll(context.Background(), OutboxNamespace)
+	require.NoError(t, err)
+	events := make([]ChangeEvent, 0, len(values))
+	for _, value := range values {
+		var event ChangeEvent
+		require.NoError(t, json.Unmarshal(value, &event))
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
+
+func TestOutboxStorage(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+
+		outbox, err := NewOutboxStorage(db, time.Hour)
+		require.NoError(t, err)
+
+		require.NoError(t, outbox.Write(ctx, "driver", "max", []byte(`{"name":"Max"}`)))
+		_, err = outbox.Update(ctx, "driver", "max", map[string]any{"team": "Red Bull"})
+		require.NoError(t, err)
+		require.NoError(t, outbox.Delete(ctx, "driver", "max"))
+		require.NoError(t, WriteIndexed(ctx, outbox, "driver", "lewis", []byte(`{"name":"Lewis"}`), IndexEntry{Name: "team", Value: "mercedes"}))
+
+		// a failed transaction records nothing
+		_, err = outbox.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+			if err := tx.Write(ctx, "driver", "charles", []byte(`{"name":"Charles"}`)); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("boom")
+		}, nil)
+		assert.Error(t, err)
+
+		events := readOutbox(t, db)
+		require.Len(t, events, 4)
+		assert.Equal(t, ChangeCreate, events[0].Type)
+		assert.Equal(t, ChangeUpdate, events[1].Type)
+		assert.JSONEq(t, `{"name":"Max","team":"Red Bull"}`, string(events[1].Value))
+		assert.Equal(t, ChangeDelete, events[2].Type)
+		assert.JSONEq(t, `{"name":"Max","team":"Red Bull"}`, string(events[2].Value))
+		assert.Equal(t, ChangeCreate, events[3].Type)
+		assert.Equal(t, "lewis", events[3].Key)
+		for _, event := range events {
+			assert.Equal(t, "driver", event.Namespace)
+		}
+
+		// deleting a missing key records nothing, and reports what the wrapped storage does
+		assert.Equal(t, db.Delete(ctx, "driver", "missing") == nil, outbox.Delete(ctx, "driver", "missing") == nil)
+		assert.Len(t, readOutbox(t, db), 4)
+
+		require.NoError(t, outbox.DeleteNamespace(ctx, "driver"))
+		events = readOutbox(t, db)
+		require.Len(t, events, 5)
+		assert.Equal(t, ChangeDelete, events[4].Type)
+		assert.Equal(t, "lewis", events[4].Key)
+		namespaces, err := db.Namespaces(ctx)
+		require.NoError(t, err)
+		assert.NotContains(t, namespaces, "driver")
+	}
+}
+
+func TestOutboxStorageDispatch(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		ctx := context.Background()
+
+		outbox, err := NewOutboxStorage(db, time.Hour)
+		require.NoError(t, err)
+
+		var audited []string
+		require.NoError(t, outbox.Subscribe("audit", func(_ context.Context, event ChangeEvent) error {
+			audited = append(audited, event.Key)
+			return nil
+		}))
+		failures := 1
+		var notified []string
+		require.NoError(t, outbox.Subscribe("notify", func(_ context.Context, event ChangeEvent) error {
+			if failures > 0 {
+				failures--
+				return errors.New("unavailable")
+			}
+			notified = append(notified, event.Key)
+			return nil
+		}))
+		assert.Error(t, outbox.Subscribe("audit", nil))
+
+		require.NoError(t, outbox.Write(ctx, "driver", "max", []byte(`{"name":"Max"}`)))
+		require.NoError(t, outbox.Write(ctx, "driver", "lewis", []byte(`{"name":"Lewis"}`)))
+
+		// the failed event stays in the outbox, and is not delivered again to the subscriber which handled it
+		now := time.Now()
+		require.NoError(t, outbox.dispatch(ctx, now))
+		assert.Equal(t, []string{"max", "lewis"}, audited)
+		assert.Equal(t, []string{"lewis"}, notified)
+		assert.Len(t, readOutbox(t, db), 1)
+
+		// the retry waits for its backoff
+		require.NoError(t, outbox.dispatch(ctx, now))
+		assert.Equal(t, []string{"lewis"}, notified)
+		require.NoError(t, outbox.dispatch(ctx, now.Add(OutboxPollInterval)))
+		assert.Equal(t, []string{"max", "lewis"}, audited)
+		assert.Equal(t, []string{"lewis", "max"}, notified)
+		assert.Empty(t, readOutbox(t, db))
+		deliveries, err := db.ReadAllKeys(ctx, outboxDeliveriesNamespace)
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		require.NoError(t, outbox.Write(ctx, "driver", "charles", []byte(`{"name":"Charles"}`)))
+		outbox.Start()
+		assert.Eventually(t, func() bool {
+			return len(readOutbox(t, db)) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Error(t, outbox.Subscribe("late", nil))
+		assert.NoError(t, outbox.Close())
+		assert.Equal(t, []string{"lewis", "max", "charles"}, notified)
+	}
+}
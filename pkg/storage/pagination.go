@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.einride.tech/aip/pagination"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageCursor is the state needed to resume a paged read. It is handed to callers as an opaque string, following
+// https://google.aip.dev/158. Key ordered providers (bolt, sql) only use LastKey, while redis uses the SCAN Cursor
+// together with the Offset of the next key within the batch returned for that cursor.
+type pageCursor struct {
+	LastKey string
+	Cursor  uint64
+	Offset  int
+}
+
+func decodePageToken(token string) (*pageCursor, error) {
+	var decoded pageCursor
+	if token == "" {
+		return &decoded, nil
+	}
+	if err := pagination.DecodePageTokenStruct(token, &decoded); err != nil {
+		return nil, errors.Wrap(ErrInvalidPageToken, err.Error())
+	}
+	if decoded.Offset < 0 {
+		return nil, errors.Wrap(ErrInvalidPageToken, "negative offset")
+	}
+	return &decoded, nil
+}
+
+func (p pageCursor) encode() string {
+	return pagination.EncodePageTokenStruct(&p)
+}
+
+func pageTokenFromLastKey(lastKey string) string {
+	return pageCursor{LastKey: lastKey}.encode()
+}
+
+// PaginateKeys returns the page of keys described by token and pageSize, out of a set of keys which has already been
+// read into memory. The keys are sorted so that tokens remain valid between calls. A pageSize <= 0 returns all the keys
+// after the token.
+func PaginateKeys(keys []string, token string, pageSize int) (page []string, nextPageToken string, err error) {
+	decoded, err := decodePageToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	start := 0
+	if decoded.LastKey != "" {
+		start = sort.Search(len(sorted), func(i int) bool { return sorted[i] > decoded.LastKey })
+	}
+	remaining := sorted[start:]
+	if pageSize <= 0 || len(remaining) <= pageSize {
+		return remaining, "", nil
+	}
+	page = remaining[:pageSize]
+	return page, pageTokenFromLastKey(page[len(page)-1]), nil
+}
+
+// PaginateOffset returns the bounds of the page described by token and pageSize, out of total values which have already
+// been read into memory and sorted in an order other than that of their keys. Tokens hold the offset of the next page,
+// so the page a token resumes from shifts when values are added or removed in between calls. A pageSize <= 0 returns
+// all the values after the token.
+func PaginateOffset(total int, token string, pageSize int) (start, end int, nextPageToken string, err error) {
+	decoded, err := decodePageToken(token)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	start = decoded.Offset
+	if start > total {
+		start = total
+	}
+	if pageSize <= 0 || total-start <= pageSize {
+		return start, total, "", nil
+	}
+	end = start + pageSize
+	return start, end, pageCursor{Offset: end}.encode(), nil
+}
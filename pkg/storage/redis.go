@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,13 +32,38 @@ type RedisDB struct {
 	db *goredislib.Client
 }
 
+// redisTx queues writes in the MULTI/EXEC pipeline, and reads on the connection holding the WATCH. Since queued writes
+// are only sent on EXEC, they are also kept aside so that reads reflect them.
 type redisTx struct {
-	pipe goredislib.Pipeliner
+	tx     *goredislib.Tx
+	pipe   goredislib.Pipeliner
+	writes []bufferedWrite
+}
+
+func (rtx *redisTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	if value, ok := readBuffered(rtx.writes, namespace, key); ok {
+		return value, nil
+	}
+	res, err := rtx.tx.Get(ctx, getRedisKey(namespace, key)).Bytes()
+	if errors.Is(err, goredislib.Nil) {
+		return nil, nil
+	}
+	return res, err
 }
 
 func (rtx *redisTx) Write(ctx context.Context, namespace, key string, value []byte) error {
+	return rtx.WriteWithTTL(ctx, namespace, key, value, 0)
+}
+
+func (rtx *redisTx) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
 	nameSpaceKey := getRedisKey(namespace, key)
-	return rtx.pipe.Set(ctx, nameSpaceKey, value, 0).Err()
+	rtx.writes = append(rtx.writes, bufferedWrite{namespace: namespace, key: key, value: bytes.Clone(value), ttl: ttl})
+	return rtx.pipe.Set(ctx, nameSpaceKey, value, redisExpiration(ttl)).Err()
+}
+
+func (rtx *redisTx) Delete(ctx context.Context, namespace, key string) error {
+	rtx.writes = append(rtx.writes, bufferedWrite{namespace: namespace, key: key, delete: true})
+	return rtx.pipe.Del(ctx, getRedisKey(namespace, key)).Err()
 }
 
 func (b *RedisDB) Init(i interface{}) error {
@@ -88,7 +115,7 @@ func (b *RedisDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFu
 	txf := func(tx *goredislib.Tx) error {
 		// Operation is commited only if the watched keys remain unchanged.
 		_, err := tx.TxPipelined(ctx, func(pipe goredislib.Pipeliner) error {
-			redisTx := redisTx{pipe}
+			redisTx := redisTx{tx: tx, pipe: pipe}
 			var err error
 
 			finalOutput, err = businessLogicFunc(ctx, &redisTx)
@@ -138,21 +165,41 @@ func (b *RedisDB) Exists(ctx context.Context, namespace, key string) (bool, erro
 }
 
 func (b *RedisDB) Write(ctx context.Context, namespace, key string, value []byte) error {
+	return b.WriteWithTTL(ctx, namespace, key, value, 0)
+}
+
+func (b *RedisDB) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
 	nameSpaceKey := getRedisKey(namespace, key)
-	return b.db.Set(ctx, nameSpaceKey, value, 0).Err()
+	return b.db.Set(ctx, nameSpaceKey, value, redisExpiration(ttl)).Err()
+}
+
+// redisExpiration returns the expiration to set a key with, which keeps the expiry the key already has when the ttl is
+// not positive.
+func redisExpiration(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return goredislib.KeepTTL
+	}
+	return ttl
 }
 
 func (b *RedisDB) WriteMany(ctx context.Context, namespaces, keys []string, values [][]byte) error {
-	if len(namespaces) != len(keys) && len(namespaces) != len(values) {
+	if len(namespaces) != len(keys) || len(namespaces) != len(values) {
 		return errors.New("namespaces, keys, and values, are not of equal length")
 	}
-
-	nameSpaceKeys := make([]string, 0)
-	for i := range namespaces {
-		nameSpaceKeys = append(nameSpaceKeys, getRedisKey(namespaces[i], keys[i]))
+	if len(namespaces) == 0 {
+		return nil
 	}
 
-	return b.db.MSet(ctx, nameSpaceKeys, values, 0).Err()
+	// MSET would clear the expiry of the keys, so they are set one by one within a single transaction
+	_, err := b.db.TxPipelined(ctx, func(pipe goredislib.Pipeliner) error {
+		for i := range namespaces {
+			if err := pipe.Set(ctx, getRedisKey(namespaces[i], keys[i]), values[i], goredislib.KeepTTL).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
 }
 
 func (b *RedisDB) Read(ctx context.Context, namespace, key string) ([]byte, error) {
@@ -188,6 +235,53 @@ func (b *RedisDB) ReadAll(ctx context.Context, namespace string) (map[string][]b
 	return readAll(ctx, keys, b)
 }
 
+// ReadPage reads a page of values from the namespace by resuming a SCAN. Since a single SCAN call may return more keys
+// than fit in the page, the page token holds the cursor of the batch being read and the offset of the next key in it.
+// Keys are returned in no particular order.
+func (b *RedisDB) ReadPage(ctx context.Context, namespace, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	token, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursor, offset := token.Cursor, token.Offset
+	var keys []string
+	var nextPageToken string
+	for {
+		batch, nextCursor, err := b.db.Scan(ctx, cursor, getRedisKey(namespace, "*"), RedisScanBatchSize).Result()
+		if err != nil {
+			return nil, "", errors.Wrap(err, "scan error")
+		}
+		if offset > len(batch) {
+			offset = len(batch)
+		}
+		batch = batch[offset:]
+
+		if remaining := pageSize - len(keys); pageSize > 0 && len(batch) > remaining {
+			keys = append(keys, batch[:remaining]...)
+			nextPageToken = pageCursor{Cursor: cursor, Offset: offset + remaining}.encode()
+			break
+		}
+		keys = append(keys, batch...)
+		offset = 0
+
+		if nextCursor == 0 {
+			break
+		}
+		cursor = nextCursor
+		if pageSize > 0 && len(keys) == pageSize {
+			nextPageToken = pageCursor{Cursor: cursor}.encode()
+			break
+		}
+	}
+
+	result, err := readAll(ctx, keys, b)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, nextPageToken, nil
+}
+
 // TODO: This potentially could dangerous as it might run out of memory as we populate result
 func readAll(ctx context.Context, keys []string, b *RedisDB) (map[string][]byte, error) {
 	result := make(map[string][]byte, len(keys))
@@ -259,6 +353,26 @@ func readAllKeys(ctx context.Context, namespace string, b *RedisDB) ([]string, e
 	return allKeys, nil
 }
 
+// Namespaces scans every key, and returns the distinct namespaces the keys belong to.
+func (b *RedisDB) Namespaces(ctx context.Context) ([]string, error) {
+	keys, err := readAllKeys(ctx, "", b)
+	if err != nil {
+		return nil, errors.Wrap(err, "read all keys")
+	}
+	namespaces := make(map[string]bool)
+	for _, key := range keys {
+		if i := strings.Index(key, NamespaceKeySeparator); i >= 0 {
+			namespaces[key[:i]] = true
+		}
+	}
+	result := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		result = append(result, namespace)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 func (b *RedisDB) Delete(ctx context.Context, namespace, key string) error {
 	nameSpaceKey := getRedisKey(namespace, key)
 
@@ -339,7 +453,7 @@ func txWithUpdater(ctx context.Context, namespace, key string, updater Updater,
 		return nil, err
 	}
 
-	if err = b.db.Set(ctx, nameSpaceKey, data, 0).Err(); err != nil {
+	if err = b.db.Set(ctx, nameSpaceKey, data, goredislib.KeepTTL).Err(); err != nil {
 		return nil, errors.Wrap(err, "writing to db")
 	}
 
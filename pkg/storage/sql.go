@@ -0,0 +1,691 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/lib/pq"           // also registers the "postgres" driver
+	"github.com/mattn/go-sqlite3" // also registers the "sqlite3" driver
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	if err := RegisterStorage(new(SQLDB)); err != nil {
+		panic(err)
+	}
+}
+
+const (
+	SQLiteDriver   = "sqlite3"
+	PostgresDriver = "postgres"
+
+	SQLDriverOption = "driver"
+	SQLDSNOption    = "dsn"
+
+	sqlTableName       = "ssi_service_kv"
+	sqlExpiryTableName = "ssi_service_kv_expiry"
+
+	// sqliteBusyTimeout is how long a SQLite connection waits for another connection to release its lock on the
+	// database before failing with SQLITE_BUSY, unless the dsn sets a timeout of its own.
+	sqliteBusyTimeout = 5 * time.Second
+
+	// The PostgreSQL error codes of the transactions which conflicted with another one.
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+var errWatchKeyChanged = errors.New("watched key changed during transaction")
+
+// SQLDB is a ServiceStorage backed by a single key/value table in a SQL database. Both SQLite and PostgreSQL are
+// supported. Each row carries a version which is incremented on every write, and is used to implement the optimistic
+// concurrency semantics of WatchKey in Execute. The deadlines of values written with a TTL are kept in a second table,
+// which is swept in the background.
+type SQLDB struct {
+	db          *sql.DB
+	driver      string
+	dsn         string
+	stopSweeper func()
+}
+
+type sqlTx struct {
+	db      *SQLDB
+	tx      *sql.Tx
+	written map[WatchKey]bool
+}
+
+func (stx *sqlTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	value, _, err := stx.db.read(ctx, stx.tx, namespace, key)
+	return value, err
+}
+
+func (stx *sqlTx) Write(ctx context.Context, namespace, key string, value []byte) error {
+	if err := stx.db.upsert(ctx, stx.tx, namespace, key, value); err != nil {
+		return err
+	}
+	stx.written[WatchKey{Namespace: namespace, Key: key}] = true
+	return nil
+}
+
+func (stx *sqlTx) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	if err := stx.db.upsertWithTTL(ctx, stx.tx, namespace, key, value, ttl); err != nil {
+		return err
+	}
+	stx.written[WatchKey{Namespace: namespace, Key: key}] = true
+	return nil
+}
+
+func (stx *sqlTx) Delete(ctx context.Context, namespace, key string) error {
+	if err := stx.db.delete(ctx, stx.tx, namespace, key); err != nil {
+		return err
+	}
+	stx.written[WatchKey{Namespace: namespace, Key: key}] = true
+	return nil
+}
+
+// Init opens a connection to the database described by the options, and creates the backing table if needed.
+// The options must be a map containing a "driver" (one of "sqlite3" or "postgres") and a "dsn" value.
+func (s *SQLDB) Init(i interface{}) error {
+	if s.db != nil {
+		return fmt.Errorf("sql db already opened with dsn %s", s.URI())
+	}
+	options, ok := i.(map[string]interface{})
+	if !ok {
+		return errors.New("options should be a map with a driver and dsn")
+	}
+	driver, _ := options[SQLDriverOption].(string)
+	if driver == "" {
+		driver = SQLiteDriver
+	}
+	if driver != SQLiteDriver && driver != PostgresDriver {
+		return fmt.Errorf("unsupported sql driver: %s", driver)
+	}
+	dsn, _ := options[SQLDSNOption].(string)
+	if dsn == "" {
+		return errors.New("a dsn must be provided for sql storage")
+	}
+
+	openDSN := dsn
+	if driver == SQLiteDriver {
+		openDSN = sqliteDSN(dsn)
+	}
+	db, err := sql.Open(driver, openDSN)
+	if err != nil {
+		return errors.Wrap(err, "opening sql db")
+	}
+	if driver == SQLiteDriver {
+		// WAL allows readers outside a transaction to proceed while a write transaction is open, which Execute
+		// relies on for the business logic's reads.
+		if _, err = db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+			_ = db.Close()
+			return errors.Wrap(err, "enabling sqlite WAL mode")
+		}
+	}
+
+	s.db = db
+	s.driver = driver
+	s.dsn = dsn
+
+	if err = s.createTable(context.Background()); err != nil {
+		_ = db.Close()
+		s.db = nil
+		return errors.Wrap(err, "creating kv table")
+	}
+	s.stopSweeper = startExpirySweeper(s)
+	return nil
+}
+
+// sqliteDSN returns the dsn with a busy timeout, unless it sets a timeout of its own, so that the connections of the
+// pool wait for each other rather than failing as soon as the database is locked.
+func sqliteDSN(dsn string) string {
+	if strings.Contains(dsn, "_timeout=") {
+		return dsn
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_busy_timeout=" + strconv.FormatInt(sqliteBusyTimeout.Milliseconds(), 10)
+}
+
+func (s *SQLDB) createTable(ctx context.Context) error {
+	valueType := "BLOB"
+	if s.driver == PostgresDriver {
+		valueType = "BYTEA"
+	}
+	stmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	namespace TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value %s,
+	version BIGINT NOT NULL DEFAULT 1,
+	PRIMARY KEY (namespace, key)
+)`, sqlTableName, valueType)
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+	expiryStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	namespace TEXT NOT NULL,
+	key TEXT NOT NULL,
+	expires_at BIGINT NOT NULL,
+	PRIMARY KEY (namespace, key)
+)`, sqlExpiryTableName)
+	if _, err := s.db.ExecContext(ctx, expiryStmt); err != nil {
+		return err
+	}
+	indexStmt := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_expires_at ON %s (expires_at)`, sqlExpiryTableName, sqlExpiryTableName)
+	_, err := s.db.ExecContext(ctx, indexStmt)
+	return err
+}
+
+func (s *SQLDB) URI() string {
+	return s.dsn
+}
+
+func (s *SQLDB) IsOpen() bool {
+	if s.db == nil {
+		return false
+	}
+	return s.db.Ping() == nil
+}
+
+func (s *SQLDB) Type() Type {
+	return SQL
+}
+
+func (s *SQLDB) Close() error {
+	if s.stopSweeper != nil {
+		s.stopSweeper()
+	}
+	return s.db.Close()
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// rebind replaces the "?" placeholders in the query with the syntax expected by the configured driver.
+func (s *SQLDB) rebind(query string) string {
+	if s.driver != PostgresDriver {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *SQLDB) upsert(ctx context.Context, q queryer, namespace, key string, value []byte) error {
+	stmt := fmt.Sprintf(`INSERT INTO %s (namespace, key, value, version) VALUES (?, ?, ?, 1)
+ON CONFLICT (namespace, key) DO UPDATE SET value = excluded.value, version = %s.version + 1`, sqlTableName, sqlTableName)
+	if _, err := q.ExecContext(ctx, s.rebind(stmt), namespace, key, value); err != nil {
+		return errors.Wrapf(err, "writing key<%s> in namespace<%s>", key, namespace)
+	}
+	return nil
+}
+
+// upsertWithTTL writes the value, and sets it to expire once the ttl has passed when it is positive.
+func (s *SQLDB) upsertWithTTL(ctx context.Context, q queryer, namespace, key string, value []byte, ttl time.Duration) error {
+	if err := s.upsert(ctx, q, namespace, key, value); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %s (namespace, key, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (namespace, key) DO UPDATE SET expires_at = excluded.expires_at`, sqlExpiryTableName)
+	if _, err := q.ExecContext(ctx, s.rebind(stmt), namespace, key, time.Now().Add(ttl).UnixNano()); err != nil {
+		return errors.Wrapf(err, "setting expiry of key<%s> in namespace<%s>", key, namespace)
+	}
+	return nil
+}
+
+// delete removes the key along with its expiry.
+func (s *SQLDB) delete(ctx context.Context, q queryer, namespace, key string) error {
+	for _, table := range []string{sqlTableName, sqlExpiryTableName} {
+		stmt := fmt.Sprintf(`DELETE FROM %s WHERE namespace = ? AND key = ?`, table)
+		if _, err := q.ExecContext(ctx, s.rebind(stmt), namespace, key); err != nil {
+			return errors.Wrapf(err, "deleting key<%s> in namespace<%s>", key, namespace)
+		}
+	}
+	return nil
+}
+
+func (s *SQLDB) sweepExpired(ctx context.Context, now time.Time) (int, error) {
+	var removed int64
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		stmt := fmt.Sprintf(`DELETE FROM %s WHERE EXISTS (
+	SELECT 1 FROM %s e WHERE e.namespace = %s.namespace AND e.key = %s.key AND e.expires_at <= ?
+)`, sqlTableName, sqlExpiryTableName, sqlTableName, sqlTableName)
+		res, err := tx.ExecContext(ctx, s.rebind(stmt), now.UnixNano())
+		if err != nil {
+			return errors.Wrap(err, "deleting expired values")
+		}
+		if removed, err = res.RowsAffected(); err != nil {
+			return err
+		}
+		expiryStmt := fmt.Sprintf(`DELETE FROM %s WHERE expires_at <= ?`, sqlExpiryTableName)
+		if _, err = tx.ExecContext(ctx, s.rebind(expiryStmt), now.UnixNano()); err != nil {
+			return errors.Wrap(err, "deleting expiries")
+		}
+		return nil
+	})
+	return int(removed), err
+}
+
+func (s *SQLDB) read(ctx context.Context, q queryer, namespace, key string) ([]byte, bool, error) {
+	stmt := fmt.Sprintf(`SELECT value FROM %s WHERE namespace = ? AND key = ?`, sqlTableName)
+	var value []byte
+	err := q.QueryRowContext(ctx, s.rebind(stmt), namespace, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "reading key<%s> in namespace<%s>", key, namespace)
+	}
+	return value, true, nil
+}
+
+// version returns the current version of the given key, or 0 when the key does not exist.
+func (s *SQLDB) version(ctx context.Context, q queryer, namespace, key string) (int64, error) {
+	stmt := fmt.Sprintf(`SELECT version FROM %s WHERE namespace = ? AND key = ?`, sqlTableName)
+	var version int64
+	err := q.QueryRowContext(ctx, s.rebind(stmt), namespace, key).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// watchKeysUnchanged reports whether every watch key still has the version recorded in snapshot. Keys written within
+// the transaction are already locked by it, so their committed version is read outside the transaction. All other keys
+// are read (and locked, where the driver supports it) from within the transaction so that they can't change until it
+// is committed.
+func (s *SQLDB) watchKeysUnchanged(ctx context.Context, stx *sqlTx, watchKeys []WatchKey, snapshot []int64) (bool, error) {
+	lockClause := ""
+	if s.driver == PostgresDriver {
+		lockClause = " FOR UPDATE"
+	}
+	for i, wk := range watchKeys {
+		var current int64
+		var err error
+		if stx.written[wk] {
+			current, err = s.version(ctx, s.db, wk.Namespace, wk.Key)
+		} else {
+			stmt := fmt.Sprintf(`SELECT version FROM %s WHERE namespace = ? AND key = ?%s`, sqlTableName, lockClause)
+			err = stx.tx.QueryRowContext(ctx, s.rebind(stmt), wk.Namespace, wk.Key).Scan(&current)
+			if errors.Is(err, sql.ErrNoRows) {
+				current, err = 0, nil
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+		if current != snapshot[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isRetryableSQLError reports whether the error is caused by a concurrent transaction, so that retrying may succeed:
+// a SQLite database which is still busy or locked after the busy timeout, or a PostgreSQL serialization failure or
+// deadlock.
+func isRetryableSQLError(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+	}
+	return false
+}
+
+// retryableTxError returns the error as is when the transaction it failed may be retried, and as a permanent error
+// otherwise.
+func retryableTxError(err error) error {
+	if isRetryableSQLError(err) {
+		logrus.WithError(err).Warn("Transaction conflicted with another. Retrying..")
+		return err
+	}
+	return backoff.Permanent(err)
+}
+
+// Execute runs the provided function within a transaction. Any failure during execution results in a rollback.
+// The values of the watchKeys are snapshotted before businessLogicFunc runs, and compared again right before the
+// transaction is committed. When any of them changed, the transaction is rolled back and retried with exponential
+// backoff, mirroring the behavior of the redis implementation. It is also retried when it fails because of a
+// concurrent transaction, as reported by isRetryableSQLError.
+func (s *SQLDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
+	var finalOutput any
+	txf := func() error {
+		snapshot := make([]int64, len(watchKeys))
+		for i, wk := range watchKeys {
+			v, err := s.version(ctx, s.db, wk.Namespace, wk.Key)
+			if err != nil {
+				return retryableTxError(errors.Wrap(err, "reading watch key version"))
+			}
+			snapshot[i] = v
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return retryableTxError(errors.Wrap(err, "beginning transaction"))
+		}
+		// Rollback is a no-op once the transaction has been committed.
+		defer func() {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				logrus.WithError(err).Error("unable to roll back")
+			}
+		}()
+
+		stx := &sqlTx{db: s, tx: tx, written: make(map[WatchKey]bool)}
+		output, err := businessLogicFunc(ctx, stx)
+		if err != nil {
+			return retryableTxError(errors.Wrap(err, "executing business logic func"))
+		}
+
+		unchanged, err := s.watchKeysUnchanged(ctx, stx, watchKeys, snapshot)
+		if err != nil {
+			return retryableTxError(errors.Wrap(err, "checking watch keys"))
+		}
+		if !unchanged {
+			return errWatchKeyChanged
+		}
+
+		if err = tx.Commit(); err != nil {
+			return retryableTxError(errors.Wrap(err, "committing transaction"))
+		}
+		finalOutput = output
+		return nil
+	}
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxElapsedTime = MaxElapsedTime
+
+	err := backoff.Retry(func() error {
+		err := txf()
+		if errors.Is(err, errWatchKeyChanged) {
+			logrus.Warn("Optimistic lock lost. Retrying..")
+		}
+		return err
+	}, expBackoff)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute after retrying")
+	}
+	return finalOutput, nil
+}
+
+func (s *SQLDB) Exists(ctx context.Context, namespace, key string) (bool, error) {
+	_, exists, err := s.read(ctx, s.db, namespace, key)
+	return exists, err
+}
+
+func (s *SQLDB) Write(ctx context.Context, namespace, key string, value []byte) error {
+	return s.upsert(ctx, s.db, namespace, key, value)
+}
+
+func (s *SQLDB) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		return s.upsertWithTTL(ctx, tx, namespace, key, value, ttl)
+	})
+}
+
+func (s *SQLDB) WriteMany(ctx context.Context, namespaces, keys []string, values [][]byte) error {
+	if len(namespaces) != len(keys) || len(namespaces) != len(values) {
+		return errors.New("namespaces, keys, and values, are not of equal length")
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for i := range namespaces {
+			if err := s.upsert(ctx, tx, namespaces[i], keys[i], values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLDB) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	value, _, err := s.read(ctx, s.db, namespace, key)
+	return value, err
+}
+
+// ReadPrefix does a prefix query within a namespace.
+func (s *SQLDB) ReadPrefix(ctx context.Context, namespace, prefix string) (map[string][]byte, error) {
+	stmt := fmt.Sprintf(`SELECT key, value FROM %s WHERE namespace = ? AND substr(key, 1, ?) = ?`, sqlTableName)
+	return s.readRows(ctx, s.rebind(stmt), namespace, utf8.RuneCountInString(prefix), prefix)
+}
+
+func (s *SQLDB) ReadAll(ctx context.Context, namespace string) (map[string][]byte, error) {
+	stmt := fmt.Sprintf(`SELECT key, value FROM %s WHERE namespace = ?`, sqlTableName)
+	return s.readRows(ctx, s.rebind(stmt), namespace)
+}
+
+// ReadPage reads a page of values from the namespace in key order. The page token holds the last key returned.
+func (s *SQLDB) ReadPage(ctx context.Context, namespace, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	token, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	stmt := fmt.Sprintf(`SELECT key, value FROM %s WHERE namespace = ? AND key > ? ORDER BY key`, sqlTableName)
+	args := []any{namespace, token.LastKey}
+	if pageSize > 0 {
+		// fetch one extra row to know whether there is a next page
+		stmt += " LIMIT ?"
+		args = append(args, pageSize+1)
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(stmt), args...)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "querying page")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	result := make(map[string][]byte)
+	var lastKey, nextPageToken string
+	for rows.Next() {
+		if pageSize > 0 && len(result) == pageSize {
+			nextPageToken = pageTokenFromLastKey(lastKey)
+			break
+		}
+		var key string
+		var value []byte
+		if err = rows.Scan(&key, &value); err != nil {
+			return nil, "", errors.Wrap(err, "scanning row")
+		}
+		result[key] = value
+		lastKey = key
+	}
+	return result, nextPageToken, rows.Err()
+}
+
+func (s *SQLDB) readRows(ctx context.Context, query string, args ...any) (map[string][]byte, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying rows")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	result := make(map[string][]byte)
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err = rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "scanning row")
+		}
+		result[key] = value
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLDB) ReadAllKeys(ctx context.Context, namespace string) ([]string, error) {
+	stmt := fmt.Sprintf(`SELECT key FROM %s WHERE namespace = ? ORDER BY key`, sqlTableName)
+	rows, err := s.db.QueryContext(ctx, s.rebind(stmt), namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying keys")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	result := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, errors.Wrap(err, "scanning key")
+		}
+		result = append(result, key)
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLDB) Namespaces(ctx context.Context) ([]string, error) {
+	stmt := fmt.Sprintf(`SELECT DISTINCT namespace FROM %s ORDER BY namespace`, sqlTableName)
+	rows, err := s.db.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying namespaces")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	result := make([]string, 0)
+	for rows.Next() {
+		var namespace string
+		if err = rows.Scan(&namespace); err != nil {
+			return nil, errors.Wrap(err, "scanning namespace")
+		}
+		result = append(result, namespace)
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLDB) namespaceExists(ctx context.Context, q queryer, namespace string) (bool, error) {
+	stmt := fmt.Sprintf(`SELECT 1 FROM %s WHERE namespace = ? LIMIT 1`, sqlTableName)
+	var one int
+	err := q.QueryRowContext(ctx, s.rebind(stmt), namespace).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *SQLDB) Delete(ctx context.Context, namespace, key string) error {
+	exists, err := s.namespaceExists(ctx, s.db, namespace)
+	if err != nil {
+		return errors.Wrap(err, "checking namespace")
+	}
+	if !exists {
+		return errors.Errorf("namespace<%s> does not exist", namespace)
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		return s.delete(ctx, tx, namespace, key)
+	})
+}
+
+func (s *SQLDB) DeleteNamespace(ctx context.Context, namespace string) error {
+	stmt := fmt.Sprintf(`DELETE FROM %s WHERE namespace = ?`, sqlTableName)
+	res, err := s.db.ExecContext(ctx, s.rebind(stmt), namespace)
+	if err != nil {
+		return errors.Wrapf(err, "could not delete namespace<%s>", namespace)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "could not delete namespace<%s>", namespace)
+	}
+	if affected == 0 {
+		return errors.Errorf("could not delete namespace<%s>, namespace does not exist", namespace)
+	}
+	expiryStmt := fmt.Sprintf(`DELETE FROM %s WHERE namespace = ?`, sqlExpiryTableName)
+	if _, err = s.db.ExecContext(ctx, s.rebind(expiryStmt), namespace); err != nil {
+		return errors.Wrapf(err, "could not delete expiries of namespace<%s>", namespace)
+	}
+	return nil
+}
+
+func (s *SQLDB) Update(ctx context.Context, namespace string, key string, values map[string]any) ([]byte, error) {
+	var updatedData []byte
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		data, err := s.updateTx(ctx, tx, namespace, key, NewUpdater(values))
+		if err != nil {
+			return err
+		}
+		updatedData = data
+		return nil
+	})
+	return updatedData, err
+}
+
+// UpdateValueAndOperation updates the value stored in (namespace,key) with the new values specified in the map.
+// The updated value is then stored inside the (opNamespace, opKey), and the "done" value is set to true.
+func (s *SQLDB) UpdateValueAndOperation(ctx context.Context, namespace, key string, updater Updater, opNamespace, opKey string, opUpdater ResponseSettingUpdater) (first, op []byte, err error) {
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		first, err = s.updateTx(ctx, tx, namespace, key, updater)
+		if err != nil {
+			return err
+		}
+		opUpdater.SetUpdatedResponse(first)
+		op, err = s.updateTx(ctx, tx, opNamespace, opKey, opUpdater)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return first, op, nil
+}
+
+func (s *SQLDB) updateTx(ctx context.Context, tx *sql.Tx, namespace, key string, updater Updater) ([]byte, error) {
+	v, exists, err := s.read(ctx, tx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.Errorf("key not found %s", key)
+	}
+	if err = updater.Validate(v); err != nil {
+		return nil, errors.Wrap(err, "validating update")
+	}
+	data, err := updater.Update(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.upsert(ctx, tx, namespace, key, data); err != nil {
+		return nil, errors.Wrap(err, "writing to db")
+	}
+	return data, nil
+}
+
+// withTx runs fn inside a transaction, committing when fn succeeds and rolling back otherwise.
+func (s *SQLDB) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "beginning transaction")
+	}
+	if err = fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logrus.Errorf("problem rolling back %s", rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
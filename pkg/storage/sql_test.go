@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPostgresDB returns a SQLDB backed by the PostgreSQL database of the SSI_TEST_POSTGRES_DSN environment variable,
+// and skips the test when it is not set.
+func setupPostgresDB(t *testing.T) *SQLDB {
+	dsn := os.Getenv("SSI_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SSI_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := NewStorage(SQL, map[string]interface{}{SQLDriverOption: PostgresDriver, SQLDSNOption: dsn})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.DeleteNamespace(context.Background(), "concurrent")
+		_ = db.Close()
+	})
+	return db.(*SQLDB)
+}
+
+func TestSQLDB_ConcurrentExecute(t *testing.T) {
+	for name, setup := range map[string]func(t *testing.T) *SQLDB{"sqlite": setupSQLDB, "postgres": setupPostgresDB} {
+		setup := setup
+		t.Run(name, func(tt *testing.T) {
+			db := setup(tt)
+			namespace := "concurrent"
+			require.NoError(tt, db.Write(context.Background(), namespace, "counter", []byte("0")))
+
+			// every increment is kept, although the transactions contend for the same key
+			const increments = 20
+			var wg sync.WaitGroup
+			errs := make(chan error, increments)
+			for i := 0; i < increments; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+						value, err := tx.Read(ctx, namespace, "counter")
+						if err != nil {
+							return nil, err
+						}
+						counter, err := strconv.Atoi(string(value))
+						if err != nil {
+							return nil, err
+						}
+						return nil, tx.Write(ctx, namespace, "counter", []byte(strconv.Itoa(counter+1)))
+					}, []WatchKey{{Namespace: namespace, Key: "counter"}})
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				assert.NoError(tt, err)
+			}
+
+			value, err := db.Read(context.Background(), namespace, "counter")
+			require.NoError(tt, err)
+			assert.Equal(tt, strconv.Itoa(increments), string(value))
+		})
+	}
+}
+
+func TestSQLDB_ExecuteRetriesWhenSnapshotIsStale(t *testing.T) {
+	db := setupSQLDB(t)
+	namespace := "stale"
+	require.NoError(t, db.Write(context.Background(), namespace, "counter", []byte("0")))
+
+	calls := 0
+	_, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+		calls++
+		// the transaction reads from a snapshot of the database
+		if _, err := tx.Read(ctx, namespace, "other"); err != nil {
+			return nil, err
+		}
+		if calls == 1 {
+			// which another connection writes to before the transaction does, so that its write fails with SQLITE_BUSY
+			if err := db.Write(ctx, namespace, "counter", []byte("1")); err != nil {
+				return nil, err
+			}
+		}
+		return nil, tx.Write(ctx, namespace, "other", []byte("value"))
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestSQLDB_WriteMany(t *testing.T) {
+	db := setupSQLDB(t)
+
+	err := db.WriteMany(context.Background(), []string{"ns", "ns"}, []string{"a", "b"}, [][]byte{[]byte("a")})
+	assert.ErrorContains(t, err, "not of equal length")
+
+	require.NoError(t, db.WriteMany(context.Background(), []string{"ns", "ns"}, []string{"a", "b"}, [][]byte{[]byte("a"), []byte("b")}))
+	values, err := db.ReadAll(context.Background(), "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("a"), "b": []byte("b")}, values)
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "test.sqlite?_busy_timeout=5000", sqliteDSN("test.sqlite"))
+	assert.Equal(t, "file:test.sqlite?cache=shared&_busy_timeout=5000", sqliteDSN("file:test.sqlite?cache=shared"))
+	assert.Equal(t, "test.sqlite?_timeout=100", sqliteDSN("test.sqlite?_timeout=100"))
+}
+
+func TestSQLDB_PostgresExecuteLocksWatchKeys(t *testing.T) {
+	conn := &recordingConn{}
+	db := &SQLDB{db: sql.OpenDB(recordingConnector{conn: conn}), driver: PostgresDriver}
+	t.Cleanup(func() {
+		_ = db.db.Close()
+	})
+
+	_, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+		return nil, tx.Write(ctx, "ns", "written", []byte("value"))
+	}, []WatchKey{{Namespace: "ns", Key: "watched"}, {Namespace: "ns", Key: "written"}})
+	require.NoError(t, err)
+
+	statements := conn.recorded()
+	require.Len(t, statements, 7)
+	assert.Equal(t, "SELECT version FROM ssi_service_kv WHERE namespace = $1 AND key = $2 [ns watched]", statements[0])
+	assert.Equal(t, "SELECT version FROM ssi_service_kv WHERE namespace = $1 AND key = $2 [ns written]", statements[1])
+	assert.Equal(t, "BEGIN", statements[2])
+	assert.True(t, strings.HasPrefix(statements[3], "INSERT INTO ssi_service_kv"))
+	// the watch keys which were not written are locked until the transaction commits, and the written one is already
+	assert.Equal(t, "SELECT version FROM ssi_service_kv WHERE namespace = $1 AND key = $2 FOR UPDATE [ns watched]", statements[4])
+	assert.Equal(t, "SELECT version FROM ssi_service_kv WHERE namespace = $1 AND key = $2 [ns written]", statements[5])
+	assert.Equal(t, "COMMIT", statements[6])
+}
+
+// recordingConnector opens the same recordingConn for every connection of the pool.
+type recordingConnector struct {
+	conn *recordingConn
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+// recordingConn is a driver connection which records the statements run through it, and answers every query with a
+// single row holding the version 1.
+type recordingConn struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (c *recordingConn) record(statement string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, statement)
+}
+
+func (c *recordingConn) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.statements...)
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.record("BEGIN")
+	return recordingTx{conn: c}, nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.record(strings.Join(strings.Fields(query), " "))
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		values = append(values, fmt.Sprint(arg.Value))
+	}
+	c.record(fmt.Sprintf("%s [%s]", query, strings.Join(values, " ")))
+	return &versionRows{}, nil
+}
+
+type recordingTx struct {
+	conn *recordingConn
+}
+
+func (tx recordingTx) Commit() error {
+	tx.conn.record("COMMIT")
+	return nil
+}
+
+func (tx recordingTx) Rollback() error {
+	tx.conn.record("ROLLBACK")
+	return nil
+}
+
+// versionRows is a single row holding the version 1.
+type versionRows struct {
+	done bool
+}
+
+func (r *versionRows) Columns() []string {
+	return []string{"version"}
+}
+
+func (r *versionRows) Close() error {
+	return nil
+}
+
+func (r *versionRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 type Type string
@@ -15,13 +17,47 @@ type WatchKey struct {
 	Key       string
 }
 
+// Tx is the transaction a BusinessLogicFunc runs in. Values read through the transaction reflect the writes made
+// earlier in the same transaction. Reading a key does not lock it: keys whose value the business logic depends on must
+// be passed as watch keys to Execute, so that the transaction is retried when they change before it is committed.
 type Tx interface {
+	// Read returns the value of the key, or nil when it does not exist.
+	Read(ctx context.Context, namespace, key string) ([]byte, error)
 	Write(ctx context.Context, namespace, key string, value []byte) error
+	// WriteWithTTL is like ServiceStorage.WriteWithTTL.
+	WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error
+	// Delete removes the key from the namespace. Deleting a key that does not exist is not an error.
+	Delete(ctx context.Context, namespace, key string) error
+}
+
+// bufferedWrite is a write or delete held back until a transaction commits, for the implementations which cannot
+// write within a transaction until its watch keys have been checked.
+type bufferedWrite struct {
+	namespace string
+	key       string
+	value     []byte
+	ttl       time.Duration
+	delete    bool
+}
+
+// readBuffered returns the value the buffered writes leave the key with, and whether any of them touched it.
+func readBuffered(writes []bufferedWrite, namespace, key string) ([]byte, bool) {
+	for i := len(writes) - 1; i >= 0; i-- {
+		if writes[i].namespace == namespace && writes[i].key == key {
+			if writes[i].delete {
+				return nil, true
+			}
+			return bytes.Clone(writes[i].value), true
+		}
+	}
+	return nil, false
 }
 
 const (
-	Bolt  Type = "bolt"
-	Redis Type = "redis"
+	Bolt   Type = "bolt"
+	Redis  Type = "redis"
+	SQL    Type = "sql"
+	Memory Type = "memory"
 )
 
 var (
@@ -35,13 +71,25 @@ type ServiceStorage interface {
 	URI() string
 	IsOpen() bool
 	Close() error
+	// Write stores the value under the key. When the key was written with a TTL, it keeps its expiry.
 	Write(ctx context.Context, namespace, key string, value []byte) error
+	// WriteWithTTL stores the value under the key, and removes it once the ttl has passed. Redis expires keys natively.
+	// The other providers keep an index of expiring keys, which is swept every ExpirySweepInterval, so an expired
+	// value may still be read until the next sweep. Deleting the key clears its expiry, and a ttl <= 0 is the same as
+	// Write.
+	WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error
 	WriteMany(ctx context.Context, namespace, key []string, value [][]byte) error
 	Read(ctx context.Context, namespace, key string) ([]byte, error)
 	Exists(ctx context.Context, namespace, key string) (bool, error)
 	ReadAll(ctx context.Context, namespace string) (map[string][]byte, error)
+	// ReadPage returns at most pageSize key/value pairs from the namespace, resuming from the position encoded in
+	// pageToken. An empty pageToken starts at the beginning of the namespace, and an empty nextPageToken signals there
+	// are no more results. A pageSize <= 0 returns all remaining values.
+	ReadPage(ctx context.Context, namespace, pageToken string, pageSize int) (results map[string][]byte, nextPageToken string, err error)
 	ReadPrefix(ctx context.Context, namespace, prefix string) (map[string][]byte, error)
 	ReadAllKeys(ctx context.Context, namespace string) ([]string, error)
+	// Namespaces returns the names of all the namespaces in the storage, sorted.
+	Namespaces(ctx context.Context) ([]string, error)
 	Delete(ctx context.Context, namespace, key string) error
 	DeleteNamespace(ctx context.Context, namespace string) error
 	Update(ctx context.Context, namespace string, key string, values map[string]any) ([]byte, error)
@@ -71,7 +119,7 @@ func RegisterStorage(storage ServiceStorage) error {
 
 // AvailableStorage returns the supported storage providers.
 func AvailableStorage() []Type {
-	return []Type{Bolt, Redis}
+	return []Type{Bolt, Redis, SQL, Memory}
 }
 
 // IsStorageAvailable determines whether a given storage provider is available for instantiation.
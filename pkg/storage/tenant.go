@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The values of each tenant are stored in namespaces prefixed by its ID, e.g. "tenant-acme-credential". The values of
+// the default tenant, which requests without a tenant belong to, are stored in unprefixed namespaces, as they were
+// before tenants existed. Tenant IDs cannot contain the separator, so that the namespaces of two tenants never overlap.
+const (
+	tenantNamespacePrefix = "tenant-"
+	tenantSeparator       = "-"
+)
+
+var tenantIDRegex = regexp.MustCompile(`^[a-z0-9]{1,32}$`)
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose storage operations are scoped to the given tenant.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant the context is scoped to, or an empty string for the default tenant.
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// ValidateTenantID checks that the ID is made of 1 to 32 lowercase letters and digits.
+func ValidateTenantID(tenantID string) error {
+	if !tenantIDRegex.MatchString(tenantID) {
+		return errors.Errorf("invalid tenant id<%s>: must be 1 to 32 lowercase letters or digits", tenantID)
+	}
+	return nil
+}
+
+// TenantNamespace returns the namespace in which the values a tenant stores in the given namespace are kept.
+func TenantNamespace(tenantID, namespace string) string {
+	if tenantID == "" {
+		return namespace
+	}
+	return tenantNamespacePrefix + tenantID + tenantSeparator + namespace
+}
+
+// SplitTenantNamespace is the reverse of TenantNamespace. It returns an empty tenant ID for the namespaces of the
+// default tenant.
+func SplitTenantNamespace(namespace string) (tenantID, tenantNamespace string) {
+	rest, ok := strings.CutPrefix(namespace, tenantNamespacePrefix)
+	if !ok {
+		return "", namespace
+	}
+	tenantID, tenantNamespace, ok = strings.Cut(rest, tenantSeparator)
+	if !ok || tenantID == "" {
+		return "", namespace
+	}
+	return tenantID, tenantNamespace
+}
+
+// TenantStorage is a ServiceStorage which scopes every operation to the tenant of its context, by prefixing the
+// namespaces it is given. Namespaces which already carry a tenant prefix are rejected, so that no tenant, including the
+// default one, can reach the values of another.
+type TenantStorage struct {
+	ServiceStorage
+}
+
+// NewTenantStorage wraps db so that its operations are scoped to the tenant of their context.
+func NewTenantStorage(db ServiceStorage) *TenantStorage {
+	return &TenantStorage{ServiceStorage: db}
+}
+
+// namespace returns the namespace of the wrapped storage which holds the values of the given namespace for the tenant
+// of the context.
+func (t *TenantStorage) namespace(ctx context.Context, namespace string) (string, error) {
+	return tenantStorageNamespace(TenantFromContext(ctx), namespace)
+}
+
+func tenantStorageNamespace(tenantID, namespace string) (string, error) {
+	if strings.HasPrefix(namespace, tenantNamespacePrefix) {
+		return "", errors.Errorf("namespace<%s> is reserved for tenants", namespace)
+	}
+	return TenantNamespace(tenantID, namespace), nil
+}
+
+func (t *TenantStorage) Write(ctx context.Context, namespace, key string, value []byte) error {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	return t.ServiceStorage.Write(ctx, ns, key, value)
+}
+
+func (t *TenantStorage) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	return t.ServiceStorage.WriteWithTTL(ctx, ns, key, value, ttl)
+}
+
+func (t *TenantStorage) WriteMany(ctx context.Context, namespaces, keys []string, values [][]byte) error {
+	tenantNamespaces := make([]string, len(namespaces))
+	for i, namespace := range namespaces {
+		ns, err := t.namespace(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		tenantNamespaces[i] = ns
+	}
+	return t.ServiceStorage.WriteMany(ctx, tenantNamespaces, keys, values)
+}
+
+func (t *TenantStorage) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return t.ServiceStorage.Read(ctx, ns, key)
+}
+
+func (t *TenantStorage) Exists(ctx context.Context, namespace, key string) (bool, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+	return t.ServiceStorage.Exists(ctx, ns, key)
+}
+
+func (t *TenantStorage) ReadAll(ctx context.Context, namespace string) (map[string][]byte, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return t.ServiceStorage.ReadAll(ctx, ns)
+}
+
+func (t *TenantStorage) ReadPage(ctx context.Context, namespace, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return t.ServiceStorage.ReadPage(ctx, ns, pageToken, pageSize)
+}
+
+func (t *TenantStorage) ReadPrefix(ctx context.Context, namespace, prefix string) (map[string][]byte, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return t.ServiceStorage.ReadPrefix(ctx, ns, prefix)
+}
+
+func (t *TenantStorage) ReadAllKeys(ctx context.Context, namespace string) ([]string, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return t.ServiceStorage.ReadAllKeys(ctx, ns)
+}
+
+// Namespaces returns the namespaces of the tenant of the context, without their prefix.
+func (t *TenantStorage) Namespaces(ctx context.Context) ([]string, error) {
+	namespaces, err := t.ServiceStorage.Namespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := TenantFromContext(ctx)
+	result := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if namespaceTenantID, ns := SplitTenantNamespace(namespace); namespaceTenantID == tenantID {
+			result = append(result, ns)
+		}
+	}
+	return result, nil
+}
+
+func (t *TenantStorage) Delete(ctx context.Context, namespace, key string) error {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	return t.ServiceStorage.Delete(ctx, ns, key)
+}
+
+func (t *TenantStorage) DeleteNamespace(ctx context.Context, namespace string) error {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	return t.ServiceStorage.DeleteNamespace(ctx, ns)
+}
+
+// DeleteTenant deletes every namespace of the tenant. The default tenant cannot be deleted.
+func (t *TenantStorage) DeleteTenant(ctx context.Context, tenantID string) error {
+	if err := ValidateTenantID(tenantID); err != nil {
+		return err
+	}
+	namespaces, err := t.ServiceStorage.Namespaces(ctx)
+	if err != nil {
+		return errors.Wrap(err, "listing namespaces")
+	}
+	for _, namespace := range namespaces {
+		if namespaceTenantID, _ := SplitTenantNamespace(namespace); namespaceTenantID != tenantID {
+			continue
+		}
+		if err = t.ServiceStorage.DeleteNamespace(ctx, namespace); err != nil {
+			return errors.Wrapf(err, "deleting namespace<%s>", namespace)
+		}
+	}
+	return nil
+}
+
+func (t *TenantStorage) Update(ctx context.Context, namespace string, key string, values map[string]any) ([]byte, error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return t.ServiceStorage.Update(ctx, ns, key, values)
+}
+
+func (t *TenantStorage) UpdateValueAndOperation(ctx context.Context, namespace, key string, updater Updater, opNamespace, opKey string, opUpdater ResponseSettingUpdater) (first, op []byte, err error) {
+	ns, err := t.namespace(ctx, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	opNs, err := t.namespace(ctx, opNamespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.ServiceStorage.UpdateValueAndOperation(ctx, ns, key, updater, opNs, opKey, opUpdater)
+}
+
+func (t *TenantStorage) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
+	tenantWatchKeys := make([]WatchKey, len(watchKeys))
+	for i, wk := range watchKeys {
+		ns, err := t.namespace(ctx, wk.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		tenantWatchKeys[i] = WatchKey{Namespace: ns, Key: wk.Key}
+	}
+	tenantID := TenantFromContext(ctx)
+	return t.ServiceStorage.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
+		return businessLogicFunc(ctx, &tenantTx{tx: tx, tenantID: tenantID})
+	}, tenantWatchKeys)
+}
+
+// tenantTx is scoped to the tenant of the context the transaction was started with, whichever context its methods are
+// called with.
+type tenantTx struct {
+	tx       Tx
+	tenantID string
+}
+
+func (t *tenantTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	ns, err := tenantStorageNamespace(t.tenantID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return t.tx.Read(ctx, ns, key)
+}
+
+func (t *tenantTx) Write(ctx context.Context, namespace, key string, value []byte) error {
+	ns, err := tenantStorageNamespace(t.tenantID, namespace)
+	if err != nil {
+		return err
+	}
+	return t.tx.Write(ctx, ns, key, value)
+}
+
+func (t *tenantTx) WriteWithTTL(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	ns, err := tenantStorageNamespace(t.tenantID, namespace)
+	if err != nil {
+		return err
+	}
+	return t.tx.WriteWithTTL(ctx, ns, key, value, ttl)
+}
+
+func (t *tenantTx) Delete(ctx context.Context, namespace, key string) error {
+	ns, err := tenantStorageNamespace(t.tenantID, namespace)
+	if err != nil {
+		return err
+	}
+	return t.tx.Delete(ctx, ns, key)
+}
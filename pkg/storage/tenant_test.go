@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantNamespace(t *testing.T) {
+	assert.Equal(t, "credential", TenantNamespace("", "credential"))
+	assert.Equal(t, "tenant-acme-credential", TenantNamespace("acme", "credential"))
+
+	tenantID, ns := SplitTenantNamespace("tenant-acme-index-credential")
+	assert.Equal(t, "acme", tenantID)
+	assert.Equal(t, "index-credential", ns)
+
+	tenantID, ns = SplitTenantNamespace("credential")
+	assert.Empty(t, tenantID)
+	assert.Equal(t, "credential", ns)
+
+	assert.NoError(t, ValidateTenantID("acme42"))
+	assert.Error(t, ValidateTenantID(""))
+	assert.Error(t, ValidateTenantID("ac-me"))
+	assert.Error(t, ValidateTenantID("Acme"))
+}
+
+func TestTenantStorage(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := NewTenantStorage(dbImpl)
+		defaultCtx := context.Background()
+		acmeCtx := WithTenant(defaultCtx, "acme")
+		globexCtx := WithTenant(defaultCtx, "globex")
+
+		require.NoError(t, db.Write(defaultCtx, "driver", "max", []byte("default")))
+		require.NoError(t, db.Write(acmeCtx, "driver", "max", []byte("acme")))
+		require.NoError(t, WriteIndexed(acmeCtx, db, "driver", "lewis", []byte("acme"), NewIndexEntry("team", "mercedes")))
+
+		// each tenant only sees its own values
+		value, err := db.Read(defaultCtx, "driver", "max")
+		require.NoError(t, err)
+		assert.Equal(t, "default", string(value))
+		value, err = db.Read(acmeCtx, "driver", "max")
+		require.NoError(t, err)
+		assert.Equal(t, "acme", string(value))
+		value, err = db.Read(globexCtx, "driver", "max")
+		require.NoError(t, err)
+		assert.Empty(t, value)
+
+		keys, err := db.ReadAllKeys(acmeCtx, "driver")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"max", "lewis"}, keys)
+		keys, err = db.ReadAllKeys(defaultCtx, "driver")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"max"}, keys)
+
+		indexed, err := ReadIndex(acmeCtx, db, "driver", "team", "mercedes")
+		require.NoError(t, err)
+		assert.Len(t, indexed, 1)
+		indexed, err = ReadIndex(defaultCtx, db, "driver", "team", "mercedes")
+		require.NoError(t, err)
+		assert.Empty(t, indexed)
+
+		// the namespaces of tenants cannot be reached by name
+		_, err = db.Read(defaultCtx, "tenant-acme-driver", "max")
+		assert.Error(t, err)
+
+		namespaces, err := db.Namespaces(acmeCtx)
+		require.NoError(t, err)
+		assert.Contains(t, namespaces, "driver")
+		for _, ns := range namespaces {
+			assert.NotContains(t, ns, "tenant-")
+		}
+		namespaces, err = db.Namespaces(defaultCtx)
+		require.NoError(t, err)
+		for _, ns := range namespaces {
+			assert.NotContains(t, ns, "tenant-")
+		}
+
+		// transactions stay scoped to the tenant they were started with
+		_, err = db.Execute(acmeCtx, func(_ context.Context, tx Tx) (any, error) {
+			return nil, tx.Write(defaultCtx, "driver", "charles", []byte("acme"))
+		}, []WatchKey{{Namespace: "driver", Key: "charles"}})
+		require.NoError(t, err)
+		exists, err := db.Exists(acmeCtx, "driver", "charles")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = db.Exists(defaultCtx, "driver", "charles")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		// deleting a tenant leaves the values of the others untouched
+		require.NoError(t, db.DeleteTenant(defaultCtx, "acme"))
+		namespaces, err = db.Namespaces(acmeCtx)
+		require.NoError(t, err)
+		assert.Empty(t, namespaces)
+		value, err = db.Read(defaultCtx, "driver", "max")
+		require.NoError(t, err)
+		assert.Equal(t, "default", string(value))
+
+		assert.Error(t, db.DeleteTenant(defaultCtx, ""))
+	}
+}